@@ -6,30 +6,106 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/app"
+	"github.com/adamkadaban/opensnitch-tui/internal/bundle"
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/control"
+	"github.com/adamkadaban/opensnitch-tui/internal/demo"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/views/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/version"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "prompt" {
+		os.Exit(runPrompt(os.Args[2:]))
+	}
+
 	var (
-		configPath string
-		themeName  string
-		listenAddr string
+		configPath     string
+		themeName      string
+		listenAddr     string
+		controlSocket  string
+		dump           string
+		dumpFormat     string
+		startView      string
+		guiImport      optionalPath
+		exportBundle   string
+		importBundle   string
+		force          bool
+		strictConfig   bool
+		demoMode       bool
+		promptOnly     bool
+		etcServices    bool
+		insecureListen bool
+		traceProtocol  bool
+		demoSeed       int64
+		showVersion    bool
 	)
 
 	flag.StringVar(&configPath, "config", "", "Path to the config file (defaults to XDG config dir)")
+	flag.BoolVar(&strictConfig, "strict-config", false, "Fail on unknown config keys instead of ignoring them")
 	flag.StringVar(&themeName, "theme", "", "Override theme (midnight, canopy, dawn)")
 	flag.StringVar(&listenAddr, "listen", "127.0.0.1:50051", "gRPC listen address for daemon connections")
+	flag.BoolVar(&insecureListen, "insecure-listen", false, "Accept a -listen address other hosts can reach without TLS, without the security warning")
+	flag.StringVar(&controlSocket, "control-socket", control.DefaultSocketPath(), "Unix socket for `opensnitch-tui prompt` (empty disables)")
+	flag.StringVar(&startView, "view", "", "Open on this view for this run (dashboard, events, alerts, rules, nodes, settings)")
+	flag.StringVar(&dump, "dump", "", "Print state of the running instance and exit (rules)")
+	flag.StringVar(&dumpFormat, "format", "table", "Output format for -dump (table)")
+	flag.Var(&guiImport, "import-gui-config", "Import prompt defaults and nodes from the Qt GUI settings and exit (`path` defaults to ~/.config/opensnitch/ui-config.json)")
+	flag.BoolVar(&force, "force", false, "With -import-gui-config, overwrite values already set in the config; with -import-bundle, accept bundles from a newer version")
+	flag.StringVar(&exportBundle, "export-bundle", "", "Write the config, rule cache, trash, baselines and rule history to this `file.tar.gz` and exit")
+	flag.StringVar(&importBundle, "import-bundle", "", "Restore the config, rule cache, trash, baselines and rule history from this `file` and exit")
+	flag.BoolVar(&demoMode, "demo", false, "Run on synthetic data instead of listening for daemons")
+	flag.BoolVar(&promptOnly, "prompt-only", false, "Show only prompts in a compact layout, answered through the -control-socket of a running instance")
+	flag.BoolVar(&traceProtocol, "trace-protocol", false, "Record each node's notifications and replies from the start, for the Nodes view's protocol trace")
+	flag.BoolVar(&etcServices, "etc-services", false, "Also name ports from /etc/services, beyond the built-in well-known ones")
+	flag.Int64Var(&demoSeed, "demo-seed", demo.DefaultSeed, "Seed for the -demo data (the same seed gives the same data)")
+	flag.BoolVar(&showVersion, "version", false, "Print the version and exit")
 	flag.Parse()
 
+	if showVersion {
+		fmt.Printf("opensnitch-tui %s\n", version.Current())
+		os.Exit(0)
+	}
+
+	if guiImport.set {
+		path := guiImport.path
+		if path == "" && flag.NArg() > 0 {
+			path = flag.Arg(0)
+		}
+		os.Exit(runImportGUI(path, configPath, force))
+	}
+	if exportBundle != "" {
+		os.Exit(runExportBundle(exportBundle, configPath))
+	}
+	if importBundle != "" {
+		os.Exit(runImportBundle(importBundle, configPath, force))
+	}
+	if dump != "" {
+		os.Exit(runDump(controlSocket, dump, dumpFormat))
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	opts := app.Options{
-		ConfigPath: configPath,
-		Theme:      themeName,
-		ListenAddr: listenAddr,
+		ConfigPath:     configPath,
+		StrictConfig:   strictConfig,
+		Theme:          themeName,
+		ListenAddr:     listenAddr,
+		ControlSocket:  controlSocket,
+		View:           startView,
+		Demo:           demoMode,
+		DemoSeed:       demoSeed,
+		PromptOnly:     promptOnly,
+		EtcServices:    etcServices,
+		InsecureListen: insecureListen,
+		TraceProtocol:  traceProtocol,
 	}
 
 	if err := app.Run(ctx, opts); err != nil {
@@ -37,3 +113,169 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// runPrompt answers the oldest pending prompt of a running instance in line mode.
+func runPrompt(args []string) int {
+	fs := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	socket := fs.String("control-socket", control.DefaultSocketPath(), "Unix socket of the running instance")
+	if err := fs.Parse(args); err != nil {
+		return control.ExitError
+	}
+
+	client, err := control.Dial(*socket)
+	if err == nil {
+		defer client.Close()
+		err = control.Ask(client, os.Stdin, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui prompt: %v\n", err)
+	}
+	return control.ExitCode(err)
+}
+
+// runDump prints state fetched from a running instance over its control socket.
+func runDump(socket, what, format string) int {
+	if what != "rules" {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: unknown -dump target %q (want rules)\n", what)
+		return control.ExitError
+	}
+	if format != "table" {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: unknown -format %q (want table)\n", format)
+		return control.ExitError
+	}
+	client, err := control.Dial(socket)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: %v\n", err)
+		return control.ExitCode(err)
+	}
+	defer client.Close()
+	nodes, byNode, err := client.Rules()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: %v\n", err)
+		return control.ExitCode(err)
+	}
+	for idx, node := range nodes {
+		if idx > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s)\n", util.DisplayName(node), node.ID)
+		fmt.Print(rules.RenderPlainTable(byNode[node.ID], rules.DefaultPlainWidth))
+	}
+	return control.ExitOK
+}
+
+// optionalPath is a flag that may be given bare or with a path value.
+type optionalPath struct {
+	set  bool
+	path string
+}
+
+func (o *optionalPath) String() string { return o.path }
+
+func (o *optionalPath) Set(value string) error {
+	o.set = true
+	if b, err := strconv.ParseBool(value); err == nil {
+		if !b {
+			o.set = false
+		}
+		return nil
+	}
+	o.path = value
+	return nil
+}
+
+func (o *optionalPath) IsBoolFlag() bool { return true }
+
+// runImportGUI merges Qt GUI settings into the TUI config and prints a summary.
+func runImportGUI(guiPath, configPath string, force bool) int {
+	if guiPath == "" {
+		path, err := config.DefaultGUIConfigPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "opensnitch-tui: %v\n", err)
+			return 1
+		}
+		guiPath = path
+	}
+	report, err := config.ImportGUIConfig(guiPath, configPath, force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: import %s: %v\n", guiPath, err)
+		return 1
+	}
+	for _, line := range report.Imported {
+		fmt.Printf("imported  %s\n", line)
+	}
+	for _, line := range report.Skipped {
+		fmt.Printf("skipped   %s\n", line)
+	}
+	if len(report.Imported) == 0 {
+		fmt.Println("nothing imported")
+	}
+	return 0
+}
+
+// runExportBundle writes the TUI's local state to a bundle and lists its contents.
+func runExportBundle(target, configPath string) int {
+	paths, err := bundle.DefaultPaths(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: %v\n", err)
+		return 1
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: %v\n", err)
+		return 1
+	}
+	manifest, err := bundle.Export(out, paths, time.Now())
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: export %s: %v\n", target, err)
+		return 1
+	}
+	for _, file := range manifest.Files {
+		fmt.Printf("exported  %s\n", file.Source)
+	}
+	fmt.Printf("wrote %s (%d files)\n", target, len(manifest.Files))
+	return 0
+}
+
+// runImportBundle restores a bundle into this machine's config and cache dirs.
+func runImportBundle(source, configPath string, force bool) int {
+	paths, err := bundle.DefaultPaths(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: %v\n", err)
+		return 1
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: %v\n", err)
+		return 1
+	}
+	defer in.Close()
+	manifest, err := bundle.Import(in, paths, force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opensnitch-tui: import %s: %v\n", source, err)
+		return 1
+	}
+	rules := 0
+	for _, file := range manifest.Files {
+		if file.Kind == bundle.KindRuleCache {
+			rules++
+		}
+	}
+	fmt.Printf("imported  %s\n", paths.Config)
+	fmt.Printf("imported  %d cached rule files into %s\n", rules, paths.RuleCache)
+	for _, file := range manifest.Files {
+		switch file.Kind {
+		case bundle.KindTrash:
+			fmt.Printf("imported  %s\n", paths.Trash)
+		case bundle.KindBaselines:
+			fmt.Printf("imported  %s\n", paths.Baselines)
+		case bundle.KindRuleHistory:
+			fmt.Printf("imported  %s\n", paths.RuleHistory)
+		}
+	}
+	return 0
+}
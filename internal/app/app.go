@@ -5,25 +5,64 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/baseline"
+	"github.com/adamkadaban/opensnitch-tui/internal/blocklist"
 	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/control"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
 	"github.com/adamkadaban/opensnitch-tui/internal/daemon"
+	"github.com/adamkadaban/opensnitch-tui/internal/demo"
 	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulecache"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulehistory"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/scanhook"
 	"github.com/adamkadaban/opensnitch-tui/internal/settings"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/trash"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	root "github.com/adamkadaban/opensnitch-tui/internal/ui/root"
+	"github.com/adamkadaban/opensnitch-tui/internal/util/netnames"
+	"github.com/adamkadaban/opensnitch-tui/internal/version"
 )
 
 // Options control how the application is executed.
 type Options struct {
 	ConfigPath string
-	Theme      string
-	ListenAddr string
+	// StrictConfig fails on config keys the schema does not know.
+	StrictConfig bool
+	Theme        string
+	ListenAddr   string
+	// ControlSocket is the unix socket used by `opensnitch-tui prompt`.
+	// Empty disables the control socket.
+	ControlSocket string
+	// View overrides the configured start view for this run.
+	View string
+	// Demo replaces the daemon connection with synthetic data generated
+	// from DemoSeed; the gRPC server and control socket are not started.
+	Demo     bool
+	DemoSeed int64
+	// PromptOnly shows just the prompts of the instance listening on
+	// ControlSocket, in a compact layout, and answers them through it.
+	PromptOnly bool
+	// EtcServices adds the port names of /etc/services to the built-in
+	// ones.
+	EtcServices bool
+	// InsecureListen listens where other hosts can connect without TLS
+	// and without the security warning.
+	InsecureListen bool
+	// TraceProtocol records the notification traffic of every node from
+	// the start rather than once turned on in the Nodes view.
+	TraceProtocol bool
 }
 
 // Run loads configuration, prepares state, and starts the Bubble Tea program.
@@ -32,59 +71,163 @@ func Run(ctx context.Context, opts Options) error {
 	if err != nil {
 		return fmt.Errorf("resolve config: %w", err)
 	}
-	cfg, err := config.Load(configPath)
-	if err != nil {
+	cfg, err := config.LoadWithOptions(configPath, config.LoadOptions{Strict: opts.StrictConfig})
+	if errors.Is(err, config.ErrMigrationNotSaved) {
+		log.Printf("config upgraded in memory only: %v", err)
+	} else if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
 	cfg.DefaultPromptAction = config.NormalizePromptAction(cfg.DefaultPromptAction)
 	cfg.DefaultPromptDuration = config.NormalizePromptDuration(cfg.DefaultPromptDuration)
 	cfg.DefaultPromptTarget = config.NormalizePromptTarget(cfg.DefaultPromptTarget)
 	cfg.PromptTimeoutSeconds = config.NormalizePromptTimeoutSeconds(cfg.PromptTimeoutSeconds)
+	cfg.PromptInitialFocus = config.NormalizePromptInitialFocus(cfg.PromptInitialFocus)
 	cfg.Theme = config.NormalizeThemeName(cfg.Theme)
+	cfg.DNDMinutes = config.NormalizeDNDMinutes(cfg.DNDMinutes)
+	cfg.MaintenanceAction = config.NormalizeMaintenanceAction(cfg.MaintenanceAction)
+	cfg.MaintenanceDuration = config.NormalizePromptDuration(cfg.MaintenanceDuration)
+
+	glyphs.SetASCII(glyphs.Resolve(cfg.ASCIIOnly, os.Getenv))
 
 	selectedTheme := cfg.Theme
 	if opts.Theme != "" {
 		selectedTheme = config.NormalizeThemeName(opts.Theme)
 	}
 
-	palette := theme.New(theme.Options{Name: selectedTheme})
+	if _, err := ruleset.ParseNameTemplate(cfg.RuleNameTemplate); err != nil {
+		log.Printf("invalid rule_name_template %q (%v); using %q", cfg.RuleNameTemplate, err, ruleset.DefaultNameTemplate)
+		cfg.RuleNameTemplate = ""
+	}
+	if _, err := ruleset.ParseDescriptionTemplate(cfg.RuleDescriptionTemplate); err != nil {
+		log.Printf("invalid rule_description_template %q (%v); using %q", cfg.RuleDescriptionTemplate, err, ruleset.DefaultDescriptionTemplate)
+		cfg.RuleDescriptionTemplate = ""
+	}
+
+	if err := scanhook.Validate(cfg.InspectHook); cfg.InspectHook != "" && err != nil {
+		log.Printf("invalid inspect_hook %q (%v); scanner hook disabled", cfg.InspectHook, err)
+		cfg.InspectHook = ""
+	}
+
+	if opts.EtcServices {
+		if err := netnames.LoadServices("/etc/services"); err != nil {
+			log.Printf("%v; using the built-in port names", err)
+		}
+	}
+
+	startView, openView := startViews(cfg.StartView, opts.View)
+
+	// The auto theme asks the terminal for its background before the
+	// program takes over the screen; later checks run asynchronously.
+	light := false
+	if selectedTheme == config.ThemeAuto {
+		light, _ = theme.DetectLight()
+	}
+	palette := theme.New(theme.Options{Name: selectedTheme, Light: light})
+	if opts.PromptOnly {
+		return runPromptOnly(ctx, opts.ControlSocket, palette, cfg)
+	}
 	store := state.NewStore()
+	store.SetConnectionCap(cfg.ConnectionBuffer)
 	store.SetNodes(configNodesToState(cfg.Nodes))
-	store.SetSettings(state.Settings{
-		ThemeName:             selectedTheme,
-		DefaultPromptAction:   cfg.DefaultPromptAction,
-		DefaultPromptDuration: cfg.DefaultPromptDuration,
-		DefaultPromptTarget:   cfg.DefaultPromptTarget,
-		PromptTimeout:         time.Duration(cfg.PromptTimeoutSeconds) * time.Second,
-		AlertsInterrupt:       cfg.AlertsInterrupt,
-		PausePromptOnInspect:  cfg.PausePromptOnInspect,
-		YaraRuleDir:           cfg.YaraRuleDir,
-		YaraEnabled:           cfg.YaraEnabled,
-	})
+	store.SetSettings(storeSettings(cfg, selectedTheme, startView))
+
+	var (
+		ruleCache  *rulecache.Cache
+		ruleTrash  *trash.File
+		baselines  *baseline.File
+		history    *rulehistory.File
+		blocklists *blocklist.Set
+		writer     *persist.Writer
+	)
+	listSources := blocklistSources(cfg.Blocklists)
+	if !opts.Demo {
+		writer = persist.New(persist.Options{OnError: func(name string, err error) {
+			store.ReportError(state.SubsystemUI, err.Error())
+		}})
+		defer flushWriter(writer)
+		ruleCache = loadRuleCache(store)
+		ruleTrash = loadTrash(store, time.Now())
+		baselines = loadBaselines(store)
+		history = loadRuleHistory(store)
+		if len(listSources) > 0 {
+			blocklists = blocklist.NewSet(listSources)
+			blocklist.LoadAll(blocklists, listSources, blocklistReporter(store))
+		}
+	}
 
 	km := keymap.DefaultGlobal()
 	daemonSrv := daemon.New(store, daemon.Options{
-		ListenAddr:    opts.ListenAddr,
-		ServerName:    "opensnitch-tui",
-		ServerVersion: "dev",
+		ListenAddr:     opts.ListenAddr,
+		ServerName:     "opensnitch-tui",
+		ServerVersion:  version.Current().Version,
+		RuleCache:      ruleCache,
+		Trash:          ruleTrash,
+		Baselines:      baselines,
+		History:        history,
+		Writer:         writer,
+		Blocklists:     blocklists,
+		InsecureListen: opts.InsecureListen,
+		TraceProtocol:  opts.TraceProtocol,
 	})
 
 	settingsMgr := settings.NewManager(configPath, cfg)
+	settingsMgr.SetStore(store)
+	defer flushSettings(settingsMgr)
+
+	var (
+		rules    controller.RuleManager     = daemonSrv
+		prompts  controller.PromptManager   = daemonSrv
+		firewall controller.FirewallManager = daemonSrv
+		marks    controller.BaselineManager = daemonSrv
+		demoCtrl *demo.Controller
+		demoGen  *demo.Generator
+	)
+	if opts.Demo {
+		// The daemon server is never started in demo mode; it is only
+		// kept to render the Wire view.
+		demoGen = demo.NewGenerator(opts.DemoSeed, time.Now())
+		demo.Seed(store, demoGen, time.Now())
+		demoCtrl = demo.NewController(store)
+		rules, prompts, firewall, marks = demoCtrl, demoCtrl, demoCtrl, demoCtrl
+	}
 
 	rootModel := root.New(store, root.Options{
-		Theme:    palette,
-		KeyMap:   &km,
-		Rules:    daemonSrv,
-		Prompts:  daemonSrv,
-		Settings: settingsMgr,
+		Theme:            palette,
+		KeyMap:           &km,
+		Rules:            rules,
+		Prompts:          prompts,
+		Firewall:         firewall,
+		Baselines:        marks,
+		Wire:             daemonSrv,
+		Settings:         settingsMgr,
+		StartView:        openView,
+		DetectBackground: theme.DetectLight,
+		About: root.About{
+			ConfigPath:    configPath,
+			ListenAddr:    opts.ListenAddr,
+			ControlSocket: opts.ControlSocket,
+			Demo:          opts.Demo,
+		},
+		Output: os.Stdout,
 	})
 
-	prog := tea.NewProgram(rootModel, tea.WithAltScreen())
+	prog := tea.NewProgram(rootModel, tea.WithAltScreen(), tea.WithOutput(os.Stdout))
 
 	runnerCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	group, groupCtx := errgroup.WithContext(runnerCtx)
+	if opts.Demo {
+		group.Go(func() error {
+			return demo.Run(groupCtx, store, demoGen, demoCtrl)
+		})
+		group.Go(func() error {
+			defer cancel()
+			_, err := prog.Run()
+			return err
+		})
+		return wait(group)
+	}
 	group.Go(func() error {
 		err := daemonSrv.Start(groupCtx)
 		if err != nil && !errors.Is(err, context.Canceled) {
@@ -92,20 +235,228 @@ func Run(ctx context.Context, opts Options) error {
 		}
 		return err
 	})
+	if blocklists != nil {
+		group.Go(func() error {
+			blocklist.Watch(groupCtx, blocklists, listSources, blocklistReporter(store))
+			return nil
+		})
+	}
+	if opts.ControlSocket != "" {
+		controlSrv := control.NewServer(store, daemonSrv)
+		group.Go(func() error {
+			if err := controlSrv.Serve(groupCtx, opts.ControlSocket); err != nil {
+				store.ReportError(state.SubsystemUI, fmt.Sprintf("control socket: %v", err))
+			}
+			return nil
+		})
+	}
 	group.Go(func() error {
 		defer cancel()
 		_, err := prog.Run()
+		// Answer pending prompts and stop the daemon server before the
+		// context cancellation tears everything else down.
+		shutdownCtx, stop := context.WithTimeout(context.Background(), daemon.ShutdownTimeout)
+		defer stop()
+		daemonSrv.Shutdown(shutdownCtx)
 		return err
 	})
+	return wait(group)
+}
+
+// flushSettings makes a last attempt to save settings changes that could not
+// be written during the session, and says so plainly when they are lost.
+func flushSettings(mgr *settings.Manager) {
+	if err := mgr.Flush(); err != nil {
+		log.Printf("WARNING: settings changed this session were NOT saved: %v", err)
+	}
+}
 
+// flushWriter writes the state files still waiting for their debounce once
+// the daemons and the UI have stopped.
+func flushWriter(writer *persist.Writer) {
+	if err := writer.Close(); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+}
+
+// wait returns the first error of the run that is not part of a normal exit.
+func wait(group *errgroup.Group) error {
 	if err := group.Wait(); err != nil && !errors.Is(err, tea.ErrProgramKilled) && !errors.Is(err, context.Canceled) {
 		log.Printf("server error: %v", err)
 		return err
 	}
-
 	return nil
 }
 
+// loadRuleCache restores the rules cached by earlier runs into store. A
+// broken cache only costs the head start, so problems are logged.
+func loadRuleCache(store *state.Store) *rulecache.Cache {
+	dir, err := rulecache.DefaultDir()
+	if err != nil {
+		log.Printf("rule cache disabled: %v", err)
+		return nil
+	}
+	cache := rulecache.New(dir)
+	entries, err := cache.Load()
+	if err != nil {
+		log.Printf("rule cache: %v", err)
+	}
+	rulecache.Restore(store, entries)
+	return cache
+}
+
+// loadTrash restores the deleted rules kept by earlier runs into store,
+// dropping those past trash.Retention. An unreadable trash file is left
+// alone and deletions this run are not persisted.
+func loadTrash(store *state.Store, now time.Time) *trash.File {
+	path, err := trash.DefaultPath()
+	if err != nil {
+		log.Printf("trash disabled: %v", err)
+		return nil
+	}
+	file := trash.New(path)
+	entries, err := file.Load()
+	if err != nil {
+		log.Printf("trash disabled: %v", err)
+		return nil
+	}
+	entries, pruned := trash.Prune(entries, now)
+	if pruned > 0 {
+		if err := file.Save(entries); err != nil {
+			log.Printf("trash: %v", err)
+		}
+	}
+	store.SetTrash(entries)
+	return file
+}
+
+// loadBaselines restores the counter baselines marked in earlier runs into
+// store. An unreadable file is left alone and baselines marked this run are
+// not persisted.
+func loadBaselines(store *state.Store) *baseline.File {
+	path, err := baseline.DefaultPath()
+	if err != nil {
+		log.Printf("baselines disabled: %v", err)
+		return nil
+	}
+	file := baseline.New(path)
+	baselines, err := file.Load()
+	if err != nil {
+		log.Printf("baselines disabled: %v", err)
+		return nil
+	}
+	store.SetBaselines(baselines)
+	return file
+}
+
+// loadRuleHistory restores the rule timelines kept by earlier runs into
+// store. An unreadable file is left alone and changes this run are not
+// persisted.
+func loadRuleHistory(store *state.Store) *rulehistory.File {
+	path, err := rulehistory.DefaultPath()
+	if err != nil {
+		log.Printf("rule history disabled: %v", err)
+		return nil
+	}
+	file := rulehistory.New(path)
+	entries, err := file.Load()
+	if err != nil {
+		log.Printf("rule history disabled: %v", err)
+		return nil
+	}
+	store.SetRuleHistory(entries)
+	return file
+}
+
+func blocklistSources(lists []config.Blocklist) []blocklist.Source {
+	sources := make([]blocklist.Source, 0, len(lists))
+	for _, list := range lists {
+		sources = append(sources, blocklist.Source{Name: list.Name, Path: list.Path, Strict: list.Strict})
+	}
+	return sources
+}
+
+// blocklistReporter logs each blocklist load; a list that fails to load
+// keeps what it had, so a half-written file never unblocks anything.
+func blocklistReporter(store *state.Store) blocklist.ReloadFunc {
+	return func(src blocklist.Source, list *blocklist.List, err error) {
+		if err != nil {
+			store.ReportError(state.SubsystemUI, fmt.Sprintf("%v (keeping the previous entries)", err))
+			return
+		}
+		text := fmt.Sprintf("blocklist %s: %d entries loaded", src.DisplayName(), list.Entries)
+		if list.Skipped > 0 {
+			text += fmt.Sprintf(", %d lines skipped", list.Skipped)
+		}
+		store.AppendLog(state.LogEntry{Severity: state.LogInfo, Subsystem: state.SubsystemUI, Text: text})
+	}
+}
+
+// storeSettings is the session's starting settings from cfg. startView is
+// the configured start view, not a -view override: the Settings view saves
+// it back to the config.
+func storeSettings(cfg config.Config, themeName string, startView state.ViewKind) state.Settings {
+	return state.Settings{
+		ThemeName:               themeName,
+		DefaultPromptAction:     cfg.DefaultPromptAction,
+		DefaultPromptDuration:   cfg.DefaultPromptDuration,
+		DefaultPromptTarget:     cfg.DefaultPromptTarget,
+		PromptTimeout:           time.Duration(cfg.PromptTimeoutSeconds) * time.Second,
+		PromptInitialFocus:      cfg.PromptInitialFocus,
+		PromptAutoJump:          cfg.PromptAutoJump,
+		AlertsInterrupt:         cfg.AlertsInterrupt,
+		PausePromptOnInspect:    cfg.PausePromptOnInspect,
+		YaraRuleDir:             cfg.YaraRuleDir,
+		YaraEnabled:             cfg.YaraEnabled,
+		InspectHook:             cfg.InspectHook,
+		InspectHookEnabled:      cfg.InspectHookEnabled,
+		InspectSections:         cfg.InspectSections,
+		DNDMinutes:              cfg.DNDMinutes,
+		MaintenanceAction:       cfg.MaintenanceAction,
+		MaintenanceDuration:     cfg.MaintenanceDuration,
+		ClockSkewCorrection:     cfg.ClockSkewCorrection,
+		MaxOperatorData:         cfg.MaxOperatorDataLength,
+		MaxRuleText:             cfg.MaxRuleTextLength,
+		RuleNameTemplate:        cfg.RuleNameTemplate,
+		RuleDescriptionTemplate: cfg.RuleDescriptionTemplate,
+		StartView:               startView,
+		UIDZeroUnknown:          cfg.UIDZeroUnknown,
+		SlowAck:                 time.Duration(cfg.SlowAckSeconds) * time.Second,
+		BulkParallelism:         cfg.BulkParallelism,
+		RuleHitFresh:            time.Duration(cfg.RuleHitFreshMinutes) * time.Minute,
+		RuleHitRecent:           time.Duration(cfg.RuleHitRecentMinutes) * time.Minute,
+		Profiles:                settings.ProfilesFromConfig(cfg.Profiles),
+	}
+}
+
+// startViews returns the configured start view and the view to open on
+// this run, which -view overrides without changing the setting.
+func startViews(configured, override string) (state.ViewKind, state.ViewKind) {
+	if strings.TrimSpace(override) == "" {
+		kind := resolveStartView(configured, "")
+		return kind, kind
+	}
+	return resolveStartView(configured, ""), resolveStartView(configured, override)
+}
+
+// resolveStartView picks the view to open on: the override when given,
+// otherwise the configured one. Unknown names fall back to the dashboard.
+func resolveStartView(configured, override string) state.ViewKind {
+	name, source := configured, "start_view"
+	if strings.TrimSpace(override) != "" {
+		name, source = override, "-view"
+	}
+	if strings.TrimSpace(name) == "" {
+		return state.ViewDashboard
+	}
+	kind, ok := state.ParseViewKind(name)
+	if !ok {
+		log.Printf("unknown %s %q; starting on %s", source, name, state.ViewDashboard)
+		return state.ViewDashboard
+	}
+	return kind
+}
+
 func configNodesToState(nodes []config.Node) []state.Node {
 	result := make([]state.Node, 0, len(nodes))
 	for idx, node := range nodes {
@@ -119,12 +470,18 @@ func configNodesToState(nodes []config.Node) []state.Node {
 			name = node.Address
 		}
 
+		prompts := state.PromptsInteractive
+		if node.InteractivePrompts != nil && !*node.InteractivePrompts {
+			prompts = state.PromptsPolicy
+		}
 		result = append(result, state.Node{
-			ID:      id,
-			Name:    name,
-			Address: node.Address,
-			Status:  state.NodeStatusDisconnected,
-			Message: "awaiting connection",
+			ID:         id,
+			Name:       name,
+			Address:    node.Address,
+			Status:     state.NodeStatusDisconnected,
+			Message:    "awaiting connection",
+			Prompts:    prompts,
+			Configured: true,
 		})
 	}
 	return result
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/control"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/compact"
+)
+
+// runPromptOnly answers the prompts of the instance listening on socket in
+// the compact layout. Only one instance can listen for daemons, so this one
+// proxies decisions to it instead.
+func runPromptOnly(ctx context.Context, socket string, palette theme.Theme, cfg config.Config) error {
+	if socket == "" {
+		return errors.New("-prompt-only needs the control socket of a running instance")
+	}
+	client, err := control.Dial(socket)
+	if err != nil {
+		return fmt.Errorf("-prompt-only: %w", err)
+	}
+	defer client.Close()
+
+	model := compact.New(client, palette, compact.Defaults{
+		Action:   cfg.DefaultPromptAction,
+		Duration: cfg.DefaultPromptDuration,
+		Target:   cfg.DefaultPromptTarget,
+	})
+	_, err = tea.NewProgram(model, tea.WithContext(ctx)).Run()
+	if errors.Is(err, tea.ErrProgramKilled) || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
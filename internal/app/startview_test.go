@@ -0,0 +1,81 @@
+package app
+
+import (
+	"bytes"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/settings"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	settingsview "github.com/adamkadaban/opensnitch-tui/internal/ui/views/settings"
+)
+
+func TestResolveStartView(t *testing.T) {
+	cases := []struct {
+		name       string
+		configured string
+		override   string
+		want       state.ViewKind
+		warn       bool
+	}{
+		{"default", "", "", state.ViewDashboard, false},
+		{"configured", "events", "", state.ViewEvents, false},
+		{"case and space", " Rules ", "", state.ViewRules, false},
+		{"flag overrides config", "events", "nodes", state.ViewNodes, false},
+		{"unknown config", "firewal", "", state.ViewDashboard, true},
+		{"unknown flag ignores config", "events", "bogus", state.ViewDashboard, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			prev := log.Writer()
+			log.SetOutput(&buf)
+			defer log.SetOutput(prev)
+
+			if got := resolveStartView(tc.configured, tc.override); got != tc.want {
+				t.Fatalf("resolveStartView(%q, %q) = %q, want %q", tc.configured, tc.override, got, tc.want)
+			}
+			if warned := strings.Contains(buf.String(), "unknown"); warned != tc.warn {
+				t.Fatalf("warning logged = %v, want %v (log %q)", warned, tc.warn, buf.String())
+			}
+		})
+	}
+}
+
+// -view opens another view for one run; saving the settings afterwards
+// must not make it the configured start view.
+func TestViewFlagIsNotSavedAsStartView(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.Default()
+	cfg.StartView = "rules"
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	configured, open := startViews(cfg.StartView, "events")
+	if configured != state.ViewRules || open != state.ViewEvents {
+		t.Fatalf("expected rules configured and events opened, got %s and %s", configured, open)
+	}
+
+	store := state.NewStore()
+	store.SetSettings(storeSettings(cfg, cfg.Theme, configured))
+	mgr := settings.NewManager(path, cfg)
+	mgr.SetStore(store)
+	view := settingsview.New(store, theme.New(theme.Options{}), mgr)
+	view.SetSize(120, 40)
+	view.View()
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.StartView != "rules" {
+		t.Fatalf("expected start_view to stay rules after saving, got %q", saved.StartView)
+	}
+}
@@ -0,0 +1,71 @@
+// Package baseline keeps the per-node counter baselines on disk, so the
+// dashboard's "since baseline" figures carry over to later sessions.
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+const fileVersion = 1
+
+type file struct {
+	Version   int              `json:"version"`
+	Baselines []state.Baseline `json:"baselines"`
+}
+
+// File stores the baselines as one JSON file.
+type File struct {
+	path string
+}
+
+// New returns baselines kept at path.
+func New(path string) *File {
+	return &File{path: path}
+}
+
+// DefaultPath returns the baseline file under XDG_CACHE_HOME (~/.cache).
+func DefaultPath() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "opensnitch-tui", "baselines.json"), nil
+}
+
+// Load reads the baselines; a missing file means none are marked.
+func (f *File) Load() ([]state.Baseline, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read baselines: %w", err)
+	}
+	var doc file
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("read baselines: %w", err)
+	}
+	if doc.Version != fileVersion {
+		return nil, fmt.Errorf("read baselines: unsupported version %d", doc.Version)
+	}
+	return doc.Baselines, nil
+}
+
+// Document is the baseline file holding baselines, for a persist.Writer.
+func (f *File) Document(baselines []state.Baseline) persist.Document {
+	return persist.Document{Name: "baselines", Path: f.path, Marshal: func() ([]byte, error) {
+		return json.MarshalIndent(file{Version: fileVersion, Baselines: baselines}, "", "  ")
+	}}
+}
+
+// Save replaces the stored baselines now.
+func (f *File) Save(baselines []state.Baseline) error {
+	return persist.Write(f.Document(baselines))
+}
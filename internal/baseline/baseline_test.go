@@ -0,0 +1,34 @@
+package baseline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	f := New(filepath.Join(t.TempDir(), "nested", "baselines.json"))
+	if baselines, err := f.Load(); err != nil || baselines != nil {
+		t.Fatalf("expected a missing file to hold no baselines, got %+v, %v", baselines, err)
+	}
+	markedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	want := []state.Baseline{{
+		NodeKey:   "name:laptop",
+		NodeName:  "laptop",
+		MarkedAt:  markedAt,
+		Counters:  state.Counters{Connections: 120, Accepted: 100, Dropped: 20},
+		Restarted: true,
+	}}
+	if err := f.Save(want); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	got, err := f.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(got) != 1 || got[0].NodeKey != "name:laptop" || got[0].Counters != want[0].Counters || !got[0].Restarted || !got[0].MarkedAt.Equal(markedAt) {
+		t.Fatalf("unexpected baselines: %+v", got)
+	}
+}
@@ -0,0 +1,304 @@
+// Package binmeta tells what kind of executable a file is from its first
+// bytes, the way file(1) would, without reading the rest of it.
+package binmeta
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// HeaderSize is how much of a file Inspect reads. ELF and program headers
+// and a #! line all fit in it.
+const HeaderSize = 4096
+
+// PreviewSize is how many leading bytes Info keeps for a hex preview.
+const PreviewSize = 32
+
+// maxInterpreter bounds the interpreter path or #! line kept from a file.
+const maxInterpreter = 256
+
+// Kind is the broad file type.
+type Kind string
+
+const (
+	KindELF     Kind = "elf"
+	KindScript  Kind = "script"
+	KindEmpty   Kind = "empty"
+	KindUnknown Kind = "unknown"
+)
+
+// Linkage says how an ELF executable finds its libraries; empty when the
+// program headers were not within the bytes read.
+type Linkage string
+
+const (
+	LinkStatic  Linkage = "statically linked"
+	LinkDynamic Linkage = "dynamically linked"
+)
+
+// Info is what the start of a file says about it.
+type Info struct {
+	Kind Kind
+	// Class is 32 or 64 for ELF files.
+	Class     int
+	BigEndian bool
+	// Machine is the ELF target architecture, e.g. "x86-64".
+	Machine string
+	// Type is the ELF file type, e.g. "PIE executable" or "shared object".
+	Type    string
+	Linkage Linkage
+	// Interpreter is the ELF program interpreter, or the #! line of a
+	// script without the "#!".
+	Interpreter string
+	// Packed names the packer whose signature is in the header, e.g. "UPX".
+	// It is a heuristic: the signature can be faked or stripped.
+	Packed string
+	// Truncated is set when the file ends before its headers do.
+	Truncated bool
+	// Head holds up to PreviewSize leading bytes.
+	Head []byte
+	// Size and ModTime come from the file system; Classify leaves them zero.
+	Size    int64
+	ModTime time.Time
+}
+
+// Inspect classifies the file at path from at most HeaderSize bytes. Only
+// regular files are opened, so a FIFO or device at the path cannot block
+// the caller.
+func Inspect(path string) (Info, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+	if !st.Mode().IsRegular() {
+		return Info{}, fmt.Errorf("%s is not a regular file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Info{}, err
+	}
+	info := Classify(header[:n])
+	info.Size = st.Size()
+	info.ModTime = st.ModTime()
+	return info, nil
+}
+
+// Classify describes a file from its leading bytes. A header shorter than
+// HeaderSize is taken to be the whole file.
+func Classify(header []byte) Info {
+	info := Info{Kind: KindUnknown, Head: bytes.Clone(header[:min(len(header), PreviewSize)])}
+	switch {
+	case len(header) == 0:
+		info.Kind = KindEmpty
+	case bytes.HasPrefix(header, []byte(elf.ELFMAG)):
+		info.Kind = KindELF
+		classifyELF(header, &info)
+	case bytes.HasPrefix(header, []byte("#!")):
+		info.Kind = KindScript
+		line, _, _ := bytes.Cut(header[2:], []byte("\n"))
+		info.Interpreter = clip(strings.TrimSpace(string(line)))
+	}
+	if bytes.Contains(header, []byte("UPX!")) {
+		info.Packed = "UPX"
+	}
+	return info
+}
+
+func classifyELF(header []byte, info *Info) {
+	if len(header) < elf.EI_NIDENT {
+		info.Truncated = true
+		return
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if elf.Data(header[elf.EI_DATA]) == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+		info.BigEndian = true
+	}
+	// Offsets into the ELF header and program header entries by class.
+	var ehdrSize, phoffAt, phentAt, phnumAt, pOffsetAt, pFileszAt int
+	switch elf.Class(header[elf.EI_CLASS]) {
+	case elf.ELFCLASS64:
+		info.Class = 64
+		ehdrSize, phoffAt, phentAt, phnumAt, pOffsetAt, pFileszAt = 64, 32, 54, 56, 8, 32
+	case elf.ELFCLASS32:
+		info.Class = 32
+		ehdrSize, phoffAt, phentAt, phnumAt, pOffsetAt, pFileszAt = 52, 28, 42, 44, 4, 16
+	default:
+		return
+	}
+	if len(header) < ehdrSize {
+		info.Truncated = true
+		return
+	}
+	word := func(b []byte) uint64 {
+		if info.Class == 64 {
+			return order.Uint64(b)
+		}
+		return uint64(order.Uint32(b))
+	}
+	typ := elf.Type(order.Uint16(header[16:]))
+	info.Machine = machineName(elf.Machine(order.Uint16(header[18:])))
+
+	phoff := word(header[phoffAt:])
+	phent := uint64(order.Uint16(header[phentAt:]))
+	phnum := uint64(order.Uint16(header[phnumAt:]))
+	var interp, dynamic, complete bool
+	if phnum > 0 && phent > 0 && phoff < uint64(len(header)) && phnum <= (uint64(len(header))-phoff)/phent {
+		complete = true
+		for i := range phnum {
+			ph := header[phoff+i*phent:]
+			if uint64(len(ph)) < uint64(pFileszAt+info.Class/8) {
+				complete = false
+				break
+			}
+			switch elf.ProgType(order.Uint32(ph)) {
+			case elf.PT_INTERP:
+				interp = true
+				off, size := word(ph[pOffsetAt:]), word(ph[pFileszAt:])
+				if off < uint64(len(header)) {
+					name := header[off:min(uint64(len(header)), off+size)]
+					name, _, _ = bytes.Cut(name, []byte{0})
+					info.Interpreter = clip(string(name))
+				}
+			case elf.PT_DYNAMIC:
+				dynamic = true
+			}
+		}
+	} else if phnum > 0 && len(header) < HeaderSize {
+		// The file ended inside its program header table.
+		info.Truncated = true
+	}
+
+	switch typ {
+	case elf.ET_EXEC:
+		info.Type = "executable"
+	case elf.ET_DYN:
+		info.Type = "shared object"
+		if interp {
+			info.Type = "PIE executable"
+		}
+	case elf.ET_REL:
+		info.Type = "relocatable"
+	case elf.ET_CORE:
+		info.Type = "core file"
+	default:
+		info.Type = fmt.Sprintf("type %#x", uint16(typ))
+	}
+	if complete && (typ == elf.ET_EXEC || typ == elf.ET_DYN) {
+		info.Linkage = LinkStatic
+		if interp || dynamic {
+			info.Linkage = LinkDynamic
+		}
+	}
+}
+
+func machineName(m elf.Machine) string {
+	switch m {
+	case elf.EM_X86_64:
+		return "x86-64"
+	case elf.EM_386:
+		return "Intel 80386"
+	case elf.EM_AARCH64:
+		return "ARM aarch64"
+	case elf.EM_ARM:
+		return "ARM"
+	case elf.EM_RISCV:
+		return "RISC-V"
+	case elf.EM_PPC64:
+		return "PowerPC64"
+	case elf.EM_PPC:
+		return "PowerPC"
+	case elf.EM_S390:
+		return "IBM S/390"
+	case elf.EM_MIPS:
+		return "MIPS"
+	case elf.EM_LOONGARCH:
+		return "LoongArch"
+	}
+	return fmt.Sprintf("machine %#x", uint16(m))
+}
+
+func clip(s string) string {
+	if len(s) > maxInterpreter {
+		return s[:maxInterpreter]
+	}
+	return s
+}
+
+// Describe sums up info in one line, e.g. "ELF 64-bit LSB PIE executable,
+// x86-64, dynamically linked".
+func (info Info) Describe() string {
+	switch info.Kind {
+	case KindEmpty:
+		return "empty file"
+	case KindScript:
+		if info.Interpreter == "" {
+			return "script"
+		}
+		return "script, #!" + info.Interpreter
+	case KindELF:
+		parts := []string{"ELF"}
+		if info.Class != 0 {
+			order := "LSB"
+			if info.BigEndian {
+				order = "MSB"
+			}
+			parts = []string{fmt.Sprintf("ELF %d-bit %s", info.Class, order)}
+		}
+		if info.Type != "" {
+			parts[0] += " " + info.Type
+		}
+		if info.Machine != "" {
+			parts = append(parts, info.Machine)
+		}
+		if info.Linkage != "" {
+			parts = append(parts, string(info.Linkage))
+		}
+		if info.Truncated {
+			parts = append(parts, "truncated")
+		}
+		return strings.Join(parts, ", ")
+	}
+	return "data"
+}
+
+// HexDump renders b as hexdump -C style lines of 16 bytes.
+func HexDump(b []byte) []string {
+	var lines []string
+	for off := 0; off < len(b); off += 16 {
+		row := b[off:min(len(b), off+16)]
+		var hex strings.Builder
+		for i := range 16 {
+			if i == 8 {
+				hex.WriteByte(' ')
+			}
+			if i < len(row) {
+				fmt.Fprintf(&hex, "%02x ", row[i])
+			} else {
+				hex.WriteString("   ")
+			}
+		}
+		ascii := make([]byte, len(row))
+		for i, c := range row {
+			ascii[i] = c
+			if c < 0x20 || c >= 0x7f {
+				ascii[i] = '.'
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%08x  %s |%s|", off, hex.String(), ascii))
+	}
+	return lines
+}
@@ -0,0 +1,126 @@
+package binmeta
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// The fixtures under testdata are hand-built headers: a PIE with an
+// interpreter, static executables, a UPX signature, scripts and files cut
+// off inside their ELF headers.
+
+func TestInspectFixtures(t *testing.T) {
+	cases := []struct {
+		file     string
+		describe string
+		want     Info
+	}{
+		{"elf64-pie", "ELF 64-bit LSB PIE executable, x86-64, dynamically linked",
+			Info{Kind: KindELF, Class: 64, Machine: "x86-64", Type: "PIE executable", Linkage: LinkDynamic, Interpreter: "/lib64/ld-linux-x86-64.so.2"}},
+		{"elf64-static", "ELF 64-bit LSB executable, ARM aarch64, statically linked",
+			Info{Kind: KindELF, Class: 64, Machine: "ARM aarch64", Type: "executable", Linkage: LinkStatic}},
+		{"elf64-upx", "ELF 64-bit LSB executable, x86-64, statically linked",
+			Info{Kind: KindELF, Class: 64, Machine: "x86-64", Type: "executable", Linkage: LinkStatic, Packed: "UPX"}},
+		{"elf32-msb-static", "ELF 32-bit MSB executable, MIPS, statically linked",
+			Info{Kind: KindELF, Class: 32, BigEndian: true, Machine: "MIPS", Type: "executable", Linkage: LinkStatic}},
+		{"script-env", "script, #!/usr/bin/env python3",
+			Info{Kind: KindScript, Interpreter: "/usr/bin/env python3"}},
+		{"script-sh", "script, #!/bin/sh -e",
+			Info{Kind: KindScript, Interpreter: "/bin/sh -e"}},
+		{"truncated-ident", "ELF, truncated",
+			Info{Kind: KindELF, Truncated: true}},
+		{"truncated-ehdr", "ELF 64-bit LSB, truncated",
+			Info{Kind: KindELF, Class: 64, Truncated: true}},
+		{"truncated-phdrs", "ELF 64-bit LSB shared object, x86-64, truncated",
+			Info{Kind: KindELF, Class: 64, Machine: "x86-64", Type: "shared object", Truncated: true}},
+		{"empty", "empty file", Info{Kind: KindEmpty}},
+		{"data", "data", Info{Kind: KindUnknown}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			path := filepath.Join("testdata", tc.file)
+			info, err := Inspect(path)
+			if err != nil {
+				t.Fatalf("Inspect: %v", err)
+			}
+			st, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size != st.Size() || !info.ModTime.Equal(st.ModTime()) {
+				t.Fatalf("expected size and mtime from the file, got %d %v", info.Size, info.ModTime)
+			}
+			if len(info.Head) != min(int(st.Size()), PreviewSize) {
+				t.Fatalf("expected %d preview bytes, got %d", min(int(st.Size()), PreviewSize), len(info.Head))
+			}
+			info.Size, info.ModTime, info.Head = 0, time.Time{}, nil
+			if !reflect.DeepEqual(info, tc.want) {
+				t.Fatalf("unexpected info\n got %+v\nwant %+v", info, tc.want)
+			}
+			if got := info.Describe(); got != tc.describe {
+				t.Fatalf("Describe = %q, want %q", got, tc.describe)
+			}
+		})
+	}
+}
+
+func TestInspectReadsOnlyTheHeader(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "elf64-pie"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "big")
+	// A sparse 1 GiB file: reading it whole would take noticeably long.
+	if err := os.WriteFile(path, fixture, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, 1<<30); err != nil {
+		t.Fatal(err)
+	}
+	info, err := Inspect(path)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if info.Size != 1<<30 || info.Type != "PIE executable" || info.Truncated {
+		t.Fatalf("unexpected info for a large file: %+v", info)
+	}
+}
+
+func TestInspectRefusesSpecialFiles(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "fifo")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := Inspect(fifo)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("expected a FIFO to be refused")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Inspect blocked on a FIFO")
+	}
+	if _, err := Inspect(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatalf("expected a missing file to fail")
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	got := HexDump([]byte("\x7fELF\x02\x01\x01\x00abcdefghij#!/bin"))
+	want := []string{
+		"00000000  7f 45 4c 46 02 01 01 00  61 62 63 64 65 66 67 68  |.ELF....abcdefgh|",
+		"00000010  69 6a 23 21 2f 62 69 6e                           |ij#!/bin|",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected dump:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,247 @@
+// Package blocklist matches connection destinations against local lists of
+// known-bad domains and addresses, such as the ones DNS blockers keep.
+package blocklist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Source is a list file as configured.
+type Source struct {
+	// Name is shown in prompts; empty uses the file's base name.
+	Name string
+	Path string
+	// Strict denies matching connections without prompting.
+	Strict bool
+}
+
+// DisplayName returns the name the list is shown under.
+func (s Source) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return filepath.Base(s.Path)
+}
+
+// Match tells which list entry a destination matched.
+type Match struct {
+	List string
+	// Line is the 1-based line of the entry in the list file.
+	Line int
+	// Entry is the domain, address or CIDR that matched.
+	Entry  string
+	Strict bool
+	// Host is set when the destination matched by name rather than address.
+	Host bool
+}
+
+// List is one parsed list file. Domains match themselves and every
+// subdomain; addresses match exactly and CIDRs by containment.
+type List struct {
+	Source Source
+	// Entries is how many domains, addresses and CIDRs were read, and
+	// Skipped how many lines were neither.
+	Entries int
+	Skipped int
+
+	hosts map[string]int
+	addrs map[netip.Addr]int
+	nets  []netEntry
+}
+
+type netEntry struct {
+	prefix netip.Prefix
+	line   int
+}
+
+// hostsFileNames are the loopback names hosts-file style lists carry in
+// their header; they are not blocked.
+var hostsFileNames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"ip6-localnet":          true,
+	"ip6-mcastprefix":       true,
+	"ip6-allnodes":          true,
+	"ip6-allrouters":        true,
+	"ip6-allhosts":          true,
+	"0.0.0.0":               true,
+}
+
+// Load reads and parses the list file of src.
+func Load(src Source) (*List, error) {
+	f, err := os.Open(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("blocklist %s: %w", src.DisplayName(), err)
+	}
+	defer f.Close()
+	list, err := Parse(src, f)
+	if err != nil {
+		return nil, fmt.Errorf("blocklist %s: %w", src.DisplayName(), err)
+	}
+	return list, nil
+}
+
+// Parse reads a list with one domain, address or CIDR per line. Text after
+// "#" is a comment, and hosts-file lines ("0.0.0.0 ads.example.com") block
+// the names rather than the address.
+func Parse(src Source, r io.Reader) (*List, error) {
+	list := &List{Source: src, hosts: make(map[string]int), addrs: make(map[netip.Addr]int)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) == 1:
+			if !list.add(fields[0], line) {
+				list.Skipped++
+			}
+		default:
+			if _, err := netip.ParseAddr(fields[0]); err != nil {
+				list.Skipped++
+				continue
+			}
+			for _, name := range fields[1:] {
+				if hostsFileNames[strings.ToLower(name)] {
+					continue
+				}
+				if !list.addHost(name, line) {
+					list.Skipped++
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (l *List) add(token string, line int) bool {
+	if strings.Contains(token, "/") {
+		prefix, err := netip.ParsePrefix(token)
+		if err != nil {
+			return false
+		}
+		l.nets = append(l.nets, netEntry{prefix: prefix.Masked(), line: line})
+		l.Entries++
+		return true
+	}
+	if addr, err := netip.ParseAddr(token); err == nil {
+		if _, ok := l.addrs[addr.Unmap()]; !ok {
+			l.addrs[addr.Unmap()] = line
+			l.Entries++
+		}
+		return true
+	}
+	return l.addHost(token, line)
+}
+
+func (l *List) addHost(name string, line int) bool {
+	host := normalizeHost(strings.TrimPrefix(name, "*."))
+	if host == "" || strings.ContainsAny(host, "/:*") {
+		return false
+	}
+	if _, ok := l.hosts[host]; !ok {
+		l.hosts[host] = line
+		l.Entries++
+	}
+	return true
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "."), ".")
+}
+
+// Match reports whether host or ip is on the list. The most specific domain
+// wins over its parents, and names are checked before the address.
+func (l *List) Match(host, ip string) (Match, bool) {
+	found := func(entry string, line int, byHost bool) (Match, bool) {
+		return Match{List: l.Source.DisplayName(), Line: line, Entry: entry, Strict: l.Source.Strict, Host: byHost}, true
+	}
+	if name := normalizeHost(host); name != "" {
+		if _, err := netip.ParseAddr(name); err != nil {
+			for suffix := name; ; {
+				if line, ok := l.hosts[suffix]; ok {
+					return found(suffix, line, true)
+				}
+				_, parent, ok := strings.Cut(suffix, ".")
+				if !ok {
+					break
+				}
+				suffix = parent
+			}
+		} else if ip == "" {
+			ip = name
+		}
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return Match{}, false
+	}
+	addr = addr.Unmap()
+	if line, ok := l.addrs[addr]; ok {
+		return found(addr.String(), line, false)
+	}
+	for _, entry := range l.nets {
+		if entry.prefix.Contains(addr) {
+			return found(entry.prefix.String(), entry.line, false)
+		}
+	}
+	return Match{}, false
+}
+
+// Set is the loaded lists in configured order, safe to match against while
+// a watcher replaces them.
+type Set struct {
+	mu    sync.RWMutex
+	lists []*List
+}
+
+// NewSet returns a Set of len(sources) empty slots, filled by Replace.
+func NewSet(sources []Source) *Set {
+	lists := make([]*List, len(sources))
+	for i, src := range sources {
+		lists[i] = &List{Source: src}
+	}
+	return &Set{lists: lists}
+}
+
+// Replace swaps in list for the i-th source.
+func (s *Set) Replace(i int, list *List) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i >= 0 && i < len(s.lists) {
+		s.lists[i] = list
+	}
+}
+
+// Match checks the lists in order and returns the first match.
+func (s *Set) Match(host, ip string) (Match, bool) {
+	if s == nil {
+		return Match{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, list := range s.lists {
+		if match, ok := list.Match(host, ip); ok {
+			return match, true
+		}
+	}
+	return Match{}, false
+}
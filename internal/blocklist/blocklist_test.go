@@ -0,0 +1,97 @@
+package blocklist
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `# ads.txt from the DNS blocker
+127.0.0.1 localhost
+0.0.0.0 tracker.example.net metrics.example.net # hosts-file style
+
+ads.example.com
+*.wild.example.org
+Upper.Example.COM.
+203.0.113.7
+10.20.0.0/16
+2001:db8::/32
+not a valid line
+bad/prefix
+`
+
+func parseSample(t *testing.T, src Source) *List {
+	t.Helper()
+	list, err := Parse(src, strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	return list
+}
+
+func TestParseCountsEntries(t *testing.T) {
+	list := parseSample(t, Source{Path: "/etc/dns/ads.txt"})
+	if list.Entries != 8 || list.Skipped != 2 {
+		t.Fatalf("expected 8 entries and 2 skipped lines, got %d and %d", list.Entries, list.Skipped)
+	}
+	if _, ok := list.Match("localhost", "127.0.0.1"); ok {
+		t.Fatal("expected the hosts-file header not to block localhost")
+	}
+}
+
+func TestListMatch(t *testing.T) {
+	list := parseSample(t, Source{Path: "/etc/dns/ads.txt", Strict: true})
+	cases := []struct {
+		host, ip   string
+		entry      string
+		line       int
+		byHost, ok bool
+	}{
+		{host: "ads.example.com", entry: "ads.example.com", line: 5, byHost: true, ok: true},
+		{host: "cdn.ADS.example.com.", entry: "ads.example.com", line: 5, byHost: true, ok: true},
+		{host: "notads.example.com", ok: false},
+		{host: "example.com", ok: false},
+		{host: "x.wild.example.org", entry: "wild.example.org", line: 6, byHost: true, ok: true},
+		{host: "upper.example.com", entry: "upper.example.com", line: 7, byHost: true, ok: true},
+		{host: "metrics.example.net", entry: "metrics.example.net", line: 3, byHost: true, ok: true},
+		{host: "good.example.org", ip: "203.0.113.7", entry: "203.0.113.7", line: 8, ok: true},
+		{ip: "::ffff:203.0.113.7", entry: "203.0.113.7", line: 8, ok: true},
+		{host: "203.0.113.7", entry: "203.0.113.7", line: 8, ok: true},
+		{ip: "10.20.30.40", entry: "10.20.0.0/16", line: 9, ok: true},
+		{ip: "10.21.0.1", ok: false},
+		{ip: "2001:db8::1", entry: "2001:db8::/32", line: 10, ok: true},
+		{ip: "not-an-ip", ok: false},
+	}
+	for _, tc := range cases {
+		match, ok := list.Match(tc.host, tc.ip)
+		if ok != tc.ok {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", tc.host, tc.ip, ok, tc.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if match.Entry != tc.entry || match.Line != tc.line || match.Host != tc.byHost || match.List != "ads.txt" || !match.Strict {
+			t.Errorf("Match(%q, %q) = %+v", tc.host, tc.ip, match)
+		}
+	}
+}
+
+func TestSetFirstListWins(t *testing.T) {
+	sources := []Source{{Name: "first", Path: "a"}, {Name: "second", Path: "b", Strict: true}}
+	set := NewSet(sources)
+	if _, ok := set.Match("ads.example.com", ""); ok {
+		t.Fatal("expected empty slots to match nothing")
+	}
+	first, _ := Parse(sources[0], strings.NewReader("example.com\n"))
+	second, _ := Parse(sources[1], strings.NewReader("ads.example.com\n"))
+	set.Replace(1, second)
+	set.Replace(0, first)
+	match, ok := set.Match("ads.example.com", "")
+	if !ok || match.List != "first" || match.Strict {
+		t.Fatalf("expected the first configured list to win, got %+v", match)
+	}
+	var nilSet *Set
+	if _, ok := nilSet.Match("ads.example.com", ""); ok {
+		t.Fatal("expected a nil set to match nothing")
+	}
+}
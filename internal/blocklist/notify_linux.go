@@ -0,0 +1,65 @@
+package blocklist
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// notify calls changed for each of paths closed after writing or moved into
+// place, until ctx is done.
+func notify(ctx context.Context, paths []string, changed func(string)) error {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+	// A non-blocking fd wrapped in an os.File goes through the runtime
+	// poller, so Close below unblocks the pending Read.
+	file := os.NewFile(uintptr(fd), "inotify")
+	defer file.Close()
+
+	watched := make(map[string]bool, len(paths))
+	dirs := make(map[int32]string)
+	for _, path := range paths {
+		watched[path] = true
+		dir := filepath.Dir(path)
+		wd, err := unix.InotifyAddWatch(fd, dir, unix.IN_CLOSE_WRITE|unix.IN_MOVED_TO)
+		if err != nil {
+			return err
+		}
+		dirs[int32(wd)] = dir
+	}
+
+	go func() {
+		<-ctx.Done()
+		file.Close()
+	}()
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := file.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, os.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(event.Len)]
+			off += unix.SizeofInotifyEvent + int(event.Len)
+			name := string(nameBytes)
+			for len(name) > 0 && name[len(name)-1] == 0 {
+				name = name[:len(name)-1]
+			}
+			path := filepath.Join(dirs[event.Wd], name)
+			if watched[path] {
+				changed(path)
+			}
+		}
+	}
+}
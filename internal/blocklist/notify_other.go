@@ -0,0 +1,13 @@
+//go:build !linux
+
+package blocklist
+
+import (
+	"context"
+	"errors"
+)
+
+// notify is only implemented with inotify; Watch polls elsewhere.
+func notify(context.Context, []string, func(string)) error {
+	return errors.New("file notifications are not supported on this platform")
+}
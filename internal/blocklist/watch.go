@@ -0,0 +1,103 @@
+package blocklist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReloadFunc is told about every load of a source: the new list, or the
+// error that left the previous one in place.
+type ReloadFunc func(src Source, list *List, err error)
+
+// pollInterval is how often files are checked where inotify is unavailable.
+var pollInterval = 5 * time.Second
+
+// LoadAll loads every source into set, in order.
+func LoadAll(set *Set, sources []Source, onReload ReloadFunc) {
+	for i, src := range sources {
+		set.reload(i, src, onReload)
+	}
+}
+
+func (s *Set) reload(i int, src Source, onReload ReloadFunc) {
+	list, err := Load(src)
+	if err == nil {
+		s.Replace(i, list)
+	}
+	if onReload != nil {
+		onReload(src, list, err)
+	}
+}
+
+// Watch reloads a source into set whenever its file is written or
+// replaced, until ctx is done. It uses inotify on the files' directories,
+// so lists rewritten by rename are seen too, and falls back to polling.
+func Watch(ctx context.Context, set *Set, sources []Source, onReload ReloadFunc) {
+	if len(sources) == 0 {
+		return
+	}
+	paths := make([]string, len(sources))
+	for i, src := range sources {
+		paths[i] = absPath(src.Path)
+	}
+	changed := func(path string) {
+		for i, src := range sources {
+			if paths[i] == path {
+				set.reload(i, src, onReload)
+			}
+		}
+	}
+	if err := notify(ctx, paths, changed); err != nil && ctx.Err() == nil {
+		poll(ctx, paths, pollInterval, changed)
+	}
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+type fileStamp struct {
+	mod  time.Time
+	size int64
+	ok   bool
+}
+
+func stampOf(path string) fileStamp {
+	st, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{mod: st.ModTime(), size: st.Size(), ok: true}
+}
+
+// poll calls changed for every path whose modification time or size moved
+// since the last look.
+func poll(ctx context.Context, paths []string, interval time.Duration, changed func(string)) {
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		stamps[path] = stampOf(path)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, path := range paths {
+			stamp := stampOf(path)
+			if stamp != stamps[path] {
+				stamps[path] = stamp
+				if stamp.ok {
+					changed(path)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package blocklist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type reloads chan *List
+
+func (r reloads) record(_ Source, list *List, err error) {
+	if err != nil {
+		list = nil
+	}
+	r <- list
+}
+
+func (r reloads) wait(t *testing.T) *List {
+	t.Helper()
+	select {
+	case list := <-r:
+		return list
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a reload")
+		return nil
+	}
+}
+
+func TestWatchReloadsRewrittenList(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ads.txt")
+	if err := os.WriteFile(path, []byte("ads.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sources := []Source{{Path: path}}
+	set := NewSet(sources)
+	got := make(reloads, 8)
+	LoadAll(set, sources, got.record)
+	if list := got.wait(t); list == nil || list.Entries != 1 {
+		t.Fatalf("expected the initial load, got %+v", list)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Watch(ctx, set, sources, got.record)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// Give the watch a moment to be registered.
+	time.Sleep(100 * time.Millisecond)
+
+	// Replace the file the way editors and blockers do, by rename.
+	tmp := filepath.Join(dir, ".ads.txt.tmp")
+	if err := os.WriteFile(tmp, []byte("ads.example.com\ntracker.example.net\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		list := got.wait(t)
+		if list != nil && list.Entries == 2 {
+			break
+		}
+	}
+	if _, ok := set.Match("tracker.example.net", ""); !ok {
+		t.Fatal("expected the reloaded entry to match")
+	}
+}
+
+func TestReloadFailureKeepsPreviousList(t *testing.T) {
+	dir := t.TempDir()
+	sources := []Source{{Path: filepath.Join(dir, "ads.txt")}}
+	if err := os.WriteFile(sources[0].Path, []byte("ads.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	set := NewSet(sources)
+	got := make(reloads, 2)
+	LoadAll(set, sources, got.record)
+	got.wait(t)
+
+	os.Remove(sources[0].Path)
+	LoadAll(set, sources, got.record)
+	if list := got.wait(t); list != nil {
+		t.Fatalf("expected a load error, got %+v", list)
+	}
+	if _, ok := set.Match("ads.example.com", ""); !ok {
+		t.Fatal("expected the previous entries kept")
+	}
+}
+
+func TestPollNoticesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ads.txt")
+	if err := os.WriteFile(path, []byte("a.example\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan string, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go poll(ctx, []string{path}, 10*time.Millisecond, func(p string) { changed <- p })
+	time.Sleep(30 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("a.example\nb.example\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-changed:
+		if got != path {
+			t.Fatalf("unexpected path %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the change noticed")
+	}
+}
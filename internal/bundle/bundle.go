@@ -0,0 +1,443 @@
+// Package bundle packs the TUI's local state, the config file, the rule
+// cache, the rule trash, the baselines and the rule history, into a tar.gz
+// with a manifest, and unpacks it on another machine.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/baseline"
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulecache"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulehistory"
+	"github.com/adamkadaban/opensnitch-tui/internal/trash"
+)
+
+// FormatVersion is the bundle layout this build writes; it reads this and
+// older ones. Format 2 added the trash, baselines and rule history.
+const FormatVersion = 2
+
+// Archive entry names. Everything else in a bundle is rejected.
+const (
+	manifestName    = "manifest.json"
+	configName      = "config.yaml"
+	rulesDir        = "rules"
+	trashName       = "trash.json"
+	baselinesName   = "baselines.json"
+	ruleHistoryName = "rule-history.json"
+)
+
+// Entry kinds recorded in the manifest.
+const (
+	KindConfig      = "config"
+	KindRuleCache   = "rule-cache"
+	KindTrash       = "trash"
+	KindBaselines   = "baselines"
+	KindRuleHistory = "rule-history"
+)
+
+// maxEntrySize bounds each archived file so a hostile bundle cannot fill
+// memory; real configs and rule caches are far smaller.
+const maxEntrySize = 16 << 20
+
+// ErrNewerBundle is returned for bundles written by a newer build; --force
+// imports them anyway.
+var ErrNewerBundle = errors.New("bundle is from a newer version")
+
+// Manifest describes a bundle: the versions it was written with and where
+// each file came from.
+type Manifest struct {
+	Format int `json:"format"`
+	// ConfigSchema is the schema_version of the bundled config.
+	ConfigSchema int       `json:"config_schema"`
+	CreatedAt    time.Time `json:"created_at"`
+	Files        []File    `json:"files"`
+}
+
+// File is one bundled file.
+type File struct {
+	// Name is the path inside the archive.
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Source is where the file was exported from.
+	Source string `json:"source"`
+	Size   int64  `json:"size"`
+}
+
+// Paths are where the bundled state lives on this machine.
+type Paths struct {
+	Config      string
+	RuleCache   string
+	Trash       string
+	Baselines   string
+	RuleHistory string
+}
+
+// DefaultPaths resolves the config file (configPath when set), the rule
+// cache directory and the state files, honouring XDG_CONFIG_HOME and
+// XDG_CACHE_HOME.
+func DefaultPaths(configPath string) (Paths, error) {
+	cfg, err := config.ResolvePath(configPath)
+	if err != nil {
+		return Paths{}, fmt.Errorf("resolve config: %w", err)
+	}
+	cache, err := rulecache.DefaultDir()
+	if err != nil {
+		return Paths{}, fmt.Errorf("resolve rule cache: %w", err)
+	}
+	paths := Paths{Config: cfg, RuleCache: cache}
+	for _, resolve := range []struct {
+		kind   string
+		target *string
+		path   func() (string, error)
+	}{
+		{KindTrash, &paths.Trash, trash.DefaultPath},
+		{KindBaselines, &paths.Baselines, baseline.DefaultPath},
+		{KindRuleHistory, &paths.RuleHistory, rulehistory.DefaultPath},
+	} {
+		if *resolve.target, err = resolve.path(); err != nil {
+			return Paths{}, fmt.Errorf("resolve %s: %w", resolve.kind, err)
+		}
+	}
+	return paths, nil
+}
+
+// stateFile is a single JSON file bundled under a fixed name.
+type stateFile struct {
+	name, kind, path string
+}
+
+// stateFiles lists the single-file state under paths; an empty path leaves
+// that file out.
+func (p Paths) stateFiles() []stateFile {
+	var files []stateFile
+	for _, f := range []stateFile{
+		{trashName, KindTrash, p.Trash},
+		{baselinesName, KindBaselines, p.Baselines},
+		{ruleHistoryName, KindRuleHistory, p.RuleHistory},
+	} {
+		if f.path != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// kindOf is the manifest kind of a checked entry name.
+func kindOf(name string) string {
+	switch name {
+	case configName:
+		return KindConfig
+	case trashName:
+		return KindTrash
+	case baselinesName:
+		return KindBaselines
+	case ruleHistoryName:
+		return KindRuleHistory
+	}
+	return KindRuleCache
+}
+
+// Export writes the state under paths to w as a gzipped tar. Missing parts
+// are left out; a bundle without a config file is an error.
+func Export(w io.Writer, paths Paths, now time.Time) (Manifest, error) {
+	manifest := Manifest{Format: FormatVersion, CreatedAt: now.UTC()}
+	type source struct {
+		file File
+		data []byte
+	}
+	var sources []source
+
+	data, err := os.ReadFile(paths.Config)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("read config: %w", err)
+	}
+	manifest.ConfigSchema = schemaVersion(data)
+	sources = append(sources, source{File{Name: configName, Kind: KindConfig, Source: paths.Config, Size: int64(len(data))}, data})
+
+	cached, err := filepath.Glob(filepath.Join(paths.RuleCache, "*.json"))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("list rule cache: %w", err)
+	}
+	sort.Strings(cached)
+	for _, file := range cached {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read rule cache: %w", err)
+		}
+		name := path.Join(rulesDir, filepath.Base(file))
+		sources = append(sources, source{File{Name: name, Kind: KindRuleCache, Source: file, Size: int64(len(data))}, data})
+	}
+	for _, f := range paths.stateFiles() {
+		data, err := os.ReadFile(f.path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("read %s: %w", f.kind, err)
+		}
+		sources = append(sources, source{File{Name: f.name, Kind: f.kind, Source: f.path, Size: int64(len(data))}, data})
+	}
+	for _, src := range sources {
+		manifest.Files = append(manifest.Files, src.file)
+	}
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("encode manifest: %w", err)
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: now, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write(manifestName, encoded); err != nil {
+		return Manifest{}, fmt.Errorf("write bundle: %w", err)
+	}
+	for _, src := range sources {
+		if err := write(src.file.Name, src.data); err != nil {
+			return Manifest{}, fmt.Errorf("write bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("write bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// Import unpacks a bundle from r into paths. The whole archive is read and
+// checked before anything is written: entries must be the manifest, the
+// config, rule cache or state files, the config must load, and a bundle
+// from a newer format or config schema is refused unless force is set. An
+// existing config is kept as config.yaml.bak. The returned manifest lists
+// the files unpacked.
+func Import(r io.Reader, paths Paths, force bool) (Manifest, error) {
+	files, err := readArchive(r)
+	if err != nil {
+		return Manifest{}, err
+	}
+	raw, ok := files[manifestName]
+	if !ok {
+		return Manifest{}, fmt.Errorf("bundle has no %s", manifestName)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("read manifest: %w", err)
+	}
+	delete(files, manifestName)
+	cfg, ok := files[configName]
+	if !ok {
+		return Manifest{}, fmt.Errorf("bundle has no %s", configName)
+	}
+	if !force {
+		if manifest.Format > FormatVersion {
+			return Manifest{}, fmt.Errorf("%w: format %d, this build reads %d (use --force to import anyway)", ErrNewerBundle, manifest.Format, FormatVersion)
+		}
+		if schema := max(manifest.ConfigSchema, schemaVersion(cfg)); schema > config.CurrentSchemaVersion {
+			return Manifest{}, fmt.Errorf("%w: config schema %d, this build reads %d (use --force to import anyway)", ErrNewerBundle, schema, config.CurrentSchemaVersion)
+		}
+	}
+	// A forced config from a newer schema cannot be checked by this build.
+	if _, err := config.Parse(cfg); err != nil && !(force && errors.Is(err, config.ErrNewerSchema)) {
+		return Manifest{}, fmt.Errorf("bundled %s: %w", configName, err)
+	}
+	var state []stateFile
+	for _, f := range paths.stateFiles() {
+		data, ok := files[f.name]
+		if !ok {
+			continue
+		}
+		if !json.Valid(data) {
+			return Manifest{}, fmt.Errorf("bundled %s is not valid JSON", f.name)
+		}
+		state = append(state, f)
+	}
+
+	if err := writeConfig(paths.Config, cfg); err != nil {
+		return Manifest{}, err
+	}
+	for _, f := range state {
+		if err := persist.WriteFile(f.path, files[f.name]); err != nil {
+			return Manifest{}, fmt.Errorf("write %s: %w", f.kind, err)
+		}
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if kindOf(name) == KindRuleCache {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		if err := os.MkdirAll(paths.RuleCache, 0o700); err != nil {
+			return Manifest{}, fmt.Errorf("ensure rule cache dir: %w", err)
+		}
+	}
+	for _, name := range names {
+		target, err := within(paths.RuleCache, path.Base(name))
+		if err != nil {
+			return Manifest{}, err
+		}
+		if err := persist.WriteFile(target, files[name]); err != nil {
+			return Manifest{}, fmt.Errorf("write rule cache: %w", err)
+		}
+	}
+	manifest.Files = imported(manifest.Files, files, paths)
+	return manifest, nil
+}
+
+// imported lists what was actually unpacked, so a manifest that disagrees
+// with its archive is not reported as is. State files with no target path
+// were skipped.
+func imported(listed []File, files map[string][]byte, paths Paths) []File {
+	targets := map[string]bool{configName: true}
+	for _, f := range paths.stateFiles() {
+		targets[f.name] = true
+	}
+	sources := make(map[string]string, len(listed))
+	for _, file := range listed {
+		sources[file.Name] = file.Source
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if kind := kindOf(name); kind == KindRuleCache || targets[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// The config leads, as it does in an exported manifest.
+		if (names[i] == configName) != (names[j] == configName) {
+			return names[i] == configName
+		}
+		return names[i] < names[j]
+	})
+	out := make([]File, 0, len(names))
+	for _, name := range names {
+		out = append(out, File{Name: name, Kind: kindOf(name), Source: sources[name], Size: int64(len(files[name]))})
+	}
+	return out
+}
+
+// readArchive reads every entry of a gzipped tar into memory, rejecting
+// anything but regular files with expected names.
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read bundle: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read bundle: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("bundle entry %q is not a regular file", header.Name)
+		}
+		name, err := checkName(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := files[name]; dup {
+			return nil, fmt.Errorf("bundle entry %q appears twice", name)
+		}
+		if header.Size > maxEntrySize {
+			return nil, fmt.Errorf("bundle entry %q is too large (%d bytes)", name, header.Size)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxEntrySize+1))
+		if err != nil {
+			return nil, fmt.Errorf("read bundle: %w", err)
+		}
+		if len(data) > maxEntrySize {
+			return nil, fmt.Errorf("bundle entry %q is too large", name)
+		}
+		files[name] = data
+	}
+}
+
+// checkName accepts the manifest, the config, the state files, rule cache
+// files directly under rules/, and nothing that could land outside the
+// target directories.
+func checkName(name string) (string, error) {
+	if name == "" || strings.Contains(name, `\`) || path.IsAbs(name) {
+		return "", fmt.Errorf("bundle entry %q has an unsafe path", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("bundle entry %q has an unsafe path", name)
+		}
+	}
+	clean := path.Clean(name)
+	switch {
+	case clean == manifestName, clean == configName, clean == trashName, clean == baselinesName, clean == ruleHistoryName:
+		return clean, nil
+	case path.Dir(clean) == rulesDir && path.Ext(clean) == ".json" && !strings.HasPrefix(path.Base(clean), "."):
+		return clean, nil
+	}
+	return "", fmt.Errorf("bundle entry %q is not part of a TUI bundle", name)
+}
+
+// within joins name onto dir and makes sure the result stays inside dir.
+func within(dir, name string) (string, error) {
+	target := filepath.Join(dir, name)
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("bundle entry %q escapes %s", name, dir)
+	}
+	return target, nil
+}
+
+func writeConfig(target string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("ensure config dir: %w", err)
+	}
+	if current, err := os.ReadFile(target); err == nil {
+		if err := persist.WriteFile(target+".bak", current); err != nil {
+			return fmt.Errorf("back up config: %w", err)
+		}
+	}
+	if err := persist.WriteFile(target, data); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// schemaVersion reads schema_version from a config file; files too old to
+// carry one are version 0.
+func schemaVersion(data []byte) int {
+	var doc struct {
+		SchemaVersion int `yaml:"schema_version"`
+	}
+	_ = yaml.Unmarshal(data, &doc)
+	return doc.SchemaVersion
+}
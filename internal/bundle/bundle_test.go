@@ -0,0 +1,263 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+)
+
+func testPaths(t *testing.T) Paths {
+	t.Helper()
+	root := t.TempDir()
+	cache := filepath.Join(root, "cache", "opensnitch-tui")
+	return Paths{
+		Config:      filepath.Join(root, "config", "opensnitch-tui", "config.yaml"),
+		RuleCache:   filepath.Join(cache, "rules"),
+		Trash:       filepath.Join(cache, "trash.json"),
+		Baselines:   filepath.Join(cache, "baselines.json"),
+		RuleHistory: filepath.Join(cache, "rule-history.json"),
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+type entry struct {
+	name     string
+	body     string
+	typeflag byte
+	linkname string
+}
+
+// archive builds a bundle by hand, so tests can craft entries Export never
+// writes.
+func archive(t *testing.T, entries ...entry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		typeflag := e.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		header := &tar.Header{Name: e.name, Mode: 0o600, Size: int64(len(e.body)), Typeflag: typeflag, Linkname: e.linkname}
+		if typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func manifestEntry(format, schema int) entry {
+	return entry{name: manifestName, body: fmt.Sprintf(`{"format": %d, "config_schema": %d}`, format, schema)}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := testPaths(t)
+	cfg := fmt.Sprintf("schema_version: %d\ntheme: dawn\n", config.CurrentSchemaVersion)
+	writeTestFile(t, src.Config, cfg)
+	writeTestFile(t, filepath.Join(src.RuleCache, "0123456789abcdef.json"), `{"rules":[]}`)
+	writeTestFile(t, filepath.Join(src.RuleCache, "notes.txt"), "not a cache file")
+	writeTestFile(t, src.Trash, `{"version":1,"entries":[]}`)
+	writeTestFile(t, src.RuleHistory, `{"version":1,"changes":[]}`)
+
+	var buf bytes.Buffer
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	manifest, err := Export(&buf, src, now)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if manifest.Format != FormatVersion || manifest.ConfigSchema != config.CurrentSchemaVersion || !manifest.CreatedAt.Equal(now) {
+		t.Fatalf("unexpected manifest header %+v", manifest)
+	}
+	if len(manifest.Files) != 4 || manifest.Files[0].Name != "config.yaml" || manifest.Files[1].Name != "rules/0123456789abcdef.json" ||
+		manifest.Files[2].Name != "trash.json" || manifest.Files[3].Name != "rule-history.json" {
+		t.Fatalf("unexpected manifest files %+v", manifest.Files)
+	}
+	if manifest.Files[0].Source != src.Config || manifest.Files[1].Kind != KindRuleCache || manifest.Files[2].Kind != KindTrash || manifest.Files[3].Kind != KindRuleHistory {
+		t.Fatalf("expected sources and kinds recorded, got %+v", manifest.Files)
+	}
+
+	dst := testPaths(t)
+	writeTestFile(t, dst.Config, "theme: midnight\n")
+	got, err := Import(&buf, dst, false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(got.Files) != 4 || got.Files[0].Source != src.Config {
+		t.Fatalf("expected the unpacked files reported, got %+v", got.Files)
+	}
+	if readTestFile(t, dst.Config) != cfg {
+		t.Fatalf("config not restored")
+	}
+	if readTestFile(t, dst.Config+".bak") != "theme: midnight\n" {
+		t.Fatalf("expected the previous config backed up")
+	}
+	if readTestFile(t, filepath.Join(dst.RuleCache, "0123456789abcdef.json")) != `{"rules":[]}` {
+		t.Fatalf("rule cache not restored")
+	}
+	if _, err := os.Stat(filepath.Join(dst.RuleCache, "notes.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected only cache files bundled")
+	}
+	if readTestFile(t, dst.Trash) != `{"version":1,"entries":[]}` || readTestFile(t, dst.RuleHistory) != `{"version":1,"changes":[]}` {
+		t.Fatalf("state files not restored")
+	}
+	if _, err := os.Stat(dst.Baselines); !os.IsNotExist(err) {
+		t.Fatalf("expected no baselines file when none was bundled")
+	}
+}
+
+func TestImportRefusesInvalidContents(t *testing.T) {
+	cases := map[string][]entry{
+		"unknown view":       {{name: "config.yaml", body: "profiles: [{name: x, view: firewall}]\n"}},
+		"config not yaml":    {{name: "config.yaml", body: "theme: [\n"}},
+		"config not mapping": {{name: "config.yaml", body: "- theme\n"}},
+		"trash not json":     {{name: "config.yaml", body: "theme: dawn\n"}, {name: "trash.json", body: "{"}},
+	}
+	for name, entries := range cases {
+		t.Run(name, func(t *testing.T) {
+			paths := testPaths(t)
+			writeTestFile(t, paths.Config, "theme: midnight\n")
+			if _, err := Import(archive(t, append([]entry{manifestEntry(FormatVersion, 0)}, entries...)...), paths, true); err == nil {
+				t.Fatal("expected the bundle to be refused")
+			}
+			if readTestFile(t, paths.Config) != "theme: midnight\n" {
+				t.Fatal("expected the current config kept")
+			}
+			if _, err := os.Stat(paths.Trash); !os.IsNotExist(err) {
+				t.Fatal("expected nothing else written")
+			}
+		})
+	}
+}
+
+func TestExportRequiresConfig(t *testing.T) {
+	if _, err := Export(&bytes.Buffer{}, testPaths(t), time.Now()); err == nil {
+		t.Fatalf("expected an error without a config file")
+	}
+}
+
+func TestImportRejectsUnsafeEntries(t *testing.T) {
+	cases := []struct {
+		name  string
+		entry entry
+	}{
+		{"parent traversal", entry{name: "../../.bashrc", body: "x"}},
+		{"traversal under rules", entry{name: "rules/../../escape.json", body: "x"}},
+		{"absolute path", entry{name: "/etc/passwd", body: "x"}},
+		{"backslash", entry{name: `rules\..\..\escape.json`, body: "x"}},
+		{"nested rules dir", entry{name: "rules/sub/a.json", body: "x"}},
+		{"unknown file", entry{name: "autostart.sh", body: "x"}},
+		{"hidden cache file", entry{name: "rules/.hidden.json", body: "x"}},
+		{"symlink", entry{name: "rules/a.json", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}},
+		{"hardlink", entry{name: "config.yaml", typeflag: tar.TypeLink, linkname: "/etc/shadow"}},
+		{"duplicate", entry{name: "./config.yaml", body: "theme: dawn\n"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths := testPaths(t)
+			buf := archive(t, manifestEntry(FormatVersion, 0), entry{name: "config.yaml", body: "theme: dawn\n"}, tc.entry)
+			if _, err := Import(buf, paths, true); err == nil {
+				t.Fatalf("expected %q to be rejected", tc.entry.name)
+			}
+			if _, err := os.Stat(paths.Config); !os.IsNotExist(err) {
+				t.Fatalf("expected nothing written after a rejected bundle")
+			}
+			if _, err := os.Stat(filepath.Dir(paths.RuleCache)); !os.IsNotExist(err) {
+				t.Fatalf("expected no cache dir created after a rejected bundle")
+			}
+		})
+	}
+}
+
+func TestImportRefusesNewerBundles(t *testing.T) {
+	newer := config.CurrentSchemaVersion + 1
+	cases := []struct {
+		name    string
+		entries []entry
+	}{
+		{"newer format", []entry{manifestEntry(FormatVersion+1, 0), {name: "config.yaml", body: "theme: dawn\n"}}},
+		{"newer schema in manifest", []entry{manifestEntry(FormatVersion, newer), {name: "config.yaml", body: "theme: dawn\n"}}},
+		{"newer schema in config", []entry{manifestEntry(FormatVersion, 0), {name: "config.yaml", body: fmt.Sprintf("schema_version: %d\n", newer)}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths := testPaths(t)
+			_, err := Import(archive(t, tc.entries...), paths, false)
+			if !errors.Is(err, ErrNewerBundle) || !strings.Contains(err.Error(), "--force") {
+				t.Fatalf("expected a newer-bundle error mentioning --force, got %v", err)
+			}
+			if _, err := os.Stat(paths.Config); !os.IsNotExist(err) {
+				t.Fatalf("expected nothing written for a refused bundle")
+			}
+			if _, err := Import(archive(t, tc.entries...), paths, true); err != nil {
+				t.Fatalf("expected --force to import, got %v", err)
+			}
+			if _, err := os.Stat(paths.Config); err != nil {
+				t.Fatalf("expected the config written with --force: %v", err)
+			}
+		})
+	}
+}
+
+func TestImportRequiresManifestAndConfig(t *testing.T) {
+	if _, err := Import(archive(t, entry{name: "config.yaml", body: "theme: dawn\n"}), testPaths(t), true); err == nil {
+		t.Fatalf("expected a bundle without a manifest to be rejected")
+	}
+	if _, err := Import(archive(t, manifestEntry(FormatVersion, 0)), testPaths(t), true); err == nil {
+		t.Fatalf("expected a bundle without a config to be rejected")
+	}
+}
+
+func TestDefaultPathsHonourXDG(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "cfg"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "cache"))
+	paths, err := DefaultPaths("")
+	if err != nil {
+		t.Fatalf("DefaultPaths: %v", err)
+	}
+	if !strings.HasPrefix(paths.Config, filepath.Join(root, "cfg")) || !strings.HasPrefix(paths.RuleCache, filepath.Join(root, "cache")) {
+		t.Fatalf("expected XDG dirs to be used, got %+v", paths)
+	}
+}
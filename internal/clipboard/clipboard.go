@@ -0,0 +1,61 @@
+// Package clipboard copies text to the system clipboard through the terminal
+// with an OSC 52 escape sequence, which also works over SSH. Terminals that
+// do not support OSC 52 silently ignore it.
+package clipboard
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+)
+
+// Msg asks the program to place Text on the clipboard. Commands run on
+// their own goroutines, so the sequence is not written there: the root model
+// hands it to Write from the update loop, as Bubble Tea does with the window
+// title, and it goes out in one write that cannot split a rendered frame.
+type Msg struct {
+	Text string
+}
+
+// Copy returns a command that places text on the clipboard.
+func Copy(text string) tea.Cmd {
+	return func() tea.Msg {
+		return Msg{Text: text}
+	}
+}
+
+// Write sends the OSC 52 sequence for msg to out, the program's output.
+func Write(out io.Writer, msg Msg) {
+	termenv.NewOutput(out).Copy(msg.Text)
+}
+
+// Supported reports whether the terminal is likely to act on OSC 52.
+// There is no way to ask, but the Linux console and dumb terminals are
+// known to drop it, and text copied there would be lost.
+func Supported() bool {
+	switch os.Getenv("TERM") {
+	case "", "dumb", "linux":
+		return false
+	}
+	return true
+}
+
+// Save writes text to clipboard.txt under XDG_STATE_HOME (~/.local/state),
+// for terminals that cannot take OSC 52, and returns the file's path.
+// Each save replaces the last.
+func Save(text string) (string, error) {
+	dir, err := persist.StateDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "clipboard.txt")
+	if err := persist.WriteFile(path, []byte(text)); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,51 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyWritesOSC52(t *testing.T) {
+	msg, ok := Copy("d41d8cd98f00b204e9800998ecf8427e")().(Msg)
+	if !ok || msg.Text != "d41d8cd98f00b204e9800998ecf8427e" {
+		t.Fatalf("expected a clipboard message, got %#v", msg)
+	}
+	var buf bytes.Buffer
+	Write(&buf, msg)
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("d41d8cd98f00b204e9800998ecf8427e"))
+	if !strings.Contains(buf.String(), want) {
+		t.Fatalf("expected OSC 52 sequence %q, got %q", want, buf.String())
+	}
+}
+
+func TestSupportedByTerm(t *testing.T) {
+	for term, want := range map[string]bool{"": false, "dumb": false, "linux": false, "xterm-256color": true, "tmux-256color": true} {
+		t.Setenv("TERM", term)
+		if got := Supported(); got != want {
+			t.Fatalf("TERM=%q: expected %v, got %v", term, want, got)
+		}
+	}
+}
+
+func TestSaveWritesUnderStateHome(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+
+	path, err := Save("first")
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := Save("second"); err != nil {
+		t.Fatalf("save again: %v", err)
+	}
+	if want := filepath.Join(dir, "opensnitch-tui", "clipboard.txt"); path != want {
+		t.Fatalf("expected %s, got %s", want, path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "second" {
+		t.Fatalf("expected the last save kept, got %q (%v)", data, err)
+	}
+}
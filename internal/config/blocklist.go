@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Blocklist is a local file of known-bad domains, addresses and CIDRs,
+// one per line, checked against the destination of every prompt.
+type Blocklist struct {
+	Path string `yaml:"path"`
+	// Name is shown in prompts; empty uses the file name.
+	Name string `yaml:"name,omitempty"`
+	// Strict denies matching connections without prompting.
+	Strict bool `yaml:"strict,omitempty"`
+}
+
+func validateBlocklists(lists []Blocklist) []string {
+	var errs []string
+	seen := make(map[string]bool, len(lists))
+	for i, list := range lists {
+		prefix := fmt.Sprintf("blocklists[%d]", i)
+		if strings.TrimSpace(list.Path) == "" {
+			errs = append(errs, prefix+": path is required")
+			continue
+		}
+		name := list.Name
+		if name == "" {
+			name = filepath.Base(list.Path)
+		}
+		if seen[name] {
+			errs = append(errs, fmt.Sprintf("%s: duplicate name %q", prefix, name))
+		}
+		seen[name] = true
+	}
+	return errs
+}
@@ -7,10 +7,14 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/defaults"
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
 )
 
 const (
@@ -22,20 +26,71 @@ const (
 	ThemeDawn     = "dawn"
 )
 
-const DefaultThemeName = ThemeMidnight
+const DefaultThemeName = defaults.ThemeName
 
 // Config captures persisted user preferences and known daemon nodes.
 type Config struct {
+	// SchemaVersion is stamped by Save; older files are migrated on load.
+	SchemaVersion         int    `yaml:"schema_version"`
 	Theme                 string `yaml:"theme"`
 	DefaultPromptAction   string `yaml:"default_prompt_action"`
 	DefaultPromptDuration string `yaml:"default_prompt_duration"`
 	DefaultPromptTarget   string `yaml:"default_prompt_target"`
 	PromptTimeoutSeconds  int    `yaml:"prompt_timeout_seconds"`
+	PromptInitialFocus    string `yaml:"prompt_initial_focus"`
+	PromptAutoJump        bool   `yaml:"prompt_auto_jump"`
 	AlertsInterrupt       bool   `yaml:"alerts_interrupt"`
 	PausePromptOnInspect  bool   `yaml:"pause_prompt_on_inspect"`
 	YaraRuleDir           string `yaml:"yara_rule_dir"`
 	YaraEnabled           bool   `yaml:"yara_enabled"`
-	Nodes                 []Node `yaml:"nodes"`
+	// InspectHook is a scanner command run against prompting binaries on
+	// inspect, with "{path}" substituted. It is only read from the file.
+	InspectHook        string `yaml:"inspect_hook"`
+	InspectHookEnabled bool   `yaml:"inspect_hook_enabled"`
+	// InspectSections lists the inspect panel sections expanded when the
+	// TUI starts; see InspectSectionNames.
+	InspectSections []string `yaml:"inspect_sections"`
+	DNDMinutes      int      `yaml:"dnd_minutes"`
+	// MaintenanceAction and MaintenanceDuration answer prompts from nodes
+	// in maintenance.
+	MaintenanceAction   string `yaml:"maintenance_action"`
+	MaintenanceDuration string `yaml:"maintenance_duration"`
+	ClockSkewCorrection bool   `yaml:"clock_skew_correction"`
+	StartView           string `yaml:"start_view"`
+	// UIDZeroUnknown treats a reported UID of 0 as unresolved rather than
+	// root, for daemons that report 0 when the owner lookup fails.
+	UIDZeroUnknown bool `yaml:"uid_zero_unknown"`
+	// SlowAckSeconds is how long an action may wait for the daemon's ack
+	// before it is flagged as slow; zero uses the built-in default.
+	SlowAckSeconds int `yaml:"slow_ack_seconds"`
+	// BulkParallelism caps how many nodes a bulk action works on at once;
+	// zero uses the built-in default.
+	BulkParallelism int `yaml:"bulk_parallelism"`
+	// RuleHitFreshMinutes and RuleHitRecentMinutes set the Rules view's
+	// last-hit dot: green for rules matched within the first, yellow within
+	// the second. Zero keeps the defaults of 5 and 60.
+	RuleHitFreshMinutes  int `yaml:"rule_hit_fresh_minutes"`
+	RuleHitRecentMinutes int `yaml:"rule_hit_recent_minutes"`
+	// Rule field length limits in characters; zero uses the built-in default.
+	MaxOperatorDataLength int `yaml:"max_operator_data_length"`
+	MaxRuleTextLength     int `yaml:"max_rule_text_length"`
+	// ConnectionBuffer is how many connections the Connections view keeps;
+	// zero uses the built-in default.
+	ConnectionBuffer int `yaml:"connection_buffer"`
+	// RuleNameTemplate is a text/template for names of rules created from
+	// prompts; empty uses the built-in default.
+	RuleNameTemplate string `yaml:"rule_name_template"`
+	// RuleDescriptionTemplate is a text/template for descriptions of rules
+	// created from prompts; empty uses the built-in default.
+	RuleDescriptionTemplate string `yaml:"rule_description_template"`
+	// ASCIIOnly draws the UI with ASCII characters only when true, and
+	// with Unicode glyphs when false; unset follows the locale.
+	ASCIIOnly *bool `yaml:"ascii_only,omitempty"`
+	// Blocklists are checked against prompt destinations, first match wins.
+	Blocklists []Blocklist `yaml:"blocklists,omitempty"`
+	// Profiles are the workspace layouts ctrl+w cycles through.
+	Profiles []Profile `yaml:"profiles,omitempty"`
+	Nodes    []Node    `yaml:"nodes"`
 }
 
 // Node contains metadata required to connect to an OpenSnitch daemon instance.
@@ -47,14 +102,26 @@ type Node struct {
 	KeyPath   string `yaml:"key_path"`
 	SkipTLS   bool   `yaml:"skip_tls"`
 	Authority string `yaml:"authority"`
+	// InteractivePrompts set to false answers the node's prompts with the
+	// defaults without showing them; unset means true.
+	InteractivePrompts *bool `yaml:"interactive_prompts,omitempty"`
 }
 
 // Validate checks the configuration for common errors.
 func Validate(cfg Config) error {
 	var errs []string
 
+	for _, name := range cfg.InspectSections {
+		if !slices.Contains(InspectSectionNames, name) {
+			errs = append(errs, fmt.Sprintf("inspect_sections: unknown section %q (want one of %s)", name, strings.Join(InspectSectionNames, ", ")))
+		}
+	}
+
+	errs = append(errs, validateProfiles(cfg.Profiles)...)
+	errs = append(errs, validateBlocklists(cfg.Blocklists)...)
+
 	for i, n := range cfg.Nodes {
-		if err := validateNode(n); err != nil {
+		if err := ValidateNode(n); err != nil {
 			errs = append(errs, fmt.Sprintf("nodes[%d]: %v", i, err))
 		}
 	}
@@ -65,7 +132,9 @@ func Validate(cfg Config) error {
 	return nil
 }
 
-func validateNode(n Node) error {
+// ValidateNode checks that a node has a usable address and that its TLS
+// files exist.
+func ValidateNode(n Node) error {
 	if strings.TrimSpace(n.Address) == "" {
 		return errors.New("address is required")
 	}
@@ -96,9 +165,23 @@ func validateNode(n Node) error {
 	return nil
 }
 
+// LoadOptions adjusts how LoadWithOptions reads the file.
+type LoadOptions struct {
+	// Strict rejects keys the schema does not know, such as misspelled ones.
+	Strict bool
+}
+
 // Load reads configuration data from the provided path. If the file does not exist,
 // a default configuration is returned without an error.
 func Load(path string) (Config, error) {
+	return LoadWithOptions(path, LoadOptions{})
+}
+
+// LoadWithOptions is Load with options. Files from older schema versions
+// are upgraded and rewritten, keeping the original as a .bak; if that
+// rewrite fails the upgraded config is returned with an error wrapping
+// ErrMigrationNotSaved.
+func LoadWithOptions(path string, opts LoadOptions) (Config, error) {
 	cfg := Default()
 
 	resolved, err := resolvePath(path)
@@ -114,28 +197,59 @@ func Load(path string) (Config, error) {
 		return cfg, fmt.Errorf("read config: %w", err)
 	}
 
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return Config{}, fmt.Errorf("decode config: %w", err)
+	cfg, current, migrated, err := parse(data, opts.Strict)
+	if err != nil {
+		return Config{}, err
 	}
+	if migrated {
+		return cfg, rewriteMigrated(resolved, data, current)
+	}
+	return cfg, nil
+}
+
+// Parse decodes and validates the contents of a config file the way Load
+// does, upgrading older schemas in memory only.
+func Parse(data []byte) (Config, error) {
+	cfg, _, _, err := parse(data, false)
+	return cfg, err
+}
 
+// parse returns the config in data along with the migrated document and
+// whether migrating changed it.
+func parse(data []byte, strict bool) (Config, []byte, bool, error) {
+	cfg := Default()
+	current, migrated, err := migrate(data)
+	if err != nil {
+		return Config{}, nil, false, fmt.Errorf("decode config: %w", err)
+	}
+	if err := decode(current, &cfg, strict); err != nil {
+		return Config{}, nil, false, fmt.Errorf("decode config: %w", err)
+	}
 	if err := Validate(cfg); err != nil {
-		return Config{}, fmt.Errorf("invalid config: %w", err)
+		return Config{}, nil, false, fmt.Errorf("invalid config: %w", err)
 	}
-
-	return cfg, nil
+	return cfg, current, migrated, nil
 }
 
 // Default returns a usable configuration when no file exists yet.
 func Default() Config {
 	return Config{
+		SchemaVersion:         CurrentSchemaVersion,
 		Theme:                 DefaultThemeName,
 		DefaultPromptAction:   DefaultPromptAction,
 		DefaultPromptDuration: DefaultPromptDuration,
 		DefaultPromptTarget:   DefaultPromptTarget,
+		PromptInitialFocus:    DefaultPromptInitialFocus,
 		PromptTimeoutSeconds:  DefaultPromptTimeoutSeconds,
 		AlertsInterrupt:       DefaultAlertsInterrupt,
 		PausePromptOnInspect:  DefaultPausePromptOnInspect,
+		InspectSections:       slices.Clone(DefaultInspectSections),
 		YaraEnabled:           DefaultYaraEnabled,
+		DNDMinutes:            DefaultDNDMinutes,
+		MaintenanceAction:     DefaultMaintenanceAction,
+		MaintenanceDuration:   DefaultMaintenanceDuration,
+		ClockSkewCorrection:   DefaultClockSkewCorrection,
+		StartView:             DefaultStartView,
 		Nodes:                 []Node{},
 	}
 }
@@ -157,13 +271,26 @@ func resolvePath(path string) (string, error) {
 	return DefaultPath()
 }
 
-const DefaultPromptAction = "deny"
-const DefaultPromptDuration = "once"
-const DefaultPromptTarget = "process.path"
-const DefaultPromptTimeoutSeconds = 30
-const DefaultAlertsInterrupt = true
-const DefaultPausePromptOnInspect = true
-const DefaultYaraEnabled = false
+const DefaultPromptAction = defaults.PromptAction
+const DefaultPromptDuration = defaults.PromptDuration
+const DefaultPromptTarget = defaults.PromptTarget
+const DefaultPromptInitialFocus = defaults.PromptInitialFocus
+const DefaultPromptTimeoutSeconds = defaults.PromptTimeoutSeconds
+const DefaultAlertsInterrupt = defaults.AlertsInterrupt
+const DefaultPausePromptOnInspect = defaults.PausePromptOnInspect
+
+// InspectSectionNames are the inspect panel sections, in display order.
+var InspectSectionNames = []string{"identity", "yara", "tree", "sockets", "env", "binary"}
+
+// DefaultInspectSections are expanded unless inspect_sections says otherwise.
+var DefaultInspectSections = []string{"identity", "yara", "tree", "binary"}
+
+const DefaultYaraEnabled = defaults.YaraEnabled
+const DefaultDNDMinutes = defaults.DNDMinutes
+const DefaultMaintenanceAction = defaults.MaintenanceAction
+const DefaultMaintenanceDuration = defaults.MaintenanceDuration
+const DefaultClockSkewCorrection = defaults.ClockSkewCorrection
+const DefaultStartView = "dashboard"
 
 // NormalizePromptAction ensures stored prompts actions stay within supported values.
 func NormalizePromptAction(action string) string {
@@ -195,17 +322,91 @@ func NormalizePromptTarget(target string) string {
 	}
 }
 
+// NormalizePromptInitialFocus restricts the prompt's initial focus to a form
+// field or "confirm", which submits the defaults on enter.
+func NormalizePromptInitialFocus(focus string) string {
+	switch focus {
+	case "action", "duration", "target", "confirm":
+		return focus
+	default:
+		return DefaultPromptInitialFocus
+	}
+}
+
+// Prompt timeouts outside this range are normalized or rejected.
+const (
+	MinPromptTimeoutSeconds = 5
+	MaxPromptTimeoutSeconds = 600
+)
+
 // NormalizePromptTimeoutSeconds ensures a reasonable timeout window.
 func NormalizePromptTimeoutSeconds(seconds int) int {
-	if seconds < 5 {
+	if seconds < MinPromptTimeoutSeconds {
 		return DefaultPromptTimeoutSeconds
 	}
-	if seconds > 600 {
-		return 600
+	if seconds > MaxPromptTimeoutSeconds {
+		return MaxPromptTimeoutSeconds
 	}
 	return seconds
 }
 
+// ValidatePromptTimeoutSeconds reports timeouts NormalizePromptTimeoutSeconds
+// would change.
+func ValidatePromptTimeoutSeconds(seconds int) error {
+	if seconds < MinPromptTimeoutSeconds || seconds > MaxPromptTimeoutSeconds {
+		return fmt.Errorf("prompt timeout must be %d-%ds (got %ds)", MinPromptTimeoutSeconds, MaxPromptTimeoutSeconds, seconds)
+	}
+	return nil
+}
+
+// NormalizeMaintenanceAction is NormalizePromptAction with allow as the
+// fallback, since maintenance exists to let work through.
+func NormalizeMaintenanceAction(action string) string {
+	switch action {
+	case "allow", "deny", "reject":
+		return action
+	default:
+		return DefaultMaintenanceAction
+	}
+}
+
+// NormalizeDNDMinutes restricts the do-not-disturb length to the offered
+// presets; 0 means prompts stay muted until DND is toggled off.
+func NormalizeDNDMinutes(minutes int) int {
+	switch minutes {
+	case 0, 15, 30, 60:
+		return minutes
+	default:
+		return DefaultDNDMinutes
+	}
+}
+
+// ValidateYaraRuleDir checks that a YARA rule directory, when set, exists
+// and is a directory.
+func ValidateYaraRuleDir(path string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+// ValidateYara checks the YARA settings together: scanning needs a valid
+// rule directory.
+func ValidateYara(enabled bool, dir string) error {
+	if enabled && strings.TrimSpace(dir) == "" {
+		return errors.New("YARA rule directory required when YARA scanning is enabled")
+	}
+	return ValidateYaraRuleDir(dir)
+}
+
 // NormalizeThemeName clamps stored theme names to supported palettes.
 func NormalizeThemeName(name string) string {
 	value := strings.ToLower(strings.TrimSpace(name))
@@ -216,7 +417,9 @@ func NormalizeThemeName(name string) string {
 		return ThemeCanopy
 	case ThemeDawn:
 		return ThemeDawn
-	case ThemeAuto, ThemeDark, ThemeLight:
+	case ThemeAuto:
+		return ThemeAuto
+	case ThemeDark, ThemeLight:
 		return ThemeMidnight
 	default:
 		return ThemeMidnight
@@ -228,7 +431,8 @@ func ResolvePath(path string) (string, error) {
 	return resolvePath(path)
 }
 
-// Save writes configuration data to disk.
+// Save writes configuration data to disk through a temporary file, so a
+// failed write leaves the previous config whole.
 func Save(path string, cfg Config) error {
 	resolved, err := resolvePath(path)
 	if err != nil {
@@ -237,6 +441,7 @@ func Save(path string, cfg Config) error {
 	if err := Validate(cfg); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
+	cfg.SchemaVersion = CurrentSchemaVersion
 	if err := os.MkdirAll(filepath.Dir(resolved), 0o755); err != nil {
 		return fmt.Errorf("ensure config dir: %w", err)
 	}
@@ -244,7 +449,11 @@ func Save(path string, cfg Config) error {
 	if err != nil {
 		return fmt.Errorf("encode config: %w", err)
 	}
-	if err := os.WriteFile(resolved, data, 0o600); err != nil {
+	// Replace the file a symlinked config points at, not the link.
+	if target, err := filepath.EvalSymlinks(resolved); err == nil {
+		resolved = target
+	}
+	if err := persist.WriteFile(resolved, data); err != nil {
 		return fmt.Errorf("write config: %w", err)
 	}
 	return nil
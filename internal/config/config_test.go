@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -58,3 +59,143 @@ func TestValidateRejectsMissingTLSFiles(t *testing.T) {
 		t.Fatalf("expected error for missing TLS files")
 	}
 }
+
+func TestValidateInspectSections(t *testing.T) {
+	if err := Validate(Config{InspectSections: []string{"identity", "env"}}); err != nil {
+		t.Fatalf("expected known sections accepted, got %v", err)
+	}
+	if err := Validate(Config{InspectSections: []string{"tree", "sockest"}}); err == nil {
+		t.Fatalf("expected an unknown section rejected")
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	valid := Profile{
+		Name: "triage", View: "events",
+		Events: EventsProfile{Filter: "deny", Sort: "process", Columns: []string{"container"}, Time: "relative", TableShare: 0.6},
+		Rules:  RulesProfile{HideDisabled: true, Sort: "action"},
+	}
+	if err := Validate(Config{Profiles: []Profile{valid, {Name: "empty"}}}); err != nil {
+		t.Fatalf("expected valid profiles accepted, got %v", err)
+	}
+	cases := map[string]Profile{
+		"no name":       {},
+		"unknown view":  {Name: "x", View: "firewall"},
+		"unknown sort":  {Name: "x", Events: EventsProfile{Sort: "size"}},
+		"unknown col":   {Name: "x", Events: EventsProfile{Columns: []string{"pid"}}},
+		"share too big": {Name: "x", Rules: RulesProfile{TableShare: 0.95}},
+	}
+	for name, p := range cases {
+		if err := Validate(Config{Profiles: []Profile{p}}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := Validate(Config{Profiles: []Profile{valid, valid}}); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Fatalf("expected duplicate names rejected, got %v", err)
+	}
+}
+
+func TestValidateBlocklists(t *testing.T) {
+	valid := []Blocklist{{Path: "/etc/dns/ads.txt"}, {Path: "/etc/dns/malware.txt", Name: "malware", Strict: true}}
+	if err := Validate(Config{Blocklists: valid}); err != nil {
+		t.Fatalf("expected valid blocklists accepted, got %v", err)
+	}
+	if err := Validate(Config{Blocklists: []Blocklist{{Name: "no path"}}}); err == nil || !strings.Contains(err.Error(), "path is required") {
+		t.Fatalf("expected a missing path rejected, got %v", err)
+	}
+	dup := []Blocklist{{Path: "/a/ads.txt"}, {Path: "/b/ads.txt"}}
+	if err := Validate(Config{Blocklists: dup}); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Fatalf("expected lists shown under the same name rejected, got %v", err)
+	}
+}
+
+func TestNormalizeDNDMinutes(t *testing.T) {
+	for in, want := range map[int]int{0: 0, 15: 15, 60: 60, 45: DefaultDNDMinutes, -1: DefaultDNDMinutes} {
+		if got := NormalizeDNDMinutes(in); got != want {
+			t.Errorf("NormalizeDNDMinutes(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestLoadStartView(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("start_view: events\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.StartView != "events" {
+		t.Fatalf("StartView = %q, want events", cfg.StartView)
+	}
+
+	cfg, err = Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load default: %v", err)
+	}
+	if cfg.StartView != DefaultStartView {
+		t.Fatalf("default StartView = %q, want %q", cfg.StartView, DefaultStartView)
+	}
+}
+
+func TestValidateYara(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rules.yar")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		enabled bool
+		dir     string
+		ok      bool
+	}{
+		{false, "", true},
+		{true, "", false},
+		{true, dir, true},
+		{false, file, false},
+		{false, filepath.Join(dir, "missing"), false},
+	}
+	for _, tc := range cases {
+		if err := ValidateYara(tc.enabled, tc.dir); (err == nil) != tc.ok {
+			t.Errorf("ValidateYara(%v, %q) = %v, want ok=%v", tc.enabled, tc.dir, err, tc.ok)
+		}
+	}
+}
+
+func TestSaveReplacesTheLinkTargetWhole(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.yaml")
+	link := filepath.Join(dir, "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("theme: dawn\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Default()
+	cfg.Theme = ThemeMidnight
+	if err := Save(link, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected the link kept, got %v (%v)", info, err)
+	}
+	info, err := os.Stat(target)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the target rewritten 0600, got %v (%v)", info, err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil || !strings.Contains(string(data), "theme: "+ThemeMidnight) {
+		t.Fatalf("expected the target saved, got %q (%v)", data, err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(target))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected no temporary files left, got %v (%v)", entries, err)
+	}
+}
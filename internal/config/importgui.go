@@ -0,0 +1,283 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GUISettings is the subset of the OpenSnitch Qt GUI settings the TUI can use.
+// Empty fields were missing or not understood.
+type GUISettings struct {
+	DefaultAction   string
+	DefaultDuration string
+	DefaultTarget   string
+	TimeoutSeconds  int
+	Nodes           []Node
+	// Unsupported lists keys that were recognised but whose values have no
+	// TUI equivalent, with the reason.
+	Unsupported []string
+}
+
+// GUIImportReport summarises a merge of GUI settings into a TUI config.
+type GUIImportReport struct {
+	Imported []string
+	Skipped  []string
+}
+
+// GUI settings keys, flattened with "/" the way QSettings names them. Older
+// exports used bare names, so each field accepts a few aliases.
+var (
+	guiActionKeys   = []string{"global/default_action", "default_action"}
+	guiDurationKeys = []string{"global/default_duration", "default_duration"}
+	guiTargetKeys   = []string{"global/default_target", "default_target"}
+	guiTimeoutKeys  = []string{"global/default_timeout", "default_timeout"}
+	guiNodesKeys    = []string{"nodes", "global/nodes", "server/nodes"}
+)
+
+// The GUI stores combo-box indices for prompt defaults.
+var (
+	guiActions   = []string{"deny", "allow", "reject"}
+	guiDurations = []string{"once", "30s", "5m", "15m", "30m", "1h", "until restart", "always"}
+	guiTargets   = []string{"process.path", "process.command", "user.id", "dest.port", "dest.ip", "dest.host"}
+)
+
+// DefaultGUIConfigPath returns where the Qt GUI keeps its settings export.
+func DefaultGUIConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("user config dir: %w", err)
+	}
+	return filepath.Join(dir, "opensnitch", "ui-config.json"), nil
+}
+
+// ParseGUIConfig reads GUI settings JSON. Unknown keys and versions are
+// ignored; nested objects are treated like "/"-separated keys.
+func ParseGUIConfig(data []byte) (GUISettings, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return GUISettings{}, fmt.Errorf("decode gui config: %w", err)
+	}
+	flat := make(map[string]any)
+	flattenGUI("", raw, flat)
+
+	var out GUISettings
+	if v, ok := lookupGUI(flat, guiActionKeys); ok {
+		out.DefaultAction = guiChoice(v, guiActions, NormalizePromptAction)
+	}
+	if v, ok := lookupGUI(flat, guiDurationKeys); ok {
+		duration := guiChoice(v, guiDurations, func(s string) string { return s })
+		switch {
+		case duration == "":
+		case NormalizePromptDuration(duration) == duration:
+			out.DefaultDuration = duration
+		default:
+			out.Unsupported = append(out.Unsupported, fmt.Sprintf("default duration %q has no TUI equivalent", duration))
+		}
+	}
+	if v, ok := lookupGUI(flat, guiTargetKeys); ok {
+		out.DefaultTarget = guiChoice(v, guiTargets, NormalizePromptTarget)
+	}
+	if v, ok := lookupGUI(flat, guiTimeoutKeys); ok {
+		if seconds, ok := guiInt(v); ok && seconds > 0 {
+			out.TimeoutSeconds = NormalizePromptTimeoutSeconds(seconds)
+		}
+	}
+	if v, ok := lookupGUI(flat, guiNodesKeys); ok {
+		out.Nodes = guiNodes(v)
+	}
+	return out, nil
+}
+
+// MergeGUISettings copies GUI settings into cfg. Keys listed in present were
+// set explicitly in the TUI config and are kept unless force is true. Nodes
+// are merged by address.
+func MergeGUISettings(cfg Config, present map[string]bool, gui GUISettings, force bool) (Config, GUIImportReport) {
+	var report GUIImportReport
+	apply := func(key, value string, set func()) {
+		if value == "" {
+			return
+		}
+		if present[key] && !force {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: keeping existing value", key))
+			return
+		}
+		set()
+		report.Imported = append(report.Imported, fmt.Sprintf("%s: %s", key, value))
+	}
+	apply("default_prompt_action", gui.DefaultAction, func() { cfg.DefaultPromptAction = gui.DefaultAction })
+	apply("default_prompt_duration", gui.DefaultDuration, func() { cfg.DefaultPromptDuration = gui.DefaultDuration })
+	apply("default_prompt_target", gui.DefaultTarget, func() { cfg.DefaultPromptTarget = gui.DefaultTarget })
+	if gui.TimeoutSeconds > 0 {
+		timeout := strconv.Itoa(gui.TimeoutSeconds)
+		apply("prompt_timeout_seconds", timeout, func() { cfg.PromptTimeoutSeconds = gui.TimeoutSeconds })
+	}
+
+	known := make(map[string]bool, len(cfg.Nodes))
+	for _, node := range cfg.Nodes {
+		known[node.Address] = true
+	}
+	for _, node := range gui.Nodes {
+		if known[node.Address] {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("node %s: already configured", node.Address))
+			continue
+		}
+		if err := ValidateNode(node); err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("node %s: %v", node.Address, err))
+			continue
+		}
+		known[node.Address] = true
+		cfg.Nodes = append(cfg.Nodes, node)
+		report.Imported = append(report.Imported, fmt.Sprintf("node %s", node.Address))
+	}
+	for _, note := range gui.Unsupported {
+		report.Skipped = append(report.Skipped, note)
+	}
+	return cfg, report
+}
+
+// ImportGUIConfig merges the GUI settings at guiPath into the TUI config at
+// configPath and saves the result.
+func ImportGUIConfig(guiPath, configPath string, force bool) (GUIImportReport, error) {
+	data, err := os.ReadFile(guiPath)
+	if err != nil {
+		return GUIImportReport{}, fmt.Errorf("read gui config: %w", err)
+	}
+	gui, err := ParseGUIConfig(data)
+	if err != nil {
+		return GUIImportReport{}, err
+	}
+	cfg, err := Load(configPath)
+	if err != nil && !errors.Is(err, ErrMigrationNotSaved) {
+		return GUIImportReport{}, err
+	}
+	present, err := presentKeys(configPath)
+	if err != nil {
+		return GUIImportReport{}, err
+	}
+	merged, report := MergeGUISettings(cfg, present, gui, force)
+	if len(report.Imported) == 0 {
+		return report, nil
+	}
+	if err := Save(configPath, merged); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// presentKeys reports which top-level keys the config file sets explicitly,
+// so imported values do not override them.
+func presentKeys(path string) (map[string]bool, error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]bool{}, nil
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	present := make(map[string]bool, len(raw))
+	for key := range raw {
+		present[key] = true
+	}
+	return present, nil
+}
+
+func flattenGUI(prefix string, in map[string]any, out map[string]any) {
+	for key, value := range in {
+		name := key
+		if prefix != "" {
+			name = prefix + "/" + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flattenGUI(name, nested, out)
+			continue
+		}
+		out[strings.ToLower(name)] = value
+	}
+}
+
+func lookupGUI(flat map[string]any, keys []string) (any, bool) {
+	for _, key := range keys {
+		if v, ok := flat[key]; ok && v != nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// guiChoice resolves a combo value stored either as an index into options or
+// as a label. normalize maps unknown labels to a default; results that do
+// not survive normalization are dropped.
+func guiChoice(v any, options []string, normalize func(string) string) string {
+	if idx, ok := guiInt(v); ok {
+		if idx < 0 || idx >= len(options) {
+			return ""
+		}
+		return normalizeChoice(options[idx], normalize)
+	}
+	if s, ok := v.(string); ok {
+		return normalizeChoice(strings.ToLower(strings.TrimSpace(s)), normalize)
+	}
+	return ""
+}
+
+func normalizeChoice(value string, normalize func(string) string) string {
+	if normalize(value) != value {
+		return ""
+	}
+	return value
+}
+
+func guiInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func guiNodes(v any) []Node {
+	items, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	var nodes []Node
+	for _, item := range items {
+		switch entry := item.(type) {
+		case string:
+			nodes = append(nodes, Node{Address: strings.TrimSpace(entry)})
+		case map[string]any:
+			node := Node{}
+			if s, ok := entry["address"].(string); ok {
+				node.Address = strings.TrimSpace(s)
+			} else if s, ok := entry["addr"].(string); ok {
+				node.Address = strings.TrimSpace(s)
+			}
+			if s, ok := entry["name"].(string); ok {
+				node.Name = s
+			}
+			if node.Address != "" {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadGUIFixture(t *testing.T, name string) GUISettings {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "gui", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	gui, err := ParseGUIConfig(data)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return gui
+}
+
+func TestParseGUIConfigFlatIndices(t *testing.T) {
+	gui := loadGUIFixture(t, "ui-config-flat.json")
+	if gui.DefaultAction != "allow" || gui.DefaultDuration != "always" || gui.DefaultTarget != "process.command" {
+		t.Fatalf("unexpected prompt defaults: %+v", gui)
+	}
+	if gui.TimeoutSeconds != 15 {
+		t.Fatalf("expected timeout 15, got %d", gui.TimeoutSeconds)
+	}
+	if len(gui.Nodes) != 2 || gui.Nodes[1].Name != "nas" {
+		t.Fatalf("unexpected nodes: %+v", gui.Nodes)
+	}
+}
+
+func TestParseGUIConfigNestedToleratesUnknownKeys(t *testing.T) {
+	gui := loadGUIFixture(t, "ui-config-nested.json")
+	if gui.DefaultAction != "reject" || gui.DefaultTarget != "dest.host" || gui.TimeoutSeconds != 45 {
+		t.Fatalf("unexpected settings: %+v", gui)
+	}
+	if gui.DefaultDuration != "" || len(gui.Unsupported) != 1 || !strings.Contains(gui.Unsupported[0], "5m") {
+		t.Fatalf("expected 5m duration to be reported as unsupported, got %+v", gui)
+	}
+}
+
+func TestParseGUIConfigRejectsInvalidJSON(t *testing.T) {
+	if _, err := ParseGUIConfig([]byte("[global]\ndefault_action=1")); err == nil {
+		t.Fatalf("expected error for non-JSON input")
+	}
+}
+
+func TestMergeGUISettingsExistingValuesWin(t *testing.T) {
+	gui := loadGUIFixture(t, "ui-config-flat.json")
+	cfg := Default()
+	cfg.DefaultPromptAction = "deny"
+	cfg.Nodes = []Node{{Address: "10.0.0.2:50051"}}
+	present := map[string]bool{"default_prompt_action": true, "nodes": true}
+
+	merged, report := MergeGUISettings(cfg, present, gui, false)
+	if merged.DefaultPromptAction != "deny" {
+		t.Fatalf("expected existing action to win, got %q", merged.DefaultPromptAction)
+	}
+	if merged.DefaultPromptDuration != "always" || merged.PromptTimeoutSeconds != 15 {
+		t.Fatalf("expected unset fields to be imported, got %+v", merged)
+	}
+	if len(merged.Nodes) != 2 || merged.Nodes[1].Address != "10.0.0.3:50051" {
+		t.Fatalf("expected only the new node to be appended, got %+v", merged.Nodes)
+	}
+	if len(report.Skipped) != 2 {
+		t.Fatalf("expected action and duplicate node to be skipped, got %v", report.Skipped)
+	}
+
+	forced, _ := MergeGUISettings(cfg, present, gui, true)
+	if forced.DefaultPromptAction != "allow" {
+		t.Fatalf("expected --force to override, got %q", forced.DefaultPromptAction)
+	}
+}
+
+func TestImportGUIConfigWritesConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("default_prompt_target: dest.ip\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	report, err := ImportGUIConfig(filepath.Join("testdata", "gui", "ui-config-nested.json"), cfgPath, false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(report.Imported) == 0 {
+		t.Fatalf("expected imported entries, got %+v", report)
+	}
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if cfg.DefaultPromptTarget != "dest.ip" || cfg.DefaultPromptAction != "reject" {
+		t.Fatalf("unexpected merged config: %+v", cfg)
+	}
+	if len(cfg.Nodes) != 1 || cfg.Nodes[0].Address != "unix:///tmp/osui.sock" {
+		t.Fatalf("expected only the valid node, got %+v", cfg.Nodes)
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+)
+
+// CurrentSchemaVersion is the schema_version this build reads and writes.
+// Files without a schema_version are version 0.
+const CurrentSchemaVersion = 1
+
+// ErrNewerSchema is returned for files written by a newer build, whose keys
+// this one could silently drop.
+var ErrNewerSchema = errors.New("config written by a newer version")
+
+// ErrMigrationNotSaved wraps a failure to back up or rewrite a migrated
+// file. The config Load returns with it is still usable.
+var ErrMigrationNotSaved = errors.New("migrated config not saved")
+
+// migration upgrades a document from version from to from+1. It edits the
+// YAML tree, so it can rename or reshape fields the Config struct no longer
+// has while the user's comments and key order stay put.
+type migration struct {
+	from  int
+	apply func(doc *yaml.Node) error
+}
+
+// migrations must hold one entry per version below CurrentSchemaVersion, in
+// order.
+var migrations = []migration{
+	{from: 0, apply: migrateLegacyThemes},
+}
+
+// migrateLegacyThemes rewrites the "dark" and "light" theme names of early
+// builds to midnight, the palette both have been loaded as since.
+func migrateLegacyThemes(doc *yaml.Node) error {
+	if theme := mappingValue(doc, "theme"); theme != nil && theme.Kind == yaml.ScalarNode && (theme.Value == ThemeDark || theme.Value == ThemeLight) {
+		theme.Value = ThemeMidnight
+	}
+	return nil
+}
+
+// mappingValue returns the value under key in the mapping doc, or nil.
+func mappingValue(doc *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == key {
+			return doc.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setScalar sets key in the mapping doc to value, appending the key when
+// it is missing.
+func setScalar(doc *yaml.Node, key, value, tag string) {
+	if node := mappingValue(doc, key); node != nil {
+		node.Kind, node.Tag, node.Value, node.Content = yaml.ScalarNode, tag, value, nil
+		return
+	}
+	doc.Content = append(doc.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value},
+	)
+}
+
+// migrate upgrades data to CurrentSchemaVersion. It returns data unchanged
+// and migrated false when the file is already current.
+func migrate(data []byte) (out []byte, migrated bool, err error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, false, err
+	}
+	if root.Kind == 0 || len(root.Content) == 0 || root.Content[0].Tag == "!!null" {
+		// An empty file, or one holding only comments.
+		root.Kind = yaml.DocumentNode
+		root.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return nil, false, errors.New("config must be a mapping of settings")
+	}
+	version := 0
+	if raw := mappingValue(doc, "schema_version"); raw != nil {
+		if err := raw.Decode(&version); err != nil || version < 0 {
+			return nil, false, fmt.Errorf("schema_version must be a non-negative integer (got %v)", raw.Value)
+		}
+	}
+	if version > CurrentSchemaVersion {
+		return nil, false, fmt.Errorf("%w (schema_version %d, this build reads up to %d)", ErrNewerSchema, version, CurrentSchemaVersion)
+	}
+	if version == CurrentSchemaVersion {
+		return data, false, nil
+	}
+	for _, m := range migrations[version:] {
+		if err := m.apply(doc); err != nil {
+			return nil, false, fmt.Errorf("migrate schema %d to %d: %w", m.from, m.from+1, err)
+		}
+	}
+	setScalar(doc, "schema_version", strconv.Itoa(CurrentSchemaVersion), "!!int")
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, false, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decode fills cfg from data. Strict decoding rejects unknown keys.
+func decode(data []byte, cfg *Config, strict bool) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(strict)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// rewriteMigrated keeps the original file as path.bak and replaces it with
+// the migrated document. Only the keys the user set are written, so
+// defaults stay unset.
+func rewriteMigrated(path string, original, migrated []byte) error {
+	if err := persist.WriteFile(path+".bak", original); err != nil {
+		return fmt.Errorf("%w: back up: %v", ErrMigrationNotSaved, err)
+	}
+	// Replace the file a symlinked config points at, not the link.
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	if err := persist.WriteFile(path, migrated); err != nil {
+		return fmt.Errorf("%w: %v", ErrMigrationNotSaved, err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	if len(migrations) != CurrentSchemaVersion {
+		t.Fatalf("expected %d migrations, got %d", CurrentSchemaVersion, len(migrations))
+	}
+	for i, m := range migrations {
+		if m.from != i {
+			t.Fatalf("migrations[%d] upgrades from %d", i, m.from)
+		}
+	}
+}
+
+func TestMigrateV0LegacyThemes(t *testing.T) {
+	cases := map[string]string{"dark": ThemeMidnight, "light": ThemeMidnight, "dawn": ThemeDawn}
+	for from, want := range cases {
+		out, migrated, err := migrate([]byte("theme: " + from + "\n"))
+		if err != nil || !migrated {
+			t.Fatalf("theme %q: migrate returned %v, %v", from, migrated, err)
+		}
+		if got := string(out); got != "theme: "+want+"\nschema_version: 1\n" {
+			t.Fatalf("theme %q migrated to %q, want %q", from, got, want)
+		}
+	}
+}
+
+func TestMigrateKeepsCommentsAndOrder(t *testing.T) {
+	original := `# opensnitch-tui settings
+theme: dark # was light once
+nodes:
+  - address: unix:///run/opensnitch.sock
+    name: laptop # the local daemon
+default_prompt_action: deny
+`
+	out, migrated, err := migrate([]byte(original))
+	if err != nil || !migrated {
+		t.Fatalf("migrate returned %v, %v", migrated, err)
+	}
+	want := `# opensnitch-tui settings
+theme: midnight # was light once
+nodes:
+  - address: unix:///run/opensnitch.sock
+    name: laptop # the local daemon
+default_prompt_action: deny
+schema_version: 1
+`
+	if string(out) != want {
+		t.Fatalf("unexpected migrated file:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestMigrateEmptyFile(t *testing.T) {
+	for _, data := range []string{"", "# nothing set yet\n"} {
+		out, migrated, err := migrate([]byte(data))
+		if err != nil || !migrated || !strings.Contains(string(out), "schema_version: 1") {
+			t.Fatalf("%q: unexpected migration %q, %v, %v", data, out, migrated, err)
+		}
+	}
+	if _, _, err := migrate([]byte("- theme\n")); err == nil {
+		t.Fatal("expected a config that is not a mapping rejected")
+	}
+}
+
+func TestLoadMigratesAndKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := "theme: dark\ndefault_prompt_action: allow\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Theme != ThemeMidnight || cfg.DefaultPromptAction != "allow" || cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Fatalf("unexpected migrated config: %+v", cfg)
+	}
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil || string(backup) != original {
+		t.Fatalf("expected original kept as .bak, got %q (%v)", backup, err)
+	}
+	rewritten, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rewritten), "schema_version: 1") {
+		t.Fatalf("expected rewritten file to carry the schema version:\n%s", rewritten)
+	}
+
+	// A current file is left alone.
+	if err := os.Remove(path + ".bak"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err := os.Stat(path + ".bak"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no backup for a current file, got %v", err)
+	}
+}
+
+func TestLoadMigratesThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.yaml")
+	link := filepath.Join(dir, "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("theme: dark\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(link); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected the link kept, got %v (%v)", info, err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil || !strings.Contains(string(data), "schema_version: 1") {
+		t.Fatalf("expected the link target migrated, got %q (%v)", data, err)
+	}
+}
+
+func TestLoadRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("schema_version: 99\ntheme: dawn\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(path)
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("expected ErrNewerSchema, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "config written by a newer version") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
+
+func TestLoadStrictRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("schema_version: 1\ndefualt_prompt_action: allow\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatalf("expected lenient load to ignore the typo, got %v", err)
+	}
+	_, err := LoadWithOptions(path, LoadOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "defualt_prompt_action") {
+		t.Fatalf("expected strict load to name the unknown key, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Profile is a named workspace layout: the view to show and how the Events
+// and Rules tables are filtered, sorted and sized. Empty fields keep the
+// built-in behaviour.
+type Profile struct {
+	Name   string        `yaml:"name"`
+	View   string        `yaml:"view,omitempty"`
+	Events EventsProfile `yaml:"events,omitempty"`
+	Rules  RulesProfile  `yaml:"rules,omitempty"`
+}
+
+// EventsProfile is the Events view part of a profile.
+type EventsProfile struct {
+	// Filter keeps only events with this action; empty shows all.
+	Filter string `yaml:"filter,omitempty"`
+	Sort   string `yaml:"sort,omitempty"`
+	// Columns lists the optional columns shown; see EventColumnNames.
+	Columns []string `yaml:"columns,omitempty"`
+	Time    string   `yaml:"time,omitempty"`
+	// TableShare is the fraction of the view height given to the table;
+	// zero sizes it automatically.
+	TableShare float64 `yaml:"table_share,omitempty"`
+}
+
+// RulesProfile is the Rules view part of a profile.
+type RulesProfile struct {
+	HideDisabled bool    `yaml:"hide_disabled,omitempty"`
+	Sort         string  `yaml:"sort,omitempty"`
+	TableShare   float64 `yaml:"table_share,omitempty"`
+}
+
+// Accepted values of the profile fields; the first sort is the default.
+var (
+	EventFilterNames = []string{"allow", "deny", "reject"}
+	EventSortNames   = []string{"time", "process", "destination"}
+	EventColumnNames = []string{"container"}
+	EventTimeNames   = []string{"utc", "local", "relative"}
+	RuleSortNames    = []string{"daemon", "name", "action"}
+)
+
+// MinTableShare and MaxTableShare bound a profile's table share.
+const (
+	MinTableShare = 0.2
+	MaxTableShare = 0.8
+)
+
+var viewNames = []string{"dashboard", "alerts", "events", "rules", "nodes", "settings"}
+
+func validateProfiles(profiles []Profile) []string {
+	var errs []string
+	seen := make(map[string]bool, len(profiles))
+	for i, p := range profiles {
+		prefix := fmt.Sprintf("profiles[%d]", i)
+		name := strings.TrimSpace(p.Name)
+		switch {
+		case name == "":
+			errs = append(errs, prefix+": name is required")
+		case seen[name]:
+			errs = append(errs, fmt.Sprintf("%s: duplicate name %q", prefix, name))
+		}
+		seen[name] = true
+		check := func(field, value string, allowed []string) {
+			if value != "" && !slices.Contains(allowed, value) {
+				errs = append(errs, fmt.Sprintf("%s.%s: unknown value %q (want one of %s)", prefix, field, value, strings.Join(allowed, ", ")))
+			}
+		}
+		check("view", p.View, viewNames)
+		check("events.filter", p.Events.Filter, EventFilterNames)
+		check("events.sort", p.Events.Sort, EventSortNames)
+		check("events.time", p.Events.Time, EventTimeNames)
+		for _, column := range p.Events.Columns {
+			check("events.columns", column, EventColumnNames)
+		}
+		check("rules.sort", p.Rules.Sort, RuleSortNames)
+		checkShare := func(field string, share float64) {
+			if share != 0 && (share < MinTableShare || share > MaxTableShare) {
+				errs = append(errs, fmt.Sprintf("%s.%s: %g is outside %g-%g", prefix, field, share, MinTableShare, MaxTableShare))
+			}
+		}
+		checkShare("events.table_share", p.Events.TableShare)
+		checkShare("rules.table_share", p.Rules.TableShare)
+	}
+	return errs
+}
@@ -0,0 +1,210 @@
+// Package container tells which container, if any, a local process runs in.
+// Detection reads /proc/<pid>/cgroup; names come from the docker or podman
+// CLI when installed, resolved in the background and cached.
+package container
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runtime is the engine a container runs under.
+type Runtime string
+
+const (
+	Docker     Runtime = "docker"
+	Podman     Runtime = "podman"
+	Containerd Runtime = "containerd"
+)
+
+// Info identifies the container of a process.
+type Info struct {
+	Runtime Runtime
+	ID      string
+	// Name is the runtime's name for the container; empty until resolved
+	// or when the runtime's CLI is not available.
+	Name string
+}
+
+// ShortID is the 12-character form docker and podman print.
+func (i Info) ShortID() string {
+	if len(i.ID) > 12 {
+		return i.ID[:12]
+	}
+	return i.ID
+}
+
+// Label is "name (runtime)", with the short ID until the name is known.
+func (i Info) Label() string {
+	name := i.Name
+	if name == "" {
+		name = i.ShortID()
+	}
+	return name + " (" + string(i.Runtime) + ")"
+}
+
+// cgroupPatterns match the cgroup paths the runtimes create, under both the
+// cgroupfs and the systemd drivers. podman's conmon runs in a libpod-conmon
+// scope, which is deliberately not matched: it is not in the container.
+var cgroupPatterns = []struct {
+	re      *regexp.Regexp
+	runtime Runtime
+}{
+	{regexp.MustCompile(`/docker-([0-9a-f]{64})\.scope`), Docker},
+	{regexp.MustCompile(`/docker/([0-9a-f]{64})(?:/|$)`), Docker},
+	{regexp.MustCompile(`/libpod-([0-9a-f]{64})(?:\.scope)?(?:/|$)`), Podman},
+	{regexp.MustCompile(`/cri-containerd-([0-9a-f]{64})\.scope`), Containerd},
+	{regexp.MustCompile(`^/kubepods\S*/([0-9a-f]{64})$`), Containerd},
+}
+
+// ParseCgroup finds the container in the contents of a /proc/<pid>/cgroup
+// file, in either the v1 or the v2 format.
+func ParseCgroup(data string) (Info, bool) {
+	for _, line := range strings.Split(data, "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, p := range cgroupPatterns {
+			if match := p.re.FindStringSubmatch(parts[2]); match != nil {
+				return Info{Runtime: p.runtime, ID: match[1]}, true
+			}
+		}
+	}
+	return Info{}, false
+}
+
+// maxCachedProcesses bounds the per-process cache; it is simply emptied
+// when full.
+const maxCachedProcesses = 4096
+
+// nameTimeout bounds one docker/podman inspect call.
+const nameTimeout = 3 * time.Second
+
+type procKey struct {
+	pid  uint32
+	path string
+}
+
+type procEntry struct {
+	info Info
+	ok   bool
+}
+
+// Resolver detects and names the containers of processes on this machine.
+// Lookups never wait on a runtime CLI. Only ask it about local nodes' processes:
+// a remote node's PIDs mean nothing here.
+type Resolver struct {
+	procRoot string
+	// lookPath and run stand in for exec in tests.
+	lookPath func(string) (string, error)
+	run      func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+	mu    sync.Mutex
+	procs map[procKey]procEntry
+	// names holds resolved names by container ID; an ID is present, maybe
+	// with an empty name, once a lookup was started.
+	names   map[string]string
+	updates chan struct{}
+}
+
+// Local resolves containers for processes on this machine.
+var Local = NewResolver("/proc")
+
+// NewResolver reads cgroup files under procRoot.
+func NewResolver(procRoot string) *Resolver {
+	return &Resolver{
+		procRoot: procRoot,
+		lookPath: exec.LookPath,
+		run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			return exec.CommandContext(ctx, name, args...).Output()
+		},
+		procs:   make(map[procKey]procEntry),
+		names:   make(map[string]string),
+		updates: make(chan struct{}, 1),
+	}
+}
+
+// Updates receives a signal whenever a container name has been resolved.
+func (r *Resolver) Updates() <-chan struct{} {
+	return r.updates
+}
+
+// Lookup returns the container running pid, if any. The cgroup file is read
+// once per process; the name, when not yet known, is looked up in the
+// background and shows up in later calls.
+func (r *Resolver) Lookup(pid uint32, path string) (Info, bool) {
+	if r == nil || pid == 0 {
+		return Info{}, false
+	}
+	key := procKey{pid: pid, path: path}
+	r.mu.Lock()
+	entry, cached := r.procs[key]
+	r.mu.Unlock()
+	if !cached {
+		data, err := os.ReadFile(filepath.Join(r.procRoot, strconv.FormatUint(uint64(pid), 10), "cgroup"))
+		if err == nil {
+			entry.info, entry.ok = ParseCgroup(string(data))
+		}
+		r.mu.Lock()
+		if len(r.procs) >= maxCachedProcesses {
+			r.procs = make(map[procKey]procEntry)
+		}
+		r.procs[key] = entry
+		r.mu.Unlock()
+	}
+	if !entry.ok {
+		return Info{}, false
+	}
+
+	info := entry.info
+	r.mu.Lock()
+	name, started := r.names[info.ID]
+	if !started {
+		r.names[info.ID] = ""
+	}
+	r.mu.Unlock()
+	info.Name = name
+	if !started {
+		go r.resolveName(info)
+	}
+	return info, true
+}
+
+// resolveName asks the runtime's CLI for the container's name. containerd
+// has no CLI usable without root, so its containers keep their ID.
+func (r *Resolver) resolveName(info Info) {
+	cli := string(info.Runtime)
+	if info.Runtime == Containerd {
+		return
+	}
+	bin, err := r.lookPath(cli)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), nameTimeout)
+	defer cancel()
+	out, err := r.run(ctx, bin, "inspect", "--format", "{{.Name}}", info.ID)
+	if err != nil {
+		return
+	}
+	name := strings.TrimPrefix(strings.TrimSpace(string(out)), "/")
+	if name == "" {
+		return
+	}
+	r.mu.Lock()
+	r.names[info.ID] = name
+	r.mu.Unlock()
+	select {
+	case r.updates <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,79 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	dockerID = "3f4e9a1c2b7d8e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+	podmanID = "9b1d0c3a5e7f2d4c6b8a0e1f3d5c7b9a1e3f5d7c9b0a2e4f6d8c0b1a3e5f7d9c"
+)
+
+func TestLookupCgroupLayouts(t *testing.T) {
+	tests := []struct {
+		pid    uint32
+		layout string
+		want   Info
+		ok     bool
+	}{
+		{101, "docker, cgroup v1 cgroupfs driver", Info{Runtime: Docker, ID: dockerID}, true},
+		{102, "docker, cgroup v2 systemd driver", Info{Runtime: Docker, ID: dockerID}, true},
+		{103, "rootless podman, cgroup v2", Info{Runtime: Podman, ID: podmanID}, true},
+		{104, "podman conmon, outside the container", Info{}, false},
+		{105, "kubernetes, cgroup v1 cgroupfs driver", Info{Runtime: Containerd, ID: "5c2a7e9d1b3f6a8c0e2d4b6f8a1c3e5d7b9f0a2c4e6d8b1f3a5c7e9d0b2f4a6c"}, true},
+		{106, "kubernetes containerd, cgroup v2 systemd driver", Info{Runtime: Containerd, ID: "e8f1a3c5d7b9e0f2a4c6d8b1e3f5a7c9d0b2e4f6a8c1d3e5f7b9a0c2e4d6f8b1"}, true},
+		{107, "host session, cgroup v2", Info{}, false},
+		{108, "host session, hybrid v1/v2", Info{}, false},
+		{109, "rootful podman, cgroup v2", Info{Runtime: Podman, ID: podmanID}, true},
+		{999, "process gone", Info{}, false},
+	}
+	r := NewResolver("testdata/proc")
+	r.lookPath = func(string) (string, error) { return "", errors.New("not installed") }
+	for _, tt := range tests {
+		got, ok := r.Lookup(tt.pid, "/usr/bin/python3")
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: Lookup(%d) = %+v, %v; want %+v, %v", tt.layout, tt.pid, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLookupResolvesNameInBackground(t *testing.T) {
+	r := NewResolver("testdata/proc")
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var calls [][]string
+	r.lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	r.run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		mu.Lock()
+		calls = append(calls, append([]string{name}, args...))
+		mu.Unlock()
+		<-release
+		return []byte("/friendly_name\n"), nil
+	}
+
+	info, ok := r.Lookup(102, "/usr/bin/python3")
+	if !ok || info.Name != "" || info.Label() != "3f4e9a1c2b7d (docker)" {
+		t.Fatalf("expected the short ID while the name is pending, got %+v (%q)", info, info.Label())
+	}
+	r.Lookup(101, "/usr/bin/curl") // same container, no second inspect
+	close(release)
+
+	select {
+	case <-r.Updates():
+	case <-time.After(time.Second):
+		t.Fatal("expected an update once the name was resolved")
+	}
+	info, _ = r.Lookup(102, "/usr/bin/python3")
+	if info.Label() != "friendly_name (docker)" {
+		t.Fatalf("expected the resolved name, got %q", info.Label())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 1 || calls[0][0] != "/usr/bin/docker" || calls[0][len(calls[0])-1] != dockerID {
+		t.Fatalf("expected one docker inspect of the container, got %v", calls)
+	}
+}
@@ -0,0 +1,101 @@
+package control
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// Exit codes used by the one-shot prompt responder.
+const (
+	ExitOK         = 0
+	ExitError      = 1
+	ExitNoPending  = 3
+	ExitNotRunning = 4
+)
+
+// ErrNoPending reports that the running instance has no prompts waiting.
+var ErrNoPending = errors.New("no pending prompts")
+
+// PromptSource is the subset of Client used by Ask.
+type PromptSource interface {
+	Pending() ([]PromptInfo, error)
+	Resolve(controller.PromptDecision) error
+}
+
+// Ask shows the oldest pending prompt as a line-mode question on out, reads
+// the answers from in and sends the decision. Output is plain text so it is
+// safe to use without a TTY.
+func Ask(src PromptSource, in io.Reader, out io.Writer) error {
+	prompts, err := src.Pending()
+	if err != nil {
+		return err
+	}
+	if len(prompts) == 0 {
+		return ErrNoPending
+	}
+	p := prompts[0]
+	reader := bufio.NewReader(in)
+
+	question := fmt.Sprintf("allow %s → %s? [a]llow/[d]eny/[r]eject: ", util.Fallback(p.ProcessPath, "unknown process"), p.Destination())
+	action, err := askChoice(reader, out, question, map[string]controller.PromptAction{
+		"a": controller.PromptActionAllow,
+		"d": controller.PromptActionDeny,
+		"r": controller.PromptActionReject,
+	})
+	if err != nil {
+		return err
+	}
+	duration, err := askChoice(reader, out, "duration [o]nce/[u]ntil restart/[A]lways: ", map[string]controller.PromptDuration{
+		"o": controller.PromptDurationOnce,
+		"u": controller.PromptDurationUntilRestart,
+		"A": controller.PromptDurationAlways,
+	})
+	if err != nil {
+		return err
+	}
+	if err := src.Resolve(controller.PromptDecision{PromptID: p.ID, Action: action, Duration: duration}); err != nil {
+		return fmt.Errorf("resolve prompt %s: %w", p.ID, err)
+	}
+	fmt.Fprintf(out, "%s %s (%s)\n", action, p.Destination(), duration)
+	return nil
+}
+
+// ExitCode maps an Ask error to the process exit code.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrNoPending):
+		return ExitNoPending
+	case errors.Is(err, ErrNotRunning):
+		return ExitNotRunning
+	default:
+		return ExitError
+	}
+}
+
+func askChoice[T any](reader *bufio.Reader, out io.Writer, question string, choices map[string]T) (T, error) {
+	var zero T
+	for {
+		fmt.Fprint(out, question)
+		line, err := reader.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if v, ok := choices[answer]; ok {
+			return v, nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				fmt.Fprintln(out)
+				return zero, errors.New("no answer given")
+			}
+			return zero, err
+		}
+		fmt.Fprintf(out, "unrecognised answer %q\n", answer)
+	}
+}
@@ -0,0 +1,138 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// ErrNotRunning reports that no instance is listening on the control socket.
+var ErrNotRunning = errors.New("no running opensnitch-tui instance")
+
+const maxResponseBytes = 16 << 20
+
+const dialTimeout = 2 * time.Second
+
+// callTimeout bounds a whole request and its response, so a primary
+// instance that stops answering cannot hang the caller.
+var callTimeout = 5 * time.Second
+
+// Client talks to a running instance over its control socket. A call that
+// fails on the connection drops it, and the next call dials again, so a
+// restarted instance is picked up. Calls are serialised.
+type Client struct {
+	path    string
+	mu      sync.Mutex
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// Dial connects to the control socket at path.
+func Dial(path string) (*Client, error) {
+	c := &Client{path: path}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("unix", c.path, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("%w (%s): %v", ErrNotRunning, c.path, err)
+	}
+	scanner := bufio.NewScanner(conn)
+	// Rule listings can exceed the default 64KiB line limit.
+	scanner.Buffer(make([]byte, 0, 64<<10), maxResponseBytes)
+	c.conn, c.scanner = conn, scanner
+	return nil
+}
+
+// drop closes a connection that failed mid-call; its stream may hold half
+// a response.
+func (c *Client) drop() {
+	_ = c.conn.Close()
+	c.conn, c.scanner = nil, nil
+}
+
+// Rules returns the connected nodes and their rules.
+func (c *Client) Rules() ([]state.Node, map[string][]state.Rule, error) {
+	resp, err := c.call(Request{Op: OpRules})
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Nodes, resp.Rules, nil
+}
+
+// Close releases the connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.scanner = nil, nil
+	return err
+}
+
+// Pending lists pending prompts, oldest first.
+func (c *Client) Pending() ([]PromptInfo, error) {
+	resp, err := c.call(Request{Op: OpPending})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Prompts, nil
+}
+
+// Resolve answers a prompt.
+func (c *Client) Resolve(decision controller.PromptDecision) error {
+	_, err := c.call(Request{Op: OpResolve, Decision: &decision})
+	return err
+}
+
+func (c *Client) call(req Request) (Response, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return Response{}, err
+		}
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(callTimeout)); err != nil {
+		c.drop()
+		return Response{}, fmt.Errorf("set deadline: %w", err)
+	}
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		c.drop()
+		return Response{}, fmt.Errorf("send request: %w", err)
+	}
+	if !c.scanner.Scan() {
+		err := c.scanner.Err()
+		c.drop()
+		if err != nil {
+			return Response{}, fmt.Errorf("read response: %w", err)
+		}
+		return Response{}, errors.New("control socket closed")
+	}
+	var resp Response
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		c.drop()
+		return Response{}, fmt.Errorf("decode response: %w", err)
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,89 @@
+// Package control exposes a small JSON-lines protocol over a unix socket so
+// other processes can list and answer pending prompts of a running instance.
+package control
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// Supported request operations.
+const (
+	OpPending = "pending"
+	OpResolve = "resolve"
+	OpRules   = "rules"
+)
+
+// Request is a single client call; one JSON object per line.
+type Request struct {
+	Op       string                     `json:"op"`
+	Decision *controller.PromptDecision `json:"decision,omitempty"`
+}
+
+// Response answers a Request.
+type Response struct {
+	Error   string                  `json:"error,omitempty"`
+	Prompts []PromptInfo            `json:"prompts,omitempty"`
+	Nodes   []state.Node            `json:"nodes,omitempty"`
+	Rules   map[string][]state.Rule `json:"rules,omitempty"`
+}
+
+// PromptInfo is the wire representation of a pending prompt.
+type PromptInfo struct {
+	ID          string    `json:"id"`
+	NodeName    string    `json:"node_name"`
+	ProcessPath string    `json:"process_path"`
+	DstHost     string    `json:"dst_host"`
+	DstIP       string    `json:"dst_ip"`
+	DstPort     uint32    `json:"dst_port"`
+	Protocol    string    `json:"protocol"`
+	SrcIP       string    `json:"src_ip,omitempty"`
+	Inbound     bool      `json:"inbound,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Destination returns the host (or IP) and port of the prompted connection.
+func (p PromptInfo) Destination() string {
+	host := p.DstHost
+	if host == "" {
+		host = p.DstIP
+	}
+	if host == "" {
+		host = "unknown"
+	}
+	return util.FormatEndpoint(host, p.DstPort)
+}
+
+func promptInfo(p state.Prompt) PromptInfo {
+	return PromptInfo{
+		ID:          p.ID,
+		NodeName:    p.NodeName,
+		ProcessPath: p.Connection.ProcessPath,
+		DstHost:     p.Connection.DstHost,
+		DstIP:       p.Connection.DstIP,
+		DstPort:     p.Connection.DstPort,
+		Protocol:    p.Connection.Protocol,
+		SrcIP:       p.Connection.SrcIP,
+		Inbound:     p.Connection.Inbound(),
+		RequestedAt: p.RequestedAt,
+		ExpiresAt:   p.ExpiresAt,
+	}
+}
+
+// DefaultSocketPath returns the per-user control socket location. Without
+// XDG_RUNTIME_DIR it sits in a directory of the user's own under the
+// temporary directory, which Serve creates private; a bare socket there
+// could be claimed by anyone first.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "opensnitch-tui.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("opensnitch-tui-%d", os.Getuid()), "control.sock")
+}
@@ -0,0 +1,348 @@
+package control
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// fakeControlServer answers control requests with canned prompts and records decisions.
+type fakeControlServer struct {
+	mu        sync.Mutex
+	prompts   []PromptInfo
+	decisions []controller.PromptDecision
+}
+
+func (f *fakeControlServer) serve(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ostui")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "ctl.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				enc := json.NewEncoder(conn)
+				for scanner.Scan() {
+					var req Request
+					_ = json.Unmarshal(scanner.Bytes(), &req)
+					f.mu.Lock()
+					resp := Response{}
+					switch req.Op {
+					case OpPending:
+						resp.Prompts = f.prompts
+					case OpResolve:
+						f.decisions = append(f.decisions, *req.Decision)
+					}
+					f.mu.Unlock()
+					_ = enc.Encode(resp)
+				}
+			}()
+		}
+	}()
+	return path
+}
+
+func TestAskResolvesOldestPrompt(t *testing.T) {
+	fake := &fakeControlServer{prompts: []PromptInfo{
+		{ID: "p1", ProcessPath: "/usr/bin/curl", DstHost: "api.github.com", DstPort: 443},
+		{ID: "p2", ProcessPath: "/usr/bin/wget", DstIP: "1.1.1.1", DstPort: 80},
+	}}
+	client, err := Dial(fake.serve(t))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	var out bytes.Buffer
+	err = Ask(client, strings.NewReader("x\nd\nA\n"), &out)
+	if code := ExitCode(err); code != ExitOK {
+		t.Fatalf("expected exit %d, got %d (%v)", ExitOK, code, err)
+	}
+	if !strings.Contains(out.String(), "allow /usr/bin/curl → api.github.com:443? [a]llow/[d]eny/[r]eject") {
+		t.Fatalf("unexpected question output: %q", out.String())
+	}
+	if !strings.Contains(out.String(), `unrecognised answer "x"`) {
+		t.Fatalf("expected invalid answer to be reported, got %q", out.String())
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.decisions) != 1 {
+		t.Fatalf("expected one decision, got %d", len(fake.decisions))
+	}
+	got := fake.decisions[0]
+	if got.PromptID != "p1" || got.Action != controller.PromptActionDeny || got.Duration != controller.PromptDurationAlways {
+		t.Fatalf("unexpected decision: %+v", got)
+	}
+}
+
+func TestAskNoPendingPrompts(t *testing.T) {
+	fake := &fakeControlServer{}
+	client, err := Dial(fake.serve(t))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	err = Ask(client, strings.NewReader(""), &bytes.Buffer{})
+	if code := ExitCode(err); code != ExitNoPending {
+		t.Fatalf("expected exit %d, got %d (%v)", ExitNoPending, code, err)
+	}
+}
+
+func TestDialInstanceNotRunning(t *testing.T) {
+	_, err := Dial(filepath.Join(t.TempDir(), "missing.sock"))
+	if code := ExitCode(err); code != ExitNotRunning {
+		t.Fatalf("expected exit %d, got %d (%v)", ExitNotRunning, code, err)
+	}
+}
+
+// flakyControlServer hangs up on its first connection after reading a
+// request, or leaves it unanswered when silent, and answers on later ones.
+func flakyControlServer(t *testing.T, silent bool) string {
+	t.Helper()
+	path := filepath.Join(shortTempDir(t), "ctl.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	go func() {
+		for first := true; ; first = false {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func(first bool) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					if first && silent {
+						continue
+					}
+					if first {
+						return
+					}
+					_ = json.NewEncoder(conn).Encode(Response{Prompts: []PromptInfo{{ID: "p1"}}})
+				}
+			}(first)
+		}
+	}()
+	return path
+}
+
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	// Socket paths are limited to about 100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "ostui")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestClientRedialsAfterTheConnectionDrops(t *testing.T) {
+	client, err := Dial(flakyControlServer(t, false))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Pending(); err == nil {
+		t.Fatalf("expected the dropped connection reported")
+	}
+	pending, err := client.Pending()
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected the next call to dial again, got %+v (%v)", pending, err)
+	}
+}
+
+func TestClientCallTimesOut(t *testing.T) {
+	defer func(old time.Duration) { callTimeout = old }(callTimeout)
+	callTimeout = 50 * time.Millisecond
+	client, err := Dial(flakyControlServer(t, true))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	if _, err := client.Pending(); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the call bounded by the deadline, took %v", elapsed)
+	}
+	if pending, err := client.Pending(); err != nil || len(pending) != 1 {
+		t.Fatalf("expected a fresh connection after the timeout, got %+v (%v)", pending, err)
+	}
+}
+
+func TestAskInputClosedBeforeAnswer(t *testing.T) {
+	fake := &fakeControlServer{prompts: []PromptInfo{{ID: "p1", DstIP: "10.0.0.1", DstPort: 22}}}
+	client, err := Dial(fake.serve(t))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	err = Ask(client, strings.NewReader("a\n"), &bytes.Buffer{})
+	if code := ExitCode(err); code != ExitError {
+		t.Fatalf("expected exit %d, got %d (%v)", ExitError, code, err)
+	}
+	if len(fake.decisions) != 0 {
+		t.Fatalf("expected no decision to be sent")
+	}
+}
+
+func TestServerHandleRules(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+
+	resp := NewServer(store, nil).Handle(Request{Op: OpRules})
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	if len(resp.Nodes) != 1 || len(resp.Rules["node-1"]) != 1 || resp.Rules["node-1"][0].Name != "ssh" {
+		t.Fatalf("unexpected rules response: %+v", resp)
+	}
+}
+
+// recordingPrompts is a prompt manager that removes resolved prompts from
+// the store, like the daemon server does.
+type recordingPrompts struct {
+	store     *state.Store
+	decisions []controller.PromptDecision
+}
+
+func (r *recordingPrompts) ResolvePrompt(decision controller.PromptDecision) error {
+	r.decisions = append(r.decisions, decision)
+	r.store.RemovePrompt(decision.PromptID)
+	return nil
+}
+func (r *recordingPrompts) PausePrompt(string) error  { return nil }
+func (r *recordingPrompts) ResumePrompt(string) error { return nil }
+
+func TestServerProxiesDecisionsFromClient(t *testing.T) {
+	store := state.NewStore()
+	store.AddPrompt(state.Prompt{ID: "p1", Connection: state.Connection{ProcessPath: "/usr/sbin/sshd", SrcIP: "203.0.113.9", DstPort: 22, Direction: state.DirectionInbound}})
+	prompts := &recordingPrompts{store: store}
+
+	dir, err := os.MkdirTemp("", "ostui")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "ctl.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go NewServer(store, prompts).Serve(ctx, path)
+
+	var client *Client
+	for range 100 {
+		if client, err = Dial(path); err == nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+
+	pending, err := client.Pending()
+	if err != nil || len(pending) != 1 || !pending[0].Inbound || pending[0].SrcIP != "203.0.113.9" {
+		t.Fatalf("expected the inbound prompt with its source, got %+v (%v)", pending, err)
+	}
+	decision := controller.PromptDecision{PromptID: "p1", Action: controller.PromptActionDeny, Duration: controller.PromptDurationOnce, Target: controller.PromptTargetSourceIP}
+	if err := client.Resolve(decision); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(prompts.decisions) != 1 || prompts.decisions[0] != decision {
+		t.Fatalf("expected the decision to reach the prompt manager, got %+v", prompts.decisions)
+	}
+	if pending, _ := client.Pending(); len(pending) != 0 {
+		t.Fatalf("expected no prompts left, got %+v", pending)
+	}
+}
+
+func TestServeCreatesAPrivateSocketDirectory(t *testing.T) {
+	path := filepath.Join(shortTempDir(t), "run", "ctl.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go NewServer(state.NewStore(), &recordingPrompts{}).Serve(ctx, path)
+
+	var err error
+	for range 100 {
+		var client *Client
+		if client, err = Dial(path); err == nil {
+			client.Close()
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected the socket directory created 0700, got %v (%v)", info.Mode(), err)
+	}
+}
+
+func TestServeRefusesSharedDirectoriesAndForeignFiles(t *testing.T) {
+	shared := shortTempDir(t)
+	if err := os.Chmod(shared, 0o755); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	srv := NewServer(state.NewStore(), &recordingPrompts{})
+	if err := srv.Serve(context.Background(), filepath.Join(shared, "ctl.sock")); err == nil || !strings.Contains(err.Error(), "private") {
+		t.Fatalf("expected a shared directory refused, got %v", err)
+	}
+
+	path := filepath.Join(shortTempDir(t), "ctl.sock")
+	if err := os.WriteFile(path, []byte("keep"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := srv.Serve(context.Background(), path); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("expected a file in the way refused, got %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "keep" {
+		t.Fatalf("expected the file left alone, got %q (%v)", data, err)
+	}
+}
+
+func TestDefaultSocketPathWithoutRuntimeDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	path := DefaultSocketPath()
+	if want := fmt.Sprintf("opensnitch-tui-%d", os.Getuid()); filepath.Base(filepath.Dir(path)) != want {
+		t.Fatalf("expected the socket in a per-user directory, got %s", path)
+	}
+}
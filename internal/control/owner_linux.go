@@ -0,0 +1,12 @@
+package control
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByMe reports whether info describes a file of the current user.
+func ownedByMe(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
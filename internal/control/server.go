@@ -0,0 +1,158 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Server answers control requests using the shared store and prompt manager.
+type Server struct {
+	store   *state.Store
+	prompts controller.PromptManager
+}
+
+// NewServer constructs a control server.
+func NewServer(store *state.Store, prompts controller.PromptManager) *Server {
+	return &Server{store: store, prompts: prompts}
+}
+
+// Serve listens on the unix socket at path until ctx is cancelled. The
+// socket's directory is created private to the user if missing and must not
+// be reachable by anyone else, so no other user can connect in the moment
+// before the socket is made 0600. A stale socket left behind by a crashed
+// instance is replaced; anything else at path, or a socket owned by someone
+// else, is left alone.
+func (s *Server) Serve(ctx context.Context, path string) error {
+	if path == "" {
+		return errors.New("control socket path required")
+	}
+	if err := privateDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return err
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = lis.Close()
+		return fmt.Errorf("chmod %s: %w", path, err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = lis.Close()
+	}()
+	defer os.Remove(path)
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// privateDir creates dir as 0700 if missing and checks that it belongs to
+// the user and is closed to everyone else.
+func privateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("control socket directory: %w", err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("control socket directory: %w", err)
+	}
+	if !info.IsDir() || !ownedByMe(info) || info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("control socket directory %s must be yours and private (mode 0700)", dir)
+	}
+	return nil
+}
+
+// removeStaleSocket clears path for a new socket: nothing there is fine, a
+// socket of this user that nobody answers on is removed, and anything else
+// is an error.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("control socket: %w", err)
+	}
+	if info.Mode().Type() != fs.ModeSocket || !ownedByMe(info) {
+		return fmt.Errorf("control socket %s exists and is not a socket of yours", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("control socket %s already in use", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove stale control socket: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = enc.Encode(Response{Error: fmt.Sprintf("decode request: %v", err)})
+			continue
+		}
+		if err := enc.Encode(s.Handle(req)); err != nil {
+			return
+		}
+	}
+}
+
+// Handle processes a single request.
+func (s *Server) Handle(req Request) Response {
+	switch req.Op {
+	case OpPending:
+		snapshot := s.store.Snapshot()
+		prompts := make([]PromptInfo, 0, len(snapshot.Prompts))
+		for _, p := range snapshot.Prompts {
+			prompts = append(prompts, promptInfo(p))
+		}
+		sort.SliceStable(prompts, func(i, j int) bool {
+			return prompts[i].RequestedAt.Before(prompts[j].RequestedAt)
+		})
+		return Response{Prompts: prompts}
+	case OpRules:
+		snapshot := s.store.Snapshot()
+		return Response{Nodes: snapshot.Nodes, Rules: snapshot.Rules}
+	case OpResolve:
+		if req.Decision == nil {
+			return Response{Error: "decision required"}
+		}
+		if s.prompts == nil {
+			return Response{Error: "prompt controller unavailable"}
+		}
+		if err := s.prompts.ResolvePrompt(*req.Decision); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{}
+	default:
+		return Response{Error: fmt.Sprintf("unknown op %q", req.Op)}
+	}
+}
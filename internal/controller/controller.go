@@ -1,13 +1,95 @@
 package controller
 
-import "github.com/adamkadaban/opensnitch-tui/internal/state"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
 
-// RuleManager exposes CRUD operations for daemon rules.
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// RuleManager exposes CRUD operations for daemon rules. Each call carries
+// the rules.Hash of the rule as the caller displayed it and fails with a
+// RuleConflictError when the node's rule no longer matches; an empty hash
+// skips the check.
 type RuleManager interface {
-	EnableRule(nodeID, ruleName string) error
-	DisableRule(nodeID, ruleName string) error
-	DeleteRule(nodeID, ruleName string) error
-	ChangeRule(nodeID string, rule state.Rule) error
+	EnableRule(nodeID, ruleName, hash string) error
+	DisableRule(nodeID, ruleName, hash string) error
+	DeleteRule(nodeID, ruleName, hash string) error
+	ChangeRule(nodeID string, rule state.Rule, hash string) error
+	// AddRule creates a rule; it fails if the node already has one by
+	// that name.
+	AddRule(nodeID string, rule state.Rule) error
+}
+
+// RuleTrash is implemented by rule managers that keep deleted rules in a
+// per-node trash. RestoreRule pushes a trashed rule back to its node.
+type RuleTrash interface {
+	RestoreRule(nodeID, ruleName string) error
+	// EmptyTrash drops the node's trashed rules and reports how many.
+	EmptyTrash(nodeID string) (int, error)
+}
+
+// RuleExporter is implemented by rule managers that can write a node's
+// rules as the JSON files opensnitchd loads from its rules directory.
+type RuleExporter interface {
+	// ExportRules writes one file per rule to dir, replacing files of the
+	// same name, and returns how many were written.
+	ExportRules(nodeID, dir string) (int, error)
+}
+
+// RuleImporter is implemented by rule managers that can push rule files
+// to a node.
+type RuleImporter interface {
+	// ImportRules reads the rule file at path, or the rule files in the
+	// directory at path, and sends them to the node, replacing rules of
+	// the same name.
+	ImportRules(nodeID, path string) (ImportResult, error)
+}
+
+// ImportResult is the outcome of an ImportRules call.
+type ImportResult struct {
+	Imported int
+	// Skipped has one error for each file or rule left out.
+	Skipped []error
+}
+
+// ErrRuleConflict matches errors from RuleManager calls made against a rule
+// that changed since it was displayed.
+var ErrRuleConflict = errors.New("rule changed since displayed")
+
+// RuleConflictError reports that Rule on NodeID no longer matches the hash
+// the caller sent.
+type RuleConflictError struct {
+	NodeID string
+	Rule   string
+}
+
+func (e *RuleConflictError) Error() string {
+	return fmt.Sprintf("rule %s on %s changed since displayed", e.Rule, e.NodeID)
+}
+
+// Is makes errors.Is(err, ErrRuleConflict) hold.
+func (e *RuleConflictError) Is(target error) bool { return target == ErrRuleConflict }
+
+// ErrNoController is what every view action reports when the view was
+// built without the controller the action goes through. The view still
+// shows the store's data.
+var ErrNoController = errors.New("read-only: no controller attached")
+
+// Attached reports whether c is a controller that can be called: neither
+// a nil interface nor one holding a nil pointer.
+func Attached(c any) bool {
+	if c == nil {
+		return false
+	}
+	v := reflect.ValueOf(c)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Func, reflect.Interface, reflect.Slice, reflect.Chan:
+		return !v.IsNil()
+	}
+	return true
 }
 
 // PromptManager resolves interactive connection prompts surfaced by the daemon.
@@ -17,7 +99,73 @@ type PromptManager interface {
 	ResumePrompt(promptID string) error
 }
 
-// SettingsManager persists UI configuration choices.
+// FirewallManager toggles a node's firewall, optionally for a limited time.
+type FirewallManager interface {
+	// EnableFirewall turns the firewall on and cancels any timed pause.
+	EnableFirewall(nodeID string) error
+	DisableFirewall(nodeID string) error
+	// PauseFirewall turns the firewall off now and back on after d.
+	PauseFirewall(nodeID string, d time.Duration) error
+}
+
+// MaintenanceManager puts a node in maintenance for a limited time: its
+// prompts are answered automatically and its alerts suppressed until the
+// window ends, when a summary alert is raised.
+type MaintenanceManager interface {
+	StartMaintenance(nodeID string, d time.Duration) error
+	// EndMaintenance closes the window early.
+	EndMaintenance(nodeID string) error
+}
+
+// BaselineManager marks a node's counters as a baseline, so the dashboard
+// can show the traffic since then next to the lifetime totals.
+type BaselineManager interface {
+	MarkBaseline(nodeID string) (state.Baseline, error)
+	// ClearBaseline drops the node's baseline and reports whether it had one.
+	ClearBaseline(nodeID string) (bool, error)
+}
+
+// WireInspector renders rules and connections in the protobuf text format
+// exchanged with the daemon, for bug reports.
+type WireInspector interface {
+	RuleWire(rule state.Rule) string
+	ConnectionWire(conn state.Connection) string
+}
+
+// ProtocolTracer keeps a bounded log of the notifications sent to each
+// node and the replies that came back, for debugging the daemon protocol.
+// Recording is off until enabled.
+type ProtocolTracer interface {
+	SetProtocolTrace(enabled bool)
+	ProtocolTraceEnabled() bool
+	// ProtocolTrace returns the node's recorded messages, oldest first.
+	ProtocolTrace(nodeID string) []state.TraceEntry
+	// DumpProtocolTrace writes the node's trace to a file and returns its
+	// path.
+	DumpProtocolTrace(nodeID string) (string, error)
+}
+
+// ErrNotPersisted matches errors from SettingsManager setters whose value was
+// applied for the session but could not be saved.
+var ErrNotPersisted = errors.New("setting not persisted")
+
+// NotPersistedError wraps the reason a setting could not be saved to Path.
+type NotPersistedError struct {
+	Path string
+	Err  error
+}
+
+func (e *NotPersistedError) Error() string {
+	return fmt.Sprintf("save %s: %v", e.Path, e.Err)
+}
+
+func (e *NotPersistedError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, ErrNotPersisted) hold.
+func (e *NotPersistedError) Is(target error) bool { return target == ErrNotPersisted }
+
+// SettingsManager persists UI configuration choices. Setters return the
+// applied value alongside an ErrNotPersisted error when only saving failed.
 type SettingsManager interface {
 	SetTheme(name string) (string, error)
 	SetDefaultPromptAction(action string) (string, error)
@@ -25,9 +173,34 @@ type SettingsManager interface {
 	SetDefaultPromptTarget(target string) (string, error)
 	SetAlertsInterrupt(enabled bool) (bool, error)
 	SetPromptTimeout(seconds int) (int, error)
+	SetPromptInitialFocus(focus string) (string, error)
 	SetPausePromptOnInspect(enabled bool) (bool, error)
 	SetYaraRuleDir(path string) (string, error)
 	SetYaraEnabled(enabled bool) (bool, error)
+	SetInspectHookEnabled(enabled bool) (bool, error)
+	SetDNDMinutes(minutes int) (int, error)
+	SetStartView(name string) (string, error)
+	SetRuleDescriptionTemplate(text string) (string, error)
+	// SaveProfile stores a workspace profile under its name, replacing an
+	// existing one, and returns it as saved.
+	SaveProfile(profile state.Profile) (state.Profile, error)
+}
+
+// SaveRetrier is implemented by settings managers that keep retrying a
+// failed save in the background. RetrySave tries again immediately.
+type SaveRetrier interface {
+	RetrySave() error
+}
+
+// NodeConfigManager is implemented by settings managers that can list
+// nodes in the config file, so nodes that connected on their own are known
+// after a restart.
+type NodeConfigManager interface {
+	// AddNode saves node; it fails if its address is already listed.
+	AddNode(node state.Node) error
+	// RemoveNode drops the entries with address and reports whether there
+	// were any.
+	RemoveNode(address string) (bool, error)
 }
 
 // PromptDecision captures an operator's selection for a pending prompt.
@@ -36,6 +209,11 @@ type PromptDecision struct {
 	Action   PromptAction
 	Duration PromptDuration
 	Target   PromptTarget
+	// Description describes the rule; empty renders the configured
+	// description template.
+	Description string
+	// Source records who made the decision (state.DecisionSource*); empty means the user.
+	Source string
 }
 
 type PromptAction string
@@ -64,4 +242,6 @@ const (
 	PromptTargetDestinationIP   PromptTarget = "dest.ip"
 	PromptTargetDestinationHost PromptTarget = "dest.host"
 	PromptTargetDestinationPort PromptTarget = "dest.port"
+	// PromptTargetSourceIP is the peer of an inbound connection.
+	PromptTargetSourceIP PromptTarget = "source.ip"
 )
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Available reports whether conn has a value for t. A PID of 0, and a UID
+// of 0 when uidZeroUnknown is set, mean the daemon could not tell; the
+// destination of an inbound connection is this host.
+func (t PromptTarget) Available(conn state.Connection, uidZeroUnknown bool) bool {
+	switch t {
+	case PromptTargetProcessPath:
+		return conn.ProcessPath != ""
+	case PromptTargetProcessCmd:
+		return len(conn.ProcessArgs) > 0 || conn.ProcessPath != ""
+	case PromptTargetDestinationHost:
+		return conn.DstHost != "" && !conn.Inbound()
+	case PromptTargetDestinationIP:
+		return conn.DstIP != "" && !conn.Inbound()
+	case PromptTargetDestinationPort:
+		return conn.DstPort != 0
+	case PromptTargetSourceIP:
+		return conn.SrcIP != "" && conn.Inbound()
+	case PromptTargetProcessID:
+		return conn.PIDKnown()
+	case PromptTargetUserID:
+		return conn.UIDKnown(uidZeroUnknown)
+	default:
+		return false
+	}
+}
+
+// BestTarget is the first target conn has a value for, from the
+// executable down to the user.
+func BestTarget(conn state.Connection, uidZeroUnknown bool) PromptTarget {
+	switch {
+	case conn.ProcessPath != "":
+		return PromptTargetProcessPath
+	case len(conn.ProcessArgs) > 0:
+		return PromptTargetProcessCmd
+	case conn.Inbound() && conn.SrcIP != "":
+		return PromptTargetSourceIP
+	case conn.Inbound() && conn.DstPort != 0:
+		return PromptTargetDestinationPort
+	case conn.DstHost != "":
+		return PromptTargetDestinationHost
+	case conn.DstIP != "":
+		return PromptTargetDestinationIP
+	case conn.DstPort != 0:
+		return PromptTargetDestinationPort
+	case conn.PIDKnown():
+		return PromptTargetProcessID
+	case conn.UIDKnown(uidZeroUnknown):
+		return PromptTargetUserID
+	default:
+		// Nothing is known; Operator explains why no rule is made.
+		return PromptTargetProcessID
+	}
+}
+
+// DefaultTarget is the target a prompt for conn starts on: preferred, the
+// configured default, when conn has a value for it, otherwise BestTarget.
+func DefaultTarget(conn state.Connection, preferred PromptTarget, uidZeroUnknown bool) PromptTarget {
+	if preferred != "" && preferred.Available(conn, uidZeroUnknown) {
+		return preferred
+	}
+	return BestTarget(conn, uidZeroUnknown)
+}
+
+// Operator builds the simple operator matching conn on t; each target is
+// named after the operand it matches. Values the daemon could not resolve
+// are refused rather than turned into rules on a zero PID or UID.
+func (t PromptTarget) Operator(conn state.Connection, uidZeroUnknown bool) (state.RuleOperator, error) {
+	simple := func(operand PromptTarget, data string) (state.RuleOperator, error) {
+		return state.RuleOperator{Type: "simple", Operand: string(operand), Data: data}, nil
+	}
+	switch t {
+	case PromptTargetProcessPath:
+		if conn.ProcessPath == "" {
+			return state.RuleOperator{}, fmt.Errorf("process path unavailable")
+		}
+		return simple(t, conn.ProcessPath)
+	case PromptTargetProcessCmd:
+		cmdLine := strings.TrimSpace(strings.Join(conn.ProcessArgs, " "))
+		if cmdLine == "" {
+			if conn.ProcessPath == "" {
+				return state.RuleOperator{}, fmt.Errorf("command line unavailable")
+			}
+			return simple(PromptTargetProcessPath, conn.ProcessPath)
+		}
+		return simple(t, cmdLine)
+	case PromptTargetProcessID:
+		if !conn.PIDKnown() {
+			return state.RuleOperator{}, fmt.Errorf("process id unknown: the daemon could not resolve the process")
+		}
+		return simple(t, fmt.Sprintf("%d", conn.ProcessID))
+	case PromptTargetUserID:
+		if !conn.UIDKnown(uidZeroUnknown) {
+			return state.RuleOperator{}, fmt.Errorf("user id unknown: the daemon could not resolve the owner")
+		}
+		return simple(t, fmt.Sprintf("%d", conn.UserID))
+	case PromptTargetDestinationIP:
+		if conn.DstIP == "" {
+			return state.RuleOperator{}, fmt.Errorf("destination ip unavailable")
+		}
+		return simple(t, conn.DstIP)
+	case PromptTargetDestinationHost:
+		if conn.DstHost == "" {
+			return state.RuleOperator{}, fmt.Errorf("destination host unavailable")
+		}
+		return simple(t, conn.DstHost)
+	case PromptTargetDestinationPort:
+		if conn.DstPort == 0 {
+			return state.RuleOperator{}, fmt.Errorf("destination port unavailable")
+		}
+		return simple(t, fmt.Sprintf("%d", conn.DstPort))
+	case PromptTargetSourceIP:
+		if conn.SrcIP == "" {
+			return state.RuleOperator{}, fmt.Errorf("source ip unavailable")
+		}
+		return simple(t, conn.SrcIP)
+	default:
+		return state.RuleOperator{}, fmt.Errorf("unsupported target %s", t)
+	}
+}
+
+// Normalize returns a, or deny when a is not an action a rule can take.
+func (a PromptAction) Normalize() PromptAction {
+	switch a {
+	case PromptActionAllow, PromptActionDeny, PromptActionReject:
+		return a
+	default:
+		return PromptActionDeny
+	}
+}
+
+// Normalize returns d, or once when d is not a duration a rule can have.
+func (d PromptDuration) Normalize() PromptDuration {
+	switch d {
+	case PromptDurationOnce, PromptDurationUntilRestart, PromptDurationAlways:
+		return d
+	default:
+		return PromptDurationOnce
+	}
+}
+
+// Resolve settles d for a prompt about conn the way the rule answering it
+// is built: the action and duration normalized, an empty target replaced
+// by BestTarget, and the operator for that target. It needs no daemon, so
+// a prompt can show the rule before it is answered.
+func (d PromptDecision) Resolve(conn state.Connection, uidZeroUnknown bool) (PromptDecision, state.RuleOperator, error) {
+	d.Action = d.Action.Normalize()
+	d.Duration = d.Duration.Normalize()
+	if d.Target == "" {
+		d.Target = BestTarget(conn, uidZeroUnknown)
+	}
+	operator, err := d.Target.Operator(conn, uidZeroUnknown)
+	return d, operator, err
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"strings"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// maxPendingOps bounds the notifications awaiting a reply; a daemon that
+// never replies must not grow the map forever.
+const maxPendingOps = 1024
+
+// ackVerbs names the rule actions the way the Rules view reports them.
+var ackVerbs = map[pb.Action]string{
+	pb.Action_ENABLE_RULE:  "enable",
+	pb.Action_DISABLE_RULE: "disable",
+	pb.Action_DELETE_RULE:  "delete",
+	pb.Action_CHANGE_RULE:  "change",
+}
+
+// trackOp remembers notif as sent to nodeID now, so its reply can be timed.
+func (s *Server) trackOp(nodeID string, notif *pb.Notification) {
+	action, ok := ackVerbs[notif.GetType()]
+	if !ok {
+		action = strings.ToLower(notif.GetType().String())
+	}
+	ack := state.ActionAck{NodeID: nodeID, Action: action, SentAt: s.now()}
+	if rules := notif.GetRules(); len(rules) > 0 {
+		ack.Rule = util.Sanitize(rules[0].GetName())
+	}
+	s.opsMu.Lock()
+	defer s.opsMu.Unlock()
+	if len(s.pendingOps) >= maxPendingOps {
+		clear(s.pendingOps)
+	}
+	s.pendingOps[notif.GetId()] = ack
+}
+
+// untrackOp forgets a notification that could not be queued.
+func (s *Server) untrackOp(id uint64) {
+	s.opsMu.Lock()
+	delete(s.pendingOps, id)
+	s.opsMu.Unlock()
+}
+
+// ackOp times the reply to a tracked notification and records it. Replies
+// to unknown IDs, or from another node than the one notified, are ignored.
+func (s *Server) ackOp(nodeID string, reply *pb.NotificationReply) {
+	s.opsMu.Lock()
+	ack, ok := s.pendingOps[reply.GetId()]
+	if ok && ack.NodeID == nodeID {
+		delete(s.pendingOps, reply.GetId())
+	}
+	s.opsMu.Unlock()
+	if !ok || ack.NodeID != nodeID {
+		return
+	}
+	ack.Latency = max(0, s.now().Sub(ack.SentAt))
+	ack.Slow = ack.Latency > s.store.Snapshot().Settings.SlowAckThreshold()
+	if reply.GetCode() == pb.NotificationReplyCode_ERROR {
+		ack.Err = util.Sanitize(strings.TrimSpace(reply.GetData()))
+		if ack.Err == "" {
+			ack.Err = "daemon reported an error"
+		}
+		s.store.ReportError(state.SubsystemDaemon, strings.TrimSpace(ack.Action+" "+ack.Rule)+" on "+s.nodeName(nodeID)+": "+ack.Err)
+	}
+	s.store.RecordAck(ack)
+}
+
+// abandonOps fails the actions still awaiting a reply from nodeID once its
+// notification stream is gone; no reply can arrive any more.
+func (s *Server) abandonOps(nodeID string) {
+	var lost []state.ActionAck
+	s.opsMu.Lock()
+	for id, ack := range s.pendingOps {
+		if ack.NodeID == nodeID {
+			lost = append(lost, ack)
+			delete(s.pendingOps, id)
+		}
+	}
+	s.opsMu.Unlock()
+	for _, ack := range lost {
+		ack.Latency = max(0, s.now().Sub(ack.SentAt))
+		ack.Err = "disconnected before acknowledging"
+		s.store.RecordAck(ack)
+	}
+}
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestServerTimesRuleActionAcks(t *testing.T) {
+	store := state.NewStore()
+	clock := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	srv := New(store, Options{})
+	srv.now = func() time.Time { return clock }
+	nodeID := "tcp://10.0.0.5:50051"
+	store.UpsertNode(state.Node{ID: nodeID, Name: "alpha"})
+	store.SetRules(nodeID, []state.Rule{{Name: "ssh", Enabled: false}})
+	sess, _ := srv.registerSession(nodeID)
+
+	// A quick reply is timed and folded into the node's average.
+	if err := srv.EnableRule(nodeID, "ssh", ""); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	clock = clock.Add(84 * time.Millisecond)
+	srv.ackOp(nodeID, &pb.NotificationReply{Id: 1})
+	ack, ok := state.FindAck(store.Snapshot().Acks, nodeID, "enable", "ssh", time.Time{})
+	if !ok || ack.Latency != 84*time.Millisecond || ack.Slow || ack.Err != "" {
+		t.Fatalf("expected an 84ms ack, got %+v (found %v)", ack, ok)
+	}
+
+	// Past the threshold the ack is flagged slow.
+	if err := srv.DisableRule(nodeID, "ssh", ""); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	clock = clock.Add(state.DefaultSlowAck + time.Second)
+	srv.ackOp(nodeID, &pb.NotificationReply{Id: 2})
+	ack, _ = state.FindAck(store.Snapshot().Acks, nodeID, "disable", "ssh", time.Time{})
+	if !ack.Slow || ack.Latency != 4*time.Second {
+		t.Fatalf("expected a slow 4s ack, got %+v", ack)
+	}
+	node := store.Snapshot().Nodes[0]
+	if node.AckCount != 2 || node.SlowAcks != 1 || node.AckAverage != 2042*time.Millisecond {
+		t.Fatalf("unexpected ack stats: %+v", node)
+	}
+
+	// Replies to unknown or already answered IDs are ignored.
+	srv.ackOp(nodeID, &pb.NotificationReply{Id: 2})
+	if got := len(store.Snapshot().Acks); got != 2 {
+		t.Fatalf("expected duplicate reply ignored, got %d acks", got)
+	}
+
+	// Actions still pending when the stream ends fail.
+	if err := srv.DeleteRule(nodeID, "ssh", ""); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	srv.unregisterSession(nodeID, sess)
+	ack, ok = state.FindAck(store.Snapshot().Acks, nodeID, "delete", "ssh", time.Time{})
+	if !ok || ack.Err == "" {
+		t.Fatalf("expected the pending delete to fail on disconnect, got %+v", ack)
+	}
+}
+
+func TestServerRecordsErrorAcks(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	nodeID := "tcp://10.0.0.6:50051"
+	store.SetRules(nodeID, []state.Rule{{Name: "ssh"}})
+	srv.registerSession(nodeID)
+
+	if err := srv.EnableRule(nodeID, "ssh", ""); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	srv.ackOp(nodeID, &pb.NotificationReply{Id: 1, Code: pb.NotificationReplyCode_ERROR, Data: "rule file not writable"})
+	ack, ok := state.FindAck(store.Snapshot().Acks, nodeID, "enable", "ssh", time.Time{})
+	if !ok || ack.Err != "rule file not writable" {
+		t.Fatalf("expected the daemon's error, got %+v", ack)
+	}
+	if log := store.Snapshot().Log; len(log) == 0 || log[0].Subsystem != state.SubsystemDaemon {
+		t.Fatalf("expected the error in the session log, got %+v", log)
+	}
+}
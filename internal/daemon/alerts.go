@@ -6,6 +6,7 @@ import (
 
 	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
 func convertAlert(alert *pb.Alert, nodeID string) state.Alert {
@@ -16,7 +17,7 @@ func convertAlert(alert *pb.Alert, nodeID string) state.Alert {
 	return state.Alert{
 		ID:        fmt.Sprintf("%d", alert.GetId()),
 		NodeID:    nodeID,
-		Text:      alert.GetText(),
+		Text:      util.Sanitize(alert.GetText()),
 		Priority:  alert.GetPriority().String(),
 		Type:      alert.GetType().String(),
 		Action:    alert.GetAction().String(),
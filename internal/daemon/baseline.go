@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// MarkBaseline implements controller.BaselineManager with the counters
+// nodeID sent in its last ping.
+func (s *Server) MarkBaseline(nodeID string) (state.Baseline, error) {
+	baseline, err := s.store.MarkBaseline(nodeID, s.now())
+	if err != nil {
+		return state.Baseline{}, err
+	}
+	s.saveBaselines()
+	return baseline, nil
+}
+
+// ClearBaseline implements controller.BaselineManager.
+func (s *Server) ClearBaseline(nodeID string) (bool, error) {
+	node, ok := s.nodeByID(nodeID)
+	if !ok {
+		return false, fmt.Errorf("node %s not found", nodeID)
+	}
+	cleared := s.store.ClearBaseline(state.NodeKey(node))
+	if cleared {
+		s.saveBaselines()
+	}
+	return cleared, nil
+}
+
+// rebaseOnRestart moves the baseline of nodeID when its counters started
+// over, which the store logs as a warning.
+func (s *Server) rebaseOnRestart(nodeID string) {
+	if s.store.RebaseRestarted(nodeID, s.now()) {
+		s.saveBaselines()
+	}
+}
+
+// saveBaselines queues the baselines for writing, if they are persisted.
+func (s *Server) saveBaselines() {
+	if s.opts.Baselines == nil {
+		return
+	}
+	if err := s.opts.Writer.Schedule(s.opts.Baselines.Document(s.store.Snapshot().Baselines)); err != nil {
+		s.store.ReportError(state.SubsystemDaemon, fmt.Sprintf("baselines: %v", err))
+	}
+}
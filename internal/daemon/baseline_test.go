@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/baseline"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestServerBaselineResetsOnDaemonRestart(t *testing.T) {
+	store := state.NewStore()
+	file := baseline.New(t.TempDir() + "/baselines.json")
+	srv := New(store, Options{Baselines: file})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return now }
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:1000"}})
+	nodeID := "tcp://1.2.3.4:1000"
+	store.UpsertNode(state.Node{ID: nodeID, Name: "laptop"})
+	ping := func(connections, accepted uint64) {
+		t.Helper()
+		req := &pb.PingRequest{Id: 1, Stats: &pb.Statistics{Connections: connections, Accepted: accepted}}
+		if _, err := srv.Ping(ctx, req); err != nil {
+			t.Fatalf("Ping returned error: %v", err)
+		}
+	}
+
+	ping(500, 450)
+	if _, err := srv.MarkBaseline(nodeID); err != nil {
+		t.Fatalf("MarkBaseline error: %v", err)
+	}
+	ping(520, 460)
+	if saved, err := file.Load(); err != nil || len(saved) != 1 || saved[0].Counters.Connections != 500 || saved[0].Restarted {
+		t.Fatalf("expected the marked baseline saved, got %+v, %v", saved, err)
+	}
+
+	now = now.Add(time.Hour)
+	ping(7, 6) // the daemon restarted
+	saved, err := file.Load()
+	if err != nil || len(saved) != 1 || saved[0].Counters.Connections != 7 || !saved[0].Restarted || !saved[0].MarkedAt.Equal(now) {
+		t.Fatalf("expected the baseline reset and saved, got %+v, %v", saved, err)
+	}
+
+	if cleared, err := srv.ClearBaseline(nodeID); err != nil || !cleared {
+		t.Fatalf("expected the baseline cleared, got %v, %v", cleared, err)
+	}
+	if saved, _ := file.Load(); len(saved) != 0 {
+		t.Fatalf("expected no saved baselines, got %+v", saved)
+	}
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// blocklistMatch checks the remote end of conn, the destination or, for
+// inbound connections, the source, against the configured blocklists.
+func (s *Server) blocklistMatch(conn state.Connection) *state.BlocklistMatch {
+	if s.opts.Blocklists == nil {
+		return nil
+	}
+	raw := restoreConnection(conn)
+	host, ip := raw.DstHost, raw.DstIP
+	if raw.Inbound() {
+		host, ip = "", raw.SrcIP
+	}
+	match, ok := s.opts.Blocklists.Match(host, ip)
+	if !ok {
+		return nil
+	}
+	return &state.BlocklistMatch{
+		List:   match.List,
+		Line:   match.Line,
+		Entry:  match.Entry,
+		Strict: match.Strict,
+		Host:   match.Host,
+	}
+}
+
+// blocklistTarget is the rule target covering the listed entry: the host
+// name when it matched by name, otherwise the remote address.
+func blocklistTarget(prompt state.Prompt, uidZeroUnknown bool) controller.PromptTarget {
+	conn := prompt.Connection
+	for _, target := range []controller.PromptTarget{
+		controller.PromptTargetDestinationHost,
+		controller.PromptTargetDestinationIP,
+		controller.PromptTargetSourceIP,
+	} {
+		if target == controller.PromptTargetDestinationHost && !prompt.Blocklist.Host {
+			continue
+		}
+		if target.Available(conn, uidZeroUnknown) {
+			return target
+		}
+	}
+	return controller.BestTarget(conn, uidZeroUnknown)
+}
+
+// blocklistDecision denies a prompt matched by a strict blocklist for good.
+func (s *Server) blocklistDecision(prompt state.Prompt) controller.PromptDecision {
+	decision := s.defaultPromptDecision(prompt)
+	decision.Duration = controller.PromptDurationAlways
+	decision.Source = state.DecisionSourceBlocklist
+	return decision
+}
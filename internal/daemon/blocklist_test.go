@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/peer"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/blocklist"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func testBlocklists(t *testing.T, strict bool) *blocklist.Set {
+	t.Helper()
+	src := blocklist.Source{Name: "ads.txt", Path: "ads.txt", Strict: strict}
+	list, err := blocklist.Parse(src, strings.NewReader("# ads\nads.example.com\n198.51.100.0/24\n"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	set := blocklist.NewSet([]blocklist.Source{src})
+	set.Replace(0, list)
+	return set
+}
+
+func TestServerAskRuleStrictBlocklistDeniesWithoutPrompt(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{Blocklists: testBlocklists(t, true)})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:6000"}})
+	conn := &pb.Connection{ProcessPath: "/usr/bin/curl", DstHost: "cdn.ads.example.com", DstIp: "203.0.113.9", DstPort: 443}
+
+	rule, err := srv.AskRule(ctx, conn)
+	if err != nil {
+		t.Fatalf("AskRule returned error: %v", err)
+	}
+	if rule.GetAction() != "deny" || rule.GetDuration() != "always" {
+		t.Fatalf("expected a permanent deny, got %s/%s", rule.GetAction(), rule.GetDuration())
+	}
+	if op := rule.GetOperator(); op.GetOperand() != "dest.host" || op.GetData() != "cdn.ads.example.com" {
+		t.Fatalf("expected the rule on the destination host, got %+v", op)
+	}
+	snap := store.Snapshot()
+	if len(snap.Prompts) != 0 || len(snap.Decisions) != 1 || snap.Decisions[0].Source != state.DecisionSourceBlocklist {
+		t.Fatalf("expected a blocklist decision and no prompt, got %+v / %+v", snap.Prompts, snap.Decisions)
+	}
+}
+
+func TestServerAskRuleBlocklistOverridesDefaults(t *testing.T) {
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.PromptTimeout = 50 * time.Millisecond
+	settings.DefaultPromptAction = "allow"
+	settings.DefaultPromptTarget = "process.path"
+	store.SetSettings(settings)
+	srv := New(store, Options{Blocklists: testBlocklists(t, false)})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:6000"}})
+	conn := &pb.Connection{ProcessPath: "/usr/bin/curl", DstIp: "198.51.100.20", DstPort: 443}
+
+	queued := make(chan *state.BlocklistMatch, 1)
+	sub := store.Subscribe()
+	defer sub.Close()
+	go func() {
+		for range sub.Events() {
+			if prompts := store.Snapshot().Prompts; len(prompts) == 1 {
+				queued <- prompts[0].Blocklist
+				return
+			}
+		}
+	}()
+
+	rule, err := srv.AskRule(ctx, conn)
+	if err != nil {
+		t.Fatalf("AskRule returned error: %v", err)
+	}
+	select {
+	case match := <-queued:
+		if match == nil || match.List != "ads.txt" || match.Line != 3 || match.Entry != "198.51.100.0/24" || match.Host {
+			t.Fatalf("expected the prompt to carry the match, got %+v", match)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a prompt to be queued for a non-strict list")
+	}
+	if rule.GetAction() != "deny" {
+		t.Fatalf("expected the blocklist to win over the allow default, got %q", rule.GetAction())
+	}
+	if op := rule.GetOperator(); op.GetOperand() != "dest.ip" || op.GetData() != "198.51.100.20" {
+		t.Fatalf("expected the rule on the destination address, got %+v", op)
+	}
+}
+
+func TestBlocklistMatchChecksInboundSource(t *testing.T) {
+	srv := New(state.NewStore(), Options{Blocklists: testBlocklists(t, false)})
+	inbound := state.Connection{Direction: state.DirectionInbound, SrcIP: "198.51.100.7", DstIP: "10.0.0.2", DstPort: 22}
+	if match := srv.blocklistMatch(inbound); match == nil || match.Entry != "198.51.100.0/24" {
+		t.Fatalf("expected the inbound source matched, got %+v", match)
+	}
+	if match := New(state.NewStore(), Options{}).blocklistMatch(inbound); match != nil {
+		t.Fatalf("expected no match without blocklists, got %+v", match)
+	}
+}
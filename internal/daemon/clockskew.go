@@ -0,0 +1,32 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// observeClockSkew feeds the newest event stamp of a ping into the node's skew
+// estimate and publishes it on the node once there are enough samples.
+func (s *Server) observeClockSkew(nodeID string, events []state.Event, received time.Time) {
+	var newest int64
+	for _, ev := range events {
+		if ev.UnixNano > newest {
+			newest = ev.UnixNano
+		}
+	}
+	if newest == 0 {
+		return
+	}
+	s.skewMu.Lock()
+	s.skew.Observe(nodeID, time.Unix(0, newest), received)
+	offset, ok := s.skew.Estimate(nodeID)
+	s.skewMu.Unlock()
+	if !ok {
+		return
+	}
+	s.store.UpdateNode(nodeID, func(n *state.Node) {
+		n.ClockSkew = offset
+		n.ClockSkewKnown = true
+	})
+}
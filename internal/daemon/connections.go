@@ -1,6 +1,8 @@
 package daemon
 
 import (
+	"net"
+
 	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 )
@@ -30,5 +32,55 @@ func convertConnection(conn *pb.Connection) state.Connection {
 			converted.ProcessChecksums[key] = value
 		}
 	}
-	return converted
+	converted.Direction = guessDirection(converted.SrcIP, converted.DstIP)
+	return sanitizeConnection(converted)
+}
+
+// guessDirection infers the direction, which the protocol does not carry. Local
+// processes connect from a local address, so a remote source talking to a
+// local destination can only be a peer reaching a listener here. Anything
+// ambiguous is outbound, which is all upstream opensnitch intercepts.
+func guessDirection(srcIP, dstIP string) state.Direction {
+	src, dst := net.ParseIP(srcIP), net.ParseIP(dstIP)
+	if src == nil || dst == nil {
+		return state.DirectionOutbound
+	}
+	if !isLocalIP(src) && isLocalIP(dst) {
+		return state.DirectionInbound
+	}
+	return state.DirectionOutbound
+}
+
+// isLocalIP reports addresses that are not reachable from the internet:
+// loopback, private, link-local and unspecified.
+func isLocalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+// serializeConnection rebuilds the wire form of a connection, with the
+// bytes the daemon sent.
+func serializeConnection(conn state.Connection) *pb.Connection {
+	conn = restoreConnection(conn)
+	proto := &pb.Connection{
+		Protocol:    conn.Protocol,
+		SrcIp:       conn.SrcIP,
+		SrcPort:     conn.SrcPort,
+		DstIp:       conn.DstIP,
+		DstHost:     conn.DstHost,
+		DstPort:     conn.DstPort,
+		UserId:      conn.UserID,
+		ProcessId:   conn.ProcessID,
+		ProcessPath: conn.ProcessPath,
+		ProcessCwd:  conn.ProcessCWD,
+	}
+	if len(conn.ProcessArgs) > 0 {
+		proto.ProcessArgs = append([]string{}, conn.ProcessArgs...)
+	}
+	if len(conn.ProcessChecksums) > 0 {
+		proto.ProcessChecksums = make(map[string]string, len(conn.ProcessChecksums))
+		for key, value := range conn.ProcessChecksums {
+			proto.ProcessChecksums[key] = value
+		}
+	}
+	return proto
 }
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestGuessDirection(t *testing.T) {
+	tests := []struct {
+		name     string
+		src, dst string
+		want     state.Direction
+	}{
+		{"local to remote", "192.168.1.5", "93.184.216.34", state.DirectionOutbound},
+		{"remote to local", "93.184.216.34", "192.168.1.5", state.DirectionInbound},
+		{"remote to loopback", "203.0.113.7", "127.0.0.1", state.DirectionInbound},
+		{"remote to link-local v6", "2001:db8::1", "fe80::1", state.DirectionInbound},
+		{"local to local", "10.0.0.2", "10.0.0.3", state.DirectionOutbound},
+		{"loopback to loopback", "127.0.0.1", "127.0.0.53", state.DirectionOutbound},
+		{"remote to remote", "93.184.216.34", "198.51.100.1", state.DirectionOutbound},
+		{"missing source", "", "192.168.1.5", state.DirectionOutbound},
+		{"hostname destination", "93.184.216.34", "example.com", state.DirectionOutbound},
+	}
+	for _, tc := range tests {
+		if got := guessDirection(tc.src, tc.dst); got != tc.want {
+			t.Errorf("%s: guessDirection(%q, %q) = %s, want %s", tc.name, tc.src, tc.dst, got, tc.want)
+		}
+	}
+}
+
+func TestConnectionDirection(t *testing.T) {
+	conn := convertConnection(&pb.Connection{SrcIp: "93.184.216.34", DstIp: "192.168.1.5"})
+	if !conn.Inbound() {
+		t.Fatalf("expected the heuristic to mark the connection inbound, got %q", conn.Direction)
+	}
+}
+
+func TestPromptTargetsForInbound(t *testing.T) {
+	inbound := state.Connection{Direction: state.DirectionInbound, SrcIP: "203.0.113.7", DstIP: "192.168.1.5", DstHost: "laptop", DstPort: 22}
+	outbound := state.Connection{SrcIP: "192.168.1.5", DstIP: "93.184.216.34", DstHost: "example.com", DstPort: 443}
+
+	tests := []struct {
+		conn   state.Connection
+		target controller.PromptTarget
+		want   bool
+	}{
+		{inbound, controller.PromptTargetSourceIP, true},
+		{inbound, controller.PromptTargetDestinationIP, false},
+		{inbound, controller.PromptTargetDestinationHost, false},
+		{inbound, controller.PromptTargetDestinationPort, true},
+		{outbound, controller.PromptTargetSourceIP, false},
+		{outbound, controller.PromptTargetDestinationIP, true},
+		{outbound, controller.PromptTargetDestinationHost, true},
+	}
+	for _, tc := range tests {
+		if got := tc.target.Available(tc.conn, false); got != tc.want {
+			t.Errorf("%s %s: available = %v, want %v", tc.conn.Direction, tc.target, got, tc.want)
+		}
+	}
+
+	if got := controller.BestTarget(inbound, false); got != controller.PromptTargetSourceIP {
+		t.Fatalf("expected the source ip for an inbound connection, got %s", got)
+	}
+	op, err := operatorForTarget(inbound, controller.PromptTargetSourceIP, false)
+	if err != nil || op.GetOperand() != "source.ip" || op.GetData() != "203.0.113.7" {
+		t.Fatalf("unexpected source operator %v, %v", op, err)
+	}
+}
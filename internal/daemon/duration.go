@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/ruleconv"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// detectDurationDialect works out how a daemon spells each duration from
+// the rules it subscribed with. When its rules disagree the most common
+// spelling wins, ties going to the one seen first. Spellings equal to the
+// canonical one are recorded too, so they outvote stray variants.
+func detectDurationDialect(rules []*pb.Rule) map[string]string {
+	counts := make(map[string]int)
+	dialect := make(map[string]string)
+	for _, rule := range rules {
+		raw := rule.GetDuration()
+		canonical, known := ruleconv.CanonicalDuration(raw)
+		if !known {
+			continue
+		}
+		counts[raw]++
+		if best, ok := dialect[canonical]; !ok || counts[raw] > counts[best] {
+			dialect[canonical] = raw
+		}
+	}
+	return dialect
+}
+
+// learnDurationDialect records the duration spellings of nodeID's daemon.
+func (s *Server) learnDurationDialect(nodeID string, rules []*pb.Rule) {
+	dialect := detectDurationDialect(rules)
+	s.dialectsMu.Lock()
+	defer s.dialectsMu.Unlock()
+	if len(dialect) == 0 {
+		delete(s.dialects, nodeID)
+		return
+	}
+	s.dialects[nodeID] = dialect
+}
+
+// daemonDuration spells a canonical duration the way nodeID's daemon does;
+// durations it has not used yet are sent as they are.
+func (s *Server) daemonDuration(nodeID, duration string) string {
+	s.dialectsMu.Lock()
+	defer s.dialectsMu.Unlock()
+	if raw, ok := s.dialects[nodeID][duration]; ok {
+		return raw
+	}
+	return duration
+}
+
+// serializeRuleFor serializes rule for nodeID's daemon, in its dialect and
+// on top of the daemon's own copy of the rule when there is one.
+func (s *Server) serializeRuleFor(nodeID string, rule state.Rule) *pb.Rule {
+	proto := ruleconv.ToProto(rule)
+	proto.Duration = s.daemonDuration(nodeID, rule.Duration)
+	if orig := s.originalRule(nodeID, rule.Name); orig != nil {
+		return mergeRule(orig, proto)
+	}
+	return proto
+}
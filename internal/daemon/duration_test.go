@@ -0,0 +1,204 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestDetectDurationDialect(t *testing.T) {
+	cases := []struct {
+		name      string
+		durations []string
+		want      map[string]string
+	}{
+		{"current daemon", []string{"always", "until restart"}, map[string]string{"always": "always", "until restart": "until restart"}},
+		{"underscore fork", []string{"until_restart", "once"}, map[string]string{"until restart": "until_restart", "once": "once"}},
+		{"bare restart", []string{"restart"}, map[string]string{"until restart": "restart"}},
+		{"majority wins", []string{"until restart", "until_restart", "until_restart"}, map[string]string{"until restart": "until_restart"}},
+		{"tie keeps first", []string{"restart", "until restart"}, map[string]string{"until restart": "restart"}},
+		{"timed and unknown ignored", []string{"30m", "", "forever"}, map[string]string{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := make([]*pb.Rule, len(tc.durations))
+			for i, d := range tc.durations {
+				rules[i] = &pb.Rule{Name: "r", Duration: d}
+			}
+			got := detectDurationDialect(rules)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for canonical, raw := range tc.want {
+				if got[canonical] != raw {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerSpeaksEachNodesDurationDialect(t *testing.T) {
+	cases := []struct {
+		name, spelling string
+	}{
+		{"current", "until restart"},
+		{"underscore", "until_restart"},
+		{"bare", "restart"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := state.NewStore()
+			srv := New(store, Options{})
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:5000"}})
+			nodeID := "tcp://1.2.3.4:5000"
+			cfg := &pb.ClientConfig{Name: "daemon", Rules: []*pb.Rule{{
+				Name: "ssh", Action: "allow", Duration: tc.spelling,
+				Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+			}}}
+			if _, err := srv.Subscribe(ctx, cfg); err != nil {
+				t.Fatalf("Subscribe error: %v", err)
+			}
+			rule := store.Snapshot().Rules[nodeID][0]
+			if rule.Duration != "until restart" {
+				t.Fatalf("expected the canonical duration in the store, got %q", rule.Duration)
+			}
+
+			// Editing the rule sends the duration back as the daemon wrote it.
+			_, queue := srv.registerSession(nodeID)
+			hash := ruleset.Hash(rule)
+			rule.Description = "edited"
+			if err := srv.ChangeRule(nodeID, rule, hash); err != nil {
+				t.Fatalf("ChangeRule error: %v", err)
+			}
+			if got := (<-queue).Rules[0].GetDuration(); got != tc.spelling {
+				t.Fatalf("expected %q sent to the daemon, got %q", tc.spelling, got)
+			}
+		})
+	}
+
+	// A node that never reported the duration gets the canonical spelling.
+	srv := New(state.NewStore(), Options{})
+	if got := srv.serializeRuleFor("other", state.Rule{Duration: "until restart"}).GetDuration(); got != "until restart" {
+		t.Fatalf("expected the canonical spelling for an unknown dialect, got %q", got)
+	}
+}
+
+func TestServerExportRulesKeepsTheDaemonsSpelling(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:5000"}})
+	cfg := &pb.ClientConfig{Name: "daemon", Rules: []*pb.Rule{{
+		Name: "ssh", Action: "allow", Duration: "until_restart", Enabled: true,
+		Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+	}}}
+	if _, err := srv.Subscribe(ctx, cfg); err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+
+	dir := t.TempDir()
+	written, err := srv.ExportRules("tcp://1.2.3.4:5000", dir)
+	if err != nil || written != 1 {
+		t.Fatalf("ExportRules = %d, %v", written, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ssh.json"))
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	var file ruleset.File
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if file.Duration != "until_restart" || file.Operator.Data != "/usr/bin/ssh" || !file.Enabled {
+		t.Fatalf("unexpected exported rule %+v", file)
+	}
+	if _, err := srv.ExportRules("tcp://9.9.9.9:1", dir); err == nil {
+		t.Fatal("expected an unknown node refused")
+	}
+}
+
+func TestServerImportRulesSendsOneNotification(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:5000"}})
+	cfg := &pb.ClientConfig{Name: "daemon", Rules: []*pb.Rule{{
+		Name: "ssh", Action: "deny", Duration: "always", Enabled: true,
+		Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+	}}}
+	if _, err := srv.Subscribe(ctx, cfg); err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	nodeID := "tcp://1.2.3.4:5000"
+	_, queue := srv.registerSession(nodeID)
+
+	dir := t.TempDir()
+	rules := []state.Rule{
+		{Name: "ssh", Action: "allow", Duration: "always", Enabled: true, Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"}},
+		{Name: "curl", Action: "deny", Duration: "until restart", Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}},
+		{Name: "long", Action: "allow", Description: strings.Repeat("x", ruleset.DefaultMaxText+1), Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/nc"}},
+	}
+	for i := range 9 {
+		rules = append(rules, state.Rule{Name: fmt.Sprintf("port-%d", i), Action: "allow", Duration: "always", Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: fmt.Sprint(8000 + i)}})
+	}
+	if _, err := ruleset.WriteFiles(dir, rules); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// These decode but could not be applied by the daemon.
+	malformed := map[string]string{
+		"bare":       `{"name":"bare"}`,
+		"action":     `{"name":"action","action":"maybe","duration":"always","operator":{"type":"simple","operand":"process.path","data":"/bin/sh"}}`,
+		"duration":   `{"name":"duration","action":"allow","duration":"forever","operator":{"type":"simple","operand":"process.path","data":"/bin/sh"}}`,
+		"type":       `{"name":"type","action":"allow","duration":"always","operator":{"type":"fuzzy","operand":"process.path","data":"/bin/sh"}}`,
+		"operand":    `{"name":"operand","action":"allow","duration":"always","operator":{"type":"simple","operand":"dest.color","data":"red"}}`,
+		"empty-list": `{"name":"empty-list","action":"allow","duration":"30m","operator":{"type":"list","operand":"list"}}`,
+	}
+	for name, data := range malformed {
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := srv.ImportRules(nodeID, dir)
+	if err != nil {
+		t.Fatalf("ImportRules: %v", err)
+	}
+	if result.Imported != 11 || len(result.Skipped) != 2+len(malformed) {
+		t.Fatalf("expected 11 imported and %d skipped, got %d %v", 2+len(malformed), result.Imported, result.Skipped)
+	}
+	skipped := fmt.Sprint(result.Skipped)
+	for _, want := range []string{`bare: unknown action ""`, `action: unknown action "maybe"`, `duration: unknown duration "forever"`, `type: unknown operator type "fuzzy"`, `operand: unknown operand "dest.color"`, "empty-list: list operator without conditions"} {
+		if !strings.Contains(skipped, want) {
+			t.Fatalf("expected %q among the skipped files, got %s", want, skipped)
+		}
+	}
+	notif := <-queue
+	if notif.GetType() != pb.Action_CHANGE_RULE || len(notif.GetRules()) != 11 {
+		t.Fatalf("expected one CHANGE_RULE with every rule, got %v with %d", notif.GetType(), len(notif.GetRules()))
+	}
+	if len(queue) != 0 {
+		t.Fatalf("expected a single notification, %d more queued", len(queue))
+	}
+	snap := store.Snapshot()
+	if ssh, _ := ruleset.Lookup(snap.Rules, nodeID, "ssh"); ssh.Action != "allow" {
+		t.Fatalf("expected ssh replaced, got %+v", ssh)
+	}
+	if curl, ok := ruleset.Lookup(snap.Rules, nodeID, "curl"); !ok || curl.Duration != "until restart" {
+		t.Fatalf("expected curl added with the canonical duration, got %+v", curl)
+	}
+	if _, err := srv.ImportRules("tcp://9.9.9.9:1", dir); err == nil {
+		t.Fatal("expected an unknown node refused")
+	}
+}
@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/ruleconv"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// ExportRules implements controller.RuleExporter. Rules are written as the
+// daemon sent them, with the bytes sanitizing replaced and the duration in
+// the node's own spelling, so opensnitchd reads back what it had.
+func (s *Server) ExportRules(nodeID, dir string) (int, error) {
+	if _, ok := s.nodeByID(nodeID); !ok {
+		return 0, fmt.Errorf("node %s not found", nodeID)
+	}
+	rules := s.store.Snapshot().Rules[nodeID]
+	for i, rule := range rules {
+		rule = ruleconv.Restore(rule)
+		rule.Duration = s.daemonDuration(nodeID, rule.Duration)
+		rule.Operator = ruleset.NormalizeOperator(rule.Operator)
+		rules[i] = rule
+	}
+	return ruleset.WriteFiles(dir, rules)
+}
+
+// ImportRules implements controller.RuleImporter. The rules go out in a
+// single CHANGE_RULE, which the daemon applies to each rule in turn,
+// creating those it does not have: one notification per file would soon
+// fill the session's queue.
+func (s *Server) ImportRules(nodeID, path string) (controller.ImportResult, error) {
+	if _, ok := s.nodeByID(nodeID); !ok {
+		return controller.ImportResult{}, fmt.Errorf("node %s not found", nodeID)
+	}
+	files, skipped, err := ruleset.ReadFiles(path)
+	if err != nil {
+		return controller.ImportResult{}, err
+	}
+	result := controller.ImportResult{Skipped: skipped}
+	snapshot := s.store.Snapshot()
+	limits := ruleset.LimitsFor(snapshot.Settings)
+	seen := make(map[string]bool, len(files))
+	var rules []state.Rule
+	for _, f := range files {
+		rule := f.Rule(nodeID)
+		rule.Duration = ruleconv.NormalizeDuration(rule.Duration)
+		rule.Operator = ruleset.NormalizeOperator(rule.Operator)
+		if rule.CreatedAt.IsZero() {
+			rule.CreatedAt = s.now()
+		}
+		err := checkImported(rule)
+		if err == nil {
+			err = limits.Validate(rule)
+		}
+		switch {
+		case err != nil:
+		case seen[rule.Name]:
+			err = errors.New("already in an earlier file")
+		default:
+			if existing, ok := ruleset.Lookup(snapshot.Rules, nodeID, rule.Name); ok && existing.Cached {
+				err = errCachedRule(existing, nodeID)
+			}
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Errorf("%s: %w", rule.Name, err))
+			continue
+		}
+		seen[rule.Name] = true
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return result, nil
+	}
+	notif := s.newNotification(pb.Action_CHANGE_RULE, nodeID)
+	for _, rule := range rules {
+		notif.Rules = append(notif.Rules, s.serializeRuleFor(nodeID, rule))
+	}
+	if err := s.sendNotification(nodeID, notif); err != nil {
+		return result, err
+	}
+	for _, rule := range rules {
+		rule = ruleconv.Sanitize(rule)
+		if _, ok := ruleset.Lookup(snapshot.Rules, nodeID, rule.Name); ok {
+			s.store.UpdateRule(nodeID, rule.Name, func(r *state.Rule) { *r = rule })
+		} else {
+			s.store.AddRule(nodeID, rule)
+		}
+	}
+	s.rulesChanged(nodeID)
+	result.Imported = len(rules)
+	return result, nil
+}
+
+// checkImported rejects a rule file that decodes but that the daemon could
+// not apply: an unknown action or duration, or an operator it cannot
+// evaluate.
+func checkImported(rule state.Rule) error {
+	switch controller.PromptAction(rule.Action) {
+	case controller.PromptActionAllow, controller.PromptActionDeny, controller.PromptActionReject:
+	default:
+		return fmt.Errorf("unknown action %q", rule.Action)
+	}
+	if _, ok := ruleconv.CanonicalDuration(rule.Duration); !ok {
+		// Timed rules carry a Go duration such as "30m".
+		if d, err := time.ParseDuration(rule.Duration); err != nil || d <= 0 {
+			return fmt.Errorf("unknown duration %q", rule.Duration)
+		}
+	}
+	return ruleset.CheckOperator(rule.Operator)
+}
@@ -0,0 +1,116 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// firewallPause is a scheduled re-enable of a node's firewall. Pauses live
+// only in memory; Shutdown re-enables any that are still pending.
+type firewallPause struct {
+	timer *time.Timer
+	until time.Time
+}
+
+// EnableFirewall implements controller.FirewallManager. It also cancels a
+// pending timed pause on the node.
+func (s *Server) EnableFirewall(nodeID string) error {
+	s.cancelFirewallPause(nodeID)
+	return s.setFirewall(nodeID, true)
+}
+
+// DisableFirewall implements controller.FirewallManager. The firewall stays
+// off until enabled again; any pending timed pause is dropped.
+func (s *Server) DisableFirewall(nodeID string) error {
+	s.cancelFirewallPause(nodeID)
+	return s.setFirewall(nodeID, false)
+}
+
+// PauseFirewall implements controller.FirewallManager. It disables the
+// firewall now and re-enables it once d has passed, replacing any earlier
+// pause on the node.
+func (s *Server) PauseFirewall(nodeID string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("pause duration must be positive")
+	}
+	if err := s.setFirewall(nodeID, false); err != nil {
+		return err
+	}
+	pause := &firewallPause{until: s.now().Add(d)}
+	s.firewallMu.Lock()
+	if prev := s.firewallPauses[nodeID]; prev != nil {
+		prev.timer.Stop()
+	}
+	pause.timer = time.AfterFunc(d, func() { s.resumeFirewall(nodeID, pause) })
+	s.firewallPauses[nodeID] = pause
+	s.firewallMu.Unlock()
+
+	s.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallResumeAt = pause.until })
+	return nil
+}
+
+// resumeFirewall fires when a pause runs out, unless it was cancelled or
+// replaced in the meantime.
+func (s *Server) resumeFirewall(nodeID string, pause *firewallPause) {
+	s.firewallMu.Lock()
+	if s.firewallPauses[nodeID] != pause {
+		s.firewallMu.Unlock()
+		return
+	}
+	delete(s.firewallPauses, nodeID)
+	s.firewallMu.Unlock()
+
+	s.clearFirewallResume(nodeID)
+	if err := s.setFirewall(nodeID, true); err != nil {
+		s.store.ReportError(state.SubsystemDaemon, fmt.Sprintf("re-enable firewall on %s: %v", nodeID, err))
+	}
+}
+
+func (s *Server) cancelFirewallPause(nodeID string) {
+	s.firewallMu.Lock()
+	pause := s.firewallPauses[nodeID]
+	delete(s.firewallPauses, nodeID)
+	s.firewallMu.Unlock()
+	if pause == nil {
+		return
+	}
+	pause.timer.Stop()
+	s.clearFirewallResume(nodeID)
+}
+
+// resumePausedFirewalls re-enables every paused firewall so quitting the TUI
+// never leaves a node unprotected.
+func (s *Server) resumePausedFirewalls() {
+	s.firewallMu.Lock()
+	nodes := make([]string, 0, len(s.firewallPauses))
+	for nodeID, pause := range s.firewallPauses {
+		pause.timer.Stop()
+		nodes = append(nodes, nodeID)
+	}
+	clear(s.firewallPauses)
+	s.firewallMu.Unlock()
+
+	for _, nodeID := range nodes {
+		s.clearFirewallResume(nodeID)
+		_ = s.setFirewall(nodeID, true)
+	}
+}
+
+func (s *Server) clearFirewallResume(nodeID string) {
+	s.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallResumeAt = time.Time{} })
+}
+
+func (s *Server) setFirewall(nodeID string, enabled bool) error {
+	action := pb.Action_DISABLE_FIREWALL
+	if enabled {
+		action = pb.Action_ENABLE_FIREWALL
+	}
+	if err := s.sendNotification(nodeID, s.newNotification(action, nodeID)); err != nil {
+		return err
+	}
+	s.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallEnabled, n.FirewallKnown = enabled, true })
+	return nil
+}
@@ -0,0 +1,109 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func newFirewallServer(t *testing.T) (*Server, *state.Store, *session) {
+	t.Helper()
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", FirewallEnabled: true}})
+	srv := New(store, Options{})
+	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 4)}
+	srv.sessions["node-1"] = sess
+	return srv, store, sess
+}
+
+func expectNotification(t *testing.T, sess *session, want pb.Action) {
+	t.Helper()
+	select {
+	case notif := <-sess.send:
+		if notif.Type != want {
+			t.Fatalf("expected %v, got %v", want, notif.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %v", want)
+	}
+}
+
+func TestPauseFirewallReenablesWhenTimerFires(t *testing.T) {
+	srv, store, sess := newFirewallServer(t)
+	if err := srv.PauseFirewall("node-1", 20*time.Millisecond); err != nil {
+		t.Fatalf("PauseFirewall error: %v", err)
+	}
+	expectNotification(t, sess, pb.Action_DISABLE_FIREWALL)
+	node := store.Snapshot().Nodes[0]
+	if node.FirewallEnabled || node.FirewallResumeAt.IsZero() {
+		t.Fatalf("expected paused node, got %+v", node)
+	}
+
+	expectNotification(t, sess, pb.Action_ENABLE_FIREWALL)
+	node = store.Snapshot().Nodes[0]
+	if !node.FirewallEnabled || !node.FirewallResumeAt.IsZero() {
+		t.Fatalf("expected firewall back on with no pause, got %+v", node)
+	}
+}
+
+func TestEnableFirewallCancelsPause(t *testing.T) {
+	srv, store, sess := newFirewallServer(t)
+	if err := srv.PauseFirewall("node-1", 30*time.Millisecond); err != nil {
+		t.Fatalf("PauseFirewall error: %v", err)
+	}
+	expectNotification(t, sess, pb.Action_DISABLE_FIREWALL)
+	if err := srv.EnableFirewall("node-1"); err != nil {
+		t.Fatalf("EnableFirewall error: %v", err)
+	}
+	expectNotification(t, sess, pb.Action_ENABLE_FIREWALL)
+	if !store.Snapshot().Nodes[0].FirewallResumeAt.IsZero() {
+		t.Fatalf("expected pause to be cleared")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if len(sess.send) != 0 {
+		t.Fatalf("expected the cancelled timer not to fire")
+	}
+}
+
+func TestPauseFirewallRejectsBadInput(t *testing.T) {
+	srv, _, sess := newFirewallServer(t)
+	if err := srv.PauseFirewall("node-1", 0); err == nil {
+		t.Fatalf("expected an error for a zero duration")
+	}
+	if err := srv.PauseFirewall("node-2", time.Minute); err == nil {
+		t.Fatalf("expected an error for a disconnected node")
+	}
+	if len(sess.send) != 0 || len(srv.firewallPauses) != 0 {
+		t.Fatalf("expected nothing to be scheduled")
+	}
+}
+
+func TestShutdownReenablesPausedFirewalls(t *testing.T) {
+	srv, store, sess := newFirewallServer(t)
+	if err := srv.PauseFirewall("node-1", time.Hour); err != nil {
+		t.Fatalf("PauseFirewall error: %v", err)
+	}
+	expectNotification(t, sess, pb.Action_DISABLE_FIREWALL)
+
+	// Drain notifications the way the stream would during the flush.
+	got := make(chan pb.Action, 1)
+	go func() { got <- (<-sess.send).Type }()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	srv.Shutdown(ctx)
+	if action := <-got; action != pb.Action_ENABLE_FIREWALL {
+		t.Fatalf("expected enable firewall on shutdown, got %v", action)
+	}
+
+	node := store.Snapshot().Nodes[0]
+	if !node.FirewallEnabled || !node.FirewallResumeAt.IsZero() {
+		t.Fatalf("expected firewall re-enabled on shutdown, got %+v", node)
+	}
+	if len(srv.firewallPauses) != 0 {
+		t.Fatalf("expected no pending pauses after shutdown")
+	}
+}
@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"fmt"
+	"net"
 	"strings"
 )
 
@@ -24,3 +25,21 @@ func parseListenAddr(addr string) (listenTarget, error) {
 	}
 	return listenTarget{network: "tcp", address: value}, nil
 }
+
+// reachable reports whether other hosts can connect to target: a TCP
+// listener on a wildcard, a non-loopback address or a host name other than
+// localhost, which may resolve to anything.
+func (t listenTarget) reachable() bool {
+	if t.network != "tcp" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(t.address)
+	if err != nil {
+		host = t.address
+	}
+	if strings.EqualFold(host, "localhost") {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip == nil || !ip.IsLoopback()
+}
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// maintenanceWindow is the timer that ends a node's maintenance. The window
+// itself lives in the store, which AskRule and PostAlert consult.
+type maintenanceWindow struct {
+	timer *time.Timer
+}
+
+// StartMaintenance implements controller.MaintenanceManager. Prompts from
+// the node are answered with the maintenance action until d has passed,
+// and its alerts are kept but suppressed. An open window is replaced and
+// its counts start over.
+func (s *Server) StartMaintenance(nodeID string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("maintenance duration must be positive")
+	}
+	now := s.now()
+	action := config.NormalizeMaintenanceAction(s.store.Snapshot().Settings.MaintenanceAction)
+	window := &maintenanceWindow{}
+	s.maintenanceMu.Lock()
+	if prev := s.maintenance[nodeID]; prev != nil {
+		prev.timer.Stop()
+	}
+	window.timer = time.AfterFunc(d, func() { s.expireMaintenance(nodeID, window) })
+	s.maintenance[nodeID] = window
+	s.maintenanceMu.Unlock()
+
+	s.store.StartMaintenance(nodeID, action, now, now.Add(d))
+	return nil
+}
+
+// EndMaintenance implements controller.MaintenanceManager. It closes the
+// node's window early and raises its summary alert.
+func (s *Server) EndMaintenance(nodeID string) error {
+	s.maintenanceMu.Lock()
+	window := s.maintenance[nodeID]
+	delete(s.maintenance, nodeID)
+	s.maintenanceMu.Unlock()
+	if window == nil {
+		return fmt.Errorf("%s is not in maintenance", s.nodeName(nodeID))
+	}
+	window.timer.Stop()
+	s.finishMaintenance(nodeID)
+	return nil
+}
+
+// expireMaintenance fires when a window runs out, unless it was ended or
+// replaced in the meantime.
+func (s *Server) expireMaintenance(nodeID string, window *maintenanceWindow) {
+	s.maintenanceMu.Lock()
+	if s.maintenance[nodeID] != window {
+		s.maintenanceMu.Unlock()
+		return
+	}
+	delete(s.maintenance, nodeID)
+	s.maintenanceMu.Unlock()
+	s.finishMaintenance(nodeID)
+}
+
+// finishMaintenance removes the window from the store and reports what
+// happened during it in a single alert.
+func (s *Server) finishMaintenance(nodeID string) {
+	window, ok := s.store.EndMaintenance(nodeID)
+	if !ok {
+		return
+	}
+	now := s.now()
+	s.store.AddAlert(state.Alert{
+		ID:        fmt.Sprintf("maintenance-%d", now.UnixNano()),
+		NodeID:    nodeID,
+		Text:      window.Summary(s.nodeName(nodeID), now),
+		Priority:  "LOW",
+		Type:      "INFO",
+		Action:    "SHOW_ALERT",
+		CreatedAt: now,
+	})
+}
+
+// maintenanceDecision answers prompt with the maintenance defaults when its
+// node is in maintenance, counting it for the summary.
+func (s *Server) maintenanceDecision(prompt state.Prompt) (controller.PromptDecision, bool) {
+	process := cmp.Or(prompt.Connection.ProcessPath, "unknown process")
+	if !s.store.RecordMaintenanceAnswer(prompt.NodeID, process, prompt.RequestedAt) {
+		return controller.PromptDecision{}, false
+	}
+	settings := s.store.Snapshot().Settings
+	decision := s.defaultPromptDecision(prompt)
+	decision.Action = controller.PromptAction(config.NormalizeMaintenanceAction(settings.MaintenanceAction)).Normalize()
+	decision.Duration = controller.PromptDuration(config.NormalizePromptDuration(settings.MaintenanceDuration)).Normalize()
+	decision.Source = state.DecisionSourceMaintenance
+	return decision, true
+}
@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"google.golang.org/grpc/peer"
+)
+
+const maintenanceNode = "tcp://10.0.0.7:41000"
+
+func newMaintenanceServer(t *testing.T) (*Server, *state.Store, context.Context) {
+	t.Helper()
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: maintenanceNode, Name: "build-box"}})
+	settings := store.Snapshot().Settings
+	settings.PromptTimeout = 10 * time.Millisecond
+	store.SetSettings(settings)
+	srv := New(store, Options{})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "10.0.0.7:41000"}})
+	return srv, store, ctx
+}
+
+func TestMaintenanceAnswersPromptsAndSuppressesAlerts(t *testing.T) {
+	srv, store, ctx := newMaintenanceServer(t)
+	base := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	clock := base
+	srv.now = func() time.Time { return clock }
+	if err := srv.StartMaintenance(maintenanceNode, time.Hour); err != nil {
+		t.Fatalf("StartMaintenance error: %v", err)
+	}
+
+	for _, path := range []string{"/usr/bin/apt", "/usr/bin/apt", "/usr/bin/curl"} {
+		rule, err := srv.AskRule(ctx, &pb.Connection{ProcessPath: path, DstHost: "deb.debian.org", DstPort: 443})
+		if err != nil {
+			t.Fatalf("AskRule returned error: %v", err)
+		}
+		if rule.GetAction() != "allow" || rule.GetDuration() != "once" {
+			t.Fatalf("expected allow once during maintenance, got %s %s", rule.GetAction(), rule.GetDuration())
+		}
+	}
+	if _, err := srv.PostAlert(ctx, &pb.Alert{Id: 7, Data: &pb.Alert_Text{Text: "disk full"}}); err != nil {
+		t.Fatalf("PostAlert error: %v", err)
+	}
+	snap := store.Snapshot()
+	if len(snap.Prompts) != 0 {
+		t.Fatalf("expected no prompts queued during maintenance, got %+v", snap.Prompts)
+	}
+	if len(snap.Decisions) != 3 || snap.Decisions[0].Source != state.DecisionSourceMaintenance {
+		t.Fatalf("expected maintenance decisions, got %+v", snap.Decisions)
+	}
+	if len(snap.Alerts) != 1 || !snap.Alerts[0].Suppressed {
+		t.Fatalf("expected the alert kept and suppressed, got %+v", snap.Alerts)
+	}
+
+	clock = base.Add(10 * time.Minute)
+	if err := srv.EndMaintenance(maintenanceNode); err != nil {
+		t.Fatalf("EndMaintenance error: %v", err)
+	}
+	snap = store.Snapshot()
+	if _, ok := snap.Maintenance[maintenanceNode]; ok {
+		t.Fatalf("expected the window removed, got %+v", snap.Maintenance)
+	}
+	want := "Maintenance on build-box ended after 10m0s: auto-allowed 3 connections (/usr/bin/apt ×2, /usr/bin/curl ×1), 1 alert suppressed"
+	if len(snap.Alerts) != 2 || snap.Alerts[0].Text != want || snap.Alerts[0].Suppressed {
+		t.Fatalf("expected summary alert %q, got %+v", want, snap.Alerts)
+	}
+
+	if _, err := srv.PostAlert(ctx, &pb.Alert{Id: 8, Data: &pb.Alert_Text{Text: "disk full again"}}); err != nil {
+		t.Fatalf("PostAlert error: %v", err)
+	}
+	if alert := store.Snapshot().Alerts[0]; alert.Suppressed {
+		t.Fatalf("expected alerts after maintenance to show, got %+v", alert)
+	}
+	if err := srv.EndMaintenance(maintenanceNode); err == nil {
+		t.Fatalf("expected an error ending maintenance twice")
+	}
+}
+
+func TestMaintenanceUsesConfiguredAnswer(t *testing.T) {
+	srv, store, ctx := newMaintenanceServer(t)
+	settings := store.Snapshot().Settings
+	settings.MaintenanceAction = "deny"
+	settings.MaintenanceDuration = "until restart"
+	store.SetSettings(settings)
+	if err := srv.StartMaintenance(maintenanceNode, time.Hour); err != nil {
+		t.Fatalf("StartMaintenance error: %v", err)
+	}
+	rule, err := srv.AskRule(ctx, &pb.Connection{ProcessPath: "/usr/bin/curl", DstHost: "example.com", DstPort: 443})
+	if err != nil {
+		t.Fatalf("AskRule returned error: %v", err)
+	}
+	if rule.GetAction() != "deny" || rule.GetDuration() != "until restart" {
+		t.Fatalf("expected deny until restart, got %s %s", rule.GetAction(), rule.GetDuration())
+	}
+}
+
+func TestMaintenanceExpiresWithSummary(t *testing.T) {
+	srv, store, ctx := newMaintenanceServer(t)
+	if err := srv.StartMaintenance(maintenanceNode, 20*time.Millisecond); err != nil {
+		t.Fatalf("StartMaintenance error: %v", err)
+	}
+	if _, err := srv.PostAlert(ctx, &pb.Alert{Id: 1, Data: &pb.Alert_Text{Text: "noisy"}}); err != nil {
+		t.Fatalf("PostAlert error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(store.Snapshot().Alerts) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("maintenance never expired")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	snap := store.Snapshot()
+	if len(snap.Maintenance) != 0 {
+		t.Fatalf("expected the window removed, got %+v", snap.Maintenance)
+	}
+	if len(snap.Alerts) != 2 || !snap.Alerts[1].Suppressed {
+		t.Fatalf("expected the suppressed alert followed by one summary, got %+v", snap.Alerts)
+	}
+
+	// Prompts are interactive again and time out to the prompt defaults.
+	rule, err := srv.AskRule(ctx, &pb.Connection{ProcessPath: "/usr/bin/curl", DstHost: "example.com", DstPort: 443})
+	if err != nil {
+		t.Fatalf("AskRule returned error: %v", err)
+	}
+	if rule.GetAction() != "deny" || store.Snapshot().Decisions[0].Source != state.DecisionSourceTimeout {
+		t.Fatalf("expected a timed-out prompt after maintenance, got %s", rule.GetAction())
+	}
+}
+
+func TestStartMaintenanceReplacesWindow(t *testing.T) {
+	srv, store, _ := newMaintenanceServer(t)
+	if err := srv.StartMaintenance(maintenanceNode, 0); err == nil {
+		t.Fatalf("expected an error for a zero duration")
+	}
+	if err := srv.StartMaintenance(maintenanceNode, 20*time.Millisecond); err != nil {
+		t.Fatalf("StartMaintenance error: %v", err)
+	}
+	if err := srv.StartMaintenance(maintenanceNode, time.Hour); err != nil {
+		t.Fatalf("StartMaintenance error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	snap := store.Snapshot()
+	if _, ok := snap.MaintenanceFor(maintenanceNode, time.Now()); !ok || len(snap.Alerts) != 0 {
+		t.Fatalf("expected the replaced timer not to end the window, got %+v %+v", snap.Maintenance, snap.Alerts)
+	}
+}
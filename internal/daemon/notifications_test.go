@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// fakeNotificationStream is a notifications stream whose Recv blocks until a
+// reply error is queued or the stream context ends, like an idle daemon.
+type fakeNotificationStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sendErr error
+	recv    chan error
+}
+
+func newFakeNotificationStream(ctx context.Context, addr string) *fakeNotificationStream {
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &testAddr{network: "tcp", value: addr}})
+	return &fakeNotificationStream{ctx: ctx, recv: make(chan error, 1)}
+}
+
+func (f *fakeNotificationStream) Context() context.Context { return f.ctx }
+
+func (f *fakeNotificationStream) Send(*pb.Notification) error { return f.sendErr }
+
+func (f *fakeNotificationStream) Recv() (*pb.NotificationReply, error) {
+	select {
+	case err := <-f.recv:
+		return nil, err
+	case <-f.ctx.Done():
+		return nil, f.ctx.Err()
+	}
+}
+
+// serveNotifications runs the handler and waits for its session to exist.
+func serveNotifications(t *testing.T, srv *Server, stream *fakeNotificationStream, nodeID string) <-chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- srv.Notifications(stream) }()
+	deadline := time.Now().Add(time.Second)
+	for !srv.hasSession(nodeID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("session for %s never registered", nodeID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return done
+}
+
+func (s *Server) hasSession(nodeID string) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	_, ok := s.sessions[nodeID]
+	return ok
+}
+
+func waitHandler(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatalf("notifications handler did not return")
+		return nil
+	}
+}
+
+func TestNotificationsEndsWhenSendFailsWhileRecvIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := state.NewStore()
+	srv := New(store, Options{})
+	stream := newFakeNotificationStream(ctx, "10.0.0.2:50051")
+	stream.sendErr = errors.New("broken pipe")
+	nodeID := "tcp://10.0.0.2:50051"
+
+	done := serveNotifications(t, srv, stream, nodeID)
+	if err := srv.sendNotification(nodeID, &pb.Notification{Id: 1}); err != nil {
+		t.Fatalf("queue notification: %v", err)
+	}
+	if err := waitHandler(t, done); err == nil || err.Error() != "broken pipe" {
+		t.Fatalf("expected the send error, got %v", err)
+	}
+	if srv.hasSession(nodeID) {
+		t.Fatalf("expected the session to be unregistered")
+	}
+	if err := srv.sendNotification(nodeID, &pb.Notification{Id: 2}); err == nil {
+		t.Fatalf("expected no queueing on a dead session")
+	}
+	node := store.Snapshot().Nodes[0]
+	if node.Status != state.NodeStatusError || node.Message != "broken pipe" {
+		t.Fatalf("expected error status, got %+v", node)
+	}
+}
+
+func TestNotificationsEndsOnRecvEOF(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := state.NewStore()
+	srv := New(store, Options{})
+	stream := newFakeNotificationStream(ctx, "10.0.0.3:50051")
+	nodeID := "tcp://10.0.0.3:50051"
+
+	done := serveNotifications(t, srv, stream, nodeID)
+	stream.recv <- io.EOF
+	if err := waitHandler(t, done); err != nil {
+		t.Fatalf("expected a clean close, got %v", err)
+	}
+	if srv.hasSession(nodeID) {
+		t.Fatalf("expected the session to be unregistered")
+	}
+	if node := store.Snapshot().Nodes[0]; node.Status != state.NodeStatusDisconnected {
+		t.Fatalf("expected disconnected status, got %+v", node)
+	}
+}
+
+func TestNotificationsEndsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := state.NewStore()
+	srv := New(store, Options{})
+	stream := newFakeNotificationStream(ctx, "10.0.0.4:50051")
+	nodeID := "tcp://10.0.0.4:50051"
+
+	done := serveNotifications(t, srv, stream, nodeID)
+	cancel()
+	if err := waitHandler(t, done); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if srv.hasSession(nodeID) {
+		t.Fatalf("expected the session to be unregistered")
+	}
+	if node := store.Snapshot().Nodes[0]; node.Status != state.NodeStatusDisconnected {
+		t.Fatalf("expected disconnected status, got %+v", node)
+	}
+}
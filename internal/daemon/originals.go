@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/ruleconv"
+)
+
+// rememberRules keeps the rules nodeID's daemon subscribed with as it sent
+// them. A newer daemon may set fields these bindings do not model; edits
+// start from its copy so those fields go back unchanged.
+func (s *Server) rememberRules(nodeID string, rules []*pb.Rule) {
+	byName := make(map[string]*pb.Rule, len(rules))
+	for _, rule := range rules {
+		if rule != nil {
+			byName[rule.GetName()] = rule
+		}
+	}
+	s.originalsMu.Lock()
+	defer s.originalsMu.Unlock()
+	if len(byName) == 0 {
+		delete(s.originals, nodeID)
+		return
+	}
+	s.originals[nodeID] = byName
+}
+
+// forgetRule drops the daemon's copy of a deleted rule.
+func (s *Server) forgetRule(nodeID, name string) {
+	s.originalsMu.Lock()
+	defer s.originalsMu.Unlock()
+	delete(s.originals[nodeID], name)
+}
+
+func (s *Server) originalRule(nodeID, name string) *pb.Rule {
+	s.originalsMu.Lock()
+	defer s.originalsMu.Unlock()
+	return s.originals[nodeID][name]
+}
+
+// mergeRule applies to a copy of orig, the rule as the daemon sent it, the
+// fields in which edited differs from what the TUI made of orig. Fields the
+// TUI did not change keep the daemon's encoding, unknown fields included.
+func mergeRule(orig, edited *pb.Rule) *pb.Rule {
+	seen := ruleconv.ToProto(ruleconv.FromProto(orig, ""))
+	merged := proto.Clone(orig).(*pb.Rule)
+	if edited.GetName() != seen.GetName() {
+		merged.Name = edited.GetName()
+	}
+	if edited.GetDescription() != seen.GetDescription() {
+		merged.Description = edited.GetDescription()
+	}
+	if edited.GetEnabled() != seen.GetEnabled() {
+		merged.Enabled = edited.GetEnabled()
+	}
+	if edited.GetPrecedence() != seen.GetPrecedence() {
+		merged.Precedence = edited.GetPrecedence()
+	}
+	if edited.GetNolog() != seen.GetNolog() {
+		merged.Nolog = edited.GetNolog()
+	}
+	if edited.GetAction() != seen.GetAction() {
+		merged.Action = edited.GetAction()
+	}
+	if ruleconv.NormalizeDuration(edited.GetDuration()) != seen.GetDuration() {
+		merged.Duration = edited.GetDuration()
+	}
+	if edited.GetCreated() != seen.GetCreated() {
+		merged.Created = edited.GetCreated()
+	}
+	if !proto.Equal(edited.GetOperator(), seen.GetOperator()) {
+		merged.Operator = edited.GetOperator()
+	}
+	return merged
+}
@@ -0,0 +1,109 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// withUnknown adds a length-delimited field these bindings do not know,
+// as a newer daemon would send it.
+func withUnknown(m proto.Message, num protowire.Number, value string) {
+	raw := protowire.AppendTag(nil, num, protowire.BytesType)
+	raw = protowire.AppendString(raw, value)
+	msg := m.ProtoReflect()
+	msg.SetUnknown(append(msg.GetUnknown(), raw...))
+}
+
+// newerRule is a rule from a daemon whose proto has fields this build
+// lacks, decoded off the wire like Subscribe receives it.
+func newerRule(t *testing.T) *pb.Rule {
+	t.Helper()
+	rule := &pb.Rule{
+		Name: "ssh", Action: "allow", Duration: "always", Description: "ssh out",
+		Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+	}
+	withUnknown(rule, 90, "origin: fleet")
+	withUnknown(rule.Operator, 91, "match: fuzzy")
+	data, err := proto.Marshal(rule)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	decoded := &pb.Rule{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return decoded
+}
+
+func subscribeNewer(t *testing.T) (*Server, *state.Store, *pb.Rule, <-chan *pb.Notification) {
+	t.Helper()
+	store := state.NewStore()
+	srv := New(store, Options{})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:5000"}})
+	orig := newerRule(t)
+	if _, err := srv.Subscribe(ctx, &pb.ClientConfig{Name: "daemon", Rules: []*pb.Rule{orig}}); err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	_, queue := srv.registerSession("tcp://1.2.3.4:5000")
+	return srv, store, orig, queue
+}
+
+func TestEnableRuleKeepsUnknownFields(t *testing.T) {
+	srv, store, orig, queue := subscribeNewer(t)
+	rule := store.Snapshot().Rules["tcp://1.2.3.4:5000"][0]
+	if err := srv.EnableRule("tcp://1.2.3.4:5000", "ssh", ruleset.Hash(rule)); err != nil {
+		t.Fatalf("EnableRule error: %v", err)
+	}
+	sent := (<-queue).Rules[0]
+
+	want := proto.Clone(orig).(*pb.Rule)
+	want.Enabled = true
+	if !proto.Equal(sent, want) {
+		t.Fatalf("expected only Enabled to change, sent %v", sent)
+	}
+	if len(sent.ProtoReflect().GetUnknown()) == 0 || len(sent.GetOperator().ProtoReflect().GetUnknown()) == 0 {
+		t.Fatal("expected the unknown fields to reach the daemon")
+	}
+}
+
+func TestChangeRuleReplacesOnlyEditedFields(t *testing.T) {
+	srv, store, orig, queue := subscribeNewer(t)
+	rule := store.Snapshot().Rules["tcp://1.2.3.4:5000"][0]
+	hash := ruleset.Hash(rule)
+	rule.Operator.Data = "/usr/bin/scp"
+	if err := srv.ChangeRule("tcp://1.2.3.4:5000", rule, hash); err != nil {
+		t.Fatalf("ChangeRule error: %v", err)
+	}
+	sent := (<-queue).Rules[0]
+
+	if sent.GetOperator().GetData() != "/usr/bin/scp" {
+		t.Fatalf("expected the edited operator, got %v", sent.GetOperator())
+	}
+	want := proto.Clone(orig).(*pb.Rule)
+	want.Operator = sent.GetOperator()
+	if !proto.Equal(sent, want) {
+		t.Fatalf("expected the rule's other fields untouched, sent %v", sent)
+	}
+}
+
+func TestDeletedRuleForgetsDaemonCopy(t *testing.T) {
+	srv, store, _, queue := subscribeNewer(t)
+	rule := store.Snapshot().Rules["tcp://1.2.3.4:5000"][0]
+	if err := srv.DeleteRule("tcp://1.2.3.4:5000", "ssh", ruleset.Hash(rule)); err != nil {
+		t.Fatalf("DeleteRule error: %v", err)
+	}
+	if len((<-queue).Rules[0].ProtoReflect().GetUnknown()) == 0 {
+		t.Fatal("expected the delete to carry the daemon's copy")
+	}
+	if srv.originalRule("tcp://1.2.3.4:5000", "ssh") != nil {
+		t.Fatal("expected the daemon's copy to be dropped with the rule")
+	}
+}
@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/rulecache"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// errCachedRule explains why rules restored from the cache cannot be
+// changed: there is no daemon to send the change to yet.
+func errCachedRule(rule state.Rule, nodeID string) error {
+	return fmt.Errorf("rule %s is cached from a previous session; wait for %s to reconnect", rule.Name, nodeID)
+}
+
+// reconcileCachedRules replaces the cached rules of a node that just
+// subscribed, raising an alert when they differ from what it reports. The
+// placeholder node is dropped when the daemon came back under another
+// peer ID.
+func (s *Server) reconcileCachedRules(node state.Node, live []state.Rule) {
+	if s.opts.RuleCache == nil {
+		return
+	}
+	key := rulecache.Key(node)
+	snap := s.store.Snapshot()
+	for _, cachedNode := range snap.Nodes {
+		cached := snap.Rules[cachedNode.ID]
+		if rulecache.Key(cachedNode) != key || !anyCached(cached) {
+			continue
+		}
+		if cachedNode.ID != node.ID {
+			s.store.RemoveNode(cachedNode.ID)
+		}
+		changes := rulecache.Diff(cached, live)
+		if changes.Empty() {
+			continue
+		}
+		now := s.now()
+		s.store.AddAlert(state.Alert{
+			ID:        fmt.Sprintf("rulecache-%d", now.UnixNano()),
+			NodeID:    node.ID,
+			Text:      fmt.Sprintf("Rules on %s changed since they were cached: %s", node.Name, changes),
+			Priority:  "MEDIUM",
+			Type:      "WARNING",
+			Action:    "SHOW_ALERT",
+			CreatedAt: now,
+		})
+	}
+}
+
+func anyCached(rules []state.Rule) bool {
+	for _, rule := range rules {
+		if rule.Cached {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesChanged queues the files following the node's rules: the rule cache
+// and the rule timelines.
+func (s *Server) rulesChanged(nodeID string) {
+	s.cacheRules(nodeID)
+	s.saveHistory()
+}
+
+// cacheRules queues the node's current rules for the rule cache, if any.
+func (s *Server) cacheRules(nodeID string) {
+	if s.opts.RuleCache == nil {
+		return
+	}
+	snap := s.store.Snapshot()
+	for _, node := range snap.Nodes {
+		if node.ID != nodeID {
+			continue
+		}
+		if err := s.opts.Writer.Schedule(s.opts.RuleCache.Document(node, snap.Rules[nodeID])); err != nil {
+			s.store.ReportError(state.SubsystemDaemon, fmt.Sprintf("rule cache: %v", err))
+		}
+		return
+	}
+}
+
+// saveHistory queues the rule timelines for writing, if they are persisted.
+func (s *Server) saveHistory() {
+	if s.opts.History == nil {
+		return
+	}
+	if err := s.opts.Writer.Schedule(s.opts.History.Document(s.store.RuleHistoryEntries())); err != nil {
+		s.store.ReportError(state.SubsystemDaemon, fmt.Sprintf("rule history: %v", err))
+	}
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulecache"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestSubscribeReconcilesCachedRules(t *testing.T) {
+	store := state.NewStore()
+	cache := rulecache.New(t.TempDir())
+	rulecache.Restore(store, []rulecache.Entry{{
+		Key:    "name:laptop",
+		NodeID: "tcp://1.2.3.4:4000",
+		Name:   "laptop",
+		Rules: []state.Rule{{
+			Name:     "ssh",
+			Action:   "allow",
+			Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+		}},
+	}})
+	srv := New(store, Options{RuleCache: cache})
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:5000"}})
+	cfg := &pb.ClientConfig{
+		Name: "laptop",
+		Rules: []*pb.Rule{
+			{Name: "ssh", Action: "deny", Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"}},
+			{Name: "curl", Action: "allow", Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}},
+		},
+	}
+	if _, err := srv.Subscribe(ctx, cfg); err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+
+	snap := store.Snapshot()
+	if len(snap.Nodes) != 1 || snap.Nodes[0].ID != "tcp://1.2.3.4:5000" {
+		t.Fatalf("expected the cached placeholder replaced, got %+v", snap.Nodes)
+	}
+	if _, ok := snap.Rules["tcp://1.2.3.4:4000"]; ok {
+		t.Fatal("cached rules of the old peer kept")
+	}
+	live := snap.Rules["tcp://1.2.3.4:5000"]
+	if len(live) != 2 || anyCached(live) {
+		t.Fatalf("expected live rules, got %+v", live)
+	}
+	if len(snap.Alerts) != 1 || !strings.Contains(snap.Alerts[0].Text, "1 added, 1 changed") {
+		t.Fatalf("expected a diff alert, got %+v", snap.Alerts)
+	}
+
+	entries, err := cache.Load()
+	if err != nil || len(entries) != 1 || entries[0].NodeID != "tcp://1.2.3.4:5000" || len(entries[0].Rules) != 2 {
+		t.Fatalf("cache not refreshed: %+v, %v", entries, err)
+	}
+
+	// A second subscribe with nothing cached left raises no further alert.
+	if _, err := srv.Subscribe(ctx, cfg); err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	if got := len(store.Snapshot().Alerts); got != 1 {
+		t.Fatalf("expected no new alert, got %d", got)
+	}
+}
+
+func TestCachedRulesCannotBeChanged(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 1)}
+	srv.sessions["node-1"] = sess
+	rule := state.Rule{Name: "ssh", Cached: true, Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"}}
+	store.SetRules("node-1", []state.Rule{rule})
+
+	if err := srv.DisableRule("node-1", "ssh", ""); err == nil || !strings.Contains(err.Error(), "cached") {
+		t.Fatalf("expected cached rule error, got %v", err)
+	}
+	if err := srv.ChangeRule("node-1", rule, ""); err == nil || !strings.Contains(err.Error(), "cached") {
+		t.Fatalf("expected cached rule error, got %v", err)
+	}
+	if len(sess.send) != 0 {
+		t.Fatal("notification sent for a cached rule")
+	}
+}
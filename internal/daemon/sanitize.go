@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"reflect"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// Daemon strings reach the store sanitized, so no view can render a
+// crafted argv raw; rules get the same treatment in ruleconv. The unsanitized value rides along in Raw
+// and is restored whenever the value goes back to a daemon or into the wire
+// view: a rule for a path with a newline in it must still match that path.
+
+func sanitizeConnection(conn state.Connection) state.Connection {
+	raw := conn
+	conn.Protocol = util.Sanitize(conn.Protocol)
+	conn.SrcIP = util.Sanitize(conn.SrcIP)
+	conn.DstIP = util.Sanitize(conn.DstIP)
+	conn.DstHost = util.Sanitize(conn.DstHost)
+	conn.ProcessPath = util.Sanitize(conn.ProcessPath)
+	conn.ProcessCWD = util.Sanitize(conn.ProcessCWD)
+	conn.ProcessArgs = util.SanitizeAll(conn.ProcessArgs)
+	conn.ProcessChecksums = sanitizeChecksums(conn.ProcessChecksums)
+	if !reflect.DeepEqual(conn, raw) {
+		conn.Raw = &raw
+	}
+	return conn
+}
+
+// restoreConnection undoes sanitizeConnection for the fields that still
+// show what the daemon sent.
+func restoreConnection(conn state.Connection) state.Connection {
+	raw := conn.Raw
+	if raw == nil {
+		return conn
+	}
+	conn.Raw = nil
+	conn.Protocol = util.Unsanitize(conn.Protocol, raw.Protocol)
+	conn.SrcIP = util.Unsanitize(conn.SrcIP, raw.SrcIP)
+	conn.DstIP = util.Unsanitize(conn.DstIP, raw.DstIP)
+	conn.DstHost = util.Unsanitize(conn.DstHost, raw.DstHost)
+	conn.ProcessPath = util.Unsanitize(conn.ProcessPath, raw.ProcessPath)
+	conn.ProcessCWD = util.Unsanitize(conn.ProcessCWD, raw.ProcessCWD)
+	if len(conn.ProcessArgs) == len(raw.ProcessArgs) {
+		args := make([]string, len(conn.ProcessArgs))
+		for i, arg := range conn.ProcessArgs {
+			args[i] = util.Unsanitize(arg, raw.ProcessArgs[i])
+		}
+		conn.ProcessArgs = args
+	}
+	if reflect.DeepEqual(sanitizeChecksums(raw.ProcessChecksums), conn.ProcessChecksums) {
+		conn.ProcessChecksums = raw.ProcessChecksums
+	}
+	return conn
+}
+
+func sanitizeChecksums(checksums map[string]string) map[string]string {
+	if len(checksums) == 0 {
+		return checksums
+	}
+	out := make(map[string]string, len(checksums))
+	for key, value := range checksums {
+		out[util.Sanitize(key)] = util.Sanitize(value)
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+const evilPath = "/tmp/x\n\x1b[2J\x1b[31mALLOWED"
+
+func TestConvertConnectionSanitizes(t *testing.T) {
+	conn := convertConnection(&pb.Connection{
+		ProcessPath:      evilPath,
+		ProcessArgs:      []string{"x", "\x1b]0;pwned\x07"},
+		DstHost:          "example.com",
+		ProcessChecksums: map[string]string{"md5": "abc\x00"},
+	})
+	if conn.ProcessPath != "/tmp/x␊␛[2J␛[31mALLOWED" || conn.ProcessArgs[1] != "␛]0;pwned␇" || conn.ProcessChecksums["md5"] != "abc␀" {
+		t.Fatalf("expected sanitized fields, got %+v", conn)
+	}
+	if conn.Raw == nil || conn.Raw.ProcessPath != evilPath || conn.Raw.Raw != nil {
+		t.Fatalf("expected the original kept in Raw, got %+v", conn.Raw)
+	}
+	if clean := convertConnection(&pb.Connection{ProcessPath: "/usr/bin/curl"}); clean.Raw != nil {
+		t.Fatalf("expected no Raw copy for clean connections")
+	}
+
+	restored := serializeConnection(conn)
+	if restored.GetProcessPath() != evilPath || restored.GetProcessArgs()[1] != "\x1b]0;pwned\x07" || restored.GetProcessChecksums()["md5"] != "abc\x00" {
+		t.Fatalf("expected the original bytes on the wire, got %+v", restored)
+	}
+	srv := New(state.NewStore(), Options{})
+	if wire := srv.ConnectionWire(conn); strings.Contains(wire, "\x1b") || !strings.Contains(wire, `\n`) {
+		t.Fatalf("expected prototext to escape the original bytes, got %q", wire)
+	}
+}
+
+func TestConvertAlertAndStatsSanitize(t *testing.T) {
+	alert := convertAlert(&pb.Alert{Data: &pb.Alert_Text{Text: "boom\x1b[2J"}}, "node-1")
+	if alert.Text != "boom␛[2J" {
+		t.Fatalf("expected sanitized alert text, got %q", alert.Text)
+	}
+	stats := convertStats(&pb.Statistics{
+		DaemonVersion: "1.6\x1b[31m",
+		ByExecutable:  map[string]uint64{"/tmp/\x1b[2Jx": 3},
+	}, "node-1", "alpha")
+	if stats.DaemonVersion != "1.6␛[31m" || stats.TopExecutables[0].Label != "/tmp/␛[2Jx" {
+		t.Fatalf("expected sanitized stats, got %+v", stats)
+	}
+}
+
+func TestPromptRuleMatchesOriginalPath(t *testing.T) {
+	srv := New(state.NewStore(), Options{})
+	prompt := state.Prompt{ID: "p1", NodeID: "node-1", Connection: convertConnection(&pb.Connection{ProcessPath: evilPath})}
+	rule, err := srv.buildRuleFromDecision(prompt, controller.PromptDecision{
+		PromptID: "p1",
+		Action:   controller.PromptActionAllow,
+		Duration: controller.PromptDurationAlways,
+		Target:   controller.PromptTargetProcessPath,
+	})
+	if err != nil {
+		t.Fatalf("buildRuleFromDecision: %v", err)
+	}
+	if rule.GetOperator().GetData() != evilPath {
+		t.Fatalf("expected the rule to match the real path, got %q", rule.GetOperator().GetData())
+	}
+	if strings.ContainsAny(rule.GetName(), "\n\x1b") {
+		t.Fatalf("expected a printable rule name, got %q", rule.GetName())
+	}
+	if desc := rule.GetDescription(); !strings.HasPrefix(desc, "created from prompt: ") || strings.ContainsAny(desc, "\n\x1b") {
+		t.Fatalf("expected a printable description from the template, got %q", desc)
+	}
+}
+
+func TestPromptRuleDescription(t *testing.T) {
+	store := state.NewStore()
+	store.SetSettings(state.Settings{RuleDescriptionTemplate: "{{.Node}}: {{.Process}} to {{.Dest}}:{{.Port}} as {{.User}}"})
+	srv := New(store, Options{})
+	prompt := state.Prompt{ID: "p1", NodeID: "node-1", NodeName: "alpha", Connection: state.Connection{
+		ProcessPath: "/usr/bin/curl", DstIP: "10.0.0.9", DstPort: 443, UserID: 1000,
+	}}
+	decision := controller.PromptDecision{
+		PromptID: "p1",
+		Action:   controller.PromptActionAllow,
+		Duration: controller.PromptDurationAlways,
+		Target:   controller.PromptTargetProcessPath,
+	}
+	rule, err := srv.buildRuleFromDecision(prompt, decision)
+	if err != nil {
+		t.Fatalf("buildRuleFromDecision: %v", err)
+	}
+	if got := rule.GetDescription(); got != "alpha: /usr/bin/curl to 10.0.0.9:443 as 1000" {
+		t.Fatalf("unexpected templated description %q", got)
+	}
+
+	// A description edited on the prompt replaces the template's.
+	decision.Description = "  curl for the updater  "
+	if rule, err = srv.buildRuleFromDecision(prompt, decision); err != nil || rule.GetDescription() != "curl for the updater" {
+		t.Fatalf("expected the edited description, got %q (%v)", rule.GetDescription(), err)
+	}
+}
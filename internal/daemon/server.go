@@ -9,7 +9,6 @@ import (
 	"io"
 	"net"
 	"os"
-	"regexp"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,10 +19,20 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/peer"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/baseline"
+	"github.com/adamkadaban/opensnitch-tui/internal/blocklist"
 	"github.com/adamkadaban/opensnitch-tui/internal/controller"
 	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulecache"
+	"github.com/adamkadaban/opensnitch-tui/internal/ruleconv"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulehistory"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/skew"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/trash"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/version"
 )
 
 // Options configure the daemon RPC server.
@@ -33,6 +42,29 @@ type Options struct {
 	TLS           TLSOptions
 	ServerName    string
 	ServerVersion string
+	// RuleCache, when set, keeps each node's rules on disk and is
+	// reconciled with what daemons report on Subscribe.
+	RuleCache *rulecache.Cache
+	// Trash, when set, persists deleted rules so they can be restored in
+	// a later session.
+	Trash *trash.File
+	// Baselines, when set, persists the counter baselines marked per node.
+	Baselines *baseline.File
+	// History, when set, persists each rule's change timeline.
+	History *rulehistory.File
+	// Writer, when set, debounces the writes of the rule cache, trash,
+	// baselines and rule history; without it they are written as they
+	// change.
+	Writer *persist.Writer
+	// Blocklists, when set, are checked against the destination of every
+	// prompt; see blocklistMatch.
+	Blocklists *blocklist.Set
+	// InsecureListen accepts listening where other hosts can connect
+	// without TLS, instead of raising a security warning.
+	InsecureListen bool
+	// TraceProtocol starts with protocol tracing on; see
+	// SetProtocolTrace.
+	TraceProtocol bool
 }
 
 // TLSOptions describe optional TLS configuration for the RPC server.
@@ -46,15 +78,48 @@ type TLSOptions struct {
 type Server struct {
 	pb.UnimplementedUIServer
 
-	store *state.Store
-	opts  Options
-	grpc  *grpc.Server
+	store  *state.Store
+	opts   Options
+	grpc   *grpc.Server
+	grpcMu sync.Mutex
 
 	sessions    map[string]*session
 	sessionsMu  sync.Mutex
 	notifySeqID uint64
 	prompts     map[string]*promptRequest
 	promptsMu   sync.Mutex
+	// pendingOps holds notifications awaiting the daemon's reply, by ID.
+	pendingOps map[uint64]state.ActionAck
+	opsMu      sync.Mutex
+
+	now func() time.Time
+
+	shutdownOnce sync.Once
+	closing      atomic.Bool
+
+	skew   *skew.Estimator
+	skewMu sync.Mutex
+
+	firewallMu     sync.Mutex
+	firewallPauses map[string]*firewallPause
+
+	maintenanceMu sync.Mutex
+	maintenance   map[string]*maintenanceWindow
+
+	// dialects maps each node to how its daemon spells rule durations,
+	// canonical spelling to the daemon's.
+	dialects   map[string]map[string]string
+	dialectsMu sync.Mutex
+
+	// originals holds each node's rules as its daemon sent them, by name.
+	originals   map[string]map[string]*pb.Rule
+	originalsMu sync.Mutex
+
+	// traces holds each node's recent notification traffic while tracing
+	// is on.
+	tracing atomic.Bool
+	traces  map[string]*traceRing
+	traceMu sync.Mutex
 }
 
 type session struct {
@@ -78,16 +143,11 @@ type promptResponse struct {
 	err  error
 }
 
-const (
-	defaultPromptTimeout = 30 * time.Second
-	ruleTypeSimple       = "simple"
-)
+const defaultPromptTimeout = 30 * time.Second
 
 const (
 	operandProcessPath = "process.path"
 	operandProcessCmd  = "process.command"
-	operandProcessID   = "process.id"
-	operandUserID      = "user.id"
 	operandDestIP      = "dest.ip"
 	operandDestHost    = "dest.host"
 	operandDestPort    = "dest.port"
@@ -105,9 +165,11 @@ func New(store *state.Store, opts Options) *Server {
 		opts.ServerName = "opensnitch-tui"
 	}
 	if opts.ServerVersion == "" {
-		opts.ServerVersion = "dev"
+		opts.ServerVersion = version.Current().Version
 	}
-	return &Server{store: store, opts: opts, sessions: make(map[string]*session), prompts: make(map[string]*promptRequest)}
+	s := &Server{store: store, opts: opts, sessions: make(map[string]*session), prompts: make(map[string]*promptRequest), pendingOps: make(map[uint64]state.ActionAck), now: time.Now, skew: skew.New(skew.DefaultWindow), firewallPauses: make(map[string]*firewallPause), maintenance: make(map[string]*maintenanceWindow), dialects: make(map[string]map[string]string), originals: make(map[string]map[string]*pb.Rule), traces: make(map[string]*traceRing)}
+	s.tracing.Store(opts.TraceProtocol)
+	return s
 }
 
 // Start begins listening for daemon connections until the context is cancelled.
@@ -125,21 +187,44 @@ func (s *Server) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("listen on %s: %w", s.opts.ListenAddr, err)
 	}
+	// Daemons send no credentials, so without TLS anyone who can reach
+	// the port can read connections and rewrite rules.
+	if target.reachable() && s.opts.TLS.CertFile == "" && !s.opts.InsecureListen {
+		s.store.SetSecurityWarning(fmt.Sprintf("listening on %s without TLS — any host on the network can manage rules", s.opts.ListenAddr))
+	}
+	return s.Serve(ctx, lis)
+}
 
+// Serve accepts daemon connections on lis until the context is cancelled.
+// Start listens on the configured address; tests hand in their own
+// listener.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
 	serverOpts, err := s.serverOptions()
 	if err != nil {
 		return err
 	}
 
-	s.grpc = grpc.NewServer(serverOpts...)
-	pb.RegisterUIServer(s.grpc, s)
+	srv := grpc.NewServer(serverOpts...)
+	pb.RegisterUIServer(srv, s)
+	s.grpcMu.Lock()
+	if s.closing.Load() {
+		// Shutdown ran before there was a server to stop; it will not
+		// run again, so do not start one.
+		s.grpcMu.Unlock()
+		_ = lis.Close()
+		return nil
+	}
+	s.grpc = srv
+	s.grpcMu.Unlock()
 
 	go func() {
 		<-ctx.Done()
-		s.grpc.GracefulStop()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		s.Shutdown(shutdownCtx)
 	}()
 
-	if err := s.grpc.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+	if err := srv.Serve(lis); err != nil && err != grpc.ErrServerStopped {
 		return err
 	}
 	return nil
@@ -152,7 +237,12 @@ func (s *Server) Subscribe(ctx context.Context, cfg *pb.ClientConfig) (*pb.Clien
 	node.Status = state.NodeStatusReady
 	node.LastSeen = time.Now()
 	s.store.UpsertNode(node)
-	s.store.SetRules(node.ID, convertRules(cfg.GetRules(), node.ID))
+	s.learnDurationDialect(node.ID, cfg.GetRules())
+	s.rememberRules(node.ID, cfg.GetRules())
+	live := ruleconv.FromProtoList(cfg.GetRules(), node.ID)
+	s.reconcileCachedRules(node, live)
+	s.store.SetRules(node.ID, live)
+	s.rulesChanged(node.ID)
 
 	return &pb.ClientConfig{
 		Id:                cfg.GetId(),
@@ -169,64 +259,93 @@ func (s *Server) Subscribe(ctx context.Context, cfg *pb.ClientConfig) (*pb.Clien
 // Ping stores the latest daemon statistics for display.
 func (s *Server) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingReply, error) {
 	nodeID := peerKey(ctx)
-	now := time.Now()
-	s.store.UpdateNodeStatus(nodeID, state.NodeStatusReady, "last ping", now)
+	now := s.now()
+	s.store.RecordPing(nodeID, now)
 
 	nodeName := s.nodeName(nodeID)
 	stats := convertStats(req.GetStats(), nodeID, nodeName)
 	s.store.SetStats(stats)
+	s.rebaseOnRestart(nodeID)
+	events := convertEvents(req.GetStats().GetEvents(), nodeID, 0)
+	s.observeClockSkew(nodeID, events, now)
+	s.store.AppendEvents(nodeID, events)
 
 	return &pb.PingReply{Id: req.GetId()}, nil
 }
 
-// Notifications drains the streaming channel to keep the daemon connected.
+// Notifications keeps the daemon's notification stream open, sending queued
+// notifications and draining replies. Whichever side fails first ends the
+// session, so a dead stream cannot hold on to a session and its queue.
 func (s *Server) Notifications(stream pb.UI_NotificationsServer) error {
-	nodeID := peerKey(stream.Context())
-	sess := s.registerSession(nodeID)
+	ctx := stream.Context()
+	nodeID := peerKey(ctx)
+	sess, queue := s.registerSession(nodeID)
 	defer s.unregisterSession(nodeID, sess)
 
 	sendErr := make(chan error, 1)
-	go s.dispatchNotifications(stream, sess, sendErr)
+	go dispatchNotifications(stream, queue, sendErr)
+	recvErr := make(chan error, 1)
+	go s.drainReplies(stream, nodeID, recvErr)
 
-	for {
-		select {
-		case err := <-sendErr:
-			if err != nil {
-				s.store.UpdateNodeStatus(nodeID, state.NodeStatusError, err.Error(), time.Now())
-				return err
-			}
-			return nil
-		default:
+	select {
+	case err := <-sendErr:
+		if err != nil {
+			s.store.UpdateNodeStatus(nodeID, state.NodeStatusError, err.Error(), time.Now())
+			return err
 		}
-
-		reply, err := stream.Recv()
-		if err == io.EOF {
+		// The session was replaced by a newer stream from the same node.
+		return nil
+	case err := <-recvErr:
+		switch {
+		case err == io.EOF:
 			s.store.UpdateNodeStatus(nodeID, state.NodeStatusDisconnected, "notifications closed", time.Now())
 			return nil
+		case ctx.Err() != nil:
+			// Recv noticed the cancellation before the select did.
+			s.store.UpdateNodeStatus(nodeID, state.NodeStatusDisconnected, "notifications closed", time.Now())
+			return ctx.Err()
 		}
+		s.store.UpdateNodeStatus(nodeID, state.NodeStatusError, err.Error(), time.Now())
+		return err
+	case <-ctx.Done():
+		s.store.UpdateNodeStatus(nodeID, state.NodeStatusDisconnected, "notifications closed", time.Now())
+		return ctx.Err()
+	}
+}
+
+// drainReplies reads notification replies until the stream fails, timing
+// each against the notification it answers. Recv returns once the handler
+// exits, so the goroutine does not outlive the stream.
+func (s *Server) drainReplies(stream pb.UI_NotificationsServer, nodeID string, errCh chan<- error) {
+	for {
+		reply, err := stream.Recv()
 		if err != nil {
-			s.store.UpdateNodeStatus(nodeID, state.NodeStatusError, err.Error(), time.Now())
-			return err
+			errCh <- err
+			return
 		}
-		_ = reply
+		s.traceReply(nodeID, reply)
+		s.ackOp(nodeID, reply)
 	}
 }
 
-// PostAlert records alert text for the UI.
+// PostAlert records alert text for the UI. Alerts from a node in
+// maintenance are kept but marked suppressed.
 func (s *Server) PostAlert(ctx context.Context, alert *pb.Alert) (*pb.MsgResponse, error) {
 	if alert == nil {
 		return &pb.MsgResponse{}, nil
 	}
 	nodeID := peerKey(ctx)
 	converted := convertAlert(alert, nodeID)
-	s.store.AddAlert(converted)
+	if !s.store.AddSuppressedAlert(converted, s.now()) {
+		s.store.AddAlert(converted)
+	}
 	return &pb.MsgResponse{Id: alert.GetId()}, nil
 }
 
 func (s *Server) AskRule(ctx context.Context, conn *pb.Connection) (*pb.Rule, error) {
 	nodeID := peerKey(ctx)
 	nodeName := s.nodeName(nodeID)
-	now := time.Now()
+	now := s.now()
 	timeout := s.promptTimeout()
 	prompt := state.Prompt{
 		ID:          fmt.Sprintf("%s:%d", nodeID, now.UnixNano()),
@@ -236,6 +355,27 @@ func (s *Server) AskRule(ctx context.Context, conn *pb.Connection) (*pb.Rule, er
 		RequestedAt: now,
 		ExpiresAt:   now.Add(timeout),
 	}
+	prompt.Blocklist = s.blocklistMatch(prompt.Connection)
+	s.store.AddConnectionEvent(state.ConnectionEvent{At: now, NodeID: nodeID, Source: state.ConnectionFromPrompt, Connection: prompt.Connection})
+	if s.closing.Load() {
+		return s.applyDecision(prompt, s.shutdownDecision(prompt))
+	}
+	if prompt.Blocklist != nil && prompt.Blocklist.Strict {
+		return s.applyDecision(prompt, s.blocklistDecision(prompt))
+	}
+	if state.PromptModeFor(s.store.Snapshot().Nodes, nodeID) == state.PromptsPolicy {
+		decision := s.defaultPromptDecision(prompt)
+		decision.Source = state.DecisionSourcePolicy
+		return s.applyDecision(prompt, decision)
+	}
+	if decision, ok := s.maintenanceDecision(prompt); ok {
+		return s.applyDecision(prompt, decision)
+	}
+	if s.store.Snapshot().Settings.DNDActive(now) {
+		decision := s.defaultPromptDecision(prompt)
+		decision.Source = state.DecisionSourceDND
+		return s.applyDecision(prompt, decision)
+	}
 	req := &promptRequest{
 		id:       prompt.ID,
 		prompt:   prompt,
@@ -255,19 +395,15 @@ func (s *Server) AskRule(ctx context.Context, conn *pb.Connection) (*pb.Rule, er
 			return resp.rule, resp.err
 		case <-req.timerC:
 			s.store.RemovePrompt(req.id)
-			s.store.SetError(fmt.Sprintf("prompt timed out for %s", displayConnectionLabel(prompt.Connection)))
+			s.store.ReportError(state.SubsystemDaemon, fmt.Sprintf("prompt timed out for %s", displayConnectionLabel(prompt.Connection)))
 			decision := s.defaultPromptDecision(prompt)
-			rule, err := s.buildRuleFromDecision(prompt, decision)
-			if err == nil {
-				stateRule := convertRule(rule, prompt.NodeID)
-				s.store.AddRule(prompt.NodeID, stateRule)
-			}
-			return rule, err
+			decision.Source = state.DecisionSourceTimeout
+			return s.applyDecision(prompt, decision)
 		case <-req.pauseCh:
 			// wait for resume
 			<-req.resumeCh
 		case <-ctx.Done():
-			s.store.RemovePrompt(req.id)
+			s.withdrawPrompt(prompt)
 			return nil, ctx.Err()
 		}
 	}
@@ -320,7 +456,7 @@ func (s *Server) loadTLSCreds() (credentials.TransportCredentials, error) {
 
 func (s *Server) nodeFromContext(ctx context.Context, cfg *pb.ClientConfig) state.Node {
 	nodeID := peerKey(ctx)
-	name := cfg.GetName()
+	name := util.Sanitize(cfg.GetName())
 	if name == "" {
 		name = nodeID
 	}
@@ -328,8 +464,9 @@ func (s *Server) nodeFromContext(ctx context.Context, cfg *pb.ClientConfig) stat
 		ID:              nodeID,
 		Name:            name,
 		Address:         peerAddress(ctx),
-		Version:         cfg.GetVersion(),
+		Version:         util.Sanitize(cfg.GetVersion()),
 		FirewallEnabled: cfg.GetIsFirewallRunning(),
+		FirewallKnown:   true,
 		Status:          state.NodeStatusConnecting,
 		Message:         "connecting",
 		LastSeen:        time.Now(),
@@ -349,8 +486,8 @@ func (s *Server) nodeName(id string) string {
 	return id
 }
 
-func (s *Server) dispatchNotifications(stream pb.UI_NotificationsServer, sess *session, errCh chan<- error) {
-	for notif := range sess.send {
+func dispatchNotifications(stream pb.UI_NotificationsServer, queue <-chan *pb.Notification, errCh chan<- error) {
+	for notif := range queue {
 		if err := stream.Send(notif); err != nil {
 			errCh <- err
 			return
@@ -359,8 +496,11 @@ func (s *Server) dispatchNotifications(stream pb.UI_NotificationsServer, sess *s
 	errCh <- nil
 }
 
-func (s *Server) registerSession(nodeID string) *session {
-	sess := &session{nodeID: nodeID, send: make(chan *pb.Notification, 8)}
+// registerSession replaces any session of nodeID and returns the new one with
+// its queue; the queue is closed when the session ends.
+func (s *Server) registerSession(nodeID string) (*session, <-chan *pb.Notification) {
+	queue := make(chan *pb.Notification, 8)
+	sess := &session{nodeID: nodeID, send: queue}
 	s.sessionsMu.Lock()
 	if existing, ok := s.sessions[nodeID]; ok {
 		if existing.send != nil {
@@ -370,62 +510,105 @@ func (s *Server) registerSession(nodeID string) *session {
 	}
 	s.sessions[nodeID] = sess
 	s.sessionsMu.Unlock()
-	return sess
+	return sess, queue
 }
 
 func (s *Server) unregisterSession(nodeID string, sess *session) {
 	s.sessionsMu.Lock()
+	last := false
 	if current, ok := s.sessions[nodeID]; ok && current == sess {
 		delete(s.sessions, nodeID)
+		last = true
 	}
-	s.sessionsMu.Unlock()
 	if sess.send != nil {
 		close(sess.send)
 		sess.send = nil
 	}
+	s.sessionsMu.Unlock()
+	if last {
+		s.abandonOps(nodeID)
+	}
 }
 
-func (s *Server) EnableRule(nodeID, ruleName string) error {
-	return s.enqueueRuleAction(nodeID, ruleName, pb.Action_ENABLE_RULE, func(rule *state.Rule) {
+func (s *Server) EnableRule(nodeID, ruleName, hash string) error {
+	return s.enqueueRuleAction(nodeID, ruleName, hash, pb.Action_ENABLE_RULE, func(rule *state.Rule) {
 		rule.Enabled = true
 	})
 }
 
-func (s *Server) DisableRule(nodeID, ruleName string) error {
-	return s.enqueueRuleAction(nodeID, ruleName, pb.Action_DISABLE_RULE, func(rule *state.Rule) {
+func (s *Server) DisableRule(nodeID, ruleName, hash string) error {
+	return s.enqueueRuleAction(nodeID, ruleName, hash, pb.Action_DISABLE_RULE, func(rule *state.Rule) {
 		rule.Enabled = false
 	})
 }
 
-func (s *Server) DeleteRule(nodeID, ruleName string) error {
-	rule, err := s.lookupRule(nodeID, ruleName)
+func (s *Server) DeleteRule(nodeID, ruleName, hash string) error {
+	rule, err := s.lookupRule(nodeID, ruleName, hash)
 	if err != nil {
 		return err
 	}
+	untrash := s.trashRule(nodeID, rule)
 	notif := s.newNotification(pb.Action_DELETE_RULE, nodeID)
-	notif.Rules = []*pb.Rule{serializeRule(rule)}
+	notif.Rules = []*pb.Rule{s.serializeRuleFor(nodeID, rule)}
 	if err := s.sendNotification(nodeID, notif); err != nil {
+		untrash()
 		return err
 	}
 	s.store.RemoveRule(nodeID, ruleName)
+	s.forgetRule(nodeID, ruleName)
+	s.rulesChanged(nodeID)
 	return nil
 }
 
-func (s *Server) ChangeRule(nodeID string, rule state.Rule) error {
+func (s *Server) ChangeRule(nodeID string, rule state.Rule, hash string) error {
 	if rule.Name == "" {
 		return errors.New("rule name required")
 	}
+	if rule.Cached {
+		return errCachedRule(rule, nodeID)
+	}
+	if _, err := s.lookupRule(nodeID, rule.Name, hash); err != nil {
+		return err
+	}
+	if err := ruleset.LimitsFor(s.store.Snapshot().Settings).Validate(rule); err != nil {
+		return err
+	}
 	notif := s.newNotification(pb.Action_CHANGE_RULE, nodeID)
-	notif.Rules = []*pb.Rule{serializeRule(rule)}
+	notif.Rules = []*pb.Rule{s.serializeRuleFor(nodeID, rule)}
 	if err := s.sendNotification(nodeID, notif); err != nil {
 		return err
 	}
 	s.store.UpdateRule(nodeID, rule.Name, func(r *state.Rule) { *r = rule })
+	s.rulesChanged(nodeID)
 	return nil
 }
 
-func (s *Server) enqueueRuleAction(nodeID, ruleName string, action pb.Action, mutate func(*state.Rule)) error {
-	rule, err := s.lookupRule(nodeID, ruleName)
+func (s *Server) AddRule(nodeID string, rule state.Rule) error {
+	if rule.Name == "" {
+		return errors.New("rule name required")
+	}
+	if _, exists := ruleset.Lookup(s.store.Snapshot().Rules, nodeID, rule.Name); exists {
+		return fmt.Errorf("rule %s already exists on %s", rule.Name, nodeID)
+	}
+	if err := ruleset.LimitsFor(s.store.Snapshot().Settings).Validate(rule); err != nil {
+		return err
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = s.now()
+	}
+	// The daemon creates rules it does not know on CHANGE_RULE.
+	notif := s.newNotification(pb.Action_CHANGE_RULE, nodeID)
+	notif.Rules = []*pb.Rule{s.serializeRuleFor(nodeID, rule)}
+	if err := s.sendNotification(nodeID, notif); err != nil {
+		return err
+	}
+	s.store.AddRule(nodeID, rule)
+	s.rulesChanged(nodeID)
+	return nil
+}
+
+func (s *Server) enqueueRuleAction(nodeID, ruleName, hash string, action pb.Action, mutate func(*state.Rule)) error {
+	rule, err := s.lookupRule(nodeID, ruleName, hash)
 	if err != nil {
 		return err
 	}
@@ -433,12 +616,13 @@ func (s *Server) enqueueRuleAction(nodeID, ruleName string, action pb.Action, mu
 		mutate(&rule)
 	}
 	notif := s.newNotification(action, nodeID)
-	notif.Rules = []*pb.Rule{serializeRule(rule)}
+	notif.Rules = []*pb.Rule{s.serializeRuleFor(nodeID, rule)}
 	if err := s.sendNotification(nodeID, notif); err != nil {
 		return err
 	}
 	if mutate != nil {
 		s.store.UpdateRule(nodeID, ruleName, mutate)
+		s.rulesChanged(nodeID)
 	}
 	return nil
 }
@@ -454,24 +638,37 @@ func (s *Server) newNotification(action pb.Action, nodeID string) *pb.Notificati
 }
 
 func (s *Server) sendNotification(nodeID string, notif *pb.Notification) error {
+	// Hold the lock while queueing so the session cannot be closed under us.
 	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
 	sess, ok := s.sessions[nodeID]
-	s.sessionsMu.Unlock()
-	if !ok {
+	if !ok || sess.send == nil {
 		return fmt.Errorf("node %s not connected", nodeID)
 	}
+	// Track before queueing: the reply may arrive before this returns.
+	s.trackOp(nodeID, notif)
 	select {
 	case sess.send <- notif:
+		s.traceNotification(nodeID, notif)
 		return nil
 	default:
+		s.untrackOp(notif.GetId())
 		return fmt.Errorf("notification buffer full for %s", nodeID)
 	}
 }
 
-func (s *Server) lookupRule(nodeID, ruleName string) (state.Rule, error) {
+// lookupRule returns the current rule named ruleName on nodeID, provided it
+// still matches hash, the rule the caller displayed.
+func (s *Server) lookupRule(nodeID, ruleName, hash string) (state.Rule, error) {
 	snapshot := s.store.Snapshot()
 	for _, rule := range snapshot.Rules[nodeID] {
 		if rule.Name == ruleName {
+			if rule.Cached {
+				return state.Rule{}, errCachedRule(rule, nodeID)
+			}
+			if hash != "" && ruleset.Hash(rule) != hash {
+				return state.Rule{}, &controller.RuleConflictError{NodeID: nodeID, Rule: ruleName}
+			}
 			return rule, nil
 		}
 	}
@@ -491,10 +688,9 @@ func (s *Server) ResolvePrompt(decision controller.PromptDecision) error {
 	if err != nil {
 		return err
 	}
-	stateRule := convertRule(rule, req.prompt.NodeID)
-	s.store.AddRule(req.prompt.NodeID, stateRule)
 	select {
 	case req.response <- promptResponse{rule: rule}:
+		s.recordDecision(req.prompt, decision, rule)
 		s.store.RemovePrompt(decision.PromptID)
 		return nil
 	default:
@@ -502,6 +698,67 @@ func (s *Server) ResolvePrompt(decision controller.PromptDecision) error {
 	}
 }
 
+// applyDecision builds the rule for a decision made without the user and
+// records it, returning what AskRule should answer with.
+func (s *Server) applyDecision(prompt state.Prompt, decision controller.PromptDecision) (*pb.Rule, error) {
+	rule, err := s.buildRuleFromDecision(prompt, decision)
+	if err != nil {
+		return nil, err
+	}
+	s.recordDecision(prompt, decision, rule)
+	return rule, nil
+}
+
+// withdrawPrompt drops a prompt the daemon stopped waiting for, because it
+// timed out on its side or the connection went away, and records it in the
+// prompt history so the overlay can explain why it vanished.
+func (s *Server) withdrawPrompt(prompt state.Prompt) {
+	now := s.now()
+	s.store.AddDecision(state.Decision{
+		PromptID:   prompt.ID,
+		NodeID:     prompt.NodeID,
+		NodeName:   prompt.NodeName,
+		Connection: prompt.Connection,
+		Source:     state.DecisionSourceWithdrawn,
+		PromptedAt: prompt.RequestedAt,
+		ResolvedAt: now,
+		Outcome:    state.OutcomeWithdrawn,
+		OutcomeAt:  now,
+	})
+	s.store.RemovePrompt(prompt.ID)
+	node := prompt.NodeName
+	if node == "" {
+		node = prompt.NodeID
+	}
+	s.store.AppendLog(state.LogEntry{
+		Severity:  state.LogWarning,
+		Subsystem: state.SubsystemDaemon,
+		Text:      fmt.Sprintf("prompt for %s withdrawn: %s stopped waiting", displayConnectionLabel(prompt.Connection), node),
+	})
+}
+
+// recordDecision adds the generated rule to the store and logs the decision.
+func (s *Server) recordDecision(prompt state.Prompt, decision controller.PromptDecision, rule *pb.Rule) {
+	s.store.AddRuleFrom(prompt.NodeID, ruleconv.FromProto(rule, prompt.NodeID), state.RuleChangePrompt)
+	s.rulesChanged(prompt.NodeID)
+	source := decision.Source
+	if source == "" {
+		source = state.DecisionSourceUser
+	}
+	s.store.AddDecision(state.Decision{
+		PromptID:   prompt.ID,
+		NodeID:     prompt.NodeID,
+		NodeName:   prompt.NodeName,
+		Connection: prompt.Connection,
+		Action:     rule.GetAction(),
+		Duration:   ruleconv.NormalizeDuration(rule.GetDuration()),
+		RuleName:   util.Sanitize(rule.GetName()),
+		Source:     source,
+		PromptedAt: prompt.RequestedAt,
+		ResolvedAt: s.now(),
+	})
+}
+
 func (s *Server) registerPrompt(req *promptRequest) {
 	s.promptsMu.Lock()
 	s.prompts[req.id] = req
@@ -591,7 +848,6 @@ func (s *Server) defaultPromptDecision(prompt state.Prompt) controller.PromptDec
 		PromptID: prompt.ID,
 		Action:   controller.PromptActionDeny,
 		Duration: controller.PromptDurationOnce,
-		Target:   bestAvailableTarget(prompt.Connection),
 	}
 	settings := s.store.Snapshot().Settings
 	if settings.DefaultPromptAction != "" {
@@ -600,68 +856,84 @@ func (s *Server) defaultPromptDecision(prompt state.Prompt) controller.PromptDec
 	if settings.DefaultPromptDuration != "" {
 		decision.Duration = controller.PromptDuration(settings.DefaultPromptDuration)
 	}
-	if preferred := controller.PromptTarget(settings.DefaultPromptTarget); preferred != "" && targetAvailable(prompt.Connection, preferred) {
-		decision.Target = preferred
+	decision.Target = controller.DefaultTarget(prompt.Connection, controller.PromptTarget(settings.DefaultPromptTarget), settings.UIDZeroUnknown)
+	// A blocklisted destination is denied whatever the defaults say.
+	if prompt.Blocklist != nil {
+		decision.Action = controller.PromptActionDeny
+		decision.Target = blocklistTarget(prompt, settings.UIDZeroUnknown)
 	}
-	decision.Action = normalizePromptAction(decision.Action)
-	decision.Duration = normalizePromptDuration(decision.Duration)
+	decision.Action = decision.Action.Normalize()
+	decision.Duration = decision.Duration.Normalize()
 	return decision
 }
 
+// buildRuleFromDecision builds the rule a decision creates, whether the
+// user made it or it was made for them, and checks it against the
+// configured rule limits.
 func (s *Server) buildRuleFromDecision(prompt state.Prompt, decision controller.PromptDecision) (*pb.Rule, error) {
-	decision.Action = normalizePromptAction(decision.Action)
-	decision.Duration = normalizePromptDuration(decision.Duration)
-	if decision.Target == "" {
-		decision.Target = bestAvailableTarget(prompt.Connection)
-	}
-	operator, err := operatorForTarget(prompt.Connection, decision.Target)
+	settings := s.store.Snapshot().Settings
+	// The operator must match the connection as the daemon sees it.
+	decision, resolved, err := decision.Resolve(restoreConnection(prompt.Connection), settings.UIDZeroUnknown)
 	if err != nil {
 		return nil, err
 	}
+	operator := ruleconv.OperatorToProto(resolved)
 	name := generateRuleName(prompt, operator, decision.Action, decision.Duration, decision.Target, s.store)
-	return &pb.Rule{
-		Created:  time.Now().Unix(),
-		Name:     name,
-		Enabled:  true,
-		Action:   string(decision.Action),
-		Duration: string(decision.Duration),
-		Operator: operator,
-	}, nil
+	now := time.Now()
+	rule := &pb.Rule{
+		Created:     now.Unix(),
+		Name:        name,
+		Description: s.ruleDescription(prompt, decision, now),
+		Enabled:     true,
+		Action:      string(decision.Action),
+		Duration:    s.daemonDuration(prompt.NodeID, string(decision.Duration)),
+		Operator:    operator,
+	}
+	if err := ruleset.LimitsFor(settings).Validate(ruleconv.FromProto(rule, prompt.NodeID)); err != nil {
+		return nil, err
+	}
+	return rule, nil
 }
 
-var nonSlugChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+// ruleDescription is the description the user settled on for the decision,
+// or the configured template rendered for prompt.
+func (s *Server) ruleDescription(prompt state.Prompt, decision controller.PromptDecision, at time.Time) string {
+	if description := strings.TrimSpace(decision.Description); description != "" {
+		return description
+	}
+	settings := s.store.Snapshot().Settings
+	fields := ruleset.DescriptionFieldsFor(prompt, settings.UIDZeroUnknown, at)
+	return ruleset.RenderDescription(settings.RuleDescriptionTemplate, fields, ruleset.LimitsFor(settings).Text)
+}
 
 func generateRuleName(prompt state.Prompt, op *pb.Operator, action controller.PromptAction, duration controller.PromptDuration, target controller.PromptTarget, store *state.Store) string {
-	parts := []string{}
-	if s := slugify(string(action)); s != "" {
-		parts = append(parts, s)
-	}
-	if s := slugify(string(duration)); s != "" {
-		parts = append(parts, s)
+	parts := ruleset.NameParts{
+		Action:   string(action),
+		Duration: string(duration),
+		Target:   string(target),
+		Data:     util.Sanitize(operandData(op, prompt.Connection, target)),
 	}
 	if op != nil {
-		if t := slugify(op.Type); t != "" {
-			parts = append(parts, t)
-		}
-		if operand := operandSlug(op, prompt.Connection, target); operand != "" {
-			parts = append(parts, operand)
+		parts.Type = op.Type
+	}
+	maxLen := ruleset.DefaultMaxText
+	var nameTemplate string
+	var existing []string
+	if store != nil {
+		snap := store.Snapshot()
+		maxLen = ruleset.LimitsFor(snap.Settings).Text
+		nameTemplate = snap.Settings.RuleNameTemplate
+		for _, r := range snap.Rules[prompt.NodeID] {
+			existing = append(existing, r.Name)
 		}
-	} else {
-		if operand := operandSlug(nil, prompt.Connection, target); operand != "" {
-			parts = append(parts, operand)
-		}
-	}
-	if len(parts) == 0 {
-		parts = append(parts, "rule")
 	}
-	base := strings.Join(parts, "-")
-	return ensureUniqueRuleName(base, prompt.NodeID, store)
+	return ruleset.GenerateName(nameTemplate, parts, existing, maxLen)
 }
 
-func operandSlug(op *pb.Operator, conn state.Connection, target controller.PromptTarget) string {
+func operandData(op *pb.Operator, conn state.Connection, target controller.PromptTarget) string {
 	if op != nil {
 		if op.Data != "" {
-			return slugify(op.Data)
+			return op.Data
 		}
 		if len(op.List) > 0 {
 			return "list"
@@ -669,15 +941,15 @@ func operandSlug(op *pb.Operator, conn state.Connection, target controller.Promp
 		switch op.Operand {
 		case operandProcessPath:
 			if conn.ProcessPath != "" {
-				return slugify(conn.ProcessPath)
+				return conn.ProcessPath
 			}
 		case operandProcessCmd:
 			cmdLine := strings.TrimSpace(strings.Join(conn.ProcessArgs, " "))
 			if cmdLine != "" {
-				return slugify(cmdLine)
+				return cmdLine
 			}
 			if conn.ProcessPath != "" {
-				return slugify(conn.ProcessPath)
+				return conn.ProcessPath
 			}
 		case operandDestHost:
 			if conn.DstHost != "" {
@@ -685,7 +957,7 @@ func operandSlug(op *pb.Operator, conn state.Connection, target controller.Promp
 				if conn.DstPort != 0 {
 					host = fmt.Sprintf("%s-%d", host, conn.DstPort)
 				}
-				return slugify(host)
+				return host
 			}
 		case operandDestIP:
 			if conn.DstIP != "" {
@@ -693,124 +965,59 @@ func operandSlug(op *pb.Operator, conn state.Connection, target controller.Promp
 				if conn.DstPort != 0 {
 					ip = fmt.Sprintf("%s-%d", ip, conn.DstPort)
 				}
-				return slugify(ip)
+				return ip
 			}
 		case operandDestPort:
 			if conn.DstPort != 0 {
-				return slugify(fmt.Sprintf("%d", conn.DstPort))
+				return fmt.Sprintf("%d", conn.DstPort)
 			}
 		}
 	}
 	switch target {
 	case controller.PromptTargetProcessPath:
-		return slugify(conn.ProcessPath)
+		return conn.ProcessPath
 	case controller.PromptTargetProcessCmd:
 		cmdLine := strings.TrimSpace(strings.Join(conn.ProcessArgs, " "))
 		if cmdLine != "" {
-			return slugify(cmdLine)
+			return cmdLine
 		}
-		return slugify(conn.ProcessPath)
+		return conn.ProcessPath
 	case controller.PromptTargetDestinationHost:
 		host := conn.DstHost
 		if conn.DstPort != 0 {
 			host = fmt.Sprintf("%s-%d", host, conn.DstPort)
 		}
-		return slugify(host)
+		return host
 	case controller.PromptTargetDestinationIP:
 		ip := conn.DstIP
 		if conn.DstPort != 0 {
 			ip = fmt.Sprintf("%s-%d", ip, conn.DstPort)
 		}
-		return slugify(ip)
+		return ip
 	case controller.PromptTargetDestinationPort:
 		if conn.DstPort != 0 {
-			return slugify(fmt.Sprintf("%d", conn.DstPort))
+			return fmt.Sprintf("%d", conn.DstPort)
 		}
 	case controller.PromptTargetUserID:
 		if conn.UserID != 0 {
-			return slugify(fmt.Sprintf("uid-%d", conn.UserID))
+			return fmt.Sprintf("uid-%d", conn.UserID)
 		}
 	case controller.PromptTargetProcessID:
 		if conn.ProcessID != 0 {
-			return slugify(fmt.Sprintf("pid-%d", conn.ProcessID))
+			return fmt.Sprintf("pid-%d", conn.ProcessID)
 		}
 	}
 	return ""
 }
 
-func slugify(s string) string {
-	s = strings.TrimSpace(strings.ToLower(s))
-	s = nonSlugChars.ReplaceAllString(s, "-")
-	s = strings.Trim(s, "-._")
-	return s
-}
-
-func ensureUniqueRuleName(base string, nodeID string, store *state.Store) string {
-	if store == nil {
-		return base
-	}
-	existing := map[string]struct{}{}
-	for _, r := range store.Snapshot().Rules[nodeID] {
-		existing[r.Name] = struct{}{}
-	}
-	if _, ok := existing[base]; !ok {
-		return base
-	}
-	for i := 1; i < 1000; i++ {
-		candidate := fmt.Sprintf("%s-%d", base, i)
-		if _, ok := existing[candidate]; !ok {
-			return candidate
-		}
-	}
-	return fmt.Sprintf("%s-%d", base, time.Now().UnixNano())
-}
-
-func operatorForTarget(conn state.Connection, target controller.PromptTarget) (*pb.Operator, error) {
-	switch target {
-	case controller.PromptTargetProcessPath:
-		if conn.ProcessPath == "" {
-			return nil, fmt.Errorf("process path unavailable")
-		}
-		return simpleOperator(operandProcessPath, conn.ProcessPath), nil
-	case controller.PromptTargetProcessCmd:
-		cmdLine := strings.TrimSpace(strings.Join(conn.ProcessArgs, " "))
-		if cmdLine == "" {
-			if conn.ProcessPath == "" {
-				return nil, fmt.Errorf("command line unavailable")
-			}
-			return simpleOperator(operandProcessPath, conn.ProcessPath), nil
-		}
-		return simpleOperator(operandProcessCmd, cmdLine), nil
-	case controller.PromptTargetProcessID:
-		return simpleOperator(operandProcessID, fmt.Sprintf("%d", conn.ProcessID)), nil
-	case controller.PromptTargetUserID:
-		return simpleOperator(operandUserID, fmt.Sprintf("%d", conn.UserID)), nil
-	case controller.PromptTargetDestinationIP:
-		if conn.DstIP == "" {
-			return nil, fmt.Errorf("destination ip unavailable")
-		}
-		return simpleOperator(operandDestIP, conn.DstIP), nil
-	case controller.PromptTargetDestinationHost:
-		if conn.DstHost == "" {
-			return nil, fmt.Errorf("destination host unavailable")
-		}
-		return simpleOperator(operandDestHost, conn.DstHost), nil
-	case controller.PromptTargetDestinationPort:
-		if conn.DstPort == 0 {
-			return nil, fmt.Errorf("destination port unavailable")
-		}
-		return simpleOperator(operandDestPort, fmt.Sprintf("%d", conn.DstPort)), nil
-	default:
-		return nil, fmt.Errorf("unsupported target %s", target)
-	}
-}
-
-func simpleOperator(operand, data string) *pb.Operator {
-	return &pb.Operator{
-		Type:    ruleTypeSimple,
-		Operand: operand,
-		Data:    data,
+// operatorForTarget builds the operator matching conn on target; see
+// controller.PromptTarget.Operator.
+func operatorForTarget(conn state.Connection, target controller.PromptTarget, uidZeroUnknown bool) (*pb.Operator, error) {
+	op, err := target.Operator(conn, uidZeroUnknown)
+	if err != nil {
+		return nil, err
 	}
+	return ruleconv.OperatorToProto(op), nil
 }
 
 func displayConnectionLabel(conn state.Connection) string {
@@ -821,60 +1028,6 @@ func displayConnectionLabel(conn state.Connection) string {
 	return fmt.Sprintf("%s -> %s:%d", util.Fallback(conn.ProcessPath, "unknown"), util.Fallback(dest, "destination"), conn.DstPort)
 }
 
-func normalizePromptAction(action controller.PromptAction) controller.PromptAction {
-	switch action {
-	case controller.PromptActionAllow, controller.PromptActionDeny, controller.PromptActionReject:
-		return action
-	default:
-		return controller.PromptActionDeny
-	}
-}
-
-func normalizePromptDuration(duration controller.PromptDuration) controller.PromptDuration {
-	switch duration {
-	case controller.PromptDurationOnce, controller.PromptDurationUntilRestart, controller.PromptDurationAlways:
-		return duration
-	default:
-		return controller.PromptDurationOnce
-	}
-}
-
-func targetAvailable(conn state.Connection, target controller.PromptTarget) bool {
-	switch target {
-	case controller.PromptTargetProcessPath:
-		return conn.ProcessPath != ""
-	case controller.PromptTargetProcessCmd:
-		return len(conn.ProcessArgs) > 0 || conn.ProcessPath != ""
-	case controller.PromptTargetDestinationHost:
-		return conn.DstHost != ""
-	case controller.PromptTargetDestinationIP:
-		return conn.DstIP != ""
-	case controller.PromptTargetDestinationPort:
-		return conn.DstPort != 0
-	case controller.PromptTargetProcessID, controller.PromptTargetUserID:
-		return true
-	default:
-		return false
-	}
-}
-
-func bestAvailableTarget(conn state.Connection) controller.PromptTarget {
-	switch {
-	case conn.ProcessPath != "":
-		return controller.PromptTargetProcessPath
-	case len(conn.ProcessArgs) > 0:
-		return controller.PromptTargetProcessCmd
-	case conn.DstHost != "":
-		return controller.PromptTargetDestinationHost
-	case conn.DstIP != "":
-		return controller.PromptTargetDestinationIP
-	case conn.DstPort != 0:
-		return controller.PromptTargetDestinationPort
-	default:
-		return controller.PromptTargetProcessID
-	}
-}
-
 func peerKey(ctx context.Context) string {
 	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
 		return fmt.Sprintf("%s://%s", p.Addr.Network(), p.Addr.String())
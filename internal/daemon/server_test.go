@@ -2,11 +2,18 @@ package daemon
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/controller"
 	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulehistory"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"google.golang.org/grpc/peer"
 )
@@ -41,6 +48,58 @@ func TestParseListenAddr(t *testing.T) {
 	}
 }
 
+func TestListenTargetReachable(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"127.0.0.1:50051", false},
+		{"127.0.1.1:50051", false},
+		{"[::1]:50051", false},
+		{"localhost:50051", false},
+		{"unix:///tmp/osui.sock", false},
+		{"0.0.0.0:50051", true},
+		{":50051", true},
+		{"[::]:50051", true},
+		{"192.168.1.20:50051", true},
+		{"[fe80::1]:50051", true},
+		{"snitch.lan:50051", true},
+	}
+	for _, tt := range tests {
+		target, err := parseListenAddr(tt.input)
+		if err != nil {
+			t.Fatalf("parseListenAddr(%q): %v", tt.input, err)
+		}
+		if got := target.reachable(); got != tt.want {
+			t.Errorf("reachable(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestServerStartWarnsAboutReachableListenerWithoutTLS(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{ListenAddr: "0.0.0.0:0"})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for store.Snapshot().SecurityWarning == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	snapshot := store.Snapshot()
+	if want := "listening on 0.0.0.0:0 without TLS — any host on the network can manage rules"; snapshot.SecurityWarning != want {
+		t.Fatalf("expected the security warning, got %q", snapshot.SecurityWarning)
+	}
+	if len(snapshot.Log) == 0 || snapshot.Log[0].Severity != state.LogError {
+		t.Fatalf("expected the warning logged as an error, got %+v", snapshot.Log)
+	}
+}
+
 func TestServerPostAlertStoresAlert(t *testing.T) {
 	store := state.NewStore()
 	srv := New(store, Options{})
@@ -94,16 +153,63 @@ func TestServerSubscribeStoresRules(t *testing.T) {
 	}
 }
 
+func TestServerSubscribeRefreshesFirewallState(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:5000"}})
+	for i, running := range []bool{true, false, true} {
+		if _, err := srv.Subscribe(ctx, &pb.ClientConfig{Name: "daemon", IsFirewallRunning: running}); err != nil {
+			t.Fatalf("Subscribe error: %v", err)
+		}
+		node := store.Snapshot().Nodes[0]
+		if node.FirewallEnabled != running || !node.FirewallKnown {
+			t.Fatalf("subscribe %d: expected the firewall reported %v, got %+v", i, running, node)
+		}
+	}
+
+	// Updates that do not carry the firewall state leave it alone.
+	store.UpsertNode(state.Node{ID: "tcp://1.2.3.4:5000", Message: "cached"})
+	if node := store.Snapshot().Nodes[0]; !node.FirewallEnabled || !node.FirewallKnown {
+		t.Fatalf("expected the reported firewall state kept, got %+v", node)
+	}
+}
+
+func TestServerSubscribeRecordsRuleChanges(t *testing.T) {
+	store := state.NewStore()
+	history := rulehistory.New(filepath.Join(t.TempDir(), "rule-history.json"))
+	srv := New(store, Options{History: history})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:5000"}})
+	subscribe := func(action string) {
+		t.Helper()
+		rule := &pb.Rule{Name: "curl", Action: action, Duration: "always", Enabled: true, Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}}
+		if _, err := srv.Subscribe(ctx, &pb.ClientConfig{Name: "daemon", Rules: []*pb.Rule{rule}}); err != nil {
+			t.Fatalf("Subscribe error: %v", err)
+		}
+	}
+	subscribe("allow")
+	subscribe("deny")
+
+	got := store.RuleHistory("tcp://1.2.3.4:5000", "curl")
+	if len(got) != 1 || got[0].Source != state.RuleChangeDaemon || got[0].Summary() != "action: allow → deny" {
+		t.Fatalf("expected the daemon's change recorded, got %+v", got)
+	}
+	saved, err := history.Load()
+	if err != nil || len(saved) != 1 || saved[0].Rule != "curl" {
+		t.Fatalf("expected the timeline written, got %+v, %v", saved, err)
+	}
+}
+
 func TestServerEnableRuleSendsNotification(t *testing.T) {
 	store := state.NewStore()
 	srv := New(store, Options{})
 	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 1)}
 	srv.sessions["node-1"] = sess
-	store.SetRules("node-1", []state.Rule{{
+	rule := state.Rule{
 		Name:     "ssh",
 		Operator: state.RuleOperator{Type: "process", Operand: "eq", Data: "/usr/bin/ssh"},
-	}})
-	if err := srv.EnableRule("node-1", "ssh"); err != nil {
+	}
+	store.SetRules("node-1", []state.Rule{rule})
+	if err := srv.EnableRule("node-1", "ssh", ruleset.Hash(rule)); err != nil {
 		t.Fatalf("EnableRule error: %v", err)
 	}
 	notif := <-sess.send
@@ -127,7 +233,7 @@ func TestServerDeleteRuleRemovesState(t *testing.T) {
 		Name:     "ssh",
 		Operator: state.RuleOperator{Type: "process"},
 	}})
-	if err := srv.DeleteRule("node-1", "ssh"); err != nil {
+	if err := srv.DeleteRule("node-1", "ssh", ""); err != nil {
 		t.Fatalf("DeleteRule error: %v", err)
 	}
 	if _, ok := store.Snapshot().Rules["node-1"]; ok {
@@ -135,6 +241,90 @@ func TestServerDeleteRuleRemovesState(t *testing.T) {
 	}
 }
 
+func TestServerAddRuleSendsChangeAndStores(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 1)}
+	srv.sessions["node-1"] = sess
+	rule := ruleset.StarterPack()[0].Rule
+
+	if err := srv.AddRule("node-1", rule); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	notif := <-sess.send
+	if notif.Type != pb.Action_CHANGE_RULE || len(notif.Rules) != 1 || len(notif.Rules[0].GetOperator().GetList()) == 0 {
+		t.Fatalf("unexpected notification: %+v", notif)
+	}
+	if got := store.Snapshot().Rules["node-1"]; len(got) != 1 || got[0].Name != rule.Name || got[0].CreatedAt.IsZero() {
+		t.Fatalf("expected the rule stored with a creation time, got %+v", got)
+	}
+	if err := srv.AddRule("node-1", rule); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a duplicate error, got %v", err)
+	}
+}
+
+func TestServerAddRuleSendsAuthoredOperator(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 1)}
+	srv.sessions["node-1"] = sess
+
+	if err := srv.AddRule("node-1", state.Rule{Action: "deny"}); err == nil || !strings.Contains(err.Error(), "name required") {
+		t.Fatalf("expected an unnamed rule rejected, got %v", err)
+	}
+	rule := state.Rule{
+		Name:     "block-curl",
+		Action:   "deny",
+		Duration: "always",
+		Enabled:  true,
+		Operator: state.RuleOperator{Type: "regexp", Operand: "process.path", Data: "^/usr/bin/curl$"},
+	}
+	if err := srv.AddRule("node-1", rule); err != nil {
+		t.Fatalf("AddRule error: %v", err)
+	}
+	notif := <-sess.send
+	op := notif.GetRules()[0].GetOperator()
+	if notif.Type != pb.Action_CHANGE_RULE || op.GetType() != "regexp" || op.GetOperand() != "process.path" || op.GetData() != "^/usr/bin/curl$" {
+		t.Fatalf("expected the authored operator on the wire, got %+v", notif)
+	}
+	if !notif.GetRules()[0].GetEnabled() || notif.GetRules()[0].GetAction() != "deny" {
+		t.Fatalf("expected an enabled deny rule, got %+v", notif.GetRules()[0])
+	}
+}
+
+func TestServerRejectsRuleChangedSinceDisplayed(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 1)}
+	srv.sessions["node-1"] = sess
+	shown := state.Rule{
+		Name:     "ssh",
+		Action:   "allow",
+		Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+	}
+	hash := ruleset.Hash(shown)
+	// A refresh replaced the rule under the same name.
+	replaced := shown
+	replaced.Operator.Data = "/usr/bin/scp"
+	store.SetRules("node-1", []state.Rule{replaced})
+
+	for name, call := range map[string]func() error{
+		"enable": func() error { return srv.EnableRule("node-1", "ssh", hash) },
+		"delete": func() error { return srv.DeleteRule("node-1", "ssh", hash) },
+		"change": func() error { return srv.ChangeRule("node-1", shown, hash) },
+	} {
+		if err := call(); !errors.Is(err, controller.ErrRuleConflict) {
+			t.Fatalf("%s: expected a conflict, got %v", name, err)
+		}
+	}
+	if len(sess.send) != 0 {
+		t.Fatal("notification sent for a conflicting rule")
+	}
+	if got := store.Snapshot().Rules["node-1"]; len(got) != 1 || got[0].Operator.Data != "/usr/bin/scp" {
+		t.Fatalf("expected the store untouched, got %+v", got)
+	}
+}
+
 func TestServerResolvePromptAddsRule(t *testing.T) {
 	store := state.NewStore()
 	store.SetStats(state.Stats{NodeID: "node-1"})
@@ -169,6 +359,92 @@ func TestServerResolvePromptAddsRule(t *testing.T) {
 	}
 }
 
+func TestServerResolvePromptRejectsOversizedOperatorData(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	req := &promptRequest{
+		id: "prompt-1",
+		prompt: state.Prompt{
+			ID:     "prompt-1",
+			NodeID: "node-1",
+			Connection: state.Connection{
+				ProcessPath: "/usr/bin/sh",
+				ProcessArgs: []string{"/usr/bin/sh", "-c", strings.Repeat("a", 64<<10)},
+			},
+		},
+		response: make(chan promptResponse, 1),
+	}
+	srv.registerPrompt(req)
+	decision := controller.PromptDecision{
+		PromptID: "prompt-1",
+		Action:   controller.PromptActionAllow,
+		Duration: controller.PromptDurationAlways,
+		Target:   controller.PromptTargetProcessCmd,
+	}
+	err := srv.ResolvePrompt(decision)
+	if err == nil || !strings.Contains(err.Error(), "operator data is") {
+		t.Fatalf("expected operator data limit error, got %v", err)
+	}
+	if len(req.response) != 0 || len(store.Snapshot().Rules["node-1"]) != 0 {
+		t.Fatalf("expected no rule to be sent or stored")
+	}
+
+	decision.Target = controller.PromptTargetProcessPath
+	if err := srv.ResolvePrompt(decision); err != nil {
+		t.Fatalf("expected a shorter target to succeed, got %v", err)
+	}
+}
+
+func TestServerAutomaticDecisionsCheckRuleLimits(t *testing.T) {
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.DefaultPromptTarget = string(controller.PromptTargetProcessPath)
+	settings.MaxOperatorData = 16
+	store.SetSettings(settings)
+	srv := New(store, Options{})
+	prompt := state.Prompt{ID: "prompt-1", NodeID: "node-1", Connection: state.Connection{ProcessPath: "/opt/vendor/bin/updater-daemon"}}
+
+	decision := srv.defaultPromptDecision(prompt)
+	decision.Source = state.DecisionSourceDND
+	if _, err := srv.applyDecision(prompt, decision); err == nil || !strings.Contains(err.Error(), "operator data is") {
+		t.Fatalf("expected the limit enforced for a decision made without the user, got %v", err)
+	}
+	if decisions := store.Snapshot().Decisions; len(decisions) != 0 {
+		t.Fatalf("expected nothing recorded for a rejected rule, got %+v", decisions)
+	}
+}
+
+func TestPromptTargetsSkipUnresolvedPIDAndUID(t *testing.T) {
+	unresolved := state.Connection{DstIP: "10.0.0.5"}
+	resolved := state.Connection{ProcessID: 4242, UserID: 1000}
+	root := state.Connection{ProcessID: 1}
+
+	for _, target := range []controller.PromptTarget{controller.PromptTargetProcessID, controller.PromptTargetUserID} {
+		if target.Available(unresolved, true) {
+			t.Fatalf("%s offered without a value", target)
+		}
+		if _, err := operatorForTarget(unresolved, target, true); err == nil || !strings.Contains(err.Error(), "unknown") {
+			t.Fatalf("%s: expected an unknown value error, got %v", target, err)
+		}
+		if _, err := operatorForTarget(resolved, target, true); err != nil {
+			t.Fatalf("%s: unexpected error %v", target, err)
+		}
+	}
+	if op, err := operatorForTarget(root, controller.PromptTargetUserID, false); err != nil || op.GetData() != "0" {
+		t.Fatalf("expected UID 0 to mean root by default, got %v, %v", op, err)
+	}
+	if _, err := operatorForTarget(root, controller.PromptTargetUserID, true); err == nil {
+		t.Fatal("expected UID 0 to be unknown when configured")
+	}
+
+	if got := controller.BestTarget(state.Connection{UserID: 1000}, true); got != controller.PromptTargetUserID {
+		t.Fatalf("expected the user id when the pid is unknown, got %s", got)
+	}
+	if got := controller.BestTarget(resolved, true); got != controller.PromptTargetProcessID {
+		t.Fatalf("expected the process id when known, got %s", got)
+	}
+}
+
 func TestPauseResumePromptUpdatesStore(t *testing.T) {
 	store := state.NewStore()
 	srv := New(store, Options{})
@@ -293,3 +569,300 @@ type testAddr struct {
 
 func (a *testAddr) Network() string { return a.network }
 func (a *testAddr) String() string  { return a.value }
+
+func TestServerPingMergesEventsIntoStore(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:1000"}})
+
+	req := &pb.PingRequest{Id: 1, Stats: &pb.Statistics{
+		Connections: 2,
+		Events: []*pb.Event{
+			{Time: "t1", Unixnano: 10, Connection: &pb.Connection{DstHost: "a.example"}},
+			{Time: "t2", Unixnano: 20, Connection: &pb.Connection{DstHost: "b.example"}},
+		},
+	}}
+	if _, err := srv.Ping(ctx, req); err != nil {
+		t.Fatalf("Ping returned error: %v", err)
+	}
+	// A ping without events keeps the existing history.
+	if _, err := srv.Ping(ctx, &pb.PingRequest{Id: 2, Stats: &pb.Statistics{Connections: 3}}); err != nil {
+		t.Fatalf("Ping returned error: %v", err)
+	}
+
+	snap := store.Snapshot()
+	if snap.Stats.Connections != 3 {
+		t.Fatalf("expected latest stats, got %+v", snap.Stats)
+	}
+	if len(snap.Events) != 2 || snap.Events[0].Connection.DstHost != "b.example" {
+		t.Fatalf("expected events newest first, got %+v", snap.Events)
+	}
+}
+
+func TestServerPingEstimatesClockSkew(t *testing.T) {
+	store := state.NewStore()
+	clock := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	srv := New(store, Options{})
+	srv.now = func() time.Time { return clock }
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:1000"}})
+
+	for i := 0; i < 4; i++ {
+		clock = clock.Add(5 * time.Second)
+		stamp := clock.Add(42 * time.Second).UnixNano()
+		req := &pb.PingRequest{Id: uint64(i), Stats: &pb.Statistics{
+			Events: []*pb.Event{{Time: "t", Unixnano: stamp, Connection: &pb.Connection{DstHost: "a.example"}}},
+		}}
+		if _, err := srv.Ping(ctx, req); err != nil {
+			t.Fatalf("Ping returned error: %v", err)
+		}
+	}
+
+	nodes := store.Snapshot().Nodes
+	if len(nodes) != 1 || !nodes[0].ClockSkewKnown || nodes[0].ClockSkew != 42*time.Second {
+		t.Fatalf("expected +42s skew on node, got %+v", nodes)
+	}
+}
+
+func TestServerAskRuleDNDResolvesUntilExpiry(t *testing.T) {
+	store := state.NewStore()
+	base := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	settings := store.Snapshot().Settings
+	settings.PromptTimeout = 10 * time.Millisecond
+	settings.DefaultPromptAction = "allow"
+	settings.DNDEnabled = true
+	settings.DNDUntil = base.Add(30 * time.Minute)
+	store.SetSettings(settings)
+
+	clock := base
+	srv := New(store, Options{})
+	srv.now = func() time.Time { return clock }
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:6000"}})
+	conn := &pb.Connection{ProcessPath: "/usr/bin/curl", DstHost: "example.com", DstPort: 443}
+
+	rule, err := srv.AskRule(ctx, conn)
+	if err != nil {
+		t.Fatalf("AskRule returned error: %v", err)
+	}
+	if rule.GetAction() != "allow" {
+		t.Fatalf("expected default action during DND, got %q", rule.GetAction())
+	}
+	snap := store.Snapshot()
+	if len(snap.Prompts) != 0 {
+		t.Fatalf("expected no prompt to be queued during DND")
+	}
+	if len(snap.Decisions) != 1 || snap.Decisions[0].Source != state.DecisionSourceDND {
+		t.Fatalf("expected dnd decision recorded, got %+v", snap.Decisions)
+	}
+
+	// Once the window has passed the prompt is queued again and times out normally.
+	clock = base.Add(31 * time.Minute)
+	if _, err := srv.AskRule(ctx, conn); err != nil {
+		t.Fatalf("AskRule returned error: %v", err)
+	}
+	snap = store.Snapshot()
+	if len(snap.Decisions) != 2 || snap.Decisions[0].Source != state.DecisionSourceTimeout {
+		t.Fatalf("expected timeout decision after DND expiry, got %+v", snap.Decisions)
+	}
+}
+
+func TestServeAfterShutdownReturns(t *testing.T) {
+	srv := New(state.NewStore(), Options{})
+	srv.Shutdown(context.Background())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(context.Background(), lis) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean return, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve kept running after Shutdown")
+	}
+	if conn, err := net.Dial("tcp", lis.Addr().String()); err == nil {
+		conn.Close()
+		t.Fatalf("expected the listener closed")
+	}
+}
+
+func TestServerShutdownResolvesPendingPrompts(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	reqs := make([]*promptRequest, 3)
+	for i := range reqs {
+		id := fmt.Sprintf("prompt-%d", i)
+		reqs[i] = &promptRequest{
+			id: id,
+			prompt: state.Prompt{
+				ID:         id,
+				NodeID:     "node-1",
+				Connection: state.Connection{ProcessPath: fmt.Sprintf("/usr/bin/app%d", i)},
+			},
+			response: make(chan promptResponse, 1),
+		}
+		srv.registerPrompt(reqs[i])
+		store.AddPrompt(reqs[i].prompt)
+	}
+
+	srv.Shutdown(context.Background())
+
+	for _, req := range reqs {
+		select {
+		case resp := <-req.response:
+			if resp.err != nil || resp.rule == nil {
+				t.Fatalf("expected rule for %s, got %+v", req.id, resp)
+			}
+			if resp.rule.GetAction() != string(controller.PromptActionDeny) {
+				t.Fatalf("expected default deny for %s, got %q", req.id, resp.rule.GetAction())
+			}
+		default:
+			t.Fatalf("expected %s to receive a response during shutdown", req.id)
+		}
+	}
+	snap := store.Snapshot()
+	if len(snap.Prompts) != 0 {
+		t.Fatalf("expected prompts to be cleared, got %d", len(snap.Prompts))
+	}
+	if len(snap.Decisions) != len(reqs) {
+		t.Fatalf("expected %d decisions, got %d", len(reqs), len(snap.Decisions))
+	}
+	for _, d := range snap.Decisions {
+		if d.Source != state.DecisionSourceShutdown {
+			t.Fatalf("expected shutdown source, got %q", d.Source)
+		}
+	}
+}
+
+func TestServerAskRuleAfterShutdownAnswersImmediately(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	srv.Shutdown(context.Background())
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "1.2.3.4:1000"}})
+	rule, err := srv.AskRule(ctx, &pb.Connection{ProcessPath: "/usr/bin/curl", DstIp: "1.1.1.1", DstPort: 443, Protocol: "tcp"})
+	if err != nil || rule == nil {
+		t.Fatalf("expected immediate default rule, got %v / %v", rule, err)
+	}
+	if len(store.Snapshot().Prompts) != 0 {
+		t.Fatalf("expected no prompt to be queued after shutdown")
+	}
+}
+
+func TestServerShutdownFlushesQueuedNotifications(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 2)}
+	srv.sessions["node-1"] = sess
+	sess.send <- &pb.Notification{Id: 1}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		<-sess.send
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	srv.Shutdown(ctx)
+	if queued := len(sess.send); queued != 0 {
+		t.Fatalf("expected Shutdown to wait for the queue to drain, %d left", queued)
+	}
+}
+
+func TestServerAskRulePolicyOnlyNodeSkipsPrompt(t *testing.T) {
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.DefaultPromptAction = "deny"
+	store.SetSettings(settings)
+	store.SetNodes([]state.Node{
+		{ID: "server", Name: "server", Address: "10.0.0.7:50051", Prompts: state.PromptsPolicy, Configured: true},
+		{ID: "laptop", Name: "laptop", Address: "10.0.0.8:50051", Prompts: state.PromptsInteractive, Configured: true},
+	})
+	srv := New(store, Options{})
+	serverCtx := peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "10.0.0.7:41000"}})
+	laptopCtx, cancel := context.WithCancel(peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "10.0.0.8:42000"}}))
+	defer cancel()
+	conn := &pb.Connection{ProcessPath: "/usr/bin/curl", DstHost: "example.com", DstPort: 443}
+
+	// The laptop's prompt stays pending while the server's is answered.
+	laptopDone := make(chan error, 1)
+	go func() {
+		_, err := srv.AskRule(laptopCtx, conn)
+		laptopDone <- err
+	}()
+	deadline := time.Now().Add(time.Second)
+	for len(store.Snapshot().Prompts) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("laptop prompt never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	rule, err := srv.AskRule(serverCtx, conn)
+	if err != nil {
+		t.Fatalf("AskRule returned error: %v", err)
+	}
+	if rule.GetAction() != "deny" {
+		t.Fatalf("expected the default action for the policy-only node, got %q", rule.GetAction())
+	}
+	snap := store.Snapshot()
+	if len(snap.Prompts) != 1 || snap.Prompts[0].NodeID != "tcp://10.0.0.8:42000" {
+		t.Fatalf("expected only the laptop prompt in the store, got %+v", snap.Prompts)
+	}
+	if len(snap.Decisions) != 1 || snap.Decisions[0].Source != state.DecisionSourcePolicy || snap.Decisions[0].NodeID != "tcp://10.0.0.7:41000" {
+		t.Fatalf("expected a policy decision for the server, got %+v", snap.Decisions)
+	}
+
+	cancel()
+	if err := <-laptopDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the laptop prompt to wait for the user, got %v", err)
+	}
+}
+
+func TestServerAskRuleCancelledRecordsWithdrawnPrompt(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "tcp://10.0.0.8:42000", Name: "laptop"}})
+	srv := New(store, Options{})
+	ctx, cancel := context.WithCancel(peer.NewContext(context.Background(), &peer.Peer{Addr: &testAddr{network: "tcp", value: "10.0.0.8:42000"}}))
+	defer cancel()
+	conn := &pb.Connection{ProcessPath: "/usr/bin/curl", DstHost: "example.com", DstPort: 443}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := srv.AskRule(ctx, conn)
+		done <- err
+	}()
+	deadline := time.Now().Add(time.Second)
+	for len(store.Snapshot().Prompts) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("prompt never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	promptID := store.Snapshot().Prompts[0].ID
+
+	// The daemon gives up: the prompt goes, with a history entry saying why.
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation returned, got %v", err)
+	}
+	snap := store.Snapshot()
+	if len(snap.Prompts) != 0 {
+		t.Fatalf("expected the prompt removed, got %+v", snap.Prompts)
+	}
+	if len(snap.Decisions) != 1 {
+		t.Fatalf("expected one history entry, got %+v", snap.Decisions)
+	}
+	if d := snap.Decisions[0]; d.PromptID != promptID || d.Source != state.DecisionSourceWithdrawn || d.Outcome != state.OutcomeWithdrawn || d.Action != "" {
+		t.Fatalf("expected a withdrawn entry, got %+v", d)
+	}
+	if len(snap.Rules) != 0 {
+		t.Fatalf("expected no rule for a withdrawn prompt, got %+v", snap.Rules)
+	}
+	if len(snap.Log) == 0 || snap.Log[0].Severity != state.LogWarning || !strings.Contains(snap.Log[0].Text, "laptop stopped waiting") {
+		t.Fatalf("expected a warning in the session log, got %+v", snap.Log)
+	}
+}
@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// ShutdownTimeout bounds how long Shutdown waits for in-flight RPCs before
+// forcing the gRPC server to stop.
+const ShutdownTimeout = 3 * time.Second
+
+// Shutdown answers every pending prompt with the default decision, re-enables
+// firewalls paused for a limited time, flushes queued notifications and
+// stops the gRPC server. It waits for a graceful
+// stop until ctx is done and then stops forcibly. It is safe to call more
+// than once; later calls wait for the first to finish.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.shutdownOnce.Do(func() {
+		s.closing.Store(true)
+		s.resolvePendingPrompts()
+		s.resumePausedFirewalls()
+		s.flushNotifications(ctx)
+		s.stopGRPC(ctx)
+	})
+}
+
+// resolvePendingPrompts unblocks every waiting AskRule call so daemons are
+// not left hanging until their own timeout.
+func (s *Server) resolvePendingPrompts() {
+	s.promptsMu.Lock()
+	pending := make([]*promptRequest, 0, len(s.prompts))
+	for _, req := range s.prompts {
+		pending = append(pending, req)
+	}
+	s.promptsMu.Unlock()
+
+	for _, req := range pending {
+		decision := s.shutdownDecision(req.prompt)
+		rule, err := s.buildRuleFromDecision(req.prompt, decision)
+		select {
+		case req.response <- promptResponse{rule: rule, err: err}:
+			if err == nil {
+				s.recordDecision(req.prompt, decision, rule)
+			}
+			s.store.RemovePrompt(req.id)
+		default:
+			// Already answered by the user or a timeout.
+		}
+	}
+}
+
+func (s *Server) shutdownDecision(prompt state.Prompt) controller.PromptDecision {
+	decision := s.defaultPromptDecision(prompt)
+	decision.Source = state.DecisionSourceShutdown
+	return decision
+}
+
+// flushNotifications waits until every session has handed its queued
+// notifications to the stream, or ctx is done.
+func (s *Server) flushNotifications(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for s.queuedNotifications() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) queuedNotifications() int {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	queued := 0
+	for _, sess := range s.sessions {
+		if sess.send != nil {
+			queued += len(sess.send)
+		}
+	}
+	return queued
+}
+
+func (s *Server) stopGRPC(ctx context.Context) {
+	s.grpcMu.Lock()
+	srv := s.grpc
+	s.grpcMu.Unlock()
+	if srv == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.Stop()
+		<-done
+	}
+}
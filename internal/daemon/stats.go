@@ -7,7 +7,9 @@ import (
 	"time"
 
 	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/ruleconv"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
 func convertStats(stats *pb.Statistics, nodeID, nodeName string) state.Stats {
@@ -22,7 +24,7 @@ func convertStats(stats *pb.Statistics, nodeID, nodeName string) state.Stats {
 	return state.Stats{
 		NodeID:         nodeID,
 		NodeName:       nodeName,
-		DaemonVersion:  stats.GetDaemonVersion(),
+		DaemonVersion:  util.Sanitize(stats.GetDaemonVersion()),
 		Rules:          stats.GetRules(),
 		Connections:    stats.GetConnections(),
 		Accepted:       stats.GetAccepted(),
@@ -34,7 +36,6 @@ func convertStats(stats *pb.Statistics, nodeID, nodeName string) state.Stats {
 		TopDestPorts:   topBuckets(stats.GetByPort(), 5),
 		TopExecutables: topBuckets(stats.GetByExecutable(), 5),
 		TopUsers:       topUserBuckets(stats.GetByUid(), 5),
-		Events:         convertEvents(stats.GetEvents(), nodeID, 0),
 		UpdatedAt:      time.Now(),
 	}
 }
@@ -48,7 +49,7 @@ func topBuckets(values map[string]uint64, size int) []state.StatBucket {
 		if value == 0 {
 			continue
 		}
-		buckets = append(buckets, state.StatBucket{Label: key, Value: value})
+		buckets = append(buckets, state.StatBucket{Label: util.Sanitize(key), Value: value})
 	}
 	if len(buckets) == 0 {
 		return nil
@@ -103,9 +104,9 @@ func convertEvent(ev *pb.Event, nodeID string) state.Event {
 	}
 	return state.Event{
 		NodeID:     nodeID,
-		Time:       ev.GetTime(),
+		Time:       util.Sanitize(ev.GetTime()),
 		UnixNano:   ev.GetUnixnano(),
 		Connection: convertConnection(ev.GetConnection()),
-		Rule:       convertRule(ev.GetRule(), nodeID),
+		Rule:       ruleconv.FromProto(ev.GetRule(), nodeID),
 	}
 }
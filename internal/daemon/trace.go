@@ -0,0 +1,171 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// traceLimit is how many messages the trace keeps per node; older ones
+// make room for new ones.
+const traceLimit = 500
+
+// traceSummaryRunes caps a traced payload. Rule lists and large operands
+// would otherwise keep whole rule sets in memory.
+const traceSummaryRunes = 240
+
+var traceFormat = prototext.MarshalOptions{}
+
+// traceRing holds the newest traceLimit messages of one node.
+type traceRing struct {
+	entries []state.TraceEntry
+	// next is where the following entry goes once the ring is full.
+	next int
+}
+
+func (r *traceRing) add(entry state.TraceEntry) {
+	if len(r.entries) < traceLimit {
+		r.entries = append(r.entries, entry)
+		return
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % traceLimit
+}
+
+func (r *traceRing) list() []state.TraceEntry {
+	out := make([]state.TraceEntry, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// SetProtocolTrace implements controller.ProtocolTracer. Turning tracing
+// off keeps what was recorded.
+func (s *Server) SetProtocolTrace(enabled bool) {
+	s.tracing.Store(enabled)
+}
+
+// ProtocolTraceEnabled implements controller.ProtocolTracer.
+func (s *Server) ProtocolTraceEnabled() bool {
+	return s.tracing.Load()
+}
+
+// ProtocolTrace implements controller.ProtocolTracer.
+func (s *Server) ProtocolTrace(nodeID string) []state.TraceEntry {
+	s.traceMu.Lock()
+	defer s.traceMu.Unlock()
+	ring, ok := s.traces[nodeID]
+	if !ok {
+		return nil
+	}
+	return ring.list()
+}
+
+// DumpProtocolTrace implements controller.ProtocolTracer, writing the trace
+// to trace-<node>.txt under the state directory.
+func (s *Server) DumpProtocolTrace(nodeID string) (string, error) {
+	entries := s.ProtocolTrace(nodeID)
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no protocol trace recorded for %s", nodeID)
+	}
+	dir, err := persist.StateDir()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# protocol trace of %s, %d messages\n", nodeID, len(entries))
+	for _, entry := range entries {
+		b.WriteString(entry.String())
+		b.WriteByte('\n')
+	}
+	path := filepath.Join(dir, "trace-"+traceFileName(nodeID)+".txt")
+	if err := persist.WriteFile(path, []byte(b.String())); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// traceNotification records notif as sent to nodeID.
+func (s *Server) traceNotification(nodeID string, notif *pb.Notification) {
+	if !s.tracing.Load() {
+		return
+	}
+	payload := proto.Clone(notif).(*pb.Notification)
+	payload.Id, payload.Type, payload.ServerName, payload.ClientName = 0, 0, "", ""
+	s.trace(state.TraceEntry{
+		NodeID:    nodeID,
+		Direction: state.TraceSent,
+		ID:        notif.GetId(),
+		Action:    notif.GetType().String(),
+		Summary:   traceSummary(payload),
+	})
+}
+
+// traceReply records reply as received from nodeID.
+func (s *Server) traceReply(nodeID string, reply *pb.NotificationReply) {
+	if !s.tracing.Load() {
+		return
+	}
+	s.trace(state.TraceEntry{
+		NodeID:    nodeID,
+		Direction: state.TraceReceived,
+		ID:        reply.GetId(),
+		Action:    reply.GetCode().String(),
+		Summary:   util.Sanitize(truncateSummary(strings.TrimSpace(reply.GetData()))),
+	})
+}
+
+func (s *Server) trace(entry state.TraceEntry) {
+	entry.At = s.now()
+	s.traceMu.Lock()
+	defer s.traceMu.Unlock()
+	ring, ok := s.traces[entry.NodeID]
+	if !ok {
+		ring = &traceRing{}
+		s.traces[entry.NodeID] = ring
+	}
+	ring.add(entry)
+}
+
+// traceSummary renders msg as one line of prototext, cut to
+// traceSummaryRunes.
+func traceSummary(msg proto.Message) string {
+	out, err := traceFormat.Marshal(msg)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return util.Sanitize(truncateSummary(strings.Join(strings.Fields(string(out)), " ")))
+}
+
+func truncateSummary(text string) string {
+	n := utf8.RuneCountInString(text)
+	if n <= traceSummaryRunes {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:traceSummaryRunes]) + fmt.Sprintf("… (%d more characters)", n-traceSummaryRunes)
+}
+
+// traceFileName turns a node ID such as unix:/run/x or 10.0.0.2:50051
+// into something safe to use in a file name.
+func traceFileName(nodeID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, nodeID)
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
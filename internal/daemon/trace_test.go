@@ -0,0 +1,150 @@
+package daemon
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// replyStream hands out queued replies, then EOF.
+type replyStream struct {
+	fakeNotificationStream
+	replies []*pb.NotificationReply
+}
+
+func (r *replyStream) Recv() (*pb.NotificationReply, error) {
+	if len(r.replies) == 0 {
+		return nil, io.EOF
+	}
+	reply := r.replies[0]
+	r.replies = r.replies[1:]
+	return reply, nil
+}
+
+func TestProtocolTraceRecordsBothDirections(t *testing.T) {
+	store := state.NewStore()
+	clock := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	srv := New(store, Options{TraceProtocol: true})
+	srv.now = func() time.Time { return clock }
+	nodeID := "tcp://10.0.0.7:50051"
+	store.SetRules(nodeID, []state.Rule{{Name: "ssh"}})
+	srv.registerSession(nodeID)
+
+	if err := srv.EnableRule(nodeID, "ssh", ""); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	clock = clock.Add(40 * time.Millisecond)
+	stream := &replyStream{replies: []*pb.NotificationReply{
+		{Id: 1},
+		{Id: 7, Code: pb.NotificationReplyCode_ERROR, Data: "rule file not writable\n"},
+	}}
+	errCh := make(chan error, 1)
+	srv.drainReplies(stream, nodeID, errCh)
+	if err := <-errCh; err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	trace := srv.ProtocolTrace(nodeID)
+	if len(trace) != 3 {
+		t.Fatalf("expected a notification and two replies, got %+v", trace)
+	}
+	sent := trace[0]
+	if sent.Direction != state.TraceSent || sent.ID != 1 || sent.Action != "ENABLE_RULE" || !strings.Contains(sent.Summary, `"ssh"`) {
+		t.Fatalf("unexpected sent entry %+v", sent)
+	}
+	ok, failed := trace[1], trace[2]
+	if ok.Direction != state.TraceReceived || ok.ID != 1 || ok.Action != "OK" || !ok.At.Equal(clock) {
+		t.Fatalf("unexpected reply entry %+v", ok)
+	}
+	if failed.ID != 7 || failed.Action != "ERROR" || failed.Summary != "rule file not writable" {
+		t.Fatalf("unexpected error reply entry %+v", failed)
+	}
+	if got := failed.String(); got != "12:00:00.040 ← #7 ERROR rule file not writable" {
+		t.Fatalf("unexpected trace line %q", got)
+	}
+	if other := srv.ProtocolTrace("tcp://10.0.0.8:50051"); len(other) != 0 {
+		t.Fatalf("expected traces kept per node, got %+v", other)
+	}
+}
+
+func TestProtocolTraceTruncatesLargeRules(t *testing.T) {
+	srv := New(state.NewStore(), Options{TraceProtocol: true})
+	nodeID := "tcp://10.0.0.7:50051"
+	srv.registerSession(nodeID)
+
+	notif := srv.newNotification(pb.Action_CHANGE_RULE, nodeID)
+	notif.Rules = []*pb.Rule{{Name: "blocklist", Operator: &pb.Operator{Type: "lists", Operand: "lists.domains", Data: strings.Repeat("ads.example.com,", 2000)}}}
+	if err := srv.sendNotification(nodeID, notif); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	trace := srv.ProtocolTrace(nodeID)
+	if len(trace) != 1 {
+		t.Fatalf("expected the notification traced, got %d entries", len(trace))
+	}
+	summary := trace[0].Summary
+	head, marker, ok := strings.Cut(summary, "…")
+	if !ok || utf8.RuneCountInString(head) != traceSummaryRunes || !strings.HasSuffix(marker, "more characters)") {
+		t.Fatalf("expected the payload cut to %d runes, got %d: %q", traceSummaryRunes, utf8.RuneCountInString(summary), summary)
+	}
+	if !strings.Contains(head, `"blocklist"`) {
+		t.Fatalf("expected the summary to start with the rule, got %q", head)
+	}
+}
+
+func TestProtocolTraceIsOffByDefaultAndBounded(t *testing.T) {
+	srv := New(state.NewStore(), Options{})
+	nodeID := "tcp://10.0.0.7:50051"
+	srv.registerSession(nodeID)
+	if err := srv.sendNotification(nodeID, srv.newNotification(pb.Action_ENABLE_INTERCEPTION, nodeID)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if trace := srv.ProtocolTrace(nodeID); len(trace) != 0 {
+		t.Fatalf("expected nothing recorded while tracing is off, got %+v", trace)
+	}
+
+	srv.SetProtocolTrace(true)
+	for id := uint64(1); id <= traceLimit+5; id++ {
+		srv.traceReply(nodeID, &pb.NotificationReply{Id: id})
+	}
+	trace := srv.ProtocolTrace(nodeID)
+	if len(trace) != traceLimit || trace[0].ID != 6 || trace[len(trace)-1].ID != traceLimit+5 {
+		t.Fatalf("expected the newest %d replies oldest first, got %d from #%d", traceLimit, len(trace), trace[0].ID)
+	}
+
+	srv.SetProtocolTrace(false)
+	srv.traceReply(nodeID, &pb.NotificationReply{Id: 9999})
+	if got := srv.ProtocolTrace(nodeID); len(got) != traceLimit || got[len(got)-1].ID != traceLimit+5 {
+		t.Fatalf("expected turning tracing off to keep the trace and stop recording")
+	}
+}
+
+func TestDumpProtocolTrace(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	srv := New(state.NewStore(), Options{TraceProtocol: true})
+	nodeID := "unix:/run/opensnitch.sock"
+	if _, err := srv.DumpProtocolTrace(nodeID); err == nil {
+		t.Fatalf("expected an error without a trace")
+	}
+	srv.traceReply(nodeID, &pb.NotificationReply{Id: 3, Data: "done"})
+
+	path, err := srv.DumpProtocolTrace(nodeID)
+	if err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	if !strings.HasSuffix(path, "/opensnitch-tui/trace-unix__run_opensnitch.sock.txt") {
+		t.Fatalf("unexpected dump path %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read dump: %v", err)
+	}
+	if !strings.Contains(string(data), "← #3 OK done\n") {
+		t.Fatalf("unexpected dump:\n%s", data)
+	}
+}
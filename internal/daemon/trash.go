@@ -0,0 +1,81 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// trashRule moves rule into the trash before it is deleted from nodeID.
+// The returned func takes it out again if the delete is not sent.
+func (s *Server) trashRule(nodeID string, rule state.Rule) (undo func()) {
+	node, _ := s.nodeByID(nodeID)
+	key := state.NodeKey(node)
+	s.store.TrashRule(state.TrashedRule{NodeKey: key, NodeName: node.Name, Rule: rule, DeletedAt: s.now()})
+	s.saveTrash()
+	return func() {
+		s.store.TakeTrashed(key, rule.Name)
+		s.saveTrash()
+	}
+}
+
+// RestoreRule implements controller.RuleTrash: the trashed rule is pushed
+// back to nodeID with AddRule and leaves the trash once the daemon has it.
+func (s *Server) RestoreRule(nodeID, ruleName string) error {
+	node, ok := s.nodeByID(nodeID)
+	if !ok {
+		return fmt.Errorf("node %s not found", nodeID)
+	}
+	key := state.NodeKey(node)
+	var entry state.TrashedRule
+	found := false
+	for _, trashed := range state.TrashOf(s.store.Snapshot().Trash, node) {
+		if trashed.Rule.Name == ruleName {
+			entry, found = trashed, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("rule %s is not in the trash of %s", ruleName, nodeID)
+	}
+	rule := entry.Rule
+	rule.NodeID = nodeID
+	if err := s.AddRule(nodeID, rule); err != nil {
+		return err
+	}
+	s.store.TakeTrashed(key, ruleName)
+	s.saveTrash()
+	return nil
+}
+
+// EmptyTrash implements controller.RuleTrash.
+func (s *Server) EmptyTrash(nodeID string) (int, error) {
+	node, ok := s.nodeByID(nodeID)
+	if !ok {
+		return 0, fmt.Errorf("node %s not found", nodeID)
+	}
+	removed := s.store.EmptyTrash(state.NodeKey(node))
+	if removed > 0 {
+		s.saveTrash()
+	}
+	return removed, nil
+}
+
+func (s *Server) nodeByID(nodeID string) (state.Node, bool) {
+	for _, node := range s.store.Snapshot().Nodes {
+		if node.ID == nodeID {
+			return node, true
+		}
+	}
+	return state.Node{ID: nodeID}, false
+}
+
+// saveTrash queues the trash for writing, if it is persisted.
+func (s *Server) saveTrash() {
+	if s.opts.Trash == nil {
+		return
+	}
+	if err := s.opts.Writer.Schedule(s.opts.Trash.Document(s.store.Snapshot().Trash)); err != nil {
+		s.store.ReportError(state.SubsystemDaemon, fmt.Sprintf("trash: %v", err))
+	}
+}
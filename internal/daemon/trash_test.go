@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/trash"
+)
+
+func TestServerDeleteRuleTrashesAndRestores(t *testing.T) {
+	store := state.NewStore()
+	file := trash.New(t.TempDir() + "/trash.json")
+	srv := New(store, Options{Trash: file})
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return deletedAt }
+	sess := &session{nodeID: "node-1", send: make(chan *pb.Notification, 1)}
+	srv.sessions["node-1"] = sess
+	store.UpsertNode(state.Node{ID: "node-1", Name: "laptop"})
+	store.SetRules("node-1", []state.Rule{{
+		Name:     "ssh",
+		Action:   "allow",
+		Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+	}})
+
+	if err := srv.DeleteRule("node-1", "ssh", ""); err != nil {
+		t.Fatalf("DeleteRule error: %v", err)
+	}
+	if notif := <-sess.send; notif.Type != pb.Action_DELETE_RULE {
+		t.Fatalf("expected a delete, got %v", notif.Type)
+	}
+	trashed := store.Snapshot().Trash
+	if len(trashed) != 1 || trashed[0].NodeKey != "name:laptop" || trashed[0].Rule.Operator.Data != "/usr/bin/ssh" || !trashed[0].DeletedAt.Equal(deletedAt) {
+		t.Fatalf("expected the rule in the trash, got %+v", trashed)
+	}
+	if saved, err := file.Load(); err != nil || len(saved) != 1 {
+		t.Fatalf("expected the trash saved, got %+v, %v", saved, err)
+	}
+
+	if err := srv.RestoreRule("node-1", "ssh"); err != nil {
+		t.Fatalf("RestoreRule error: %v", err)
+	}
+	notif := <-sess.send
+	if notif.Type != pb.Action_CHANGE_RULE || len(notif.Rules) != 1 || notif.Rules[0].GetOperator().GetData() != "/usr/bin/ssh" {
+		t.Fatalf("expected the rule sent back, got %+v", notif)
+	}
+	snap := store.Snapshot()
+	if len(snap.Trash) != 0 || len(snap.Rules["node-1"]) != 1 {
+		t.Fatalf("expected the rule restored and out of the trash, got %+v / %+v", snap.Trash, snap.Rules)
+	}
+	if saved, err := file.Load(); err != nil || len(saved) != 0 {
+		t.Fatalf("expected the saved trash emptied, got %+v, %v", saved, err)
+	}
+	if err := srv.RestoreRule("node-1", "ssh"); err == nil {
+		t.Fatal("expected an error restoring a rule not in the trash")
+	}
+}
+
+func TestServerDeleteRuleUntrashesWhenNotSent(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Operator: state.RuleOperator{Type: "process"}}})
+
+	if err := srv.DeleteRule("node-1", "ssh", ""); err == nil {
+		t.Fatal("expected an error deleting on a disconnected node")
+	}
+	snap := store.Snapshot()
+	if len(snap.Trash) != 0 || len(snap.Rules["node-1"]) != 1 {
+		t.Fatalf("expected the rule kept and the trash empty, got %+v / %+v", snap.Trash, snap.Rules)
+	}
+}
+
+func TestServerEmptyTrashKeepsOtherNodes(t *testing.T) {
+	store := state.NewStore()
+	srv := New(store, Options{})
+	store.UpsertNode(state.Node{ID: "node-1", Name: "laptop"})
+	store.SetTrash([]state.TrashedRule{
+		{NodeKey: "name:laptop", Rule: state.Rule{Name: "a"}},
+		{NodeKey: "name:server", Rule: state.Rule{Name: "b"}},
+		{NodeKey: "name:laptop", Rule: state.Rule{Name: "c"}},
+	})
+
+	removed, err := srv.EmptyTrash("node-1")
+	if err != nil || removed != 2 {
+		t.Fatalf("expected 2 removed, got %d, %v", removed, err)
+	}
+	if trash := store.Snapshot().Trash; len(trash) != 1 || trash[0].Rule.Name != "b" {
+		t.Fatalf("expected the other node's entry kept, got %+v", trash)
+	}
+}
@@ -0,0 +1,28 @@
+package daemon
+
+import (
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+var wireFormat = prototext.MarshalOptions{Multiline: true, Indent: "  "}
+
+// RuleWire renders the pb.Rule the TUI would send for rule as prototext.
+func (s *Server) RuleWire(rule state.Rule) string {
+	return formatWire(s.serializeRuleFor(rule.NodeID, rule))
+}
+
+// ConnectionWire renders conn as the pb.Connection the daemon reported it as.
+func (s *Server) ConnectionWire(conn state.Connection) string {
+	return formatWire(serializeConnection(conn))
+}
+
+func formatWire(msg proto.Message) string {
+	out, err := wireFormat.Marshal(msg)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return string(out)
+}
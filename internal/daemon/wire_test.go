@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// prototext randomly doubles the space after a colon; squash it so the
+// assertions stay stable.
+var wireSpaces = regexp.MustCompile(`:\s+`)
+
+func normalizeWire(text string) string {
+	return wireSpaces.ReplaceAllString(text, ": ")
+}
+
+func TestRuleWireIncludesNestedOperators(t *testing.T) {
+	srv := New(state.NewStore(), Options{})
+	rule := state.Rule{
+		Name:       "000-curl-to-example",
+		Action:     "deny",
+		Duration:   "always",
+		Enabled:    true,
+		Precedence: true,
+		Operator: state.RuleOperator{
+			Type:    "list",
+			Operand: "list",
+			Children: []state.RuleOperator{
+				{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"},
+				{Type: "regexp", Operand: "dest.host", Data: `.*\.example\.com`, Sensitive: true},
+			},
+		},
+	}
+
+	out := normalizeWire(srv.RuleWire(rule))
+	for _, want := range []string{
+		`name: "000-curl-to-example"`,
+		`action: "deny"`,
+		`precedence: true`,
+		`operator: {`,
+		`list: {`,
+		`operand: "process.path"`,
+		`data: "/usr/bin/curl"`,
+		`operand: "dest.host"`,
+		`sensitive: true`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in prototext:\n%s", want, out)
+		}
+	}
+	if got := strings.Count(out, "list: {"); got != 2 {
+		t.Errorf("expected both child operators, got %d:\n%s", got, out)
+	}
+}
+
+func TestConnectionWire(t *testing.T) {
+	srv := New(state.NewStore(), Options{})
+	conn := state.Connection{
+		Protocol:         "tcp",
+		DstIP:            "93.184.216.34",
+		DstHost:          "example.com",
+		DstPort:          443,
+		ProcessID:        42,
+		ProcessPath:      "/usr/bin/curl",
+		ProcessArgs:      []string{"curl", "https://example.com"},
+		ProcessChecksums: map[string]string{"md5": "abc"},
+	}
+
+	out := normalizeWire(srv.ConnectionWire(conn))
+	for _, want := range []string{
+		`protocol: "tcp"`,
+		`dst_host: "example.com"`,
+		`dst_port: 443`,
+		`process_id: 42`,
+		`process_args: "https://example.com"`,
+		`key: "md5"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in prototext:\n%s", want, out)
+		}
+	}
+}
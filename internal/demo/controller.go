@@ -0,0 +1,427 @@
+package demo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Controller applies rule, prompt and firewall actions straight to the
+// store, standing in for connected daemons. Like the real server it refuses
+// actions on nodes that are not connected.
+type Controller struct {
+	store *state.Store
+	now   func() time.Time
+
+	mu     sync.Mutex
+	pauses map[string]*time.Timer
+}
+
+var (
+	_ controller.RuleManager     = (*Controller)(nil)
+	_ controller.PromptManager   = (*Controller)(nil)
+	_ controller.FirewallManager = (*Controller)(nil)
+	_ controller.BaselineManager = (*Controller)(nil)
+	_ controller.RuleExporter    = (*Controller)(nil)
+	_ controller.RuleImporter    = (*Controller)(nil)
+)
+
+// NewController returns a controller acting on store.
+func NewController(store *state.Store) *Controller {
+	return &Controller{store: store, now: time.Now, pauses: make(map[string]*time.Timer)}
+}
+
+func (c *Controller) connected(nodeID string) error {
+	for _, node := range c.store.Snapshot().Nodes {
+		if node.ID == nodeID && node.Status == state.NodeStatusReady {
+			return nil
+		}
+	}
+	return fmt.Errorf("node %s not connected", nodeID)
+}
+
+// check fails unless nodeID is connected and its rule still matches hash.
+func (c *Controller) check(nodeID, ruleName, hash string) error {
+	if err := c.connected(nodeID); err != nil {
+		return err
+	}
+	rule, ok := ruleset.Lookup(c.store.Snapshot().Rules, nodeID, ruleName)
+	if !ok {
+		return fmt.Errorf("rule %s not found for %s", ruleName, nodeID)
+	}
+	if hash != "" && ruleset.Hash(rule) != hash {
+		return &controller.RuleConflictError{NodeID: nodeID, Rule: ruleName}
+	}
+	return nil
+}
+
+func (c *Controller) updateRule(action, nodeID, ruleName, hash string, fn func(*state.Rule)) error {
+	if err := c.check(nodeID, ruleName, hash); err != nil {
+		return err
+	}
+	if !c.store.UpdateRule(nodeID, ruleName, fn) {
+		return fmt.Errorf("rule %s not found for %s", ruleName, nodeID)
+	}
+	c.ack(action, nodeID, ruleName)
+	return nil
+}
+
+// demoAckLatency is the round trip reported for every demo action.
+const demoAckLatency = 40 * time.Millisecond
+
+// ack records the reply a daemon would send for an applied rule action.
+func (c *Controller) ack(action, nodeID, ruleName string) {
+	c.store.RecordAck(state.ActionAck{NodeID: nodeID, Action: action, Rule: ruleName, SentAt: c.now(), Latency: demoAckLatency})
+}
+
+// EnableRule implements controller.RuleManager.
+func (c *Controller) EnableRule(nodeID, ruleName, hash string) error {
+	return c.updateRule("enable", nodeID, ruleName, hash, func(r *state.Rule) { r.Enabled = true })
+}
+
+// DisableRule implements controller.RuleManager.
+func (c *Controller) DisableRule(nodeID, ruleName, hash string) error {
+	return c.updateRule("disable", nodeID, ruleName, hash, func(r *state.Rule) { r.Enabled = false })
+}
+
+// DeleteRule implements controller.RuleManager.
+func (c *Controller) DeleteRule(nodeID, ruleName, hash string) error {
+	if err := c.check(nodeID, ruleName, hash); err != nil {
+		return err
+	}
+	if !c.store.RemoveRule(nodeID, ruleName) {
+		return fmt.Errorf("rule %s not found for %s", ruleName, nodeID)
+	}
+	c.ack("delete", nodeID, ruleName)
+	return nil
+}
+
+// ChangeRule implements controller.RuleManager.
+func (c *Controller) ChangeRule(nodeID string, rule state.Rule, hash string) error {
+	if rule.Name == "" {
+		return errors.New("rule name required")
+	}
+	if err := ruleset.LimitsFor(c.store.Snapshot().Settings).Validate(rule); err != nil {
+		return err
+	}
+	return c.updateRule("change", nodeID, rule.Name, hash, func(r *state.Rule) { *r = rule })
+}
+
+// AddRule implements controller.RuleManager.
+func (c *Controller) AddRule(nodeID string, rule state.Rule) error {
+	if err := c.connected(nodeID); err != nil {
+		return err
+	}
+	snap := c.store.Snapshot()
+	if _, exists := ruleset.Lookup(snap.Rules, nodeID, rule.Name); exists {
+		return fmt.Errorf("rule %s already exists on %s", rule.Name, nodeID)
+	}
+	if err := ruleset.LimitsFor(snap.Settings).Validate(rule); err != nil {
+		return err
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = c.now()
+	}
+	c.store.AddRule(nodeID, rule)
+	c.ack("change", nodeID, rule.Name)
+	return nil
+}
+
+func (c *Controller) prompt(id string) (state.Prompt, error) {
+	for _, prompt := range c.store.Snapshot().Prompts {
+		if prompt.ID == id {
+			return prompt, nil
+		}
+	}
+	return state.Prompt{}, fmt.Errorf("prompt %s not found", id)
+}
+
+// ResolvePrompt implements controller.PromptManager. The answer becomes a
+// rule on the prompting node, as it would with a real daemon.
+func (c *Controller) ResolvePrompt(decision controller.PromptDecision) error {
+	if decision.PromptID == "" {
+		return fmt.Errorf("prompt id required")
+	}
+	prompt, err := c.prompt(decision.PromptID)
+	if err != nil {
+		return err
+	}
+	return c.resolve(prompt, decision)
+}
+
+// Expire answers the prompts that ran out by now with the default
+// decision, the way a daemon's prompt timeout does. Paused prompts wait.
+func (c *Controller) Expire(now time.Time) {
+	settings := c.store.Snapshot().Settings
+	for _, prompt := range c.store.Snapshot().Prompts {
+		if prompt.Paused || now.Before(prompt.ExpiresAt) {
+			continue
+		}
+		decision := controller.PromptDecision{
+			PromptID: prompt.ID,
+			Action:   controller.PromptAction(config.NormalizePromptAction(settings.DefaultPromptAction)),
+			Duration: controller.PromptDuration(config.NormalizePromptDuration(settings.DefaultPromptDuration)),
+			Target:   controller.PromptTarget(settings.DefaultPromptTarget),
+			Source:   state.DecisionSourceTimeout,
+		}
+		if decision.Target == controller.PromptTargetDestinationHost && prompt.Connection.DstHost == "" {
+			decision.Target = controller.PromptTargetDestinationIP
+		}
+		if err := c.resolve(prompt, decision); err == nil {
+			c.store.ReportError(state.SubsystemDaemon, fmt.Sprintf("prompt timed out for %s", prompt.Connection.ProcessPath))
+		}
+	}
+}
+
+func (c *Controller) resolve(prompt state.Prompt, decision controller.PromptDecision) error {
+	if decision.Target == "" {
+		decision.Target = controller.PromptTargetProcessPath
+	}
+	snap := c.store.Snapshot()
+	op, err := targetOperator(prompt.Connection, decision.Target, snap.Settings.UIDZeroUnknown)
+	if err != nil {
+		return err
+	}
+	existing := make([]string, 0, len(snap.Rules[prompt.NodeID]))
+	for _, r := range snap.Rules[prompt.NodeID] {
+		existing = append(existing, r.Name)
+	}
+	limits := ruleset.LimitsFor(snap.Settings)
+	rule := state.Rule{
+		NodeID: prompt.NodeID,
+		Name: ruleset.GenerateName(snap.Settings.RuleNameTemplate, ruleset.NameParts{
+			Action:   string(decision.Action),
+			Duration: string(decision.Duration),
+			Type:     op.Type,
+			Target:   string(decision.Target),
+			Data:     op.Data,
+		}, existing, limits.Text),
+		Action:    string(decision.Action),
+		Duration:  string(decision.Duration),
+		Enabled:   true,
+		CreatedAt: c.now(),
+		Operator:  op,
+	}
+	rule.Description = strings.TrimSpace(decision.Description)
+	if rule.Description == "" {
+		fields := ruleset.DescriptionFieldsFor(prompt, snap.Settings.UIDZeroUnknown, rule.CreatedAt)
+		rule.Description = ruleset.RenderDescription(snap.Settings.RuleDescriptionTemplate, fields, limits.Text)
+	}
+	if err := limits.Validate(rule); err != nil {
+		return err
+	}
+	if !c.store.RemovePrompt(prompt.ID) {
+		return fmt.Errorf("prompt %s already resolved", prompt.ID)
+	}
+	record(c.store, prompt, decision, rule, c.now())
+	return nil
+}
+
+// record adds the rule made from a prompt answer and logs the decision.
+func record(store *state.Store, prompt state.Prompt, decision controller.PromptDecision, rule state.Rule, at time.Time) {
+	store.AddRuleFrom(prompt.NodeID, rule, state.RuleChangePrompt)
+	source := decision.Source
+	if source == "" {
+		source = state.DecisionSourceUser
+	}
+	store.AddDecision(state.Decision{
+		PromptID:   prompt.ID,
+		NodeID:     prompt.NodeID,
+		NodeName:   prompt.NodeName,
+		Connection: prompt.Connection,
+		Action:     rule.Action,
+		Duration:   rule.Duration,
+		RuleName:   rule.Name,
+		Source:     source,
+		PromptedAt: prompt.RequestedAt,
+		ResolvedAt: at,
+	})
+}
+
+func targetOperator(conn state.Connection, target controller.PromptTarget, uidZeroUnknown bool) (state.RuleOperator, error) {
+	switch target {
+	case controller.PromptTargetProcessPath:
+		return simple("process.path", conn.ProcessPath), nil
+	case controller.PromptTargetProcessCmd:
+		return simple("process.command", strings.Join(conn.ProcessArgs, " ")), nil
+	case controller.PromptTargetProcessID:
+		if !conn.PIDKnown() {
+			return state.RuleOperator{}, fmt.Errorf("process id unknown")
+		}
+		return simple("process.id", fmt.Sprintf("%d", conn.ProcessID)), nil
+	case controller.PromptTargetUserID:
+		if !conn.UIDKnown(uidZeroUnknown) {
+			return state.RuleOperator{}, fmt.Errorf("user id unknown")
+		}
+		return simple("user.id", fmt.Sprintf("%d", conn.UserID)), nil
+	case controller.PromptTargetDestinationIP:
+		return simple("dest.ip", conn.DstIP), nil
+	case controller.PromptTargetDestinationHost:
+		if conn.DstHost == "" {
+			return state.RuleOperator{}, fmt.Errorf("destination host unavailable")
+		}
+		return simple("dest.host", conn.DstHost), nil
+	case controller.PromptTargetDestinationPort:
+		return simple("dest.port", fmt.Sprintf("%d", conn.DstPort)), nil
+	case controller.PromptTargetSourceIP:
+		return simple("source.ip", conn.SrcIP), nil
+	}
+	return state.RuleOperator{}, fmt.Errorf("unsupported target %s", target)
+}
+
+// PausePrompt implements controller.PromptManager.
+func (c *Controller) PausePrompt(promptID string) error {
+	prompt, err := c.prompt(promptID)
+	if err != nil {
+		return err
+	}
+	if prompt.Paused {
+		return nil
+	}
+	remaining := max(0, prompt.ExpiresAt.Sub(c.now()))
+	c.store.UpdatePrompt(promptID, func(p *state.Prompt) {
+		p.Paused = true
+		p.Remaining = remaining
+	})
+	return nil
+}
+
+// ResumePrompt implements controller.PromptManager.
+func (c *Controller) ResumePrompt(promptID string) error {
+	prompt, err := c.prompt(promptID)
+	if err != nil {
+		return err
+	}
+	if !prompt.Paused {
+		return nil
+	}
+	expires := c.now().Add(prompt.Remaining)
+	c.store.UpdatePrompt(promptID, func(p *state.Prompt) {
+		p.Paused = false
+		p.Remaining = 0
+		p.ExpiresAt = expires
+	})
+	return nil
+}
+
+// EnableFirewall implements controller.FirewallManager.
+func (c *Controller) EnableFirewall(nodeID string) error {
+	c.cancelPause(nodeID)
+	return c.setFirewall(nodeID, true)
+}
+
+// DisableFirewall implements controller.FirewallManager.
+func (c *Controller) DisableFirewall(nodeID string) error {
+	c.cancelPause(nodeID)
+	return c.setFirewall(nodeID, false)
+}
+
+// PauseFirewall implements controller.FirewallManager.
+func (c *Controller) PauseFirewall(nodeID string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("pause duration must be positive")
+	}
+	if err := c.setFirewall(nodeID, false); err != nil {
+		return err
+	}
+	until := c.now().Add(d)
+	c.mu.Lock()
+	if prev := c.pauses[nodeID]; prev != nil {
+		prev.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		c.mu.Lock()
+		current := c.pauses[nodeID] == timer
+		if current {
+			delete(c.pauses, nodeID)
+		}
+		c.mu.Unlock()
+		if current {
+			_ = c.EnableFirewall(nodeID)
+		}
+	})
+	c.pauses[nodeID] = timer
+	c.mu.Unlock()
+
+	c.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallResumeAt = until })
+	return nil
+}
+
+func (c *Controller) cancelPause(nodeID string) {
+	c.mu.Lock()
+	timer := c.pauses[nodeID]
+	delete(c.pauses, nodeID)
+	c.mu.Unlock()
+	if timer != nil {
+		timer.Stop()
+	}
+	c.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallResumeAt = time.Time{} })
+}
+
+func (c *Controller) setFirewall(nodeID string, enabled bool) error {
+	if err := c.connected(nodeID); err != nil {
+		return err
+	}
+	c.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallEnabled, n.FirewallKnown = enabled, true })
+	return nil
+}
+
+// MarkBaseline implements controller.BaselineManager; demo baselines last
+// for the session.
+func (c *Controller) MarkBaseline(nodeID string) (state.Baseline, error) {
+	return c.store.MarkBaseline(nodeID, c.now())
+}
+
+// ClearBaseline implements controller.BaselineManager.
+func (c *Controller) ClearBaseline(nodeID string) (bool, error) {
+	for _, node := range c.store.Snapshot().Nodes {
+		if node.ID == nodeID {
+			return c.store.ClearBaseline(state.NodeKey(node)), nil
+		}
+	}
+	return false, fmt.Errorf("node %s not found", nodeID)
+}
+
+// ExportRules implements controller.RuleExporter; the demo rules are
+// written like a daemon's.
+func (c *Controller) ExportRules(nodeID, dir string) (int, error) {
+	rules, ok := c.store.Snapshot().Rules[nodeID]
+	if !ok {
+		return 0, fmt.Errorf("node %s not found", nodeID)
+	}
+	return ruleset.WriteFiles(dir, rules)
+}
+
+// ImportRules implements controller.RuleImporter, one rule at a time.
+func (c *Controller) ImportRules(nodeID, path string) (controller.ImportResult, error) {
+	if err := c.connected(nodeID); err != nil {
+		return controller.ImportResult{}, err
+	}
+	files, skipped, err := ruleset.ReadFiles(path)
+	if err != nil {
+		return controller.ImportResult{}, err
+	}
+	result := controller.ImportResult{Skipped: skipped}
+	for _, f := range files {
+		rule := f.Rule(nodeID)
+		if _, exists := ruleset.Lookup(c.store.Snapshot().Rules, nodeID, rule.Name); exists {
+			err = c.ChangeRule(nodeID, rule, "")
+		} else {
+			err = c.AddRule(nodeID, rule)
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Errorf("%s: %w", rule.Name, err))
+			continue
+		}
+		result.Imported++
+	}
+	return result, nil
+}
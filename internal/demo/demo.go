@@ -0,0 +1,405 @@
+// Package demo fills the store with a synthetic OpenSnitch setup for -demo
+// runs and screenshots: three nodes, a rule set, a trickle of events with
+// stats to match, a couple of alerts and the occasional prompt. Everything
+// is derived from a seed, so the same seed and start time always produce
+// the same data.
+package demo
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// DefaultSeed is used when -demo is given without -demo-seed.
+const DefaultSeed int64 = 1
+
+// Node IDs of the demo setup.
+const (
+	LaptopID = "unix:///tmp/osui.sock"
+	ServerID = "tcp://10.0.0.12:50051"
+	NASID    = "tcp://10.0.0.31:50051"
+)
+
+const (
+	daemonVersion = "1.6.6"
+	timeLayout    = "2006-01-02 15:04:05"
+)
+
+// Generator produces the demo data. It is not safe for concurrent use.
+type Generator struct {
+	rng   *rand.Rand
+	start time.Time
+	nodes []state.Node
+	apps  map[string][]app
+	rules map[string][]state.Rule
+	// appRules holds the rule matching each app, by node and app index.
+	appRules map[string][]state.Rule
+	counts   map[string]*counters
+	prompts  int
+}
+
+// counters accumulate what a node's stats report.
+type counters struct {
+	connections, accepted, dropped, hits uint64
+	hosts, ports, executables, users     map[string]uint64
+}
+
+func newCounters() *counters {
+	return &counters{
+		hosts:       make(map[string]uint64),
+		ports:       make(map[string]uint64),
+		executables: make(map[string]uint64),
+		users:       make(map[string]uint64),
+	}
+}
+
+// NewGenerator returns a generator whose timestamps are anchored at start.
+func NewGenerator(seed int64, start time.Time) *Generator {
+	g := &Generator{
+		rng:      rand.New(rand.NewSource(seed)),
+		start:    start,
+		apps:     map[string][]app{LaptopID: laptopApps, ServerID: serverApps},
+		rules:    make(map[string][]state.Rule),
+		appRules: make(map[string][]state.Rule),
+		counts:   map[string]*counters{LaptopID: newCounters(), ServerID: newCounters()},
+	}
+	g.nodes = []state.Node{
+		{
+			ID:              LaptopID,
+			Name:            "laptop",
+			Address:         "/tmp/osui.sock",
+			Version:         daemonVersion,
+			FirewallEnabled: true,
+			FirewallKnown:   true,
+			Status:          state.NodeStatusReady,
+			Message:         "last ping",
+			LastSeen:        start,
+		},
+		{
+			ID:              ServerID,
+			Name:            "build-01",
+			Address:         "10.0.0.12:50051",
+			Version:         daemonVersion,
+			FirewallEnabled: false,
+			FirewallKnown:   true,
+			Status:          state.NodeStatusReady,
+			Message:         "last ping",
+			LastSeen:        start,
+		},
+		{
+			ID:              NASID,
+			Name:            "nas",
+			Address:         "10.0.0.31:50051",
+			Version:         "1.5.2",
+			FirewallEnabled: true,
+			FirewallKnown:   true,
+			Status:          state.NodeStatusDisconnected,
+			Message:         "connection reset by peer",
+			LastSeen:        start.Add(-17 * time.Minute),
+		},
+	}
+	for _, nodeID := range []string{LaptopID, ServerID} {
+		for _, a := range g.apps[nodeID] {
+			rule := g.addRule(nodeID, ruleSpec{
+				action:   a.action,
+				duration: "always",
+				op:       simple("process.path", a.path),
+				nolog:    a.uid == 101,
+			})
+			g.appRules[nodeID] = append(g.appRules[nodeID], rule)
+		}
+	}
+	for _, nodeID := range []string{LaptopID, ServerID, NASID} {
+		for _, spec := range extraRules[nodeID] {
+			g.addRule(nodeID, spec)
+		}
+	}
+	for nodeID := range g.rules {
+		sort.Slice(g.rules[nodeID], func(i, j int) bool { return g.rules[nodeID][i].Name < g.rules[nodeID][j].Name })
+	}
+	return g
+}
+
+// Nodes returns the demo nodes: two connected, one of them with its
+// firewall off, and one that dropped off a while ago.
+func (g *Generator) Nodes() []state.Node {
+	return append([]state.Node(nil), g.nodes...)
+}
+
+// Rules returns the rules of a node in name order.
+func (g *Generator) Rules(nodeID string) []state.Rule {
+	return append([]state.Rule(nil), g.rules[nodeID]...)
+}
+
+// Events returns n events seen at, spread over the second before it.
+func (g *Generator) Events(at time.Time, n int) []state.Event {
+	events := make([]state.Event, 0, n)
+	for i := 0; i < n; i++ {
+		nodeID := LaptopID
+		if g.rng.Intn(10) >= 7 {
+			nodeID = ServerID
+		}
+		apps := g.apps[nodeID]
+		idx := g.rng.Intn(len(apps))
+		a := apps[idx]
+		d := a.dests[g.rng.Intn(len(a.dests))]
+		ts := at.Add(-time.Duration(g.rng.Intn(1000)) * time.Millisecond)
+		ev := state.Event{
+			NodeID:     nodeID,
+			Time:       ts.Format(timeLayout),
+			UnixNano:   ts.UnixNano(),
+			Connection: g.connection(a, d, nodeID),
+			Rule:       g.appRules[nodeID][idx],
+		}
+		g.count(nodeID, ev)
+		events = append(events, ev)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].UnixNano < events[j].UnixNano })
+	return events
+}
+
+// burst picks how many events arrive on one tick: usually one or two,
+// sometimes none, now and then a handful.
+func (g *Generator) burst() int {
+	switch n := g.rng.Intn(10); {
+	case n < 2:
+		return 0
+	case n < 8:
+		return 1 + n%2
+	default:
+		return 3 + g.rng.Intn(3)
+	}
+}
+
+func (g *Generator) connection(a app, d dest, nodeID string) state.Connection {
+	src := "192.168.1.23"
+	if nodeID == ServerID {
+		src = "10.0.0.12"
+	}
+	conn := state.Connection{
+		Protocol:    d.protocol,
+		SrcIP:       src,
+		SrcPort:     uint32(32768 + g.rng.Intn(28000)),
+		DstIP:       d.ip,
+		DstHost:     d.host,
+		DstPort:     d.port,
+		UserID:      a.uid,
+		ProcessID:   uint32(1200 + g.rng.Intn(60000)),
+		ProcessPath: a.path,
+		ProcessCWD:  "/",
+		ProcessArgs: append([]string(nil), a.args...),
+	}
+	return conn
+}
+
+func (g *Generator) count(nodeID string, ev state.Event) {
+	c := g.counts[nodeID]
+	c.connections++
+	c.hits++
+	if ev.Rule.Action == "allow" {
+		c.accepted++
+	} else {
+		c.dropped++
+	}
+	conn := ev.Connection
+	host := conn.DstHost
+	if host == "" {
+		host = conn.DstIP
+	}
+	c.hosts[host]++
+	c.ports[fmt.Sprintf("%d", conn.DstPort)]++
+	c.executables[conn.ProcessPath]++
+	c.users[userLabel(conn.UserID)]++
+}
+
+func userLabel(uid uint32) string {
+	if name, ok := users[uid]; ok {
+		return fmt.Sprintf("%s (%d)", name, uid)
+	}
+	return fmt.Sprintf("%d", uid)
+}
+
+// Stats returns the node's statistics covering every event generated so far.
+func (g *Generator) Stats(nodeID string, at time.Time) state.Stats {
+	stats := state.Stats{NodeID: nodeID, DaemonVersion: daemonVersion, UpdatedAt: at}
+	for _, node := range g.nodes {
+		if node.ID == nodeID {
+			stats.NodeName = node.Name
+		}
+	}
+	stats.Rules = uint64(len(g.rules[nodeID]))
+	c := g.counts[nodeID]
+	if c == nil {
+		return stats
+	}
+	stats.Connections = c.connections
+	stats.Accepted = c.accepted
+	stats.Dropped = c.dropped
+	stats.RuleHits = c.hits
+	stats.TopDestHosts = top(c.hosts)
+	stats.TopDestPorts = top(c.ports)
+	stats.TopExecutables = top(c.executables)
+	stats.TopUsers = top(c.users)
+	return stats
+}
+
+func top(values map[string]uint64) []state.StatBucket {
+	buckets := make([]state.StatBucket, 0, len(values))
+	for label, value := range values {
+		buckets = append(buckets, state.StatBucket{Label: label, Value: value})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Value == buckets[j].Value {
+			return buckets[i].Label < buckets[j].Label
+		}
+		return buckets[i].Value > buckets[j].Value
+	})
+	if len(buckets) > 5 {
+		buckets = buckets[:5]
+	}
+	return buckets
+}
+
+// Alerts returns the alerts the demo starts with.
+func (g *Generator) Alerts() []state.Alert {
+	return []state.Alert{
+		{
+			ID:        "1",
+			NodeID:    ServerID,
+			Text:      "eBPF process monitor unavailable (kernel 5.4.0 lacks BTF); falling back to /proc",
+			Priority:  "MEDIUM",
+			Type:      "WARNING",
+			Action:    "SHOW_ALERT",
+			CreatedAt: g.start.Add(-42 * time.Minute),
+		},
+		{
+			ID:        "2",
+			NodeID:    LaptopID,
+			Text:      "Connection to pool.minexmr.com:4444 denied for /home/alice/.cache/.x/kworkerd",
+			Priority:  "HIGH",
+			Type:      "ERROR",
+			Action:    "SHOW_ALERT",
+			CreatedAt: g.start.Add(-6 * time.Minute),
+		},
+	}
+}
+
+// Prompt returns the next prompt, asked at and expiring after timeout.
+func (g *Generator) Prompt(at time.Time, timeout time.Duration) state.Prompt {
+	a := promptApps[g.prompts%len(promptApps)]
+	g.prompts++
+	d := a.dests[g.rng.Intn(len(a.dests))]
+	return state.Prompt{
+		ID:          fmt.Sprintf("demo-%d", g.prompts),
+		NodeID:      LaptopID,
+		NodeName:    "laptop",
+		Connection:  g.connection(a, d, LaptopID),
+		RequestedAt: at,
+		ExpiresAt:   at.Add(timeout),
+	}
+}
+
+// ruleSpec describes a rule before it is named and dated.
+type ruleSpec struct {
+	action, duration string
+	op               state.RuleOperator
+	precedence       bool
+	disabled         bool
+	nolog            bool
+	description      string
+}
+
+func simple(operand, data string) state.RuleOperator {
+	return state.RuleOperator{Type: ruleset.OperatorSimple, Operand: operand, Data: data}
+}
+
+func regexp(operand, data string) state.RuleOperator {
+	return state.RuleOperator{Type: ruleset.OperatorRegexp, Operand: operand, Data: data}
+}
+
+func network(operand, data string) state.RuleOperator {
+	return state.RuleOperator{Type: ruleset.OperatorNetwork, Operand: operand, Data: data}
+}
+
+func list(children ...state.RuleOperator) state.RuleOperator {
+	return state.RuleOperator{Type: ruleset.OperatorList, Operand: "list", Children: children}
+}
+
+func (g *Generator) addRule(nodeID string, spec ruleSpec) state.Rule {
+	existing := make([]string, 0, len(g.rules[nodeID]))
+	for _, r := range g.rules[nodeID] {
+		existing = append(existing, r.Name)
+	}
+	data := spec.op.Data
+	if spec.op.Type == ruleset.OperatorList {
+		data = "list"
+	}
+	name := ruleset.GenerateName("", ruleset.NameParts{
+		Action:   spec.action,
+		Duration: spec.duration,
+		Type:     spec.op.Type,
+		Target:   spec.op.Operand,
+		Data:     data,
+	}, existing, ruleset.DefaultMaxText)
+	rule := state.Rule{
+		NodeID:      nodeID,
+		Name:        name,
+		Description: spec.description,
+		Action:      spec.action,
+		Duration:    spec.duration,
+		Enabled:     !spec.disabled,
+		Precedence:  spec.precedence,
+		NoLog:       spec.nolog,
+		CreatedAt:   g.start.Add(-time.Duration(1+g.rng.Intn(90*24)) * time.Hour).Truncate(time.Second),
+		Operator:    spec.op,
+	}
+	g.rules[nodeID] = append(g.rules[nodeID], rule)
+	return rule
+}
+
+// extraRules round the rule set out with the other operator types, a few
+// disabled and precedence rules, and the rules of the offline node.
+var extraRules = map[string][]ruleSpec{
+	LaptopID: {
+		{action: "allow", duration: "always", op: regexp("dest.host", `^(.*\.)?mozilla\.(org|net|com)$`)},
+		{action: "allow", duration: "always", op: list(simple("process.path", "/usr/lib/firefox/firefox"), simple("dest.port", "443"))},
+		{action: "allow", duration: "always", op: network("dest.network", "192.168.0.0/16"), precedence: true, description: "home LAN"},
+		{action: "allow", duration: "always", op: network("dest.network", "10.0.0.0/8"), description: "office VPN"},
+		{action: "allow", duration: "always", op: list(simple("dest.port", "53"), simple("protocol", "udp"))},
+		{action: "deny", duration: "always", op: regexp("process.command", `curl .*\| *(ba)?sh`), precedence: true, description: "no curl | sh"},
+		{action: "reject", duration: "always", op: regexp("dest.host", `(^|\.)doubleclick\.net$`)},
+		{action: "deny", duration: "always", op: simple("dest.host", "telemetry.ubuntu.com")},
+		{action: "allow", duration: "always", op: simple("process.path", "/usr/games/steam"), disabled: true},
+		{action: "allow", duration: "until restart", op: simple("dest.host", "ifconfig.me")},
+		{action: "allow", duration: "always", op: list(simple("user.id", "1000"), regexp("dest.host", `\.github(usercontent)?\.com$`))},
+		{action: "deny", duration: "always", op: regexp("process.path", `^/home/[^/]+/\.cache/`), precedence: true, description: "nothing runs from caches"},
+		{action: "allow", duration: "always", op: simple("dest.ip", "1.1.1.1"), disabled: true},
+		{action: "deny", duration: "always", op: list(simple("dest.port", "4444"), simple("protocol", "tcp"))},
+	},
+	ServerID: {
+		{action: "allow", duration: "always", op: network("dest.network", "10.0.0.0/8"), precedence: true, description: "internal network"},
+		{action: "allow", duration: "always", op: regexp("dest.host", `\.ubuntu\.com$`)},
+		{action: "allow", duration: "always", op: list(simple("user.id", "1001"), simple("dest.port", "443"))},
+		{action: "deny", duration: "always", op: simple("dest.port", "25")},
+		{action: "deny", duration: "always", op: regexp("process.path", `^/(tmp|dev/shm)/`), precedence: true},
+		{action: "allow", duration: "always", op: simple("process.path", "/usr/bin/rsync"), disabled: true},
+		{action: "reject", duration: "always", op: network("dest.network", "203.0.113.0/24"), description: "known bad range"},
+		{action: "allow", duration: "always", op: regexp("dest.host", `^(registry|auth|production)\..*docker\.(io|com)$`)},
+	},
+	NASID: {
+		{action: "allow", duration: "always", op: simple("process.path", "/usr/sbin/smbd")},
+		{action: "allow", duration: "always", op: simple("process.path", "/usr/bin/syncthing")},
+		{action: "allow", duration: "always", op: network("dest.network", "192.168.1.0/24"), precedence: true},
+		{action: "allow", duration: "always", op: simple("dest.host", "backup.example.net")},
+		{action: "allow", duration: "always", op: list(simple("process.path", "/usr/bin/rclone"), simple("dest.port", "443"))},
+		{action: "deny", duration: "always", op: regexp("dest.host", `(^|\.)(plex\.tv|plex\.direct)$`)},
+		{action: "allow", duration: "always", op: simple("user.id", "0"), disabled: true},
+		{action: "reject", duration: "always", op: simple("dest.port", "23")},
+		{action: "allow", duration: "always", op: simple("process.path", "/usr/sbin/ntpd")},
+	},
+}
@@ -0,0 +1,165 @@
+package demo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func seeded(t *testing.T, seed int64) *state.Store {
+	t.Helper()
+	store := state.NewStore()
+	Seed(store, NewGenerator(seed, start), start)
+	return store
+}
+
+func TestSeedIsDeterministic(t *testing.T) {
+	a, b := seeded(t, 7).Snapshot(), seeded(t, 7).Snapshot()
+	if !reflect.DeepEqual(a.Events, b.Events) || !reflect.DeepEqual(a.Rules, b.Rules) || !reflect.DeepEqual(a.Stats, b.Stats) {
+		t.Fatal("same seed produced different data")
+	}
+	if c := seeded(t, 8).Snapshot(); reflect.DeepEqual(a.Events, c.Events) {
+		t.Fatal("different seeds produced the same events")
+	}
+}
+
+func TestSeedCoversTheViews(t *testing.T) {
+	snap := seeded(t, DefaultSeed).Snapshot()
+
+	statuses := make(map[state.NodeStatus]int)
+	for _, node := range snap.Nodes {
+		statuses[node.Status]++
+	}
+	if len(snap.Nodes) != 3 || statuses[state.NodeStatusReady] != 2 || statuses[state.NodeStatusDisconnected] != 1 {
+		t.Fatalf("unexpected nodes: %+v", snap.Nodes)
+	}
+
+	total := 0
+	types := make(map[string]bool)
+	for _, rules := range snap.Rules {
+		total += len(rules)
+		for _, rule := range rules {
+			types[rule.Operator.Type] = true
+			if err := ruleset.LimitsFor(state.Settings{}).Validate(rule); err != nil {
+				t.Errorf("rule %s invalid: %v", rule.Name, err)
+			}
+		}
+	}
+	if total < 40 || total > 60 {
+		t.Fatalf("expected about 50 rules, got %d", total)
+	}
+	for _, typ := range []string{ruleset.OperatorSimple, ruleset.OperatorRegexp, ruleset.OperatorNetwork, ruleset.OperatorList} {
+		if !types[typ] {
+			t.Errorf("no %s rule generated", typ)
+		}
+	}
+
+	if len(snap.Events) == 0 || len(snap.Alerts) != 2 {
+		t.Fatalf("expected events and 2 alerts, got %d and %d", len(snap.Events), len(snap.Alerts))
+	}
+	if snap.Stats.NodeID != LaptopID || snap.Stats.Connections == 0 || len(snap.Stats.TopDestHosts) == 0 {
+		t.Fatalf("unexpected stats: %+v", snap.Stats)
+	}
+}
+
+func TestControllerActsOnStore(t *testing.T) {
+	store := seeded(t, DefaultSeed)
+	ctrl := NewController(store)
+	name := store.Snapshot().Rules[LaptopID][0].Name
+
+	if err := ctrl.DisableRule(LaptopID, name, ""); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	if store.Snapshot().Rules[LaptopID][0].Enabled {
+		t.Fatal("rule still enabled")
+	}
+	if err := ctrl.DeleteRule(LaptopID, name, ""); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	for _, rule := range store.Snapshot().Rules[LaptopID] {
+		if rule.Name == name {
+			t.Fatal("rule not deleted")
+		}
+	}
+
+	nasRule := store.Snapshot().Rules[NASID][0].Name
+	if err := ctrl.DisableRule(NASID, nasRule, ""); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Fatalf("expected not connected error, got %v", err)
+	}
+
+	if err := ctrl.PauseFirewall(LaptopID, time.Minute); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	node := store.Snapshot().Nodes[0]
+	if node.FirewallEnabled || node.FirewallResumeAt.IsZero() {
+		t.Fatalf("firewall not paused: %+v", node)
+	}
+	if err := ctrl.EnableFirewall(LaptopID); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	if node := store.Snapshot().Nodes[0]; !node.FirewallEnabled || !node.FirewallResumeAt.IsZero() {
+		t.Fatalf("firewall not re-enabled: %+v", node)
+	}
+}
+
+func TestControllerResolvesAndExpiresPrompts(t *testing.T) {
+	store := seeded(t, DefaultSeed)
+	gen := NewGenerator(DefaultSeed, start)
+	ctrl := NewController(store)
+	ctrl.now = func() time.Time { return start }
+
+	answered := gen.Prompt(start, PromptTimeout)
+	store.AddPrompt(answered)
+	err := ctrl.ResolvePrompt(controller.PromptDecision{
+		PromptID: answered.ID,
+		Action:   controller.PromptActionAllow,
+		Duration: controller.PromptDurationAlways,
+		Target:   controller.PromptTargetProcessPath,
+	})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	snap := store.Snapshot()
+	if len(snap.Prompts) != 0 || len(snap.Decisions) != 1 {
+		t.Fatalf("expected the prompt answered, got %d prompts and %d decisions", len(snap.Prompts), len(snap.Decisions))
+	}
+	if snap.Decisions[0].Source != state.DecisionSourceUser {
+		t.Fatalf("unexpected source %q", snap.Decisions[0].Source)
+	}
+	found := false
+	for _, rule := range snap.Rules[LaptopID] {
+		if rule.Name == snap.Decisions[0].RuleName && rule.Operator.Data == answered.Connection.ProcessPath {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("rule %q not added", snap.Decisions[0].RuleName)
+	}
+
+	paused := gen.Prompt(start, PromptTimeout)
+	expiring := gen.Prompt(start, PromptTimeout)
+	store.AddPrompt(paused)
+	store.AddPrompt(expiring)
+	if err := ctrl.PausePrompt(paused.ID); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	ctrl.Expire(start.Add(PromptTimeout - time.Second))
+	if got := len(store.Snapshot().Prompts); got != 2 {
+		t.Fatalf("prompts expired early: %d left", got)
+	}
+	ctrl.Expire(start.Add(PromptTimeout))
+	snap = store.Snapshot()
+	if len(snap.Prompts) != 1 || snap.Prompts[0].ID != paused.ID {
+		t.Fatalf("expected only the paused prompt left, got %+v", snap.Prompts)
+	}
+	if snap.Decisions[0].PromptID != expiring.ID || snap.Decisions[0].Source != state.DecisionSourceTimeout {
+		t.Fatalf("unexpected timeout decision: %+v", snap.Decisions[0])
+	}
+}
@@ -0,0 +1,72 @@
+package demo
+
+import (
+	"context"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Pacing of the running demo.
+const (
+	EventInterval  = 1500 * time.Millisecond
+	StatsInterval  = 5 * time.Second
+	PromptInterval = 45 * time.Second
+	PromptTimeout  = 30 * time.Second
+
+	// backfill is how much history Seed loads so the tables are not empty
+	// on launch.
+	backfill     = 5 * time.Minute
+	backfillStep = 5 * time.Second
+)
+
+// Seed loads the demo's starting state into store: nodes, rules, alerts,
+// the last few minutes of events and the laptop's stats.
+func Seed(store *state.Store, gen *Generator, now time.Time) {
+	store.SetNodes(gen.Nodes())
+	for _, nodeID := range []string{LaptopID, ServerID, NASID} {
+		store.SetRules(nodeID, gen.Rules(nodeID))
+	}
+	for _, alert := range gen.Alerts() {
+		store.AddAlert(alert)
+	}
+	var events []state.Event
+	for at := now.Add(-backfill); !at.After(now); at = at.Add(backfillStep) {
+		events = append(events, gen.Events(at, 2)...)
+	}
+	store.MergeEvents(events)
+	store.SetStats(gen.Stats(LaptopID, now))
+}
+
+// Run keeps the demo moving until ctx is done: events trickle in, the
+// connected nodes ping and report stats, and a prompt is asked every
+// PromptInterval that times out after PromptTimeout unless answered.
+func Run(ctx context.Context, store *state.Store, gen *Generator, ctrl *Controller) error {
+	events := time.NewTicker(EventInterval)
+	defer events.Stop()
+	stats := time.NewTicker(StatsInterval)
+	defer stats.Stop()
+	prompts := time.NewTicker(PromptInterval)
+	defer prompts.Stop()
+	expiry := time.NewTicker(time.Second)
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-events.C:
+			store.MergeEvents(gen.Events(now, gen.burst()))
+		case now := <-stats.C:
+			store.RecordPing(LaptopID, now)
+			store.RecordPing(ServerID, now)
+			snap := gen.Stats(LaptopID, now)
+			snap.Rules = uint64(len(store.Snapshot().Rules[LaptopID]))
+			store.SetStats(snap)
+		case now := <-prompts.C:
+			store.AddPrompt(gen.Prompt(now, PromptTimeout))
+		case now := <-expiry.C:
+			ctrl.Expire(now)
+		}
+	}
+}
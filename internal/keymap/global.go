@@ -13,6 +13,19 @@ type Global struct {
 	Help     key.Binding
 	NextView key.Binding
 	PrevView key.Binding
+	DND      key.Binding
+	// RefreshTheme re-detects the terminal background for the auto theme.
+	RefreshTheme key.Binding
+	// Log shows, focuses and hides the session log pane.
+	Log key.Binding
+	// Profile applies the next workspace profile; SaveProfile saves the
+	// current layout as one.
+	Profile     key.Binding
+	SaveProfile key.Binding
+	// About opens the build and runtime information overlay.
+	About key.Binding
+	// AckWarning dismisses the security warning banner.
+	AckWarning key.Binding
 }
 
 // DefaultGlobal returns the default global key bindings.
@@ -34,12 +47,40 @@ func DefaultGlobal() Global {
 			key.WithKeys("shift+tab"),
 			key.WithHelp("shift+tab", "previous view"),
 		),
+		DND: key.NewBinding(
+			key.WithKeys("ctrl+n"),
+			key.WithHelp("ctrl+n", "do not disturb"),
+		),
+		RefreshTheme: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "re-detect background"),
+		),
+		Log: key.NewBinding(
+			key.WithKeys("ctrl+l"),
+			key.WithHelp("ctrl+l", "log"),
+		),
+		Profile: key.NewBinding(
+			key.WithKeys("ctrl+w"),
+			key.WithHelp("ctrl+w", "next profile"),
+		),
+		SaveProfile: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "save profile"),
+		),
+		About: key.NewBinding(
+			key.WithKeys("f1"),
+			key.WithHelp("f1", "about"),
+		),
+		AckWarning: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "acknowledge warning"),
+		),
 	}
 }
 
 // ShortHelp renders a compact help string for the footer.
 func (g Global) ShortHelp() string {
-	bindings := []key.Binding{g.Quit, g.NextView, g.PrevView}
+	bindings := []key.Binding{g.Quit, g.NextView, g.PrevView, g.Help, g.About}
 	snippets := make([]string, 0, len(bindings))
 	for _, binding := range bindings {
 		help := binding.Help()
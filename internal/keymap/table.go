@@ -0,0 +1,82 @@
+package keymap
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Action is a view command bound to keys. C is whatever the view hands
+// to Dispatch, usually the snapshot its Update took.
+type Action[C any] struct {
+	// ID names the action, e.g. "rules.delete".
+	ID   string
+	Keys []string
+	Help string
+	// Enabled, when set, reports whether the action applies right now. A
+	// disabled action leaves its keys to later actions or to the caller.
+	Enabled func(C) bool
+	Run     func(C) tea.Cmd
+}
+
+// Do wraps an action body that returns no command.
+func Do[C any](f func(C)) func(C) tea.Cmd {
+	return func(ctx C) tea.Cmd {
+		f(ctx)
+		return nil
+	}
+}
+
+// Help is an action's line in the key help.
+type Help struct {
+	ID   string
+	Keys []string
+	Desc string
+}
+
+// KeyString joins the keys for display, e.g. "up/k".
+func (h Help) KeyString() string {
+	return strings.Join(h.Keys, "/")
+}
+
+// Table dispatches key presses to a view's actions. Actions are tried in
+// declaration order, so of two enabled actions sharing a key the first
+// one runs.
+type Table[C any] struct {
+	actions []Action[C]
+	byKey   map[string][]int
+}
+
+// NewTable returns a table of actions. Tables are not modified once built,
+// so views share them across models.
+func NewTable[C any](actions ...Action[C]) *Table[C] {
+	t := &Table[C]{actions: append([]Action[C](nil), actions...), byKey: make(map[string][]int)}
+	for i, action := range t.actions {
+		for _, key := range action.Keys {
+			t.byKey[key] = append(t.byKey[key], i)
+		}
+	}
+	return t
+}
+
+// Dispatch runs the first enabled action bound to key and reports whether
+// one ran.
+func (t *Table[C]) Dispatch(key string, ctx C) (tea.Cmd, bool) {
+	for _, i := range t.byKey[key] {
+		action := t.actions[i]
+		if action.Enabled != nil && !action.Enabled(ctx) {
+			continue
+		}
+		return action.Run(ctx), true
+	}
+	return nil, false
+}
+
+// Help lists the actions with their keys, in declaration order.
+func (t *Table[C]) Help() []Help {
+	out := make([]Help, 0, len(t.actions))
+	for _, action := range t.actions {
+		out = append(out, Help{ID: action.ID, Keys: append([]string(nil), action.Keys...), Desc: action.Help})
+	}
+	return out
+}
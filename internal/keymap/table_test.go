@@ -0,0 +1,100 @@
+package keymap
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// counter records which actions ran.
+type counter struct {
+	ran     []string
+	enabled bool
+}
+
+func record(id string) func(*counter) tea.Cmd {
+	return func(c *counter) tea.Cmd {
+		c.ran = append(c.ran, id)
+		return nil
+	}
+}
+
+func testTable() *Table[*counter] {
+	return NewTable(
+		Action[*counter]{ID: "down", Keys: []string{"down", "j"}, Help: "next", Run: record("down")},
+		Action[*counter]{ID: "next", Keys: []string{"n"}, Help: "next option", Enabled: func(c *counter) bool { return c.enabled }, Run: record("next")},
+		Action[*counter]{ID: "fallback", Keys: []string{"n"}, Help: "fallback", Run: record("fallback")},
+		Action[*counter]{ID: "save", Keys: []string{"enter"}, Help: "save", Run: func(c *counter) tea.Cmd {
+			c.ran = append(c.ran, "save")
+			return tea.Quit
+		}},
+	)
+}
+
+func TestDispatchMultiKeyBinding(t *testing.T) {
+	table := testTable()
+	c := &counter{}
+	for _, key := range []string{"down", "j"} {
+		if _, ok := table.Dispatch(key, c); !ok {
+			t.Fatalf("expected %q to be handled", key)
+		}
+	}
+	if !reflect.DeepEqual(c.ran, []string{"down", "down"}) {
+		t.Fatalf("unexpected actions: %v", c.ran)
+	}
+	if _, ok := table.Dispatch("x", c); ok {
+		t.Fatal("expected unbound key to be unhandled")
+	}
+	if cmd, ok := table.Dispatch("enter", c); !ok || cmd == nil {
+		t.Fatal("expected the action's command to be returned")
+	}
+}
+
+func TestDispatchSkipsDisabledActions(t *testing.T) {
+	table := NewTable(
+		Action[*counter]{ID: "next", Keys: []string{"n"}, Enabled: func(c *counter) bool { return c.enabled }, Run: record("next")},
+	)
+	c := &counter{}
+	if _, ok := table.Dispatch("n", c); ok {
+		t.Fatal("expected disabled action to leave the key unhandled")
+	}
+	c.enabled = true
+	if _, ok := table.Dispatch("n", c); !ok || !reflect.DeepEqual(c.ran, []string{"next"}) {
+		t.Fatalf("expected enabled action to run, got %v", c.ran)
+	}
+
+	shared := testTable()
+	c = &counter{}
+	shared.Dispatch("n", c)
+	c.enabled = true
+	shared.Dispatch("n", c)
+	if !reflect.DeepEqual(c.ran, []string{"fallback", "next"}) {
+		t.Fatalf("expected a disabled action to fall through to the next one, got %v", c.ran)
+	}
+}
+
+func TestDoRunsWithoutCommand(t *testing.T) {
+	c := &counter{}
+	if cmd := Do(func(c *counter) { c.ran = append(c.ran, "do") })(c); cmd != nil || !reflect.DeepEqual(c.ran, []string{"do"}) {
+		t.Fatalf("expected the body run without a command, got %v", c.ran)
+	}
+}
+
+func TestHelpListsActionsInOrder(t *testing.T) {
+	table := testTable()
+	help := table.Help()
+	if len(help) != 4 {
+		t.Fatalf("expected every action listed, got %+v", help)
+	}
+	if help[0].ID != "down" || help[0].KeyString() != "down/j" || help[0].Desc != "next" {
+		t.Fatalf("unexpected help: %+v", help[0])
+	}
+	if help[1].ID != "next" || help[2].ID != "fallback" {
+		t.Fatalf("expected declaration order, got %+v", help)
+	}
+	help[0].Keys[0] = "x"
+	if table.Help()[0].Keys[0] != "down" {
+		t.Fatal("expected Help to return copies of the keys")
+	}
+}
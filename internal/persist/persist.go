@@ -0,0 +1,229 @@
+// Package persist writes the small state, history and cache files of the
+// UI in the background. Changes to a document are coalesced, so a burst of
+// updates costs one write, and every file is replaced atomically.
+package persist
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default timings: a document is written once it has been left alone for
+// DefaultDebounce, and at most DefaultMaxDelay after its first change even
+// if changes keep coming.
+const (
+	DefaultDebounce = 2 * time.Second
+	DefaultMaxDelay = 10 * time.Second
+)
+
+// Document is a file kept up to date by a Writer.
+type Document struct {
+	// Name identifies the document: changes to the same name are coalesced
+	// and errors are reported under it.
+	Name string
+	Path string
+	// Marshal returns the file contents. It runs when the write happens,
+	// so the latest scheduled Marshal is the one that counts.
+	Marshal func() ([]byte, error)
+}
+
+// Options tunes a Writer; zero durations take the defaults.
+type Options struct {
+	Debounce time.Duration
+	MaxDelay time.Duration
+	// OnError receives background write failures; nil drops them.
+	OnError func(name string, err error)
+}
+
+// Writer debounces writes of named documents. A nil Writer writes
+// synchronously.
+type Writer struct {
+	debounce time.Duration
+	maxDelay time.Duration
+	onError  func(name string, err error)
+
+	// now and schedule are replaced in tests.
+	now      func() time.Time
+	schedule func(delay time.Duration, fn func()) (stop func())
+
+	mu      sync.Mutex
+	pending map[string]*pendingDoc
+	seq     uint64
+	closed  bool
+	// writeMu serializes disk writes, so a document is never written by a
+	// timer and a flush at once and the later write always wins.
+	writeMu sync.Mutex
+}
+
+type pendingDoc struct {
+	doc Document
+	// first is when the document first changed since its last write;
+	// order keeps flushes in that order.
+	first time.Time
+	order uint64
+	stop  func()
+	token uint64
+}
+
+// New returns a writer with opts.
+func New(opts Options) *Writer {
+	w := &Writer{
+		debounce: opts.Debounce,
+		maxDelay: opts.MaxDelay,
+		onError:  opts.OnError,
+		now:      time.Now,
+		schedule: func(delay time.Duration, fn func()) func() {
+			timer := time.AfterFunc(delay, fn)
+			return func() { timer.Stop() }
+		},
+		pending: make(map[string]*pendingDoc),
+	}
+	if w.debounce <= 0 {
+		w.debounce = DefaultDebounce
+	}
+	if w.maxDelay <= 0 {
+		w.maxDelay = DefaultMaxDelay
+	}
+	w.maxDelay = max(w.maxDelay, w.debounce)
+	return w
+}
+
+// Schedule queues a write of doc, replacing any write of the same name not
+// yet done. Once the writer is closed, or when it is nil, doc is written
+// before Schedule returns and the error is returned; otherwise failures go
+// to Options.OnError.
+func (w *Writer) Schedule(doc Document) error {
+	if w == nil {
+		return Write(doc)
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		w.writeMu.Lock()
+		defer w.writeMu.Unlock()
+		return Write(doc)
+	}
+	defer w.mu.Unlock()
+
+	now := w.now()
+	p, ok := w.pending[doc.Name]
+	if !ok {
+		w.seq++
+		p = &pendingDoc{first: now, order: w.seq}
+		w.pending[doc.Name] = p
+	}
+	p.doc = doc
+	if p.stop != nil {
+		p.stop()
+	}
+	delay := min(w.debounce, p.first.Add(w.maxDelay).Sub(now))
+	p.token++
+	token := p.token
+	name := doc.Name
+	p.stop = w.schedule(max(0, delay), func() { w.fire(name, token) })
+	return nil
+}
+
+// fire runs when a document's timer goes off; a timer replaced after it
+// started running finds its token stale and does nothing.
+func (w *Writer) fire(name string, token uint64) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	w.mu.Lock()
+	p, ok := w.pending[name]
+	if !ok || p.token != token || w.closed {
+		w.mu.Unlock()
+		return
+	}
+	delete(w.pending, name)
+	w.mu.Unlock()
+
+	if err := Write(p.doc); err != nil && w.onError != nil {
+		w.onError(name, err)
+	}
+}
+
+// Close stops the timers and writes every pending document, in the order
+// they first changed. Later Schedule calls write synchronously. The error
+// joins the failed writes, whose changes are lost.
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	w.mu.Lock()
+	w.closed = true
+	docs := make([]*pendingDoc, 0, len(w.pending))
+	for _, p := range w.pending {
+		if p.stop != nil {
+			p.stop()
+		}
+		docs = append(docs, p)
+	}
+	w.pending = make(map[string]*pendingDoc)
+	w.mu.Unlock()
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].order < docs[j].order })
+	var errs []error
+	for _, p := range docs {
+		errs = append(errs, Write(p.doc))
+	}
+	return errors.Join(errs...)
+}
+
+// Write writes doc now.
+func Write(doc Document) error {
+	data, err := doc.Marshal()
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", doc.Name, err)
+	}
+	if err := WriteFile(doc.Path, data); err != nil {
+		return fmt.Errorf("write %s: %w", doc.Name, err)
+	}
+	return nil
+}
+
+// WriteFile replaces path with data, creating its directory. It writes a
+// temporary file beside path and renames it over, so a crash never leaves
+// half a file.
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// StateDir returns the UI's directory under XDG_STATE_HOME
+// (~/.local/state), for files written on request rather than cached.
+func StateDir() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "opensnitch-tui"), nil
+}
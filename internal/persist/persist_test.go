@@ -0,0 +1,302 @@
+package persist
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock runs the writer's timers by hand: advance moves the time and
+// fires the timers that came due, in order.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	fn      func()
+	stopped bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) schedule(delay time.Duration, fn func()) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{at: c.now.Add(delay), fn: fn}
+	c.timers = append(c.timers, timer)
+	return func() {
+		c.mu.Lock()
+		timer.stopped = true
+		c.mu.Unlock()
+	}
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	kept := c.timers[:0]
+	for _, timer := range c.timers {
+		switch {
+		case timer.stopped:
+		case !timer.at.After(c.now):
+			due = append(due, timer)
+		default:
+			kept = append(kept, timer)
+		}
+	}
+	c.timers = kept
+	c.mu.Unlock()
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+	for _, timer := range due {
+		timer.fn()
+	}
+}
+
+func newTestWriter(t *testing.T, opts Options) (*Writer, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	w := New(opts)
+	w.now = clock.Now
+	w.schedule = clock.schedule
+	return w, clock
+}
+
+// counted returns a document whose writes are counted in writes.
+func counted(path, body string, writes *int) Document {
+	return Document{Name: filepath.Base(path), Path: path, Marshal: func() ([]byte, error) {
+		*writes++
+		return []byte(body), nil
+	}}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestScheduleCoalescesUntilQuiet(t *testing.T) {
+	w, clock := newTestWriter(t, Options{})
+	path := filepath.Join(t.TempDir(), "state.json")
+	writes := 0
+
+	for i := range 5 {
+		if err := w.Schedule(counted(path, fmt.Sprintf("v%d", i), &writes)); err != nil {
+			t.Fatal(err)
+		}
+		clock.advance(time.Second)
+	}
+	if writes != 0 {
+		t.Fatalf("expected no write while changes keep coming within the debounce, got %d", writes)
+	}
+	clock.advance(time.Second)
+	if writes != 1 || readFile(t, path) != "v4" {
+		t.Fatalf("expected one write of the latest version, got %d writes of %q", writes, readFile(t, path))
+	}
+	clock.advance(time.Minute)
+	if writes != 1 {
+		t.Fatalf("expected nothing more to write, got %d writes", writes)
+	}
+}
+
+func TestScheduleWritesByMaxDelay(t *testing.T) {
+	w, clock := newTestWriter(t, Options{Debounce: 2 * time.Second, MaxDelay: 5 * time.Second})
+	path := filepath.Join(t.TempDir(), "state.json")
+	writes := 0
+
+	for i := range 4 {
+		if i > 0 {
+			clock.advance(1500 * time.Millisecond)
+		}
+		if err := w.Schedule(counted(path, "busy", &writes)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The fourth change came at 4.5s, so the debounce alone would wait
+	// until 6.5s; the max delay flushes at 5s.
+	clock.advance(400 * time.Millisecond)
+	if writes != 0 {
+		t.Fatalf("expected no write before the max delay, got %d", writes)
+	}
+	clock.advance(100 * time.Millisecond)
+	if writes != 1 {
+		t.Fatalf("expected the max delay to force a write, got %d", writes)
+	}
+}
+
+func TestDocumentsAreDebouncedSeparately(t *testing.T) {
+	w, clock := newTestWriter(t, Options{})
+	dir := t.TempDir()
+	var trashWrites, baselineWrites int
+
+	if err := w.Schedule(counted(filepath.Join(dir, "trash.json"), "t", &trashWrites)); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(time.Second)
+	if err := w.Schedule(counted(filepath.Join(dir, "baselines.json"), "b", &baselineWrites)); err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(time.Second)
+	if trashWrites != 1 || baselineWrites != 0 {
+		t.Fatalf("expected only the trash to be due, got %d and %d writes", trashWrites, baselineWrites)
+	}
+	clock.advance(time.Second)
+	if baselineWrites != 1 {
+		t.Fatalf("expected the baselines to follow, got %d writes", baselineWrites)
+	}
+}
+
+func TestWriteFileReplacesAtomically(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	path := filepath.Join(dir, "state.json")
+	if err := WriteFile(path, []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "new" {
+		t.Fatalf("expected the new contents, got %q", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected no temporary files left behind, got %v", entries)
+	}
+
+	// A failed marshal leaves the previous file untouched.
+	broken := Document{Name: "state", Path: path, Marshal: func() ([]byte, error) { return nil, errors.New("boom") }}
+	if err := Write(broken); err == nil || !strings.Contains(err.Error(), "encode state") {
+		t.Fatalf("expected an encode error, got %v", err)
+	}
+	if got := readFile(t, path); got != "new" {
+		t.Fatalf("expected the file to survive a failed write, got %q", got)
+	}
+}
+
+func TestBackgroundErrorsGoToOnError(t *testing.T) {
+	var reported []string
+	w, clock := newTestWriter(t, Options{OnError: func(name string, err error) {
+		reported = append(reported, name)
+	}})
+	// A file where the directory should be makes the write fail.
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	doc := Document{Name: "trash", Path: filepath.Join(blocker, "trash.json"), Marshal: func() ([]byte, error) { return []byte("x"), nil }}
+	if err := w.Schedule(doc); err != nil {
+		t.Fatalf("expected background writes to report through OnError, got %v", err)
+	}
+	clock.advance(DefaultDebounce)
+	if len(reported) != 1 || reported[0] != "trash" {
+		t.Fatalf("expected the failure to be reported for trash, got %v", reported)
+	}
+}
+
+func TestCloseFlushesInOrderOfFirstChange(t *testing.T) {
+	w, clock := newTestWriter(t, Options{})
+	dir := t.TempDir()
+	var order []string
+	doc := func(name, body string) Document {
+		return Document{Name: name, Path: filepath.Join(dir, name), Marshal: func() ([]byte, error) {
+			order = append(order, name+"="+body)
+			return []byte(body), nil
+		}}
+	}
+	for _, d := range []Document{doc("b", "1"), doc("a", "1"), doc("c", "1"), doc("b", "2")} {
+		if err := w.Schedule(d); err != nil {
+			t.Fatal(err)
+		}
+		clock.advance(100 * time.Millisecond)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(order, " "); got != "b=2 a=1 c=1" {
+		t.Fatalf("expected each document flushed once in order of first change, got %s", got)
+	}
+
+	// The stopped timers do not write again, and later changes are written
+	// straight away.
+	clock.advance(time.Minute)
+	if len(order) != 3 {
+		t.Fatalf("expected no writes after Close, got %v", order)
+	}
+	if err := w.Schedule(doc("a", "late")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(dir, "a")); got != "late" {
+		t.Fatalf("expected a change after Close to be written at once, got %q", got)
+	}
+}
+
+func TestNilWriterWritesNow(t *testing.T) {
+	var w *Writer
+	path := filepath.Join(t.TempDir(), "state.json")
+	writes := 0
+	if err := w.Schedule(counted(path, "now", &writes)); err != nil {
+		t.Fatal(err)
+	}
+	if writes != 1 || readFile(t, path) != "now" {
+		t.Fatalf("expected a nil writer to write synchronously, got %d writes", writes)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentSchedulesKeepTheLatestWrite(t *testing.T) {
+	w := New(Options{Debounce: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	var (
+		mu     sync.Mutex
+		latest int
+	)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				mu.Lock()
+				latest++
+				mu.Unlock()
+				if err := w.Schedule(Document{Name: "state", Path: path, Marshal: func() ([]byte, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					return []byte(fmt.Sprint(latest)), nil
+				}}); err != nil {
+					t.Errorf("goroutine %d, change %d: %v", g, i, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "400" {
+		t.Fatalf("expected the final state to be on disk after Close, got %q", got)
+	}
+}
@@ -0,0 +1,225 @@
+// Package rulecache keeps each node's last known rule list on disk so the
+// Rules view has something to show before the daemons reconnect.
+package rulecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// AwaitingSync is the node message shown while only cached rules are known.
+const AwaitingSync = "cached — awaiting sync"
+
+const fileVersion = 1
+
+// Entry is one node's cached rule list.
+type Entry struct {
+	Version int          `json:"version"`
+	Key     string       `json:"key"`
+	NodeID  string       `json:"node_id"`
+	Name    string       `json:"name"`
+	Address string       `json:"address"`
+	SavedAt time.Time    `json:"saved_at"`
+	Rules   []state.Rule `json:"rules"`
+}
+
+// Cache stores entries as one JSON file per node in a directory.
+type Cache struct {
+	dir string
+	now func() time.Time
+}
+
+// New returns a cache kept in dir.
+func New(dir string) *Cache {
+	return &Cache{dir: dir, now: time.Now}
+}
+
+// DefaultDir returns the cache directory under XDG_CACHE_HOME (~/.cache).
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "opensnitch-tui", "rules"), nil
+}
+
+// Key identifies a node across restarts; see state.NodeKey.
+func Key(node state.Node) string {
+	return state.NodeKey(node)
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Document is the cache file of node holding rules, for a
+// persist.Writer. The save time is taken when it is written.
+func (c *Cache) Document(node state.Node, rules []state.Rule) persist.Document {
+	key := Key(node)
+	entry := Entry{
+		Version: fileVersion,
+		Key:     key,
+		NodeID:  node.ID,
+		Name:    node.Name,
+		Address: node.Address,
+		Rules:   make([]state.Rule, len(rules)),
+	}
+	for i, rule := range rules {
+		rule.Cached, rule.Session = false, false
+		entry.Rules[i] = rule
+	}
+	return persist.Document{Name: "rule cache " + key, Path: c.path(key), Marshal: func() ([]byte, error) {
+		entry.SavedAt = c.now()
+		return json.MarshalIndent(entry, "", "  ")
+	}}
+}
+
+// Save replaces the cached rules of node now.
+func (c *Cache) Save(node state.Node, rules []state.Rule) error {
+	return persist.Write(c.Document(node, rules))
+}
+
+// Load reads every cached entry, ordered by node name. Unreadable or
+// foreign files are skipped and reported in the returned error alongside
+// the entries that did load; a missing directory is an empty cache.
+func (c *Cache) Load() ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		entries []Entry
+		errs    []error
+	)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(file), err))
+			continue
+		}
+		if entry.Version != fileVersion || entry.Key == "" || entry.NodeID == "" {
+			errs = append(errs, fmt.Errorf("%s: unsupported rule cache entry", filepath.Base(file)))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name == entries[j].Name {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, errors.Join(errs...)
+}
+
+// Restore puts the cached entries into store, marking their rules as
+// awaiting sync. Nodes already in the store, from the config for instance,
+// keep their details; unknown ones are added as disconnected.
+func Restore(store *state.Store, entries []Entry) {
+	for _, entry := range entries {
+		known := store.UpdateNode(entry.NodeID, func(n *state.Node) { n.Message = AwaitingSync })
+		if !known {
+			store.UpsertNode(state.Node{
+				ID:       entry.NodeID,
+				Name:     entry.Name,
+				Address:  entry.Address,
+				Status:   state.NodeStatusDisconnected,
+				Message:  AwaitingSync,
+				LastSeen: entry.SavedAt,
+			})
+		}
+		rules := make([]state.Rule, len(entry.Rules))
+		for i, rule := range entry.Rules {
+			rule.NodeID = entry.NodeID
+			rule.Cached = true
+			rules[i] = rule
+		}
+		store.SetRules(entry.NodeID, rules)
+	}
+}
+
+// Changes summarises how a live rule list differs from the cached one.
+type Changes struct {
+	Added, Removed, Changed []string
+}
+
+// Empty reports whether the lists matched.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+func (c Changes) String() string {
+	var parts []string
+	for _, part := range []struct {
+		names []string
+		label string
+	}{{c.Added, "added"}, {c.Removed, "removed"}, {c.Changed, "changed"}} {
+		if len(part.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", len(part.names), part.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Diff compares rules by name. Node IDs and the cached and session flags
+// are ignored.
+func Diff(cached, live []state.Rule) Changes {
+	normalize := func(rule state.Rule) state.Rule {
+		rule.NodeID = ""
+		rule.Cached, rule.Session = false, false
+		return rule
+	}
+	old := make(map[string]state.Rule, len(cached))
+	for _, rule := range cached {
+		old[rule.Name] = normalize(rule)
+	}
+	var changes Changes
+	seen := make(map[string]struct{}, len(live))
+	for _, rule := range live {
+		seen[rule.Name] = struct{}{}
+		prev, ok := old[rule.Name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, rule.Name)
+		case !sameRule(prev, normalize(rule)):
+			changes.Changed = append(changes.Changed, rule.Name)
+		}
+	}
+	for _, rule := range cached {
+		if _, ok := seen[rule.Name]; !ok {
+			changes.Removed = append(changes.Removed, rule.Name)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return changes
+}
+
+// sameRule compares rules after a JSON round trip has dropped the
+// monotonic clock reading and location from their timestamps.
+func sameRule(a, b state.Rule) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return false
+	}
+	a.CreatedAt, b.CreatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
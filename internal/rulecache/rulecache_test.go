@@ -0,0 +1,142 @@
+package rulecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+var saved = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func testRules(nodeID string) []state.Rule {
+	return []state.Rule{
+		{NodeID: nodeID, Name: "allow-curl", Action: "allow", Duration: "always", Enabled: true,
+			CreatedAt: time.Unix(1700000000, 0), Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}},
+		{NodeID: nodeID, Name: "deny-lan", Action: "deny", Duration: "always",
+			Operator: state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+				{Type: "network", Operand: "dest.network", Data: "10.0.0.0/8"},
+				{Type: "simple", Operand: "dest.port", Data: "22"},
+			}}},
+	}
+}
+
+func newCache(t *testing.T) *Cache {
+	t.Helper()
+	cache := New(filepath.Join(t.TempDir(), "rules"))
+	cache.now = func() time.Time { return saved }
+	return cache
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	cache := newCache(t)
+	if entries, err := cache.Load(); err != nil || len(entries) != 0 {
+		t.Fatalf("missing dir should be an empty cache, got %v, %v", entries, err)
+	}
+	node := state.Node{ID: "tcp://10.0.0.2:41234", Name: "laptop", Address: "10.0.0.2:41234"}
+	rules := testRules(node.ID)
+	rules[0].Cached = true
+	if err := cache.Save(node, rules); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// Saving again under a new peer ID replaces the entry of the same daemon.
+	node.ID = "tcp://10.0.0.2:50000"
+	if err := cache.Save(node, testRules(node.ID)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	entries, err := cache.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.NodeID != node.ID || entry.Name != "laptop" || !entry.SavedAt.Equal(saved) {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if changes := Diff(entry.Rules, testRules(node.ID)); !changes.Empty() {
+		t.Fatalf("round trip changed rules: %v", changes)
+	}
+	for _, rule := range entry.Rules {
+		if rule.Cached {
+			t.Fatalf("cached flag persisted on %s", rule.Name)
+		}
+	}
+}
+
+func TestLoadSkipsBrokenFiles(t *testing.T) {
+	cache := newCache(t)
+	if err := cache.Save(state.Node{ID: "unix:///tmp/osui.sock"}, testRules("unix:///tmp/osui.sock")); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cache.dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cache.Load()
+	if err == nil {
+		t.Fatal("expected the broken file to be reported")
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected the good entry to load, got %d", len(entries))
+	}
+}
+
+func TestKeyPrefersDaemonName(t *testing.T) {
+	if got := Key(state.Node{ID: "tcp://1.2.3.4:5000", Name: "laptop"}); got != "name:laptop" {
+		t.Fatalf("got %q", got)
+	}
+	if got := Key(state.Node{ID: "unix:///tmp/osui.sock", Name: "unix:///tmp/osui.sock"}); got != "id:unix:///tmp/osui.sock" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRestoreMarksRulesAwaitingSync(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "configured", Address: "10.0.0.9:50051", Status: state.NodeStatusDisconnected}})
+	Restore(store, []Entry{
+		{Key: "id:node-1", NodeID: "node-1", Name: "other", SavedAt: saved, Rules: testRules("old")},
+		{Key: "name:laptop", NodeID: "tcp://10.0.0.2:41234", Name: "laptop", SavedAt: saved, Rules: testRules("old")},
+	})
+
+	snap := store.Snapshot()
+	if len(snap.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %+v", snap.Nodes)
+	}
+	if configured := snap.Nodes[0]; configured.Name != "configured" || configured.Message != AwaitingSync {
+		t.Fatalf("configured node not kept: %+v", configured)
+	}
+	restored := snap.Nodes[1]
+	if restored.Name != "laptop" || restored.Status != state.NodeStatusDisconnected || restored.Message != AwaitingSync || !restored.LastSeen.Equal(saved) {
+		t.Fatalf("unexpected restored node: %+v", restored)
+	}
+	for _, rule := range snap.Rules[restored.ID] {
+		if !rule.Cached || rule.NodeID != restored.ID {
+			t.Fatalf("rule not marked cached: %+v", rule)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cached := testRules("a")
+	live := testRules("b")
+	live[0].Enabled = false
+	live = append(live[:1], state.Rule{Name: "allow-ssh"})
+
+	changes := Diff(cached, live)
+	if len(changes.Added) != 1 || changes.Added[0] != "allow-ssh" {
+		t.Fatalf("added: %v", changes.Added)
+	}
+	if len(changes.Removed) != 1 || changes.Removed[0] != "deny-lan" {
+		t.Fatalf("removed: %v", changes.Removed)
+	}
+	if len(changes.Changed) != 1 || changes.Changed[0] != "allow-curl" {
+		t.Fatalf("changed: %v", changes.Changed)
+	}
+	if got := changes.String(); got != "1 added, 1 removed, 1 changed" {
+		t.Fatalf("summary %q", got)
+	}
+}
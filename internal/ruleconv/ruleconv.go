@@ -0,0 +1,199 @@
+// Package ruleconv converts rules between the daemon's protobuf messages
+// and the store's state.Rule. Strings from a daemon are sanitized on the
+// way in and restored on the way out, so a rule for a path with control
+// characters in it still matches that path.
+package ruleconv
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// durationVariants maps the spellings daemons use for rule durations to the
+// ones the TUI works with. 1.6 daemons write "until restart"; some forks
+// and older releases write "until_restart" or just "restart".
+var durationVariants = map[string]string{
+	"once":          string(controller.PromptDurationOnce),
+	"always":        string(controller.PromptDurationAlways),
+	"until restart": string(controller.PromptDurationUntilRestart),
+	"until_restart": string(controller.PromptDurationUntilRestart),
+	"until-restart": string(controller.PromptDurationUntilRestart),
+	"restart":       string(controller.PromptDurationUntilRestart),
+}
+
+// CanonicalDuration returns the canonical spelling of a daemon duration and
+// whether it is one of the known spellings.
+func CanonicalDuration(raw string) (string, bool) {
+	canonical, ok := durationVariants[strings.ToLower(strings.TrimSpace(raw))]
+	return canonical, ok
+}
+
+// NormalizeDuration returns the canonical spelling of a daemon duration.
+// Values it does not know, such as timed durations ("30m"), pass through.
+func NormalizeDuration(raw string) string {
+	if canonical, ok := CanonicalDuration(raw); ok {
+		return canonical
+	}
+	return raw
+}
+
+// FromProtoList converts the rules a daemon reported for nodeID.
+func FromProtoList(list []*pb.Rule, nodeID string) []state.Rule {
+	if len(list) == 0 {
+		return nil
+	}
+	rules := make([]state.Rule, 0, len(list))
+	for _, rule := range list {
+		rules = append(rules, FromProto(rule, nodeID))
+	}
+	return rules
+}
+
+// FromProto converts a daemon rule of nodeID, normalizing its duration and
+// operator and sanitizing its strings.
+func FromProto(rule *pb.Rule, nodeID string) state.Rule {
+	if rule == nil {
+		return state.Rule{}
+	}
+	converted := state.Rule{
+		NodeID:      nodeID,
+		Name:        rule.GetName(),
+		Description: rule.GetDescription(),
+		Action:      rule.GetAction(),
+		Duration:    NormalizeDuration(rule.GetDuration()),
+		Enabled:     rule.GetEnabled(),
+		Precedence:  rule.GetPrecedence(),
+		NoLog:       rule.GetNolog(),
+		Operator:    ruleset.NormalizeOperator(OperatorFromProto(rule.GetOperator())),
+	}
+	if created := rule.GetCreated(); created > 0 {
+		converted.CreatedAt = time.Unix(created, 0)
+	}
+	return Sanitize(converted)
+}
+
+// OperatorFromProto converts a daemon operator and its list as they are.
+func OperatorFromProto(op *pb.Operator) state.RuleOperator {
+	if op == nil {
+		return state.RuleOperator{}
+	}
+	converted := state.RuleOperator{
+		Type:      op.GetType(),
+		Operand:   op.GetOperand(),
+		Data:      op.GetData(),
+		Sensitive: op.GetSensitive(),
+	}
+	list := op.GetList()
+	if len(list) == 0 {
+		return converted
+	}
+	children := make([]state.RuleOperator, len(list))
+	for i, child := range list {
+		children[i] = OperatorFromProto(child)
+	}
+	converted.Children = children
+	return converted
+}
+
+// ToProto converts rule for a daemon, with the original bytes of the
+// fields left unedited since FromProto.
+func ToProto(rule state.Rule) *pb.Rule {
+	rule = Restore(rule)
+	proto := &pb.Rule{
+		Name:        rule.Name,
+		Description: rule.Description,
+		Enabled:     rule.Enabled,
+		Precedence:  rule.Precedence,
+		Nolog:       rule.NoLog,
+		Action:      rule.Action,
+		Duration:    rule.Duration,
+		Operator:    OperatorToProto(ruleset.NormalizeOperator(rule.Operator)),
+	}
+	if !rule.CreatedAt.IsZero() {
+		proto.Created = rule.CreatedAt.Unix()
+	}
+	return proto
+}
+
+// OperatorToProto converts op and its children for a daemon.
+func OperatorToProto(op state.RuleOperator) *pb.Operator {
+	operator := &pb.Operator{
+		Type:      op.Type,
+		Operand:   op.Operand,
+		Data:      op.Data,
+		Sensitive: op.Sensitive,
+	}
+	if len(op.Children) == 0 {
+		return operator
+	}
+	operator.List = make([]*pb.Operator, len(op.Children))
+	for i, child := range op.Children {
+		operator.List[i] = OperatorToProto(child)
+	}
+	return operator
+}
+
+// Sanitize makes the strings of rule safe to render, keeping the original
+// in Raw when anything changed.
+func Sanitize(rule state.Rule) state.Rule {
+	raw := rule
+	rule.Name = util.Sanitize(rule.Name)
+	rule.Description = util.Sanitize(rule.Description)
+	rule.Action = util.Sanitize(rule.Action)
+	rule.Duration = util.Sanitize(rule.Duration)
+	rule.Operator = sanitizeOperator(rule.Operator)
+	if !reflect.DeepEqual(rule, raw) {
+		rule.Raw = &raw
+	}
+	return rule
+}
+
+func sanitizeOperator(op state.RuleOperator) state.RuleOperator {
+	op.Type = util.Sanitize(op.Type)
+	op.Operand = util.Sanitize(op.Operand)
+	op.Data = util.Sanitize(op.Data)
+	if len(op.Children) > 0 {
+		children := make([]state.RuleOperator, len(op.Children))
+		for i, child := range op.Children {
+			children[i] = sanitizeOperator(child)
+		}
+		op.Children = children
+	}
+	return op
+}
+
+// Restore undoes Sanitize for the fields left unedited.
+func Restore(rule state.Rule) state.Rule {
+	raw := rule.Raw
+	if raw == nil {
+		return rule
+	}
+	rule.Raw = nil
+	rule.Name = util.Unsanitize(rule.Name, raw.Name)
+	rule.Description = util.Unsanitize(rule.Description, raw.Description)
+	rule.Action = util.Unsanitize(rule.Action, raw.Action)
+	rule.Duration = util.Unsanitize(rule.Duration, raw.Duration)
+	rule.Operator = restoreOperator(rule.Operator, raw.Operator)
+	return rule
+}
+
+func restoreOperator(op, raw state.RuleOperator) state.RuleOperator {
+	op.Type = util.Unsanitize(op.Type, raw.Type)
+	op.Operand = util.Unsanitize(op.Operand, raw.Operand)
+	op.Data = util.Unsanitize(op.Data, raw.Data)
+	if len(op.Children) > 0 && len(op.Children) == len(raw.Children) {
+		children := make([]state.RuleOperator, len(op.Children))
+		for i, child := range op.Children {
+			children[i] = restoreOperator(child, raw.Children[i])
+		}
+		op.Children = children
+	}
+	return op
+}
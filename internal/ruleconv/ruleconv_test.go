@@ -0,0 +1,150 @@
+package ruleconv
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+)
+
+func TestFromProtoList(t *testing.T) {
+	protoRules := []*pb.Rule{{
+		Created:     100,
+		Name:        "ssh",
+		Description: "allow ssh",
+		Enabled:     true,
+		Precedence:  true,
+		Nolog:       true,
+		Action:      "allow",
+		Duration:    "always",
+		Operator: &pb.Operator{
+			Type:    "process",
+			Operand: "eq",
+			Data:    "/usr/bin/ssh",
+			List: []*pb.Operator{{
+				Type:    "list",
+				Operand: "contains",
+				Data:    "10.0.0.1",
+			}},
+		},
+	}}
+
+	rules := FromProtoList(protoRules, "node-1")
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+	rule := rules[0]
+	if rule.Name != "ssh" {
+		t.Fatalf("unexpected rule name %q", rule.Name)
+	}
+	if rule.CreatedAt.IsZero() {
+		t.Fatalf("expected created at to be set")
+	}
+	if len(rule.Operator.Children) != 1 {
+		t.Fatalf("expected nested operators to be copied")
+	}
+}
+
+func TestToProto(t *testing.T) {
+	rule := FromProto(&pb.Rule{
+		Created: 50,
+		Name:    "web",
+		Operator: &pb.Operator{
+			Type:    "process",
+			Operand: "eq",
+			Data:    "/usr/bin/web",
+			List: []*pb.Operator{{
+				Type:    "list",
+				Operand: "contains",
+				Data:    "example.com",
+			}},
+		},
+	}, "node-1")
+	proto := ToProto(rule)
+	if proto.GetName() != "web" {
+		t.Fatalf("expected serialized rule name web, got %q", proto.GetName())
+	}
+	if proto.GetCreated() == 0 {
+		t.Fatalf("expected created timestamp to be preserved")
+	}
+	if proto.GetOperator() == nil || len(proto.GetOperator().GetList()) != 1 {
+		t.Fatalf("expected operator children to be serialized")
+	}
+	roundTrip := FromProto(proto, "node-1")
+	if roundTrip.Operator.Children[0].Data != "example.com" {
+		t.Fatalf("expected operator payload to survive round trip")
+	}
+}
+
+func TestFromProtoNormalizesListOperators(t *testing.T) {
+	rule := FromProto(&pb.Rule{
+		Name: "curl-https",
+		Operator: &pb.Operator{
+			Type: "list",
+			List: []*pb.Operator{
+				{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"},
+				{Type: "simple", Operand: "dest.port", Data: "443"},
+			},
+		},
+	}, "node-1")
+	if rule.Operator.Operand != "list" || rule.Operator.Type != "list" {
+		t.Fatalf("expected the list operand filled in, got %+v", rule.Operator)
+	}
+	if rule.Operator.Children[0].Operand != "process.path" {
+		t.Fatalf("expected children left alone, got %+v", rule.Operator.Children)
+	}
+	if proto := ToProto(rule); proto.GetOperator().GetOperand() != "list" {
+		t.Fatalf("expected the list operand sent back, got %+v", proto.GetOperator())
+	}
+}
+
+func TestNormalizeDuration(t *testing.T) {
+	cases := []struct {
+		raw, want string
+	}{
+		{"until restart", "until restart"},
+		{"until_restart", "until restart"},
+		{"until-restart", "until restart"},
+		{"restart", "until restart"},
+		{"Until Restart", "until restart"},
+		{" once ", "once"},
+		{"ALWAYS", "always"},
+		{"30m", "30m"},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		if got := NormalizeDuration(tc.raw); got != tc.want {
+			t.Errorf("NormalizeDuration(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
+
+const evilPath = "/tmp/x\n\x1b[2J\x1b[31mALLOWED"
+
+func TestFromProtoSanitizesAndRoundTrips(t *testing.T) {
+	rule := FromProto(&pb.Rule{
+		Name:        "allow-\x1b[8mhidden",
+		Description: "line1\nline2",
+		Action:      "allow",
+		Duration:    "always",
+		Operator: &pb.Operator{Type: "list", Operand: "list", List: []*pb.Operator{
+			{Type: "simple", Operand: "process.path", Data: evilPath},
+			{Type: "simple", Operand: "dest.port", Data: "443"},
+		}},
+	}, "node-1")
+	if rule.Name != "allow-␛[8mhidden" || rule.Description != "line1␊line2" || strings.ContainsRune(rule.Operator.Children[0].Data, '\x1b') {
+		t.Fatalf("expected sanitized rule, got %+v", rule)
+	}
+	if rule.Raw == nil || rule.Raw.Name != "allow-\x1b[8mhidden" {
+		t.Fatalf("expected the original rule kept, got %+v", rule.Raw)
+	}
+
+	proto := ToProto(rule)
+	if proto.GetName() != "allow-\x1b[8mhidden" || proto.GetOperator().GetList()[0].GetData() != evilPath {
+		t.Fatalf("expected the original bytes sent back, got %+v", proto)
+	}
+	rule.Description = "edited"
+	if got := ToProto(rule); got.GetDescription() != "edited" || got.GetName() != "allow-\x1b[8mhidden" {
+		t.Fatalf("expected edits kept and untouched fields restored, got %+v", got)
+	}
+}
@@ -0,0 +1,71 @@
+// Package rulehistory keeps the per-rule change timelines on disk, so a
+// rule's history outlives the session that recorded it.
+package rulehistory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+const fileVersion = 1
+
+type file struct {
+	Version int                `json:"version"`
+	Entries []state.RuleChange `json:"entries"`
+}
+
+// File stores every timeline as one JSON file.
+type File struct {
+	path string
+}
+
+// New returns timelines kept at path.
+func New(path string) *File {
+	return &File{path: path}
+}
+
+// DefaultPath returns the history file under XDG_CACHE_HOME (~/.cache).
+func DefaultPath() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "opensnitch-tui", "rule-history.json"), nil
+}
+
+// Load reads the timelines; a missing file has none.
+func (f *File) Load() ([]state.RuleChange, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read rule history: %w", err)
+	}
+	var doc file
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("read rule history: %w", err)
+	}
+	if doc.Version != fileVersion {
+		return nil, fmt.Errorf("read rule history: unsupported version %d", doc.Version)
+	}
+	return doc.Entries, nil
+}
+
+// Document is the history file holding entries, for a persist.Writer.
+func (f *File) Document(entries []state.RuleChange) persist.Document {
+	return persist.Document{Name: "rule history", Path: f.path, Marshal: func() ([]byte, error) {
+		return json.MarshalIndent(file{Version: fileVersion, Entries: entries}, "", "  ")
+	}}
+}
+
+// Save replaces the file with entries now.
+func (f *File) Save(entries []state.RuleChange) error {
+	return persist.Write(f.Document(entries))
+}
@@ -0,0 +1,49 @@
+package rulehistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	f := New(filepath.Join(t.TempDir(), "nested", "rule-history.json"))
+	if entries, err := f.Load(); err != nil || entries != nil {
+		t.Fatalf("expected a missing file to hold no history, got %+v, %v", entries, err)
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	want := []state.RuleChange{{
+		NodeKey: "name:laptop",
+		Rule:    "ssh",
+		At:      at,
+		Source:  state.RuleChangeDaemon,
+		Kind:    state.RuleChanged,
+		Fields:  []state.FieldChange{{Field: "action", From: "allow", To: "deny"}},
+	}}
+	if err := f.Save(want); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	got, err := f.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(got) != 1 || got[0].Rule != "ssh" || got[0].Source != state.RuleChangeDaemon || !got[0].At.Equal(at) {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+	if fields := got[0].Fields; len(fields) != 1 || fields[0] != want[0].Fields[0] {
+		t.Fatalf("fields not kept: %+v", fields)
+	}
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rule-history.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "entries": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(path).Load(); err == nil {
+		t.Fatal("expected an error for an unknown version")
+	}
+}
@@ -0,0 +1,186 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// DefaultDescriptionTemplate yields descriptions like "created from prompt:
+// /usr/bin/curl → example.com:443 on 2024-05-01".
+const DefaultDescriptionTemplate = "created from prompt: {{.Process}} → {{.Dest}}:{{.Port}} on {{.Date}}"
+
+// unknownField stands in for a value the daemon did not report.
+const unknownField = "unknown"
+
+// DescriptionFields are the values available to a rule description
+// template. None is ever empty: values the daemon left out read "unknown".
+type DescriptionFields struct {
+	// Process is the executable and Command its full command line, or the
+	// executable when the daemon sent no arguments.
+	Process string
+	Command string
+	// Host and IP are the destination; Dest is the host when known and the
+	// address otherwise.
+	Host string
+	IP   string
+	Dest string
+	Port string
+	// Node is the name of the node that asked.
+	Node string
+	// User is the UID of the process.
+	User string
+	// Date is the day the rule was made, as 2006-01-02.
+	Date string
+}
+
+// descriptionFuncs are the only functions a description template may
+// call, besides the comparisons and logic below; the rest of text/template's
+// builtins are refused.
+var descriptionFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"base":  path.Base,
+	// default returns value, or fallback when value is empty or unknown.
+	"default": func(fallback, value string) string {
+		if value == "" || value == unknownField {
+			return fallback
+		}
+		return value
+	},
+}
+
+var descriptionBuiltins = map[string]bool{"and": true, "or": true, "not": true, "eq": true, "ne": true}
+
+// DescriptionFieldsFor resolves the fields of prompt, made at at. A UID of
+// 0 counts as unknown when uidZeroUnknown is set.
+func DescriptionFieldsFor(prompt state.Prompt, uidZeroUnknown bool, at time.Time) DescriptionFields {
+	conn := prompt.Connection
+	fields := DescriptionFields{
+		Process: util.Fallback(conn.ProcessPath, unknownField),
+		Host:    util.Fallback(conn.DstHost, unknownField),
+		IP:      util.Fallback(conn.DstIP, unknownField),
+		Port:    unknownField,
+		Node:    util.Fallback(prompt.NodeName, util.Fallback(prompt.NodeID, unknownField)),
+		User:    unknownField,
+		Date:    unknownField,
+	}
+	fields.Command = util.Fallback(strings.Join(conn.ProcessArgs, " "), fields.Process)
+	fields.Dest = util.Fallback(conn.DstHost, fields.IP)
+	if conn.DstPort != 0 {
+		fields.Port = strconv.FormatUint(uint64(conn.DstPort), 10)
+	}
+	if conn.UIDKnown(uidZeroUnknown) {
+		fields.User = strconv.FormatUint(uint64(conn.UserID), 10)
+	}
+	if !at.IsZero() {
+		fields.Date = at.Format("2006-01-02")
+	}
+	return fields
+}
+
+// ParseDescriptionTemplate checks a rule description template, returning
+// the default for an empty one. Templates may only read the fields and call
+// descriptionFuncs; {{template}}, {{define}} and other builtins are refused.
+func ParseDescriptionTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultDescriptionTemplate
+	}
+	tmpl, err := template.New("rule-description").Funcs(descriptionFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, errors.New("template definitions are not allowed")
+	}
+	if err := checkDescriptionNode(tmpl.Tree.Root); err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(&strings.Builder{}, DescriptionFields{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// checkDescriptionNode refuses calls outside the allowed functions.
+func checkDescriptionNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkDescriptionNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkDescriptionNode(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := checkDescriptionNode(cmd); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := checkDescriptionNode(arg); err != nil {
+				return err
+			}
+		}
+	case *parse.IdentifierNode:
+		if _, ok := descriptionFuncs[n.Ident]; !ok && !descriptionBuiltins[n.Ident] {
+			return fmt.Errorf("function %q is not allowed", n.Ident)
+		}
+	case *parse.IfNode:
+		return checkDescriptionBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkDescriptionBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return errors.New("range is not allowed")
+	case *parse.TemplateNode:
+		return errors.New("template calls are not allowed")
+	}
+	return nil
+}
+
+func checkDescriptionBranch(n *parse.BranchNode) error {
+	for _, node := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if err := checkDescriptionNode(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderDescription renders fields through the description template
+// (falling back to DefaultDescriptionTemplate when it is invalid) and keeps
+// the result within maxLen runes.
+func RenderDescription(templateText string, fields DescriptionFields, maxLen int) string {
+	tmpl, err := ParseDescriptionTemplate(templateText)
+	if err != nil {
+		tmpl, _ = ParseDescriptionTemplate(DefaultDescriptionTemplate)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, fields); err != nil {
+		out.Reset()
+		tmpl, _ = ParseDescriptionTemplate(DefaultDescriptionTemplate)
+		_ = tmpl.Execute(&out, fields)
+	}
+	text := strings.Join(strings.Fields(out.String()), " ")
+	if runes := []rune(text); maxLen > 0 && len(runes) > maxLen {
+		text = string(runes[:maxLen-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+var curlPrompt = state.Prompt{
+	NodeID:   "unix:/run/opensnitch.sock",
+	NodeName: "laptop",
+	Connection: state.Connection{
+		DstHost:     "example.com",
+		DstIP:       "93.184.216.34",
+		DstPort:     443,
+		UserID:      1000,
+		ProcessPath: "/usr/bin/curl",
+		ProcessArgs: []string{"curl", "-s", "https://example.com"},
+	},
+}
+
+var may1 = time.Date(2024, time.May, 1, 8, 30, 0, 0, time.UTC)
+
+func TestRenderDescriptionDefaultTemplate(t *testing.T) {
+	got := RenderDescription("", DescriptionFieldsFor(curlPrompt, false, may1), DefaultMaxText)
+	if got != "created from prompt: /usr/bin/curl → example.com:443 on 2024-05-01" {
+		t.Fatalf("unexpected description %q", got)
+	}
+}
+
+func TestDescriptionFieldsFallBackForMissingValues(t *testing.T) {
+	fields := DescriptionFieldsFor(state.Prompt{NodeID: "tcp://10.0.0.2:50051", Connection: state.Connection{DstIP: "10.0.0.9"}}, true, time.Time{})
+	want := DescriptionFields{
+		Process: "unknown", Command: "unknown", Host: "unknown", IP: "10.0.0.9", Dest: "10.0.0.9",
+		Port: "unknown", Node: "tcp://10.0.0.2:50051", User: "unknown", Date: "unknown",
+	}
+	if fields != want {
+		t.Fatalf("unexpected fields\n got %+v\nwant %+v", fields, want)
+	}
+	// The command falls back to the executable when there are no arguments.
+	noArgs := curlPrompt
+	noArgs.Connection.ProcessArgs = nil
+	if got := DescriptionFieldsFor(noArgs, false, may1).Command; got != "/usr/bin/curl" {
+		t.Fatalf("expected the executable as the command, got %q", got)
+	}
+}
+
+func TestRenderDescriptionTemplate(t *testing.T) {
+	fields := DescriptionFieldsFor(curlPrompt, false, may1)
+	cases := map[string]string{
+		"{{.Node}}: {{base .Process}} as {{.User}} to {{upper .Host}}": "laptop: curl as 1000 to EXAMPLE.COM",
+		"{{.Command}}": "curl -s https://example.com",
+		`{{if eq .Port "443"}}https{{else}}other{{end}} {{.IP}}`: "https 93.184.216.34",
+		`{{default "any host" .Host}}`:                           "example.com",
+	}
+	for text, want := range cases {
+		if got := RenderDescription(text, fields, DefaultMaxText); got != want {
+			t.Fatalf("template %q: got %q, want %q", text, got, want)
+		}
+	}
+	missing := DescriptionFieldsFor(state.Prompt{}, false, may1)
+	if got := RenderDescription(`{{default "any host" .Host}}`, missing, DefaultMaxText); got != "any host" {
+		t.Fatalf("expected default to replace an unknown value, got %q", got)
+	}
+}
+
+func TestParseDescriptionTemplateRestrictsFunctions(t *testing.T) {
+	for _, bad := range []string{
+		"{{.Process",
+		"{{.Missing}}",
+		`{{printf "%s" .Process}}`,
+		`{{call .Process}}`,
+		`{{define "x"}}x{{end}}{{template "x"}}`,
+		`{{range .Process}}{{end}}`,
+		`{{if .Process}}{{index .Process 0}}{{end}}`,
+	} {
+		if _, err := ParseDescriptionTemplate(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+		// Rendering falls back to the default rather than failing.
+		if got := RenderDescription(bad, DescriptionFieldsFor(curlPrompt, false, may1), DefaultMaxText); !strings.HasPrefix(got, "created from prompt: /usr/bin/curl") {
+			t.Fatalf("template %q: expected the default description, got %q", bad, got)
+		}
+	}
+}
+
+func TestRenderDescriptionRespectsMaxLength(t *testing.T) {
+	long := curlPrompt
+	long.Connection.ProcessPath = "/opt/" + strings.Repeat("x", 300)
+	got := RenderDescription("", DescriptionFieldsFor(long, false, may1), 64)
+	if n := len([]rune(got)); n != 64 || !strings.HasSuffix(got, "…") {
+		t.Fatalf("expected 64 runes ending in an ellipsis, got %d: %q", n, got)
+	}
+}
@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// DaemonRulesDir is where opensnitchd loads its rule files from.
+const DaemonRulesDir = "/etc/opensnitchd/rules"
+
+// File is a rule in the JSON schema of opensnitchd's rule files.
+type File struct {
+	Created     time.Time    `json:"created"`
+	Updated     time.Time    `json:"updated"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Enabled     bool         `json:"enabled"`
+	Precedence  bool         `json:"precedence"`
+	NoLog       bool         `json:"nolog"`
+	Action      string       `json:"action"`
+	Duration    string       `json:"duration"`
+	Operator    FileOperator `json:"operator"`
+}
+
+// FileOperator is a rule file's operator; List holds the operators of a
+// list operator.
+type FileOperator struct {
+	Type      string         `json:"type"`
+	Operand   string         `json:"operand"`
+	Sensitive bool           `json:"sensitive"`
+	Data      string         `json:"data"`
+	List      []FileOperator `json:"list"`
+}
+
+// ToFile converts rule to its rule file. The daemon keeps no update time
+// apart from the one it stamps itself, so both times are the creation.
+func ToFile(rule state.Rule) File {
+	return File{
+		Created:     rule.CreatedAt,
+		Updated:     rule.CreatedAt,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Enabled:     rule.Enabled,
+		Precedence:  rule.Precedence,
+		NoLog:       rule.NoLog,
+		Action:      rule.Action,
+		Duration:    rule.Duration,
+		Operator:    toFileOperator(rule.Operator),
+	}
+}
+
+func toFileOperator(op state.RuleOperator) FileOperator {
+	out := FileOperator{Type: op.Type, Operand: op.Operand, Sensitive: op.Sensitive, Data: op.Data}
+	for _, child := range op.Children {
+		out.List = append(out.List, toFileOperator(child))
+	}
+	return out
+}
+
+// Rule converts f back to a rule of nodeID.
+func (f File) Rule(nodeID string) state.Rule {
+	return state.Rule{
+		NodeID:      nodeID,
+		Name:        f.Name,
+		Description: f.Description,
+		Action:      f.Action,
+		Duration:    f.Duration,
+		Enabled:     f.Enabled,
+		Precedence:  f.Precedence,
+		NoLog:       f.NoLog,
+		CreatedAt:   f.Created,
+		Operator:    f.Operator.operator(),
+	}
+}
+
+func (op FileOperator) operator() state.RuleOperator {
+	out := state.RuleOperator{Type: op.Type, Operand: op.Operand, Data: op.Data, Sensitive: op.Sensitive}
+	for _, child := range op.List {
+		out.Children = append(out.Children, child.operator())
+	}
+	return out
+}
+
+// FileName is the file the daemon would keep the rule named name in. Path
+// separators become dashes, so every rule lands in the directory itself.
+func FileName(name string) string {
+	return strings.ReplaceAll(name, string(filepath.Separator), "-") + ".json"
+}
+
+// WriteFiles writes each rule to dir as a rule file, replacing files of
+// the same name, and returns how many were written before any error.
+func WriteFiles(dir string, rules []state.Rule) (int, error) {
+	for i, rule := range rules {
+		data, err := json.MarshalIndent(ToFile(rule), "", "  ")
+		if err != nil {
+			return i, fmt.Errorf("encode %s: %w", rule.Name, err)
+		}
+		if err := persist.WriteFile(filepath.Join(dir, FileName(rule.Name)), append(data, '\n')); err != nil {
+			return i, err
+		}
+	}
+	return len(rules), nil
+}
+
+// ReadFiles reads the rule file at path, or every .json file directly in
+// the directory at path in name order. A file that cannot be read, does
+// not decode or names no rule is left out, with an error naming it in
+// skipped; err is only set when path itself cannot be read.
+func ReadFiles(path string) (files []File, skipped []error, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		paths = paths[:0]
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+				paths = append(paths, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(paths)
+	}
+	for _, p := range paths {
+		f, err := readFile(p)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("%s: %w", filepath.Base(p), err))
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, skipped, nil
+}
+
+func readFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return File{}, err
+	}
+	if strings.TrimSpace(f.Name) == "" {
+		return File{}, errors.New("rule name required")
+	}
+	return f, nil
+}
@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestRuleFileRoundTripsNestedOperators(t *testing.T) {
+	rule := listRule(
+		state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"},
+		state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+			{Type: "regexp", Operand: "dest.host", Data: `^(.*\.)?example\.com$`, Sensitive: true},
+			{Type: "network", Operand: "dest.network", Data: "10.0.0.0/8"},
+		}},
+	)
+	rule.NodeID = "node-1"
+	rule.Description = "curl to example"
+	rule.NoLog = true
+	rule.CreatedAt = time.Date(2024, time.May, 1, 8, 30, 0, 0, time.UTC)
+
+	data, err := json.Marshal(ToFile(rule))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := file.Rule("node-1"); !reflect.DeepEqual(got, rule) {
+		t.Fatalf("round trip changed the rule:\n got %+v\nwant %+v", got, rule)
+	}
+
+	// The daemon reads list operators from "list" and the flags by its
+	// own names.
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	op := raw["operator"].(map[string]any)
+	if list, ok := op["list"].([]any); !ok || len(list) != 2 || raw["nolog"] != true {
+		t.Fatalf("unexpected rule file %s", data)
+	}
+}
+
+func TestWriteFilesReplacesRulesOfTheSameName(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ssh.json"), []byte("stale"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules := []state.Rule{
+		{Name: "ssh", Action: "allow", Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"}},
+		{Name: "usr/bin/curl", Action: "deny", Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}},
+	}
+	written, err := WriteFiles(dir, rules)
+	if err != nil || written != 2 {
+		t.Fatalf("WriteFiles = %d, %v", written, err)
+	}
+	for _, rule := range rules {
+		data, err := os.ReadFile(filepath.Join(dir, FileName(rule.Name)))
+		if err != nil {
+			t.Fatalf("read %s: %v", rule.Name, err)
+		}
+		var file File
+		if err := json.Unmarshal(data, &file); err != nil || file.Name != rule.Name || file.Action != rule.Action {
+			t.Fatalf("unexpected file for %s: %s (%v)", rule.Name, data, err)
+		}
+	}
+	if FileName("usr/bin/curl") != "usr-bin-curl.json" {
+		t.Fatalf("expected path separators replaced, got %q", FileName("usr/bin/curl"))
+	}
+}
+
+func TestReadFilesSkipsMalformedFiles(t *testing.T) {
+	dir := t.TempDir()
+	rules := []state.Rule{
+		{Name: "ssh", Action: "allow", Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"}},
+		{Name: "curl", Action: "deny", Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}},
+	}
+	if _, err := WriteFiles(dir, rules); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"broken.json":   "{not json",
+		"nameless.json": `{"action": "allow"}`,
+		"notes.txt":     "not a rule file",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, skipped, err := ReadFiles(dir)
+	if err != nil {
+		t.Fatalf("ReadFiles: %v", err)
+	}
+	if len(files) != 2 || files[0].Name != "curl" || files[1].Name != "ssh" {
+		t.Fatalf("expected curl and ssh in name order, got %+v", files)
+	}
+	if len(skipped) != 2 || !strings.HasPrefix(skipped[0].Error(), "broken.json: ") || !strings.HasPrefix(skipped[1].Error(), "nameless.json: ") {
+		t.Fatalf("expected the two bad .json files skipped, got %v", skipped)
+	}
+
+	// A single file is read on its own.
+	files, skipped, err = ReadFiles(filepath.Join(dir, "ssh.json"))
+	if err != nil || len(skipped) != 0 || len(files) != 1 || files[0].Rule("").Operator.Data != "22" {
+		t.Fatalf("expected ssh.json read alone, got %+v %v %v", files, skipped, err)
+	}
+	if _, _, err := ReadFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected a missing path to fail")
+	}
+}
@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// hashedRule is the part of a rule that defines what it does. The node, the
+// cached flag and the creation time are left out: they change without the
+// rule itself changing.
+type hashedRule struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Action      string         `json:"action"`
+	Duration    string         `json:"duration"`
+	Enabled     bool           `json:"enabled"`
+	Precedence  bool           `json:"precedence"`
+	NoLog       bool           `json:"nolog"`
+	Operator    hashedOperator `json:"operator"`
+}
+
+type hashedOperator struct {
+	Type      string            `json:"type"`
+	Operand   string            `json:"operand"`
+	Data      string            `json:"data"`
+	Sensitive bool              `json:"sensitive"`
+	Children  []json.RawMessage `json:"children,omitempty"`
+}
+
+// Hash returns a digest of rule's definition, used to tell whether the rule
+// a user acted on is still the one the daemon has. List operators match
+// regardless of child order, so their children are hashed sorted.
+func Hash(rule state.Rule) string {
+	data, _ := json.Marshal(hashedRule{
+		Name:        rule.Name,
+		Description: rule.Description,
+		Action:      rule.Action,
+		Duration:    rule.Duration,
+		Enabled:     rule.Enabled,
+		Precedence:  rule.Precedence,
+		NoLog:       rule.NoLog,
+		Operator:    canonicalOperator(rule.Operator),
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func canonicalOperator(op state.RuleOperator) hashedOperator {
+	out := hashedOperator{Type: op.Type, Operand: op.Operand, Data: op.Data, Sensitive: op.Sensitive}
+	for _, child := range op.Children {
+		data, _ := json.Marshal(canonicalOperator(child))
+		out.Children = append(out.Children, data)
+	}
+	sort.Slice(out.Children, func(i, j int) bool {
+		return string(out.Children[i]) < string(out.Children[j])
+	})
+	return out
+}
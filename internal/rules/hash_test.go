@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func listRule(children ...state.RuleOperator) state.Rule {
+	return state.Rule{
+		Name:     "curl-example",
+		Action:   "allow",
+		Duration: "always",
+		Enabled:  true,
+		Operator: state.RuleOperator{Type: "list", Operand: "list", Children: children},
+	}
+}
+
+func TestHashIgnoresVolatileFields(t *testing.T) {
+	path := state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}
+	host := state.RuleOperator{Type: "simple", Operand: "dest.host", Data: "example.com"}
+	base := listRule(path, host)
+
+	moved := listRule(host, path)
+	moved.NodeID = "node-2"
+	moved.Cached = true
+	moved.CreatedAt = time.Unix(1700000000, 0)
+	if Hash(base) != Hash(moved) {
+		t.Fatal("expected node, cache flag, creation time and child order to be ignored")
+	}
+
+	changed := listRule(path, state.RuleOperator{Type: "simple", Operand: "dest.host", Data: "example.org"})
+	if Hash(base) == Hash(changed) {
+		t.Fatal("expected a different operator to change the hash")
+	}
+	disabled := base
+	disabled.Enabled = false
+	if Hash(base) == Hash(disabled) {
+		t.Fatal("expected enabling to change the hash")
+	}
+}
@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Operator types understood by the matcher. "lists" operators reference
+// files on the daemon host and never match here.
+const (
+	OperatorSimple  = "simple"
+	OperatorRegexp  = "regexp"
+	OperatorNetwork = "network"
+	OperatorList    = "list"
+	OperatorLists   = "lists"
+)
+
+// Matcher evaluates connections against a rule list compiled once up front,
+// so it is cheap enough to call while rendering.
+type Matcher struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	rule state.Rule
+	op   compiledOperator
+}
+
+type compiledOperator struct {
+	kind      string
+	operand   string
+	data      string
+	sensitive bool
+	re        *regexp.Regexp
+	network   *net.IPNet
+	children  []compiledOperator
+	// always is set for the "true" operand, which matches everything.
+	always bool
+	// valid is false when the operator cannot be evaluated locally; such
+	// operators never match.
+	valid bool
+}
+
+// NewMatcher compiles rules for matching. Rules keep their order.
+func NewMatcher(rules []state.Rule) *Matcher {
+	m := &Matcher{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		m.rules = append(m.rules, compiledRule{rule: rule, op: compileOperator(rule.Operator)})
+	}
+	return m
+}
+
+// First returns the first rule whose operator matches conn.
+func (m *Matcher) First(conn state.Connection) (state.Rule, bool) {
+	if m == nil {
+		return state.Rule{}, false
+	}
+	for _, rule := range m.rules {
+		if rule.op.match(conn) {
+			return rule.rule, true
+		}
+	}
+	return state.Rule{}, false
+}
+
+// Matches reports whether the rule's operator matches conn.
+func Matches(rule state.Rule, conn state.Connection) bool {
+	return compileOperator(rule.Operator).match(conn)
+}
+
+func compileOperator(op state.RuleOperator) compiledOperator {
+	out := compiledOperator{
+		kind:      strings.ToLower(op.Type),
+		operand:   op.Operand,
+		data:      op.Data,
+		sensitive: op.Sensitive,
+		valid:     true,
+	}
+	if isListOperator(op) {
+		out.kind = OperatorList
+	} else if op.Operand == OperandTrue {
+		out.always = true
+		return out
+	}
+	switch out.kind {
+	case OperatorSimple:
+	case OperatorRegexp:
+		expr := op.Data
+		if !op.Sensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		out.re, out.valid = re, err == nil
+	case OperatorNetwork:
+		_, network, err := net.ParseCIDR(op.Data)
+		out.network, out.valid = network, err == nil
+	case OperatorList:
+		for _, child := range op.Children {
+			out.children = append(out.children, compileOperator(child))
+		}
+		out.valid = len(out.children) > 0
+	default:
+		out.valid = false
+	}
+	return out
+}
+
+func (op compiledOperator) match(conn state.Connection) bool {
+	if !op.valid {
+		return false
+	}
+	if op.always {
+		return true
+	}
+	if op.kind == OperatorList {
+		// The daemon has no "any of" list; every child must match.
+		for _, child := range op.children {
+			if !child.match(conn) {
+				return false
+			}
+		}
+		return true
+	}
+	value, ok := operandValue(op.operand, conn)
+	if !ok {
+		return false
+	}
+	switch op.kind {
+	case OperatorSimple:
+		if op.sensitive {
+			return value == op.data
+		}
+		return strings.EqualFold(value, op.data)
+	case OperatorRegexp:
+		return op.re.MatchString(value)
+	case OperatorNetwork:
+		return networkContains(op, value)
+	}
+	return false
+}
+
+// operandValue extracts the connection field an operand refers to. Unknown
+// operands and missing values report false so they never match.
+func operandValue(operand string, conn state.Connection) (string, bool) {
+	var value string
+	switch operand {
+	case "process.path":
+		value = conn.ProcessPath
+	case "process.command":
+		value = strings.Join(conn.ProcessArgs, " ")
+	case "process.id":
+		return strconv.FormatUint(uint64(conn.ProcessID), 10), true
+	case "user.id":
+		return strconv.FormatUint(uint64(conn.UserID), 10), true
+	case "dest.ip", "dest.network":
+		value = conn.DstIP
+	case "dest.host":
+		value = conn.DstHost
+	case "dest.port":
+		if conn.DstPort == 0 {
+			return "", false
+		}
+		return strconv.FormatUint(uint64(conn.DstPort), 10), true
+	case "source.ip", "source.network":
+		value = conn.SrcIP
+	case "protocol":
+		value = conn.Protocol
+	default:
+		return "", false
+	}
+	return value, value != ""
+}
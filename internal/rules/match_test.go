@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestMatcherOperatorTypes(t *testing.T) {
+	conn := state.Connection{
+		Protocol:    "tcp",
+		DstIP:       "10.1.2.3",
+		DstHost:     "API.Spotify.com",
+		DstPort:     443,
+		UserID:      1000,
+		ProcessPath: "/usr/bin/spotify",
+		ProcessArgs: []string{"/usr/bin/spotify", "--no-zygote"},
+	}
+	cases := []struct {
+		name string
+		op   state.RuleOperator
+		want bool
+	}{
+		{"simple path", state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/spotify"}, true},
+		{"simple host ignores case", state.RuleOperator{Type: "simple", Operand: "dest.host", Data: "api.spotify.com"}, true},
+		{"simple host sensitive", state.RuleOperator{Type: "simple", Operand: "dest.host", Data: "api.spotify.com", Sensitive: true}, false},
+		{"simple command", state.RuleOperator{Type: "simple", Operand: "process.command", Data: "/usr/bin/spotify --no-zygote"}, true},
+		{"simple port", state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "443"}, true},
+		{"simple user", state.RuleOperator{Type: "simple", Operand: "user.id", Data: "0"}, false},
+		{"regexp host", state.RuleOperator{Type: "regexp", Operand: "dest.host", Data: `\.spotify\.com$`}, true},
+		{"bad regexp", state.RuleOperator{Type: "regexp", Operand: "dest.host", Data: `(`}, false},
+		{"network", state.RuleOperator{Type: "network", Operand: "dest.network", Data: "10.0.0.0/8"}, true},
+		{"network miss", state.RuleOperator{Type: "network", Operand: "dest.network", Data: "192.168.0.0/16"}, false},
+		{"list all match", state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+			{Type: "simple", Operand: "process.path", Data: "/usr/bin/spotify"},
+			{Type: "simple", Operand: "dest.port", Data: "443"},
+		}}, true},
+		{"list one misses", state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+			{Type: "simple", Operand: "process.path", Data: "/usr/bin/spotify"},
+			{Type: "simple", Operand: "dest.port", Data: "80"},
+		}}, false},
+		{"empty list", state.RuleOperator{Type: "list", Operand: "list"}, false},
+		{"domain lists", state.RuleOperator{Type: "lists", Operand: "lists.domains", Data: "/etc/lists"}, false},
+		{"unknown operand", state.RuleOperator{Type: "simple", Operand: "process.env.HOME", Data: ""}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Matches(state.Rule{Operator: tc.op}, conn); got != tc.want {
+				t.Fatalf("Matches = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatcherMissingValuesNeverMatch(t *testing.T) {
+	// An empty connection field must not match an empty operator value.
+	rule := state.Rule{Operator: state.RuleOperator{Type: "simple", Operand: "dest.host", Data: ""}}
+	if Matches(rule, state.Connection{DstIP: "1.1.1.1"}) {
+		t.Fatalf("expected missing host not to match")
+	}
+}
+
+func TestMatcherFirstKeepsOrder(t *testing.T) {
+	m := NewMatcher([]state.Rule{
+		{Name: "other", Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"}},
+		{Name: "web", Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "443"}},
+		{Name: "web-2", Operator: state.RuleOperator{Type: "simple", Operand: "protocol", Data: "tcp"}},
+	})
+	rule, ok := m.First(state.Connection{DstPort: 443, Protocol: "tcp"})
+	if !ok || rule.Name != "web" {
+		t.Fatalf("expected first matching rule, got %+v ok=%v", rule, ok)
+	}
+	if _, ok := (*Matcher)(nil).First(state.Connection{}); ok {
+		t.Fatalf("expected nil matcher to match nothing")
+	}
+}
@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultNameTemplate yields names like "allow-always-simple-usr-bin-curl".
+const DefaultNameTemplate = "{{.Action}}-{{.Duration}}-{{.Type}}-{{.Slug}}"
+
+// maxSlugLength caps the data part of a generated name; longer data keeps
+// a prefix and gains a short hash so distinct values stay distinct.
+const maxSlugLength = 40
+
+// NameParts are the fields available to a rule name template.
+type NameParts struct {
+	Action   string
+	Duration string
+	// Type is the operator type, e.g. "simple".
+	Type string
+	// Target is the prompt target, e.g. "process.path".
+	Target string
+	// Data is the matched value, e.g. "/usr/bin/curl"; templates see it
+	// slugified as Slug.
+	Data string
+}
+
+type nameFields struct {
+	Action, Duration, Type, Target, Slug string
+}
+
+var (
+	nonSlugChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+	dashRuns     = regexp.MustCompile(`-{2,}`)
+)
+
+// ParseNameTemplate checks a rule name template, returning the default for
+// an empty one.
+func ParseNameTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultNameTemplate
+	}
+	tmpl, err := template.New("rule-name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(&strings.Builder{}, nameFields{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// GenerateName renders parts through the name template (falling back to
+// DefaultNameTemplate when it is invalid), keeps the result within maxLen
+// runes and appends "-2", "-3", ... until it differs from every name in
+// existing.
+func GenerateName(templateText string, parts NameParts, existing []string, maxLen int) string {
+	tmpl, err := ParseNameTemplate(templateText)
+	if err != nil {
+		tmpl, _ = ParseNameTemplate(DefaultNameTemplate)
+	}
+	fields := nameFields{
+		Action:   Slugify(parts.Action),
+		Duration: Slugify(parts.Duration),
+		Type:     Slugify(parts.Type),
+		Target:   Slugify(parts.Target),
+		Slug:     dataSlug(parts.Data),
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, fields); err != nil {
+		out.Reset()
+	}
+	base := Slugify(out.String())
+	if base == "" {
+		base = "rule"
+	}
+	// Leave room for the uniqueness suffix.
+	if runes := []rune(base); maxLen > 8 && len(runes) > maxLen-4 {
+		base = strings.TrimRight(string(runes[:maxLen-4]), "-._")
+	}
+
+	taken := make(map[string]struct{}, len(existing))
+	for _, name := range existing {
+		taken[name] = struct{}{}
+	}
+	if _, ok := taken[base]; !ok {
+		return base
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// Slugify lowercases s and replaces runs of characters outside
+// [a-z0-9._-] with a single dash.
+func Slugify(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = nonSlugChars.ReplaceAllString(s, "-")
+	s = dashRuns.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-._")
+}
+
+func dataSlug(data string) string {
+	slug := Slugify(data)
+	if len(slug) <= maxSlugLength {
+		return slug
+	}
+	sum := sha256.Sum256([]byte(data))
+	prefix := strings.TrimRight(slug[:maxSlugLength-9], "-._")
+	return prefix + "-" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+var curlParts = NameParts{
+	Action:   "allow",
+	Duration: "always",
+	Type:     "simple",
+	Target:   "process.path",
+	Data:     "/usr/bin/curl",
+}
+
+func TestGenerateNameDefaultTemplate(t *testing.T) {
+	if got := GenerateName("", curlParts, nil, DefaultMaxText); got != "allow-always-simple-usr-bin-curl" {
+		t.Fatalf("unexpected name %q", got)
+	}
+	noType := curlParts
+	noType.Type = ""
+	if got := GenerateName("", noType, nil, DefaultMaxText); got != "allow-always-usr-bin-curl" {
+		t.Fatalf("empty fields should not leave double dashes, got %q", got)
+	}
+	if got := GenerateName("", NameParts{}, nil, DefaultMaxText); got != "rule" {
+		t.Fatalf("expected fallback name, got %q", got)
+	}
+}
+
+func TestGenerateNameCollisions(t *testing.T) {
+	existing := []string{"allow-always-simple-usr-bin-curl", "allow-always-simple-usr-bin-curl-2"}
+	if got := GenerateName("", curlParts, existing, DefaultMaxText); got != "allow-always-simple-usr-bin-curl-3" {
+		t.Fatalf("expected next free suffix, got %q", got)
+	}
+	// The same inputs always produce the same name.
+	if a, b := GenerateName("", curlParts, existing, DefaultMaxText), GenerateName("", curlParts, existing, DefaultMaxText); a != b {
+		t.Fatalf("generation is not deterministic: %q vs %q", a, b)
+	}
+}
+
+func TestGenerateNameTemplate(t *testing.T) {
+	got := GenerateName("{{.Action}}-{{.Target}}-{{.Slug}}", curlParts, nil, DefaultMaxText)
+	if got != "allow-process.path-usr-bin-curl" {
+		t.Fatalf("unexpected templated name %q", got)
+	}
+	// Invalid templates fall back to the default rather than failing.
+	for _, bad := range []string{"{{.Action", "{{.Missing}}"} {
+		if _, err := ParseNameTemplate(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+		if got := GenerateName(bad, curlParts, nil, DefaultMaxText); got != "allow-always-simple-usr-bin-curl" {
+			t.Fatalf("template %q: expected default name, got %q", bad, got)
+		}
+	}
+}
+
+func TestGenerateNameHashesLongData(t *testing.T) {
+	long := curlParts
+	long.Data = "/opt/app/" + strings.Repeat("x", 200)
+	other := long
+	other.Data = long.Data + "y"
+
+	a := GenerateName("", long, nil, DefaultMaxText)
+	b := GenerateName("", other, nil, DefaultMaxText)
+	if len(a) > 64 {
+		t.Fatalf("expected a short name, got %d chars: %q", len(a), a)
+	}
+	if a == b {
+		t.Fatalf("distinct long data should hash to distinct names, both %q", a)
+	}
+	if !strings.HasPrefix(a, "allow-always-simple-opt-app-xxx") {
+		t.Fatalf("expected a readable prefix, got %q", a)
+	}
+}
+
+func TestGenerateNameRespectsMaxLength(t *testing.T) {
+	existing := []string{GenerateName("", curlParts, nil, 20)}
+	got := GenerateName("", curlParts, existing, 20)
+	if len([]rune(got)) > 20 {
+		t.Fatalf("name exceeds limit: %q", got)
+	}
+	if got == existing[0] {
+		t.Fatalf("expected a unique name, got %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+)
+
+// Operands with special meaning to the daemon. The daemon picks list
+// evaluation from the operand, not the type, and rewrites the operand of a
+// "list" operator to "list" when it compiles the rule.
+const (
+	OperandList = "list"
+	OperandTrue = "true"
+)
+
+// Combinator is how the daemon combines an operator's conditions.
+type Combinator int
+
+const (
+	// CombineNone is a single condition.
+	CombineNone Combinator = iota
+	// CombineAll is a list operator: every child must match.
+	CombineAll
+	// CombineAny is a lists operator: the value must match any entry of
+	// the list files.
+	CombineAny
+)
+
+// Join is the symbol rendered between combined conditions.
+func (c Combinator) Join() string {
+	switch c {
+	case CombineAll:
+		return glyphs.Current().And
+	case CombineAny:
+		return glyphs.Current().Or
+	}
+	return ""
+}
+
+// Semantics reports how op's conditions combine, the way the daemon
+// evaluates it.
+func Semantics(op state.RuleOperator) Combinator {
+	switch {
+	case isListOperator(op):
+		return CombineAll
+	case strings.EqualFold(op.Type, OperatorLists):
+		return CombineAny
+	}
+	return CombineNone
+}
+
+func isListOperator(op state.RuleOperator) bool {
+	return op.Operand == OperandList || strings.EqualFold(op.Type, OperatorList)
+}
+
+// NormalizeOperator spells list operators the way the daemon evaluates
+// them, with both the type and the operand set to "list", however the
+// daemon or a rule file sent them. Children are normalized too.
+func NormalizeOperator(op state.RuleOperator) state.RuleOperator {
+	if isListOperator(op) {
+		op.Type, op.Operand = OperatorList, OperandList
+	}
+	if len(op.Children) == 0 {
+		return op
+	}
+	children := make([]state.RuleOperator, len(op.Children))
+	for i, child := range op.Children {
+		children[i] = NormalizeOperator(child)
+	}
+	op.Children = children
+	return op
+}
+
+// OperatorTree renders op one condition per line, with list operators as
+// branches labelled by how their children combine.
+func OperatorTree(op state.RuleOperator) []string {
+	var lines []string
+	operatorTree(op, "", "", &lines)
+	return lines
+}
+
+func operatorTree(op state.RuleOperator, first, rest string, lines *[]string) {
+	if Semantics(op) != CombineAll {
+		*lines = append(*lines, first+DescribeOperator(op))
+		return
+	}
+	*lines = append(*lines, fmt.Sprintf("%s%s all of", first, CombineAll.Join()))
+	g := glyphs.Current()
+	for i, child := range op.Children {
+		branch, indent := g.Branch+g.Line+" ", g.Pipe+"  "
+		if i == len(op.Children)-1 {
+			branch, indent = g.Last+g.Line+" ", "   "
+		}
+		operatorTree(child, rest+branch, rest+indent, lines)
+	}
+}
@@ -0,0 +1,132 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+)
+
+// Fixtures follow the daemon: the "list" operand makes every child
+// required, "lists" operators match any entry of their files, and the
+// "true" operand matches everything.
+var (
+	curlPath = state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}
+	port443  = state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "443"}
+	port80   = state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "80"}
+	hostRe   = state.RuleOperator{Type: "regexp", Operand: "dest.host", Data: `^(api|www)\.example\.com$`}
+	tcp      = state.RuleOperator{Type: "simple", Operand: "protocol", Data: "tcp"}
+)
+
+func listOf(children ...state.RuleOperator) state.RuleOperator {
+	return state.RuleOperator{Type: "list", Operand: "list", Children: children}
+}
+
+func TestMatcherListSemantics(t *testing.T) {
+	conn := state.Connection{ProcessPath: "/usr/bin/curl", DstHost: "api.example.com", DstPort: 443, Protocol: "tcp"}
+	cases := []struct {
+		name string
+		op   state.RuleOperator
+		want bool
+	}{
+		{"all children match", listOf(curlPath, port443, tcp), true},
+		{"last child misses", listOf(curlPath, tcp, port80), false},
+		{"first child misses", listOf(port80, curlPath), false},
+		{"regexp alternation inside a list", listOf(curlPath, hostRe), true},
+		{"nested lists", listOf(curlPath, listOf(port443, hostRe)), true},
+		{"nested list misses", listOf(curlPath, listOf(port443, port80)), false},
+		{"single child", listOf(port443), true},
+		{"list operand with another type", state.RuleOperator{Type: "simple", Operand: "list", Children: []state.RuleOperator{curlPath, port80}}, false},
+		{"list type without operand", state.RuleOperator{Type: "List", Children: []state.RuleOperator{curlPath, port443}}, true},
+		{"lists files are not read", state.RuleOperator{Type: "lists", Operand: "lists.domains", Data: "/etc/opensnitchd/lists"}, false},
+		{"lists inside a list", listOf(curlPath, state.RuleOperator{Type: "lists", Operand: "lists.domains", Data: "/etc/opensnitchd/lists"}), false},
+		{"true operand", state.RuleOperator{Type: "simple", Operand: "true"}, true},
+		{"true inside a list", listOf(state.RuleOperator{Type: "simple", Operand: "true"}, port443), true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Matches(state.Rule{Operator: tc.op}, conn); got != tc.want {
+				t.Fatalf("Matches = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDescribeOperatorJoins(t *testing.T) {
+	cases := []struct {
+		name string
+		op   state.RuleOperator
+		want string
+	}{
+		{"empty", state.RuleOperator{}, "-"},
+		{"leaf", curlPath, "simple process.path /usr/bin/curl"},
+		{"list", listOf(curlPath, port443), "(simple process.path /usr/bin/curl ∧ simple dest.port 443)"},
+		{"nested", listOf(curlPath, listOf(port443, tcp)), "(simple process.path /usr/bin/curl ∧ (simple dest.port 443 ∧ simple protocol tcp))"},
+		{"single child", listOf(port443), "simple dest.port 443"},
+		{"empty list", listOf(), "list (empty)"},
+		{"lists", state.RuleOperator{Type: "lists", Operand: "lists.domains", Data: "/etc/lists"}, "lists.domains ∨ entries in /etc/lists"},
+		{"true", state.RuleOperator{Type: "simple", Operand: "true"}, "always"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DescribeOperator(tc.op); got != tc.want {
+				t.Fatalf("DescribeOperator = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOperatorTree(t *testing.T) {
+	got := OperatorTree(listOf(curlPath, listOf(port443, tcp), hostRe))
+	want := []string{
+		"∧ all of",
+		"├─ simple process.path /usr/bin/curl",
+		"├─ ∧ all of",
+		"│  ├─ simple dest.port 443",
+		"│  └─ simple protocol tcp",
+		`└─ regexp dest.host ^(api|www)\.example\.com$`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("OperatorTree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := OperatorTree(curlPath); len(got) != 1 || got[0] != DescribeOperator(curlPath) {
+		t.Fatalf("expected a leaf to render on one line, got %q", got)
+	}
+}
+
+func TestOperatorTreeASCII(t *testing.T) {
+	glyphs.SetASCII(true)
+	t.Cleanup(func() { glyphs.SetASCII(false) })
+
+	got := OperatorTree(listOf(curlPath, listOf(port443, tcp)))
+	want := []string{
+		"& all of",
+		"|- simple process.path /usr/bin/curl",
+		"`- & all of",
+		"   |- simple dest.port 443",
+		"   `- simple protocol tcp",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("OperatorTree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNormalizeOperator(t *testing.T) {
+	in := state.RuleOperator{Type: "List", Children: []state.RuleOperator{
+		curlPath,
+		{Type: "simple", Operand: "list", Children: []state.RuleOperator{port443}},
+	}}
+	got := NormalizeOperator(in)
+	want := listOf(curlPath, listOf(port443))
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeOperator = %+v, want %+v", got, want)
+	}
+	if in.Children[1].Type != "simple" {
+		t.Fatalf("NormalizeOperator modified its input")
+	}
+	if Semantics(got) != CombineAll || Semantics(curlPath) != CombineNone || Semantics(state.RuleOperator{Type: "lists"}) != CombineAny {
+		t.Fatalf("unexpected semantics")
+	}
+}
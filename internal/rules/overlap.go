@@ -0,0 +1,239 @@
+package rules
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Overlap is another rule that can match some of the same connections.
+type Overlap struct {
+	Rule state.Rule
+	// Wins is true when Rule takes effect over the analysed rule on
+	// connections both match.
+	Wins bool
+}
+
+// Analysis holds the pairwise overlaps between the enabled rules of a node.
+// Overlap is conservative: operators that cannot be compared precisely (two
+// different regexps, for instance) are treated as possibly overlapping, but
+// a rule is only reported as shadowed when that is certain.
+type Analysis struct {
+	overlaps map[string][]Overlap
+	shadowed map[string]string
+}
+
+// Overlaps returns the rules overlapping the named rule, in name order.
+func (a Analysis) Overlaps(name string) []Overlap {
+	return a.overlaps[name]
+}
+
+// ShadowedBy returns the rule that matches every connection the named rule
+// matches and always wins over it, so the named rule can never fire.
+func (a Analysis) ShadowedBy(name string) (string, bool) {
+	by, ok := a.shadowed[name]
+	return by, ok
+}
+
+// Wins reports whether a takes effect over b on a connection both match.
+// The daemon checks rules in name order: the first matching deny, reject or
+// precedence rule applies at once, otherwise the last matching rule does.
+func Wins(a, b state.Rule) bool {
+	first, second := a, b
+	if b.Name < a.Name {
+		first, second = b, a
+	}
+	winner := second
+	if isFinal(first) {
+		winner = first
+	}
+	return winner.Name == a.Name
+}
+
+func isFinal(rule state.Rule) bool {
+	return rule.Precedence || rule.Action == "deny" || rule.Action == "reject"
+}
+
+// Analyze computes overlaps and shadowing between the enabled rules.
+func Analyze(rules []state.Rule) Analysis {
+	type entry struct {
+		rule  state.Rule
+		shape ruleShape
+	}
+	entries := make([]entry, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		shape := shapeOf(compileOperator(rule.Operator))
+		if shape.ok {
+			entries = append(entries, entry{rule: rule, shape: shape})
+		}
+	}
+	analysis := Analysis{overlaps: make(map[string][]Overlap), shadowed: make(map[string]string)}
+	for i := range entries {
+		for j := range entries {
+			if i == j {
+				continue
+			}
+			self, other := entries[i], entries[j]
+			if !self.shape.overlaps(other.shape) {
+				continue
+			}
+			wins := Wins(other.rule, self.rule)
+			analysis.overlaps[self.rule.Name] = append(analysis.overlaps[self.rule.Name], Overlap{Rule: other.rule, Wins: wins})
+			if _, done := analysis.shadowed[self.rule.Name]; !done && wins && other.shape.covers(self.shape) {
+				analysis.shadowed[self.rule.Name] = other.rule.Name
+			}
+		}
+	}
+	for _, list := range analysis.overlaps {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Rule.Name < list[j].Rule.Name })
+	}
+	return analysis
+}
+
+// ruleShape is a rule operator flattened into the conditions it places on
+// each connection field; all of them must hold.
+type ruleShape struct {
+	fields map[string][]compiledOperator
+	ok     bool
+}
+
+func shapeOf(op compiledOperator) ruleShape {
+	shape := ruleShape{fields: make(map[string][]compiledOperator), ok: true}
+	shape.add(op)
+	return shape
+}
+
+func (s *ruleShape) add(op compiledOperator) {
+	if !op.valid {
+		s.ok = false
+		return
+	}
+	if op.always {
+		return
+	}
+	if op.kind == OperatorList {
+		for _, child := range op.children {
+			s.add(child)
+		}
+		return
+	}
+	field, known := canonicalOperand(op.operand)
+	if !known {
+		s.ok = false
+		return
+	}
+	s.fields[field] = append(s.fields[field], op)
+}
+
+// overlaps reports whether some connection may satisfy both shapes.
+func (s ruleShape) overlaps(other ruleShape) bool {
+	for field, mine := range s.fields {
+		for _, a := range mine {
+			for _, b := range other.fields[field] {
+				if !leafOverlaps(a, b) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// covers reports whether every connection satisfying other satisfies s.
+func (s ruleShape) covers(other ruleShape) bool {
+	for field, mine := range s.fields {
+		theirs := other.fields[field]
+		for _, a := range mine {
+			covered := false
+			for _, b := range theirs {
+				if leafCovers(a, b) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func canonicalOperand(operand string) (string, bool) {
+	switch operand {
+	case "dest.network":
+		return "dest.ip", true
+	case "source.network":
+		return "source.ip", true
+	case "process.path", "process.command", "process.id", "user.id", "dest.ip", "dest.host", "dest.port", "source.ip", "protocol":
+		return operand, true
+	}
+	return "", false
+}
+
+func sameValue(a, b compiledOperator) bool {
+	if a.sensitive && b.sensitive {
+		return a.data == b.data
+	}
+	return strings.EqualFold(a.data, b.data)
+}
+
+func networkContains(op compiledOperator, value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && op.network.Contains(ip)
+}
+
+// leafOverlaps reports whether some value may satisfy both conditions.
+func leafOverlaps(a, b compiledOperator) bool {
+	if b.kind == OperatorSimple && a.kind != OperatorSimple {
+		a, b = b, a
+	}
+	switch {
+	case a.kind == OperatorSimple && b.kind == OperatorSimple:
+		return sameValue(a, b)
+	case a.kind == OperatorSimple && b.kind == OperatorRegexp:
+		return b.re.MatchString(a.data)
+	case a.kind == OperatorSimple && b.kind == OperatorNetwork:
+		return networkContains(b, a.data)
+	case a.kind == OperatorNetwork && b.kind == OperatorNetwork:
+		return a.network.Contains(b.network.IP) || b.network.Contains(a.network.IP)
+	}
+	// Two regexps, or a regexp and a network, cannot be compared cheaply.
+	return true
+}
+
+// leafCovers reports whether every value satisfying b satisfies a.
+func leafCovers(a, b compiledOperator) bool {
+	switch a.kind {
+	case OperatorSimple:
+		if b.kind != OperatorSimple {
+			return false
+		}
+		if a.sensitive && !b.sensitive {
+			return false
+		}
+		return sameValue(a, b)
+	case OperatorRegexp:
+		switch b.kind {
+		case OperatorSimple:
+			return (!a.sensitive || b.sensitive) && a.re.MatchString(b.data)
+		case OperatorRegexp:
+			return a.data == b.data && (!a.sensitive || b.sensitive)
+		}
+	case OperatorNetwork:
+		switch b.kind {
+		case OperatorSimple:
+			return networkContains(a, b.data)
+		case OperatorNetwork:
+			aOnes, _ := a.network.Mask.Size()
+			bOnes, _ := b.network.Mask.Size()
+			return a.network.Contains(b.network.IP) && aOnes <= bOnes
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func overlapRule(name, action string, op state.RuleOperator) state.Rule {
+	return state.Rule{Name: name, Action: action, Enabled: true, Operator: op}
+}
+
+func TestWinsFollowsEvaluationOrder(t *testing.T) {
+	allowA := state.Rule{Name: "000-allow", Action: "allow"}
+	allowB := state.Rule{Name: "001-allow", Action: "allow"}
+	deny := state.Rule{Name: "002-deny", Action: "deny"}
+	precedent := state.Rule{Name: "000-allow", Action: "allow", Precedence: true}
+
+	if Wins(allowA, allowB) || !Wins(allowB, allowA) {
+		t.Fatalf("later allow should win over an earlier allow")
+	}
+	if !Wins(deny, allowB) {
+		t.Fatalf("deny should win over an earlier allow")
+	}
+	if !Wins(precedent, deny) {
+		t.Fatalf("earlier precedence rule should win over a later deny")
+	}
+}
+
+func TestAnalyzeShadowedRule(t *testing.T) {
+	rules := []state.Rule{
+		overlapRule("000-block-firefox", "deny", state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/firefox"}),
+		overlapRule("001-allow-firefox-https", "allow", state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+			{Type: "simple", Operand: "process.path", Data: "/usr/bin/firefox"},
+			{Type: "simple", Operand: "dest.port", Data: "443"},
+		}}),
+	}
+	analysis := Analyze(rules)
+	by, ok := analysis.ShadowedBy("001-allow-firefox-https")
+	if !ok || by != "000-block-firefox" {
+		t.Fatalf("ShadowedBy = %q, %v; want 000-block-firefox", by, ok)
+	}
+	if _, ok := analysis.ShadowedBy("000-block-firefox"); ok {
+		t.Fatalf("broader deny must not be shadowed")
+	}
+	overlaps := analysis.Overlaps("000-block-firefox")
+	if len(overlaps) != 1 || overlaps[0].Rule.Name != "001-allow-firefox-https" || overlaps[0].Wins {
+		t.Fatalf("unexpected overlaps: %+v", overlaps)
+	}
+}
+
+func TestAnalyzePartialOverlap(t *testing.T) {
+	rules := []state.Rule{
+		overlapRule("000-allow-lan", "allow", state.RuleOperator{Type: "network", Operand: "dest.network", Data: "10.0.0.0/8"}),
+		overlapRule("001-deny-curl", "deny", state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}),
+		overlapRule("002-deny-subnet", "deny", state.RuleOperator{Type: "network", Operand: "dest.network", Data: "10.1.0.0/16"}),
+	}
+	analysis := Analyze(rules)
+	overlaps := analysis.Overlaps("000-allow-lan")
+	if len(overlaps) != 2 {
+		t.Fatalf("expected two overlaps, got %+v", overlaps)
+	}
+	for _, overlap := range overlaps {
+		if !overlap.Wins {
+			t.Fatalf("deny %s should win over the allow", overlap.Rule.Name)
+		}
+	}
+	for _, name := range []string{"000-allow-lan", "001-deny-curl", "002-deny-subnet"} {
+		if by, ok := analysis.ShadowedBy(name); ok {
+			t.Fatalf("%s reported shadowed by %s", name, by)
+		}
+	}
+}
+
+func TestAnalyzeIndependentRules(t *testing.T) {
+	rules := []state.Rule{
+		overlapRule("000-allow-ssh", "allow", state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"}),
+		overlapRule("001-deny-telnet", "deny", state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "23"}),
+		overlapRule("002-allow-lan-web", "allow", state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+			{Type: "network", Operand: "dest.network", Data: "192.168.0.0/16"},
+			{Type: "simple", Operand: "dest.port", Data: "443"},
+		}}),
+		overlapRule("003-deny-host", "deny", state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+			{Type: "simple", Operand: "dest.ip", Data: "192.168.1.1"},
+			{Type: "simple", Operand: "dest.port", Data: "80"},
+		}}),
+		overlapRule("004-disabled", "deny", state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"}),
+	}
+	rules[4].Enabled = false
+	analysis := Analyze(rules)
+	for _, rule := range rules {
+		if overlaps := analysis.Overlaps(rule.Name); len(overlaps) != 0 {
+			t.Fatalf("%s: unexpected overlaps %+v", rule.Name, overlaps)
+		}
+	}
+}
+
+func TestAnalyzeRegexpCoverage(t *testing.T) {
+	rules := []state.Rule{
+		overlapRule("000-deny-tracking", "deny", state.RuleOperator{Type: "regexp", Operand: "dest.host", Data: `\.doubleclick\.net$`}),
+		overlapRule("001-allow-ads", "allow", state.RuleOperator{Type: "simple", Operand: "dest.host", Data: "ad.doubleclick.net"}),
+		overlapRule("002-allow-other", "allow", state.RuleOperator{Type: "regexp", Operand: "dest.host", Data: `^ad\.`}),
+		overlapRule("003-lists", "deny", state.RuleOperator{Type: "lists", Operand: "lists.domains", Data: "/etc/lists"}),
+	}
+	analysis := Analyze(rules)
+	if by, ok := analysis.ShadowedBy("001-allow-ads"); !ok || by != "000-deny-tracking" {
+		t.Fatalf("ShadowedBy = %q, %v; want 000-deny-tracking", by, ok)
+	}
+	// Different regexps may overlap, but coverage is unknown.
+	if _, ok := analysis.ShadowedBy("002-allow-other"); ok {
+		t.Fatalf("regexp rule must not be reported shadowed by a different regexp")
+	}
+	if len(analysis.Overlaps("002-allow-other")) != 2 {
+		t.Fatalf("expected regexp rule to overlap both others, got %+v", analysis.Overlaps("002-allow-other"))
+	}
+	if len(analysis.Overlaps("003-lists")) != 0 {
+		t.Fatalf("lists operators cannot be analysed and should report nothing")
+	}
+}
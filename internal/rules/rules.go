@@ -0,0 +1,92 @@
+// Package rules holds helpers for working with daemon rules that are shared
+// between views and the daemon server.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// Find returns the index of the rule named name, or -1.
+func Find(list []state.Rule, name string) int {
+	for idx, rule := range list {
+		if rule.Name == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+// FindPrefix returns the index of the first rule whose name starts with
+// prefix (case-insensitive), or -1.
+func FindPrefix(list []state.Rule, prefix string) int {
+	prefix = strings.ToLower(prefix)
+	for idx, rule := range list {
+		if strings.HasPrefix(strings.ToLower(rule.Name), prefix) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// Lookup resolves a rule by name on a specific node. Rules with the same
+// name on other nodes never match.
+func Lookup(byNode map[string][]state.Rule, nodeID, name string) (state.Rule, bool) {
+	if name == "" {
+		return state.Rule{}, false
+	}
+	list := byNode[nodeID]
+	if idx := Find(list, name); idx >= 0 {
+		return list[idx], true
+	}
+	return state.Rule{}, false
+}
+
+// DescribeOperator renders an operator tree as a compact one-liner. List
+// children are joined with ∧ since all of them must match; lists operators
+// match any entry of their files, shown with ∨.
+func DescribeOperator(op state.RuleOperator) string {
+	if op.Type == "" && op.Operand == "" && op.Data == "" && len(op.Children) == 0 {
+		return "-"
+	}
+	switch Semantics(op) {
+	case CombineAll:
+		if len(op.Children) == 0 {
+			return "list (empty)"
+		}
+		childParts := make([]string, len(op.Children))
+		for i, child := range op.Children {
+			childParts[i] = DescribeOperator(child)
+		}
+		if len(childParts) == 1 {
+			return childParts[0]
+		}
+		return "(" + strings.Join(childParts, " "+CombineAll.Join()+" ") + ")"
+	case CombineAny:
+		return strings.TrimSpace(fmt.Sprintf("%s %s entries in %s", op.Operand, CombineAny.Join(), util.Fallback(op.Data, "-")))
+	}
+	if op.Operand == OperandTrue {
+		return "always"
+	}
+	parts := []string{op.Type}
+	if op.Operand != "" {
+		parts = append(parts, op.Operand)
+	}
+	if op.Data != "" {
+		parts = append(parts, op.Data)
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// Summary renders action, duration, enabled flag and operator on one line.
+func Summary(rule state.Rule) string {
+	enabled := "disabled"
+	if rule.Enabled {
+		enabled = "enabled"
+	}
+	return fmt.Sprintf("%s · %s · %s · %s",
+		util.Fallback(rule.Action, "-"), util.Fallback(rule.Duration, "-"), enabled, DescribeOperator(rule.Operator))
+}
@@ -0,0 +1,33 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestLookupIsScopedToNode(t *testing.T) {
+	byNode := map[string][]state.Rule{
+		"a": {{Name: "web", Action: "allow"}},
+		"b": {{Name: "web", Action: "deny"}, {Name: "dns"}},
+	}
+	if rule, ok := Lookup(byNode, "b", "web"); !ok || rule.Action != "deny" {
+		t.Fatalf("expected node b's web rule, got %+v ok=%v", rule, ok)
+	}
+	if _, ok := Lookup(byNode, "a", "dns"); ok {
+		t.Fatalf("expected dns to be missing on node a")
+	}
+	if _, ok := Lookup(byNode, "a", ""); ok {
+		t.Fatalf("expected empty name not to resolve")
+	}
+}
+
+func TestFindPrefixIsCaseInsensitive(t *testing.T) {
+	list := []state.Rule{{Name: "allow-curl"}, {Name: "Deny-DNS"}}
+	if got := FindPrefix(list, "deny"); got != 1 {
+		t.Fatalf("expected index 1, got %d", got)
+	}
+	if got := FindPrefix(list, "zzz"); got != -1 {
+		t.Fatalf("expected -1, got %d", got)
+	}
+}
@@ -0,0 +1,86 @@
+package rules
+
+import "github.com/adamkadaban/opensnitch-tui/internal/state"
+
+// Starter is one rule of the starter pack: local-network traffic nearly every
+// desktop makes and nobody wants to be prompted about on a fresh install.
+type Starter struct {
+	// Label is the checklist entry.
+	Label string
+	// Example is a connection the rule allows.
+	Example state.Connection
+	// Rule is pushed as is: allow, always, enabled.
+	Rule state.Rule
+}
+
+// StarterPack returns the starter rules in checklist order, built afresh on
+// each call.
+func StarterPack() []Starter {
+	return []Starter{
+		{
+			Label:   "mDNS multicast (avahi)",
+			Example: state.Connection{Protocol: "udp", DstIP: "224.0.0.251", DstPort: 5353, ProcessPath: "/usr/sbin/avahi-daemon"},
+			Rule: starterRule("starter-allow-mdns",
+				"Multicast DNS service discovery on the local network (224.0.0.251 / ff02::fb, port 5353)",
+				allOf(
+					opRegexp("protocol", `^udp6?$`),
+					opSimple("dest.port", "5353"),
+					opRegexp("dest.ip", `^(224\.0\.0\.251|ff02::fb)$`),
+				)),
+		},
+		{
+			Label:   "Local DNS stub (systemd-resolved)",
+			Example: state.Connection{Protocol: "udp", DstIP: "127.0.0.53", DstPort: 53, ProcessPath: "/usr/bin/curl"},
+			Rule: starterRule("starter-allow-resolved",
+				"DNS queries to the systemd-resolved stub listener on 127.0.0.53",
+				allOf(
+					opSimple("dest.ip", "127.0.0.53"),
+					opSimple("dest.port", "53"),
+				)),
+		},
+		{
+			Label:   "Time sync (chrony/ntpd)",
+			Example: state.Connection{Protocol: "udp", DstIP: "162.159.200.1", DstPort: 123, ProcessPath: "/usr/sbin/chronyd"},
+			Rule: starterRule("starter-allow-ntp",
+				"NTP time synchronisation by chronyd or ntpd (udp port 123)",
+				allOf(
+					opRegexp("process.path", `^/usr/s?bin/(chronyd|ntpd)$`),
+					opSimple("protocol", "udp"),
+					opSimple("dest.port", "123"),
+				)),
+		},
+		{
+			Label:   "DHCP client",
+			Example: state.Connection{Protocol: "udp", DstIP: "255.255.255.255", DstPort: 67, ProcessPath: "/usr/sbin/dhclient"},
+			Rule: starterRule("starter-allow-dhcp",
+				"DHCP address requests to DHCPv4 (port 67) and DHCPv6 (port 547) servers",
+				allOf(
+					opRegexp("protocol", `^udp6?$`),
+					opRegexp("dest.port", `^(67|547)$`),
+				)),
+		},
+	}
+}
+
+func starterRule(name, description string, op state.RuleOperator) state.Rule {
+	return state.Rule{
+		Name:        name,
+		Description: description,
+		Action:      "allow",
+		Duration:    "always",
+		Enabled:     true,
+		Operator:    op,
+	}
+}
+
+func opSimple(operand, data string) state.RuleOperator {
+	return state.RuleOperator{Type: OperatorSimple, Operand: operand, Data: data}
+}
+
+func opRegexp(operand, data string) state.RuleOperator {
+	return state.RuleOperator{Type: OperatorRegexp, Operand: operand, Data: data}
+}
+
+func allOf(children ...state.RuleOperator) state.RuleOperator {
+	return state.RuleOperator{Type: OperatorList, Operand: OperandList, Children: children}
+}
@@ -0,0 +1,47 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestStarterPackRulesAreValid(t *testing.T) {
+	limits := LimitsFor(state.Settings{})
+	names := make(map[string]bool)
+	for _, starter := range StarterPack() {
+		rule := starter.Rule
+		if err := limits.Validate(rule); err != nil {
+			t.Errorf("%s: %v", rule.Name, err)
+		}
+		if !strings.HasPrefix(rule.Name, "starter-") || rule.Description == "" || starter.Label == "" {
+			t.Errorf("%s: expected a starter- name, a description and a label", rule.Name)
+		}
+		if names[rule.Name] {
+			t.Errorf("duplicate rule name %s", rule.Name)
+		}
+		names[rule.Name] = true
+		if rule.Action != "allow" || rule.Duration != "always" || !rule.Enabled {
+			t.Errorf("%s: expected an enabled allow-always rule, got %s/%s", rule.Name, rule.Action, rule.Duration)
+		}
+		if !compileOperator(rule.Operator).valid {
+			t.Errorf("%s: operator does not compile", rule.Name)
+		}
+		if !Matches(rule, starter.Example) {
+			t.Errorf("%s: does not match its example %+v", rule.Name, starter.Example)
+		}
+	}
+	if len(names) != 4 {
+		t.Fatalf("expected 4 starter rules, got %d", len(names))
+	}
+}
+
+func TestStarterPackStaysLocal(t *testing.T) {
+	web := state.Connection{Protocol: "tcp", DstIP: "93.184.216.34", DstPort: 443, ProcessPath: "/usr/bin/curl"}
+	for _, starter := range StarterPack() {
+		if Matches(starter.Rule, web) {
+			t.Errorf("%s matches ordinary web traffic", starter.Rule.Name)
+		}
+	}
+}
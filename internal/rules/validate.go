@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Default limits for user-supplied rule fields. The daemon accepts far larger
+// values, but they make every table, detail pane and notification unwieldy.
+const (
+	DefaultMaxOperatorData = 4096
+	DefaultMaxText         = 256
+)
+
+// Limits bounds the length of rule fields, counted in runes.
+type Limits struct {
+	OperatorData int
+	Text         int
+}
+
+// LimitsFor returns the limits configured in settings, falling back to the
+// defaults for unset values.
+func LimitsFor(settings state.Settings) Limits {
+	limits := Limits{OperatorData: settings.MaxOperatorData, Text: settings.MaxRuleText}
+	if limits.OperatorData <= 0 {
+		limits.OperatorData = DefaultMaxOperatorData
+	}
+	if limits.Text <= 0 {
+		limits.Text = DefaultMaxText
+	}
+	return limits
+}
+
+// Validate checks the rule's name, description and operator data (including
+// nested list operators) against the limits.
+func (l Limits) Validate(rule state.Rule) error {
+	if err := checkLength("rule name", rule.Name, l.Text); err != nil {
+		return err
+	}
+	if err := checkLength("description", rule.Description, l.Text); err != nil {
+		return err
+	}
+	return l.ValidateOperator(rule.Operator)
+}
+
+// ValidateOperator checks operator data against the limit.
+func (l Limits) ValidateOperator(op state.RuleOperator) error {
+	if err := checkLength("operator data", op.Data, l.OperatorData); err != nil {
+		return err
+	}
+	for _, child := range op.Children {
+		if err := l.ValidateOperator(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkLength(field, value string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	if n := utf8.RuneCountInString(value); n > limit {
+		return fmt.Errorf("%s is %d characters long; the limit is %d", field, n, limit)
+	}
+	return nil
+}
+
+// operatorTypes are the operator types the daemon evaluates.
+var operatorTypes = map[string]bool{
+	OperatorSimple:  true,
+	OperatorRegexp:  true,
+	OperatorNetwork: true,
+	OperatorList:    true,
+	OperatorLists:   true,
+}
+
+// operands are the operands the daemon evaluates; process.env. takes the
+// variable name after it.
+var operands = map[string]bool{
+	OperandTrue:            true,
+	OperandList:            true,
+	"process.path":         true,
+	"process.parent.path":  true,
+	"process.command":      true,
+	"process.id":           true,
+	"process.hash.md5":     true,
+	"process.hash.sha1":    true,
+	"user.id":              true,
+	"user.name":            true,
+	"source.ip":            true,
+	"source.port":          true,
+	"source.network":       true,
+	"dest.ip":              true,
+	"dest.host":            true,
+	"dest.port":            true,
+	"dest.network":         true,
+	"protocol":             true,
+	"iface.in":             true,
+	"iface.out":            true,
+	"lists.domains":        true,
+	"lists.domains_regexp": true,
+	"lists.ips":            true,
+	"lists.nets":           true,
+	"lists.hash.md5":       true,
+}
+
+// CheckOperator reports why the daemon could not evaluate op: an unknown
+// type or operand, or a list operator without conditions. Children are
+// checked too.
+func CheckOperator(op state.RuleOperator) error {
+	if !operatorTypes[strings.ToLower(op.Type)] {
+		return fmt.Errorf("unknown operator type %q", op.Type)
+	}
+	if !operands[op.Operand] && !strings.HasPrefix(op.Operand, "process.env.") {
+		return fmt.Errorf("unknown operand %q", op.Operand)
+	}
+	if Semantics(op) != CombineAll {
+		return nil
+	}
+	if len(op.Children) == 0 {
+		return fmt.Errorf("list operator without conditions")
+	}
+	for _, child := range op.Children {
+		if err := CheckOperator(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestLimitsBoundaryLengths(t *testing.T) {
+	limits := LimitsFor(state.Settings{})
+	if limits.OperatorData != DefaultMaxOperatorData || limits.Text != DefaultMaxText {
+		t.Fatalf("expected defaults for unset settings, got %+v", limits)
+	}
+
+	atLimit := state.Rule{
+		Name:        strings.Repeat("n", DefaultMaxText),
+		Description: strings.Repeat("d", DefaultMaxText),
+		Operator:    state.RuleOperator{Data: strings.Repeat("x", DefaultMaxOperatorData)},
+	}
+	if err := limits.Validate(atLimit); err != nil {
+		t.Fatalf("expected values at the limit to pass, got %v", err)
+	}
+
+	over := atLimit
+	over.Operator.Data += "x"
+	err := limits.Validate(over)
+	if err == nil || !strings.Contains(err.Error(), "operator data is 4097 characters") {
+		t.Fatalf("expected operator data error with actual length, got %v", err)
+	}
+
+	over = atLimit
+	over.Description += "d"
+	if err := limits.Validate(over); err == nil || !strings.Contains(err.Error(), "description is 257") {
+		t.Fatalf("expected description error, got %v", err)
+	}
+}
+
+func TestLimitsCountRunesNotBytes(t *testing.T) {
+	limits := Limits{OperatorData: 4, Text: 3}
+	// Four runes, twelve bytes.
+	if err := limits.ValidateOperator(state.RuleOperator{Data: "日本語字"}); err != nil {
+		t.Fatalf("expected multi-byte data within the rune limit to pass, got %v", err)
+	}
+	if err := limits.Validate(state.Rule{Name: "ünï"}); err != nil {
+		t.Fatalf("expected three-rune name to pass, got %v", err)
+	}
+	if err := limits.Validate(state.Rule{Name: "ünïc"}); err == nil || !strings.Contains(err.Error(), "is 4 characters") {
+		t.Fatalf("expected rune count in error, got %v", err)
+	}
+}
+
+func TestLimitsCheckNestedOperators(t *testing.T) {
+	limits := Limits{OperatorData: 5, Text: 10}
+	op := state.RuleOperator{Type: "list", Children: []state.RuleOperator{
+		{Data: "ok"},
+		{Data: "too long"},
+	}}
+	if err := limits.ValidateOperator(op); err == nil {
+		t.Fatalf("expected nested operator data to be checked")
+	}
+}
+
+func TestCheckOperator(t *testing.T) {
+	valid := []state.RuleOperator{
+		{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"},
+		{Type: "Regexp", Operand: "process.env.HOME", Data: "^/home"},
+		{Type: "lists", Operand: "lists.domains", Data: "/etc/opensnitchd/blocklists"},
+		{Type: "list", Operand: "list", Children: []state.RuleOperator{{Type: "network", Operand: "dest.network", Data: "10.0.0.0/8"}}},
+	}
+	for _, op := range valid {
+		if err := CheckOperator(op); err != nil {
+			t.Fatalf("%+v: unexpected error %v", op, err)
+		}
+	}
+	invalid := map[string]state.RuleOperator{
+		`unknown operator type ""`:          {},
+		`unknown operand "dest.color"`:      {Type: "simple", Operand: "dest.color"},
+		"list operator without conditions":  {Type: "list", Operand: "list"},
+		`unknown operator type "sometimes"`: {Type: "list", Operand: "list", Children: []state.RuleOperator{{Type: "sometimes", Operand: "dest.port"}}},
+	}
+	for want, op := range invalid {
+		if err := CheckOperator(op); err == nil || err.Error() != want {
+			t.Fatalf("%+v: expected %q, got %v", op, want, err)
+		}
+	}
+}
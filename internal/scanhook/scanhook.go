@@ -0,0 +1,259 @@
+// Package scanhook runs a user-configured scanner command (clamscan, a capa
+// wrapper, ...) against a binary and maps its exit code to a verdict.
+package scanhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a scanner run.
+const DefaultTimeout = 30 * time.Second
+
+// MaxLines is how many output lines a Result keeps.
+const MaxLines = 8
+
+// maxOutput caps the output kept from a run; only MaxLines lines of it are
+// shown, so a scanner dumping megabytes is not buffered whole.
+const maxOutput = 64 << 10
+
+// waitDelay bounds how long Run waits for the output pipes after the scanner
+// exits or is killed, in case a child it spawned still holds them open.
+const waitDelay = 2 * time.Second
+
+// PathPlaceholder is replaced by the scanned file's path in each argument.
+const PathPlaceholder = "{path}"
+
+// keptEnv lists the only environment variables passed to the scanner, so
+// tokens and agent sockets in the TUI's environment do not leak into it.
+var keptEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TMPDIR"}
+
+var (
+	ErrNoCommand = errors.New("inspect_hook is not set")
+	ErrTimeout   = errors.New("scanner timed out")
+)
+
+// Verdict classifies a scanner run by its exit code.
+type Verdict int
+
+const (
+	// VerdictError covers failures to run and exit codes other than 0 and 1.
+	VerdictError Verdict = iota
+	// VerdictClean is exit code 0.
+	VerdictClean
+	// VerdictSuspicious is exit code 1, which clamscan and most scanners
+	// use for "found something".
+	VerdictSuspicious
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictClean:
+		return "clean"
+	case VerdictSuspicious:
+		return "suspicious"
+	}
+	return "error"
+}
+
+// Result is the outcome of one scanner run.
+type Result struct {
+	Verdict  Verdict
+	ExitCode int
+	// Lines holds the first MaxLines non-empty lines of combined output.
+	Lines []string
+	Err   error
+}
+
+// Runner executes argv with env and returns its combined output and exit
+// code. A non-nil error means the command did not run to completion.
+type Runner interface {
+	Run(ctx context.Context, argv, env []string) (output []byte, exitCode int, err error)
+}
+
+// ExecRunner runs commands with os/exec, without a shell.
+type ExecRunner struct{}
+
+// Run implements Runner.
+func (ExecRunner) Run(ctx context.Context, argv, env []string) ([]byte, int, error) {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = env
+	cmd.WaitDelay = waitDelay
+	out := &cappedBuffer{limit: maxOutput}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return out.Bytes(), exitErr.ExitCode(), nil
+	}
+	if errors.Is(err, exec.ErrWaitDelay) && ctx.Err() == nil {
+		// The scanner itself exited; only a leftover child kept the pipes.
+		return out.Bytes(), cmd.ProcessState.ExitCode(), nil
+	}
+	if err != nil {
+		return out.Bytes(), -1, err
+	}
+	return out.Bytes(), 0, nil
+}
+
+// cappedBuffer keeps the first limit bytes written and drops the rest while
+// still reporting them written, so the command never sees a short write. The
+// buffer is a named field so its ReadFrom does not bypass the cap in io.Copy.
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) Bytes() []byte { return b.buf.Bytes() }
+
+// Hook is a configured scanner command.
+type Hook struct {
+	// Command is split into words like a shell would (single and double
+	// quotes group words) but never run through one; PathPlaceholder is
+	// substituted in each word.
+	Command string
+	Timeout time.Duration
+	Runner  Runner
+	// Environ supplies the environment to scrub; nil uses os.Environ.
+	Environ func() []string
+}
+
+// Scan runs the hook against path.
+func (h Hook) Scan(ctx context.Context, path string) Result {
+	argv, err := Command(h.Command, path)
+	if err != nil {
+		return Result{Verdict: VerdictError, ExitCode: -1, Err: err}
+	}
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	runner := h.Runner
+	if runner == nil {
+		runner = ExecRunner{}
+	}
+	environ := h.Environ
+	if environ == nil {
+		environ = os.Environ
+	}
+	out, code, err := runner.Run(ctx, argv, ScrubEnv(environ()))
+	res := Result{ExitCode: code, Lines: firstLines(string(out), MaxLines)}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		res.Verdict, res.Err = VerdictError, ErrTimeout
+	case err != nil:
+		res.Verdict, res.Err = VerdictError, err
+	case code == 0:
+		res.Verdict = VerdictClean
+	case code == 1:
+		res.Verdict = VerdictSuspicious
+	default:
+		res.Verdict = VerdictError
+	}
+	return res
+}
+
+// Command splits template into arguments and substitutes path.
+func Command(template, path string) ([]string, error) {
+	words, err := splitWords(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, ErrNoCommand
+	}
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, PathPlaceholder, path)
+	}
+	return words, nil
+}
+
+// Validate reports whether template can be split into a command.
+func Validate(template string) error {
+	_, err := Command(template, "")
+	return err
+}
+
+// ScrubEnv keeps only the variables in keptEnv.
+func ScrubEnv(environ []string) []string {
+	kept := make([]string, 0, len(keptEnv))
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		for _, keep := range keptEnv {
+			if name == keep {
+				kept = append(kept, entry)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+func splitWords(s string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in inspect_hook", quote)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+func firstLines(out string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r \t")
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == n {
+			break
+		}
+	}
+	return lines
+}
@@ -0,0 +1,134 @@
+package scanhook
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeRunner struct {
+	argv   []string
+	env    []string
+	output string
+	code   int
+	err    error
+	block  bool
+}
+
+func (f *fakeRunner) Run(ctx context.Context, argv, env []string) ([]byte, int, error) {
+	f.argv, f.env = argv, env
+	if f.block {
+		<-ctx.Done()
+		return nil, -1, ctx.Err()
+	}
+	return []byte(f.output), f.code, f.err
+}
+
+func TestCommandSubstitutesPathWithoutShell(t *testing.T) {
+	argv, err := Command(`clamscan --no-summary "{path}" --log='/tmp/my log'`, "/tmp/evil; rm -rf ~")
+	if err != nil {
+		t.Fatalf("Command error: %v", err)
+	}
+	want := []string{"clamscan", "--no-summary", "/tmp/evil; rm -rf ~", "--log=/tmp/my log"}
+	if !reflect.DeepEqual(argv, want) {
+		t.Fatalf("got %q, want %q", argv, want)
+	}
+	if _, err := Command("  ", "/bin/ls"); !errors.Is(err, ErrNoCommand) {
+		t.Fatalf("expected ErrNoCommand, got %v", err)
+	}
+	if err := Validate(`scan "{path}`); err == nil {
+		t.Fatalf("expected unterminated quote to be rejected")
+	}
+}
+
+func TestScanMapsExitCodes(t *testing.T) {
+	cases := []struct {
+		code int
+		want Verdict
+	}{
+		{0, VerdictClean},
+		{1, VerdictSuspicious},
+		{2, VerdictError},
+	}
+	for _, tc := range cases {
+		runner := &fakeRunner{output: "line1\n\nline2\n", code: tc.code}
+		res := Hook{Command: "scan {path}", Runner: runner}.Scan(context.Background(), "/usr/bin/curl")
+		if res.Verdict != tc.want || res.ExitCode != tc.code {
+			t.Fatalf("exit %d: got %+v, want %s", tc.code, res, tc.want)
+		}
+		if !reflect.DeepEqual(res.Lines, []string{"line1", "line2"}) {
+			t.Fatalf("unexpected lines %q", res.Lines)
+		}
+		if runner.argv[1] != "/usr/bin/curl" {
+			t.Fatalf("path not substituted: %q", runner.argv)
+		}
+	}
+}
+
+func TestScanKeepsFirstLines(t *testing.T) {
+	runner := &fakeRunner{output: strings.Repeat("x\n", MaxLines+5)}
+	res := Hook{Command: "scan", Runner: runner}.Scan(context.Background(), "/bin/ls")
+	if len(res.Lines) != MaxLines {
+		t.Fatalf("expected %d lines, got %d", MaxLines, len(res.Lines))
+	}
+}
+
+func TestScanTimesOut(t *testing.T) {
+	runner := &fakeRunner{block: true}
+	res := Hook{Command: "scan", Timeout: 10 * time.Millisecond, Runner: runner}.Scan(context.Background(), "/bin/ls")
+	if res.Verdict != VerdictError || !errors.Is(res.Err, ErrTimeout) {
+		t.Fatalf("expected timeout error, got %+v", res)
+	}
+}
+
+func TestScanScrubsEnvironment(t *testing.T) {
+	runner := &fakeRunner{}
+	hook := Hook{
+		Command: "scan",
+		Runner:  runner,
+		Environ: func() []string {
+			return []string{"PATH=/usr/bin", "GITHUB_TOKEN=secret", "SSH_AUTH_SOCK=/tmp/agent", "LANG=C.UTF-8"}
+		},
+	}
+	hook.Scan(context.Background(), "/bin/ls")
+	if want := []string{"PATH=/usr/bin", "LANG=C.UTF-8"}; !reflect.DeepEqual(runner.env, want) {
+		t.Fatalf("got env %q, want %q", runner.env, want)
+	}
+}
+
+func TestExecRunnerReportsExitCode(t *testing.T) {
+	out, code, err := ExecRunner{}.Run(context.Background(), []string{"sh", "-c", "echo found; exit 1"}, ScrubEnv([]string{"PATH=/usr/bin:/bin"}))
+	if err != nil {
+		t.Skipf("sh unavailable: %v", err)
+	}
+	if code != 1 || strings.TrimSpace(string(out)) != "found" {
+		t.Fatalf("got code %d output %q", code, out)
+	}
+}
+
+func TestExecRunnerCapsOutput(t *testing.T) {
+	out, code, err := ExecRunner{}.Run(context.Background(), []string{"sh", "-c", "yes scanned | head -c 200000"}, ScrubEnv([]string{"PATH=/usr/bin:/bin"}))
+	if err != nil {
+		t.Skipf("sh unavailable: %v", err)
+	}
+	if code != 0 || len(out) != maxOutput || !strings.HasPrefix(string(out), "scanned\n") {
+		t.Fatalf("got code %d and %d bytes of output", code, len(out))
+	}
+}
+
+func TestExecRunnerDoesNotWaitForLeftoverChildren(t *testing.T) {
+	start := time.Now()
+	out, code, err := ExecRunner{}.Run(context.Background(), []string{"sh", "-c", "sleep 30 & echo found; exit 1"}, ScrubEnv([]string{"PATH=/usr/bin:/bin"}))
+	if err != nil {
+		t.Skipf("sh unavailable: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > waitDelay+5*time.Second {
+		t.Fatalf("Run waited %v for a child holding the output pipe", elapsed)
+	}
+	if code != 1 || strings.TrimSpace(string(out)) != "found" {
+		t.Fatalf("got code %d output %q", code, out)
+	}
+}
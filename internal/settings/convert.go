@@ -0,0 +1,68 @@
+package settings
+
+import (
+	"slices"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// ProfilesFromConfig converts the configured profiles; unknown views are
+// dropped so applying the profile keeps the current one.
+func ProfilesFromConfig(profiles []config.Profile) []state.Profile {
+	out := make([]state.Profile, 0, len(profiles))
+	for _, p := range profiles {
+		kind, _ := state.ParseViewKind(p.View)
+		out = append(out, state.Profile{
+			Name: p.Name,
+			View: kind,
+			Events: state.EventsLayout{
+				Filter:     p.Events.Filter,
+				Sort:       p.Events.Sort,
+				Columns:    slices.Clone(p.Events.Columns),
+				Time:       p.Events.Time,
+				TableShare: p.Events.TableShare,
+			},
+			Rules: state.RulesLayout{
+				HideDisabled: p.Rules.HideDisabled,
+				Sort:         p.Rules.Sort,
+				TableShare:   p.Rules.TableShare,
+			},
+		})
+	}
+	return out
+}
+
+// profileConfig converts p back for saving.
+func profileConfig(p state.Profile) config.Profile {
+	return config.Profile{
+		Name: p.Name,
+		View: string(p.View),
+		Events: config.EventsProfile{
+			Filter:     p.Events.Filter,
+			Sort:       p.Events.Sort,
+			Columns:    slices.Clone(p.Events.Columns),
+			Time:       p.Events.Time,
+			TableShare: p.Events.TableShare,
+		},
+		Rules: config.RulesProfile{
+			HideDisabled: p.Rules.HideDisabled,
+			Sort:         p.Rules.Sort,
+			TableShare:   p.Rules.TableShare,
+		},
+	}
+}
+
+// nodeConfig converts node to a config file entry under its listed
+// address. A name that only repeats the ID is left out.
+func nodeConfig(node state.Node) config.Node {
+	entry := config.Node{Name: node.Name, Address: node.ListedAddress()}
+	if entry.Name == node.ID {
+		entry.Name = ""
+	}
+	if node.Prompts == state.PromptsPolicy {
+		interactive := false
+		entry.InteractivePrompts = &interactive
+	}
+	return entry
+}
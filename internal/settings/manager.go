@@ -1,25 +1,69 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
 )
 
-// Manager persists user-facing settings to disk.
+// Manager persists user-facing settings to disk. When the config cannot be
+// written, setters still apply the value and return an error matching
+// controller.ErrNotPersisted, and the save is retried in the background
+// until it succeeds.
 type Manager struct {
 	path string
 	mu   sync.Mutex
 	cfg  config.Config
+
+	// save and schedule are replaced in tests.
+	save     func(path string, cfg config.Config) error
+	schedule func(delay time.Duration, fn func()) (stop func())
+	now      func() time.Time
+	store    *state.Store
+
+	// dirty is set while the config file is behind cfg.
+	dirty    bool
+	attempts int
+	lastErr  error
+	// stopRetry cancels the pending retry; retryToken tells a stale
+	// timer from the current one.
+	stopRetry  func()
+	retryToken uint64
+	nextRetry  time.Time
+	// closed is set by Flush; no retries are scheduled after it.
+	closed bool
 }
 
 // NewManager returns a manager initialized with the current configuration snapshot.
 func NewManager(path string, cfg config.Config) *Manager {
 	cfg.Theme = config.NormalizeThemeName(cfg.Theme)
-	return &Manager{path: path, cfg: cfg}
+	return &Manager{
+		path: path,
+		cfg:  cfg,
+		save: config.Save,
+		schedule: func(delay time.Duration, fn func()) func() {
+			timer := time.AfterFunc(delay, fn)
+			return func() { timer.Stop() }
+		},
+		now: time.Now,
+	}
+}
+
+// SetStore publishes the pending-save state to store, so the UI can show
+// that changes are not yet on disk.
+func (m *Manager) SetStore(store *state.Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+	m.publishLocked()
 }
 
 // SetTheme updates the preferred color palette and writes it to disk.
@@ -29,10 +73,7 @@ func (m *Manager) SetTheme(name string) (string, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.Theme = normalized
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return "", err
-	}
-	return normalized, nil
+	return normalized, m.saveLocked()
 }
 
 // SetDefaultPromptAction stores the normalized default prompt action and writes it to disk.
@@ -42,10 +83,7 @@ func (m *Manager) SetDefaultPromptAction(action string) (string, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.DefaultPromptAction = normalized
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return "", err
-	}
-	return normalized, nil
+	return normalized, m.saveLocked()
 }
 
 // SetDefaultPromptDuration stores the normalized default prompt duration and writes it to disk.
@@ -55,10 +93,7 @@ func (m *Manager) SetDefaultPromptDuration(duration string) (string, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.DefaultPromptDuration = normalized
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return "", err
-	}
-	return normalized, nil
+	return normalized, m.saveLocked()
 }
 
 // SetDefaultPromptTarget stores the normalized default prompt target and writes it to disk.
@@ -68,10 +103,17 @@ func (m *Manager) SetDefaultPromptTarget(target string) (string, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.DefaultPromptTarget = normalized
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return "", err
-	}
-	return normalized, nil
+	return normalized, m.saveLocked()
+}
+
+// SetPromptInitialFocus stores where new prompts put focus and writes it to disk.
+func (m *Manager) SetPromptInitialFocus(focus string) (string, error) {
+	normalized := config.NormalizePromptInitialFocus(strings.ToLower(strings.TrimSpace(focus)))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cfg.PromptInitialFocus = normalized
+	return normalized, m.saveLocked()
 }
 
 // SetAlertsInterrupt toggles whether alerts interrupt active work.
@@ -80,10 +122,7 @@ func (m *Manager) SetAlertsInterrupt(enabled bool) (bool, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.AlertsInterrupt = enabled
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return m.cfg.AlertsInterrupt, err
-	}
-	return m.cfg.AlertsInterrupt, nil
+	return m.cfg.AlertsInterrupt, m.saveLocked()
 }
 
 // SetPromptTimeout updates the default prompt timeout duration in seconds.
@@ -93,10 +132,7 @@ func (m *Manager) SetPromptTimeout(seconds int) (int, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.PromptTimeoutSeconds = normalized
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return 0, err
-	}
-	return normalized, nil
+	return normalized, m.saveLocked()
 }
 
 // SetPausePromptOnInspect toggles whether to pause prompt timeout while inspecting.
@@ -105,10 +141,7 @@ func (m *Manager) SetPausePromptOnInspect(enabled bool) (bool, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.PausePromptOnInspect = enabled
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return m.cfg.PausePromptOnInspect, err
-	}
-	return m.cfg.PausePromptOnInspect, nil
+	return m.cfg.PausePromptOnInspect, m.saveLocked()
 }
 
 // SetYaraRuleDir sets the directory containing YARA rules.
@@ -117,21 +150,12 @@ func (m *Manager) SetYaraRuleDir(path string) (string, error) {
 	defer m.mu.Unlock()
 
 	path = strings.TrimSpace(path)
-	if path != "" {
-		info, err := os.Stat(path)
-		if err != nil {
-			return "", fmt.Errorf("%s: %w", path, err)
-		}
-		if !info.IsDir() {
-			return "", fmt.Errorf("%s is not a directory", path)
-		}
+	if err := config.ValidateYaraRuleDir(path); err != nil {
+		return "", err
 	}
 
 	m.cfg.YaraRuleDir = path
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return m.cfg.YaraRuleDir, err
-	}
-	return m.cfg.YaraRuleDir, nil
+	return m.cfg.YaraRuleDir, m.saveLocked()
 }
 
 // SetYaraEnabled toggles YARA scanning.
@@ -140,10 +164,131 @@ func (m *Manager) SetYaraEnabled(enabled bool) (bool, error) {
 	defer m.mu.Unlock()
 
 	m.cfg.YaraEnabled = enabled
-	if err := config.Save(m.path, m.cfg); err != nil {
-		return m.cfg.YaraEnabled, err
+	return m.cfg.YaraEnabled, m.saveLocked()
+}
+
+// SetInspectHookEnabled toggles the external scanner hook. The command itself
+// is config-file-only and never set from the UI.
+func (m *Manager) SetInspectHookEnabled(enabled bool) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cfg.InspectHookEnabled = enabled
+	return m.cfg.InspectHookEnabled, m.saveLocked()
+}
+
+// SetDNDMinutes stores the do-not-disturb preset length.
+func (m *Manager) SetDNDMinutes(minutes int) (int, error) {
+	normalized := config.NormalizeDNDMinutes(minutes)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cfg.DNDMinutes = normalized
+	return normalized, m.saveLocked()
+}
+
+// SetStartView stores the view shown on launch. Unknown view names are
+// rejected rather than normalized.
+func (m *Manager) SetStartView(name string) (string, error) {
+	kind, ok := state.ParseViewKind(name)
+	if !ok {
+		return "", fmt.Errorf("unknown view %q", name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cfg.StartView = string(kind)
+	return m.cfg.StartView, m.saveLocked()
+}
+
+// SetRuleDescriptionTemplate stores the template describing rules created
+// from prompts; an empty one selects the default.
+func (m *Manager) SetRuleDescriptionTemplate(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if _, err := ruleset.ParseDescriptionTemplate(text); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cfg.RuleDescriptionTemplate = text
+	return m.cfg.RuleDescriptionTemplate, m.saveLocked()
+}
+
+// SaveProfile stores a workspace profile, replacing the one with the same
+// name, and writes it to disk. The name is trimmed and must not be empty.
+func (m *Manager) SaveProfile(profile state.Profile) (state.Profile, error) {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return state.Profile{}, errors.New("profile name is required")
+	}
+	saved := profileConfig(profile)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	profiles := slices.Clone(m.cfg.Profiles)
+	if idx := slices.IndexFunc(profiles, func(p config.Profile) bool { return p.Name == saved.Name }); idx >= 0 {
+		profiles[idx] = saved
+	} else {
+		profiles = append(profiles, saved)
+	}
+	m.cfg.Profiles = profiles
+	return profile, m.saveLocked()
+}
+
+// AddNode lists node in the config file, so it is shown, disconnected,
+// after a restart until its daemon connects again. A node whose address is
+// already listed is refused.
+func (m *Manager) AddNode(node state.Node) error {
+	entry := nodeConfig(node)
+	if err := config.ValidateNode(entry); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slices.ContainsFunc(m.cfg.Nodes, func(n config.Node) bool { return n.Address == entry.Address }) {
+		return fmt.Errorf("%s is already configured", entry.Address)
+	}
+	m.cfg.Nodes = append(slices.Clone(m.cfg.Nodes), entry)
+	return m.saveLocked()
+}
+
+// RemoveNode drops the config entries with address and reports whether
+// there were any; nothing is written when there were none.
+func (m *Manager) RemoveNode(address string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nodes := slices.DeleteFunc(slices.Clone(m.cfg.Nodes), func(n config.Node) bool { return n.Address == address })
+	if len(nodes) == len(m.cfg.Nodes) {
+		return false, nil
+	}
+	m.cfg.Nodes = nodes
+	return true, m.saveLocked()
+}
+
+// saveLocked writes the config. The in-memory value is kept either way, so a
+// read-only config only costs persistence; a failed save marks the manager
+// dirty and schedules a retry.
+func (m *Manager) saveLocked() error {
+	if err := m.save(m.path, m.cfg); err != nil {
+		m.dirty = true
+		m.attempts++
+		m.lastErr = err
+		if m.stopRetry == nil && !m.closed {
+			m.scheduleRetryLocked()
+		}
+		m.publishLocked()
+		return &controller.NotPersistedError{Path: m.path, Err: err}
+	}
+	wasDirty := m.dirty
+	m.cancelRetryLocked()
+	m.dirty, m.attempts, m.lastErr = false, 0, nil
+	if wasDirty {
+		m.publishLocked()
 	}
-	return m.cfg.YaraEnabled, nil
+	return nil
 }
 
 // Config returns a copy of the managed config.
@@ -1,11 +1,14 @@
 package settings
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
 )
 
 func TestManagerSettersPersistNormalizedValues(t *testing.T) {
@@ -138,3 +141,213 @@ func TestManagerSettersPersistNormalizedValues(t *testing.T) {
 		t.Fatalf("expected error for invalid YaraRuleDir path")
 	}
 }
+
+func TestManagerSetStartView(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	mgr := NewManager(cfgPath, config.Default())
+
+	view, err := mgr.SetStartView(" Events ")
+	if err != nil {
+		t.Fatalf("SetStartView error: %v", err)
+	}
+	if view != "events" {
+		t.Fatalf("expected normalized view events, got %s", view)
+	}
+	if _, err := mgr.SetStartView("firewall"); err == nil {
+		t.Fatalf("expected error for unknown view")
+	}
+
+	persisted, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if persisted.StartView != "events" {
+		t.Fatalf("expected persisted start view events, got %s", persisted.StartView)
+	}
+}
+
+func TestManagerSetRuleDescriptionTemplate(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	mgr := NewManager(cfgPath, config.Default())
+
+	got, err := mgr.SetRuleDescriptionTemplate(" {{.Node}}: {{.Process}} ")
+	if err != nil || got != "{{.Node}}: {{.Process}}" {
+		t.Fatalf("SetRuleDescriptionTemplate = %q, %v", got, err)
+	}
+	if _, err := mgr.SetRuleDescriptionTemplate("{{.Nope}}"); err == nil {
+		t.Fatal("expected an unknown field rejected")
+	}
+
+	persisted, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if persisted.RuleDescriptionTemplate != "{{.Node}}: {{.Process}}" {
+		t.Fatalf("expected the template persisted, got %q", persisted.RuleDescriptionTemplate)
+	}
+}
+
+func TestManagerSaveProfile(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	mgr := NewManager(cfgPath, config.Default())
+
+	triage := state.Profile{Name: " triage ", View: state.ViewEvents, Events: state.EventsLayout{Filter: "deny", Columns: []string{"container"}}}
+	saved, err := mgr.SaveProfile(triage)
+	if err != nil {
+		t.Fatalf("SaveProfile error: %v", err)
+	}
+	if saved.Name != "triage" {
+		t.Fatalf("expected a trimmed name, got %q", saved.Name)
+	}
+	if _, err := mgr.SaveProfile(state.Profile{Name: "audit", Rules: state.RulesLayout{Sort: "name"}}); err != nil {
+		t.Fatalf("SaveProfile error: %v", err)
+	}
+	// Saving under an existing name replaces that profile in place.
+	saved.Events.Filter = "reject"
+	if _, err := mgr.SaveProfile(saved); err != nil {
+		t.Fatalf("SaveProfile error: %v", err)
+	}
+	if _, err := mgr.SaveProfile(state.Profile{Name: "  "}); err == nil {
+		t.Fatalf("expected an empty name rejected")
+	}
+
+	persisted, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if len(persisted.Profiles) != 2 || persisted.Profiles[0].Name != "triage" || persisted.Profiles[1].Name != "audit" {
+		t.Fatalf("unexpected persisted profiles %+v", persisted.Profiles)
+	}
+	if got := persisted.Profiles[0]; got.View != "events" || got.Events.Filter != "reject" || len(got.Events.Columns) != 1 {
+		t.Fatalf("unexpected persisted triage profile %+v", got)
+	}
+}
+
+// unwritableConfigPath returns a config path whose directory cannot be
+// written. Root ignores directory permissions, so a regular file standing in
+// for the directory is used as a fallback.
+func unwritableConfigPath(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "ro")
+	if err := os.Mkdir(dir, 0o500); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o700) })
+	probe := filepath.Join(dir, "probe")
+	if err := os.WriteFile(probe, nil, 0o600); err != nil {
+		return filepath.Join(dir, "config.yaml")
+	}
+	_ = os.Remove(probe)
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+	return filepath.Join(blocker, "config.yaml")
+}
+
+func TestManagerAppliesSettingsWhenConfigUnwritable(t *testing.T) {
+	cfgPath := unwritableConfigPath(t)
+	mgr := NewManager(cfgPath, config.Default())
+
+	action, err := mgr.SetDefaultPromptAction("allow")
+	if !errors.Is(err, controller.ErrNotPersisted) {
+		t.Fatalf("expected ErrNotPersisted, got %v", err)
+	}
+	var notPersisted *controller.NotPersistedError
+	if !errors.As(err, &notPersisted) || notPersisted.Path != cfgPath || notPersisted.Err == nil {
+		t.Fatalf("expected NotPersistedError for %s wrapping the cause, got %#v", cfgPath, err)
+	}
+	if action != "allow" || mgr.Config().DefaultPromptAction != "allow" {
+		t.Fatalf("expected action applied in memory, got %q / %q", action, mgr.Config().DefaultPromptAction)
+	}
+
+	minutes, err := mgr.SetDNDMinutes(60)
+	if !errors.Is(err, controller.ErrNotPersisted) || minutes != 60 {
+		t.Fatalf("SetDNDMinutes = %d, %v; want 60 and ErrNotPersisted", minutes, err)
+	}
+
+	// Invalid values are still rejected outright.
+	if _, err := mgr.SetStartView("bogus"); err == nil || errors.Is(err, controller.ErrNotPersisted) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+func TestManagerSetPromptInitialFocus(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	mgr := NewManager(cfgPath, config.Default())
+
+	for in, want := range map[string]string{" Confirm ": "confirm", "target": "target", "bogus": config.DefaultPromptInitialFocus} {
+		got, err := mgr.SetPromptInitialFocus(in)
+		if err != nil {
+			t.Fatalf("SetPromptInitialFocus(%q) error: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("SetPromptInitialFocus(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if _, err := mgr.SetPromptInitialFocus("target"); err != nil {
+		t.Fatal(err)
+	}
+	persisted, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if persisted.PromptInitialFocus != "target" {
+		t.Fatalf("expected persisted focus target, got %s", persisted.PromptInitialFocus)
+	}
+}
+
+func TestManagerSetInspectHookEnabledKeepsCommand(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.Default()
+	cfg.InspectHook = "clamscan {path}"
+	mgr := NewManager(cfgPath, cfg)
+
+	if enabled, err := mgr.SetInspectHookEnabled(true); err != nil || !enabled {
+		t.Fatalf("SetInspectHookEnabled = %v, %v", enabled, err)
+	}
+	persisted, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if !persisted.InspectHookEnabled || persisted.InspectHook != "clamscan {path}" {
+		t.Fatalf("expected hook enabled with its command kept, got %+v", persisted)
+	}
+}
+
+func TestManagerAddAndRemoveNode(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	mgr := NewManager(cfgPath, config.Default())
+
+	live := state.Node{ID: "tcp://10.0.0.5:43122", Name: "alpha", Address: "10.0.0.5:43122", Prompts: state.PromptsPolicy}
+	if err := mgr.AddNode(live); err != nil {
+		t.Fatalf("AddNode error: %v", err)
+	}
+	if err := mgr.AddNode(state.Node{ID: "tcp://10.0.0.5:43122", Name: "again", Address: "10.0.0.5:43122"}); err == nil {
+		t.Fatalf("expected a second node with the same address refused")
+	}
+	if err := mgr.AddNode(state.Node{ID: "bogus", Address: "no-port"}); err == nil {
+		t.Fatalf("expected an invalid address refused")
+	}
+
+	persisted, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if len(persisted.Nodes) != 1 {
+		t.Fatalf("expected one persisted node, got %+v", persisted.Nodes)
+	}
+	if got := persisted.Nodes[0]; got.Name != "alpha" || got.Address != "10.0.0.5:43122" || got.InteractivePrompts == nil || *got.InteractivePrompts {
+		t.Fatalf("unexpected persisted node %+v", got)
+	}
+
+	if removed, err := mgr.RemoveNode("10.0.0.9:50051"); err != nil || removed {
+		t.Fatalf("expected an unknown address left alone, got %v, %v", removed, err)
+	}
+	if removed, err := mgr.RemoveNode("10.0.0.5:43122"); err != nil || !removed {
+		t.Fatalf("RemoveNode = %v, %v", removed, err)
+	}
+	if persisted, err = config.Load(cfgPath); err != nil || len(persisted.Nodes) != 0 {
+		t.Fatalf("expected no persisted nodes, got %+v (%v)", persisted.Nodes, err)
+	}
+}
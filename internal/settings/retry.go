@@ -0,0 +1,95 @@
+package settings
+
+import (
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Retry delays for a config that cannot be written: the first retry comes
+// after retryBase and each failure doubles the wait up to retryMax.
+const (
+	retryBase = 5 * time.Second
+	retryMax  = 5 * time.Minute
+)
+
+// retryDelay is the wait before the retry following the given number of
+// failed saves.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < retryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMax)
+}
+
+// RetrySave writes the config now instead of waiting for the next retry.
+// It is a no-op when nothing is pending.
+func (m *Manager) RetrySave() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.dirty {
+		return nil
+	}
+	m.cancelRetryLocked()
+	return m.saveLocked()
+}
+
+// Flush stops background retries and makes a last attempt to write unsaved
+// changes. It is meant for shutdown; the returned error means the session's
+// changes are lost.
+func (m *Manager) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.cancelRetryLocked()
+	if !m.dirty {
+		return nil
+	}
+	return m.saveLocked()
+}
+
+func (m *Manager) scheduleRetryLocked() {
+	delay := retryDelay(m.attempts)
+	m.retryToken++
+	token := m.retryToken
+	m.nextRetry = m.now().Add(delay)
+	m.stopRetry = m.schedule(delay, func() { m.retry(token) })
+}
+
+func (m *Manager) cancelRetryLocked() {
+	if m.stopRetry != nil {
+		m.stopRetry()
+		m.stopRetry = nil
+	}
+	m.retryToken++
+	m.nextRetry = time.Time{}
+}
+
+// retry runs when a scheduled retry fires; a timer cancelled after it
+// started running finds its token stale and does nothing.
+func (m *Manager) retry(token uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if token != m.retryToken || m.closed || !m.dirty {
+		return
+	}
+	m.stopRetry = nil
+	m.nextRetry = time.Time{}
+	_ = m.saveLocked()
+}
+
+func (m *Manager) publishLocked() {
+	if m.store == nil {
+		return
+	}
+	save := state.ConfigSave{Dirty: m.dirty, Attempts: m.attempts, NextRetry: m.nextRetry}
+	if m.lastErr != nil {
+		save.Err = (&controller.NotPersistedError{Path: m.path, Err: m.lastErr}).Error()
+	}
+	m.store.SetConfigSave(save)
+}
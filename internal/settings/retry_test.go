@@ -0,0 +1,208 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// flakyDisk stands in for the config file system: saves fail while broken
+// and go through to config.Save otherwise.
+type flakyDisk struct {
+	broken bool
+	saves  int
+}
+
+func (d *flakyDisk) save(path string, cfg config.Config) error {
+	d.saves++
+	if d.broken {
+		return errors.New("read-only file system")
+	}
+	return config.Save(path, cfg)
+}
+
+// fakeTimers records scheduled retries instead of running them.
+type fakeTimers struct {
+	delays  []time.Duration
+	pending []func()
+	stopped int
+}
+
+func (f *fakeTimers) schedule(delay time.Duration, fn func()) func() {
+	f.delays = append(f.delays, delay)
+	f.pending = append(f.pending, fn)
+	return func() { f.stopped++ }
+}
+
+// fire runs the most recently scheduled retry.
+func (f *fakeTimers) fire(t *testing.T) {
+	t.Helper()
+	if len(f.pending) == 0 {
+		t.Fatalf("no retry scheduled")
+	}
+	fn := f.pending[len(f.pending)-1]
+	f.pending = f.pending[:len(f.pending)-1]
+	fn()
+}
+
+func newRetryManager(t *testing.T) (*Manager, *flakyDisk, *fakeTimers, *state.Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	disk := &flakyDisk{broken: true}
+	timers := &fakeTimers{}
+	mgr := NewManager(path, config.Config{})
+	mgr.save = disk.save
+	mgr.schedule = timers.schedule
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	mgr.now = func() time.Time { return now }
+	store := state.NewStore()
+	mgr.SetStore(store)
+	return mgr, disk, timers, store, path
+}
+
+func TestManagerRetriesFailedSaveWithBackoff(t *testing.T) {
+	mgr, disk, timers, store, _ := newRetryManager(t)
+
+	if _, err := mgr.SetTheme("dawn"); !errors.Is(err, controller.ErrNotPersisted) {
+		t.Fatalf("expected ErrNotPersisted, got %v", err)
+	}
+	for range 8 {
+		timers.fire(t)
+	}
+	want := []time.Duration{
+		5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second,
+		80 * time.Second, 160 * time.Second, 5 * time.Minute, 5 * time.Minute, 5 * time.Minute,
+	}
+	if len(timers.delays) != len(want) {
+		t.Fatalf("expected %d retries scheduled, got %v", len(want), timers.delays)
+	}
+	for i, delay := range want {
+		if timers.delays[i] != delay {
+			t.Fatalf("retry %d: expected delay %v, got %v", i, delay, timers.delays[i])
+		}
+	}
+	if disk.saves != 9 {
+		t.Fatalf("expected 9 save attempts, got %d", disk.saves)
+	}
+
+	save := store.Snapshot().ConfigSave
+	if !save.Dirty || save.Attempts != 9 || save.NextRetry.IsZero() || save.Err == "" {
+		t.Fatalf("expected the pending save published, got %+v", save)
+	}
+	if want := state.ConfigRetryingWarning + ": " + save.Err; store.Snapshot().ConfigWarning != want || !strings.Contains(want, "config.yaml") {
+		t.Fatalf("expected the footer warning, got %q", store.Snapshot().ConfigWarning)
+	}
+}
+
+func TestManagerSetterFailureKeepsPendingRetry(t *testing.T) {
+	mgr, _, timers, _, _ := newRetryManager(t)
+
+	_, _ = mgr.SetTheme("dawn")
+	_, _ = mgr.SetAlertsInterrupt(true)
+	if len(timers.delays) != 1 {
+		t.Fatalf("expected one retry while one is pending, got %v", timers.delays)
+	}
+}
+
+func TestManagerRetryClearsDirtyOnSuccess(t *testing.T) {
+	mgr, disk, timers, store, path := newRetryManager(t)
+
+	_, _ = mgr.SetTheme("dawn")
+	disk.broken = false
+	timers.fire(t)
+
+	if len(timers.pending) != 0 {
+		t.Fatalf("expected no retry after a successful save")
+	}
+	if save := store.Snapshot().ConfigSave; save != (state.ConfigSave{}) {
+		t.Fatalf("expected the pending save cleared, got %+v", save)
+	}
+	if store.Snapshot().ConfigWarning != "" {
+		t.Fatalf("expected the footer warning cleared, got %q", store.Snapshot().ConfigWarning)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Theme != config.ThemeDawn {
+		t.Fatalf("expected the retried save on disk, got theme %q", cfg.Theme)
+	}
+}
+
+func TestManagerRetrySaveNow(t *testing.T) {
+	mgr, disk, timers, store, _ := newRetryManager(t)
+
+	if err := mgr.RetrySave(); err != nil || disk.saves != 0 {
+		t.Fatalf("expected nothing to retry when clean, got %v after %d saves", err, disk.saves)
+	}
+	_, _ = mgr.SetTheme("dawn")
+	if err := mgr.RetrySave(); !errors.Is(err, controller.ErrNotPersisted) {
+		t.Fatalf("expected the manual retry to fail, got %v", err)
+	}
+	if timers.stopped != 1 || len(timers.delays) != 2 || timers.delays[1] != 10*time.Second {
+		t.Fatalf("expected the pending retry replaced with a longer one, got %v (stopped %d)", timers.delays, timers.stopped)
+	}
+	disk.broken = false
+	if err := mgr.RetrySave(); err != nil {
+		t.Fatalf("RetrySave: %v", err)
+	}
+	if store.Snapshot().ConfigSave.Dirty {
+		t.Fatalf("expected the manual retry to clear the pending save")
+	}
+	// The timer cancelled above may already be running; it must not save again.
+	saves := disk.saves
+	timers.fire(t)
+	if disk.saves != saves {
+		t.Fatalf("expected a stale retry to do nothing")
+	}
+}
+
+func TestManagerFlushOnExit(t *testing.T) {
+	t.Run("writes pending changes", func(t *testing.T) {
+		mgr, disk, timers, _, path := newRetryManager(t)
+		_, _ = mgr.SetTheme("dawn")
+		disk.broken = false
+
+		if err := mgr.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		if timers.stopped != 1 {
+			t.Fatalf("expected the pending retry stopped")
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected the config written: %v", err)
+		}
+	})
+
+	t.Run("reports lost changes", func(t *testing.T) {
+		mgr, disk, timers, _, _ := newRetryManager(t)
+		_, _ = mgr.SetTheme("dawn")
+
+		err := mgr.Flush()
+		if !errors.Is(err, controller.ErrNotPersisted) {
+			t.Fatalf("expected the final flush to fail, got %v", err)
+		}
+		if len(timers.delays) != 1 {
+			t.Fatalf("expected no retry scheduled after Flush, got %v", timers.delays)
+		}
+		saves := disk.saves
+		timers.fire(t)
+		if disk.saves != saves {
+			t.Fatalf("expected retries to stop after Flush")
+		}
+	})
+
+	t.Run("clean manager does not write", func(t *testing.T) {
+		mgr, disk, _, _, _ := newRetryManager(t)
+		if err := mgr.Flush(); err != nil || disk.saves != 0 {
+			t.Fatalf("expected a no-op flush, got %v after %d saves", err, disk.saves)
+		}
+	})
+}
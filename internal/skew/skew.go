@@ -0,0 +1,98 @@
+// Package skew estimates how far a remote daemon's clock is from ours.
+//
+// Each sample compares a timestamp produced by the daemon with the local time
+// it was received. Transport latency and reporting delay only ever make the
+// remote stamp look older, and they vary from sample to sample, so the
+// estimate is the median of a sliding window rather than the latest delta.
+// The result is best-effort and should only be used for display and ordering.
+package skew
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultWindow is the number of recent samples kept per node.
+const DefaultWindow = 15
+
+// MinSamples is how many samples are needed before an estimate is reported.
+const MinSamples = 3
+
+// Estimator tracks clock offsets per node. It is not safe for concurrent use.
+type Estimator struct {
+	window  int
+	samples map[string][]time.Duration
+	latest  map[string]int64
+}
+
+// New returns an estimator keeping window samples per node.
+func New(window int) *Estimator {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Estimator{window: window, samples: make(map[string][]time.Duration), latest: make(map[string]int64)}
+}
+
+// Observe records that the node reported remote when local time was local.
+// Stamps that are not newer than the last one seen for the node are ignored,
+// since daemons resend recent events with every ping.
+func (e *Estimator) Observe(nodeID string, remote, local time.Time) bool {
+	if remote.IsZero() || local.IsZero() {
+		return false
+	}
+	stamp := remote.UnixNano()
+	if last, ok := e.latest[nodeID]; ok && stamp <= last {
+		return false
+	}
+	e.latest[nodeID] = stamp
+	samples := append(e.samples[nodeID], remote.Sub(local))
+	if len(samples) > e.window {
+		samples = samples[len(samples)-e.window:]
+	}
+	e.samples[nodeID] = samples
+	return true
+}
+
+// Estimate returns the median offset of the node's clock (positive when the
+// remote clock is ahead) once enough samples exist.
+func (e *Estimator) Estimate(nodeID string) (time.Duration, bool) {
+	samples := e.samples[nodeID]
+	if len(samples) < MinSamples {
+		return 0, false
+	}
+	return median(samples), true
+}
+
+// Forget drops all samples for a node.
+func (e *Estimator) Forget(nodeID string) {
+	delete(e.samples, nodeID)
+	delete(e.latest, nodeID)
+}
+
+func median(values []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// Threshold is the smallest offset worth showing; below it latency noise
+// dominates.
+const Threshold = 2 * time.Second
+
+// Significant reports whether an offset is large enough to display.
+func Significant(offset time.Duration) bool {
+	return offset >= Threshold || offset <= -Threshold
+}
+
+// Format renders an offset with an explicit sign, rounded to seconds.
+func Format(offset time.Duration) string {
+	rounded := offset.Round(time.Second)
+	if rounded >= 0 {
+		return "+" + rounded.String()
+	}
+	return rounded.String()
+}
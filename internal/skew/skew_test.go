@@ -0,0 +1,97 @@
+package skew
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateNeedsMinSamples(t *testing.T) {
+	e := New(DefaultWindow)
+	base := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < MinSamples-1; i++ {
+		local := base.Add(time.Duration(i) * time.Second)
+		e.Observe("n1", local.Add(42*time.Second), local)
+	}
+	if _, ok := e.Estimate("n1"); ok {
+		t.Fatalf("expected no estimate with %d samples", MinSamples-1)
+	}
+	local := base.Add(time.Minute)
+	e.Observe("n1", local.Add(42*time.Second), local)
+	if got, ok := e.Estimate("n1"); !ok || got != 42*time.Second {
+		t.Fatalf("expected +42s estimate, got %v (ok=%v)", got, ok)
+	}
+}
+
+func TestEstimateMedianIgnoresJitter(t *testing.T) {
+	e := New(DefaultWindow)
+	base := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	// Daemon is 30s behind; delivery delay makes some samples look older.
+	delays := []time.Duration{0, 200 * time.Millisecond, 0, 3 * time.Second, 100 * time.Millisecond}
+	for i, delay := range delays {
+		local := base.Add(time.Duration(i) * 5 * time.Second)
+		e.Observe("n1", local.Add(-30*time.Second-delay), local)
+	}
+	got, ok := e.Estimate("n1")
+	if !ok {
+		t.Fatalf("expected an estimate")
+	}
+	if got != -30*time.Second-100*time.Millisecond {
+		t.Fatalf("expected median near -30s, got %v", got)
+	}
+}
+
+func TestObserveIgnoresStaleStamps(t *testing.T) {
+	e := New(DefaultWindow)
+	base := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	remote := base.Add(10 * time.Second)
+	if !e.Observe("n1", remote, base) {
+		t.Fatalf("expected first sample to be recorded")
+	}
+	// The same event resent on the next ping must not count again.
+	if e.Observe("n1", remote, base.Add(5*time.Second)) {
+		t.Fatalf("expected resent stamp to be ignored")
+	}
+	if e.Observe("n1", time.Time{}, base) {
+		t.Fatalf("expected zero stamp to be ignored")
+	}
+	if !e.Observe("n2", remote, base) {
+		t.Fatalf("expected nodes to be tracked independently")
+	}
+}
+
+func TestWindowDropsOldSamples(t *testing.T) {
+	e := New(3)
+	base := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		local := base.Add(time.Duration(i) * time.Second)
+		e.Observe("n1", local.Add(time.Hour), local)
+	}
+	// The daemon clock was corrected; old samples age out of the window.
+	for i := 3; i < 6; i++ {
+		local := base.Add(time.Duration(i) * time.Hour * 2)
+		e.Observe("n1", local.Add(5*time.Second), local)
+	}
+	if got, _ := e.Estimate("n1"); got != 5*time.Second {
+		t.Fatalf("expected estimate to follow recent samples, got %v", got)
+	}
+	e.Forget("n1")
+	if _, ok := e.Estimate("n1"); ok {
+		t.Fatalf("expected Forget to drop samples")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := map[time.Duration]string{
+		42 * time.Second:                   "+42s",
+		-90*time.Second - time.Millisecond: "-1m30s",
+		1400 * time.Millisecond:            "+1s",
+	}
+	for in, want := range cases {
+		if got := Format(in); got != want {
+			t.Fatalf("Format(%v) = %q, want %q", in, got, want)
+		}
+	}
+	if Significant(time.Second) || !Significant(-3*time.Second) {
+		t.Fatalf("unexpected Significant threshold")
+	}
+}
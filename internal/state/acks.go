@@ -0,0 +1,96 @@
+package state
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultSlowAck is how long a daemon may take to acknowledge an action
+// before it is flagged as slow, when no threshold is configured.
+const DefaultSlowAck = 3 * time.Second
+
+// maxAcks bounds Snapshot.Acks.
+const maxAcks = 100
+
+// ackAverageWindow is how many recent acks Node.AckAverage follows; older
+// ones fade out of it.
+const ackAverageWindow = 20
+
+// ActionAck is a daemon's reply to an action sent to it, such as enabling a
+// rule, timed from the notification being sent.
+type ActionAck struct {
+	NodeID string
+	// Action is the verb shown to the user: enable, disable, delete,
+	// change, or the notification type for other actions.
+	Action string
+	// Rule names the rule the action was about; empty for other actions.
+	Rule    string
+	SentAt  time.Time
+	Latency time.Duration
+	// Err is the daemon's error reply, or why no reply will come.
+	Err string
+	// Slow marks acks that took longer than the slow-ack threshold.
+	Slow bool
+}
+
+// SlowAckThreshold returns the configured slow-ack threshold or
+// DefaultSlowAck.
+func (s Settings) SlowAckThreshold() time.Duration {
+	if s.SlowAck <= 0 {
+		return DefaultSlowAck
+	}
+	return s.SlowAck
+}
+
+// RecordAck prepends ack to the bounded ack history and, for successful
+// acks, folds its latency into the node's rolling average.
+func (s *Store) RecordAck(ack ActionAck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot.Acks = append([]ActionAck{ack}, s.snapshot.Acks...)
+	if len(s.snapshot.Acks) > maxAcks {
+		s.snapshot.Acks = s.snapshot.Acks[:maxAcks]
+	}
+	if idx := s.indexOfLocked(ack.NodeID); idx != -1 && ack.Err == "" {
+		node := &s.snapshot.Nodes[idx]
+		node.AckCount++
+		window := time.Duration(min(node.AckCount, ackAverageWindow))
+		node.AckAverage += (ack.Latency - node.AckAverage) / window
+		if ack.Slow {
+			node.SlowAcks++
+		}
+	}
+	s.notifyLocked()
+}
+
+// FindAck returns the newest ack for action on rule of nodeID sent no
+// earlier than since.
+func FindAck(acks []ActionAck, nodeID, action, rule string, since time.Time) (ActionAck, bool) {
+	for _, ack := range acks {
+		if ack.NodeID == nodeID && ack.Action == action && ack.Rule == rule && !ack.SentAt.Before(since) {
+			return ack, true
+		}
+	}
+	return ActionAck{}, false
+}
+
+// WaitAck blocks until an ack for action on rule of nodeID sent no earlier
+// than since is recorded, returning it, or until ctx is done.
+func (s *Store) WaitAck(ctx context.Context, nodeID, action, rule string, since time.Time) (ActionAck, error) {
+	sub := s.Subscribe()
+	defer sub.Close()
+	for {
+		s.mu.RLock()
+		ack, ok := FindAck(s.snapshot.Acks, nodeID, action, rule, since)
+		s.mu.RUnlock()
+		if ok {
+			return ack, nil
+		}
+		select {
+		case <-ctx.Done():
+			return ActionAck{}, ctx.Err()
+		case <-sub.Events():
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// Counters are the lifetime totals a daemon reports with every ping.
+type Counters struct {
+	Connections uint64 `json:"connections"`
+	Accepted    uint64 `json:"accepted"`
+	Dropped     uint64 `json:"dropped"`
+	Ignored     uint64 `json:"ignored"`
+	RuleHits    uint64 `json:"rule_hits"`
+	RuleMisses  uint64 `json:"rule_misses"`
+}
+
+// CountersOf returns the counters carried by stats.
+func CountersOf(stats Stats) Counters {
+	return Counters{
+		Connections: stats.Connections,
+		Accepted:    stats.Accepted,
+		Dropped:     stats.Dropped,
+		Ignored:     stats.Ignored,
+		RuleHits:    stats.RuleHits,
+		RuleMisses:  stats.RuleMisses,
+	}
+}
+
+// Since returns how far c has moved on from base. ok is false when any
+// counter is below its base: the daemon restarted and counts from zero.
+func (c Counters) Since(base Counters) (delta Counters, ok bool) {
+	pairs := []struct {
+		out       *uint64
+		now, then uint64
+	}{
+		{&delta.Connections, c.Connections, base.Connections},
+		{&delta.Accepted, c.Accepted, base.Accepted},
+		{&delta.Dropped, c.Dropped, base.Dropped},
+		{&delta.Ignored, c.Ignored, base.Ignored},
+		{&delta.RuleHits, c.RuleHits, base.RuleHits},
+		{&delta.RuleMisses, c.RuleMisses, base.RuleMisses},
+	}
+	for _, pair := range pairs {
+		if pair.now < pair.then {
+			return Counters{}, false
+		}
+		*pair.out = pair.now - pair.then
+	}
+	return delta, true
+}
+
+// Baseline is a node's counters at the moment they were marked, so the
+// dashboard can tell the traffic since then from the lifetime totals.
+type Baseline struct {
+	// NodeKey is the NodeKey of the node, so the baseline survives the
+	// node reconnecting under a new ID.
+	NodeKey  string    `json:"node_key"`
+	NodeName string    `json:"node_name"`
+	MarkedAt time.Time `json:"marked_at"`
+	Counters Counters  `json:"counters"`
+	// Restarted is set when a daemon restart moved the baseline rather
+	// than the user.
+	Restarted bool `json:"restarted,omitempty"`
+}
+
+// BaselineOf returns the baseline marked for node, if any.
+func BaselineOf(baselines []Baseline, node Node) (Baseline, bool) {
+	key := NodeKey(node)
+	for _, baseline := range baselines {
+		if baseline.NodeKey == key {
+			return baseline, true
+		}
+	}
+	return Baseline{}, false
+}
+
+// MarkBaseline makes the counters nodeID last reported its baseline,
+// replacing any earlier one.
+func (s *Store) MarkBaseline(nodeID string, now time.Time) (Baseline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, ok := s.nodeCounters[nodeID]
+	if !ok {
+		return Baseline{}, fmt.Errorf("no statistics from %s yet", nodeID)
+	}
+	baseline := s.newBaselineLocked(nodeID, counters, now)
+	s.putBaselineLocked(baseline)
+	s.notifyLocked()
+	return baseline, nil
+}
+
+// ClearBaseline drops the baseline of the node with nodeKey and reports
+// whether there was one.
+func (s *Store) ClearBaseline(nodeKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, baseline := range s.snapshot.Baselines {
+		if baseline.NodeKey == nodeKey {
+			s.snapshot.Baselines = append(s.snapshot.Baselines[:i:i], s.snapshot.Baselines[i+1:]...)
+			s.notifyLocked()
+			return true
+		}
+	}
+	return false
+}
+
+// SetBaselines replaces the baselines, for restoring them from disk.
+func (s *Store) SetBaselines(baselines []Baseline) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot.Baselines = append([]Baseline(nil), baselines...)
+	s.notifyLocked()
+}
+
+// RebaseRestarted checks the counters nodeID last reported against its
+// baseline. When they went backwards the daemon restarted, so the baseline
+// moves to them, a warning is logged and RebaseRestarted reports true.
+func (s *Store) RebaseRestarted(nodeID string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, ok := s.nodeCounters[nodeID]
+	if !ok {
+		return false
+	}
+	baseline := s.newBaselineLocked(nodeID, counters, now)
+	for _, existing := range s.snapshot.Baselines {
+		if existing.NodeKey != baseline.NodeKey {
+			continue
+		}
+		if _, ok := counters.Since(existing.Counters); ok {
+			return false
+		}
+		baseline.Restarted = true
+		s.putBaselineLocked(baseline)
+		s.appendLogLocked(LogEntry{At: now, Severity: LogWarning, Subsystem: SubsystemDaemon, Text: fmt.Sprintf("counters of %s went back (daemon restarted); baseline reset", baseline.NodeName)})
+		s.notifyLocked()
+		return true
+	}
+	return false
+}
+
+func (s *Store) newBaselineLocked(nodeID string, counters Counters, now time.Time) Baseline {
+	node := Node{ID: nodeID}
+	for _, candidate := range s.snapshot.Nodes {
+		if candidate.ID == nodeID {
+			node = candidate
+			break
+		}
+	}
+	name := node.Name
+	if name == "" {
+		name = node.ID
+	}
+	return Baseline{NodeKey: NodeKey(node), NodeName: name, MarkedAt: now, Counters: counters}
+}
+
+func (s *Store) putBaselineLocked(baseline Baseline) {
+	for i, existing := range s.snapshot.Baselines {
+		if existing.NodeKey == baseline.NodeKey {
+			s.snapshot.Baselines[i] = baseline
+			return
+		}
+	}
+	s.snapshot.Baselines = append(s.snapshot.Baselines, baseline)
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountersSince(t *testing.T) {
+	base := Counters{Connections: 100, Accepted: 80, Dropped: 20, RuleHits: 60, RuleMisses: 40}
+	now := Counters{Connections: 150, Accepted: 110, Dropped: 25, Ignored: 15, RuleHits: 90, RuleMisses: 60}
+	delta, ok := now.Since(base)
+	if !ok {
+		t.Fatal("expected growing counters to give a delta")
+	}
+	want := Counters{Connections: 50, Accepted: 30, Dropped: 5, Ignored: 15, RuleHits: 30, RuleMisses: 20}
+	if delta != want {
+		t.Fatalf("expected %+v, got %+v", want, delta)
+	}
+
+	// One counter going back is enough to tell a restart.
+	now.Dropped = 3
+	if _, ok := now.Since(base); ok {
+		t.Fatal("expected a counter below its base to fail")
+	}
+}
+
+func TestBaselineAcrossDaemonRestart(t *testing.T) {
+	store := NewStore()
+	store.SetNodes([]Node{{ID: "tcp://10.0.0.1:50051", Name: "laptop"}})
+	node := store.Snapshot().Nodes[0]
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, err := store.MarkBaseline(node.ID, t0); err == nil {
+		t.Fatal("expected marking to fail before any statistics")
+	}
+	store.SetStats(Stats{NodeID: node.ID, Connections: 1000, Accepted: 900, Dropped: 100})
+	if _, err := store.MarkBaseline(node.ID, t0); err != nil {
+		t.Fatalf("MarkBaseline error: %v", err)
+	}
+
+	store.SetStats(Stats{NodeID: node.ID, Connections: 1040, Accepted: 930, Dropped: 110})
+	if store.RebaseRestarted(node.ID, t0.Add(time.Minute)) {
+		t.Fatal("expected no rebase while the counters grow")
+	}
+	baseline, ok := BaselineOf(store.Snapshot().Baselines, node)
+	if !ok {
+		t.Fatal("expected a baseline for the node")
+	}
+	delta, _ := CountersOf(store.Snapshot().Stats).Since(baseline.Counters)
+	if delta.Connections != 40 || delta.Accepted != 30 || delta.Dropped != 10 {
+		t.Fatalf("unexpected delta before the restart: %+v", delta)
+	}
+
+	// The daemon restarts and counts from zero again.
+	store.SetStats(Stats{NodeID: node.ID, Connections: 12, Accepted: 10, Dropped: 2})
+	t1 := t0.Add(2 * time.Minute)
+	if !store.RebaseRestarted(node.ID, t1) {
+		t.Fatal("expected the baseline to be reset after the restart")
+	}
+	baseline, _ = BaselineOf(store.Snapshot().Baselines, node)
+	if !baseline.Restarted || !baseline.MarkedAt.Equal(t1) || baseline.Counters.Connections != 12 {
+		t.Fatalf("expected the baseline moved to the restarted counters, got %+v", baseline)
+	}
+	if log := store.Snapshot().Log; len(log) != 1 || log[0].Severity != LogWarning {
+		t.Fatalf("expected a warning about the reset, got %+v", log)
+	}
+
+	store.SetStats(Stats{NodeID: node.ID, Connections: 20, Accepted: 15, Dropped: 5})
+	delta, ok = CountersOf(store.Snapshot().Stats).Since(baseline.Counters)
+	if !ok || delta.Connections != 8 || delta.Accepted != 5 || delta.Dropped != 3 {
+		t.Fatalf("unexpected delta after the restart: %+v", delta)
+	}
+
+	// A baseline is found again after the node reconnects under a new ID.
+	if _, ok := BaselineOf(store.Snapshot().Baselines, Node{ID: "tcp://10.0.0.1:40000", Name: "laptop"}); !ok {
+		t.Fatal("expected the baseline to follow the node's name")
+	}
+	if !store.ClearBaseline(NodeKey(node)) || store.ClearBaseline(NodeKey(node)) {
+		t.Fatal("expected exactly one baseline cleared")
+	}
+}
@@ -0,0 +1,148 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultConnectionCap is how many connection events the store keeps when
+// no cap is configured.
+const DefaultConnectionCap = 1000
+
+// ConnectionSource is where a connection event came from.
+type ConnectionSource string
+
+const (
+	// ConnectionFromPrompt is a connection the daemon asked about.
+	ConnectionFromPrompt ConnectionSource = "prompt"
+	// ConnectionFromEvent is a connection first seen in a daemon's events.
+	ConnectionFromEvent ConnectionSource = "event"
+)
+
+// ConnectionEvent is one connection of the live feed, in arrival order.
+type ConnectionEvent struct {
+	// Seq numbers the connection events of the session from 1.
+	Seq        uint64
+	At         time.Time
+	NodeID     string
+	Source     ConnectionSource
+	Connection Connection
+	// Rule is the rule that matched; it is empty for prompts, which are
+	// asked before any rule applies.
+	Rule Rule
+}
+
+// connRing holds the newest connection events up to its capacity.
+type connRing struct {
+	buf   []ConnectionEvent
+	start int
+	n     int
+}
+
+func newConnRing(capacity int) *connRing {
+	return &connRing{buf: make([]ConnectionEvent, capacity)}
+}
+
+func (r *connRing) push(ev ConnectionEvent) {
+	if len(r.buf) == 0 {
+		return
+	}
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = ev
+		r.n++
+		return
+	}
+	r.buf[r.start] = ev
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// newestFirst copies the events out, newest first. Events are cloned on
+// the way in and never changed, so the copy can share them.
+func (r *connRing) newestFirst() []ConnectionEvent {
+	out := make([]ConnectionEvent, r.n)
+	for i := range out {
+		out[i] = r.buf[(r.start+r.n-1-i)%len(r.buf)]
+	}
+	return out
+}
+
+// resized returns a ring of capacity holding the newest events of r.
+func (r *connRing) resized(capacity int) *connRing {
+	out := newConnRing(capacity)
+	events := r.newestFirst()
+	for i := min(len(events), capacity) - 1; i >= 0; i-- {
+		out.push(events[i])
+	}
+	return out
+}
+
+// SetConnectionCap bounds the connection events kept; n <= 0 selects
+// DefaultConnectionCap. The newest events survive a shrink.
+func (s *Store) SetConnectionCap(n int) {
+	if n <= 0 {
+		n = DefaultConnectionCap
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.connections = s.connections.resized(n)
+	s.notifyLocked()
+}
+
+// AddConnectionEvent appends ev to the live connection feed, evicting the
+// oldest event once the cap is reached. Seq is assigned here, and At
+// defaults to now.
+func (s *Store) AddConnectionEvent(ev ConnectionEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addConnectionLocked(ev)
+	s.notifyLocked()
+}
+
+// ConnectionsSeen is the number of connection events added this session,
+// including those evicted since.
+func (s *Store) ConnectionsSeen() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot.ConnectionsSeen
+}
+
+func (s *Store) addConnectionLocked(ev ConnectionEvent) {
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	ev.Connection = cloneConnection(ev.Connection)
+	ev.Rule = cloneRule(ev.Rule)
+	s.snapshot.ConnectionsSeen++
+	ev.Seq = s.snapshot.ConnectionsSeen
+	s.connections.push(ev)
+}
+
+// addEventConnectionsLocked feeds the events of incoming not yet in the
+// history to the connection feed, oldest first, so a daemon repeating its
+// recent events on every ping adds each connection once.
+func (s *Store) addEventConnectionsLocked(incoming []Event) {
+	seen := make(map[string]struct{}, len(s.snapshot.Events)+len(incoming))
+	for _, ev := range s.snapshot.Events {
+		seen[eventKey(ev)] = struct{}{}
+	}
+	var fresh []Event
+	for _, ev := range incoming {
+		key := eventKey(ev)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		fresh = append(fresh, ev)
+	}
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].UnixNano < fresh[j].UnixNano })
+	for _, ev := range fresh {
+		var at time.Time
+		if ev.UnixNano != 0 {
+			at = time.Unix(0, ev.UnixNano)
+		}
+		s.addConnectionLocked(ConnectionEvent{At: at, NodeID: ev.NodeID, Source: ConnectionFromEvent, Connection: ev.Connection, Rule: ev.Rule})
+	}
+}
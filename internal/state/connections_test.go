@@ -0,0 +1,71 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func connectionTo(host string) ConnectionEvent {
+	return ConnectionEvent{At: time.Unix(1700000000, 0), Source: ConnectionFromPrompt, Connection: Connection{DstHost: host}}
+}
+
+func TestAddConnectionEventEvictsOldest(t *testing.T) {
+	store := NewStore()
+	store.SetConnectionCap(3)
+	for i := range 5 {
+		store.AddConnectionEvent(connectionTo(fmt.Sprintf("host-%d", i)))
+	}
+
+	snap := store.Snapshot()
+	if len(snap.Connections) != 3 {
+		t.Fatalf("expected the feed capped at 3, got %d", len(snap.Connections))
+	}
+	for i, want := range []string{"host-4", "host-3", "host-2"} {
+		if got := snap.Connections[i].Connection.DstHost; got != want {
+			t.Fatalf("connection %d: expected %s, got %s", i, want, got)
+		}
+	}
+	if snap.Connections[0].Seq != 5 || snap.ConnectionsSeen != 5 || store.ConnectionsSeen() != 5 {
+		t.Fatalf("expected 5 seen with the newest numbered 5, got seq %d and %d seen", snap.Connections[0].Seq, snap.ConnectionsSeen)
+	}
+}
+
+func TestSetConnectionCapKeepsNewest(t *testing.T) {
+	store := NewStore()
+	for i := range 4 {
+		store.AddConnectionEvent(connectionTo(fmt.Sprintf("host-%d", i)))
+	}
+	store.SetConnectionCap(2)
+	conns := store.Snapshot().Connections
+	if len(conns) != 2 || conns[0].Connection.DstHost != "host-3" || conns[1].Connection.DstHost != "host-2" {
+		t.Fatalf("expected the two newest kept, got %+v", conns)
+	}
+
+	store.SetConnectionCap(0)
+	for i := range DefaultConnectionCap {
+		store.AddConnectionEvent(connectionTo(fmt.Sprintf("more-%d", i)))
+	}
+	if got := len(store.Snapshot().Connections); got != DefaultConnectionCap {
+		t.Fatalf("expected a zero cap to select the default of %d, got %d", DefaultConnectionCap, got)
+	}
+}
+
+func TestAppendEventsFeedsOnlyNewConnections(t *testing.T) {
+	store := NewStore()
+	batch := ingestEvents(3)
+	store.AppendEvents("node-1", batch)
+	// Daemons resend their recent events on every ping.
+	store.AppendEvents("node-1", append(batch, ingestEvents(4)[3]))
+
+	conns := store.Snapshot().Connections
+	if len(conns) != 4 {
+		t.Fatalf("expected each event fed once, got %d connections", len(conns))
+	}
+	if conns[0].Connection.DstHost != "host-3.example.com" || conns[3].Connection.DstHost != "host-0.example.com" {
+		t.Fatalf("expected the feed newest first, got %+v", conns)
+	}
+	if conns[0].Source != ConnectionFromEvent || conns[0].NodeID != "node-1" || conns[0].Rule.Name != "allow-curl" {
+		t.Fatalf("expected the event's node and rule carried over, got %+v", conns[0])
+	}
+}
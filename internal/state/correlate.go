@@ -0,0 +1,66 @@
+package state
+
+import "time"
+
+// An answered prompt is matched with events for the connection it was about
+// when they happen between correlationLead before and correlationWindow after
+// the answer. Only the bounded decision history is searched.
+const (
+	correlationLead   = 2 * time.Second
+	correlationWindow = 30 * time.Second
+)
+
+// correlateLocked links events to recently answered prompts for the same
+// connection and records the first such event as the decision's outcome.
+// Decisions are newest first, so the latest matching answer wins.
+func (s *Store) correlateLocked(events []Event, offsets map[string]time.Duration) {
+	for i := range events {
+		ev := &events[i]
+		if ev.UnixNano == 0 {
+			continue
+		}
+		at := time.Unix(0, ev.UnixNano-int64(offsets[ev.NodeID]))
+		for j := range s.snapshot.Decisions {
+			decision := &s.snapshot.Decisions[j]
+			if !correlates(*decision, *ev, at) {
+				continue
+			}
+			ev.Prompt = PromptLink{
+				PromptID:   decision.PromptID,
+				Action:     decision.Action,
+				Duration:   decision.Duration,
+				Source:     decision.Source,
+				PromptedAt: decision.PromptedAt,
+				ResolvedAt: decision.ResolvedAt,
+			}
+			if decision.OutcomeAt.IsZero() {
+				decision.Outcome = ev.Rule.Action
+				decision.OutcomeAt = at
+			}
+			break
+		}
+	}
+}
+
+// correlates reports whether ev, seen at the local time at, is the
+// connection decision was asked about. Withdrawn prompts were never
+// answered, so nothing results from them.
+func correlates(decision Decision, ev Event, at time.Time) bool {
+	if decision.NodeID != ev.NodeID || decision.Source == DecisionSourceWithdrawn {
+		return false
+	}
+	if at.Before(decision.ResolvedAt.Add(-correlationLead)) || at.After(decision.ResolvedAt.Add(correlationWindow)) {
+		return false
+	}
+	want, got := decision.Connection, ev.Connection
+	if want.ProcessPath != got.ProcessPath || want.DstPort != got.DstPort {
+		return false
+	}
+	if want.ProcessID != 0 && got.ProcessID != 0 && want.ProcessID != got.ProcessID {
+		return false
+	}
+	if want.DstIP != "" && want.DstIP == got.DstIP {
+		return true
+	}
+	return want.DstHost != "" && want.DstHost == got.DstHost
+}
@@ -0,0 +1,100 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func correlationFixture(resolved time.Time) (Decision, Event) {
+	conn := Connection{ProcessPath: "/usr/bin/curl", ProcessID: 42, DstIP: "93.184.216.34", DstHost: "example.com", DstPort: 443}
+	decision := Decision{
+		PromptID:   "prompt-1",
+		NodeID:     "node-1",
+		Connection: conn,
+		Action:     "allow",
+		Duration:   "once",
+		Source:     DecisionSourceUser,
+		PromptedAt: resolved.Add(-5 * time.Second),
+		ResolvedAt: resolved,
+	}
+	event := Event{
+		NodeID:     "node-1",
+		UnixNano:   resolved.Add(time.Second).UnixNano(),
+		Connection: conn,
+		Rule:       Rule{Name: "allow-once-curl", Action: "allow"},
+	}
+	return decision, event
+}
+
+func TestStoreCorrelatesEventWithPrompt(t *testing.T) {
+	resolved := time.Date(2024, time.March, 1, 14, 2, 11, 0, time.UTC)
+	decision, event := correlationFixture(resolved)
+	store := NewStore()
+	store.AddDecision(decision)
+	store.MergeEvents([]Event{event})
+
+	snap := store.Snapshot()
+	link := snap.Events[0].Prompt
+	if link.PromptID != "prompt-1" || link.Action != "allow" || link.Duration != "once" || link.Source != DecisionSourceUser {
+		t.Fatalf("unexpected prompt link: %+v", link)
+	}
+	if !link.PromptedAt.Equal(decision.PromptedAt) {
+		t.Fatalf("PromptedAt = %v, want %v", link.PromptedAt, decision.PromptedAt)
+	}
+	if got := snap.Decisions[0]; got.Outcome != "allow" || !got.OutcomeAt.Equal(resolved.Add(time.Second)) {
+		t.Fatalf("decision outcome = %q at %v", got.Outcome, got.OutcomeAt)
+	}
+}
+
+func TestStoreCorrelationNearMisses(t *testing.T) {
+	resolved := time.Date(2024, time.March, 1, 14, 2, 11, 0, time.UTC)
+	cases := []struct {
+		name   string
+		mutate func(*Event)
+	}{
+		{"different port", func(ev *Event) { ev.Connection.DstPort = 80 }},
+		{"different node", func(ev *Event) { ev.NodeID = "node-2" }},
+		{"different pid", func(ev *Event) { ev.Connection.ProcessID = 43 }},
+		{"different destination", func(ev *Event) { ev.Connection.DstIP, ev.Connection.DstHost = "10.0.0.1", "other.test" }},
+		{"window expired", func(ev *Event) { ev.UnixNano = resolved.Add(correlationWindow + time.Second).UnixNano() }},
+		{"before the answer", func(ev *Event) { ev.UnixNano = resolved.Add(-correlationLead - time.Second).UnixNano() }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision, event := correlationFixture(resolved)
+			tc.mutate(&event)
+			store := NewStore()
+			store.AddDecision(decision)
+			store.MergeEvents([]Event{event})
+
+			snap := store.Snapshot()
+			if link := snap.Events[0].Prompt; link.PromptID != "" {
+				t.Fatalf("expected no correlation, got %+v", link)
+			}
+			if snap.Decisions[0].Outcome != "" {
+				t.Fatalf("expected no decision outcome, got %q", snap.Decisions[0].Outcome)
+			}
+		})
+	}
+}
+
+func TestStoreCorrelationKeepsFirstOutcome(t *testing.T) {
+	resolved := time.Date(2024, time.March, 1, 14, 2, 11, 0, time.UTC)
+	decision, event := correlationFixture(resolved)
+	store := NewStore()
+	store.AddDecision(decision)
+	store.MergeEvents([]Event{event})
+
+	later := event
+	later.UnixNano = resolved.Add(10 * time.Second).UnixNano()
+	later.Rule.Action = "deny"
+	store.MergeEvents([]Event{later})
+
+	snap := store.Snapshot()
+	if len(snap.Events) != 2 || snap.Events[0].Prompt.PromptID != "prompt-1" {
+		t.Fatalf("expected the later event to be linked too, got %+v", snap.Events)
+	}
+	if snap.Decisions[0].Outcome != "allow" {
+		t.Fatalf("expected the first outcome to stick, got %q", snap.Decisions[0].Outcome)
+	}
+}
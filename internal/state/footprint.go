@@ -0,0 +1,73 @@
+package state
+
+import (
+	"cmp"
+	"strings"
+	"time"
+)
+
+// Footprint aggregates the events in the history that share something with
+// a selected row: a process path or a rule.
+type Footprint struct {
+	Events int
+	// Allowed and Denied count events by their rule's action; rejects
+	// count as denied.
+	Allowed int
+	Denied  int
+	// Destinations counts distinct destination hosts, or IPs when the
+	// host is unknown.
+	Destinations int
+	// First and Last are the oldest and newest event times; zero when no
+	// event carried a timestamp.
+	First time.Time
+	Last  time.Time
+}
+
+// ProcessFootprint aggregates the events from path across every node.
+func ProcessFootprint(events []Event, path string) Footprint {
+	if path == "" {
+		return Footprint{}
+	}
+	return footprint(events, func(ev Event) bool { return ev.Connection.ProcessPath == path })
+}
+
+// RuleFootprint aggregates the events that nodeID's rule called name
+// matched.
+func RuleFootprint(events []Event, nodeID, name string) Footprint {
+	if name == "" {
+		return Footprint{}
+	}
+	return footprint(events, func(ev Event) bool { return ev.NodeID == nodeID && ev.Rule.Name == name })
+}
+
+func footprint(events []Event, match func(Event) bool) Footprint {
+	var fp Footprint
+	destinations := make(map[string]struct{})
+	for _, ev := range events {
+		if !match(ev) {
+			continue
+		}
+		fp.Events++
+		switch strings.ToLower(ev.Rule.Action) {
+		case "allow":
+			fp.Allowed++
+		case "deny", "reject":
+			fp.Denied++
+		}
+		if dst := cmp.Or(ev.Connection.DstHost, ev.Connection.DstIP); dst != "" {
+			destinations[dst] = struct{}{}
+		}
+		if ev.UnixNano == 0 {
+			continue
+		}
+		ts := time.Unix(0, ev.UnixNano)
+		if fp.First.IsZero() || ts.Before(fp.First) {
+			fp.First = ts
+		}
+		if ts.After(fp.Last) {
+			fp.Last = ts
+		}
+	}
+	fp.Destinations = len(destinations)
+	return fp
+}
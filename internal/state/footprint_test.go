@@ -0,0 +1,65 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func footprintEvents() []Event {
+	base := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	ev := func(node, path, host, ip, action, rule string, offset time.Duration) Event {
+		return Event{
+			NodeID:     node,
+			UnixNano:   base.Add(offset).UnixNano(),
+			Connection: Connection{ProcessPath: path, DstHost: host, DstIP: ip},
+			Rule:       Rule{Name: rule, Action: action},
+		}
+	}
+	return []Event{
+		ev("a", "/usr/bin/curl", "example.com", "1.1.1.1", "allow", "allow-curl", 3*time.Minute),
+		ev("a", "/usr/bin/curl", "", "9.9.9.9", "deny", "deny-quad9", 2*time.Minute),
+		ev("b", "/usr/bin/curl", "example.com", "1.1.1.2", "reject", "allow-curl", time.Minute),
+		ev("a", "/usr/bin/dig", "example.org", "2.2.2.2", "allow", "allow-curl", 0),
+		{NodeID: "a", Connection: Connection{ProcessPath: "/usr/bin/curl"}, Rule: Rule{Name: "allow-curl", Action: "Allow"}},
+	}
+}
+
+func TestProcessFootprint(t *testing.T) {
+	base := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	got := ProcessFootprint(footprintEvents(), "/usr/bin/curl")
+	want := Footprint{Events: 4, Allowed: 2, Denied: 2, Destinations: 2, First: base.Add(time.Minute), Last: base.Add(3 * time.Minute)}
+	if got.Events != want.Events || got.Allowed != want.Allowed || got.Denied != want.Denied || got.Destinations != want.Destinations ||
+		!got.First.Equal(want.First) || !got.Last.Equal(want.Last) {
+		t.Fatalf("ProcessFootprint = %+v, want %+v", got, want)
+	}
+	if got := ProcessFootprint(footprintEvents(), ""); got.Events != 0 {
+		t.Fatalf("expected no footprint without a path, got %+v", got)
+	}
+	if got := ProcessFootprint(footprintEvents(), "/usr/bin/wget"); got.Events != 0 || !got.First.IsZero() {
+		t.Fatalf("expected an empty footprint, got %+v", got)
+	}
+}
+
+func TestRuleFootprintIsScopedToNode(t *testing.T) {
+	base := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	got := RuleFootprint(footprintEvents(), "a", "allow-curl")
+	if got.Events != 3 || got.Allowed != 3 || !got.First.Equal(base) || !got.Last.Equal(base.Add(3*time.Minute)) {
+		t.Fatalf("RuleFootprint = %+v", got)
+	}
+	if got := RuleFootprint(footprintEvents(), "b", "deny-quad9"); got.Events != 0 {
+		t.Fatalf("expected rules on other nodes not to count, got %+v", got)
+	}
+}
+
+func TestMergeEventsBumpsRevision(t *testing.T) {
+	store := NewStore()
+	before := store.Snapshot().EventsRevision
+	store.MergeEvents(nil)
+	if store.Snapshot().EventsRevision != before {
+		t.Fatalf("expected an empty batch to leave the revision alone")
+	}
+	store.MergeEvents(footprintEvents()[:1])
+	if store.Snapshot().EventsRevision == before {
+		t.Fatalf("expected the revision to move")
+	}
+}
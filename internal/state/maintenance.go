@@ -0,0 +1,168 @@
+package state
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maintenanceSummaryItems caps how many auto-answered processes the summary
+// alert names.
+const maintenanceSummaryItems = 5
+
+// Maintenance is a window during which a node's prompts are answered
+// automatically and its alerts are kept but marked suppressed.
+type Maintenance struct {
+	Since time.Time
+	Until time.Time
+	// Answered counts the prompts answered automatically, by process.
+	Answered map[string]int
+	// Action is how they were answered.
+	Action     string
+	Suppressed int
+}
+
+// Active reports whether the window is still open at now.
+func (m Maintenance) Active(now time.Time) bool {
+	return now.Before(m.Until)
+}
+
+// Summary describes what happened during the window on the node called
+// name, for the alert raised when it ends.
+func (m Maintenance) Summary(name string, ended time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Maintenance on %s ended after %s: ", name, ended.Sub(m.Since).Round(time.Second))
+	total := 0
+	for _, n := range m.Answered {
+		total += n
+	}
+	if total == 0 {
+		b.WriteString("no prompts")
+	} else {
+		fmt.Fprintf(&b, "auto-%s %d %s (%s)", pastTense(m.Action), total, plural(total, "connection"), topProcesses(m.Answered))
+	}
+	fmt.Fprintf(&b, ", %d %s suppressed", m.Suppressed, plural(m.Suppressed, "alert"))
+	return b.String()
+}
+
+// topProcesses lists the most answered processes, most first.
+func topProcesses(counts map[string]int) string {
+	names := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	parts := make([]string, 0, maintenanceSummaryItems+1)
+	for i, name := range names {
+		if i == maintenanceSummaryItems {
+			parts = append(parts, fmt.Sprintf("%d more", len(names)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s ×%d", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pastTense(action string) string {
+	switch action {
+	case "allow":
+		return "allowed"
+	case "deny":
+		return "denied"
+	case "reject":
+		return "rejected"
+	}
+	return "answered"
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// MaintenanceFor returns nodeID's maintenance window when it is open at now.
+func (s Snapshot) MaintenanceFor(nodeID string, now time.Time) (Maintenance, bool) {
+	m, ok := s.Maintenance[nodeID]
+	if !ok || !m.Active(now) {
+		return Maintenance{}, false
+	}
+	return m, true
+}
+
+// StartMaintenance opens a window on nodeID, replacing any open one.
+func (s *Store) StartMaintenance(nodeID, action string, since, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot.Maintenance == nil {
+		s.snapshot.Maintenance = make(map[string]Maintenance)
+	}
+	s.snapshot.Maintenance[nodeID] = Maintenance{Since: since, Until: until, Action: action, Answered: map[string]int{}}
+	s.notifyLocked()
+}
+
+// EndMaintenance closes nodeID's window and returns it.
+func (s *Store) EndMaintenance(nodeID string) (Maintenance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.snapshot.Maintenance[nodeID]
+	if !ok {
+		return Maintenance{}, false
+	}
+	delete(s.snapshot.Maintenance, nodeID)
+	s.notifyLocked()
+	return m, true
+}
+
+// RecordMaintenanceAnswer counts a prompt from nodeID for process that was
+// answered because of its window. It reports false when no window is open
+// at now.
+func (s *Store) RecordMaintenanceAnswer(nodeID, process string, now time.Time) bool {
+	return s.updateMaintenance(nodeID, now, func(m *Maintenance) { m.Answered[process]++ })
+}
+
+// AddSuppressedAlert stores alert marked as suppressed when its node is in
+// maintenance at now, counting it for the summary, and reports whether it
+// did. Other alerts are left to AddAlert.
+func (s *Store) AddSuppressedAlert(alert Alert, now time.Time) bool {
+	if !s.updateMaintenance(alert.NodeID, now, func(m *Maintenance) { m.Suppressed++ }) {
+		return false
+	}
+	alert.Suppressed = true
+	s.AddAlert(alert)
+	return true
+}
+
+func (s *Store) updateMaintenance(nodeID string, now time.Time, fn func(*Maintenance)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.snapshot.Maintenance[nodeID]
+	if !ok || !m.Active(now) {
+		return false
+	}
+	fn(&m)
+	s.snapshot.Maintenance[nodeID] = m
+	s.notifyLocked()
+	return true
+}
+
+func cloneMaintenance(in map[string]Maintenance) map[string]Maintenance {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]Maintenance, len(in))
+	for id, m := range in {
+		m.Answered = maps.Clone(m.Answered)
+		out[id] = m
+	}
+	return out
+}
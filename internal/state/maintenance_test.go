@@ -0,0 +1,87 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceSummary(t *testing.T) {
+	since := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		window Maintenance
+		want   string
+	}{
+		{
+			name:   "quiet",
+			window: Maintenance{Since: since, Action: "allow"},
+			want:   "Maintenance on alpha ended after 30m0s: no prompts, 0 alerts suppressed",
+		},
+		{
+			name:   "one each",
+			window: Maintenance{Since: since, Action: "deny", Answered: map[string]int{"/usr/bin/apt": 1}, Suppressed: 1},
+			want:   "Maintenance on alpha ended after 30m0s: auto-denied 1 connection (/usr/bin/apt ×1), 1 alert suppressed",
+		},
+		{
+			name: "most first, capped",
+			window: Maintenance{Since: since, Action: "allow", Suppressed: 4, Answered: map[string]int{
+				"a": 1, "b": 1, "c": 2, "d": 1, "e": 1, "f": 1, "g": 9,
+			}},
+			want: "Maintenance on alpha ended after 30m0s: auto-allowed 16 connections (g ×9, c ×2, a ×1, b ×1, d ×1, 2 more), 4 alerts suppressed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Summary("alpha", since.Add(30*time.Minute)); got != tt.want {
+				t.Fatalf("got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreMaintenanceExpiry(t *testing.T) {
+	store := NewStore()
+	since := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+	store.StartMaintenance("node-1", "allow", since, until)
+
+	if !store.RecordMaintenanceAnswer("node-1", "/usr/bin/apt", since.Add(time.Minute)) {
+		t.Fatalf("expected the answer counted inside the window")
+	}
+	if store.RecordMaintenanceAnswer("node-2", "/usr/bin/apt", since.Add(time.Minute)) {
+		t.Fatalf("expected other nodes unaffected")
+	}
+	if !store.AddSuppressedAlert(Alert{NodeID: "node-1", Text: "noisy"}, since.Add(time.Minute)) {
+		t.Fatalf("expected the alert suppressed inside the window")
+	}
+	// Past Until the window is closed even before it is ended.
+	if store.RecordMaintenanceAnswer("node-1", "/usr/bin/apt", until) {
+		t.Fatalf("expected no answer counted once the window has passed")
+	}
+	if store.AddSuppressedAlert(Alert{NodeID: "node-1", Text: "late"}, until) {
+		t.Fatalf("expected alerts after the window left alone")
+	}
+
+	snap := store.Snapshot()
+	if _, ok := snap.MaintenanceFor("node-1", until); ok {
+		t.Fatalf("expected MaintenanceFor to ignore an expired window")
+	}
+	window, ok := snap.MaintenanceFor("node-1", since)
+	if !ok || window.Answered["/usr/bin/apt"] != 1 || window.Suppressed != 1 {
+		t.Fatalf("unexpected window %+v", window)
+	}
+	if len(snap.Alerts) != 1 || !snap.Alerts[0].Suppressed {
+		t.Fatalf("expected one suppressed alert, got %+v", snap.Alerts)
+	}
+	window.Answered["/usr/bin/apt"] = 99
+	if store.Snapshot().Maintenance["node-1"].Answered["/usr/bin/apt"] != 1 {
+		t.Fatalf("snapshot shares the answered counts with the store")
+	}
+
+	if _, ok := store.EndMaintenance("node-1"); !ok {
+		t.Fatalf("expected EndMaintenance to return the window")
+	}
+	if _, ok := store.EndMaintenance("node-1"); ok {
+		t.Fatalf("expected a second EndMaintenance to find nothing")
+	}
+}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"net"
+	"strings"
+)
+
+// PromptMode is how a node's connection prompts are handled.
+type PromptMode string
+
+const (
+	// PromptsInteractive asks the user, as usual.
+	PromptsInteractive PromptMode = "interactive"
+	// PromptsPolicy answers every prompt with the defaults at once,
+	// without showing it.
+	PromptsPolicy PromptMode = "policy"
+)
+
+// PromptModeFor resolves the prompt mode of nodeID. A mode set on the node
+// itself wins; otherwise the configured node it corresponds to decides, and
+// nodes nothing is known about are interactive.
+func PromptModeFor(nodes []Node, nodeID string) PromptMode {
+	live := Node{ID: nodeID}
+	for _, n := range nodes {
+		if n.ID == nodeID {
+			live = n
+			break
+		}
+	}
+	if live.Prompts != "" {
+		return live.Prompts
+	}
+	if configured, ok := MatchConfigured(nodes, live); ok && configured.Prompts != "" {
+		return configured.Prompts
+	}
+	return PromptsInteractive
+}
+
+// MatchConfigured finds the configured node a node derived from a peer
+// connection stands for. Peers connect from ephemeral ports, so their IDs
+// rarely equal a configured one; they match on the ID, on the address host
+// (any unix socket for a unix address), or on the name the daemon reports.
+func MatchConfigured(nodes []Node, live Node) (Node, bool) {
+	if live.Configured {
+		return live, true
+	}
+	liveHost := nodeHost(live.Address)
+	if liveHost == "" {
+		liveHost = nodeHost(live.ID)
+	}
+	for _, n := range nodes {
+		if !n.Configured {
+			continue
+		}
+		switch {
+		case n.ID == live.ID:
+			return n, true
+		case isUnixAddress(n.Address) && (isUnixAddress(live.ID) || isUnixAddress(live.Address)):
+			return n, true
+		case liveHost != "" && nodeHost(n.Address) == liveHost:
+			return n, true
+		case live.Name != "" && strings.EqualFold(n.Name, live.Name):
+			return n, true
+		}
+	}
+	return Node{}, false
+}
+
+// ListedAddress is the address n is listed under in the config file. A
+// peer is listed under the address it connected from, which
+// MatchConfigured recognises again by host once its ephemeral port
+// changes; a unix socket peer keeps its ID, which names the network.
+func (n Node) ListedAddress() string {
+	if isUnixAddress(n.ID) {
+		return n.ID
+	}
+	return n.Address
+}
+
+// nodeHost returns the host part of a "scheme://host:port" or "host:port"
+// address, or "" for unix sockets and empty addresses.
+func nodeHost(addr string) string {
+	if addr == "" || isUnixAddress(addr) {
+		return ""
+	}
+	if _, rest, ok := strings.Cut(addr, "://"); ok {
+		addr = rest
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func isUnixAddress(addr string) bool {
+	return strings.HasPrefix(addr, "unix:")
+}
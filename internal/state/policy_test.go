@@ -0,0 +1,31 @@
+package state
+
+import "testing"
+
+func TestPromptModeForMatchesConfiguredNodes(t *testing.T) {
+	nodes := []Node{
+		{ID: "node-1", Name: "server", Address: "tcp://10.0.0.7:50051", Prompts: PromptsPolicy, Configured: true},
+		{ID: "node-2", Name: "local", Address: "unix:///tmp/osui.sock", Prompts: PromptsPolicy, Configured: true},
+		{ID: "node-3", Name: "laptop", Address: "10.0.0.8:50051", Prompts: PromptsInteractive, Configured: true},
+		{ID: "tcp://10.0.0.7:41000", Name: "tcp://10.0.0.7:41000", Address: "10.0.0.7:41000"},
+		{ID: "tcp://192.168.1.9:43000", Name: "SERVER", Address: "192.168.1.9:43000"},
+		{ID: "tcp://10.0.0.8:42000", Name: "laptop", Address: "10.0.0.8:42000", Prompts: PromptsPolicy},
+	}
+	cases := []struct {
+		id   string
+		want PromptMode
+	}{
+		{"node-1", PromptsPolicy},
+		{"tcp://10.0.0.7:41000", PromptsPolicy},
+		{"unix://@", PromptsPolicy},
+		{"tcp://192.168.1.9:43000", PromptsPolicy},
+		// A mode set on the live node overrides its configured one.
+		{"tcp://10.0.0.8:42000", PromptsPolicy},
+		{"tcp://10.0.0.99:40000", PromptsInteractive},
+	}
+	for _, tc := range cases {
+		if got := PromptModeFor(nodes, tc.id); got != tc.want {
+			t.Errorf("PromptModeFor(%q) = %q, want %q", tc.id, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package state
+
+import "slices"
+
+// Profile is a named workspace layout that views capture from and apply to
+// themselves. String fields use the names in the config package; empty
+// ones mean the view's default.
+type Profile struct {
+	Name string
+	// View is the view to switch to; empty keeps the current one.
+	View   ViewKind
+	Events EventsLayout
+	Rules  RulesLayout
+}
+
+// EventsLayout is how the Events view filters, sorts and sizes its table.
+type EventsLayout struct {
+	Filter     string
+	Sort       string
+	Columns    []string
+	Time       string
+	TableShare float64
+}
+
+// RulesLayout is how the Rules view filters, sorts and sizes its table.
+type RulesLayout struct {
+	HideDisabled bool
+	Sort         string
+	TableShare   float64
+}
+
+// WithProfile returns profiles with p replacing the profile of the same
+// name, or appended when there is none. profiles is not modified.
+func WithProfile(profiles []Profile, p Profile) []Profile {
+	out := slices.Clone(profiles)
+	for i := range out {
+		if out[i].Name == p.Name {
+			out[i] = p
+			return out
+		}
+	}
+	return append(out, p)
+}
@@ -0,0 +1,244 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RuleHistoryCap is how many changes are kept per rule; older ones are
+// dropped first.
+const RuleHistoryCap = 50
+
+// RuleChangeSource says what changed a rule.
+type RuleChangeSource string
+
+const (
+	// RuleChangeUser is an action taken in this UI: a create, edit,
+	// toggle, delete or restore.
+	RuleChangeUser RuleChangeSource = "user"
+	// RuleChangeDaemon is a difference found when a daemon sent its rule
+	// list on Subscribe.
+	RuleChangeDaemon RuleChangeSource = "daemon"
+	// RuleChangePrompt is a rule created by answering a prompt.
+	RuleChangePrompt RuleChangeSource = "prompt"
+)
+
+// RuleChangeKind is what happened to the rule.
+type RuleChangeKind string
+
+const (
+	RuleCreated  RuleChangeKind = "created"
+	RuleChanged  RuleChangeKind = "changed"
+	RuleEnabled  RuleChangeKind = "enabled"
+	RuleDisabled RuleChangeKind = "disabled"
+	RuleDeleted  RuleChangeKind = "deleted"
+)
+
+// FieldChange is one rule field before and after a change. From is empty
+// for a created rule and To for a deleted one.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// RuleChange is one entry of a rule's timeline.
+type RuleChange struct {
+	// NodeKey is the NodeKey of the rule's node, so the timeline survives
+	// the node reconnecting under a new ID.
+	NodeKey string           `json:"node_key"`
+	Rule    string           `json:"rule"`
+	At      time.Time        `json:"at"`
+	Source  RuleChangeSource `json:"source"`
+	Kind    RuleChangeKind   `json:"kind"`
+	Fields  []FieldChange    `json:"fields,omitempty"`
+}
+
+// Summary describes the changed fields on one line.
+func (c RuleChange) Summary() string {
+	parts := make([]string, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		switch {
+		case c.Kind == RuleCreated:
+			parts = append(parts, fmt.Sprintf("%s=%s", f.Field, f.To))
+		case c.Kind == RuleDeleted:
+			parts = append(parts, fmt.Sprintf("%s=%s", f.Field, f.From))
+		default:
+			parts = append(parts, fmt.Sprintf("%s: %s → %s", f.Field, quoteEmpty(f.From), quoteEmpty(f.To)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func quoteEmpty(value string) string {
+	if value == "" {
+		return `""`
+	}
+	return value
+}
+
+type historyKey struct {
+	nodeKey, rule string
+}
+
+// RuleHistory returns the timeline of rule name on the node with nodeID,
+// newest first.
+func (s *Store) RuleHistory(nodeID, name string) []RuleChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.ruleHistory[historyKey{NodeKeyOf(s.snapshot.Nodes, nodeID), name}]
+	out := make([]RuleChange, len(entries))
+	for i, entry := range entries {
+		out[len(entries)-1-i] = cloneRuleChange(entry)
+	}
+	return out
+}
+
+// RuleHistoryEntries returns every rule's timeline, oldest first, for
+// saving.
+func (s *Store) RuleHistoryEntries() []RuleChange {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []RuleChange
+	for _, entries := range s.ruleHistory {
+		for _, entry := range entries {
+			out = append(out, cloneRuleChange(entry))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+// SetRuleHistory replaces the timelines, for restoring them from disk.
+func (s *Store) SetRuleHistory(entries []RuleChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ruleHistory = nil
+	for _, entry := range entries {
+		s.appendRuleChangeLocked(cloneRuleChange(entry))
+	}
+	s.notifyLocked()
+}
+
+// recordRuleChangeLocked adds the change from before to after, either nil
+// for a created or deleted rule, to the rule's timeline. Nothing is kept
+// when no tracked field differs.
+func (s *Store) recordRuleChangeLocked(nodeID string, before, after *Rule, source RuleChangeSource) {
+	change := RuleChange{NodeKey: NodeKeyOf(s.snapshot.Nodes, nodeID), At: time.Now(), Source: source}
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		change.Rule, change.Kind = after.Name, RuleCreated
+		for _, f := range ruleFields(*after) {
+			change.Fields = append(change.Fields, FieldChange{Field: f.name, To: f.value})
+		}
+	case after == nil:
+		change.Rule, change.Kind = before.Name, RuleDeleted
+		for _, f := range ruleFields(*before) {
+			change.Fields = append(change.Fields, FieldChange{Field: f.name, From: f.value})
+		}
+	default:
+		change.Rule, change.Kind = after.Name, RuleChanged
+		from, to := ruleFields(*before), ruleFields(*after)
+		if before.Name != after.Name {
+			change.Fields = append(change.Fields, FieldChange{Field: "name", From: before.Name, To: after.Name})
+		}
+		for i := range to {
+			if from[i].value != to[i].value {
+				change.Fields = append(change.Fields, FieldChange{Field: to[i].name, From: from[i].value, To: to[i].value})
+			}
+		}
+		if len(change.Fields) == 0 {
+			return
+		}
+		if len(change.Fields) == 1 && change.Fields[0].Field == "enabled" {
+			change.Kind = RuleDisabled
+			if after.Enabled {
+				change.Kind = RuleEnabled
+			}
+		}
+	}
+	s.appendRuleChangeLocked(change)
+}
+
+// recordRuleDiffLocked records how a node's rule list went from before to
+// after, matching rules by name.
+func (s *Store) recordRuleDiffLocked(nodeID string, before, after []Rule, source RuleChangeSource) {
+	old := make(map[string]Rule, len(before))
+	for _, rule := range before {
+		old[rule.Name] = rule
+	}
+	for i := range after {
+		if prev, ok := old[after[i].Name]; ok {
+			delete(old, after[i].Name)
+			s.recordRuleChangeLocked(nodeID, &prev, &after[i], source)
+			continue
+		}
+		s.recordRuleChangeLocked(nodeID, nil, &after[i], source)
+	}
+	for _, rule := range before {
+		if gone, ok := old[rule.Name]; ok {
+			s.recordRuleChangeLocked(nodeID, &gone, nil, source)
+		}
+	}
+}
+
+func (s *Store) appendRuleChangeLocked(change RuleChange) {
+	if s.ruleHistory == nil {
+		s.ruleHistory = make(map[historyKey][]RuleChange)
+	}
+	key := historyKey{change.NodeKey, change.Rule}
+	entries := append(s.ruleHistory[key], change)
+	if len(entries) > RuleHistoryCap {
+		entries = append(entries[:0:0], entries[len(entries)-RuleHistoryCap:]...)
+	}
+	s.ruleHistory[key] = entries
+}
+
+type ruleField struct {
+	name, value string
+}
+
+// ruleFields are the fields a timeline tracks, in display order.
+func ruleFields(rule Rule) []ruleField {
+	return []ruleField{
+		{"action", rule.Action},
+		{"duration", rule.Duration},
+		{"enabled", yesNo(rule.Enabled)},
+		{"precedence", yesNo(rule.Precedence)},
+		{"nolog", yesNo(rule.NoLog)},
+		{"description", rule.Description},
+		{"operator", formatOperator(rule.Operator)},
+	}
+}
+
+// formatOperator writes op as type:operand=data, list operands joined in
+// brackets.
+func formatOperator(op RuleOperator) string {
+	if len(op.Children) == 0 {
+		return fmt.Sprintf("%s:%s=%s", op.Type, op.Operand, op.Data)
+	}
+	children := make([]string, len(op.Children))
+	for i, child := range op.Children {
+		children[i] = formatOperator(child)
+	}
+	return fmt.Sprintf("%s[%s]", op.Type, strings.Join(children, " "))
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+func cloneRuleChange(change RuleChange) RuleChange {
+	change.Fields = append([]FieldChange(nil), change.Fields...)
+	return change
+}
@@ -0,0 +1,114 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+)
+
+func historyStore() *Store {
+	store := NewStore()
+	store.SetNodes([]Node{{ID: "node-1", Name: "alpha"}})
+	return store
+}
+
+func TestRuleHistoryRecordsUserAndPromptChanges(t *testing.T) {
+	store := historyStore()
+	store.AddRuleFrom("node-1", Rule{Name: "curl", Action: "allow", Duration: "always", Enabled: true}, RuleChangePrompt)
+	store.UpdateRule("node-1", "curl", func(r *Rule) { r.Enabled = false })
+	store.UpdateRule("node-1", "curl", func(r *Rule) { r.Action, r.Description = "deny", "noisy" })
+	store.UpdateRule("node-1", "curl", func(r *Rule) {})
+	store.RemoveRule("node-1", "curl")
+
+	got := store.RuleHistory("node-1", "curl")
+	want := []struct {
+		source RuleChangeSource
+		kind   RuleChangeKind
+	}{
+		{RuleChangeUser, RuleDeleted},
+		{RuleChangeUser, RuleChanged},
+		{RuleChangeUser, RuleDisabled},
+		{RuleChangePrompt, RuleCreated},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d changes newest first, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Source != w.source || got[i].Kind != w.kind || got[i].NodeKey != "name:alpha" {
+			t.Fatalf("change %d: expected %s %s, got %+v", i, w.source, w.kind, got[i])
+		}
+	}
+	if summary := got[1].Summary(); summary != `action: allow → deny, description: "" → noisy` {
+		t.Fatalf("unexpected field summary %q", summary)
+	}
+	if summary := got[3].Summary(); summary != "action=allow, duration=always, enabled=yes, precedence=no, nolog=no, description=, operator=:=" {
+		t.Fatalf("unexpected created summary %q", summary)
+	}
+}
+
+func TestRuleHistoryRecordsDaemonDiff(t *testing.T) {
+	store := historyStore()
+	ssh := Rule{Name: "ssh", Action: "allow", Operator: RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"}}
+	dig := Rule{Name: "dig", Action: "allow"}
+	// The first list a node reports is where its history starts.
+	store.SetRules("node-1", []Rule{ssh, dig})
+	if got := store.RuleHistoryEntries(); len(got) != 0 {
+		t.Fatalf("expected the first list left unrecorded, got %+v", got)
+	}
+	store.SetRules("node-1", []Rule{ssh, dig})
+	if got := store.RuleHistoryEntries(); len(got) != 0 {
+		t.Fatalf("expected an unchanged list left unrecorded, got %+v", got)
+	}
+
+	changed := ssh
+	changed.Operator.Data = "/usr/bin/scp"
+	store.SetRules("node-1", []Rule{changed, {Name: "ntp", Action: "allow"}})
+	for name, kind := range map[string]RuleChangeKind{"ssh": RuleChanged, "dig": RuleDeleted, "ntp": RuleCreated} {
+		got := store.RuleHistory("node-1", name)
+		if len(got) != 1 || got[0].Source != RuleChangeDaemon || got[0].Kind != kind {
+			t.Fatalf("%s: expected one daemon %s change, got %+v", name, kind, got)
+		}
+	}
+	fields := store.RuleHistory("node-1", "ssh")[0].Fields
+	if len(fields) != 1 || fields[0].From != "simple:process.path=/usr/bin/ssh" || fields[0].To != "simple:process.path=/usr/bin/scp" {
+		t.Fatalf("expected the operator change, got %+v", fields)
+	}
+}
+
+func TestRuleHistoryKeepsNewestPerRule(t *testing.T) {
+	store := historyStore()
+	store.AddRule("node-1", Rule{Name: "curl"})
+	store.AddRule("node-1", Rule{Name: "other"})
+	for i := range RuleHistoryCap + 5 {
+		store.UpdateRule("node-1", "curl", func(r *Rule) { r.Description = fmt.Sprintf("edit %d", i) })
+	}
+
+	got := store.RuleHistory("node-1", "curl")
+	if len(got) != RuleHistoryCap {
+		t.Fatalf("expected the timeline capped at %d, got %d", RuleHistoryCap, len(got))
+	}
+	if to := got[0].Fields[0].To; to != fmt.Sprintf("edit %d", RuleHistoryCap+4) {
+		t.Fatalf("expected the newest edit kept, got %q", to)
+	}
+	if got[len(got)-1].Kind != RuleChanged {
+		t.Fatalf("expected the creation evicted first, got %+v", got[len(got)-1])
+	}
+	if other := store.RuleHistory("node-1", "other"); len(other) != 1 {
+		t.Fatalf("expected other rules' timelines untouched, got %+v", other)
+	}
+}
+
+func TestSetRuleHistoryRestoresByNodeKey(t *testing.T) {
+	store := historyStore()
+	store.AddRule("node-1", Rule{Name: "curl"})
+	store.UpdateRule("node-1", "curl", func(r *Rule) { r.Enabled = true })
+	saved := store.RuleHistoryEntries()
+
+	// The next session sees the node under a new ID.
+	restored := NewStore()
+	restored.SetNodes([]Node{{ID: "node-9", Name: "alpha"}})
+	restored.SetRuleHistory(saved)
+	got := restored.RuleHistory("node-9", "curl")
+	if len(got) != 2 || got[0].Kind != RuleEnabled || got[1].Kind != RuleCreated {
+		t.Fatalf("expected the timeline found under the node's name, got %+v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package state
+
+import "time"
+
+// maxRuleHits bounds the last-hit index. Past it the rule hit longest ago
+// is forgotten, which only turns its freshness dot gray early.
+const maxRuleHits = 4096
+
+// Default freshness thresholds for the Rules view's last-hit dot.
+const (
+	DefaultRuleHitFresh  = 5 * time.Minute
+	DefaultRuleHitRecent = time.Hour
+)
+
+// RuleHitThresholds returns the configured freshness thresholds or their
+// defaults; recent is never shorter than fresh.
+func (s Settings) RuleHitThresholds() (fresh, recent time.Duration) {
+	fresh, recent = s.RuleHitFresh, s.RuleHitRecent
+	if fresh <= 0 {
+		fresh = DefaultRuleHitFresh
+	}
+	if recent <= 0 {
+		recent = DefaultRuleHitRecent
+	}
+	return fresh, max(fresh, recent)
+}
+
+// recordRuleHitsLocked notes, per rule, the newest event that matched it,
+// on the local clock. Events without a rule or a time are skipped.
+func (s *Store) recordRuleHitsLocked(events []Event, offsets map[string]time.Duration) {
+	for _, ev := range events {
+		if ev.Rule.Name == "" || ev.UnixNano == 0 {
+			continue
+		}
+		key := ruleKey{ev.NodeID, ev.Rule.Name}
+		at := time.Unix(0, ev.UnixNano-int64(offsets[ev.NodeID]))
+		if last, ok := s.ruleHits[key]; ok {
+			if at.After(last) {
+				s.ruleHits[key] = at
+			}
+			continue
+		}
+		if s.ruleHits == nil {
+			s.ruleHits = make(map[ruleKey]time.Time)
+		}
+		if len(s.ruleHits) >= maxRuleHits {
+			s.evictOldestRuleHitLocked()
+		}
+		s.ruleHits[key] = at
+	}
+}
+
+func (s *Store) evictOldestRuleHitLocked() {
+	var oldest ruleKey
+	var oldestAt time.Time
+	first := true
+	for key, at := range s.ruleHits {
+		if first || at.Before(oldestAt) {
+			oldest, oldestAt, first = key, at, false
+		}
+	}
+	delete(s.ruleHits, oldest)
+}
+
+// RuleLastHit reports when an event last matched the rule name on nodeID;
+// ok is false for rules no event this session has hit.
+func (s *Store) RuleLastHit(nodeID, name string) (at time.Time, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	at, ok = s.ruleHits[ruleKey{nodeID, name}]
+	return at, ok
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func hitEvent(nodeID, rule string, at time.Time) Event {
+	return Event{NodeID: nodeID, UnixNano: at.UnixNano(), Rule: Rule{Name: rule}, Connection: Connection{DstHost: rule}}
+}
+
+func TestStoreRuleLastHitKeepsNewest(t *testing.T) {
+	store := NewStore()
+	store.SetRules("node-1", []Rule{{NodeID: "node-1", Name: "allow-curl"}})
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store.AppendEvents("node-1", []Event{
+		hitEvent("node-1", "allow-curl", t0.Add(time.Minute)),
+		hitEvent("node-1", "allow-curl", t0),
+	})
+	store.AppendEvents("node-2", []Event{hitEvent("node-2", "allow-curl", t0.Add(time.Hour))})
+
+	if at, ok := store.RuleLastHit("node-1", "allow-curl"); !ok || !at.Equal(t0.Add(time.Minute)) {
+		t.Fatalf("expected the newest hit kept, got %v %v", at, ok)
+	}
+	if at, ok := store.RuleLastHit("node-2", "allow-curl"); !ok || !at.Equal(t0.Add(time.Hour)) {
+		t.Fatalf("expected hits indexed per node, got %v %v", at, ok)
+	}
+	store.RemoveRule("node-1", "allow-curl")
+	if _, ok := store.RuleLastHit("node-1", "allow-curl"); ok {
+		t.Fatalf("expected a removed rule forgotten")
+	}
+}
+
+func TestStoreRuleLastHitNeverHitAllocatesNothing(t *testing.T) {
+	store := NewStore()
+	store.AppendEvents("node-1", []Event{
+		{NodeID: "node-1", UnixNano: 1, Connection: Connection{DstHost: "no-rule"}},
+		{NodeID: "node-1", Rule: Rule{Name: "no-time"}},
+	})
+	for range 3 {
+		if _, ok := store.RuleLastHit("node-1", "never-hit"); ok {
+			t.Fatalf("expected no hit for a rule no event matched")
+		}
+	}
+	if len(store.ruleHits) != 0 {
+		t.Fatalf("expected no index entries, got %d", len(store.ruleHits))
+	}
+}
+
+func TestStoreRuleHitsBounded(t *testing.T) {
+	store := NewStore()
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := make([]Event, maxRuleHits+10)
+	for i := range events {
+		events[i] = hitEvent("node-1", fmt.Sprintf("rule-%05d", i), t0.Add(time.Duration(i)*time.Second))
+	}
+	store.AppendEvents("node-1", events)
+
+	if len(store.ruleHits) != maxRuleHits {
+		t.Fatalf("expected the index capped at %d, got %d", maxRuleHits, len(store.ruleHits))
+	}
+	if _, ok := store.RuleLastHit("node-1", "rule-00000"); ok {
+		t.Fatalf("expected the oldest hit evicted")
+	}
+	if _, ok := store.RuleLastHit("node-1", fmt.Sprintf("rule-%05d", maxRuleHits+9)); !ok {
+		t.Fatalf("expected the newest hit kept")
+	}
+}
+
+func TestSettingsRuleHitThresholds(t *testing.T) {
+	if fresh, recent := (Settings{}).RuleHitThresholds(); fresh != DefaultRuleHitFresh || recent != DefaultRuleHitRecent {
+		t.Fatalf("expected defaults, got %v %v", fresh, recent)
+	}
+	if fresh, recent := (Settings{RuleHitFresh: 2 * time.Hour}).RuleHitThresholds(); fresh != 2*time.Hour || recent != 2*time.Hour {
+		t.Fatalf("expected recent raised to fresh, got %v %v", fresh, recent)
+	}
+}
@@ -0,0 +1,32 @@
+package state
+
+// ruleKey identifies a rule across daemon refreshes.
+type ruleKey struct {
+	nodeID, name string
+}
+
+// applySessionLocked sets Session on the rules of nodeID this process
+// created, so the mark survives a daemon replacing its rule list.
+func (s *Store) applySessionLocked(nodeID string, rules []Rule) {
+	for i := range rules {
+		_, rules[i].Session = s.sessionRules[ruleKey{nodeID, rules[i].Name}]
+	}
+}
+
+func (s *Store) markSessionLocked(nodeID, name string) {
+	if s.sessionRules == nil {
+		s.sessionRules = make(map[ruleKey]struct{})
+	}
+	s.sessionRules[ruleKey{nodeID, name}] = struct{}{}
+}
+
+// SessionRuleCount reports how many of rules this process created.
+func SessionRuleCount(rules []Rule) int {
+	count := 0
+	for _, rule := range rules {
+		if rule.Session {
+			count++
+		}
+	}
+	return count
+}
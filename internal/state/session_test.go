@@ -0,0 +1,36 @@
+package state
+
+import "testing"
+
+func TestStoreSessionRulesSurviveSetRules(t *testing.T) {
+	store := NewStore()
+	store.SetRules("node-1", []Rule{{Name: "ssh"}})
+	store.AddRule("node-1", Rule{Name: "allow-curl"})
+	store.AddRule("node-2", Rule{Name: "allow-curl"})
+
+	// A re-subscribe replaces the list with the daemon's copy, which knows
+	// nothing about the session.
+	store.SetRules("node-1", []Rule{{Name: "ssh"}, {Name: "allow-curl"}, {Name: "dns"}})
+	rules := store.Snapshot().Rules["node-1"]
+	if rules[0].Session || !rules[1].Session || rules[2].Session {
+		t.Fatalf("expected only allow-curl marked after refresh, got %+v", rules)
+	}
+	if got := SessionRuleCount(rules); got != 1 {
+		t.Fatalf("SessionRuleCount = %d, want 1", got)
+	}
+
+	store.UpdateRule("node-1", "allow-curl", func(r *Rule) { r.Name = "allow-curl-https"; r.Session = false })
+	store.SetRules("node-1", store.Snapshot().Rules["node-1"])
+	if got := store.Snapshot().Rules["node-1"][1]; got.Name != "allow-curl-https" || !got.Session {
+		t.Fatalf("expected the mark to follow a rename, got %+v", got)
+	}
+
+	store.RemoveRule("node-1", "allow-curl-https")
+	store.SetRules("node-1", []Rule{{Name: "allow-curl-https"}})
+	if store.Snapshot().Rules["node-1"][0].Session {
+		t.Fatalf("expected a deleted rule to lose its mark")
+	}
+	if !store.Snapshot().Rules["node-2"][0].Session {
+		t.Fatalf("expected marks to be kept per node")
+	}
+}
@@ -0,0 +1,71 @@
+package state
+
+import "time"
+
+// DefaultStaleAfter is how long a node may stay silent before its data is
+// shown as stale when its ping interval is not known yet.
+const DefaultStaleAfter = 60 * time.Second
+
+// Staleness describes how current the data reported by a node is.
+type Staleness struct {
+	// LastSync is the latest ping or stats update; zero when the node has
+	// never reported.
+	LastSync time.Time
+	Age      time.Duration
+	Stale    bool
+}
+
+// StaleAfter returns how long the node may go without pinging before its
+// data counts as stale: twice its observed ping interval, or
+// DefaultStaleAfter while that is unknown.
+func (n Node) StaleAfter() time.Duration {
+	if n.PingInterval <= 0 {
+		return DefaultStaleAfter
+	}
+	return 2 * n.PingInterval
+}
+
+// NodeStaleness reports how long ago node last reported at now. Stats are
+// taken into account when they belong to the node. A node that never
+// reported is not stale; there is nothing old to warn about.
+func NodeStaleness(node Node, stats Stats, now time.Time) Staleness {
+	last := node.LastSeen
+	if stats.NodeID == node.ID && stats.UpdatedAt.After(last) {
+		last = stats.UpdatedAt
+	}
+	if last.IsZero() {
+		return Staleness{}
+	}
+	age := max(0, now.Sub(last))
+	return Staleness{LastSync: last, Age: age, Stale: age > node.StaleAfter()}
+}
+
+// RecordPing marks the node ready and seen at, learning its ping interval
+// from the gap since the previous report. Gaps beyond DefaultStaleAfter are
+// outages rather than the daemon's cadence and are not learned.
+func (s *Store) RecordPing(id string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.indexOfLocked(id)
+	if idx == -1 {
+		s.snapshot.Nodes = append(s.snapshot.Nodes, Node{
+			ID:       id,
+			Name:     id,
+			Status:   NodeStatusReady,
+			Message:  "last ping",
+			LastSeen: at,
+		})
+		s.notifyLocked()
+		return
+	}
+	node := s.snapshot.Nodes[idx]
+	if gap := at.Sub(node.LastSeen); !node.LastSeen.IsZero() && gap > 0 && gap <= DefaultStaleAfter {
+		node.PingInterval = gap
+	}
+	node.Status = NodeStatusReady
+	node.Message = "last ping"
+	node.LastSeen = at
+	s.snapshot.Nodes[idx] = node
+	s.notifyLocked()
+}
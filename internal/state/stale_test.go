@@ -0,0 +1,54 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNodeStalenessDefaultThreshold(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	node := Node{ID: "node-1", LastSeen: now.Add(-59 * time.Second)}
+	if got := NodeStaleness(node, Stats{}, now); got.Stale {
+		t.Fatalf("node seen 59s ago should be fresh, got %+v", got)
+	}
+	node.LastSeen = now.Add(-61 * time.Second)
+	got := NodeStaleness(node, Stats{}, now)
+	if !got.Stale || got.Age != 61*time.Second || !got.LastSync.Equal(node.LastSeen) {
+		t.Fatalf("node seen 61s ago should be stale, got %+v", got)
+	}
+	if got := NodeStaleness(Node{ID: "new"}, Stats{}, now); got.Stale || !got.LastSync.IsZero() {
+		t.Fatalf("a node that never reported is not stale, got %+v", got)
+	}
+}
+
+func TestNodeStalenessUsesPingIntervalAndStats(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	node := Node{ID: "node-1", LastSeen: now.Add(-3 * time.Second), PingInterval: time.Second}
+	if got := NodeStaleness(node, Stats{}, now); !got.Stale {
+		t.Fatalf("expected stale after 2x the ping interval, got %+v", got)
+	}
+	stats := Stats{NodeID: "node-1", UpdatedAt: now.Add(-time.Second)}
+	if got := NodeStaleness(node, stats, now); got.Stale || !got.LastSync.Equal(stats.UpdatedAt) {
+		t.Fatalf("newer stats should count as a sync, got %+v", got)
+	}
+	stats.NodeID = "node-2"
+	if got := NodeStaleness(node, stats, now); !got.Stale {
+		t.Fatalf("stats from another node should be ignored, got %+v", got)
+	}
+}
+
+func TestRecordPingLearnsInterval(t *testing.T) {
+	store := NewStore()
+	start := time.Unix(1700000000, 0)
+	store.RecordPing("node-1", start)
+	store.RecordPing("node-1", start.Add(2*time.Second))
+	node := store.Snapshot().Nodes[0]
+	if node.PingInterval != 2*time.Second || node.Status != NodeStatusReady {
+		t.Fatalf("expected a 2s interval on a ready node, got %+v", node)
+	}
+	// A long outage is not mistaken for the daemon's cadence.
+	store.RecordPing("node-1", start.Add(10*time.Minute))
+	if got := store.Snapshot().Nodes[0]; got.PingInterval != 2*time.Second || !got.LastSeen.Equal(start.Add(10*time.Minute)) {
+		t.Fatalf("expected interval kept after an outage, got %+v", got)
+	}
+}
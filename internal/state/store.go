@@ -6,7 +6,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/defaults"
 )
 
 // Store guards shared application state needed by multiple Bubble Tea models.
@@ -15,10 +15,29 @@ type Store struct {
 	snapshot Snapshot
 	subs     map[int]*Subscription
 	nextSub  int
+	// sessionRules holds the rules created by this process, for
+	// Rule.Session. It is deliberately not persisted.
+	sessionRules map[ruleKey]struct{}
+	// ruleHits indexes when each rule last matched an event; see
+	// RuleLastHit.
+	ruleHits map[ruleKey]time.Time
+	// nodeCounters keeps the counters each node last reported, as Stats
+	// only holds the latest ping's.
+	nodeCounters map[string]Counters
+	// connections is the live connection feed behind
+	// Snapshot.Connections.
+	connections *connRing
+	// ruleHistory holds each rule's timeline, oldest first; see
+	// RuleHistory.
+	ruleHistory map[historyKey][]RuleChange
 }
 
 const maxAlerts = 100
 
+const maxDecisions = 100
+
+const maxLogEntries = 500
+
 var errorDisplayTTL = 10 * time.Second
 
 // Subscription delivers notifications when the store mutates.
@@ -36,18 +55,25 @@ func NewStore() *Store {
 			Nodes:      []Node{},
 			Rules:      make(map[string][]Rule),
 			Settings: Settings{
-				ThemeName:             config.DefaultThemeName,
-				DefaultPromptAction:   config.DefaultPromptAction,
-				DefaultPromptDuration: config.DefaultPromptDuration,
-				DefaultPromptTarget:   config.DefaultPromptTarget,
-				PromptTimeout:         time.Duration(config.DefaultPromptTimeoutSeconds) * time.Second,
-				AlertsInterrupt:       config.DefaultAlertsInterrupt,
-				PausePromptOnInspect:  config.DefaultPausePromptOnInspect,
-				YaraEnabled:           config.DefaultYaraEnabled,
+				ThemeName:             defaults.ThemeName,
+				DefaultPromptAction:   defaults.PromptAction,
+				DefaultPromptDuration: defaults.PromptDuration,
+				DefaultPromptTarget:   defaults.PromptTarget,
+				PromptTimeout:         time.Duration(defaults.PromptTimeoutSeconds) * time.Second,
+				PromptInitialFocus:    defaults.PromptInitialFocus,
+				AlertsInterrupt:       defaults.AlertsInterrupt,
+				PausePromptOnInspect:  defaults.PausePromptOnInspect,
+				YaraEnabled:           defaults.YaraEnabled,
+				DNDMinutes:            defaults.DNDMinutes,
+				MaintenanceAction:     defaults.MaintenanceAction,
+				MaintenanceDuration:   defaults.MaintenanceDuration,
+				ClockSkewCorrection:   defaults.ClockSkewCorrection,
+				StartView:             ViewDashboard,
 			},
 			Prompts: []Prompt{},
 		},
-		subs: make(map[int]*Subscription),
+		subs:        make(map[int]*Subscription),
+		connections: newConnRing(DefaultConnectionCap),
 	}
 }
 
@@ -62,7 +88,16 @@ func (s *Store) Snapshot() Snapshot {
 	copySnap.Rules = cloneRulesMap(s.snapshot.Rules)
 	copySnap.Settings = s.snapshot.Settings
 	copySnap.Stats = cloneStats(s.snapshot.Stats)
+	copySnap.Events = cloneEvents(s.snapshot.Events)
 	copySnap.Prompts = clonePrompts(s.snapshot.Prompts)
+	copySnap.Decisions = cloneDecisions(s.snapshot.Decisions)
+	copySnap.Log = cloneLog(s.snapshot.Log)
+	copySnap.Acks = append([]ActionAck(nil), s.snapshot.Acks...)
+	copySnap.Maintenance = cloneMaintenance(s.snapshot.Maintenance)
+	copySnap.Trash = cloneTrash(s.snapshot.Trash)
+	copySnap.Baselines = append([]Baseline(nil), s.snapshot.Baselines...)
+	copySnap.Tasks = cloneTasks(s.snapshot.Tasks)
+	copySnap.Connections = s.connections.newestFirst()
 	return copySnap
 }
 
@@ -84,6 +119,24 @@ func (s *Store) UpsertNode(node Node) {
 	s.notifyLocked()
 }
 
+// RemoveNode drops a node together with its rules.
+func (s *Store) RemoveNode(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.indexOfLocked(id)
+	if idx == -1 {
+		return false
+	}
+	s.snapshot.Nodes = append(s.snapshot.Nodes[:idx:idx], s.snapshot.Nodes[idx+1:]...)
+	if _, ok := s.snapshot.Rules[id]; ok {
+		delete(s.snapshot.Rules, id)
+		s.rulesChangedLocked(id)
+	}
+	s.notifyLocked()
+	return true
+}
+
 // UpdateNode applies a mutation to an existing node.
 func (s *Store) UpdateNode(id string, fn func(*Node)) bool {
 	s.mu.Lock()
@@ -151,16 +204,68 @@ func (s *Store) SetStats(stats Stats) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Merge events so they don't disappear when stats updates lack events.
-	stats.Events = mergeEvents(s.snapshot.Stats.Events, stats.Events, maxEvents)
-
 	s.snapshot.Stats = cloneStats(stats)
+	if stats.NodeID != "" {
+		if s.nodeCounters == nil {
+			s.nodeCounters = make(map[string]Counters)
+		}
+		s.nodeCounters[stats.NodeID] = CountersOf(stats)
+	}
 	s.notifyLocked()
 }
 
+// MergeEvents folds a batch of events into the rolling event history,
+// dropping duplicates and keeping the newest maxEvents entries.
+func (s *Store) MergeEvents(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	incoming := cloneEvents(events)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mergeEventsLocked(incoming)
+	s.notifyLocked()
+}
+
+// AppendEvents ingests a batch of events reported by nodeID, as a daemon
+// ping delivers them. Talker totals, prompt correlation and the history are
+// updated under one lock and subscribers are notified once per batch, so
+// ingestion cost does not grow with the number of subscribers per event.
+// Events without a node ID are attributed to nodeID.
+func (s *Store) AppendEvents(nodeID string, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	incoming := cloneEvents(events)
+	for i := range incoming {
+		if incoming[i].NodeID == "" {
+			incoming[i].NodeID = nodeID
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mergeEventsLocked(incoming)
+	s.notifyLocked()
+}
+
+// mergeEventsLocked folds events, which the store now owns, into the
+// history and everything derived from it.
+func (s *Store) mergeEventsLocked(incoming []Event) {
+	s.addEventConnectionsLocked(incoming)
+	offsets := skewOffsets(s.snapshot.Nodes, s.snapshot.Settings)
+	s.correlateLocked(incoming, offsets)
+	s.recordRuleHitsLocked(incoming, offsets)
+	s.snapshot.Events = mergeEvents(s.snapshot.Events, incoming, maxEvents, offsets)
+	s.snapshot.EventsRevision++
+}
+
 const maxEvents = 200
 
-func mergeEvents(old, incoming []Event, limit int) []Event {
+// mergeEvents dedups and orders events newest first. offsets holds per-node
+// clock skew so events from different daemons interleave on the local clock.
+func mergeEvents(old, incoming []Event, limit int, offsets map[string]time.Duration) []Event {
 	if limit <= 0 {
 		limit = maxEvents
 	}
@@ -184,8 +289,9 @@ func mergeEvents(old, incoming []Event, limit int) []Event {
 	}
 
 	// Sort by time, newest first
+	local := func(ev Event) int64 { return ev.UnixNano - int64(offsets[ev.NodeID]) }
 	sort.Slice(merged, func(i, j int) bool {
-		return merged[i].UnixNano > merged[j].UnixNano
+		return local(merged[i]) > local(merged[j])
 	})
 
 	if len(merged) > limit {
@@ -198,18 +304,126 @@ func eventKey(ev Event) string {
 	return fmt.Sprintf("%s|%d|%s|%s|%s|%s|%d", ev.NodeID, ev.UnixNano, ev.Time, ev.Rule.Name, ev.Connection.DstHost, ev.Connection.DstIP, ev.Connection.DstPort)
 }
 
-// SetError records a user-visible error message.
+// SetFollowedPath records the process path the Events view follows; empty
+// stops following.
+func (s *Store) SetFollowedPath(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot.FollowedPath == path {
+		return
+	}
+	s.snapshot.FollowedPath = path
+	s.notifyLocked()
+}
+
+// SetConfigWarning records why settings cannot be saved. It stays until
+// replaced, unlike SetError.
+func (s *Store) SetConfigWarning(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot.ConfigWarning == msg {
+		return
+	}
+	s.snapshot.ConfigWarning = msg
+	if msg != "" {
+		s.appendLogLocked(LogEntry{At: time.Now(), Severity: LogWarning, Subsystem: SubsystemSettings, Text: msg})
+	}
+	s.notifyLocked()
+}
+
+// SetSecurityWarning records a risk in how the TUI was started and logs it
+// as an error; empty clears it once acknowledged.
+func (s *Store) SetSecurityWarning(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot.SecurityWarning == msg {
+		return
+	}
+	s.snapshot.SecurityWarning = msg
+	if msg != "" {
+		s.appendLogLocked(LogEntry{At: time.Now(), Severity: LogError, Subsystem: SubsystemDaemon, Text: msg})
+	}
+	s.notifyLocked()
+}
+
+// ConfigRetryingWarning starts the footer warning while settings changes
+// wait for a retried save; the failed save, naming the file, follows it.
+const ConfigRetryingWarning = "unsaved changes — retrying"
+
+// SetConfigSave publishes the settings manager's pending-save state. While
+// changes are unsaved the footer says why and, if a retry is scheduled,
+// starts with ConfigRetryingWarning; a successful save clears it.
+func (s *Store) SetConfigSave(save ConfigSave) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.snapshot.ConfigSave
+	if prev == save {
+		return
+	}
+	s.snapshot.ConfigSave = save
+	switch {
+	case save.Dirty:
+		s.snapshot.ConfigWarning = configSaveWarning(save)
+		if !prev.Dirty || prev.Err != save.Err {
+			s.appendLogLocked(LogEntry{At: time.Now(), Severity: LogWarning, Subsystem: SubsystemSettings, Text: "settings not saved: " + save.Err})
+		}
+	case prev.Dirty:
+		s.snapshot.ConfigWarning = ""
+		s.appendLogLocked(LogEntry{At: time.Now(), Severity: LogInfo, Subsystem: SubsystemSettings, Text: fmt.Sprintf("settings saved after %d failed attempts", prev.Attempts)})
+	}
+	s.notifyLocked()
+}
+
+// configSaveWarning is the footer text for unsaved settings.
+func configSaveWarning(save ConfigSave) string {
+	if save.NextRetry.IsZero() {
+		return "settings not saved: " + save.Err
+	}
+	return ConfigRetryingWarning + ": " + save.Err
+}
+
+// SetError records a user-visible error message from an unnamed subsystem.
 func (s *Store) SetError(msg string) {
+	s.ReportError("", msg)
+}
+
+// ReportError shows msg in the footer for a while and keeps it in the
+// session log under subsystem.
+func (s *Store) ReportError(subsystem, msg string) {
 	s.mu.Lock()
 	issuedAt := time.Now()
 	s.snapshot.LastError = msg
 	s.snapshot.LastErrorAt = issuedAt
+	s.appendLogLocked(LogEntry{At: issuedAt, Severity: LogError, Subsystem: subsystem, Text: msg})
 	s.notifyLocked()
 	s.mu.Unlock()
 
 	go s.expireError(issuedAt)
 }
 
+// AppendLog adds entry to the session log without showing it in the footer.
+func (s *Store) AppendLog(entry LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	s.appendLogLocked(entry)
+	s.notifyLocked()
+}
+
+func (s *Store) appendLogLocked(entry LogEntry) {
+	s.snapshot.Log = append([]LogEntry{entry}, s.snapshot.Log...)
+	if len(s.snapshot.Log) > maxLogEntries {
+		s.snapshot.Log = s.snapshot.Log[:maxLogEntries]
+	}
+}
+
 // ClearError removes the currently displayed error message, if any.
 func (s *Store) ClearError() {
 	s.mu.Lock()
@@ -223,7 +437,8 @@ func (s *Store) ClearError() {
 	s.notifyLocked()
 }
 
-// SetRules replaces the rule list for a node.
+// SetRules replaces the rule list for a node. When the node had a list
+// already, the differences go to the rules' timelines as daemon changes.
 func (s *Store) SetRules(nodeID string, rules []Rule) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -231,13 +446,23 @@ func (s *Store) SetRules(nodeID string, rules []Rule) {
 	if s.snapshot.Rules == nil {
 		s.snapshot.Rules = make(map[string][]Rule)
 	}
+	if before, ok := s.snapshot.Rules[nodeID]; ok {
+		s.recordRuleDiffLocked(nodeID, before, rules, RuleChangeDaemon)
+	}
 	s.snapshot.Rules[nodeID] = cloneRuleSlice(rules)
-	s.syncRuleCountLocked(nodeID)
+	s.applySessionLocked(nodeID, s.snapshot.Rules[nodeID])
+	s.rulesChangedLocked(nodeID)
 	s.notifyLocked()
 }
 
-// AddRule appends a rule entry for the specified node.
+// AddRule appends a rule the user created for the specified node and
+// marks it as created this session.
 func (s *Store) AddRule(nodeID string, rule Rule) {
+	s.AddRuleFrom(nodeID, rule, RuleChangeUser)
+}
+
+// AddRuleFrom is AddRule for a rule created by source.
+func (s *Store) AddRuleFrom(nodeID string, rule Rule, source RuleChangeSource) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -245,11 +470,16 @@ func (s *Store) AddRule(nodeID string, rule Rule) {
 		s.snapshot.Rules = make(map[string][]Rule)
 	}
 	rule.NodeID = nodeID
+	rule.Session = true
+	s.markSessionLocked(nodeID, rule.Name)
 	s.snapshot.Rules[nodeID] = append(s.snapshot.Rules[nodeID], cloneRule(rule))
-	s.syncRuleCountLocked(nodeID)
+	s.recordRuleChangeLocked(nodeID, nil, &rule, source)
+	s.rulesChangedLocked(nodeID)
 	s.notifyLocked()
 }
 
+// UpdateRule applies fn to a rule of the node; the change goes to the
+// rule's timeline as the user's.
 func (s *Store) UpdateRule(nodeID, ruleName string, fn func(*Rule)) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -270,12 +500,15 @@ func (s *Store) RemoveRule(nodeID, ruleName string) bool {
 			continue
 		}
 		list = append(list[:idx], list[idx+1:]...)
+		s.recordRuleChangeLocked(nodeID, &rule, nil, RuleChangeUser)
+		delete(s.sessionRules, ruleKey{nodeID, ruleName})
+		delete(s.ruleHits, ruleKey{nodeID, ruleName})
 		if len(list) == 0 {
 			delete(s.snapshot.Rules, nodeID)
 		} else {
 			s.snapshot.Rules[nodeID] = list
 		}
-		s.syncRuleCountLocked(nodeID)
+		s.rulesChangedLocked(nodeID)
 		s.notifyLocked()
 		return true
 	}
@@ -293,7 +526,7 @@ func (s *Store) AddPrompt(prompt Prompt) {
 	if prompt.ExpiresAt.IsZero() {
 		timeout := s.snapshot.Settings.PromptTimeout
 		if timeout <= 0 {
-			timeout = time.Duration(config.DefaultPromptTimeoutSeconds) * time.Second
+			timeout = time.Duration(defaults.PromptTimeoutSeconds) * time.Second
 		}
 		prompt.ExpiresAt = prompt.RequestedAt.Add(timeout)
 	}
@@ -358,6 +591,22 @@ func (s *Store) AddAlert(alert Alert) {
 	s.notifyLocked()
 }
 
+// AddDecision prepends a resolved prompt to the bounded decision history.
+func (s *Store) AddDecision(decision Decision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if decision.ResolvedAt.IsZero() {
+		decision.ResolvedAt = time.Now()
+	}
+	decision.Connection = cloneConnection(decision.Connection)
+	s.snapshot.Decisions = append([]Decision{decision}, s.snapshot.Decisions...)
+	if len(s.snapshot.Decisions) > maxDecisions {
+		s.snapshot.Decisions = s.snapshot.Decisions[:maxDecisions]
+	}
+	s.notifyLocked()
+}
+
 // Subscribe returns a subscription that receives a signal whenever the store mutates.
 func (s *Store) Subscribe() *Subscription {
 	s.mu.Lock()
@@ -437,6 +686,15 @@ func cloneNodes(nodes []Node) []Node {
 	return copyNodes
 }
 
+func cloneLog(entries []LogEntry) []LogEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	copyLog := make([]LogEntry, len(entries))
+	copy(copyLog, entries)
+	return copyLog
+}
+
 func cloneAlerts(alerts []Alert) []Alert {
 	if len(alerts) == 0 {
 		return nil
@@ -468,12 +726,23 @@ func clonePrompts(prompts []Prompt) []Prompt {
 	return copyPrompts
 }
 
+func cloneDecisions(decisions []Decision) []Decision {
+	if len(decisions) == 0 {
+		return nil
+	}
+	copyDecisions := make([]Decision, len(decisions))
+	for i, decision := range decisions {
+		decision.Connection = cloneConnection(decision.Connection)
+		copyDecisions[i] = decision
+	}
+	return copyDecisions
+}
+
 func cloneStats(stats Stats) Stats {
 	stats.TopDestHosts = cloneBuckets(stats.TopDestHosts)
 	stats.TopDestPorts = cloneBuckets(stats.TopDestPorts)
 	stats.TopExecutables = cloneBuckets(stats.TopExecutables)
 	stats.TopUsers = cloneBuckets(stats.TopUsers)
-	stats.Events = cloneEvents(stats.Events)
 	return stats
 }
 
@@ -482,7 +751,11 @@ func cloneEvents(events []Event) []Event {
 		return nil
 	}
 	copyEvents := make([]Event, len(events))
-	copy(copyEvents, events)
+	for i, ev := range events {
+		ev.Connection = cloneConnection(ev.Connection)
+		ev.Rule = cloneRule(ev.Rule)
+		copyEvents[i] = ev
+	}
 	return copyEvents
 }
 
@@ -526,6 +799,10 @@ func cloneRuleOperator(op RuleOperator) RuleOperator {
 
 func clonePrompt(prompt Prompt) Prompt {
 	prompt.Connection = cloneConnection(prompt.Connection)
+	if prompt.Blocklist != nil {
+		match := *prompt.Blocklist
+		prompt.Blocklist = &match
+	}
 	return prompt
 }
 
@@ -586,8 +863,21 @@ func mergeNodes(current, update Node) Node {
 	if update.Message == "" {
 		update.Message = current.Message
 	}
-	if !update.FirewallEnabled && current.FirewallEnabled {
-		update.FirewallEnabled = true
+	if !update.FirewallKnown {
+		update.FirewallEnabled, update.FirewallKnown = current.FirewallEnabled, current.FirewallKnown
+	}
+	if update.FirewallResumeAt.IsZero() {
+		update.FirewallResumeAt = current.FirewallResumeAt
+	}
+	if !update.ClockSkewKnown {
+		update.ClockSkew, update.ClockSkewKnown = current.ClockSkew, current.ClockSkewKnown
+	}
+	if update.Prompts == "" {
+		update.Prompts = current.Prompts
+	}
+	update.Configured = update.Configured || current.Configured
+	if update.AckCount == 0 {
+		update.AckAverage, update.AckCount, update.SlowAcks = current.AckAverage, current.AckCount, current.SlowAcks
 	}
 	return update
 }
@@ -604,17 +894,27 @@ func (s *Store) updateRuleLocked(nodeID, ruleName string, fn func(*Rule)) bool {
 		if rule.Name != ruleName {
 			continue
 		}
+		before := cloneRule(rule)
 		fn(&rule)
+		s.recordRuleChangeLocked(nodeID, &before, &rule, RuleChangeUser)
+		if _, ok := s.sessionRules[ruleKey{nodeID, ruleName}]; ok && rule.Name != ruleName {
+			delete(s.sessionRules, ruleKey{nodeID, ruleName})
+			s.markSessionLocked(nodeID, rule.Name)
+		}
+		_, rule.Session = s.sessionRules[ruleKey{nodeID, rule.Name}]
 		list[idx] = rule
 		s.snapshot.Rules[nodeID] = list
-		s.syncRuleCountLocked(nodeID)
+		s.rulesChangedLocked(nodeID)
 		s.notifyLocked()
 		return true
 	}
 	return false
 }
 
-func (s *Store) syncRuleCountLocked(nodeID string) {
+// rulesChangedLocked bumps the rules revision and keeps the rule count in
+// the stats in sync.
+func (s *Store) rulesChangedLocked(nodeID string) {
+	s.snapshot.RulesRevision++
 	if nodeID == "" {
 		return
 	}
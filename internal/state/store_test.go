@@ -312,3 +312,297 @@ func TestStoreSubscriptionCloseStopsEvents(t *testing.T) {
 	case <-time.After(50 * time.Millisecond):
 	}
 }
+
+func TestStoreMergeEventsDeepCopies(t *testing.T) {
+	store := NewStore()
+	store.MergeEvents([]Event{{
+		NodeID:     "node-1",
+		UnixNano:   1,
+		Connection: Connection{ProcessArgs: []string{"curl"}, ProcessChecksums: map[string]string{"md5": "abc"}},
+	}})
+
+	snap := store.Snapshot()
+	snap.Events[0].Connection.ProcessArgs[0] = "mutated"
+	snap.Events[0].Connection.ProcessChecksums["md5"] = "mutated"
+
+	again := store.Snapshot()
+	if got := again.Events[0].Connection.ProcessArgs[0]; got != "curl" {
+		t.Fatalf("expected args to be isolated from snapshot mutation, got %q", got)
+	}
+	if got := again.Events[0].Connection.ProcessChecksums["md5"]; got != "abc" {
+		t.Fatalf("expected checksums to be isolated from snapshot mutation, got %q", got)
+	}
+}
+
+func TestStoreMergeEventsSurvivesStatsWithoutEvents(t *testing.T) {
+	store := NewStore()
+	store.MergeEvents([]Event{{NodeID: "node-1", UnixNano: 1}})
+	store.SetStats(Stats{NodeID: "node-1", Connections: 3})
+	store.MergeEvents(nil)
+
+	if got := len(store.Snapshot().Events); got != 1 {
+		t.Fatalf("expected events to persist across stats updates, got %d", got)
+	}
+}
+
+// Run with -race: concurrent writers and snapshot readers must not share memory.
+func TestStoreEventsConcurrentAccess(t *testing.T) {
+	store := NewStore()
+	done := make(chan struct{})
+	writers := make(chan struct{})
+
+	go func() {
+		defer close(writers)
+		for i := 0; i < 500; i++ {
+			store.SetStats(Stats{NodeID: "node-1", Connections: uint64(i)})
+			store.MergeEvents([]Event{{
+				NodeID:     "node-1",
+				UnixNano:   int64(i),
+				Connection: Connection{ProcessArgs: []string{fmt.Sprint(i)}},
+			}})
+		}
+	}()
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-writers:
+				return
+			default:
+			}
+			snap := store.Snapshot()
+			for i := range snap.Events {
+				if len(snap.Events[i].Connection.ProcessArgs) > 0 {
+					snap.Events[i].Connection.ProcessArgs[0] = "reader"
+				}
+			}
+		}
+	}()
+	<-done
+
+	events := store.Snapshot().Events
+	if len(events) != maxEvents {
+		t.Fatalf("expected %d retained events, got %d", maxEvents, len(events))
+	}
+	for _, ev := range events {
+		if ev.Connection.ProcessArgs[0] == "reader" {
+			t.Fatalf("reader mutation leaked into store")
+		}
+	}
+}
+
+func TestStoreMergeEventsCorrectsClockSkew(t *testing.T) {
+	store := NewStore()
+	base := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	// node-b's clock runs a minute fast, so its event happened before node-a's.
+	store.SetNodes([]Node{
+		{ID: "node-a"},
+		{ID: "node-b", ClockSkew: time.Minute, ClockSkewKnown: true},
+	})
+	store.MergeEvents([]Event{
+		{NodeID: "node-a", UnixNano: base.Add(10 * time.Second).UnixNano()},
+		{NodeID: "node-b", UnixNano: base.Add(time.Minute).UnixNano()},
+	})
+
+	snapshot := store.Snapshot()
+	if snapshot.Events[0].NodeID != "node-a" {
+		t.Fatalf("expected skew-corrected order, got %+v", snapshot.Events)
+	}
+	if got := snapshot.EventLocalTime(snapshot.Events[1]); !got.Equal(base) {
+		t.Fatalf("expected corrected local time %v, got %v", base, got)
+	}
+
+	settings := snapshot.Settings
+	settings.ClockSkewCorrection = false
+	store.SetSettings(settings)
+	store.MergeEvents([]Event{{NodeID: "node-a", UnixNano: base.UnixNano()}})
+	snapshot = store.Snapshot()
+	if snapshot.Events[0].NodeID != "node-b" {
+		t.Fatalf("expected raw daemon order with correction disabled, got %+v", snapshot.Events)
+	}
+	if got := snapshot.EventLocalTime(snapshot.Events[0]); !got.Equal(base.Add(time.Minute)) {
+		t.Fatalf("expected uncorrected time, got %v", got)
+	}
+}
+
+func TestStoreLogIsBoundedNewestFirst(t *testing.T) {
+	store := NewStore()
+	for i := range maxLogEntries + 5 {
+		store.ReportError(SubsystemDaemon, fmt.Sprintf("error %d", i))
+	}
+	store.SetConfigWarning("settings not saved")
+
+	log := store.Snapshot().Log
+	if len(log) != maxLogEntries {
+		t.Fatalf("expected %d entries, got %d", maxLogEntries, len(log))
+	}
+	if got := log[0]; got.Severity != LogWarning || got.Subsystem != SubsystemSettings || got.Text != "settings not saved" {
+		t.Fatalf("expected the config warning first, got %+v", got)
+	}
+	if got := log[1]; got.Severity != LogError || got.Subsystem != SubsystemDaemon || got.Text != fmt.Sprintf("error %d", maxLogEntries+4) {
+		t.Fatalf("expected the newest error next, got %+v", got)
+	}
+	if got := log[len(log)-1].Text; got != "error 6" {
+		t.Fatalf("expected the oldest entries evicted, last is %q", got)
+	}
+}
+
+func TestStoreSetConfigSaveDrivesFooterWarning(t *testing.T) {
+	store := NewStore()
+	retry := time.Date(2026, 5, 1, 12, 0, 10, 0, time.UTC)
+	store.SetConfigSave(ConfigSave{Dirty: true, Attempts: 1, NextRetry: retry, Err: "save config.yaml: read-only file system"})
+	snapshot := store.Snapshot()
+	if want := ConfigRetryingWarning + ": save config.yaml: read-only file system"; snapshot.ConfigWarning != want {
+		t.Fatalf("expected the retrying warning with the cause, got %q", snapshot.ConfigWarning)
+	}
+	if got := snapshot.Log[0]; got.Severity != LogWarning || got.Text != "settings not saved: save config.yaml: read-only file system" {
+		t.Fatalf("expected the failure logged, got %+v", got)
+	}
+
+	store.SetConfigSave(ConfigSave{Dirty: true, Attempts: 2, Err: "save config.yaml: read-only file system"})
+	snapshot = store.Snapshot()
+	if got := len(snapshot.Log); got != 1 {
+		t.Fatalf("expected a repeated failure logged once, got %d entries", got)
+	}
+	if want := "settings not saved: save config.yaml: read-only file system"; snapshot.ConfigWarning != want {
+		t.Fatalf("expected no retry promised once none is scheduled, got %q", snapshot.ConfigWarning)
+	}
+
+	store.SetConfigSave(ConfigSave{})
+	snapshot = store.Snapshot()
+	if snapshot.ConfigWarning != "" || snapshot.ConfigSave.Dirty {
+		t.Fatalf("expected the warning cleared after a save, got %q", snapshot.ConfigWarning)
+	}
+	if got := snapshot.Log[0]; got.Severity != LogInfo || got.Text != "settings saved after 2 failed attempts" {
+		t.Fatalf("expected the recovery logged, got %+v", got)
+	}
+}
+
+func ingestEvents(n int) []Event {
+	events := make([]Event, n)
+	for i := range events {
+		events[i] = Event{
+			UnixNano: int64(i + 1),
+			Rule:     Rule{Name: "allow-curl", Action: "allow"},
+			Connection: Connection{
+				ProcessPath: "/usr/bin/curl",
+				DstHost:     fmt.Sprintf("host-%d.example.com", i%50),
+				DstPort:     443,
+			},
+		}
+	}
+	return events
+}
+
+func TestStoreAppendEventsIngestsBatchOnce(t *testing.T) {
+	store := NewStore()
+	sub := store.Subscribe()
+	defer sub.Close()
+	batch := ingestEvents(maxEvents + 50)
+	batch[0].NodeID = "node-b"
+
+	store.AppendEvents("node-a", batch)
+	batch[1].Connection.DstHost = "mutated"
+
+	snap := store.Snapshot()
+	if snap.EventsRevision != 1 {
+		t.Fatalf("expected one revision for the batch, got %d", snap.EventsRevision)
+	}
+	if len(snap.Events) != maxEvents {
+		t.Fatalf("expected the history capped at %d, got %d", maxEvents, len(snap.Events))
+	}
+	for _, ev := range snap.Events {
+		if ev.NodeID != "node-a" || ev.Connection.DstHost == "mutated" {
+			t.Fatalf("expected events attributed to node-a and copied, got %+v", ev)
+		}
+	}
+	select {
+	case <-sub.Events():
+	default:
+		t.Fatalf("expected subscribers to be notified")
+	}
+	select {
+	case <-sub.Events():
+		t.Fatalf("expected a single notification per batch")
+	default:
+	}
+
+	store.AppendEvents("node-a", nil)
+	if got := store.Snapshot().EventsRevision; got != 1 {
+		t.Fatalf("expected an empty batch to be ignored, got revision %d", got)
+	}
+}
+
+// Run with -race: batches from several nodes race snapshot readers.
+func TestStoreAppendEventsConcurrentReaders(t *testing.T) {
+	store := NewStore()
+	sub := store.Subscribe()
+	defer sub.Close()
+	stop := make(chan struct{})
+	readers := make(chan struct{}, 3)
+	for r := 0; r < cap(readers); r++ {
+		go func() {
+			defer func() { readers <- struct{}{} }()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-sub.Events():
+				default:
+				}
+				snap := store.Snapshot()
+				for i := range snap.Events {
+					snap.Events[i].Connection.DstHost = "reader"
+				}
+				_ = snap.Stats
+			}
+		}()
+	}
+
+	writers := make(chan struct{}, 4)
+	for w := 0; w < cap(writers); w++ {
+		go func(node string) {
+			defer func() { writers <- struct{}{} }()
+			for i := 0; i < 50; i++ {
+				store.AppendEvents(node, ingestEvents(100))
+			}
+		}(fmt.Sprintf("node-%d", w))
+	}
+	for range cap(writers) {
+		<-writers
+	}
+	close(stop)
+	for range cap(readers) {
+		<-readers
+	}
+
+	snap := store.Snapshot()
+	if snap.EventsRevision != 200 {
+		t.Fatalf("expected one revision per batch, got %d", snap.EventsRevision)
+	}
+	for _, ev := range snap.Events {
+		if ev.Connection.DstHost == "reader" {
+			t.Fatalf("reader mutation leaked into store")
+		}
+	}
+}
+
+func BenchmarkIngestPerEvent(b *testing.B) {
+	events := ingestEvents(10000)
+	b.ResetTimer()
+	for range b.N {
+		store := NewStore()
+		for i := range events {
+			store.MergeEvents(events[i : i+1])
+		}
+	}
+}
+
+func BenchmarkIngestBatched(b *testing.B) {
+	events := ingestEvents(10000)
+	b.ResetTimer()
+	for range b.N {
+		store := NewStore()
+		store.AppendEvents("node-a", events)
+	}
+}
@@ -0,0 +1,94 @@
+package state
+
+// TaskProgress is a bulk action running one task per node, as shown by the
+// view that started it.
+type TaskProgress struct {
+	ID    string
+	Title string
+	Total int
+	Done  int
+	// Failures lists the tasks that failed, in the order they finished.
+	Failures []TaskFailure
+	// Canceling is set once the user asked to stop; Skipped then counts
+	// the tasks that were never started.
+	Canceling bool
+	Skipped   int
+	Finished  bool
+}
+
+// TaskFailure is one failed task of a bulk action.
+type TaskFailure struct {
+	Name string
+	Err  string
+}
+
+// TaskByID returns the bulk action with id.
+func TaskByID(tasks []TaskProgress, id string) (TaskProgress, bool) {
+	for _, task := range tasks {
+		if task.ID == id {
+			return task, true
+		}
+	}
+	return TaskProgress{}, false
+}
+
+// SetTask records the progress of a bulk action, replacing the earlier
+// report with the same ID. A cancel request already recorded is kept.
+func (s *Store) SetTask(task TaskProgress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.Failures = append([]TaskFailure(nil), task.Failures...)
+	for i, existing := range s.snapshot.Tasks {
+		if existing.ID == task.ID {
+			task.Canceling = task.Canceling || existing.Canceling
+			s.snapshot.Tasks[i] = task
+			s.notifyLocked()
+			return
+		}
+	}
+	s.snapshot.Tasks = append(s.snapshot.Tasks, task)
+	s.notifyLocked()
+}
+
+// CancelTask marks the bulk action with id as canceling.
+func (s *Store) CancelTask(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.snapshot.Tasks {
+		if s.snapshot.Tasks[i].ID == id {
+			s.snapshot.Tasks[i].Canceling = true
+			s.notifyLocked()
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveTask drops the bulk action with id.
+func (s *Store) RemoveTask(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, task := range s.snapshot.Tasks {
+		if task.ID == id {
+			s.snapshot.Tasks = append(s.snapshot.Tasks[:i:i], s.snapshot.Tasks[i+1:]...)
+			s.notifyLocked()
+			return true
+		}
+	}
+	return false
+}
+
+func cloneTasks(tasks []TaskProgress) []TaskProgress {
+	if len(tasks) == 0 {
+		return nil
+	}
+	out := make([]TaskProgress, len(tasks))
+	for i, task := range tasks {
+		task.Failures = append([]TaskFailure(nil), task.Failures...)
+		out[i] = task
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreSetTaskKeepsCancelRequest(t *testing.T) {
+	store := NewStore()
+	store.SetTask(TaskProgress{ID: "copy-1", Title: "Copy ssh", Total: 3})
+	if !store.CancelTask("copy-1") {
+		t.Fatalf("expected the task to be found")
+	}
+	store.SetTask(TaskProgress{ID: "copy-1", Title: "Copy ssh", Total: 3, Done: 1, Failures: []TaskFailure{{Name: "db", Err: "timeout"}}})
+
+	task, ok := TaskByID(store.Snapshot().Tasks, "copy-1")
+	if !ok || task.Done != 1 || !task.Canceling || len(task.Failures) != 1 {
+		t.Fatalf("unexpected task %+v", task)
+	}
+	if !store.RemoveTask("copy-1") || len(store.Snapshot().Tasks) != 0 {
+		t.Fatalf("expected the task to be removed")
+	}
+}
+
+func TestStoreWaitAck(t *testing.T) {
+	store := NewStore()
+	sent := time.Unix(1700000000, 0)
+	store.RecordAck(ActionAck{NodeID: "n1", Action: "change", Rule: "ssh", SentAt: sent.Add(-time.Minute)})
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		store.RecordAck(ActionAck{NodeID: "n1", Action: "change", Rule: "ssh", SentAt: sent, Err: "exists"})
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ack, err := store.WaitAck(ctx, "n1", "change", "ssh", sent)
+	if err != nil || ack.Err != "exists" {
+		t.Fatalf("expected the newer ack, got %+v, %v", ack, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := store.WaitAck(ctx, "n2", "change", "ssh", sent); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// TraceDirection says which way a traced message went.
+type TraceDirection string
+
+const (
+	// TraceSent is a notification the TUI sent to a daemon.
+	TraceSent TraceDirection = "sent"
+	// TraceReceived is a notification reply a daemon sent back.
+	TraceReceived TraceDirection = "received"
+)
+
+// TraceEntry is one message of a node's notification stream, kept while
+// protocol tracing is on.
+type TraceEntry struct {
+	NodeID    string
+	At        time.Time
+	Direction TraceDirection
+	// ID is the notification ID; a reply carries the ID it answers.
+	ID uint64
+	// Action is the notification type, or the reply code.
+	Action string
+	// Summary is the payload in compact prototext, cut short when long.
+	Summary string
+}
+
+// String formats the entry as one line of a trace dump.
+func (e TraceEntry) String() string {
+	arrow := "→"
+	if e.Direction == TraceReceived {
+		arrow = "←"
+	}
+	line := fmt.Sprintf("%s %s #%d %s", e.At.Format("15:04:05.000"), arrow, e.ID, e.Action)
+	if e.Summary != "" {
+		line += " " + e.Summary
+	}
+	return line
+}
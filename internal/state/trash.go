@@ -0,0 +1,122 @@
+package state
+
+import "time"
+
+// TrashedRule is a rule deleted from a daemon and kept locally so it can
+// be restored.
+type TrashedRule struct {
+	// NodeKey is the NodeKey of the node the rule was deleted from, so the
+	// entry finds the node again after it reconnects under a new ID.
+	NodeKey   string    `json:"node_key"`
+	NodeName  string    `json:"node_name"`
+	Rule      Rule      `json:"rule"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// NodeKey identifies a node across restarts. Node IDs come from the peer
+// address, which changes with every TCP connection, so the name the daemon
+// reports (its hostname) is preferred.
+func NodeKey(node Node) string {
+	if node.Name != "" && node.Name != node.ID {
+		return "name:" + node.Name
+	}
+	return "id:" + node.ID
+}
+
+// NodeKeyOf returns the NodeKey of the node with id, or an ID key when the
+// node is not listed.
+func NodeKeyOf(nodes []Node, id string) string {
+	for _, node := range nodes {
+		if node.ID == id {
+			return NodeKey(node)
+		}
+	}
+	return "id:" + id
+}
+
+// TrashOf returns the entries of trash deleted from node, newest first.
+func TrashOf(trash []TrashedRule, node Node) []TrashedRule {
+	key := NodeKey(node)
+	var out []TrashedRule
+	for _, entry := range trash {
+		if entry.NodeKey == key {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// TrashRule puts entry at the top of the trash, replacing an older entry
+// for the same rule on the same node.
+func (s *Store) TrashRule(entry TrashedRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.Rule = cloneRule(entry.Rule)
+	entry.Rule.Cached, entry.Rule.Session = false, false
+	trash := []TrashedRule{entry}
+	for _, existing := range s.snapshot.Trash {
+		if existing.NodeKey != entry.NodeKey || existing.Rule.Name != entry.Rule.Name {
+			trash = append(trash, existing)
+		}
+	}
+	s.snapshot.Trash = trash
+	s.notifyLocked()
+}
+
+// TakeTrashed removes the entry for rule name on the node with nodeKey and
+// returns it.
+func (s *Store) TakeTrashed(nodeKey, name string) (TrashedRule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, entry := range s.snapshot.Trash {
+		if entry.NodeKey == nodeKey && entry.Rule.Name == name {
+			s.snapshot.Trash = append(s.snapshot.Trash[:i:i], s.snapshot.Trash[i+1:]...)
+			s.notifyLocked()
+			return entry, true
+		}
+	}
+	return TrashedRule{}, false
+}
+
+// EmptyTrash drops every entry of the node with nodeKey and reports how
+// many there were.
+func (s *Store) EmptyTrash(nodeKey string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.snapshot.Trash[:0:0]
+	for _, entry := range s.snapshot.Trash {
+		if entry.NodeKey != nodeKey {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(s.snapshot.Trash) - len(kept)
+	if removed > 0 {
+		s.snapshot.Trash = kept
+		s.notifyLocked()
+	}
+	return removed
+}
+
+// SetTrash replaces the trash, for restoring it from disk.
+func (s *Store) SetTrash(entries []TrashedRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot.Trash = cloneTrash(entries)
+	s.notifyLocked()
+}
+
+func cloneTrash(entries []TrashedRule) []TrashedRule {
+	if entries == nil {
+		return nil
+	}
+	out := make([]TrashedRule, len(entries))
+	for i, entry := range entries {
+		entry.Rule = cloneRule(entry.Rule)
+		out[i] = entry
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package state
+
+import "testing"
+
+func TestTrashRuleReplacesOlderEntry(t *testing.T) {
+	store := NewStore()
+	store.TrashRule(TrashedRule{NodeKey: "name:alpha", Rule: Rule{Name: "ssh", Action: "allow"}})
+	store.TrashRule(TrashedRule{NodeKey: "name:beta", Rule: Rule{Name: "ssh"}})
+	store.TrashRule(TrashedRule{NodeKey: "name:alpha", Rule: Rule{Name: "ssh", Action: "deny", Cached: true, Session: true}})
+
+	trash := store.Snapshot().Trash
+	if len(trash) != 2 || trash[0].NodeKey != "name:alpha" || trash[0].Rule.Action != "deny" {
+		t.Fatalf("expected the newer entry on top, got %+v", trash)
+	}
+	if trash[0].Rule.Cached || trash[0].Rule.Session {
+		t.Fatalf("expected cache and session marks dropped, got %+v", trash[0].Rule)
+	}
+	if got := TrashOf(trash, Node{ID: "node-1", Name: "alpha"}); len(got) != 1 {
+		t.Fatalf("expected one entry for alpha, got %+v", got)
+	}
+
+	if _, ok := store.TakeTrashed("name:alpha", "ssh"); !ok {
+		t.Fatal("expected the entry taken")
+	}
+	if _, ok := store.TakeTrashed("name:alpha", "ssh"); ok {
+		t.Fatal("expected the entry gone")
+	}
+	if removed := store.EmptyTrash("name:beta"); removed != 1 || len(store.Snapshot().Trash) != 0 {
+		t.Fatalf("expected beta's entry emptied, got %d", removed)
+	}
+}
@@ -1,29 +1,46 @@
 package state
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // ViewKind identifies a top-level view inside the TUI router.
 type ViewKind string
 
 const (
-	ViewDashboard ViewKind = "dashboard"
-	ViewAlerts    ViewKind = "alerts"
-	ViewEvents    ViewKind = "events"
-	ViewRules     ViewKind = "rules"
-	ViewNodes     ViewKind = "nodes"
-	ViewSettings  ViewKind = "settings"
+	ViewDashboard   ViewKind = "dashboard"
+	ViewAlerts      ViewKind = "alerts"
+	ViewEvents      ViewKind = "events"
+	ViewRules       ViewKind = "rules"
+	ViewNodes       ViewKind = "nodes"
+	ViewSettings    ViewKind = "settings"
+	ViewConnections ViewKind = "connections"
 )
 
 // DefaultViewOrder drives the tab navigation order across the application.
 var DefaultViewOrder = []ViewKind{
 	ViewDashboard,
 	ViewEvents,
+	ViewConnections,
 	ViewAlerts,
 	ViewRules,
 	ViewNodes,
 	ViewSettings,
 }
 
+// ParseViewKind resolves a view name, ignoring case and surrounding space,
+// to one of the views in DefaultViewOrder.
+func ParseViewKind(name string) (ViewKind, bool) {
+	kind := ViewKind(strings.ToLower(strings.TrimSpace(name)))
+	for _, known := range DefaultViewOrder {
+		if kind == known {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
 // NodeStatus captures the health of a daemon connection.
 type NodeStatus string
 
@@ -37,14 +54,39 @@ const (
 
 // Node represents a daemon endpoint tracked by the UI.
 type Node struct {
-	ID              string
-	Name            string
-	Address         string
-	Version         string
+	ID       string
+	Name     string
+	Address  string
+	Version  string
+	Status   NodeStatus
+	LastSeen time.Time
+	Message  string
+	// FirewallEnabled is the daemon's firewall state as last reported or
+	// set. FirewallKnown is false until then, and an update without it
+	// leaves the state as it was.
 	FirewallEnabled bool
-	Status          NodeStatus
-	LastSeen        time.Time
-	Message         string
+	FirewallKnown   bool
+	// PingInterval is the observed gap between pings; zero until two pings
+	// have arrived.
+	PingInterval time.Duration
+	// ClockSkew is the estimated offset of the daemon's clock from ours,
+	// positive when the daemon is ahead. Only valid when ClockSkewKnown.
+	ClockSkew      time.Duration
+	ClockSkewKnown bool
+	// FirewallResumeAt is when a timed firewall pause ends; zero when the
+	// firewall is not paused.
+	FirewallResumeAt time.Time
+	// AckAverage is the rolling average time the daemon took to
+	// acknowledge actions, over AckCount acks of which SlowAcks were slow.
+	AckAverage time.Duration
+	AckCount   int
+	SlowAcks   int
+	// Prompts is how the node's prompts are handled; empty defers to the
+	// configured node it matches (see PromptModeFor).
+	Prompts PromptMode
+	// Configured marks nodes listed in the config file, as opposed to
+	// those derived from a connecting peer.
+	Configured bool
 }
 
 // Stats aggregates daemon telemetry snapshots rendered in the dashboard.
@@ -63,7 +105,6 @@ type Stats struct {
 	TopDestPorts   []StatBucket
 	TopExecutables []StatBucket
 	TopUsers       []StatBucket
-	Events         []Event
 	UpdatedAt      time.Time
 }
 
@@ -74,6 +115,19 @@ type Event struct {
 	UnixNano   int64
 	Connection Connection
 	Rule       Rule
+	// Prompt links the event to the prompt answer that caused it, if any.
+	Prompt PromptLink
+}
+
+// PromptLink identifies the answered prompt an event resulted from. The zero
+// value means no prompt was correlated.
+type PromptLink struct {
+	PromptID   string
+	Action     string
+	Duration   string
+	Source     string
+	PromptedAt time.Time
+	ResolvedAt time.Time
 }
 
 // StatBucket captures a label/value pair for breakdown charts.
@@ -91,6 +145,9 @@ type Alert struct {
 	Type      string
 	Action    string
 	CreatedAt time.Time
+	// Suppressed marks alerts that arrived while their node was in
+	// maintenance.
+	Suppressed bool
 }
 
 // Rule represents a daemon rule entry.
@@ -105,6 +162,16 @@ type Rule struct {
 	NoLog       bool
 	CreatedAt   time.Time
 	Operator    RuleOperator
+	// Cached marks a rule restored from the on-disk cache that its daemon
+	// has not confirmed since the TUI started.
+	Cached bool
+	// Session marks a rule this TUI process created, from a prompt, the
+	// Rules view or starter rules. The store keeps it by node and name
+	// across daemon refreshes; it is not kept across restarts.
+	Session bool
+	// Raw is the rule as the daemon sent it when sanitizing it for display
+	// changed a field, so it can be sent back byte for byte.
+	Raw *Rule
 }
 
 type RuleOperator struct {
@@ -122,10 +189,71 @@ type Settings struct {
 	DefaultPromptDuration string
 	DefaultPromptTarget   string
 	PromptTimeout         time.Duration
-	AlertsInterrupt       bool
-	PausePromptOnInspect  bool
-	YaraRuleDir           string
-	YaraEnabled           bool
+	// PromptInitialFocus is where new prompts put focus: "action",
+	// "duration", "target" or "confirm".
+	PromptInitialFocus string
+	// PromptAutoJump switches the prompt overlay to another pending prompt
+	// about to time out, unless the user picked the displayed one.
+	PromptAutoJump       bool
+	AlertsInterrupt      bool
+	PausePromptOnInspect bool
+	YaraRuleDir          string
+	YaraEnabled          bool
+	// InspectHook is the configured scanner command; InspectHookEnabled
+	// runs it on inspect.
+	InspectHook        string
+	InspectHookEnabled bool
+	// InspectSections are the inspect panel sections expanded at first;
+	// nil selects the config defaults.
+	InspectSections []string
+	// DNDMinutes is the preset used when do-not-disturb is switched on;
+	// 0 keeps it on until toggled off.
+	DNDMinutes int
+	// MaintenanceAction and MaintenanceDuration answer prompts from nodes
+	// in maintenance.
+	MaintenanceAction   string
+	MaintenanceDuration string
+	// DNDEnabled mutes prompts; a zero DNDUntil means no expiry.
+	DNDEnabled bool
+	DNDUntil   time.Time
+	// ClockSkewCorrection shifts daemon timestamps by the estimated node
+	// clock skew when ordering and displaying events.
+	ClockSkewCorrection bool
+	// MaxOperatorData and MaxRuleText cap the length of user-supplied rule
+	// fields; zero selects the defaults in the rules package.
+	MaxOperatorData int
+	MaxRuleText     int
+	// RuleNameTemplate names rules created from prompts; empty selects the
+	// default in the rules package.
+	RuleNameTemplate string
+	// RuleDescriptionTemplate describes rules created from prompts; empty
+	// selects the default in the rules package.
+	RuleDescriptionTemplate string
+	// StartView is the view shown when the TUI opens.
+	StartView ViewKind
+	// UIDZeroUnknown makes a connection's UID 0 count as unresolved.
+	UIDZeroUnknown bool
+	// SlowAck is how long an action may wait for the daemon's ack before it
+	// is flagged as slow; zero selects DefaultSlowAck.
+	SlowAck time.Duration
+	// BulkParallelism caps how many nodes a bulk action works on at once;
+	// zero selects the task runner's default.
+	BulkParallelism int
+	// RuleHitFresh and RuleHitRecent are how long ago a rule may have last
+	// matched to get a green or yellow dot in the Rules view; zero selects
+	// DefaultRuleHitFresh and DefaultRuleHitRecent.
+	RuleHitFresh  time.Duration
+	RuleHitRecent time.Duration
+	// Profiles are the workspace layouts ctrl+w cycles through.
+	Profiles []Profile
+}
+
+// DNDActive reports whether do-not-disturb is in effect at now.
+func (s Settings) DNDActive(now time.Time) bool {
+	if !s.DNDEnabled {
+		return false
+	}
+	return s.DNDUntil.IsZero() || now.Before(s.DNDUntil)
 }
 
 // Connection stores the details of an outbound connection awaiting operator input.
@@ -142,8 +270,47 @@ type Connection struct {
 	ProcessCWD       string
 	ProcessArgs      []string
 	ProcessChecksums map[string]string
+	// Direction is guessed from the addresses, since the protocol does not
+	// carry it; empty means outbound.
+	Direction Direction
+	// Raw is the connection exactly as the daemon reported it, set only
+	// when sanitizing it for display changed a field. Rules built from the
+	// connection and the wire view use it; nothing renders it directly.
+	Raw *Connection
+}
+
+// Direction tells whether a connection was opened by a local process or
+// accepted from a peer.
+type Direction string
+
+const (
+	DirectionOutbound Direction = "outbound"
+	DirectionInbound  Direction = "inbound"
+)
+
+// Inbound reports whether a peer opened the connection. For inbound
+// connections Src is the peer and Dst the local listener.
+func (c Connection) Inbound() bool { return c.Direction == DirectionInbound }
+
+// RawProcessPath returns the executable's path as the daemon reported it.
+// ProcessPath is sanitized for display, so anything that opens the file
+// must use this instead: a path with control characters in it would
+// otherwise name a file that does not exist.
+func (c Connection) RawProcessPath() string {
+	if c.Raw != nil {
+		return c.Raw.ProcessPath
+	}
+	return c.ProcessPath
 }
 
+// PIDKnown reports whether the daemon resolved the process; it sends 0
+// when it could not.
+func (c Connection) PIDKnown() bool { return c.ProcessID != 0 }
+
+// UIDKnown reports whether UserID names the connection's owner. UID 0 is
+// root, unless zeroUnknown says the daemon uses it for "not resolved".
+func (c Connection) UIDKnown(zeroUnknown bool) bool { return c.UserID != 0 || !zeroUnknown }
+
 // Prompt captures a pending AskRule request from a daemon node.
 type Prompt struct {
 	ID          string
@@ -154,17 +321,180 @@ type Prompt struct {
 	ExpiresAt   time.Time
 	Paused      bool
 	Remaining   time.Duration
+	// Blocklist is set when the destination is on a configured blocklist.
+	Blocklist *BlocklistMatch
+}
+
+// BlocklistMatch is the blocklist entry a prompt's destination matched.
+type BlocklistMatch struct {
+	List string
+	// Line is the 1-based line of Entry in the list file.
+	Line  int
+	Entry string
+	// Strict lists deny without prompting.
+	Strict bool
+	// Host is set when the destination matched by name, not address.
+	Host bool
+}
+
+// Decision source labels recorded alongside resolved prompts.
+const (
+	DecisionSourceUser     = "user"
+	DecisionSourceTimeout  = "timeout"
+	DecisionSourceDND      = "dnd"
+	DecisionSourceShutdown = "shutdown"
+	DecisionSourcePolicy   = "policy"
+	// DecisionSourceMaintenance marks prompts answered because their node
+	// was in maintenance.
+	DecisionSourceMaintenance = "maintenance"
+	// DecisionSourceBlocklist marks prompts denied because their
+	// destination is on a strict blocklist.
+	DecisionSourceBlocklist = "blocklist"
+	// DecisionSourceWithdrawn marks prompts nobody answered because the
+	// daemon stopped waiting for them.
+	DecisionSourceWithdrawn = "withdrawn"
+)
+
+// OutcomeWithdrawn is the outcome of a withdrawn prompt.
+const OutcomeWithdrawn = "withdrawn by daemon"
+
+// Decision records how a prompt was answered.
+type Decision struct {
+	PromptID   string
+	NodeID     string
+	NodeName   string
+	Connection Connection
+	Action     string
+	Duration   string
+	RuleName   string
+	Source     string
+	PromptedAt time.Time
+	ResolvedAt time.Time
+	// Outcome is the action of the first event correlated with this
+	// decision, seen at OutcomeAt; empty until such an event arrives.
+	Outcome   string
+	OutcomeAt time.Time
+}
+
+// LogSeverity ranks entries of the session log.
+type LogSeverity int
+
+const (
+	LogError LogSeverity = iota
+	LogWarning
+	LogInfo
+)
+
+func (s LogSeverity) String() string {
+	switch s {
+	case LogError:
+		return "ERROR"
+	case LogWarning:
+		return "WARN"
+	default:
+		return "INFO"
+	}
+}
+
+// Subsystems that report to the session log.
+const (
+	SubsystemDaemon   = "daemon"
+	SubsystemUI       = "ui"
+	SubsystemSettings = "settings"
+)
+
+// LogEntry is one message of the session log. Subsystem is empty when the
+// reporter did not say.
+type LogEntry struct {
+	At        time.Time
+	Severity  LogSeverity
+	Subsystem string
+	Text      string
 }
 
 // Snapshot is a threadsafe copy of the application's state tree.
 type Snapshot struct {
-	ActiveView  ViewKind
-	Nodes       []Node
-	Stats       Stats
-	Alerts      []Alert
-	Rules       map[string][]Rule
-	Settings    Settings
-	Prompts     []Prompt
-	LastError   string
-	LastErrorAt time.Time
+	ActiveView ViewKind
+	Nodes      []Node
+	Stats      Stats
+	Events     []Event
+	// EventsRevision changes whenever Events does, so views can cache data
+	// derived from the history.
+	EventsRevision uint64
+	Alerts         []Alert
+	Rules          map[string][]Rule
+	// RulesRevision changes whenever any rule list does, so views can cache
+	// data derived from Rules.
+	RulesRevision uint64
+	Settings      Settings
+	Prompts       []Prompt
+	Decisions     []Decision
+	// ConfigWarning explains why settings changes are not being saved;
+	// empty while the config is writable.
+	ConfigWarning string
+	// ConfigSave tracks settings changes that failed to save and are
+	// being retried.
+	ConfigSave ConfigSave
+	// SecurityWarning is a risk in how the TUI was started, shown until
+	// the user acknowledges it; empty when there is none.
+	SecurityWarning string
+	// FollowedPath is the process the Events view is following, if any.
+	FollowedPath string
+	LastError    string
+	LastErrorAt  time.Time
+	// Log holds the session's errors and warnings, newest first, bounded
+	// so a noisy daemon cannot grow it without limit.
+	Log []LogEntry
+	// Acks holds the daemons' replies to actions, newest first.
+	Acks []ActionAck
+	// Maintenance holds the nodes' maintenance windows by node ID; a
+	// window stays until it is ended, even past its Until.
+	Maintenance map[string]Maintenance
+	// Trash holds deleted rules that can still be restored, newest first.
+	Trash []TrashedRule
+	// Baselines holds the counter baselines marked per node.
+	Baselines []Baseline
+	// Connections is the live connection feed, newest first, bounded by
+	// the connection cap; ConnectionsSeen counts every connection added
+	// this session.
+	Connections     []ConnectionEvent
+	ConnectionsSeen uint64
+	// Tasks holds the bulk actions running or finished but not yet
+	// dismissed, oldest first.
+	Tasks []TaskProgress
+}
+
+// EventLocalTime returns when ev happened on the local clock. With clock
+// skew correction enabled the node's estimated offset is removed; this is
+// best-effort and falls back to the daemon timestamp.
+func (s Snapshot) EventLocalTime(ev Event) time.Time {
+	offset := skewOffsets(s.Nodes, s.Settings)[ev.NodeID]
+	return time.Unix(0, ev.UnixNano-int64(offset))
+}
+
+func skewOffsets(nodes []Node, settings Settings) map[string]time.Duration {
+	offsets := make(map[string]time.Duration)
+	if !settings.ClockSkewCorrection {
+		return offsets
+	}
+	for _, node := range nodes {
+		if node.ClockSkewKnown {
+			offsets[node.ID] = node.ClockSkew
+		}
+	}
+	return offsets
+}
+
+// ConfigSave is the state of settings applied in memory but not yet
+// written to the config file.
+type ConfigSave struct {
+	// Dirty is set while the config file is behind the settings in use.
+	Dirty bool
+	// Attempts counts failed saves since the config was last written.
+	Attempts int
+	// NextRetry is when the next background save is due; zero when none
+	// is scheduled.
+	NextRetry time.Time
+	// Err is the last save failure.
+	Err string
 }
@@ -0,0 +1,108 @@
+// Package tasks runs the per-node halves of bulk actions side by side, so a
+// node that is slow to answer holds up only its own task.
+package tasks
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultParallelism is how many tasks run at once when Options leaves the
+// cap unset.
+const DefaultParallelism = 4
+
+// Failure is a task that returned an error.
+type Failure struct {
+	Name string
+	Err  error
+}
+
+// Result is where a run stands: Done of Total tasks have finished, Failures
+// among them.
+type Result struct {
+	Total    int
+	Done     int
+	Failures []Failure
+	// Skipped counts the tasks never started because the run was canceled;
+	// it is only known once Run returns.
+	Skipped  int
+	Canceled bool
+}
+
+// Options tune Run.
+type Options struct {
+	// Parallelism caps how many tasks run at once; zero or less selects
+	// DefaultParallelism.
+	Parallelism int
+	// Progress, when set, receives the result as the run starts and after
+	// each task finishes. Calls never overlap.
+	Progress func(Result)
+}
+
+// Run calls fn for every item, at most opts.Parallelism at a time, and
+// returns once the started tasks have finished. name labels an item in
+// Failures. Canceling ctx stops further tasks from starting; those already
+// running are given a context that is not canceled, so they finish their
+// round trip, and are waited for.
+func Run[T any](ctx context.Context, items []T, name func(T) string, fn func(context.Context, T) error, opts Options) Result {
+	limit := opts.Parallelism
+	if limit <= 0 {
+		limit = DefaultParallelism
+	}
+	taskCtx := context.WithoutCancel(ctx)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		result  = Result{Total: len(items)}
+		slots   = make(chan struct{}, limit)
+		started int
+	)
+	report := func() {
+		if opts.Progress != nil {
+			opts.Progress(result.clone())
+		}
+	}
+	mu.Lock()
+	report()
+	mu.Unlock()
+
+dispatch:
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case slots <- struct{}{}:
+		}
+		// select picks at random when both cases are ready.
+		if ctx.Err() != nil {
+			<-slots
+			break
+		}
+		started++
+		wg.Add(1)
+		go func(item T) {
+			defer wg.Done()
+			err := fn(taskCtx, item)
+
+			mu.Lock()
+			result.Done++
+			if err != nil {
+				result.Failures = append(result.Failures, Failure{Name: name(item), Err: err})
+			}
+			report()
+			mu.Unlock()
+			<-slots
+		}(item)
+	}
+	wg.Wait()
+
+	result.Skipped = len(items) - started
+	result.Canceled = ctx.Err() != nil && result.Skipped > 0
+	return result.clone()
+}
+
+func (r Result) clone() Result {
+	r.Failures = append([]Failure(nil), r.Failures...)
+	return r
+}
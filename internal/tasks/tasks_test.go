@@ -0,0 +1,132 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func itemName(i int) string { return "node-" + strconv.Itoa(i) }
+
+func TestRunCancelStopsDispatchMidFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	var running sync.WaitGroup
+	running.Add(2)
+	var ran atomic.Int32
+	fn := func(ctx context.Context, i int) error {
+		ran.Add(1)
+		running.Done()
+		<-release
+		// Canceling the run must not cut a running task short.
+		return ctx.Err()
+	}
+
+	done := make(chan Result)
+	go func() {
+		done <- Run(ctx, []int{1, 2, 3, 4, 5}, itemName, fn, Options{Parallelism: 2})
+	}()
+	running.Wait()
+	cancel()
+	close(release)
+
+	result := <-done
+	if got := ran.Load(); got != 2 {
+		t.Fatalf("expected only the 2 running tasks to run, got %d", got)
+	}
+	if result.Done != 2 || result.Skipped != 3 || !result.Canceled || len(result.Failures) != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestRunAggregatesPartialFailures(t *testing.T) {
+	var progress []Result
+	fn := func(_ context.Context, i int) error {
+		if i%2 == 0 {
+			return errors.New("unreachable")
+		}
+		return nil
+	}
+	result := Run(context.Background(), []int{1, 2, 3, 4, 5}, itemName, fn, Options{
+		Progress: func(r Result) { progress = append(progress, r) },
+	})
+
+	if result.Total != 5 || result.Done != 5 || result.Skipped != 0 || result.Canceled {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	var names []string
+	for _, failure := range result.Failures {
+		if failure.Err == nil || failure.Err.Error() != "unreachable" {
+			t.Fatalf("unexpected failure %+v", failure)
+		}
+		names = append(names, failure.Name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "node-2" || names[1] != "node-4" {
+		t.Fatalf("expected node-2 and node-4 to fail, got %v", names)
+	}
+	// One report as the run starts and one per task, counting up.
+	if len(progress) != 6 {
+		t.Fatalf("expected 6 progress reports, got %d", len(progress))
+	}
+	for i, p := range progress {
+		if p.Done != i || p.Total != 5 {
+			t.Fatalf("report %d: unexpected progress %+v", i, p)
+		}
+	}
+}
+
+func TestRunLimitsParallelism(t *testing.T) {
+	var current, peak atomic.Int32
+	fn := func(context.Context, int) error {
+		n := current.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		current.Add(-1)
+		return nil
+	}
+	items := make([]int, 20)
+	for _, limit := range []int{1, 3} {
+		peak.Store(0)
+		result := Run(context.Background(), items, itemName, fn, Options{Parallelism: limit})
+		if result.Done != len(items) {
+			t.Fatalf("limit %d: unexpected result %+v", limit, result)
+		}
+		if got := peak.Load(); got != int32(limit) {
+			t.Fatalf("limit %d: expected %d tasks at once, peaked at %d", limit, limit, got)
+		}
+	}
+}
+
+func TestTrackRecordsProgressInStore(t *testing.T) {
+	store := state.NewStore()
+	var seen []state.TaskProgress
+	fn := func(_ context.Context, i int) error {
+		seen = append(seen, store.Snapshot().Tasks...)
+		if i == 2 {
+			return errors.New("timeout")
+		}
+		return nil
+	}
+	Track(context.Background(), store, "copy", "Copy ssh", []int{1, 2}, itemName, fn, Options{Parallelism: 1})
+
+	if len(seen) != 2 || seen[0].Done != 0 || seen[1].Done != 1 || seen[0].Finished {
+		t.Fatalf("expected progress before each task, got %+v", seen)
+	}
+	task, ok := state.TaskByID(store.Snapshot().Tasks, "copy")
+	if !ok || !task.Finished || task.Done != 2 || len(task.Failures) != 1 || task.Failures[0] != (state.TaskFailure{Name: "node-2", Err: "timeout"}) {
+		t.Fatalf("unexpected final progress %+v", task)
+	}
+}
@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Track is Run reporting its progress to store as the bulk action id, for
+// the view that started it to render. The last report is marked Finished;
+// the entry stays until the view removes it.
+func Track[T any](ctx context.Context, store *state.Store, id, title string, items []T, name func(T) string, fn func(context.Context, T) error, opts Options) Result {
+	progress := opts.Progress
+	opts.Progress = func(r Result) {
+		store.SetTask(r.progress(id, title))
+		if progress != nil {
+			progress(r)
+		}
+	}
+	result := Run(ctx, items, name, fn, opts)
+	final := result.progress(id, title)
+	final.Finished = true
+	store.SetTask(final)
+	return result
+}
+
+func (r Result) progress(id, title string) state.TaskProgress {
+	task := state.TaskProgress{ID: id, Title: title, Total: r.Total, Done: r.Done, Skipped: r.Skipped}
+	for _, failure := range r.Failures {
+		task.Failures = append(task.Failures, state.TaskFailure{Name: failure.Name, Err: failure.Err.Error()})
+	}
+	return task
+}
@@ -0,0 +1,243 @@
+// Package testutil runs the daemon RPC server in memory and connects fake
+// daemons to it, so tests can drive the path from gRPC through the store to
+// the views the way opensnitchd would.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/daemon"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Timeout bounds every wait of the harness.
+const Timeout = 5 * time.Second
+
+const bufSize = 1 << 20
+
+// Harness is a daemon server listening on an in-memory connection.
+type Harness struct {
+	Store  *state.Store
+	Server *daemon.Server
+
+	lis *listener
+	// dialMu pairs each dial with the connection the server accepts.
+	dialMu sync.Mutex
+}
+
+// NewHarness starts a server on store; it stops when the test ends.
+func NewHarness(t testing.TB, store *state.Store, opts daemon.Options) *Harness {
+	t.Helper()
+	h := &Harness{
+		Store:  store,
+		Server: daemon.New(store, opts),
+		lis:    &listener{Listener: bufconn.Listen(bufSize), accepted: make(chan string, 1)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- h.Server.Serve(ctx, h.lis) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+	return h
+}
+
+// Dial connects a fake daemon called name. Like a real daemon it gets a
+// new peer address, and so a new node ID, on every connection.
+func (h *Harness) Dial(t testing.TB, name string) *FakeDaemon {
+	t.Helper()
+	d := &FakeDaemon{Name: name, harness: h, replies: make(chan *pb.Notification, 16)}
+	if err := d.connect(); err != nil {
+		t.Fatalf("dial %s: %v", name, err)
+	}
+	t.Cleanup(d.Close)
+	return d
+}
+
+// FakeDaemon speaks the UI service like opensnitchd: it subscribes, pings
+// and asks for rules, and answers every notification with OK.
+type FakeDaemon struct {
+	Name string
+	// NodeID is the ID the server keys this connection by.
+	NodeID string
+
+	harness *Harness
+	conn    *grpc.ClientConn
+	client  pb.UIClient
+	cancel  context.CancelFunc
+	// replies receives every notification once it has been answered.
+	replies chan *pb.Notification
+}
+
+func (d *FakeDaemon) connect() error {
+	h := d.harness
+	h.dialMu.Lock()
+	defer h.dialMu.Unlock()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return h.lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	conn.Connect()
+	for st := conn.GetState(); st != connectivity.Ready; st = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, st) {
+			conn.Close()
+			return fmt.Errorf("connection not ready: %s", st)
+		}
+	}
+	select {
+	case addr := <-h.lis.accepted:
+		d.NodeID = "tcp://" + addr
+	case <-ctx.Done():
+		conn.Close()
+		return ctx.Err()
+	}
+	d.conn = conn
+	d.client = pb.NewUIClient(conn)
+	return nil
+}
+
+// Subscribe announces the daemon with rules, as opensnitchd does on
+// connect.
+func (d *FakeDaemon) Subscribe(rules ...*pb.Rule) (*pb.ClientConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	return d.client.Subscribe(ctx, &pb.ClientConfig{Name: d.Name, Version: "1.6.0", Rules: rules})
+}
+
+// Ping reports stats.
+func (d *FakeDaemon) Ping(stats *pb.Statistics) error {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	_, err := d.client.Ping(ctx, &pb.PingRequest{Id: 1, Stats: stats})
+	return err
+}
+
+// AskRule asks the UI about conn and blocks until it answers.
+func (d *FakeDaemon) AskRule(ctx context.Context, conn *pb.Connection) (*pb.Rule, error) {
+	return d.client.AskRule(ctx, conn)
+}
+
+// OpenNotifications opens the notification stream and answers each
+// notification until the daemon is closed.
+func (d *FakeDaemon) OpenNotifications() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := d.client.Notifications(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	d.cancel = cancel
+	go func() {
+		for {
+			notif, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			if err := stream.Send(&pb.NotificationReply{Id: notif.GetId(), Code: pb.NotificationReplyCode_OK}); err != nil {
+				return
+			}
+			d.replies <- notif
+		}
+	}()
+	return nil
+}
+
+// NextNotification returns the next notification the daemon answered.
+func (d *FakeDaemon) NextNotification() (*pb.Notification, error) {
+	select {
+	case notif := <-d.replies:
+		return notif, nil
+	case <-time.After(Timeout):
+		return nil, fmt.Errorf("%s: no notification within %s", d.Name, Timeout)
+	}
+}
+
+// Reconnect drops the connection and dials again, as a restarted daemon
+// would; the caller subscribes again.
+func (d *FakeDaemon) Reconnect() error {
+	d.Close()
+	return d.connect()
+}
+
+// Close ends the notification stream and the connection.
+func (d *FakeDaemon) Close() {
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+}
+
+// WaitFor polls cond until it holds, failing the test after Timeout.
+func WaitFor(t testing.TB, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(Timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// listener numbers accepted connections with made-up TCP addresses, so
+// the server tells daemons apart the way it does on a real socket.
+type listener struct {
+	*bufconn.Listener
+	mu       sync.Mutex
+	next     int
+	accepted chan string
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.next++
+	addr := fmt.Sprintf("127.0.0.1:%d", 40000+l.next)
+	l.mu.Unlock()
+	select {
+	case l.accepted <- addr:
+	default:
+		// Nobody is dialing through the harness; do not stall the server.
+	}
+	return &peerConn{Conn: conn, remote: tcpAddr(addr)}, nil
+}
+
+type peerConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c *peerConn) RemoteAddr() net.Addr { return c.remote }
+
+type tcpAddr string
+
+func (a tcpAddr) Network() string { return "tcp" }
+func (a tcpAddr) String() string  { return string(a) }
@@ -0,0 +1,190 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/daemon"
+	pb "github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/prompt"
+)
+
+func newStore() *state.Store {
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.AlertsInterrupt = true
+	settings.DefaultPromptAction = string(controller.PromptActionDeny)
+	store.SetSettings(settings)
+	return store
+}
+
+func sshRule() *pb.Rule {
+	return &pb.Rule{
+		Name:     "ssh",
+		Enabled:  false,
+		Action:   "allow",
+		Duration: "always",
+		Operator: &pb.Operator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+	}
+}
+
+func nodeRule(store *state.Store, nodeID, name string) (state.Rule, bool) {
+	return ruleset.Lookup(store.Snapshot().Rules, nodeID, name)
+}
+
+func findNode(store *state.Store, id string) (state.Node, bool) {
+	for _, node := range store.Snapshot().Nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+	return state.Node{}, false
+}
+
+func TestPromptDecisionAnswersAskRule(t *testing.T) {
+	store := newStore()
+	h := NewHarness(t, store, daemon.Options{})
+	d := h.Dial(t, "laptop")
+	if _, err := d.Subscribe(); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	type answer struct {
+		rule *pb.Rule
+		err  error
+	}
+	answered := make(chan answer, 1)
+	go func() {
+		rule, err := d.AskRule(context.Background(), &pb.Connection{
+			Protocol:    "tcp",
+			DstIp:       "203.0.113.7",
+			DstHost:     "example.com",
+			DstPort:     443,
+			ProcessPath: "/usr/bin/curl",
+		})
+		answered <- answer{rule, err}
+	}()
+	WaitFor(t, "the prompt", func() bool { return len(store.Snapshot().Prompts) == 1 })
+	if conns := store.Snapshot().Connections; len(conns) != 1 || conns[0].Source != state.ConnectionFromPrompt || conns[0].Connection.DstHost != "example.com" {
+		t.Fatalf("expected the asked connection in the feed, got %+v", conns)
+	}
+
+	m := prompt.New(store, theme.New(theme.Options{}), h.Server)
+	m.SetSize(100, 30)
+	m.View()
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	got := <-answered
+	if got.err != nil {
+		t.Fatalf("AskRule: %v", got.err)
+	}
+	if got.rule.GetAction() != string(controller.PromptActionAllow) || got.rule.GetName() == "" || got.rule.GetOperator() == nil {
+		t.Fatalf("unexpected rule: %+v", got.rule)
+	}
+	decisions := store.Snapshot().Decisions
+	if len(decisions) != 1 || decisions[0].Source != state.DecisionSourceUser || decisions[0].NodeID != d.NodeID {
+		t.Fatalf("expected the user's decision to be recorded, got %+v", decisions)
+	}
+}
+
+func TestEnableRuleFlowsThroughNotifications(t *testing.T) {
+	store := newStore()
+	h := NewHarness(t, store, daemon.Options{})
+	d := h.Dial(t, "laptop")
+	if _, err := d.Subscribe(sshRule()); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := d.OpenNotifications(); err != nil {
+		t.Fatalf("notifications: %v", err)
+	}
+	rule, ok := nodeRule(store, d.NodeID, "ssh")
+	if !ok || rule.Enabled {
+		t.Fatalf("expected the subscribed rule to be stored disabled, got %+v", rule)
+	}
+	// The stream registers its session asynchronously.
+	WaitFor(t, "the notification session", func() bool {
+		return h.Server.EnableRule(d.NodeID, "ssh", ruleset.Hash(rule)) == nil
+	})
+
+	notif, err := d.NextNotification()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notif.GetType() != pb.Action_ENABLE_RULE || len(notif.GetRules()) != 1 || !notif.GetRules()[0].GetEnabled() {
+		t.Fatalf("unexpected notification: %+v", notif)
+	}
+	if rule, _ := nodeRule(store, d.NodeID, "ssh"); !rule.Enabled {
+		t.Fatal("expected the store to show the rule enabled")
+	}
+}
+
+func TestReconnectReplacesSession(t *testing.T) {
+	store := newStore()
+	h := NewHarness(t, store, daemon.Options{})
+	d := h.Dial(t, "laptop")
+	if _, err := d.Subscribe(sshRule()); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := d.OpenNotifications(); err != nil {
+		t.Fatalf("notifications: %v", err)
+	}
+	first := d.NodeID
+	// Let the first session register before replacing it, or the server may
+	// see the reconnect before the session it is meant to replace.
+	rule, _ := nodeRule(store, first, "ssh")
+	WaitFor(t, "the first notification session", func() bool {
+		return h.Server.EnableRule(first, "ssh", ruleset.Hash(rule)) == nil
+	})
+	if _, err := d.NextNotification(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Reconnect(); err != nil {
+		t.Fatalf("reconnect: %v", err)
+	}
+	if _, err := d.Subscribe(sshRule()); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := d.OpenNotifications(); err != nil {
+		t.Fatalf("notifications: %v", err)
+	}
+	WaitFor(t, "the old session to close", func() bool {
+		node, ok := findNode(store, first)
+		return ok && node.Status == state.NodeStatusDisconnected
+	})
+	rule, _ = nodeRule(store, d.NodeID, "ssh")
+	WaitFor(t, "the new notification session", func() bool {
+		return h.Server.DisableRule(d.NodeID, "ssh", ruleset.Hash(rule)) == nil
+	})
+	if _, err := d.NextNotification(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconnectPreservesNodeIdentity(t *testing.T) {
+	t.Skip("nodes are keyed by peer address, so a reconnecting daemon comes back as a new node")
+
+	store := newStore()
+	h := NewHarness(t, store, daemon.Options{})
+	d := h.Dial(t, "laptop")
+	if _, err := d.Subscribe(); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	first := d.NodeID
+	if err := d.Reconnect(); err != nil {
+		t.Fatalf("reconnect: %v", err)
+	}
+	if _, err := d.Subscribe(); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if d.NodeID != first || len(store.Snapshot().Nodes) != 1 {
+		t.Fatalf("expected %s to keep its identity, got %s and %d nodes", first, d.NodeID, len(store.Snapshot().Nodes))
+	}
+}
@@ -0,0 +1,66 @@
+package theme
+
+import (
+	"hash/fnv"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+)
+
+// darkAccents and lightAccents are the node accent palettes: eight hues
+// that read on the dark themes' backgrounds and on Dawn's respectively.
+var (
+	darkAccents = []lipgloss.Color{
+		"#60a5fa", "#f472b6", "#34d399", "#fbbf24",
+		"#a78bfa", "#fb923c", "#22d3ee", "#a3e635",
+	}
+	lightAccents = []lipgloss.Color{
+		"#1d4ed8", "#be185d", "#047857", "#b45309",
+		"#6d28d9", "#c2410c", "#0e7490", "#4d7c0f",
+	}
+)
+
+// Accent returns the accent color of the node with identity key, such as
+// state.NodeKey. The same key gets the same color in every view and
+// session.
+func (t Theme) Accent(key string) lipgloss.Color {
+	palette := darkAccents
+	if t.IsLight {
+		palette = lightAccents
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// AccentMark is a dot in the node's accent, set before its name.
+func (t Theme) AccentMark(key string) string {
+	return lipgloss.NewStyle().Foreground(t.Accent(key)).Render(glyphs.Current().Dot)
+}
+
+// NodeBadge renders label on the node's accent.
+func (t Theme) NodeBadge(key, label string) string {
+	text := lipgloss.Color("#0f1115")
+	if t.IsLight {
+		text = lipgloss.Color("#ffffff")
+	}
+	return lipgloss.NewStyle().Foreground(text).Background(t.Accent(key)).Bold(true).Padding(0, 1).Render(label)
+}
+
+// AccentUnderline draws a line in the node's accent under s.
+func (t Theme) AccentUnderline(key, s string) string {
+	return lipgloss.NewStyle().
+		BorderStyle(border(lipgloss.ThickBorder())).
+		BorderBottom(true).
+		BorderForeground(t.Accent(key)).
+		Render(s)
+}
+
+// border is b, or its ASCII rendering while the UI is limited to ASCII.
+func border(b lipgloss.Border) lipgloss.Border {
+	if glyphs.ASCIIOnly() {
+		return lipgloss.ASCIIBorder()
+	}
+	return b
+}
@@ -0,0 +1,29 @@
+package theme
+
+import (
+	"errors"
+	"os"
+
+	"github.com/muesli/termenv"
+)
+
+var errNoBackground = errors.New("terminal did not report its background color")
+
+// DetectLight queries the terminal background color (OSC 11) and reports
+// whether it is light. The query waits on the terminal, so callers inside a
+// running program must not make it from the render loop.
+func DetectLight() (bool, error) {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return false, errNoBackground
+	}
+	bg := termenv.NewOutput(os.Stdout).BackgroundColor()
+	if _, ok := bg.(termenv.NoColor); ok || bg == nil {
+		return false, errNoBackground
+	}
+	_, _, lightness := termenv.ConvertToRGB(bg).Hsl()
+	return lightness >= 0.5, nil
+}
@@ -9,6 +9,8 @@ import (
 // Options configure the active palette.
 type Options struct {
 	Name string
+	// Light picks Dawn over Midnight when Name is "auto".
+	Light bool
 }
 
 // Theme exposes reusable lipgloss styles for the UI.
@@ -58,6 +60,9 @@ func Normalize(name string) string {
 
 // Label returns the friendly label for a theme name.
 func Label(name string) string {
+	if config.NormalizeThemeName(name) == config.ThemeAuto {
+		return "Auto"
+	}
 	for _, preset := range presets {
 		if preset.Name == config.NormalizeThemeName(name) {
 			return preset.Label
@@ -69,6 +74,12 @@ func Label(name string) string {
 // New constructs a theme based on the provided preferences.
 func New(opts Options) Theme {
 	name := Normalize(opts.Name)
+	if name == config.ThemeAuto {
+		name = config.ThemeMidnight
+		if opts.Light {
+			name = config.ThemeDawn
+		}
+	}
 	switch name {
 	case config.ThemeCanopy:
 		return buildCanopy(name)
@@ -104,7 +115,7 @@ func buildMidnight(name string) Theme {
 		TabActive:      lipgloss.NewStyle().Foreground(bg).Background(primary).Padding(0, 2).Bold(true),
 		TabInactive:    lipgloss.NewStyle().Foreground(primary).Background(bg).Padding(0, 2),
 		Body:           body,
-		Card:           body.BorderStyle(lipgloss.NormalBorder()).BorderForeground(primary).Padding(1, 2).MarginRight(2),
+		Card:           body.BorderStyle(border(lipgloss.NormalBorder())).BorderForeground(primary).Padding(1, 2).MarginRight(2),
 		Success:        lipgloss.NewStyle().Foreground(lipgloss.Color("#4ade80")).Bold(true),
 		Warning:        lipgloss.NewStyle().Foreground(lipgloss.Color("#facc15")).Bold(true),
 		Danger:         lipgloss.NewStyle().Foreground(lipgloss.Color("#f87171")).Bold(true),
@@ -131,7 +142,7 @@ func buildCanopy(name string) Theme {
 		TabActive:      lipgloss.NewStyle().Foreground(bg).Background(primary).Padding(0, 2).Bold(true),
 		TabInactive:    lipgloss.NewStyle().Foreground(primary).Background(bg).Padding(0, 2),
 		Body:           body,
-		Card:           body.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(primary).Padding(1, 2).MarginRight(2),
+		Card:           body.BorderStyle(border(lipgloss.RoundedBorder())).BorderForeground(primary).Padding(1, 2).MarginRight(2),
 		Success:        lipgloss.NewStyle().Foreground(lipgloss.Color("#86efac")).Bold(true),
 		Warning:        lipgloss.NewStyle().Foreground(lipgloss.Color("#fde047")).Bold(true),
 		Danger:         lipgloss.NewStyle().Foreground(lipgloss.Color("#f97316")).Bold(true),
@@ -158,7 +169,7 @@ func buildDawn(name string) Theme {
 		TabActive:      lipgloss.NewStyle().Foreground(bg).Background(primary).Padding(0, 2).Bold(true),
 		TabInactive:    lipgloss.NewStyle().Foreground(primary).Background(bg).Padding(0, 2),
 		Body:           body,
-		Card:           body.BorderStyle(lipgloss.DoubleBorder()).BorderForeground(primary).Padding(1, 2).MarginRight(2),
+		Card:           body.BorderStyle(border(lipgloss.DoubleBorder())).BorderForeground(primary).Padding(1, 2).MarginRight(2),
 		Success:        lipgloss.NewStyle().Foreground(lipgloss.Color("#15803d")).Bold(true),
 		Warning:        lipgloss.NewStyle().Foreground(lipgloss.Color("#b45309")).Bold(true),
 		Danger:         lipgloss.NewStyle().Foreground(lipgloss.Color("#b91c1c")).Bold(true),
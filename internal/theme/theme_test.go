@@ -43,6 +43,15 @@ func TestNewDefaultToMidnight(t *testing.T) {
 	}
 }
 
+func TestNewAutoFollowsBackground(t *testing.T) {
+	if th := New(Options{Name: config.ThemeAuto}); th.Name != config.ThemeMidnight {
+		t.Fatalf("expected dark background to pick Midnight, got %q", th.Name)
+	}
+	if th := New(Options{Name: config.ThemeAuto, Light: true}); th.Name != config.ThemeDawn || !th.IsLight {
+		t.Fatalf("expected light background to pick Dawn, got %q", th.Name)
+	}
+}
+
 func TestNormalize(t *testing.T) {
 	cases := []struct {
 		input    string
@@ -53,6 +62,7 @@ func TestNormalize(t *testing.T) {
 		{"canopy", config.ThemeCanopy},
 		{" Dawn ", config.ThemeDawn},
 		{"unknown", config.ThemeMidnight},
+		{" Auto ", config.ThemeAuto},
 	}
 	for _, tc := range cases {
 		got := Normalize(tc.input)
@@ -109,3 +119,18 @@ func TestRenderTab(t *testing.T) {
 		t.Fatal("expected tab content to be present")
 	}
 }
+
+func TestAccentIsStablePerNode(t *testing.T) {
+	th := New(Options{Name: config.ThemeMidnight})
+	laptop, server := th.Accent("name:laptop"), th.Accent("name:server")
+	if laptop == server {
+		t.Fatalf("expected laptop and server to get different accents, both got %s", laptop)
+	}
+	again := New(Options{Name: config.ThemeCanopy})
+	if got := again.Accent("name:laptop"); got != laptop {
+		t.Fatalf("expected the same accent for the same node, got %s and %s", laptop, got)
+	}
+	if dawn := New(Options{Name: config.ThemeDawn}).Accent("name:laptop"); dawn == laptop {
+		t.Fatalf("expected Dawn to use its own accent palette, got %s", dawn)
+	}
+}
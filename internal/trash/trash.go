@@ -0,0 +1,88 @@
+// Package trash keeps rules deleted from daemons on disk for a while, so
+// they can be restored in a later session.
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// Retention is how long a deleted rule is kept before Prune drops it.
+const Retention = 7 * 24 * time.Hour
+
+const fileVersion = 1
+
+type file struct {
+	Version int                 `json:"version"`
+	Entries []state.TrashedRule `json:"entries"`
+}
+
+// File stores the trash as one JSON file.
+type File struct {
+	path string
+}
+
+// New returns a trash kept at path.
+func New(path string) *File {
+	return &File{path: path}
+}
+
+// DefaultPath returns the trash file under XDG_CACHE_HOME (~/.cache).
+func DefaultPath() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "opensnitch-tui", "trash.json"), nil
+}
+
+// Load reads the trash; a missing file is an empty trash.
+func (f *File) Load() ([]state.TrashedRule, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read trash: %w", err)
+	}
+	var doc file
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("read trash: %w", err)
+	}
+	if doc.Version != fileVersion {
+		return nil, fmt.Errorf("read trash: unsupported version %d", doc.Version)
+	}
+	return doc.Entries, nil
+}
+
+// Document is the trash file holding entries, for a persist.Writer.
+func (f *File) Document(entries []state.TrashedRule) persist.Document {
+	return persist.Document{Name: "trash", Path: f.path, Marshal: func() ([]byte, error) {
+		return json.MarshalIndent(file{Version: fileVersion, Entries: entries}, "", "  ")
+	}}
+}
+
+// Save replaces the trash with entries now.
+func (f *File) Save(entries []state.TrashedRule) error {
+	return persist.Write(f.Document(entries))
+}
+
+// Prune drops entries deleted more than Retention before now and reports
+// how many went.
+func Prune(entries []state.TrashedRule, now time.Time) ([]state.TrashedRule, int) {
+	cutoff := now.Add(-Retention)
+	kept := make([]state.TrashedRule, 0, len(entries))
+	for _, entry := range entries {
+		if entry.DeletedAt.After(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, len(entries) - len(kept)
+}
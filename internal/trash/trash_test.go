@@ -0,0 +1,67 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	f := New(filepath.Join(t.TempDir(), "nested", "trash.json"))
+	if entries, err := f.Load(); err != nil || entries != nil {
+		t.Fatalf("expected a missing file to be an empty trash, got %+v, %v", entries, err)
+	}
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	want := []state.TrashedRule{{
+		NodeKey:   "name:laptop",
+		NodeName:  "laptop",
+		DeletedAt: deletedAt,
+		Rule: state.Rule{
+			Name:   "ssh",
+			Action: "allow",
+			Operator: state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+				{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"},
+				{Type: "simple", Operand: "dest.port", Data: "22"},
+			}},
+		},
+	}}
+	if err := f.Save(want); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	got, err := f.Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(got) != 1 || got[0].NodeKey != "name:laptop" || got[0].Rule.Name != "ssh" || !got[0].DeletedAt.Equal(deletedAt) {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+	if children := got[0].Rule.Operator.Children; len(children) != 2 || children[1].Data != "22" {
+		t.Fatalf("operator not kept: %+v", got[0].Rule.Operator)
+	}
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trash.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "entries": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(path).Load(); err == nil {
+		t.Fatal("expected an error for an unknown version")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	entries := []state.TrashedRule{
+		{Rule: state.Rule{Name: "fresh"}, DeletedAt: now.Add(-time.Hour)},
+		{Rule: state.Rule{Name: "old"}, DeletedAt: now.Add(-Retention - time.Minute)},
+		{Rule: state.Rule{Name: "edge"}, DeletedAt: now.Add(-Retention + time.Minute)},
+	}
+	kept, pruned := Prune(entries, now)
+	if pruned != 1 || len(kept) != 2 || kept[0].Rule.Name != "fresh" || kept[1].Rule.Name != "edge" {
+		t.Fatalf("unexpected prune: %+v, %d", kept, pruned)
+	}
+}
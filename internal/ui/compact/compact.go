@@ -0,0 +1,367 @@
+// Package compact shows the pending prompts of a running instance in three
+// lines, for a small terminal dedicated to answering them. Prompts are
+// fetched and answered over the primary instance's control socket.
+package compact
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/control"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/util/netnames"
+)
+
+// pollInterval is the pause between fetches of the pending prompts; the
+// control protocol has no push.
+const pollInterval = time.Second
+
+const helpLine = "←/→ or n/p change · ↑/↓ or tab field · enter send · a/d/r answer · q quit"
+
+// Defaults are the choices preselected for each new prompt.
+type Defaults struct {
+	Action   string
+	Duration string
+	Target   string
+}
+
+var actions = []widget.Option{
+	{Label: "Allow", Value: string(controller.PromptActionAllow)},
+	{Label: "Deny", Value: string(controller.PromptActionDeny)},
+	{Label: "Reject", Value: string(controller.PromptActionReject)},
+}
+
+var durations = []widget.Option{
+	{Label: "Once", Value: string(controller.PromptDurationOnce)},
+	{Label: "Until restart", Value: string(controller.PromptDurationUntilRestart)},
+	{Label: "Always", Value: string(controller.PromptDurationAlways)},
+}
+
+// field is the choice ←/→ change.
+type field int
+
+const (
+	fieldAction field = iota
+	fieldDuration
+	fieldTarget
+	fieldCount
+)
+
+// Model is the compact prompt-only program.
+type Model struct {
+	src      control.PromptSource
+	theme    theme.Theme
+	defaults Defaults
+	now      func() time.Time
+	width    int
+
+	pending []control.PromptInfo
+	// current is the ID of the prompt on screen; it stays selected while
+	// it is pending so a poll does not reset the choices.
+	current     string
+	targets     []widget.Option
+	focus       field
+	actionIdx   int
+	durationIdx int
+	targetIdx   int
+	status      string
+	err         error
+	// sending is set while a decision is on its way, so a second key
+	// press does not answer the prompt twice.
+	sending bool
+	// answered holds the prompts decided here that a poll started before
+	// the decision may still list.
+	answered map[string]bool
+}
+
+type tickMsg struct{}
+
+// pendingMsg carries the result of a poll.
+type pendingMsg struct {
+	prompts []control.PromptInfo
+	err     error
+}
+
+// resolvedMsg carries the outcome of a decision sent to the primary
+// instance.
+type resolvedMsg struct {
+	prompt   control.PromptInfo
+	decision controller.PromptDecision
+	err      error
+}
+
+// New builds the compact model answering prompts from src.
+func New(src control.PromptSource, th theme.Theme, defaults Defaults) *Model {
+	return &Model{src: src, theme: th, defaults: defaults, now: time.Now, answered: make(map[string]bool)}
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(pollInterval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// poll fetches the pending prompts off the UI goroutine. The next poll is
+// scheduled once this one is back, so a slow primary instance never has
+// more than one request queued.
+func (m *Model) poll() tea.Cmd {
+	src := m.src
+	return func() tea.Msg {
+		prompts, err := src.Pending()
+		return pendingMsg{prompts: prompts, err: err}
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.poll()
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tickMsg:
+		return m, m.poll()
+	case pendingMsg:
+		m.refresh(msg.prompts, msg.err)
+		return m, tick()
+	case resolvedMsg:
+		m.resolved(msg)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		}
+		if m.current == "" || m.sending {
+			return m, nil
+		}
+		m.status = ""
+		switch msg.String() {
+		case "left", "p":
+			m.shift(-1)
+		case "right", "n":
+			m.shift(1)
+		case "up", "shift+tab":
+			m.focus = field(util.WrapIndex(int(m.focus), -1, int(fieldCount)))
+		case "down", "tab":
+			m.focus = field(util.WrapIndex(int(m.focus), 1, int(fieldCount)))
+		case "enter":
+			return m, m.resolve(actions[m.actionIdx].Value)
+		case "a":
+			return m, m.resolve(string(controller.PromptActionAllow))
+		case "d":
+			return m, m.resolve(string(controller.PromptActionDeny))
+		case "r":
+			return m, m.resolve(string(controller.PromptActionReject))
+		}
+	}
+	return m, nil
+}
+
+// refresh applies a poll, keeping the shown prompt selected while it is
+// still pending.
+func (m *Model) refresh(prompts []control.PromptInfo, err error) {
+	m.err = err
+	if err != nil {
+		return
+	}
+	listed := make(map[string]bool, len(prompts))
+	pending := prompts[:0:0]
+	for _, p := range prompts {
+		listed[p.ID] = true
+		if !m.answered[p.ID] {
+			pending = append(pending, p)
+		}
+	}
+	for id := range m.answered {
+		if !listed[id] {
+			delete(m.answered, id)
+		}
+	}
+	m.setPending(pending)
+}
+
+// setPending replaces the pending prompts and shows the first one unless
+// the shown one is among them.
+func (m *Model) setPending(prompts []control.PromptInfo) {
+	m.pending = prompts
+	for _, p := range prompts {
+		if p.ID == m.current {
+			return
+		}
+	}
+	m.current = ""
+	if len(prompts) == 0 {
+		return
+	}
+	m.show(prompts[0])
+}
+
+// show selects p with the default choices.
+func (m *Model) show(p control.PromptInfo) {
+	m.current = p.ID
+	m.targets = targetOptions(p)
+	m.focus = fieldAction
+	m.actionIdx = widget.IndexOf(actions, m.defaults.Action)
+	m.durationIdx = widget.IndexOf(durations, m.defaults.Duration)
+	m.targetIdx = widget.IndexOf(m.targets, m.defaults.Target)
+}
+
+func (m *Model) shift(delta int) {
+	switch m.focus {
+	case fieldAction:
+		m.actionIdx = util.WrapIndex(m.actionIdx, delta, len(actions))
+	case fieldDuration:
+		m.durationIdx = util.WrapIndex(m.durationIdx, delta, len(durations))
+	case fieldTarget:
+		m.targetIdx = util.WrapIndex(m.targetIdx, delta, len(m.targets))
+	}
+}
+
+// resolve sends the shown prompt's decision to the primary instance.
+func (m *Model) resolve(action string) tea.Cmd {
+	p, ok := m.shown()
+	if !ok {
+		return nil
+	}
+	decision := controller.PromptDecision{
+		PromptID: p.ID,
+		Action:   controller.PromptAction(action),
+		Duration: controller.PromptDuration(durations[m.durationIdx].Value),
+		Target:   controller.PromptTarget(m.targets[m.targetIdx].Value),
+	}
+	m.sending = true
+	src := m.src
+	return func() tea.Msg {
+		return resolvedMsg{prompt: p, decision: decision, err: src.Resolve(decision)}
+	}
+}
+
+// resolved reports a sent decision and moves on to the next prompt; the
+// next poll confirms the list.
+func (m *Model) resolved(msg resolvedMsg) {
+	m.sending = false
+	if msg.err != nil {
+		m.status = m.theme.Danger.Render(fmt.Sprintf("%s: %v", msg.prompt.Destination(), msg.err))
+		return
+	}
+	m.status = m.theme.Success.Render(fmt.Sprintf("%s %s (%s)", msg.decision.Action, msg.prompt.Destination(), msg.decision.Duration))
+	m.answered[msg.prompt.ID] = true
+	pending := make([]control.PromptInfo, 0, len(m.pending))
+	for _, p := range m.pending {
+		if p.ID != msg.prompt.ID {
+			pending = append(pending, p)
+		}
+	}
+	m.setPending(pending)
+}
+
+func (m *Model) shown() (control.PromptInfo, bool) {
+	for _, p := range m.pending {
+		if p.ID == m.current {
+			return p, true
+		}
+	}
+	return control.PromptInfo{}, false
+}
+
+// View renders the connection, the choices and a status line.
+func (m *Model) View() string {
+	width := m.width
+	if width <= 0 {
+		width = 100
+	}
+	p, ok := m.shown()
+	lines := make([]string, 3)
+	if ok {
+		lines[0] = m.connectionLine(p, width)
+		lines[1] = m.choiceLine()
+	} else {
+		lines[0] = m.theme.Subtle.Render("no pending prompts")
+	}
+	lines[2] = m.statusLine(ok, width)
+	return glyphs.Text(strings.Join(lines, "\n"))
+}
+
+func (m *Model) connectionLine(p control.PromptInfo, width int) string {
+	process := util.Fallback(p.ProcessPath, "unknown process")
+	var line string
+	if p.Inbound {
+		line = fmt.Sprintf("%s ← %s %s", process, util.Fallback(p.SrcIP, "unknown"), netnames.Protocol(p.Protocol))
+	} else {
+		line = fmt.Sprintf("%s → %s %s", process, p.Destination(), netnames.Protocol(p.Protocol))
+	}
+	if p.NodeName != "" {
+		line += " · " + p.NodeName
+	}
+	if !p.ExpiresAt.IsZero() {
+		line += fmt.Sprintf(" · %ds", max(0, int(p.ExpiresAt.Sub(m.now()).Seconds())))
+	}
+	return m.theme.Header.Render(util.TruncateString(line, width))
+}
+
+func (m *Model) choiceLine() string {
+	choices := []struct {
+		field field
+		label string
+		value string
+	}{
+		{fieldAction, "Action", actions[m.actionIdx].Label},
+		{fieldDuration, "Duration", durations[m.durationIdx].Label},
+		{fieldTarget, "Target", m.targets[m.targetIdx].Label},
+	}
+	parts := make([]string, len(choices))
+	for i, c := range choices {
+		value := c.value
+		if c.field == m.focus {
+			value = m.theme.Warning.Render("‹" + value + "›")
+		}
+		parts[i] = c.label + " " + value
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (m *Model) statusLine(prompting bool, width int) string {
+	if m.err != nil {
+		return m.theme.Danger.Render(util.TruncateString(fmt.Sprintf("primary instance: %v", m.err), width))
+	}
+	if m.status != "" {
+		return m.status
+	}
+	help := "q quit"
+	if prompting {
+		help = helpLine
+		if more := len(m.pending) - 1; more > 0 {
+			help = fmt.Sprintf("%d more · %s", more, help)
+		}
+	}
+	return m.theme.Subtle.Render(util.TruncateString(help, width))
+}
+
+// targetOptions lists the rule targets the prompt carries data for. Inbound
+// prompts are scoped by the remote peer or the local port instead of the
+// destination.
+func targetOptions(p control.PromptInfo) []widget.Option {
+	var opts []widget.Option
+	if p.Inbound {
+		if p.SrcIP != "" {
+			opts = append(opts, widget.Option{Label: "Source IP", Value: string(controller.PromptTargetSourceIP)})
+		}
+		return append(opts, widget.Option{Label: "Local port", Value: string(controller.PromptTargetDestinationPort)})
+	}
+	if p.ProcessPath != "" {
+		opts = append(opts, widget.Option{Label: "Executable", Value: string(controller.PromptTargetProcessPath)})
+	}
+	if p.DstHost != "" {
+		opts = append(opts, widget.Option{Label: "Destination host", Value: string(controller.PromptTargetDestinationHost)})
+	}
+	if p.DstIP != "" {
+		opts = append(opts, widget.Option{Label: "Destination IP", Value: string(controller.PromptTargetDestinationIP)})
+	}
+	return append(opts, widget.Option{Label: "Destination port", Value: string(controller.PromptTargetDestinationPort)})
+}
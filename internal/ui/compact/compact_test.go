@@ -0,0 +1,204 @@
+package compact
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/control"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// fakeSource serves canned prompts and drops the ones it is told to resolve.
+type fakeSource struct {
+	prompts   []control.PromptInfo
+	decisions []controller.PromptDecision
+	err       error
+}
+
+func (f *fakeSource) Pending() ([]control.PromptInfo, error) { return f.prompts, f.err }
+
+func (f *fakeSource) Resolve(decision controller.PromptDecision) error {
+	f.decisions = append(f.decisions, decision)
+	for i, p := range f.prompts {
+		if p.ID == decision.PromptID {
+			f.prompts = append(f.prompts[:i:i], f.prompts[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+var now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newModel(src *fakeSource) *Model {
+	m := New(src, theme.New(theme.Options{}), Defaults{Action: "deny", Duration: "once", Target: "dest.host"})
+	m.now = func() time.Time { return now }
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 8})
+	run(m, m.Init())
+	return m
+}
+
+// send delivers msg and feeds back the result of the command it returns,
+// as the program would; the poll that follows is left unscheduled.
+func send(m *Model, msg tea.Msg) {
+	_, cmd := m.Update(msg)
+	run(m, cmd)
+}
+
+func run(m *Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if msg := cmd(); msg != nil {
+		m.Update(msg)
+	}
+}
+
+func TestCompactRendersThreeLines(t *testing.T) {
+	m := newModel(&fakeSource{prompts: []control.PromptInfo{
+		{ID: "p1", NodeName: "laptop", ProcessPath: "/usr/bin/curl", DstHost: "api.github.com", DstIP: "140.82.112.6", DstPort: 443, Protocol: "tcp", ExpiresAt: now.Add(25 * time.Second)},
+		{ID: "p2", ProcessPath: "/usr/bin/wget", DstIP: "1.1.1.1", DstPort: 80},
+	}})
+
+	lines := strings.Split(m.View(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected three lines, got %d: %q", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], "/usr/bin/curl → api.github.com:443 tcp · laptop · 25s") {
+		t.Fatalf("unexpected connection line %q", lines[0])
+	}
+	for _, want := range []string{"‹Deny›", "Duration Once", "Target Destination host"} {
+		if !strings.Contains(lines[1], want) {
+			t.Fatalf("expected %q with the defaults preselected, got %q", want, lines[1])
+		}
+	}
+	if !strings.Contains(lines[2], "1 more") || !strings.Contains(lines[2], "enter send") {
+		t.Fatalf("unexpected status line %q", lines[2])
+	}
+}
+
+func TestCompactIdleAndUnreachable(t *testing.T) {
+	src := &fakeSource{}
+	m := newModel(src)
+	if out := m.View(); !strings.Contains(out, "no pending prompts") || len(strings.Split(out, "\n")) != 3 {
+		t.Fatalf("expected the idle text in three lines, got %q", out)
+	}
+
+	src.err = errors.New("control socket closed")
+	send(m, tickMsg{})
+	if out := m.View(); !strings.Contains(out, "primary instance: control socket closed") {
+		t.Fatalf("expected the connection error, got %q", out)
+	}
+}
+
+func TestCompactSendsChosenDecision(t *testing.T) {
+	src := &fakeSource{prompts: []control.PromptInfo{
+		{ID: "p1", ProcessPath: "/usr/bin/curl", DstHost: "api.github.com", DstIP: "140.82.112.6", DstPort: 443},
+		{ID: "p2", ProcessPath: "/usr/bin/wget", DstIP: "1.1.1.1", DstPort: 80},
+	}}
+	m := newModel(src)
+
+	send(m, tea.KeyMsg{Type: tea.KeyLeft}) // deny -> allow
+	send(m, tea.KeyMsg{Type: tea.KeyDown})
+	send(m, tea.KeyMsg{Type: tea.KeyRight}) // once -> until restart
+	send(m, tea.KeyMsg{Type: tea.KeyDown})
+	send(m, tea.KeyMsg{Type: tea.KeyRight}) // dest.host -> dest.ip
+	send(m, tickMsg{})                      // a poll keeps the choices
+	send(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	want := controller.PromptDecision{PromptID: "p1", Action: "allow", Duration: "until restart", Target: "dest.ip"}
+	if len(src.decisions) != 1 || src.decisions[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, src.decisions)
+	}
+	out := m.View()
+	if !strings.Contains(out, "/usr/bin/wget → 1.1.1.1:80") || !strings.Contains(out, "allow api.github.com:443 (until restart)") {
+		t.Fatalf("expected the next prompt and the sent decision, got %q", out)
+	}
+
+	send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if len(src.decisions) != 2 || src.decisions[1].Action != controller.PromptActionReject || src.decisions[1].Target != "process.path" {
+		t.Fatalf("expected a quick reject on the executable, as wget has no host for the default target, got %+v", src.decisions)
+	}
+}
+
+func TestCompactChoosesWithoutArrowKeys(t *testing.T) {
+	src := &fakeSource{prompts: []control.PromptInfo{
+		{ID: "p1", ProcessPath: "/usr/bin/curl", DstHost: "api.github.com", DstIP: "140.82.112.6", DstPort: 443},
+	}}
+	m := newModel(src)
+
+	send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}}) // deny -> allow
+	send(m, tea.KeyMsg{Type: tea.KeyTab})
+	send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}) // once -> until restart
+	send(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	want := controller.PromptDecision{PromptID: "p1", Action: "allow", Duration: "until restart", Target: "dest.host"}
+	if len(src.decisions) != 1 || src.decisions[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, src.decisions)
+	}
+}
+
+// blockingSource holds every call until release is closed.
+type blockingSource struct {
+	fakeSource
+	release chan struct{}
+}
+
+func (b *blockingSource) Pending() ([]control.PromptInfo, error) {
+	<-b.release
+	return b.fakeSource.Pending()
+}
+
+func (b *blockingSource) Resolve(decision controller.PromptDecision) error {
+	<-b.release
+	return b.fakeSource.Resolve(decision)
+}
+
+func TestCompactCallsTheInstanceOffTheUpdateLoop(t *testing.T) {
+	src := &blockingSource{
+		fakeSource: fakeSource{prompts: []control.PromptInfo{{ID: "p1", DstIP: "1.1.1.1", DstPort: 53}}},
+		release:    make(chan struct{}),
+	}
+	m := New(src, theme.New(theme.Options{}), Defaults{})
+	poll := m.Init()
+	if _, cmd := m.Update(tickMsg{}); cmd == nil {
+		t.Fatalf("expected the tick to return a poll")
+	}
+	close(src.release)
+	run(m, poll)
+
+	_, resolve := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if resolve == nil || len(src.decisions) != 0 {
+		t.Fatalf("expected the decision sent from a command, got %+v", src.decisions)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}); cmd != nil {
+		t.Fatalf("expected no second decision while one is on its way")
+	}
+	run(m, resolve)
+	if len(src.decisions) != 1 || src.decisions[0].Action != controller.PromptActionAllow {
+		t.Fatalf("expected the allow sent once, got %+v", src.decisions)
+	}
+}
+
+func TestCompactIgnoresAnsweredPromptsInStalePolls(t *testing.T) {
+	p1 := control.PromptInfo{ID: "p1", DstIP: "1.1.1.1", DstPort: 53}
+	p2 := control.PromptInfo{ID: "p2", DstIP: "8.8.8.8", DstPort: 53}
+	src := &fakeSource{prompts: []control.PromptInfo{p1, p2}}
+	m := newModel(src)
+	send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+
+	// A poll sent before the decision arrived still lists p1.
+	m.Update(pendingMsg{prompts: []control.PromptInfo{p1, p2}})
+	if out := m.View(); !strings.Contains(out, "8.8.8.8:53") {
+		t.Fatalf("expected the answered prompt skipped, got %q", out)
+	}
+	send(m, tickMsg{})
+	if len(m.answered) != 0 {
+		t.Fatalf("expected answered prompts forgotten once the instance drops them, got %v", m.answered)
+	}
+}
@@ -1,8 +1,11 @@
 package table
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
@@ -17,7 +20,8 @@ func ComputeMaxWidth(rows []string) int {
 	return maxWidth
 }
 
-// ClipRows slices each row horizontally using ANSI-safe slicing.
+// ClipRows slices each row horizontally using ANSI-safe slicing; colours
+// and hyperlinks cut by either edge are reopened and closed within the row.
 func ClipRows(rows []string, xOffset, width int) []string {
 	if width <= 0 {
 		width = 1
@@ -34,17 +38,17 @@ func RenderCaretRow(width int, style lipgloss.Style) string {
 	if width <= 0 {
 		width = 3
 	}
-	glyphs := make([]rune, width)
-	for i := range glyphs {
-		glyphs[i] = ' '
+	cells := make([]string, width)
+	for i := range cells {
+		cells[i] = " "
 	}
 	positions := []int{0, width / 2, max(0, width-1)}
 	for _, pos := range positions {
 		if pos >= 0 && pos < width {
-			glyphs[pos] = 'v'
+			cells[pos] = glyphs.Current().Caret
 		}
 	}
-	return style.Render(string(glyphs))
+	return style.Render(strings.Join(cells, ""))
 }
 
 // PadAndStyle truncates/pads text and renders it with the given style.
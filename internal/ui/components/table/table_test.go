@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 )
 
 func TestComputeMaxWidth(t *testing.T) {
@@ -28,6 +30,16 @@ func TestRenderCaretRow(t *testing.T) {
 	}
 }
 
+func TestRenderCaretRowASCII(t *testing.T) {
+	for _, ascii := range []bool{false, true} {
+		glyphs.SetASCII(ascii)
+		if row := RenderCaretRow(5, lipgloss.NewStyle()); row != "v v v" {
+			t.Fatalf("ascii=%v: expected carets at the ends and middle, got %q", ascii, row)
+		}
+	}
+	glyphs.SetASCII(false)
+}
+
 func TestPadAndStyle(t *testing.T) {
 	st := lipgloss.NewStyle()
 	res := PadAndStyle(st, "abc", 5, false)
@@ -35,3 +47,16 @@ func TestPadAndStyle(t *testing.T) {
 		t.Fatalf("expected padded width >=5, got %d", len([]rune(res)))
 	}
 }
+
+func TestClipRowsKeepsHyperlinksClosed(t *testing.T) {
+	open := "\x1b]8;;https://example.com\x1b\\"
+	end := "\x1b]8;;\x1b\\"
+	rows := []string{"ab " + open + "example" + end, open + "x" + end + "yz"}
+	clipped := ClipRows(rows, 4, 3)
+	if want := open + "xam" + end; clipped[0] != want {
+		t.Fatalf("expected %q, got %q", want, clipped[0])
+	}
+	if clipped[1] != "" {
+		t.Fatalf("expected nothing past a short row, got %q", clipped[1])
+	}
+}
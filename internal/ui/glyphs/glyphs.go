@@ -0,0 +1,133 @@
+// Package glyphs holds the non-ASCII characters the UI draws with, and
+// ASCII stand-ins for terminals that cannot show them, such as the Linux
+// console or a serial line without a UTF-8 locale.
+package glyphs
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// Set is the glyphs of one mode. Each ASCII stand-in is as wide as the
+// glyph it replaces, so layouts hold in both modes.
+type Set struct {
+	// Branch and Last lead a tree child, the last one of its parent
+	// taking Last; Pipe continues a branch past a child's descendants.
+	// Line extends a connector towards the child.
+	Branch, Last, Pipe, Line string
+	// Bar is one filled cell of a bar chart.
+	Bar string
+	// Caret marks a table that continues below the visible rows.
+	Caret string
+	// Dot marks a node accent, a selection or a state.
+	Dot     string
+	Warning string
+	// Collapsed and Expanded lead a foldable section heading.
+	Collapsed, Expanded string
+	// And and Or join the operands of list operators.
+	And, Or string
+}
+
+// Unicode is the default set.
+var Unicode = Set{
+	Branch: "├", Last: "└", Pipe: "│", Line: "─",
+	Bar:       "█",
+	Caret:     "v",
+	Dot:       "●",
+	Warning:   "⚠",
+	Collapsed: "▸", Expanded: "▾",
+	And: "∧", Or: "∨",
+}
+
+// ASCII is the set used when UTF-8 cannot be shown.
+var ASCII = Set{
+	Branch: "|", Last: "`", Pipe: "|", Line: "-",
+	Bar:       "#",
+	Caret:     "v",
+	Dot:       "*",
+	Warning:   "!",
+	Collapsed: "+", Expanded: "-",
+	And: "&", Or: "|",
+}
+
+var asciiOnly atomic.Bool
+
+// SetASCII switches every later render to the ASCII set.
+func SetASCII(on bool) { asciiOnly.Store(on) }
+
+// ASCIIOnly reports whether the ASCII set is in use.
+func ASCIIOnly() bool { return asciiOnly.Load() }
+
+// Current returns the set in use.
+func Current() Set {
+	if asciiOnly.Load() {
+		return ASCII
+	}
+	return Unicode
+}
+
+// UTF8Locale reports whether the locale in getenv's environment is UTF-8,
+// going by LC_ALL, LC_CTYPE and LANG in that order. An unset locale is the
+// C locale, which is not.
+func UTF8Locale(getenv func(string) string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
+
+// Resolve decides the mode from the ascii_only setting, nil leaving it to
+// the locale.
+func Resolve(override *bool, getenv func(string) string) bool {
+	if override != nil {
+		return *override
+	}
+	return !UTF8Locale(getenv)
+}
+
+// fallbacks replaces the glyphs found in text, such as help lines and
+// borders, with one ASCII character each.
+var fallbacks = strings.NewReplacer(
+	"·", "|", "•", "*", "●", "*", "○", "o", "…", "~", "—", "-", "–", "-",
+	"↑", "^", "↓", "v", "←", "<", "→", ">", "↳", ">", "›", ">", "‹", "<",
+	"─", "-", "━", "-", "│", "|", "┃", "|", "├", "|", "└", "`", "┌", "+", "┐", "+", "┘", "+", "┬", "+", "┴", "+", "┼", "+", "┤", "|",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+", "═", "=", "║", "|", "╔", "+", "╗", "+", "╚", "+", "╝", "+",
+	"█", "#", "▌", "#", "▐", "#", "▀", "-", "▄", "_", "░", ".", "▒", ":", "▓", "#",
+	"▸", "+", "▾", "-", "▲", "^", "▼", "v", "⚠", "!", "✓", "v", "✗", "x",
+	"≈", "~", "×", "x", "∧", "&", "∨", "|", "␡", "?", "🔧", "*",
+)
+
+// Text returns s unchanged in Unicode mode. In ASCII mode known glyphs
+// become their stand-ins and any other non-ASCII character a "?", so
+// nothing multi-byte reaches the terminal.
+func Text(s string) string {
+	if !asciiOnly.Load() || isASCII(s) {
+		return s
+	}
+	s = fallbacks.Replace(s)
+	if isASCII(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			r = '?'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package glyphs
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestUTF8Locale(t *testing.T) {
+	cases := []struct {
+		name string
+		vars map[string]string
+		want bool
+	}{
+		{"lang", map[string]string{"LANG": "en_US.UTF-8"}, true},
+		{"lowercase", map[string]string{"LANG": "C.utf8"}, true},
+		{"lc_all wins", map[string]string{"LC_ALL": "C", "LANG": "en_US.UTF-8"}, false},
+		{"lc_ctype before lang", map[string]string{"LC_CTYPE": "en_US.UTF-8", "LANG": "POSIX"}, true},
+		{"unset", nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := UTF8Locale(env(tc.vars)); got != tc.want {
+				t.Fatalf("UTF8Locale = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolvePrefersOverride(t *testing.T) {
+	on, off := true, false
+	utf8Env := env(map[string]string{"LANG": "en_US.UTF-8"})
+	if !Resolve(&on, utf8Env) || Resolve(&off, env(nil)) {
+		t.Fatal("expected ascii_only to override the locale")
+	}
+	if Resolve(nil, utf8Env) || !Resolve(nil, env(nil)) {
+		t.Fatal("expected the locale to decide without ascii_only")
+	}
+}
+
+func TestSetsHaveSameWidths(t *testing.T) {
+	pairs := [][2]string{
+		{Unicode.Branch, ASCII.Branch}, {Unicode.Last, ASCII.Last}, {Unicode.Pipe, ASCII.Pipe}, {Unicode.Line, ASCII.Line},
+		{Unicode.Bar, ASCII.Bar}, {Unicode.Caret, ASCII.Caret}, {Unicode.Dot, ASCII.Dot}, {Unicode.Warning, ASCII.Warning},
+		{Unicode.Collapsed, ASCII.Collapsed}, {Unicode.Expanded, ASCII.Expanded}, {Unicode.And, ASCII.And}, {Unicode.Or, ASCII.Or},
+	}
+	for _, pair := range pairs {
+		if utf8.RuneCountInString(pair[0]) != len(pair[1]) || !isASCII(pair[1]) {
+			t.Fatalf("expected %q to stand in for %q at the same width", pair[1], pair[0])
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	in := "↑/↓ move · ╭─╮ 🔧 naïve…"
+	if got := Text(in); got != in {
+		t.Fatalf("expected Unicode mode to leave text alone, got %q", got)
+	}
+
+	SetASCII(true)
+	t.Cleanup(func() { SetASCII(false) })
+	if Current() != ASCII {
+		t.Fatal("expected the ASCII set in use")
+	}
+	got := Text(in)
+	if want := "^/v move | +-+ * na?ve~"; got != want {
+		t.Fatalf("Text = %q, want %q", got, want)
+	}
+	if utf8.RuneCountInString(got) != utf8.RuneCountInString(in) {
+		t.Fatalf("expected the width kept, got %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+package prompt
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/binmeta"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+type binaryResultMsg struct {
+	promptID string
+	info     binmeta.Info
+	err      error
+}
+
+func inspectBinaryCmd(promptID, path string) tea.Cmd {
+	return func() tea.Msg {
+		info, err := binmeta.Inspect(path)
+		return binaryResultMsg{promptID: promptID, info: info, err: err}
+	}
+}
+
+// startBinary reads the header of the prompting binary off the UI
+// goroutine. Only local nodes have the file at hand.
+func (m *Model) startBinary(prompt state.Prompt, local bool) tea.Cmd {
+	path := prompt.Connection.RawProcessPath()
+	switch {
+	case !local:
+		m.binaryLines = []string{m.theme.Subtle.Render("Not available for remote nodes")}
+		return nil
+	case path == "":
+		m.binaryLines = []string{m.theme.Subtle.Render("Process path unknown")}
+		return nil
+	}
+	m.binaryLines = []string{m.theme.Warning.Render("Reading header…")}
+	return inspectBinaryCmd(prompt.ID, path)
+}
+
+func (m *Model) showBinary(info binmeta.Info, err error) {
+	if err != nil {
+		m.binaryLines = []string{m.theme.Danger.Render("Binary: error: " + util.Sanitize(err.Error()))}
+		m.refreshSection("binary")
+		return
+	}
+	lines := []string{"Type: " + util.Sanitize(info.Describe())}
+	if info.Kind == binmeta.KindELF && info.Interpreter != "" {
+		lines = append(lines, "Interpreter: "+util.Sanitize(info.Interpreter))
+	}
+	if info.Packed != "" {
+		lines = append(lines, m.theme.Danger.Render(fmt.Sprintf("Packed: %s signature in header (heuristic)", info.Packed)))
+	}
+	lines = append(lines, fmt.Sprintf("Size: %s · Modified: %s", util.HumanizeBytes(uint64(info.Size)), info.ModTime.Local().Format("2006-01-02 15:04:05")))
+	for _, line := range binmeta.HexDump(info.Head) {
+		lines = append(lines, m.theme.Subtle.Render(line))
+	}
+	m.binaryLines = lines
+	m.refreshSection("binary")
+}
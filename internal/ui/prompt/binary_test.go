@@ -0,0 +1,104 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func newBinaryModel(t *testing.T, prompt state.Prompt, nodes []state.Node) *Model {
+	t.Helper()
+	store := state.NewStore()
+	store.SetNodes(nodes)
+	store.AddPrompt(prompt)
+	settings := store.Snapshot().Settings
+	settings.InspectSections = []string{"binary"}
+	store.SetSettings(settings)
+	m := New(store, theme.New(theme.Options{}), nil)
+	m.SetSize(120, 40)
+	return m
+}
+
+// binaryMsg runs cmd and returns the header read among its messages.
+func binaryMsg(t *testing.T, cmd tea.Cmd) binaryResultMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatalf("expected the header read as a command")
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, inner := range batch {
+			if inner == nil {
+				continue
+			}
+			if found, ok := inner().(binaryResultMsg); ok {
+				return found
+			}
+		}
+	}
+	found, ok := msg.(binaryResultMsg)
+	if !ok {
+		t.Fatalf("expected a binary result, got %T", msg)
+	}
+	return found
+}
+
+func TestInspectShowsBinaryHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fetch")
+	if err := os.WriteFile(path, []byte("#!/usr/bin/env python3\nimport urllib\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := newBinaryModel(t, state.Prompt{ID: "p1", Connection: state.Connection{ProcessPath: path}}, nil)
+
+	cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	if !strings.Contains(inspectText(m), "Reading header…") {
+		t.Fatalf("expected the header read pending, got:\n%s", inspectText(m))
+	}
+	msg := binaryMsg(t, cmd)
+	if _, handled := m.Update(msg); !handled {
+		t.Fatalf("expected the binary result handled")
+	}
+	text := inspectText(m)
+	for _, want := range []string{"▾ 6 Binary", "Type: script, #!/usr/bin/env python3", "Size: 37 B", "00000000  23 21 2f 75"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in the Binary section, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestInspectBinarySkipsRemoteNodes(t *testing.T) {
+	nodes := []state.Node{{ID: "remote", Address: "203.0.113.5:50051"}}
+	m := newBinaryModel(t, state.Prompt{ID: "p1", NodeID: "remote", Connection: state.Connection{ProcessPath: "/usr/bin/curl"}}, nodes)
+
+	if cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}}); cmd != nil {
+		t.Fatalf("expected nothing read for a remote node")
+	}
+	if !strings.Contains(inspectText(m), "Not available for remote nodes") {
+		t.Fatalf("expected a remote note, got:\n%s", inspectText(m))
+	}
+}
+
+// A path with control characters is shown sanitized, but the header is
+// read from the file the daemon named.
+func TestInspectBinaryReadsTheRawPath(t *testing.T) {
+	raw := filepath.Join(t.TempDir(), "evil\nname\x1b")
+	if err := os.WriteFile(raw, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	conn := state.Connection{ProcessPath: util.Sanitize(raw)}
+	conn.Raw = &state.Connection{ProcessPath: raw}
+	m := newBinaryModel(t, state.Prompt{ID: "p1", Connection: conn}, nil)
+
+	cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m.Update(binaryMsg(t, cmd))
+	if text := inspectText(m); !strings.Contains(text, "Type: script, #!/bin/sh") {
+		t.Fatalf("expected the header of the raw path, got:\n%s", text)
+	}
+}
@@ -0,0 +1,40 @@
+package prompt
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+)
+
+// blocklistLine says which list entry the remote end of the prompt is on.
+func blocklistLine(prompt state.Prompt) string {
+	side := "destination"
+	if prompt.Connection.Inbound() {
+		side = "source"
+	}
+	match := prompt.Blocklist
+	return fmt.Sprintf("%s %s is on blocklist '%s' (line %d: %s)", glyphs.Current().Warning, side, match.List, match.Line, match.Entry)
+}
+
+// applyBlocklist preselects a permanent deny of the listed destination,
+// over the configured prompt defaults.
+func applyBlocklist(form *formState, prompt state.Prompt, targets []targetOption) {
+	if prompt.Blocklist == nil {
+		return
+	}
+	form.action = slices.IndexFunc(actionOptions, func(opt actionOption) bool { return opt.value == controller.PromptActionDeny })
+	form.duration = slices.IndexFunc(durationOptions, func(opt durationOption) bool { return opt.value == controller.PromptDurationAlways })
+	preferred := []controller.PromptTarget{controller.PromptTargetDestinationIP, controller.PromptTargetSourceIP}
+	if prompt.Blocklist.Host {
+		preferred = append([]controller.PromptTarget{controller.PromptTargetDestinationHost}, preferred...)
+	}
+	for _, want := range preferred {
+		if idx := slices.IndexFunc(targets, func(opt targetOption) bool { return opt.value == want }); idx >= 0 {
+			form.target = idx
+			return
+		}
+	}
+}
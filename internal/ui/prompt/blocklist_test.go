@@ -0,0 +1,60 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestPromptBlocklistOverridesDefaults(t *testing.T) {
+	m, store, ctrl := newTrackingModel(t)
+	settings := store.Snapshot().Settings
+	settings.DefaultPromptAction = "allow"
+	settings.DefaultPromptDuration = "once"
+	settings.DefaultPromptTarget = "process.path"
+	settings.PromptInitialFocus = "confirm"
+	store.SetSettings(settings)
+	store.AddPrompt(state.Prompt{
+		ID:       "ads",
+		NodeName: "local",
+		Connection: state.Connection{
+			ProcessPath: "/usr/bin/firefox",
+			DstHost:     "cdn.ads.example.com",
+			DstIP:       "203.0.113.9",
+			DstPort:     443,
+		},
+		Blocklist: &state.BlocklistMatch{List: "ads.txt", Line: 1042, Entry: "ads.example.com", Host: true},
+	})
+
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "destination is on blocklist 'ads.txt' (line 1042: ads.example.com)") {
+		t.Fatalf("expected the blocklist line, got:\n%s", out)
+	}
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 {
+		t.Fatalf("expected one decision, got %+v", ctrl.decisions)
+	}
+	got := ctrl.decisions[0]
+	if got.Action != controller.PromptActionDeny || got.Duration != controller.PromptDurationAlways || got.Target != controller.PromptTargetDestinationHost {
+		t.Fatalf("expected deny/always on the host over the defaults, got %+v", got)
+	}
+}
+
+func TestPromptBlocklistAddressMatchTargetsIP(t *testing.T) {
+	m, store, _ := newTrackingModel(t)
+	store.AddPrompt(state.Prompt{
+		ID:         "ip",
+		NodeName:   "local",
+		Connection: state.Connection{ProcessPath: "/usr/bin/curl", DstHost: "example.org", DstIP: "198.51.100.20", DstPort: 80},
+		Blocklist:  &state.BlocklistMatch{List: "bad-nets", Line: 3, Entry: "198.51.100.0/24"},
+	})
+	m.View()
+	form := m.forms["ip"]
+	targets := targetOptionsFor(store.Snapshot().Prompts[0].Connection, false)
+	if targets[form.target].value != controller.PromptTargetDestinationIP {
+		t.Fatalf("expected the address target preselected, got %s", targets[form.target].value)
+	}
+}
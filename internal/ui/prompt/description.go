@@ -0,0 +1,69 @@
+package prompt
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+const descriptionHelp = "type to edit the description · ↑/↓ move · esc back · enter confirm"
+
+// newDescriptionInput holds the description the rule for prompt would get,
+// rendered from the template when the prompt's form is made.
+func newDescriptionInput(prompt state.Prompt, settings state.Settings) textinput.Model {
+	limit := ruleset.LimitsFor(settings).Text
+	fields := ruleset.DescriptionFieldsFor(prompt, settings.UIDZeroUnknown, time.Now())
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = limit
+	input.SetValue(ruleset.RenderDescription(settings.RuleDescriptionTemplate, fields, limit))
+	return input
+}
+
+// updateDescription types into the description field. Only the keys that
+// leave the field or answer the prompt are taken from it.
+func (m *Model) updateDescription(key tea.KeyMsg, prompt state.Prompt, targets []targetOption, form *formState, prevID string) (tea.Cmd, bool) {
+	switch key.String() {
+	case "tab", "shift+tab", "ctrl+c":
+		return nil, false
+	case "up":
+		m.focus = fieldTarget
+		return nil, true
+	case "down":
+		m.focus = fieldAction
+		return nil, true
+	case "esc":
+		m.focus = fieldTarget
+		return nil, true
+	case "enter":
+		if prevID != "" && prevID != prompt.ID {
+			return nil, true
+		}
+		m.submit(prompt, targets, form)
+		return nil, true
+	}
+	form.description.Focus()
+	var cmd tea.Cmd
+	form.description, cmd = form.description.Update(key)
+	return cmd, true
+}
+
+// renderDescription shows the description field within innerWidth, with
+// a cursor while it has focus.
+func (m *Model) renderDescription(form *formState, innerWidth int) string {
+	label := m.theme.Header.Render("Description:")
+	ti := form.description
+	ti.Width = max(10, innerWidth-len("Description: ")-2)
+	marker := " "
+	if m.focus == fieldDescription {
+		ti.Focus()
+		marker = m.theme.Warning.Render(">")
+	} else {
+		ti.Blur()
+	}
+	return label + " " + marker + ti.View()
+}
@@ -0,0 +1,55 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestPromptDescriptionEditedBeforeSubmit(t *testing.T) {
+	m, store, ctrl := newTrackingModel(t, "curl")
+	settings := store.Snapshot().Settings
+	settings.RuleDescriptionTemplate = "{{base .Process}} to {{.Dest}}:{{.Port}} on {{.Node}}"
+	store.SetSettings(settings)
+
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "curl to 203.0.113.7:443 on local") {
+		t.Fatalf("expected the rendered description on the card, got:\n%s", out)
+	}
+	for range 3 {
+		pressKey(m, "j")
+	}
+	if m.focus != fieldDescription {
+		t.Fatalf("expected the description focused after the target, got %d", m.focus)
+	}
+	// Letters that are shortcuts elsewhere on the card are typed here.
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	pressKey(m, "daily updater")
+	if len(ctrl.decisions) != 0 || m.review != nil {
+		t.Fatalf("expected typing to answer nothing, got %+v", ctrl.decisions)
+	}
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 || ctrl.decisions[0].Description != "daily updater" {
+		t.Fatalf("expected the edited description sent, got %+v", ctrl.decisions)
+	}
+}
+
+func TestPromptDescriptionEscReturnsToForm(t *testing.T) {
+	m, _, ctrl := newTrackingModel(t, "curl")
+	m.View()
+	pressKey(m, "k")
+	pressKey(m, "k")
+	if m.focus != fieldDescription {
+		t.Fatalf("expected k to wrap from the action to the description, got %d", m.focus)
+	}
+	pressKey(m, "esc")
+	if m.focus != fieldTarget || len(ctrl.decisions) != 0 {
+		t.Fatalf("expected esc to move to the target without answering, got %d %+v", m.focus, ctrl.decisions)
+	}
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 || !strings.HasPrefix(ctrl.decisions[0].Description, "created from prompt: /usr/bin/curl → 203.0.113.7:443") {
+		t.Fatalf("expected the default description sent, got %+v", ctrl.decisions)
+	}
+}
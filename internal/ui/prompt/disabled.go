@@ -0,0 +1,73 @@
+package prompt
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+)
+
+// ruleEnabler is implemented by prompt controllers that can also manage
+// rules, which the daemon server does.
+type ruleEnabler interface {
+	EnableRule(nodeID, ruleName, hash string) error
+}
+
+// disabledMatch returns a disabled allow rule on the prompt's node that would
+// have matched the connection. Matchers are compiled per node and reused
+// until the rules change.
+func (m *Model) disabledMatch(snapshot state.Snapshot, prompt state.Prompt) (state.Rule, bool) {
+	if m.disabledMatchers == nil || m.disabledRev != snapshot.RulesRevision {
+		m.disabledMatchers = make(map[string]*ruleset.Matcher)
+		m.disabledRev = snapshot.RulesRevision
+	}
+	matcher, ok := m.disabledMatchers[prompt.NodeID]
+	if !ok {
+		var disabled []state.Rule
+		for _, rule := range snapshot.Rules[prompt.NodeID] {
+			if !rule.Enabled && rule.Action == string(controller.PromptActionAllow) {
+				disabled = append(disabled, rule)
+			}
+		}
+		matcher = ruleset.NewMatcher(disabled)
+		m.disabledMatchers[prompt.NodeID] = matcher
+	}
+	return matcher.First(prompt.Connection)
+}
+
+func disabledRuleHint(rule state.Rule) string {
+	return fmt.Sprintf("disabled rule '%s' would match — press R to re-enable instead", rule.Name)
+}
+
+// reenable turns the matching disabled rule back on and lets this connection
+// through once rather than adding another rule.
+func (m *Model) reenable(prompt state.Prompt, rule state.Rule, targets []targetOption, form *formState) {
+	if !widget.Guard(m.theme, m.controller != nil, &m.status) {
+		return
+	}
+	enabler, ok := m.controller.(ruleEnabler)
+	if !ok {
+		m.status = m.theme.Danger.Render("Rule re-enabling unavailable")
+		return
+	}
+	if err := enabler.EnableRule(prompt.NodeID, rule.Name, ruleset.Hash(rule)); err != nil {
+		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to enable %s: %v", rule.Name, err))
+		return
+	}
+	decision := controller.PromptDecision{
+		PromptID: prompt.ID,
+		Action:   controller.PromptActionAllow,
+		Duration: controller.PromptDurationOnce,
+	}
+	if len(targets) > 0 {
+		decision.Target = targets[min(form.target, len(targets)-1)].value
+	}
+	if err := m.controller.ResolvePrompt(decision); err != nil {
+		m.status = m.theme.Danger.Render(fmt.Sprintf("Enabled %s but failed to send decision: %v", rule.Name, err))
+		return
+	}
+	m.submittedID = prompt.ID
+	m.status = m.theme.Success.Render(fmt.Sprintf("Re-enabled %s and allowed once", rule.Name))
+}
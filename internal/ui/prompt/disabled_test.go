@@ -0,0 +1,114 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+type recordingRuleController struct {
+	recordingPromptManager
+	enabled []string
+}
+
+func (r *recordingRuleController) EnableRule(nodeID, ruleName, _ string) error {
+	r.enabled = append(r.enabled, nodeID+"/"+ruleName)
+	return nil
+}
+
+func spotifyRule(name, action string, enabled bool, host string) state.Rule {
+	return state.Rule{
+		Name:    name,
+		Action:  action,
+		Enabled: enabled,
+		Operator: state.RuleOperator{
+			Type: "list", Operand: "list",
+			Children: []state.RuleOperator{
+				{Type: "simple", Operand: "process.path", Data: "/usr/bin/spotify"},
+				{Type: "simple", Operand: "dest.host", Data: host},
+			},
+		},
+	}
+}
+
+func newDisabledRuleModel(t *testing.T, rules ...state.Rule) (*Model, *state.Store, *recordingRuleController) {
+	t.Helper()
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.AlertsInterrupt = true
+	store.SetSettings(settings)
+	store.SetRules("node-1", rules)
+	store.AddPrompt(state.Prompt{ID: "p1", NodeID: "node-1", NodeName: "local", Connection: state.Connection{
+		ProcessPath: "/usr/bin/spotify",
+		DstHost:     "api.spotify.com",
+		DstIP:       "203.0.113.7",
+		DstPort:     443,
+		Protocol:    "tcp",
+	}})
+	ctrl := &recordingRuleController{}
+	m := New(store, theme.New(theme.Options{}), ctrl)
+	m.SetSize(120, 30)
+	return m, store, ctrl
+}
+
+func TestPromptHintsAtMatchingDisabledRule(t *testing.T) {
+	m, _, _ := newDisabledRuleModel(t, spotifyRule("allow-spotify", "allow", false, "api.spotify.com"))
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "disabled rule 'allow-spotify' would match — press R to re-enable instead") {
+		t.Fatalf("expected disabled rule hint, got:\n%s", out)
+	}
+}
+
+func TestPromptDisabledRuleHintAvoidsFalsePositives(t *testing.T) {
+	cases := []struct {
+		name string
+		node string
+		rule state.Rule
+	}{
+		{"enabled rule", "node-1", spotifyRule("allow-spotify", "allow", true, "api.spotify.com")},
+		{"other host", "node-1", spotifyRule("allow-spotify", "allow", false, "ads.spotify.com")},
+		{"disabled deny", "node-1", spotifyRule("deny-spotify", "deny", false, "api.spotify.com")},
+		{"other node", "node-2", spotifyRule("allow-spotify", "allow", false, "api.spotify.com")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, store, ctrl := newDisabledRuleModel(t)
+			store.SetRules(tc.node, []state.Rule{tc.rule})
+			if out := util.StripANSI(m.View()); strings.Contains(out, "would match") {
+				t.Fatalf("did not expect a hint, got:\n%s", out)
+			}
+			pressKey(m, "R")
+			if len(ctrl.enabled) != 0 || len(ctrl.decisions) != 0 {
+				t.Fatalf("expected R to do nothing, got enabled=%v decisions=%v", ctrl.enabled, ctrl.decisions)
+			}
+		})
+	}
+}
+
+func TestPromptReenableDisabledRule(t *testing.T) {
+	m, store, ctrl := newDisabledRuleModel(t, spotifyRule("allow-spotify", "allow", false, "api.spotify.com"))
+	m.View()
+	pressKey(m, "d") // the form choice is ignored by R
+	pressKey(m, "R")
+
+	if len(ctrl.enabled) != 1 || ctrl.enabled[0] != "node-1/allow-spotify" {
+		t.Fatalf("expected rule to be enabled, got %v", ctrl.enabled)
+	}
+	if len(ctrl.decisions) != 1 {
+		t.Fatalf("expected one decision, got %+v", ctrl.decisions)
+	}
+	got := ctrl.decisions[0]
+	if got.PromptID != "p1" || got.Action != controller.PromptActionAllow || got.Duration != controller.PromptDurationOnce {
+		t.Fatalf("expected allow/once for p1, got %+v", got)
+	}
+
+	// Once the rule is enabled the hint goes away for later prompts.
+	store.UpdateRule("node-1", "allow-spotify", func(r *state.Rule) { r.Enabled = true })
+	if _, ok := m.disabledMatch(store.Snapshot(), store.Snapshot().Prompts[0]); ok {
+		t.Fatalf("expected cached matchers to refresh after the rules changed")
+	}
+}
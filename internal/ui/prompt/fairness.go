@@ -0,0 +1,97 @@
+package prompt
+
+import (
+	"sort"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// expiringThreshold is how close to its timeout a pending prompt gets before
+// the header flags it.
+const expiringThreshold = 5 * time.Second
+
+// timeLeft returns how long prompt has before it times out. Paused prompts
+// report what was left when they were paused.
+func timeLeft(prompt state.Prompt, timeout time.Duration, now time.Time) time.Duration {
+	if prompt.Paused {
+		return max(prompt.Remaining, 0)
+	}
+	expiresAt := prompt.ExpiresAt
+	if expiresAt.IsZero() {
+		if prompt.RequestedAt.IsZero() {
+			return timeout
+		}
+		expiresAt = prompt.RequestedAt.Add(timeout)
+	}
+	return max(expiresAt.Sub(now), 0)
+}
+
+// expiryOrder returns the indexes of prompts ordered by time left, ties
+// keeping arrival order.
+func expiryOrder(prompts []state.Prompt, timeout time.Duration, now time.Time) []int {
+	order := make([]int, len(prompts))
+	left := make([]time.Duration, len(prompts))
+	for i, prompt := range prompts {
+		order[i] = i
+		left[i] = timeLeft(prompt, timeout, now)
+	}
+	sort.SliceStable(order, func(a, b int) bool { return left[order[a]] < left[order[b]] })
+	return order
+}
+
+// expiringOther returns the index of the prompt closest to timing out, other
+// than skip, if it is under expiringThreshold.
+func expiringOther(prompts []state.Prompt, skip int, timeout time.Duration, now time.Time) (int, bool) {
+	for _, idx := range expiryOrder(prompts, timeout, now) {
+		if idx == skip {
+			continue
+		}
+		return idx, timeLeft(prompts[idx], timeout, now) < expiringThreshold
+	}
+	return -1, false
+}
+
+func promptTimeout(settings state.Settings) time.Duration {
+	if settings.PromptTimeout > 0 {
+		return settings.PromptTimeout
+	}
+	return fallbackPromptTimeout
+}
+
+// selectPrompt picks the index of the prompt to display. A prompt the user
+// navigated to stays pinned until it resolves; otherwise the one closest to
+// timing out is shown first, and with PromptAutoJump the display moves to
+// any other prompt about to expire.
+func (m *Model) selectPrompt(snapshot state.Snapshot, current int) int {
+	now := time.Now()
+	timeout := promptTimeout(snapshot.Settings)
+	if current < 0 {
+		m.pinned = false
+		return expiryOrder(snapshot.Prompts, timeout, now)[0]
+	}
+	if m.pinned || !snapshot.Settings.PromptAutoJump {
+		return current
+	}
+	if timeLeft(snapshot.Prompts[current], timeout, now) < expiringThreshold {
+		return current
+	}
+	if idx, expiring := expiringOther(snapshot.Prompts, current, timeout, now); expiring {
+		return idx
+	}
+	return current
+}
+
+// expiringBadge flags the displayed prompt, or another pending one, when it
+// is about to time out.
+func (m *Model) expiringBadge(snapshot state.Snapshot, current int) string {
+	now := time.Now()
+	timeout := promptTimeout(snapshot.Settings)
+	if timeLeft(snapshot.Prompts[current], timeout, now) < expiringThreshold {
+		return m.theme.Danger.Render("expiring!")
+	}
+	if _, expiring := expiringOther(snapshot.Prompts, current, timeout, now); expiring {
+		return m.theme.Danger.Render("another prompt expiring!")
+	}
+	return ""
+}
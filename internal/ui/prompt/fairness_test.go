@@ -0,0 +1,106 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func addExpiringPrompt(store *state.Store, id string, left time.Duration) {
+	store.AddPrompt(state.Prompt{
+		ID:         id,
+		NodeName:   "local",
+		Connection: state.Connection{ProcessPath: "/usr/bin/" + id},
+		ExpiresAt:  time.Now().Add(left),
+	})
+}
+
+func setAutoJump(store *state.Store, enabled bool) {
+	settings := store.Snapshot().Settings
+	settings.PromptAutoJump = enabled
+	store.SetSettings(settings)
+}
+
+func TestPromptShowsEarliestExpiryFirst(t *testing.T) {
+	m, store, _ := newTrackingModel(t)
+	addExpiringPrompt(store, "curl", time.Minute)
+	addExpiringPrompt(store, "wget", 20*time.Second)
+	addExpiringPrompt(store, "ssh", 40*time.Second)
+
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "/usr/bin/wget") {
+		t.Fatalf("expected the prompt closest to timing out, got:\n%s", out)
+	}
+	// [/] cycles in expiry order: wget, ssh, curl.
+	for _, want := range []string{"ssh", "curl", "wget"} {
+		pressKey(m, "]")
+		if m.activeID != want {
+			t.Fatalf("expected ] to move to %s, got %s", want, m.activeID)
+		}
+	}
+}
+
+func TestPromptManualSelectionPinnedUntilResolved(t *testing.T) {
+	m, store, _ := newTrackingModel(t)
+	setAutoJump(store, true)
+	addExpiringPrompt(store, "curl", 20*time.Second)
+	addExpiringPrompt(store, "wget", time.Minute)
+	m.View()
+	pressKey(m, "]") // pin wget
+
+	addExpiringPrompt(store, "ssh", 2*time.Second)
+	out := util.StripANSI(m.View())
+	if m.activeID != "wget" {
+		t.Fatalf("expected the pinned prompt to stay, got %s", m.activeID)
+	}
+	if !strings.Contains(out, "another prompt expiring!") {
+		t.Fatalf("expected a badge for the other expiring prompt, got:\n%s", out)
+	}
+
+	store.RemovePrompt("wget")
+	m.View()
+	if m.activeID != "ssh" || m.pinned {
+		t.Fatalf("expected selection to resume on ssh once unpinned, got %s (pinned %v)", m.activeID, m.pinned)
+	}
+}
+
+func TestPromptAutoJumpToExpiring(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		m, store, _ := newTrackingModel(t)
+		setAutoJump(store, enabled)
+		addExpiringPrompt(store, "curl", time.Minute)
+		m.View()
+		addExpiringPrompt(store, "wget", 3*time.Second)
+		m.View()
+
+		want := "curl"
+		if enabled {
+			want = "wget"
+		}
+		if m.activeID != want {
+			t.Fatalf("auto-jump %v: expected %s, got %s", enabled, want, m.activeID)
+		}
+	}
+}
+
+func TestPromptExpiringBadgeThreshold(t *testing.T) {
+	cases := []struct {
+		left  time.Duration
+		badge bool
+	}{
+		{time.Minute, false},
+		{6 * time.Second, false},
+		{4 * time.Second, true},
+		{-time.Second, true},
+	}
+	for _, tc := range cases {
+		m, store, _ := newTrackingModel(t)
+		addExpiringPrompt(store, "curl", tc.left)
+		out := util.StripANSI(m.View())
+		if got := strings.Contains(out, "expiring!"); got != tc.badge {
+			t.Errorf("%s left: badge shown = %v, want %v", tc.left, got, tc.badge)
+		}
+	}
+}
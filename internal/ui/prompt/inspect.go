@@ -2,54 +2,23 @@ package prompt
 
 import (
 	"fmt"
-	"net"
 	"os"
 	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
+	"github.com/adamkadaban/opensnitch-tui/internal/container"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
 type PathHighlighter func(string) string
 
-func isLocalNode(nodes []state.Node, nodeID string) bool {
-	if nodeID == "" {
-		return true
-	}
-	// If the nodeID itself looks like an address (peerKey style), evaluate directly.
-	if isLocalAddress(nodeID) {
-		return true
-	}
-	for _, n := range nodes {
-		if n.ID == nodeID {
-			return isLocalAddress(n.Address)
-		}
-	}
-	return false
-}
-
-func isLocalAddress(addr string) bool {
-	if addr == "" {
-		return true
-	}
-	if strings.HasPrefix(addr, "unix://") {
-		return true
-	}
-	host := addr
-	if h, _, err := net.SplitHostPort(host); err == nil {
-		host = h
-	}
-	host = strings.Trim(host, "[]")
-	if ip := net.ParseIP(host); ip != nil {
-		return ip.IsLoopback()
-	}
-	host = strings.ToLower(host)
-	return host == "localhost"
-}
-
 func hasPrefix(path string, prefixes []string) bool {
 	for _, p := range prefixes {
 		if strings.HasPrefix(path, p) {
@@ -68,69 +37,78 @@ func matchedPrefix(path string, prefixes []string) string {
 	return ""
 }
 
-func buildProcessInspect(conn state.Connection, hl PathHighlighter) processInspect {
+// identityLines describes who the prompting process is: executable,
+// arguments, owner and checksums.
+func identityLines(conn state.Connection, hl PathHighlighter, containers *container.Resolver) []string {
 	lines := []string{}
-	maxWidth := 0
-	track := func(s string) {
-		lines = append(lines, s)
-		if w := util.RuneWidth(s); w > maxWidth {
-			maxWidth = w
-		}
-	}
 	pid := int(conn.ProcessID)
 	if pid > 0 {
-		track(fmt.Sprintf("PID: %d", pid))
+		lines = append(lines, fmt.Sprintf("PID: %d", pid))
 	}
 	if conn.ProcessPath != "" {
 		path := conn.ProcessPath
 		if hl != nil {
 			path = hl(path)
 		}
-		track(fmt.Sprintf("Executable: %s", path))
+		lines = append(lines, fmt.Sprintf("Executable: %s", path))
+	}
+	if info, ok := containers.Lookup(conn.ProcessID, conn.ProcessPath); ok {
+		lines = append(lines, fmt.Sprintf("Container: %s", info.Label()))
 	}
 	if len(conn.ProcessArgs) > 0 {
-		track(fmt.Sprintf("Args: %s", strings.Join(conn.ProcessArgs, " ")))
+		lines = append(lines, fmt.Sprintf("Args: %s", strings.Join(conn.ProcessArgs, " ")))
 	}
 	if conn.ProcessCWD != "" {
 		cwd := conn.ProcessCWD
 		if hl != nil {
 			cwd = hl(cwd)
 		}
-		track(fmt.Sprintf("CWD: %s", cwd))
+		lines = append(lines, fmt.Sprintf("CWD: %s", cwd))
 	}
+	if conn.DstHost != "" {
+		lines = append(lines, fmt.Sprintf("Destination host: %s", conn.DstHost))
+	}
+	lines = append(lines, checksumSection(conn)...)
 	if conn.UserID != 0 {
-		track(fmt.Sprintf("User: %s", resolveUser(uint32(conn.UserID))))
+		lines = append(lines, fmt.Sprintf("User: %s", resolveUser(uint32(conn.UserID))))
 	}
 
 	// Best-effort /proc inspection (only works if TUI host == process host)
 	if pid > 0 {
-		uids, gids := readProcIDs(pid)
+		uids, gids, err := readProcIDs(pid)
+		if err != nil {
+			lines = append(lines, procUnavailable(err))
+		}
 		if gids[0] != "" {
-			track(fmt.Sprintf("Group: %s", resolveGroup(gids[0])))
+			lines = append(lines, fmt.Sprintf("Group: %s", resolveGroup(gids[0])))
 		}
 		if uids[1] != "" {
-			track(fmt.Sprintf("User (effective): %s", resolveUserString(uids[1])))
+			lines = append(lines, fmt.Sprintf("User (effective): %s", resolveUserString(uids[1])))
 		}
 		if gids[1] != "" {
-			track(fmt.Sprintf("Group (effective): %s", resolveGroup(gids[1])))
-		}
-
-		if tree := readProcessTree(pid, hl); len(tree) > 0 {
-			track("")
-			track("Process Tree:")
-			for _, line := range tree {
-				track(line)
-			}
+			lines = append(lines, fmt.Sprintf("Group (effective): %s", resolveGroup(gids[1])))
 		}
 	}
 
 	if len(lines) == 0 {
-		track("No additional process info available")
+		lines = append(lines, "No additional process info available")
 	}
-	return processInspect{Lines: lines, MaxWidth: maxWidth}
+	return lines
 }
 
-// (YARA status is rendered in the header; content remains plain process info.)
+// checksumSection lists the daemon-reported checksums, one per line. They
+// come with the connection, so remote nodes have them too.
+func checksumSection(conn state.Connection) []string {
+	checksums := util.ChecksumLines(util.SortedChecksums(conn.ProcessChecksums))
+	if len(checksums) == 0 {
+		return nil
+	}
+	lines := []string{"Checksums:"}
+	for _, line := range checksums {
+		lines = append(lines, "  "+line)
+	}
+	return lines
+}
 
 // renderInspectContent slices lines horizontally by offset and clips to width.
 func renderInspectContent(info processInspect, offset, width int) string {
@@ -157,20 +135,25 @@ type procNode struct {
 	Children []*procNode
 }
 
-func readProcessTree(pid int, hl PathHighlighter) []string {
+// readProcessTree renders pid and its descendants; it fails when pid's own
+// entry cannot be read.
+func readProcessTree(pid int, hl PathHighlighter) ([]string, error) {
+	if _, _, err := readProcStat(pid); err != nil {
+		return nil, err
+	}
 	root := buildTree(pid, map[int]bool{}, 0, hl)
 	if root == nil {
-		return nil
+		return nil, nil
 	}
 	var lines []string
 	formatTree(root, "", true, &lines)
-	return lines
+	return lines, nil
 }
 
-func readProcStat(pid int) (comm string, ppid int) {
-	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+func readProcStat(pid int) (comm string, ppid int, err error) {
+	data, err := readProcFile(filepath.Join(procRoot, strconv.Itoa(pid), "stat"))
 	if err != nil {
-		return "?", 0
+		return "?", 0, classifyProc(err)
 	}
 	// /proc/<pid>/stat format: pid (comm) state ppid ...
 	content := string(data)
@@ -190,7 +173,7 @@ func readProcStat(pid int) (comm string, ppid int) {
 	if comm == "" {
 		comm = "?"
 	}
-	return comm, ppid
+	return util.Sanitize(comm), ppid, nil
 }
 
 func buildTree(pid int, visited map[int]bool, depth int, hl PathHighlighter) *procNode {
@@ -201,7 +184,7 @@ func buildTree(pid int, visited map[int]bool, depth int, hl PathHighlighter) *pr
 		return nil
 	}
 	visited[pid] = true
-	comm, _ := readProcStat(pid)
+	comm, _, _ := readProcStat(pid)
 	cmdline := readProcCmdline(pid)
 	path := readProcExe(pid)
 	if hl != nil {
@@ -220,10 +203,11 @@ func formatTree(node *procNode, prefix string, last bool, out *[]string) {
 	if node == nil {
 		return
 	}
-	connector := "├──"
-	childPrefix := "│   "
+	g := glyphs.Current()
+	connector := g.Branch + g.Line + g.Line
+	childPrefix := g.Pipe + "   "
 	if last {
-		connector = "└──"
+		connector = g.Last + g.Line + g.Line
 		childPrefix = "    "
 	}
 	line := fmt.Sprintf("%s%s%d %s", prefix, connector, node.PID, node.Comm)
@@ -240,24 +224,24 @@ func formatTree(node *procNode, prefix string, last bool, out *[]string) {
 }
 
 func readProcCmdline(pid int) string {
-	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	data, err := readProcFile(filepath.Join(procRoot, strconv.Itoa(pid), "cmdline"))
 	if err != nil {
 		return ""
 	}
 	parts := strings.Split(string(data), "\x00")
-	return strings.TrimSpace(strings.Join(parts, " "))
+	return util.Sanitize(strings.TrimSpace(strings.Join(parts, " ")))
 }
 
 func readProcExe(pid int) string {
-	path, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "exe"))
+	path, err := os.Readlink(filepath.Join(procRoot, strconv.Itoa(pid), "exe"))
 	if err != nil {
 		return ""
 	}
-	return path
+	return util.Sanitize(path)
 }
 
 func readProcChildren(pid int) []int {
-	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "task", strconv.Itoa(pid), "children"))
+	data, err := readProcFile(filepath.Join(procRoot, strconv.Itoa(pid), "task", strconv.Itoa(pid), "children"))
 	if err != nil {
 		return nil
 	}
@@ -309,11 +293,11 @@ func lookupGroup(gid string) (string, error) {
 
 // readProcIDs parses /proc/<pid>/status for Uid and Gid lines.
 // Returns arrays [real, effective, saved set, fs].
-func readProcIDs(pid int) ([4]string, [4]string) {
+func readProcIDs(pid int) ([4]string, [4]string, error) {
 	var uids, gids [4]string
-	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "status"))
+	data, err := readProcFile(filepath.Join(procRoot, strconv.Itoa(pid), "status"))
 	if err != nil {
-		return uids, gids
+		return uids, gids, classifyProc(err)
 	}
 	for _, line := range strings.Split(string(data), "\n") {
 		if strings.HasPrefix(line, "Uid:") {
@@ -329,7 +313,7 @@ func readProcIDs(pid int) ([4]string, [4]string) {
 			}
 		}
 	}
-	return uids, gids
+	return uids, gids, nil
 }
 
 func resolveUserString(id string) string {
@@ -341,3 +325,104 @@ func resolveUserString(id string) string {
 	}
 	return id
 }
+
+// copyNextChecksum copies the prompt's checksums one per press, cycling
+// through the algorithms in display order.
+func (m *Model) copyNextChecksum(prompt state.Prompt) tea.Cmd {
+	checksums := util.SortedChecksums(prompt.Connection.ProcessChecksums)
+	if len(checksums) == 0 {
+		m.status = m.theme.Warning.Render("No checksums reported")
+		return nil
+	}
+	m.checksumIdx = util.WrapIndex(m.checksumIdx, 1, len(checksums))
+	cs := checksums[m.checksumIdx]
+	m.status = m.theme.Success.Render(fmt.Sprintf("Copied %s checksum to clipboard", cs.Algorithm))
+	return clipboard.Copy(cs.Value)
+}
+
+// copyVirusTotalURL copies the VirusTotal report URL for the sha256; the TUI
+// never contacts VirusTotal itself.
+func (m *Model) copyVirusTotalURL(prompt state.Prompt) tea.Cmd {
+	url, ok := util.VirusTotalURL(prompt.Connection.ProcessChecksums)
+	if !ok {
+		m.status = m.theme.Warning.Render("No sha256 checksum reported")
+		return nil
+	}
+	m.status = m.theme.Success.Render("Copied " + url)
+	return clipboard.Copy(url)
+}
+
+// inspectSections builds the inspect panel of prompt. Process details come
+// from /proc, so remote nodes only get what the daemon reported.
+func (m *Model) inspectSections(prompt state.Prompt, settings state.Settings, local bool) []inspectSection {
+	conn := prompt.Connection
+	pid := int(conn.ProcessID)
+	fromProc := func(read func(int) []string) func() []string {
+		return func() []string {
+			switch {
+			case !local:
+				return []string{"Not available for remote nodes"}
+			case pid <= 0:
+				return []string{"Process ID unknown"}
+			}
+			// Without access to the process's entry the section would be
+			// empty or misleading; say why instead.
+			if err := checkProc(pid); err != nil {
+				return []string{procUnavailable(err)}
+			}
+			return read(pid)
+		}
+	}
+	return []inspectSection{
+		{id: "identity", key: "1", title: "Identity", lines: func() []string {
+			if !local {
+				return append([]string{"Process details available only for local nodes"}, checksumSection(conn)...)
+			}
+			return identityLines(conn, m.highlightPath, m.containers)
+		}},
+		{id: "yara", key: "2", title: "YARA", lines: m.yaraLines, open: func() tea.Cmd {
+			if !local {
+				m.setYaraStatus("YARA: unavailable for remote nodes", yaraStatusNotAvailable)
+				return m.startScanner(prompt, settings, false)
+			}
+			return tea.Batch(m.startScanner(prompt, settings, true), m.startYara(prompt, settings))
+		}},
+		{id: "tree", key: "3", title: "Process tree", lines: fromProc(func(pid int) []string {
+			lines, err := readProcessTree(pid, m.highlightPath)
+			if err != nil {
+				return []string{procUnavailable(err)}
+			}
+			return lines
+		})},
+		{id: "sockets", key: "4", title: "Sockets", lines: fromProc(readProcSockets)},
+		{id: "env", key: "5", title: "Environment", lines: fromProc(readProcEnviron)},
+		{id: "binary", key: "6", title: "Binary", lines: func() []string { return m.binaryLines }, open: func() tea.Cmd {
+			return m.startBinary(prompt, local)
+		}},
+	}
+}
+
+// yaraLines renders the YARA status and matches followed by the scanner
+// hook's output.
+func (m *Model) yaraLines() []string {
+	var lines []string
+	if m.yaraStatus != "" {
+		style := m.theme.Subtle
+		switch m.yaraKind {
+		case yaraStatusScanning:
+			style = m.theme.Warning
+		case yaraStatusNoMatches:
+			style = m.theme.Success
+		case yaraStatusMatches, yaraStatusError, yaraStatusNotAvailable, yaraStatusRuleDirMissing, yaraStatusPathUnknown, yaraStatusTimeout:
+			style = m.theme.Danger
+		}
+		lines = append(lines, style.Render(m.yaraStatus))
+	}
+	if m.yaraWarning != "" {
+		lines = append(lines, m.theme.Danger.Render(glyphs.Current().Warning+" "+m.yaraWarning))
+	}
+	for _, rule := range m.yaraMatches {
+		lines = append(lines, m.theme.Danger.Render(" - "+rule))
+	}
+	return append(lines, m.scannerLines...)
+}
@@ -5,8 +5,10 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"unicode"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 )
 
 func TestBuildProcessInspect_IncludesRealGroup(t *testing.T) {
@@ -18,10 +20,10 @@ func TestBuildProcessInspect_IncludesRealGroup(t *testing.T) {
 	}
 
 	pid := os.Getpid()
-	info := buildProcessInspect(state.Connection{ProcessID: uint32(pid)}, nil)
+	lines := identityLines(state.Connection{ProcessID: uint32(pid)}, nil, nil)
 
 	hasRealGroup := false
-	for _, line := range info.Lines {
+	for _, line := range lines {
 		if strings.HasPrefix(line, "Group: ") {
 			hasRealGroup = true
 			break
@@ -29,6 +31,55 @@ func TestBuildProcessInspect_IncludesRealGroup(t *testing.T) {
 	}
 
 	if !hasRealGroup {
-		t.Fatalf("expected real group line in inspect info; got lines: %v", info.Lines)
+		t.Fatalf("expected real group line in inspect info; got lines: %v", lines)
+	}
+}
+
+func TestBuildProcessInspectListsChecksums(t *testing.T) {
+	lines := identityLines(state.Connection{ProcessChecksums: map[string]string{
+		"sha1": "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"md5":  "d41d8cd98f00b204e9800998ecf8427e",
+	}}, nil, nil)
+	want := []string{
+		"Checksums:",
+		"  md5   d41d8cd98f00b204e9800998ecf8427e",
+		"  sha1  da39a3ee5e6b4b0d3255bfef95601890afd80709",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected inspect lines: %q", lines)
+	}
+
+	empty := identityLines(state.Connection{ProcessChecksums: map[string]string{}}, nil, nil)
+	for _, line := range empty {
+		if strings.Contains(line, "Checksums") {
+			t.Fatalf("expected no checksum header without checksums, got %q", empty)
+		}
+	}
+}
+
+func TestFormatTreeGlyphs(t *testing.T) {
+	tree := &procNode{PID: 1, Comm: "init", Children: []*procNode{
+		{PID: 10, Comm: "sshd", Children: []*procNode{{PID: 11, Comm: "bash"}}},
+		{PID: 20, Comm: "curl"},
+	}}
+	render := func() string {
+		var lines []string
+		formatTree(tree, "", true, &lines)
+		return strings.Join(lines, "\n")
+	}
+
+	if got, want := render(), "└──1 init\n    ├──10 sshd\n    │   └──11 bash\n    └──20 curl"; got != want {
+		t.Fatalf("Unicode tree =\n%s\nwant\n%s", got, want)
+	}
+	glyphs.SetASCII(true)
+	t.Cleanup(func() { glyphs.SetASCII(false) })
+	got := render()
+	if want := "`--1 init\n    |--10 sshd\n    |   `--11 bash\n    `--20 curl"; got != want {
+		t.Fatalf("ASCII tree =\n%s\nwant\n%s", got, want)
+	}
+	for _, r := range got {
+		if r > unicode.MaxASCII {
+			t.Fatalf("expected only ASCII in %q", got)
+		}
 	}
 }
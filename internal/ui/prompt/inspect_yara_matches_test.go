@@ -12,7 +12,7 @@ import (
 	"github.com/adamkadaban/opensnitch-tui/internal/yara"
 )
 
-func TestInspectInsertsYaraMatchesBeforeProcessTree(t *testing.T) {
+func TestInspectListsYaraMatchesBeforeProcessTree(t *testing.T) {
 	store := state.NewStore()
 	store.AddPrompt(state.Prompt{ID: "p1", Connection: state.Connection{ProcessPath: "/bin/echo", ProcessID: uint32(os.Getpid())}})
 	settings := store.Snapshot().Settings
@@ -30,7 +30,7 @@ func TestInspectInsertsYaraMatchesBeforeProcessTree(t *testing.T) {
 	}
 
 	matches := []yara.Match{{Rule: "rule-one"}, {Rule: "rule-two"}}
-	if _, handled := m.Update(yaraResultMsg{promptID: "p1", result: yara.Result{Matches: matches}}); !handled {
+	if _, handled := m.Update(yaraResultMsg{promptID: "p1", scans: []yaraScan{{result: yara.Result{Matches: matches}}}}); !handled {
 		t.Fatalf("expected yaraResultMsg to be handled")
 	}
 
@@ -41,7 +41,7 @@ func TestInspectInsertsYaraMatchesBeforeProcessTree(t *testing.T) {
 		if matchIdx == -1 && strings.Contains(line, "rule-one") {
 			matchIdx = i
 		}
-		if strings.HasPrefix(line, "Process Tree:") {
+		if strings.Contains(line, "Process tree") {
 			ptIdx = i
 			break
 		}
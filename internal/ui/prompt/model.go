@@ -7,15 +7,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/container"
 	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/scanhook"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/util/netnames"
 	"github.com/adamkadaban/opensnitch-tui/internal/yara"
 )
 
@@ -33,6 +40,7 @@ type Model struct {
 	forms          map[string]*formState
 	status         string
 	activeID       string
+	submittedID    string
 	inspect        bool
 	inspectInfo    processInspect
 	inspectVP      viewport.Model
@@ -41,7 +49,35 @@ type Model struct {
 	yaraPending    bool
 	yaraStatus     string
 	yaraKind       yaraStatusKind
-	inspectRoot    bool
+	yaraMatches    []string
+	// yaraWarning says the running binary is not the file on disk.
+	yaraWarning string
+	// yaraScanFile and yaraAvailable reach the YARA scanner; tests
+	// replace them.
+	yaraScanFile  func(path, rulesDir string) (yara.Result, error)
+	yaraAvailable func() bool
+	// scanner runs the inspect hook; scannerLines is its latest output.
+	scanner      scanhook.Runner
+	scannerLines []string
+	// binaryLines is the Binary section: the header of the prompting
+	// executable.
+	binaryLines []string
+	// containers names the container of local prompting processes.
+	containers  *container.Resolver
+	inspectRoot bool
+	// panel holds the sections of the inspected prompt; expanded is the
+	// set of open sections, kept for the whole session.
+	panel       *sectionPanel
+	expanded    map[string]bool
+	checksumIdx int
+	// pinned is set once the user picks a prompt with [/]; automatic
+	// selection leaves it alone until it resolves.
+	pinned bool
+	// review is the open batch review of every pending prompt, if any.
+	review *reviewState
+
+	disabledRev      uint64
+	disabledMatchers map[string]*ruleset.Matcher
 }
 
 var (
@@ -86,8 +122,10 @@ func (m *Model) toggleInspect(prompt state.Prompt, settings state.Settings, loca
 		m.inspect = false
 		m.paused = false
 		m.status = ""
+		m.checksumIdx = -1
 		m.yaraPending = false
 		m.yaraStatus = ""
+		m.panel = nil
 		return nil
 	}
 	// enter inspect
@@ -103,26 +141,30 @@ func (m *Model) toggleInspect(prompt state.Prompt, settings state.Settings, loca
 	// detect root (real or effective)
 	root := prompt.Connection.UserID == 0
 	if !root && prompt.Connection.ProcessID != 0 {
-		uids, _ := readProcIDs(int(prompt.Connection.ProcessID))
+		uids, _, _ := readProcIDs(int(prompt.Connection.ProcessID))
 		if uids[1] == "0" { // effective UID
 			root = true
 		}
 	}
 	m.inspectRoot = root
-	if !local {
-		msg := "Process details available only for local nodes"
-		m.inspectInfo = processInspect{Lines: []string{msg}, MaxWidth: len(msg)}
-		m.resetInspectViewport()
-		m.setYaraStatus("YARA: unavailable for remote nodes", yaraStatusNotAvailable)
-		m.inspect = true
-		return nil
+	if m.expanded == nil {
+		m.expanded = expandedSections(settings.InspectSections)
 	}
-
-	m.inspectInfo = buildProcessInspect(prompt.Connection, m.highlightPath)
-	m.resetInspectViewport()
+	m.yaraMatches = nil
+	m.yaraWarning = ""
+	m.scannerLines = nil
+	m.binaryLines = nil
 	m.setYaraStatus("", yaraStatusUnknown)
+	m.panel = newSectionPanel(m.inspectSections(prompt, settings, local), m.expanded)
 	m.inspect = true
-	// trigger optional YARA scan
+	cmd := m.panel.start()
+	m.resetInspectViewport()
+	m.refreshInspect()
+	return cmd
+}
+
+// startYara runs the optional YARA scan of the prompting binary.
+func (m *Model) startYara(prompt state.Prompt, settings state.Settings) tea.Cmd {
 	if !settings.YaraEnabled {
 		m.setYaraStatus("YARA: disabled", yaraStatusDisabled)
 		return nil
@@ -135,37 +177,51 @@ func (m *Model) toggleInspect(prompt state.Prompt, settings state.Settings, loca
 		m.setYaraStatus("YARA: process path unknown", yaraStatusPathUnknown)
 		return nil
 	}
-	if !yara.IsAvailable() {
+	if !m.yaraAvailable() {
 		m.setYaraStatus("YARA: not available (build without -tags yara)", yaraStatusNotAvailable)
 		return nil
 	}
 	m.yaraPending = true
-	status := fmt.Sprintf("YARA: scanning %s", prompt.Connection.ProcessPath)
+	path := prompt.Connection.ProcessPath
+	images, replaced := yaraImages(prompt.Connection.ProcessID, prompt.Connection.RawProcessPath())
+	status := fmt.Sprintf("YARA: scanning %s", path)
+	switch {
+	case replaced:
+		status = fmt.Sprintf("YARA: scanning %s and the running image", path)
+	case images[0].path != prompt.Connection.RawProcessPath():
+		status = fmt.Sprintf("YARA: scanning the running image of %s", path)
+	}
 	m.setYaraStatus(status, yaraStatusScanning)
-	return scanYaraCmd(prompt.ID, prompt.Connection.ProcessPath, settings.YaraRuleDir)
+	return scanYaraCmd(prompt.ID, path, images, replaced, settings.YaraRuleDir, m.yaraScanFile)
 }
 
 type yaraResultMsg struct {
 	promptID string
-	result   yara.Result
-	err      error
+	// path is the process path; scans has one entry per image scanned.
+	path     string
+	scans    []yaraScan
+	replaced bool
 }
 
-func scanYaraCmd(promptID, path, rulesDir string) tea.Cmd {
+func scanYaraCmd(promptID, path string, images []yaraImage, replaced bool, rulesDir string, scanFile func(path, rulesDir string) (yara.Result, error)) tea.Cmd {
 	return func() tea.Msg {
 		debug := os.Getenv("TUI_DEBUG_YARA") != ""
-		if debug {
-			log.Printf("[yara] scanning prompt=%s path=%s rules=%s", promptID, path, rulesDir)
-		}
-		res, err := yara.ScanFile(path, rulesDir)
-		if debug {
-			if err != nil {
-				log.Printf("[yara] scan error: %v", err)
-			} else {
-				log.Printf("[yara] scan matches: %d", len(res.Matches))
+		msg := yaraResultMsg{promptID: promptID, path: path, replaced: replaced}
+		for _, image := range images {
+			if debug {
+				log.Printf("[yara] scanning prompt=%s path=%s rules=%s", promptID, image.path, rulesDir)
+			}
+			res, err := scanFile(image.path, rulesDir)
+			if debug {
+				if err != nil {
+					log.Printf("[yara] scan error: %v", err)
+				} else {
+					log.Printf("[yara] scan matches: %d", len(res.Matches))
+				}
 			}
+			msg.scans = append(msg.scans, yaraScan{label: image.label, result: res, err: err})
 		}
-		return yaraResultMsg{promptID: promptID, result: res, err: err}
+		return msg
 	}
 }
 
@@ -183,35 +239,34 @@ func (m *Model) updateInspectContent() {
 	m.inspectVP.SetContent(content)
 }
 
-// setYaraStatus rebuilds inspect info with a single YARA status line above the process tree.
+// refreshInspect re-renders the section panel into the inspect viewport.
+func (m *Model) refreshInspect() {
+	if m.panel == nil {
+		return
+	}
+	lines := m.panel.render(m.theme.Header)
+	maxW := 0
+	for _, line := range lines {
+		maxW = max(maxW, util.RuneWidth(line))
+	}
+	m.inspectInfo = processInspect{Lines: lines, MaxWidth: maxW}
+	m.updateInspectContent()
+}
+
+// setYaraStatus updates the status line of the YARA section.
 func (m *Model) setYaraStatus(status string, kind yaraStatusKind) {
 	m.yaraStatus = status
 	m.yaraKind = kind
+	m.refreshSection("yara")
 }
 
-func (m *Model) insertInspectLinesBefore(pred func(string) bool, lines ...string) {
-	existing := m.inspectInfo.Lines
-	idx := len(existing)
-	for i, line := range existing {
-		if pred(line) {
-			idx = i
-			break
-		}
-	}
-	newLines := make([]string, 0, len(existing)+len(lines))
-	newLines = append(newLines, existing[:idx]...)
-	newLines = append(newLines, lines...)
-	newLines = append(newLines, existing[idx:]...)
-	m.inspectInfo.Lines = newLines
-	// recompute max width
-	maxW := 0
-	for _, line := range newLines {
-		if w := util.RuneWidth(line); w > maxW {
-			maxW = w
-		}
+// refreshSection recomputes the body of section id on an open panel.
+func (m *Model) refreshSection(id string) {
+	if m.panel == nil {
+		return
 	}
-	m.inspectInfo.MaxWidth = maxW
-	m.updateInspectContent()
+	m.panel.invalidate(id)
+	m.refreshInspect()
 }
 
 func (m *Model) computeInspectDimensions() (cardWidth, innerWidth, innerHeight int) {
@@ -252,12 +307,33 @@ const (
 	fieldAction field = iota
 	fieldDuration
 	fieldTarget
+	// fieldDescription is the rule's description, prefilled from the
+	// description template and typed into directly.
+	fieldDescription
+	// fieldConfirm sits outside the form: enter submits the defaults and
+	// down or esc move into the form.
+	fieldConfirm
 )
 
+// initialFocus maps the prompt_initial_focus setting to a field.
+func initialFocus(setting string) field {
+	switch setting {
+	case "duration":
+		return fieldDuration
+	case "target":
+		return fieldTarget
+	case "confirm":
+		return fieldConfirm
+	default:
+		return fieldAction
+	}
+}
+
 type formState struct {
-	action   int
-	duration int
-	target   int
+	action      int
+	duration    int
+	target      int
+	description textinput.Model
 }
 
 type actionOption struct {
@@ -295,11 +371,20 @@ type processInspect struct {
 }
 
 func New(store *state.Store, th theme.Theme, ctrl controller.PromptManager) *Model {
+	if !controller.Attached(ctrl) {
+		ctrl = nil
+	}
 	return &Model{
-		store:      store,
-		theme:      th,
-		controller: ctrl,
-		forms:      make(map[string]*formState),
+		store:       store,
+		theme:       th,
+		controller:  ctrl,
+		forms:       make(map[string]*formState),
+		checksumIdx: -1,
+		scanner:     scanhook.ExecRunner{},
+		containers:  container.Local,
+
+		yaraScanFile:  yara.ScanFile,
+		yaraAvailable: yara.IsAvailable,
 	}
 }
 
@@ -328,57 +413,79 @@ func (m *Model) Update(msg tea.Msg) (tea.Cmd, bool) {
 		m.syncForms(snapshot.Prompts)
 		return nil, false
 	}
+	prevID := m.activeID
 	prompt, targets, form, ok := m.promptStateFromSnapshot(snapshot)
 	if !ok {
 		return nil, false
 	}
 	switch key := msg.(type) {
 	case tea.KeyMsg:
+		if m.review != nil {
+			return m.updateReview(key, snapshot)
+		}
 		if m.inspect {
 			// handle inspect UI scrolling
 			switch key.String() {
 			case "i", "esc":
-				local := isLocalNode(snapshot.Nodes, prompt.NodeID)
+				local := util.IsLocalNode(snapshot.Nodes, prompt.NodeID)
 				cmd := m.toggleInspect(prompt, snapshot.Settings, local)
 				return cmd, true
 			case "tab", "shift+tab":
 				// let global tab navigation (view switching) work while inspecting
 				return nil, false
-			case "up", "pgup":
+			case "up", "k", "pgup":
 				m.inspectVP.LineUp(1)
 				return nil, true
-			case "down", "pgdown":
+			case "down", "j", "pgdown":
 				m.inspectVP.LineDown(1)
 				return nil, true
-			case "left":
+			case "left", "h":
 				m.adjustInspectX(-4)
 				return nil, true
-			case "right":
+			case "right", "l":
 				m.adjustInspectX(4)
 				return nil, true
+			case "1", "2", "3", "4", "5":
+				cmd, _ := m.panel.toggle(key.String())
+				m.refreshInspect()
+				return cmd, true
+			case "c":
+				return m.copyNextChecksum(prompt), true
+			case "v":
+				return m.copyVirusTotalURL(prompt), true
 			}
 			return nil, true
 		}
+		if m.focus == fieldDescription {
+			return m.updateDescription(key, prompt, targets, form, prevID)
+		}
 		switch key.String() {
 		case "i":
-			local := isLocalNode(snapshot.Nodes, prompt.NodeID)
+			local := util.IsLocalNode(snapshot.Nodes, prompt.NodeID)
 			cmd := m.toggleInspect(prompt, snapshot.Settings, local)
 			return cmd, true
-		case "down":
-			m.focus = (m.focus + 1) % 3
+		case "down", "j":
+			if m.focus == fieldConfirm {
+				m.focus = fieldAction
+			} else {
+				m.focus = (m.focus + 1) % fieldConfirm
+			}
 			return nil, true
-		case "up":
+		case "up", "k":
 			m.focus--
 			if m.focus < 0 {
-				m.focus = fieldTarget
+				m.focus = fieldDescription
 			}
 			return nil, true
-		case "left":
+		case "left", "p":
 			m.stepSelection(-1, form, len(targets))
 			return nil, true
-		case "right":
+		case "right", "n":
 			m.stepSelection(1, form, len(targets))
 			return nil, true
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			m.pickOption(int(key.Runes[0]-'1'), form, len(targets))
+			return nil, true
 		case "a":
 			form.action = 0
 			return nil, true
@@ -388,6 +495,26 @@ func (m *Model) Update(msg tea.Msg) (tea.Cmd, bool) {
 		case "r":
 			form.action = 2
 			return nil, true
+		case "R":
+			rule, found := m.disabledMatch(snapshot, prompt)
+			if !found || (prevID != "" && prevID != prompt.ID) {
+				return nil, true
+			}
+			m.reenable(prompt, rule, targets, form)
+			return nil, true
+		case "A", "D":
+			if prevID != "" && prevID != prompt.ID {
+				return nil, true
+			}
+			action := controller.PromptActionAllow
+			if key.String() == "D" {
+				action = controller.PromptActionDeny
+			}
+			m.resolveProcess(snapshot, prompt, targets, form, action)
+			return nil, true
+		case "L":
+			m.openReview()
+			return nil, true
 		case "[":
 			m.shiftPrompt(-1)
 			return nil, true
@@ -395,31 +522,41 @@ func (m *Model) Update(msg tea.Msg) (tea.Cmd, bool) {
 			m.shiftPrompt(1)
 			return nil, true
 		case "enter", "esc":
+			if key.String() == "esc" && m.focus == fieldConfirm {
+				m.focus = fieldAction
+				return nil, true
+			}
 			if m.inspect {
-				local := isLocalNode(snapshot.Nodes, prompt.NodeID)
+				local := util.IsLocalNode(snapshot.Nodes, prompt.NodeID)
 				cmd := m.toggleInspect(prompt, snapshot.Settings, local)
 				return cmd, true
 			}
+			if prevID != "" && prevID != prompt.ID {
+				// The prompt on screen went away since the last render; let
+				// the user see its replacement before anything is sent.
+				return nil, true
+			}
 			m.submit(prompt, targets, form)
 			return nil, true
 		}
+	case scanResultMsg:
+		if !m.inspect || key.promptID != m.activeID {
+			return nil, false
+		}
+		m.showScanResult(key.result)
+		return nil, true
+	case binaryResultMsg:
+		if !m.inspect || key.promptID != m.activeID {
+			return nil, false
+		}
+		m.showBinary(key.info, key.err)
+		return nil, true
 	case yaraResultMsg:
 		if !m.inspect || key.promptID != m.activeID {
 			return nil, false
 		}
 		m.yaraPending = false
-		if key.err != nil {
-			m.setYaraStatus(fmt.Sprintf("YARA: error: %v", key.err), yaraStatusError)
-		} else if len(key.result.Matches) == 0 {
-			m.setYaraStatus("YARA: no matches", yaraStatusNoMatches)
-		} else {
-			m.setYaraStatus(fmt.Sprintf("YARA: matches (%d)", len(key.result.Matches)), yaraStatusMatches)
-			lines := []string{m.theme.Danger.Render("YARA matches:")}
-			for _, match := range key.result.Matches {
-				lines = append(lines, m.theme.Danger.Render(" - "+match.Rule))
-			}
-			m.insertInspectLinesBefore(func(line string) bool { return strings.HasPrefix(line, "Process Tree:") }, lines...)
-		}
+		m.showYaraScans(key.scans, key.replaced, key.path)
 		return nil, true
 	}
 
@@ -436,6 +573,9 @@ func (m *Model) View() string {
 		return ""
 	}
 
+	if m.review != nil {
+		return m.renderReview(snapshot)
+	}
 	if m.inspect {
 		pauseOnInspect := snapshot.Settings.PausePromptOnInspect
 		cardW, innerW, innerH := m.computeInspectDimensions()
@@ -445,6 +585,9 @@ func (m *Model) View() string {
 			m.updateInspectContent()
 		}
 		statusLine := "[esc/i] back · scroll ↑/↓ ←/→"
+		if len(prompt.Connection.ProcessChecksums) > 0 {
+			statusLine += " · c copy checksum · v VirusTotal URL"
+		}
 		if pauseOnInspect {
 			statusLine += " · countdown paused"
 		} else {
@@ -452,22 +595,12 @@ func (m *Model) View() string {
 		}
 		header := []string{m.theme.Header.Render("Process inspection")}
 		if m.inspectRoot {
-			header = append(header, m.theme.Danger.Render("⚠ Root user"))
-		}
-		if m.yaraStatus != "" {
-			style := m.theme.Subtle
-			switch m.yaraKind {
-			case yaraStatusScanning:
-				style = m.theme.Warning
-			case yaraStatusNoMatches:
-				style = m.theme.Success
-			case yaraStatusMatches, yaraStatusError, yaraStatusNotAvailable, yaraStatusRuleDirMissing, yaraStatusPathUnknown, yaraStatusTimeout:
-				style = m.theme.Danger
-			case yaraStatusDisabled:
-				style = m.theme.Subtle
-			}
-			header = append(header, style.Render(m.yaraStatus))
+			header = append(header, m.theme.Danger.Render(glyphs.Current().Warning+" Root user"))
+		}
+		if m.status != "" {
+			header = append(header, m.status)
 		}
+		header = append(header, m.panel.legend(m.theme.TabActive, m.theme.Subtle))
 		body := lipgloss.JoinVertical(lipgloss.Left,
 			strings.Join(header, "\n"),
 			m.inspectVP.View(),
@@ -477,24 +610,68 @@ func (m *Model) View() string {
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Top, card.Render(body))
 	}
 
-	headline := fmt.Sprintf("Connection prompt · %s · node %s", prompt.ID, prompt.NodeName)
-	dest := prompt.Connection.DstHost
-	if dest == "" {
-		dest = prompt.Connection.DstIP
+	kind := "Connection"
+	if prompt.Connection.Inbound() {
+		kind = "Incoming connection"
+	}
+	headline := fmt.Sprintf("%s prompt · %s", kind, prompt.ID)
+	if pos, total := m.queuePosition(snapshot); total > 1 {
+		headline = fmt.Sprintf("Prompt %d/%d · %s · %s", pos, total, kind, prompt.ID)
+	}
+	title := m.theme.Header.Render(headline) + " " + m.theme.NodeBadge(state.NodeKeyOf(snapshot.Nodes, prompt.NodeID), "NODE "+prompt.NodeName)
+	if badge := m.expiringBadge(snapshot, m.promptIdx); badge != "" {
+		title += " " + badge
+	}
+	// The Events view is following this binary; make its prompts stand out.
+	if path := snapshot.FollowedPath; path != "" && prompt.Connection.ProcessPath == path {
+		title += " " + m.theme.TabActive.Render("followed")
 	}
+	cardWidth := min(m.width-4, 96)
 	command := strings.Join(prompt.Connection.ProcessArgs, " ")
-	info := []string{
+	var info []string
+	if prompt.Blocklist != nil {
+		info = append(info, m.theme.Danger.Render(blocklistLine(prompt)))
+	}
+	info = append(info,
 		fmt.Sprintf("Process: %s", util.Fallback(prompt.Connection.ProcessPath, "unknown")),
 		fmt.Sprintf("Command: %s", util.Fallback(command, "-")),
-		fmt.Sprintf("Destination: %s:%d (%s)", util.Fallback(dest, "unknown"), prompt.Connection.DstPort, prompt.Connection.Protocol),
-		fmt.Sprintf("User %d · PID %d", prompt.Connection.UserID, prompt.Connection.ProcessID),
+		destinationLine(prompt.Connection, cardWidth-m.theme.Card.GetHorizontalFrameSize()),
+	)
+	if prompt.Connection.Inbound() {
+		info = append(info, sourceLine(prompt.Connection))
+	}
+	info = append(info, ownerLine(prompt.Connection, snapshot.Settings.UIDZeroUnknown))
+	if util.IsLocalNode(snapshot.Nodes, prompt.NodeID) {
+		if ctr, ok := m.containers.Lookup(prompt.Connection.ProcessID, prompt.Connection.ProcessPath); ok {
+			info = append(info, fmt.Sprintf("Container: %s", ctr.Label()))
+		}
+	}
+	if rule, ok := m.disabledMatch(snapshot, prompt); ok {
+		hint := util.TruncateString(disabledRuleHint(rule), cardWidth-m.theme.Card.GetHorizontalFrameSize())
+		info = append(info, m.theme.Warning.Render(hint))
 	}
 
 	actionRow := m.renderChoices("Action", mapActionLabels(actionOptions), form.action, m.focus == fieldAction)
 	durationRow := m.renderChoices("Duration", mapDurationLabels(durationOptions), form.duration, m.focus == fieldDuration)
 	targetRow := m.renderChoices("Target", mapTargetLabels(targets), form.target, m.focus == fieldTarget)
+	previewRow := util.TruncateString(m.rulePreview(prompt, targets, form, snapshot.Settings.UIDZeroUnknown), cardWidth-m.theme.Card.GetHorizontalFrameSize())
+	descriptionRow := m.renderDescription(form, cardWidth-m.theme.Card.GetHorizontalFrameSize())
 
-	controls := m.theme.Subtle.Render("↑/↓ move · ←/→ change · enter confirm · i inspect · [/] cycle prompts")
+	help := "↑/↓ move · ←/→ change · enter confirm · i inspect · [/] cycle prompts · L review all"
+	switch m.focus {
+	case fieldConfirm:
+		help = "enter confirm defaults · ↓/esc edit · i inspect · [/] cycle prompts · L review all"
+	case fieldDescription:
+		help = descriptionHelp
+	}
+	if n := sameProcess(snapshot.Prompts, prompt); n > 1 && m.focus != fieldDescription {
+		help += fmt.Sprintf(" · A/D allow/deny all %d from this process", n)
+	}
+	var disabled []string
+	if m.controller == nil {
+		disabled = []string{"enter confirm", "A/D"}
+	}
+	controls := widget.RenderHelp(m.theme, help, disabled)
 	expiresAt := prompt.ExpiresAt
 	if expiresAt.IsZero() && !prompt.RequestedAt.IsZero() {
 		timeout := snapshot.Settings.PromptTimeout
@@ -518,19 +695,56 @@ func (m *Model) View() string {
 			}
 			status = fmt.Sprintf("Timeout in %s", remaining.Round(time.Second))
 		}
+		if m.controller == nil {
+			status = strings.TrimSpace(widget.RenderReadOnly(m.theme) + "\n" + status)
+		}
 	}
 
 	body := lipgloss.JoinVertical(lipgloss.Left,
-		m.theme.Header.Render(headline),
+		title,
 		strings.Join(info, "\n"),
 		actionRow,
 		durationRow,
 		targetRow,
+		previewRow,
+		descriptionRow,
 		controls,
 		status,
 	)
 
-	return lipgloss.Place(m.width, max(10, m.height-2), lipgloss.Center, lipgloss.Center, m.theme.Card.Width(min(m.width-4, 96)).Render(body))
+	return lipgloss.Place(m.width, max(10, m.height-2), lipgloss.Center, lipgloss.Center, m.theme.Card.Width(cardWidth).Render(body))
+}
+
+// destinationLine renders the destination summary, shortening long hostnames
+// with a middle ellipsis so the line never wraps inside a card of innerWidth.
+// The untruncated host stays available through the inspect view.
+func destinationLine(conn state.Connection, innerWidth int) string {
+	dest := conn.DstHost
+	if dest == "" {
+		dest = util.CompactIP(conn.DstIP)
+		if strings.Contains(dest, ":") {
+			dest = "[" + dest + "]"
+		}
+	}
+	dest = util.Fallback(dest, "unknown")
+	prefix := "Destination: "
+	proto := netnames.Protocol(conn.Protocol)
+	if name, ok := netnames.Service(conn.DstPort, conn.Protocol); ok {
+		proto = name + ", " + proto
+	}
+	suffix := fmt.Sprintf(":%d (%s)", conn.DstPort, proto)
+	if avail := innerWidth - util.RuneWidth(prefix) - util.RuneWidth(suffix); innerWidth > 0 && util.RuneWidth(dest) > avail {
+		dest = util.TruncateMiddle(dest, max(avail, 8))
+	}
+	return prefix + dest + suffix
+}
+
+// sourceLine names the peer of an inbound connection.
+func sourceLine(conn state.Connection) string {
+	if conn.SrcIP == "" {
+		return "Source: unknown"
+	}
+	return "Source: " + util.FormatEndpoint(conn.SrcIP, conn.SrcPort)
 }
 
 func (m *Model) promptStateFromSnapshot(snapshot state.Snapshot) (state.Prompt, []targetOption, *formState, bool) {
@@ -538,31 +752,61 @@ func (m *Model) promptStateFromSnapshot(snapshot state.Snapshot) (state.Prompt,
 	if len(snapshot.Prompts) == 0 {
 		return state.Prompt{}, nil, nil, false
 	}
-	if m.promptIdx >= len(snapshot.Prompts) {
-		m.promptIdx = len(snapshot.Prompts) - 1
-	}
-	if m.promptIdx < 0 {
-		m.promptIdx = 0
-	}
-	prompt := snapshot.Prompts[m.promptIdx]
-	if prompt.ID != m.activeID {
-		m.activeID = prompt.ID
+	idx := promptIndex(snapshot.Prompts, m.activeID)
+	if idx < 0 && m.activeID != "" {
+		// The prompt we were showing is gone; move on to the one closest
+		// to timing out.
 		m.status = ""
+		switch {
+		case withdrawn(snapshot.Decisions, m.activeID):
+			m.status = m.theme.Warning.Render("Prompt withdrawn — the daemon stopped waiting")
+		case m.activeID != m.submittedID:
+			m.status = m.theme.Warning.Render("Prompt resolved elsewhere")
+		}
 	}
-	targets := targetOptionsFor(prompt.Connection)
-	form := m.ensureForm(prompt.ID, targets)
+	m.promptIdx = m.selectPrompt(snapshot, idx)
+	prompt := snapshot.Prompts[m.promptIdx]
+	m.activeID = prompt.ID
+	targets := targetOptionsFor(prompt.Connection, snapshot.Settings.UIDZeroUnknown)
+	form := m.ensureForm(prompt, targets)
 	return prompt, targets, form, true
 }
 
-func (m *Model) ensureForm(id string, targets []targetOption) *formState {
-	form, ok := m.forms[id]
+// withdrawn reports whether the daemon gave up on prompt id before it was
+// answered.
+func withdrawn(decisions []state.Decision, id string) bool {
+	for _, decision := range decisions {
+		if decision.PromptID == id {
+			return decision.Source == state.DecisionSourceWithdrawn
+		}
+	}
+	return false
+}
+
+func promptIndex(prompts []state.Prompt, id string) int {
+	if id == "" {
+		return -1
+	}
+	for idx, prompt := range prompts {
+		if prompt.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (m *Model) ensureForm(prompt state.Prompt, targets []targetOption) *formState {
+	form, ok := m.forms[prompt.ID]
 	if !ok {
 		form = &formState{
-			action:   m.defaultActionIndex(),
-			duration: m.defaultDurationIndex(),
-			target:   m.defaultTargetIndex(targets),
+			action:      m.defaultActionIndex(),
+			duration:    m.defaultDurationIndex(),
+			target:      m.defaultTargetIndex(targets),
+			description: newDescriptionInput(prompt, m.store.Snapshot().Settings),
 		}
-		m.forms[id] = form
+		applyBlocklist(form, prompt, targets)
+		m.forms[prompt.ID] = form
+		m.focus = initialFocus(m.store.Snapshot().Settings.PromptInitialFocus)
 	}
 	if form.action >= len(actionOptions) {
 		form.action = len(actionOptions) - 1
@@ -579,6 +823,9 @@ func (m *Model) ensureForm(id string, targets []targetOption) *formState {
 }
 
 func (m *Model) syncForms(prompts []state.Prompt) {
+	if len(prompts) == 0 {
+		m.review = nil
+	}
 	if len(m.forms) == 0 {
 		return
 	}
@@ -593,6 +840,7 @@ func (m *Model) syncForms(prompts []state.Prompt) {
 	}
 	if len(prompts) == 0 {
 		m.promptIdx = 0
+		m.activeID = ""
 	}
 }
 
@@ -607,11 +855,40 @@ func (m *Model) stepSelection(delta int, form *formState, targets int) {
 	}
 }
 
+// pickOption selects option idx, counted from 0, of the focused field;
+// an index past the end of the list is ignored.
+func (m *Model) pickOption(idx int, form *formState, targets int) {
+	switch m.focus {
+	case fieldAction:
+		if idx < len(actionOptions) {
+			form.action = idx
+		}
+	case fieldDuration:
+		if idx < len(durationOptions) {
+			form.duration = idx
+		}
+	case fieldTarget:
+		if idx < targets {
+			form.target = idx
+		}
+	}
+}
+
 func (m *Model) submit(prompt state.Prompt, targets []targetOption, form *formState) {
-	if m.controller == nil {
-		m.status = m.theme.Danger.Render("Prompt controller unavailable")
+	if !widget.Guard(m.theme, m.controller != nil, &m.status) {
+		return
+	}
+	decision := formDecision(prompt, targets, form)
+	if err := m.controller.ResolvePrompt(decision); err != nil {
+		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to send decision: %v", err))
 		return
 	}
+	m.submittedID = prompt.ID
+	m.status = m.theme.Success.Render(fmt.Sprintf("Action %s for %s", decision.Action, prompt.NodeName))
+}
+
+// formDecision is the answer to prompt that form is set to.
+func formDecision(prompt state.Prompt, targets []targetOption, form *formState) controller.PromptDecision {
 	decision := controller.PromptDecision{
 		PromptID: prompt.ID,
 		Action:   actionOptions[min(form.action, len(actionOptions)-1)].value,
@@ -620,11 +897,8 @@ func (m *Model) submit(prompt state.Prompt, targets []targetOption, form *formSt
 	if len(targets) > 0 {
 		decision.Target = targets[min(form.target, len(targets)-1)].value
 	}
-	if err := m.controller.ResolvePrompt(decision); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to send decision: %v", err))
-		return
-	}
-	m.status = m.theme.Success.Render(fmt.Sprintf("Action %s for %s", decision.Action, prompt.NodeName))
+	decision.Description = strings.TrimSpace(form.description.Value())
+	return decision
 }
 
 func (m *Model) shiftPrompt(delta int) {
@@ -633,7 +907,17 @@ func (m *Model) shiftPrompt(delta int) {
 	if len(snapshot.Prompts) == 0 {
 		return
 	}
-	m.promptIdx = util.WrapIndex(m.promptIdx, delta, len(snapshot.Prompts))
+	order := expiryOrder(snapshot.Prompts, promptTimeout(snapshot.Settings), time.Now())
+	pos := 0
+	for i, idx := range order {
+		if snapshot.Prompts[idx].ID == m.activeID {
+			pos = i
+		}
+	}
+	m.promptIdx = order[util.WrapIndex(pos, delta, len(order))]
+	m.activeID = snapshot.Prompts[m.promptIdx].ID
+	m.pinned = true
+	m.status = ""
 }
 
 func (m *Model) renderChoices(label string, options []string, selected int, focused bool) string {
@@ -655,7 +939,24 @@ func (m *Model) renderChoices(label string, options []string, selected int, focu
 	return fmt.Sprintf("%s %s", m.theme.Header.Render(label+":"), strings.Join(cells, " "))
 }
 
-func targetOptionsFor(conn state.Connection) []targetOption {
+// ownerLine names the connection's user and process, saying so when the
+// daemon could not resolve them instead of showing a misleading 0.
+func ownerLine(conn state.Connection, uidZeroUnknown bool) string {
+	user, pid := "unknown", "unknown"
+	if conn.UIDKnown(uidZeroUnknown) {
+		user = fmt.Sprintf("%d", conn.UserID)
+	}
+	if conn.PIDKnown() {
+		pid = fmt.Sprintf("%d", conn.ProcessID)
+	}
+	return fmt.Sprintf("User %s · PID %s", user, pid)
+}
+
+// targetOptionsFor lists the targets conn has values for. A PID of 0, and a
+// UID of 0 when uidZeroUnknown is set, mean the daemon could not tell. For
+// inbound connections the destination is this host, so the peer's address
+// and the local port are offered instead.
+func targetOptionsFor(conn state.Connection, uidZeroUnknown bool) []targetOption {
 	options := make([]targetOption, 0, 6)
 	if conn.ProcessPath != "" {
 		options = append(options, targetOption{label: "Executable", value: controller.PromptTargetProcessPath})
@@ -663,17 +964,31 @@ func targetOptionsFor(conn state.Connection) []targetOption {
 	if len(conn.ProcessArgs) > 0 {
 		options = append(options, targetOption{label: "Command", value: controller.PromptTargetProcessCmd})
 	}
-	if conn.DstHost != "" {
-		options = append(options, targetOption{label: "Destination host", value: controller.PromptTargetDestinationHost})
+	switch {
+	case conn.Inbound():
+		if conn.SrcIP != "" {
+			options = append(options, targetOption{label: "Source IP", value: controller.PromptTargetSourceIP})
+		}
+		if conn.DstPort != 0 {
+			options = append(options, targetOption{label: "Local port", value: controller.PromptTargetDestinationPort})
+		}
+	default:
+		if conn.DstHost != "" {
+			options = append(options, targetOption{label: "Destination host", value: controller.PromptTargetDestinationHost})
+		}
+		if conn.DstIP != "" {
+			options = append(options, targetOption{label: "Destination IP", value: controller.PromptTargetDestinationIP})
+		}
+		if conn.DstPort != 0 {
+			options = append(options, targetOption{label: "Destination port", value: controller.PromptTargetDestinationPort})
+		}
 	}
-	if conn.DstIP != "" {
-		options = append(options, targetOption{label: "Destination IP", value: controller.PromptTargetDestinationIP})
+	if conn.PIDKnown() {
+		options = append(options, targetOption{label: "Process ID", value: controller.PromptTargetProcessID})
 	}
-	if conn.DstPort != 0 {
-		options = append(options, targetOption{label: "Destination port", value: controller.PromptTargetDestinationPort})
+	if conn.UIDKnown(uidZeroUnknown) {
+		options = append(options, targetOption{label: "User ID", value: controller.PromptTargetUserID})
 	}
-	options = append(options, targetOption{label: "Process ID", value: controller.PromptTargetProcessID})
-	options = append(options, targetOption{label: "User ID", value: controller.PromptTargetUserID})
 	return options
 }
 
@@ -741,6 +1056,9 @@ func (m *Model) shouldDisplayPrompts(snapshot state.Snapshot) bool {
 	if len(snapshot.Prompts) == 0 {
 		return false
 	}
+	if snapshot.Settings.DNDActive(time.Now()) {
+		return false
+	}
 	if snapshot.Settings.AlertsInterrupt {
 		return true
 	}
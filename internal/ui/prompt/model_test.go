@@ -0,0 +1,366 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestViewTruncatesLongDestinationHost(t *testing.T) {
+	host := strings.Repeat("a", 192) + ".cdn.net"
+	store := state.NewStore()
+	store.AddPrompt(state.Prompt{ID: "p1", NodeName: "local", Connection: state.Connection{
+		ProcessPath: "/usr/bin/curl",
+		DstHost:     host,
+		DstIP:       "203.0.113.7",
+		DstPort:     443,
+		Protocol:    "tcp",
+	}})
+	settings := store.Snapshot().Settings
+	settings.AlertsInterrupt = true
+	store.SetSettings(settings)
+
+	m := New(store, theme.New(theme.Options{}), nil)
+	m.SetSize(100, 30)
+
+	out := util.StripANSI(m.View())
+	for _, want := range []string{"Action:", "Duration:", "Target:", "enter confirm"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in prompt output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, host) {
+		t.Fatalf("expected destination host to be truncated")
+	}
+	if !strings.Contains(out, "…") || !strings.Contains(out, ":443 (https, tcp)") {
+		t.Fatalf("expected middle-ellipsis destination line, got:\n%s", out)
+	}
+	if lines := strings.Count(out, "\n") + 1; lines > 30 {
+		t.Fatalf("expected output to fit in 30 rows, got %d", lines)
+	}
+}
+
+type recordingPromptManager struct {
+	decisions []controller.PromptDecision
+}
+
+func (r *recordingPromptManager) ResolvePrompt(decision controller.PromptDecision) error {
+	r.decisions = append(r.decisions, decision)
+	return nil
+}
+func (r *recordingPromptManager) PausePrompt(string) error  { return nil }
+func (r *recordingPromptManager) ResumePrompt(string) error { return nil }
+
+func newTrackingModel(t *testing.T, ids ...string) (*Model, *state.Store, *recordingPromptManager) {
+	t.Helper()
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.AlertsInterrupt = true
+	settings.DefaultPromptAction = string(controller.PromptActionDeny)
+	store.SetSettings(settings)
+	for _, id := range ids {
+		store.AddPrompt(state.Prompt{ID: id, NodeName: "local", Connection: state.Connection{
+			ProcessPath: "/usr/bin/" + id,
+			DstIP:       "203.0.113.7",
+			DstPort:     443,
+			Protocol:    "tcp",
+		}})
+	}
+	ctrl := &recordingPromptManager{}
+	m := New(store, theme.New(theme.Options{}), ctrl)
+	m.SetSize(100, 30)
+	return m, store, ctrl
+}
+
+func pressKey(m *Model, key string) {
+	switch key {
+	case "enter":
+		m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	case "down":
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	case "esc":
+		m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	default:
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+}
+
+func TestPromptResolvedElsewhereDoesNotApplyForm(t *testing.T) {
+	m, store, ctrl := newTrackingModel(t, "curl", "wget")
+	m.View()
+	pressKey(m, "a") // allow on the curl form
+
+	store.RemovePrompt("curl")
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 0 {
+		t.Fatalf("expected no decision after the prompt vanished, got %+v", ctrl.decisions)
+	}
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "Prompt resolved elsewhere") || !strings.Contains(out, "/usr/bin/wget") {
+		t.Fatalf("expected replacement prompt with notice, got:\n%s", out)
+	}
+
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 {
+		t.Fatalf("expected one decision, got %+v", ctrl.decisions)
+	}
+	if got := ctrl.decisions[0]; got.PromptID != "wget" || got.Action != controller.PromptActionDeny {
+		t.Fatalf("expected wget resolved with its own defaults, got %+v", got)
+	}
+}
+
+func TestPromptReplacedSinceRenderIgnoresEveryAnswerKey(t *testing.T) {
+	for _, key := range []string{"A", "D", "description enter"} {
+		m, store, ctrl := newTrackingModel(t, "curl", "wget")
+		m.View()
+		if key == "description enter" {
+			m.focus = fieldDescription
+			key = "enter"
+		}
+
+		store.RemovePrompt("curl")
+		pressKey(m, key)
+		if len(ctrl.decisions) != 0 {
+			t.Fatalf("%s: expected nothing sent for a prompt not yet shown, got %+v", key, ctrl.decisions)
+		}
+	}
+}
+
+func TestPromptWithdrawnByDaemonExplainsSwitch(t *testing.T) {
+	m, store, ctrl := newTrackingModel(t, "curl", "wget")
+	m.View()
+	pressKey(m, "down") // focus the duration row, mid-decision
+	pressKey(m, "a")
+
+	// The daemon stops waiting for curl: it records the withdrawal and
+	// drops the prompt, as AskRule does on cancellation.
+	store.AddDecision(state.Decision{PromptID: "curl", Source: state.DecisionSourceWithdrawn, Outcome: state.OutcomeWithdrawn})
+	store.RemovePrompt("curl")
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "Prompt withdrawn — the daemon stopped waiting") || strings.Contains(out, "resolved elsewhere") {
+		t.Fatalf("expected the withdrawal explained, got:\n%s", out)
+	}
+	if !strings.Contains(out, "/usr/bin/wget") || len(ctrl.decisions) != 0 {
+		t.Fatalf("expected the next prompt shown and nothing sent, got %+v:\n%s", ctrl.decisions, out)
+	}
+}
+
+func TestPromptTrackedByIDWhenEarlierPromptResolves(t *testing.T) {
+	m, store, ctrl := newTrackingModel(t, "curl", "wget", "ssh")
+	pressKey(m, "]")
+	m.View()
+	pressKey(m, "a") // allow on the wget form
+
+	store.RemovePrompt("curl")
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "/usr/bin/wget") {
+		t.Fatalf("expected wget to stay on screen, got:\n%s", out)
+	}
+	if strings.Contains(out, "resolved elsewhere") {
+		t.Fatalf("did not expect a notice when the shown prompt is still pending")
+	}
+
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 {
+		t.Fatalf("expected one decision, got %+v", ctrl.decisions)
+	}
+	if got := ctrl.decisions[0]; got.PromptID != "wget" || got.Action != controller.PromptActionAllow {
+		t.Fatalf("expected the edited wget form to be submitted, got %+v", got)
+	}
+}
+
+func TestDestinationLineBracketsIPv6(t *testing.T) {
+	conn := state.Connection{DstIP: "2001:db8:0:0:0:0:0:1", DstPort: 443, Protocol: "tcp6"}
+	if got, want := destinationLine(conn, 80), "Destination: [2001:db8::1]:443 (https, tcp6)"; got != want {
+		t.Fatalf("destinationLine = %q, want %q", got, want)
+	}
+}
+
+func TestPromptHidesUnresolvedPIDAndUID(t *testing.T) {
+	labels := func(conn state.Connection, uidZeroUnknown bool) string {
+		return strings.Join(mapTargetLabels(targetOptionsFor(conn, uidZeroUnknown)), ",")
+	}
+	resolved := state.Connection{ProcessPath: "/usr/bin/curl", ProcessID: 4242, UserID: 1000}
+	if got := labels(resolved, true); !strings.Contains(got, "Process ID") || !strings.Contains(got, "User ID") {
+		t.Fatalf("expected pid and uid targets, got %s", got)
+	}
+	if got := ownerLine(resolved, true); got != "User 1000 · PID 4242" {
+		t.Fatalf("unexpected owner line %q", got)
+	}
+
+	unresolved := state.Connection{ProcessPath: "/usr/bin/curl"}
+	if got := labels(unresolved, false); strings.Contains(got, "Process ID") || !strings.Contains(got, "User ID") {
+		t.Fatalf("expected only the uid target (root), got %s", got)
+	}
+	if got := labels(unresolved, true); strings.Contains(got, "Process ID") || strings.Contains(got, "User ID") {
+		t.Fatalf("expected neither pid nor uid target, got %s", got)
+	}
+	if got := ownerLine(unresolved, true); got != "User unknown · PID unknown" {
+		t.Fatalf("unexpected owner line %q", got)
+	}
+
+	m, store, _ := newTrackingModel(t)
+	store.AddPrompt(state.Prompt{ID: "curl", NodeName: "local", Connection: unresolved})
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "User 0 · PID unknown") {
+		t.Fatalf("expected PID unknown in the prompt, got:\n%s", out)
+	}
+}
+
+func TestPromptInboundConnection(t *testing.T) {
+	conn := state.Connection{
+		Direction:   state.DirectionInbound,
+		ProcessPath: "/usr/sbin/sshd",
+		SrcIP:       "203.0.113.7",
+		SrcPort:     51000,
+		DstIP:       "192.168.1.5",
+		DstPort:     22,
+		ProcessID:   812,
+	}
+	got := strings.Join(mapTargetLabels(targetOptionsFor(conn, false)), ",")
+	if got != "Executable,Source IP,Local port,Process ID,User ID" {
+		t.Fatalf("unexpected inbound targets %s", got)
+	}
+
+	m, store, _ := newTrackingModel(t)
+	store.AddPrompt(state.Prompt{ID: "sshd", NodeName: "local", Connection: conn})
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "Incoming connection prompt") || !strings.Contains(out, "Source: 203.0.113.7:51000") {
+		t.Fatalf("expected an incoming headline and source line, got:\n%s", out)
+	}
+}
+
+func TestPromptInitialFocus(t *testing.T) {
+	cases := map[string]field{
+		"action":   fieldAction,
+		"duration": fieldDuration,
+		"target":   fieldTarget,
+		"confirm":  fieldConfirm,
+		"":         fieldAction,
+	}
+	for setting, want := range cases {
+		m, store, _ := newTrackingModel(t)
+		settings := store.Snapshot().Settings
+		settings.PromptInitialFocus = setting
+		store.SetSettings(settings)
+		store.AddPrompt(state.Prompt{ID: "curl", NodeName: "local", Connection: state.Connection{ProcessPath: "/usr/bin/curl"}})
+
+		out := util.StripANSI(m.View())
+		if m.focus != want {
+			t.Errorf("%q: focus = %d, want %d", setting, m.focus, want)
+		}
+		confirmHelp := strings.Contains(out, "enter confirm defaults")
+		if confirmHelp != (want == fieldConfirm) {
+			t.Errorf("%q: confirm help shown = %v", setting, confirmHelp)
+		}
+	}
+}
+
+func TestPromptConfirmFocusSubmitsDefaults(t *testing.T) {
+	m, store, ctrl := newTrackingModel(t)
+	settings := store.Snapshot().Settings
+	settings.PromptInitialFocus = "confirm"
+	store.SetSettings(settings)
+	store.AddPrompt(state.Prompt{ID: "curl", NodeName: "local", Connection: state.Connection{ProcessPath: "/usr/bin/curl"}})
+	m.View()
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRight}) // fields are skipped while confirming
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 || ctrl.decisions[0].Action != controller.PromptActionDeny {
+		t.Fatalf("expected the default deny to be submitted, got %+v", ctrl.decisions)
+	}
+}
+
+func TestPromptConfirmFocusEscEntersForm(t *testing.T) {
+	m, store, ctrl := newTrackingModel(t)
+	settings := store.Snapshot().Settings
+	settings.PromptInitialFocus = "confirm"
+	store.SetSettings(settings)
+	store.AddPrompt(state.Prompt{ID: "curl", NodeName: "local", Connection: state.Connection{ProcessPath: "/usr/bin/curl"}})
+	m.View()
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(ctrl.decisions) != 0 {
+		t.Fatalf("expected esc not to submit from confirm focus, got %+v", ctrl.decisions)
+	}
+	if m.focus != fieldAction {
+		t.Fatalf("expected esc to move into the form, got focus %d", m.focus)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRight}) // deny -> reject
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 || ctrl.decisions[0].Action != controller.PromptActionReject {
+		t.Fatalf("expected the edited action to be submitted, got %+v", ctrl.decisions)
+	}
+}
+
+func TestPromptBadgesFollowedProcess(t *testing.T) {
+	store := state.NewStore()
+	store.AddPrompt(state.Prompt{ID: "p1", NodeName: "local", Connection: state.Connection{ProcessPath: "/usr/bin/curl"}})
+	m := New(store, theme.New(theme.Options{}), nil)
+	m.SetSize(120, 30)
+
+	if strings.Contains(util.StripANSI(m.View()), "followed") {
+		t.Fatalf("expected no badge while nothing is followed")
+	}
+	store.SetFollowedPath("/usr/bin/curl")
+	if !strings.Contains(util.StripANSI(m.View()), "followed") {
+		t.Fatalf("expected a badge for the followed process")
+	}
+	store.SetFollowedPath("/usr/bin/dig")
+	if strings.Contains(util.StripANSI(m.View()), "followed") {
+		t.Fatalf("expected no badge for another process")
+	}
+}
+
+func TestPromptFormWithoutArrowKeys(t *testing.T) {
+	m, _, ctrl := newTrackingModel(t, "curl")
+	m.View()
+
+	pressKey(m, "n") // deny -> reject
+	pressKey(m, "p") // reject -> deny
+	pressKey(m, "p") // deny -> allow
+	if m.focus != fieldAction {
+		t.Fatalf("expected n/p to leave focus on the action, got %d", m.focus)
+	}
+	pressKey(m, "j")
+	if m.focus != fieldDuration {
+		t.Fatalf("expected j to focus the duration, got %d", m.focus)
+	}
+	pressKey(m, "3") // Always
+	pressKey(m, "9") // past the end: ignored
+	pressKey(m, "k")
+	if m.focus != fieldAction {
+		t.Fatalf("expected k to focus the action, got %d", m.focus)
+	}
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 {
+		t.Fatalf("expected one decision, got %+v", ctrl.decisions)
+	}
+	got := ctrl.decisions[0]
+	if got.Action != controller.PromptActionAllow || got.Duration != controller.PromptDurationAlways {
+		t.Fatalf("expected allow always, got %+v", got)
+	}
+}
+
+func TestPromptNumberKeysPickOption(t *testing.T) {
+	m, _, ctrl := newTrackingModel(t, "curl")
+	m.View()
+
+	pressKey(m, "3") // Reject
+	pressKey(m, "enter")
+	if len(ctrl.decisions) != 1 || ctrl.decisions[0].Action != controller.PromptActionReject {
+		t.Fatalf("expected 3 to pick reject, got %+v", ctrl.decisions)
+	}
+}
+
+func TestDestinationLineNamesProtocolNumbers(t *testing.T) {
+	conn := state.Connection{DstHost: "example.com", DstPort: 40123, Protocol: "17"}
+	if got, want := destinationLine(conn, 80), "Destination: example.com:40123 (udp)"; got != want {
+		t.Fatalf("destinationLine = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package prompt
+
+import (
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// previewLine renders the rule answering a prompt about conn with
+// decision creates, as "simple process.path /usr/bin/curl · allow ·
+// always", or why none can be made.
+func previewLine(conn state.Connection, decision controller.PromptDecision, uidZeroUnknown bool) (string, bool) {
+	decision, operator, err := decision.Resolve(conn, uidZeroUnknown)
+	if err != nil {
+		return "no rule: " + err.Error(), false
+	}
+	return strings.Join([]string{operator.Type + " " + operator.Operand + " " + operator.Data, string(decision.Action), string(decision.Duration)}, " · "), true
+}
+
+// rulePreview is the card's line under the target row, following the
+// form as it changes.
+func (m *Model) rulePreview(prompt state.Prompt, targets []targetOption, form *formState, uidZeroUnknown bool) string {
+	line, ok := previewLine(prompt.Connection, formDecision(prompt, targets, form), uidZeroUnknown)
+	label := m.theme.Header.Render("Rule:")
+	if !ok {
+		return label + " " + m.theme.Danger.Render(line)
+	}
+	return label + " " + m.theme.Subtle.Render(line)
+}
@@ -0,0 +1,94 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestPreviewLineForEachTarget(t *testing.T) {
+	full := state.Connection{
+		ProcessPath: "/usr/bin/curl",
+		ProcessArgs: []string{"curl", "-s", "example.com"},
+		ProcessID:   4242,
+		UserID:      1000,
+		DstHost:     "example.com",
+		DstIP:       "93.184.216.34",
+		DstPort:     443,
+		Protocol:    "tcp",
+	}
+	noPath := full
+	noPath.ProcessPath, noPath.ProcessArgs = "", nil
+	noArgs := full
+	noArgs.ProcessArgs = nil
+	unresolved := full
+	unresolved.ProcessID, unresolved.UserID = 0, 0
+	inbound := state.Connection{ProcessPath: "/usr/sbin/sshd", SrcIP: "203.0.113.9", SrcPort: 51000, DstIP: "192.168.1.2", DstPort: 22, Protocol: "tcp", Direction: "in"}
+
+	allowAlways := controller.PromptDecision{Action: controller.PromptActionAllow, Duration: controller.PromptDurationAlways}
+	with := func(target controller.PromptTarget) controller.PromptDecision {
+		d := allowAlways
+		d.Target = target
+		return d
+	}
+	cases := []struct {
+		name     string
+		conn     state.Connection
+		decision controller.PromptDecision
+		want     string
+		ok       bool
+	}{
+		{"process path", full, with(controller.PromptTargetProcessPath), "simple process.path /usr/bin/curl · allow · always", true},
+		{"command", full, with(controller.PromptTargetProcessCmd), "simple process.command curl -s example.com · allow · always", true},
+		{"command falls back to the path", noArgs, with(controller.PromptTargetProcessCmd), "simple process.path /usr/bin/curl · allow · always", true},
+		{"host", full, with(controller.PromptTargetDestinationHost), "simple dest.host example.com · allow · always", true},
+		{"ip", full, with(controller.PromptTargetDestinationIP), "simple dest.ip 93.184.216.34 · allow · always", true},
+		{"port", full, with(controller.PromptTargetDestinationPort), "simple dest.port 443 · allow · always", true},
+		{"pid", full, with(controller.PromptTargetProcessID), "simple process.id 4242 · allow · always", true},
+		{"uid", full, with(controller.PromptTargetUserID), "simple user.id 1000 · allow · always", true},
+		{"source ip", inbound, with(controller.PromptTargetSourceIP), "simple source.ip 203.0.113.9 · allow · always", true},
+		{"no target picks the executable", full, allowAlways, "simple process.path /usr/bin/curl · allow · always", true},
+		{"no target and no path picks the host", noPath, allowAlways, "simple dest.host example.com · allow · always", true},
+		{"missing path", noPath, with(controller.PromptTargetProcessPath), "no rule: process path unavailable", false},
+		{"missing command and path", noPath, with(controller.PromptTargetProcessCmd), "no rule: command line unavailable", false},
+		{"unresolved pid", unresolved, with(controller.PromptTargetProcessID), "no rule: process id unknown: the daemon could not resolve the process", false},
+		{"uid zero unknown", unresolved, with(controller.PromptTargetUserID), "no rule: user id unknown: the daemon could not resolve the owner", false},
+		{"unknown action and duration", full, controller.PromptDecision{Action: "maybe", Duration: "forever", Target: controller.PromptTargetDestinationPort}, "simple dest.port 443 · deny · once", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := previewLine(tc.conn, tc.decision, true)
+			if got != tc.want || ok != tc.ok {
+				t.Fatalf("expected %q (%v), got %q (%v)", tc.want, tc.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestPromptCardPreviewFollowsTheForm(t *testing.T) {
+	m, _, _ := newTrackingModel(t, "curl")
+	previewOf := func() string {
+		for _, line := range strings.Split(util.StripANSI(m.View()), "\n") {
+			if i := strings.Index(line, "Rule: "); i >= 0 {
+				return strings.TrimRight(strings.Trim(line[i+len("Rule: "):], " │"), " ")
+			}
+		}
+		t.Fatalf("expected a rule preview on the card")
+		return ""
+	}
+	if got := previewOf(); got != "simple process.path /usr/bin/curl · deny · once" {
+		t.Fatalf("unexpected preview %q", got)
+	}
+	m.focus = fieldAction
+	pressKey(m, "a")
+	m.focus = fieldDuration
+	pressKey(m, "right")
+	m.focus = fieldTarget
+	pressKey(m, "n")
+	if got := previewOf(); got != "simple dest.ip 203.0.113.7 · allow · until restart" {
+		t.Fatalf("expected the preview to follow the form, got %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procRoot is where the process readers look, and readProcFile how they
+// read; tests point both at a fake tree.
+var (
+	procRoot     = "/proc"
+	readProcFile = os.ReadFile
+)
+
+// procFailure says why a process's /proc entry could not be read.
+type procFailure int
+
+const (
+	procFailed procFailure = iota
+	// procRestricted: the entry is hidden or denied, as under hidepid.
+	procRestricted
+	// procGone: the process has exited.
+	procGone
+	// procUnmounted: there is no proc filesystem at all.
+	procUnmounted
+)
+
+// procError is a failure to read a process's /proc entry, classified so
+// the inspect panel can say why details are missing.
+type procError struct {
+	failure procFailure
+	err     error
+}
+
+func (e *procError) Error() string {
+	switch e.failure {
+	case procRestricted:
+		return "/proc restricted (hidepid?)"
+	case procGone:
+		return "process has exited"
+	case procUnmounted:
+		return "/proc not mounted"
+	}
+	return e.err.Error()
+}
+
+func (e *procError) Unwrap() error { return e.err }
+
+// classifyProc turns an error reading a process entry into a procError.
+// hidepid=2 makes other users' processes look like they do not exist, so a
+// missing entry counts as restricted when proc is mounted with hidepid.
+func classifyProc(err error) error {
+	if err == nil {
+		return nil
+	}
+	failure := procFailed
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		failure = procRestricted
+	case errors.Is(err, fs.ErrNotExist):
+		switch {
+		case !procMounted():
+			failure = procUnmounted
+		case hidepidActive():
+			failure = procRestricted
+		default:
+			failure = procGone
+		}
+	}
+	return &procError{failure: failure, err: err}
+}
+
+// procMounted reports whether procRoot holds a proc filesystem; /proc/self
+// is there whatever hidepid says.
+func procMounted() bool {
+	_, err := os.Stat(filepath.Join(procRoot, "self"))
+	return err == nil
+}
+
+// hidepidActive reports whether /proc is mounted with a hidepid option
+// that hides other users' processes.
+func hidepidActive() bool {
+	data, err := readProcFile(filepath.Join(procRoot, "self", "mountinfo"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[4] != "/proc" {
+			continue
+		}
+		// Super block options follow the "-" separator and the fs type and source.
+		_, after, ok := strings.Cut(line, " - ")
+		if !ok {
+			continue
+		}
+		rest := strings.Fields(after)
+		if len(rest) < 3 {
+			continue
+		}
+		for _, opt := range strings.Split(rest[2], ",") {
+			if value, ok := strings.CutPrefix(opt, "hidepid="); ok && value != "0" && value != "off" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkProc reads pid's status to find out whether its /proc entry can be
+// read at all.
+func checkProc(pid int) error {
+	_, err := readProcFile(filepath.Join(procRoot, strconv.Itoa(pid), "status"))
+	return classifyProc(err)
+}
+
+// procUnavailable is the line shown in place of details /proc withheld.
+func procUnavailable(err error) string {
+	return "process details unavailable: " + err.Error()
+}
@@ -0,0 +1,128 @@
+package prompt
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+const procMountinfo = "22 26 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw%s\n"
+
+// fakeProc points the process readers at a temporary proc tree. mounted
+// adds /proc/self with a mountinfo carrying opts; files maps paths below
+// the root to their contents.
+func fakeProc(t *testing.T, mounted bool, opts string, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	if mounted {
+		files["self/mountinfo"] = strings.ReplaceAll(procMountinfo, "%s", opts)
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prevRoot, prevRead := procRoot, readProcFile
+	procRoot = root
+	t.Cleanup(func() { procRoot, readProcFile = prevRoot, prevRead })
+	return root
+}
+
+// denyProc makes reads below pid's directory fail as hidepid=1 does; the
+// tests may run as root, which file modes would not stop.
+func denyProc(root, pid string) {
+	readProcFile = func(name string) ([]byte, error) {
+		if strings.HasPrefix(name, filepath.Join(root, pid)+string(filepath.Separator)) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+		}
+		return os.ReadFile(name)
+	}
+}
+
+// inspectLines renders the identity and /proc backed sections for pid 4242.
+func inspectLines(t *testing.T) map[string][]string {
+	t.Helper()
+	m := New(state.NewStore(), theme.New(theme.Options{}), nil)
+	m.containers = nil
+	prompt := state.Prompt{ID: "p", Connection: state.Connection{ProcessID: 4242, ProcessPath: "/usr/bin/curl"}}
+	out := make(map[string][]string)
+	for _, section := range m.inspectSections(prompt, state.Settings{}, true) {
+		switch section.id {
+		case "identity", "tree", "sockets", "env":
+			out[section.id] = section.lines()
+		}
+	}
+	return out
+}
+
+func TestProcFailuresAreExplained(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  string
+	}{
+		{"permission denied", func(t *testing.T) {
+			root := fakeProc(t, true, ",hidepid=1", map[string]string{"4242/status": "Uid:\t1000\t1000\t1000\t1000\n"})
+			denyProc(root, "4242")
+		}, "process details unavailable: /proc restricted (hidepid?)"},
+		{"hidden by hidepid=2", func(t *testing.T) {
+			fakeProc(t, true, ",hidepid=invisible", map[string]string{})
+		}, "process details unavailable: /proc restricted (hidepid?)"},
+		{"exited", func(t *testing.T) {
+			fakeProc(t, true, "", map[string]string{})
+		}, "process details unavailable: process has exited"},
+		{"not mounted", func(t *testing.T) {
+			fakeProc(t, false, "", map[string]string{})
+		}, "process details unavailable: /proc not mounted"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setup(t)
+			sections := inspectLines(t)
+			identity := strings.Join(sections["identity"], "\n")
+			if !strings.Contains(identity, tc.want) {
+				t.Fatalf("expected %q in identity, got %q", tc.want, sections["identity"])
+			}
+			if strings.Contains(identity, "Group") || strings.Contains(identity, "User (effective)") {
+				t.Fatalf("expected no partial ID lines, got %q", sections["identity"])
+			}
+			for _, id := range []string{"tree", "sockets", "env"} {
+				if got := sections[id]; len(got) != 1 || got[0] != tc.want {
+					t.Fatalf("expected %s to be skipped with %q, got %q", id, tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestProcReadersReadFakeRoot(t *testing.T) {
+	fakeProc(t, true, "", map[string]string{
+		"4242/status":             "Name:\tcurl\nUid:\t1000\t0\t0\t0\nGid:\t1000\t1000\t1000\t1000\n",
+		"4242/stat":               "4242 (curl) S 1 4242 4242 0 -1",
+		"4242/cmdline":            "curl\x00example.com\x00",
+		"4242/task/4242/children": "4243",
+		"4243/stat":               "4243 (sh) S 4242 4242 4242 0 -1",
+		"4243/status":             "Name:\tsh\n",
+		"4243/task/4243/children": "",
+	})
+	uids, gids, err := readProcIDs(4242)
+	if err != nil || uids[1] != "0" || gids[0] != "1000" {
+		t.Fatalf("unexpected IDs %v %v %v", uids, gids, err)
+	}
+	tree, err := readProcessTree(4242, nil)
+	if err != nil || len(tree) != 2 || !strings.Contains(tree[0], "4242 curl curl example.com") || !strings.Contains(tree[1], "4243 sh") {
+		t.Fatalf("unexpected tree %q (%v)", tree, err)
+	}
+	if err := checkProc(4242); err != nil {
+		t.Fatalf("expected a readable entry, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package prompt
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// tcpStates names the kernel's TCP states as /proc/net/tcp numbers them.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// readProcSockets lists the inet sockets pid holds open, matching the
+// socket inodes of its descriptors against the tables in its network
+// namespace. Other sockets (unix, netlink, ...) are only counted.
+func readProcSockets(pid int) []string {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	entries, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		return []string{fmt.Sprintf("Sockets unreadable: %v", err)}
+	}
+	inodes := make(map[string]bool)
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(dir, "fd", entry.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			inodes[strings.TrimSuffix(inode, "]")] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return []string{"No open sockets"}
+	}
+	var lines []string
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		data, err := readProcFile(filepath.Join(dir, "net", proto))
+		if err != nil {
+			continue
+		}
+		lines = append(lines, parseProcNet(proto, string(data), inodes)...)
+	}
+	if other := len(inodes); other > 0 {
+		lines = append(lines, fmt.Sprintf("%d other sockets (unix, netlink, ...)", other))
+	}
+	return lines
+}
+
+// parseProcNet formats the rows of a /proc/net/{tcp,udp}[6] table whose
+// inode is in inodes, removing the ones it found.
+func parseProcNet(proto, table string, inodes map[string]bool) []string {
+	var lines []string
+	for _, row := range strings.Split(table, "\n")[1:] {
+		fields := strings.Fields(row)
+		if len(fields) < 10 || !inodes[fields[9]] {
+			continue
+		}
+		delete(inodes, fields[9])
+		local, lok := decodeProcAddr(fields[1])
+		remote, rok := decodeProcAddr(fields[2])
+		if !lok || !rok {
+			continue
+		}
+		line := fmt.Sprintf("%-4s %s", strings.TrimSuffix(proto, "6"), local)
+		if strings.Trim(fields[2], "0:") != "" {
+			line += " → " + remote
+		}
+		if state, ok := tcpStates[fields[3]]; ok && strings.HasPrefix(proto, "tcp") {
+			line += " " + state
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// decodeProcAddr turns "0100007F:0035" into "127.0.0.1:53". Addresses are
+// written as 32-bit words in host (little-endian) order.
+func decodeProcAddr(s string) (string, bool) {
+	addrHex, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", false
+	}
+	raw, err := hex.DecodeString(addrHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", false
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return "", false
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	return util.FormatEndpoint(ip.String(), uint32(port)), true
+}
+
+// readProcEnviron lists the environment pid started with. Reading another
+// user's environment needs privileges, which the error line says.
+func readProcEnviron(pid int) []string {
+	data, err := readProcFile(filepath.Join(procRoot, strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return []string{fmt.Sprintf("Environment unreadable: %v", err)}
+	}
+	var lines []string
+	for _, kv := range strings.Split(string(data), "\x00") {
+		if kv != "" {
+			lines = append(lines, sanitizeOutput(kv))
+		}
+	}
+	return lines
+}
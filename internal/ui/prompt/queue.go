@@ -0,0 +1,119 @@
+package prompt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+)
+
+// queuePosition returns where the shown prompt is in the [/] order, counted
+// from 1, and how many prompts are pending.
+func (m *Model) queuePosition(snapshot state.Snapshot) (int, int) {
+	order := expiryOrder(snapshot.Prompts, promptTimeout(snapshot.Settings), time.Now())
+	return slices.Index(order, m.promptIdx) + 1, len(order)
+}
+
+// sameProcess counts the pending prompts from the process of prompt,
+// prompt included.
+func sameProcess(prompts []state.Prompt, prompt state.Prompt) int {
+	if prompt.Connection.ProcessPath == "" {
+		return 0
+	}
+	n := 0
+	for _, other := range prompts {
+		if other.Connection.ProcessPath == prompt.Connection.ProcessPath {
+			n++
+		}
+	}
+	return n
+}
+
+// resolveProcess answers every pending prompt from the shown prompt's
+// process with action and the card's duration and target, closest to
+// timing out first. A prompt that does not offer the card's target keeps
+// its own. Prompts that time out before their turn are counted rather
+// than stopping the others.
+func (m *Model) resolveProcess(snapshot state.Snapshot, prompt state.Prompt, targets []targetOption, form *formState, action controller.PromptAction) {
+	if !widget.Guard(m.theme, m.controller != nil, &m.status) {
+		return
+	}
+	path := prompt.Connection.ProcessPath
+	if path == "" {
+		m.status = m.theme.Warning.Render("Process path unknown; answer this prompt on its own")
+		return
+	}
+	form.action = slices.IndexFunc(actionOptions, func(opt actionOption) bool { return opt.value == action })
+	var target controller.PromptTarget
+	if len(targets) > 0 {
+		target = targets[min(form.target, len(targets)-1)].value
+	}
+
+	var resolved, expired, failed int
+	var firstErr error
+	rows := m.reviewRows(snapshot, time.Now())
+	for _, row := range rows {
+		if row.prompt.Connection.ProcessPath != path {
+			continue
+		}
+		decision := row.decision()
+		decision.Action = action
+		decision.Duration = durationOptions[min(form.duration, len(durationOptions)-1)].value
+		if slices.ContainsFunc(row.targets, func(opt targetOption) bool { return opt.value == target }) {
+			decision.Target = target
+		}
+		if err := m.controller.ResolvePrompt(decision); err != nil {
+			if !pending(m.store.Snapshot().Prompts, row.prompt.ID) {
+				expired++
+				continue
+			}
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		resolved++
+		if row.prompt.ID == m.activeID {
+			m.submittedID = row.prompt.ID
+		}
+	}
+
+	summary := fmt.Sprintf("%s %d %s from %s", resolvedVerb(action), resolved, plural(resolved, "prompt"), path)
+	var notes []string
+	if expired > 0 {
+		notes = append(notes, fmt.Sprintf("%d expired first", expired))
+	}
+	if failed > 0 {
+		notes = append(notes, fmt.Sprintf("%d failed: %v", failed, firstErr))
+		m.status = m.theme.Danger.Render(summary + "; " + strings.Join(notes, "; "))
+		return
+	}
+	if len(notes) > 0 {
+		m.status = m.theme.Warning.Render(summary + "; " + strings.Join(notes, "; "))
+		return
+	}
+	m.status = m.theme.Success.Render(summary)
+}
+
+func pending(prompts []state.Prompt, id string) bool {
+	return promptIndex(prompts, id) >= 0
+}
+
+func resolvedVerb(action controller.PromptAction) string {
+	if action == controller.PromptActionAllow {
+		return "Allowed"
+	}
+	return "Denied"
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
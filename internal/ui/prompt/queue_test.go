@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// expiringPromptManager resolves prompts in the store, except that the
+// prompts in expire time out just before their answer arrives and those
+// in refuse stay pending with an error.
+type expiringPromptManager struct {
+	store     *state.Store
+	expire    map[string]bool
+	refuse    map[string]bool
+	decisions []controller.PromptDecision
+}
+
+func (f *expiringPromptManager) ResolvePrompt(decision controller.PromptDecision) error {
+	switch {
+	case f.expire[decision.PromptID]:
+		f.store.RemovePrompt(decision.PromptID)
+		return errors.New("prompt " + decision.PromptID + " not found")
+	case f.refuse[decision.PromptID]:
+		return errors.New("daemon unreachable")
+	}
+	f.decisions = append(f.decisions, decision)
+	f.store.RemovePrompt(decision.PromptID)
+	return nil
+}
+func (f *expiringPromptManager) PausePrompt(string) error  { return nil }
+func (f *expiringPromptManager) ResumePrompt(string) error { return nil }
+
+// queueModel queues curl-1..3 and wget-1..2, oldest first; curl-3 has no
+// host, so it cannot take a host target.
+func queueModel(t *testing.T, ctrl *expiringPromptManager) (*Model, *state.Store) {
+	t.Helper()
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.AlertsInterrupt = true
+	settings.DefaultPromptAction = string(controller.PromptActionDeny)
+	settings.DefaultPromptTarget = string(controller.PromptTargetProcessPath)
+	store.SetSettings(settings)
+	now := time.Now()
+	for i, id := range []string{"curl-1", "wget-1", "curl-2", "curl-3", "wget-2"} {
+		conn := state.Connection{ProcessPath: "/usr/bin/" + id[:4], DstHost: id + ".example.com", DstIP: "203.0.113.7", DstPort: 443, Protocol: "tcp"}
+		if id == "curl-3" {
+			conn.DstHost = ""
+		}
+		store.AddPrompt(state.Prompt{ID: id, NodeName: "local", RequestedAt: now.Add(time.Duration(i-10) * time.Second), Connection: conn})
+	}
+	ctrl.store = store
+	m := New(store, theme.New(theme.Options{}), ctrl)
+	m.SetSize(120, 30)
+	return m, store
+}
+
+func TestPromptHeadlineShowsQueuePosition(t *testing.T) {
+	m, store := queueModel(t, &expiringPromptManager{})
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "Prompt 1/5 · Connection · curl-1") || !strings.Contains(out, "allow/deny all 3 from this process") {
+		t.Fatalf("expected the first of five prompts, got:\n%s", out)
+	}
+	pressKey(m, "]")
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "Prompt 2/5 · Connection · wget-1") || !strings.Contains(out, "all 2 from this process") {
+		t.Fatalf("expected the second of five prompts, got:\n%s", out)
+	}
+	for _, id := range []string{"curl-1", "curl-2", "curl-3", "wget-2"} {
+		store.RemovePrompt(id)
+	}
+	if out := util.StripANSI(m.View()); strings.Contains(out, "Prompt 1/1") || strings.Contains(out, "A/D") || !strings.Contains(out, "Connection prompt · wget-1") {
+		t.Fatalf("expected no queue position for a lone prompt, got:\n%s", out)
+	}
+}
+
+func TestPromptResolveProcessAppliesTheCard(t *testing.T) {
+	ctrl := &expiringPromptManager{expire: map[string]bool{"curl-2": true}}
+	m, store := queueModel(t, ctrl)
+	// Always, by destination host: curl-3 has no host and keeps its own target.
+	m.View()
+	m.focus = fieldDuration
+	pressKey(m, "3")
+	m.focus = fieldTarget
+	pressKey(m, "2")
+	pressKey(m, "A")
+
+	if len(ctrl.decisions) != 2 {
+		t.Fatalf("expected curl-1 and curl-3 answered, got %+v", ctrl.decisions)
+	}
+	first, second := ctrl.decisions[0], ctrl.decisions[1]
+	if first.PromptID != "curl-1" || first.Action != controller.PromptActionAllow || first.Duration != controller.PromptDurationAlways || first.Target != controller.PromptTargetDestinationHost {
+		t.Fatalf("expected curl-1 allowed always by host, got %+v", first)
+	}
+	if second.PromptID != "curl-3" || second.Duration != controller.PromptDurationAlways || second.Target != controller.PromptTargetProcessPath {
+		t.Fatalf("expected curl-3 by its own target, got %+v", second)
+	}
+	if got := util.StripANSI(m.status); got != "Allowed 2 prompts from /usr/bin/curl; 1 expired first" {
+		t.Fatalf("unexpected status %q", got)
+	}
+	if got := len(store.Snapshot().Prompts); got != 2 {
+		t.Fatalf("expected the wget prompts still pending, got %d", got)
+	}
+}
+
+func TestPromptResolveProcessKeepsGoingPastFailures(t *testing.T) {
+	ctrl := &expiringPromptManager{refuse: map[string]bool{"curl-1": true}}
+	m, store := queueModel(t, ctrl)
+	pressKey(m, "D")
+
+	if len(ctrl.decisions) != 2 || ctrl.decisions[0].PromptID != "curl-2" || ctrl.decisions[1].Action != controller.PromptActionDeny {
+		t.Fatalf("expected curl-2 and curl-3 denied, got %+v", ctrl.decisions)
+	}
+	if got := util.StripANSI(m.status); got != "Denied 2 prompts from /usr/bin/curl; 1 failed: daemon unreachable" {
+		t.Fatalf("unexpected status %q", got)
+	}
+	if got := len(store.Snapshot().Prompts); got != 3 {
+		t.Fatalf("expected curl-1 and the wget prompts pending, got %d", got)
+	}
+}
@@ -0,0 +1,227 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/components/table"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const reviewHelp = "↑/↓ move · a/d/r action · o/u/A duration · t target · enter answer all · esc/L back"
+
+// reviewChrome is the rows of the review card around its table.
+const reviewChrome = 9
+
+// reviewState is the open batch review of the pending prompts.
+type reviewState struct {
+	cursor int
+	offset int
+	// status reports the last batch; the card's status is reset whenever
+	// its prompt resolves.
+	status string
+}
+
+// reviewRow is one pending prompt with the proposal that answers it. The
+// form is the prompt's card form, so changes made in either place stick.
+type reviewRow struct {
+	prompt  state.Prompt
+	targets []targetOption
+	form    *formState
+}
+
+func (m *Model) openReview() {
+	m.review = &reviewState{}
+}
+
+// reviewRows lists the pending prompts closest to timing out first, each
+// with its proposal.
+func (m *Model) reviewRows(snapshot state.Snapshot, now time.Time) []reviewRow {
+	m.syncForms(snapshot.Prompts)
+	// A new form moves the card's focus to its initial field; the card is
+	// not on screen, so leave its focus where it was.
+	focus := m.focus
+	defer func() { m.focus = focus }()
+	order := expiryOrder(snapshot.Prompts, promptTimeout(snapshot.Settings), now)
+	rows := make([]reviewRow, len(order))
+	for i, idx := range order {
+		prompt := snapshot.Prompts[idx]
+		targets := targetOptionsFor(prompt.Connection, snapshot.Settings.UIDZeroUnknown)
+		rows[i] = reviewRow{prompt: prompt, targets: targets, form: m.ensureForm(prompt, targets)}
+	}
+	return rows
+}
+
+func (m *Model) updateReview(key tea.KeyMsg, snapshot state.Snapshot) (tea.Cmd, bool) {
+	rows := m.reviewRows(snapshot, time.Now())
+	if len(rows) == 0 {
+		m.review = nil
+		return nil, false
+	}
+	rv := m.review
+	rv.cursor = min(rv.cursor, len(rows)-1)
+	form, targets := rows[rv.cursor].form, len(rows[rv.cursor].targets)
+	switch key.String() {
+	case "esc", "L":
+		m.review = nil
+	case "tab", "shift+tab":
+		return nil, false
+	case "up", "k":
+		rv.cursor = max(0, rv.cursor-1)
+	case "down", "j":
+		rv.cursor = min(max(0, len(rows)-1), rv.cursor+1)
+	case "a", "d", "r":
+		form.action = strings.Index("adr", key.String())
+	case "o":
+		form.duration = durationIndex(controller.PromptDurationOnce)
+	case "u":
+		form.duration = durationIndex(controller.PromptDurationUntilRestart)
+	case "A":
+		form.duration = durationIndex(controller.PromptDurationAlways)
+	case "t":
+		form.target = util.WrapIndex(form.target, 1, max(1, targets))
+	case "enter":
+		m.submitBatch(rows)
+	}
+	return nil, true
+}
+
+// submitBatch answers rows in order and stops at the first failure, so
+// the prompts after it stay pending for another look.
+func (m *Model) submitBatch(rows []reviewRow) {
+	if !widget.Guard(m.theme, m.controller != nil, &m.review.status) {
+		return
+	}
+	for i, row := range rows {
+		decision := row.decision()
+		if err := m.controller.ResolvePrompt(decision); err != nil {
+			m.review.cursor = 0
+			m.review.status = m.theme.Danger.Render(fmt.Sprintf("Answered %d of %d; %s failed: %v", i, len(rows), util.Fallback(row.prompt.Connection.ProcessPath, row.prompt.ID), err))
+			return
+		}
+		if row.prompt.ID == m.activeID {
+			m.submittedID = row.prompt.ID
+		}
+	}
+	m.review = nil
+	m.status = m.theme.Success.Render(fmt.Sprintf("Answered %d prompts", len(rows)))
+}
+
+func (r reviewRow) decision() controller.PromptDecision {
+	return formDecision(r.prompt, r.targets, r.form)
+}
+
+func (r reviewRow) targetLabel() string {
+	if len(r.targets) == 0 {
+		return "-"
+	}
+	return r.targets[min(r.form.target, len(r.targets)-1)].label
+}
+
+func durationIndex(value controller.PromptDuration) int {
+	for idx, opt := range durationOptions {
+		if opt.value == value {
+			return idx
+		}
+	}
+	return 0
+}
+
+func (m *Model) renderReview(snapshot state.Snapshot) string {
+	now := time.Now()
+	rows := m.reviewRows(snapshot, now)
+	rv := m.review
+	rv.cursor = min(rv.cursor, max(0, len(rows)-1))
+	cardWidth := min(m.width-4, 120)
+	innerWidth := max(20, cardWidth-m.theme.Card.GetHorizontalFrameSize())
+	capacity := max(1, m.height-reviewChrome)
+	if rv.cursor < rv.offset {
+		rv.offset = rv.cursor
+	}
+	if rv.cursor >= rv.offset+capacity {
+		rv.offset = rv.cursor - capacity + 1
+	}
+	rv.offset = min(rv.offset, max(0, len(rows)-capacity))
+
+	widths := []int{1, 0, 28, 8, 6, 13, 16}
+	fixed := len(widths) - 1
+	for _, w := range widths {
+		fixed += w
+	}
+	widths[1] = max(12, innerWidth-fixed)
+	header := m.theme.Header.Bold(true).Padding(0)
+	labels := []string{"", "PROCESS", "DESTINATION", "AGE", "ACTION", "DURATION", "TARGET"}
+	cells := make([]string, len(labels))
+	for i, label := range labels {
+		cells[i] = table.PadAndStyle(header, label, widths[i], true)
+	}
+	lines := []string{strings.Join(cells, " ")}
+	end := min(len(rows), rv.offset+capacity)
+	for idx := rv.offset; idx < end; idx++ {
+		row := rows[idx]
+		style := m.theme.Body.Padding(0)
+		cursor := " "
+		if idx == rv.cursor {
+			style = m.theme.TabActive.Padding(0)
+			cursor = ">"
+		}
+		values := []string{
+			cursor,
+			util.Fallback(row.prompt.Connection.ProcessPath, "unknown"),
+			reviewDestination(row.prompt.Connection),
+			promptAge(row.prompt, now),
+			actionOptions[min(row.form.action, len(actionOptions)-1)].label,
+			durationOptions[min(row.form.duration, len(durationOptions)-1)].label,
+			row.targetLabel(),
+		}
+		for i, value := range values {
+			cells[i] = table.PadAndStyle(style, value, widths[i], true)
+		}
+		lines = append(lines, strings.Join(cells, " "))
+	}
+	if end < len(rows) {
+		lines = append(lines, table.RenderCaretRow(table.ComputeMaxWidth(lines[:1]), m.theme.Subtle))
+	}
+	parts := []string{
+		m.theme.Header.Render(fmt.Sprintf("Review %d pending prompts", len(rows))),
+		strings.Join(table.ClipRows(lines, 0, innerWidth), "\n"),
+	}
+	if m.controller == nil {
+		parts = append(parts, widget.RenderHelp(m.theme, reviewHelp, []string{"enter answer all"}))
+		if rv.status == "" {
+			parts = append(parts, widget.RenderReadOnly(m.theme))
+		}
+	} else {
+		parts = append(parts, m.theme.Subtle.Render(reviewHelp))
+	}
+	if rv.status != "" {
+		parts = append(parts, rv.status)
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, parts...)
+	return lipgloss.Place(m.width, max(10, m.height-2), lipgloss.Center, lipgloss.Center, m.theme.Card.Width(cardWidth).Render(body))
+}
+
+// reviewDestination is the host, or the address, and port of conn.
+func reviewDestination(conn state.Connection) string {
+	dest := util.Fallback(conn.DstHost, util.CompactIP(conn.DstIP))
+	if strings.Contains(dest, ":") {
+		dest = "[" + dest + "]"
+	}
+	return fmt.Sprintf("%s:%d", util.Fallback(dest, "unknown"), conn.DstPort)
+}
+
+// promptAge is how long prompt has waited, or "-" when the daemon did not
+// say when it asked.
+func promptAge(prompt state.Prompt, now time.Time) string {
+	if prompt.RequestedAt.IsZero() {
+		return "-"
+	}
+	return now.Sub(prompt.RequestedAt).Truncate(time.Second).String()
+}
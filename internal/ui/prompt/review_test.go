@@ -0,0 +1,169 @@
+package prompt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// failingPromptManager resolves prompts in the store like a daemon would,
+// except failID, which it refuses.
+type failingPromptManager struct {
+	store     *state.Store
+	failID    string
+	decisions []controller.PromptDecision
+}
+
+func (f *failingPromptManager) ResolvePrompt(decision controller.PromptDecision) error {
+	if decision.PromptID == f.failID {
+		return errors.New("daemon unreachable")
+	}
+	f.decisions = append(f.decisions, decision)
+	f.store.RemovePrompt(decision.PromptID)
+	return nil
+}
+func (f *failingPromptManager) PausePrompt(string) error  { return nil }
+func (f *failingPromptManager) ResumePrompt(string) error { return nil }
+
+// reviewModel queues one prompt per process, the first expiring first.
+func reviewModel(t *testing.T, failID string, procs ...string) (*Model, *state.Store, *failingPromptManager) {
+	t.Helper()
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.AlertsInterrupt = true
+	settings.DefaultPromptAction = string(controller.PromptActionDeny)
+	settings.DefaultPromptTarget = string(controller.PromptTargetDestinationHost)
+	store.SetSettings(settings)
+	now := time.Now()
+	for i, proc := range procs {
+		store.AddPrompt(state.Prompt{ID: proc, NodeName: "local", RequestedAt: now.Add(time.Duration(i-len(procs)) * time.Second), Connection: state.Connection{
+			ProcessPath: "/usr/bin/" + proc,
+			DstHost:     proc + ".example.com",
+			DstIP:       "203.0.113.7",
+			DstPort:     443,
+			Protocol:    "tcp",
+		}})
+	}
+	ctrl := &failingPromptManager{store: store, failID: failID}
+	m := New(store, theme.New(theme.Options{}), ctrl)
+	m.SetSize(120, 30)
+	return m, store, ctrl
+}
+
+func TestReviewListsPendingPromptsWithProposals(t *testing.T) {
+	m, _, _ := reviewModel(t, "", "curl", "wget")
+	pressKey(m, "L")
+	out := util.StripANSI(m.View())
+	for _, want := range []string{"Review 2 pending prompts", "PROCESS", "/usr/bin/curl", "wget.example.com:443", "Deny", "Destination host", reviewHelp} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the review, got:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "/usr/bin/curl") > strings.Index(out, "/usr/bin/wget") {
+		t.Fatalf("expected the prompt closest to timing out first, got:\n%s", out)
+	}
+	pressKey(m, "esc")
+	if out := util.StripANSI(m.View()); strings.Contains(out, "Review 2") || !strings.Contains(out, "Action:") {
+		t.Fatalf("expected esc to return to the card, got:\n%s", out)
+	}
+}
+
+func TestReviewSubmitsBatchInOrder(t *testing.T) {
+	m, store, ctrl := reviewModel(t, "", "curl", "wget", "dig")
+	pressKey(m, "L")
+	pressKey(m, "a")
+	pressKey(m, "A")
+	pressKey(m, "down")
+	pressKey(m, "r")
+	pressKey(m, "u")
+	pressKey(m, "t")
+	pressKey(m, "enter")
+
+	if len(ctrl.decisions) != 3 {
+		t.Fatalf("expected every prompt answered, got %+v", ctrl.decisions)
+	}
+	first, second, third := ctrl.decisions[0], ctrl.decisions[1], ctrl.decisions[2]
+	if first.PromptID != "curl" || first.Action != controller.PromptActionAllow || first.Duration != controller.PromptDurationAlways {
+		t.Fatalf("expected curl allowed always, got %+v", first)
+	}
+	if second.PromptID != "wget" || second.Action != controller.PromptActionReject || second.Duration != controller.PromptDurationUntilRestart || second.Target != controller.PromptTargetDestinationIP {
+		t.Fatalf("expected wget rejected until restart by IP, got %+v", second)
+	}
+	if third.PromptID != "dig" || third.Action != controller.PromptActionDeny || third.Target != controller.PromptTargetDestinationHost {
+		t.Fatalf("expected dig left on the defaults, got %+v", third)
+	}
+	if len(store.Snapshot().Prompts) != 0 || m.review != nil {
+		t.Fatal("expected the queue emptied and the review closed")
+	}
+}
+
+func TestReviewStopsAtFirstFailure(t *testing.T) {
+	m, store, ctrl := reviewModel(t, "wget", "curl", "wget", "dig")
+	pressKey(m, "L")
+	pressKey(m, "enter")
+
+	if len(ctrl.decisions) != 1 || ctrl.decisions[0].PromptID != "curl" {
+		t.Fatalf("expected only curl answered before the failure, got %+v", ctrl.decisions)
+	}
+	if got := len(store.Snapshot().Prompts); got != 2 {
+		t.Fatalf("expected wget and dig still pending, got %d", got)
+	}
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "Answered 1 of 3; /usr/bin/wget failed: daemon unreachable") || !strings.Contains(out, "Review 2 pending prompts") {
+		t.Fatalf("expected the failure reported in the review, got:\n%s", out)
+	}
+}
+
+func TestReviewTargetsFollowEachPrompt(t *testing.T) {
+	m, store, ctrl := reviewModel(t, "", "curl")
+	// No host and no PID: the default target is not on offer.
+	store.AddPrompt(state.Prompt{ID: "raw", NodeName: "local", RequestedAt: time.Now(), Connection: state.Connection{
+		ProcessPath: "/usr/bin/raw",
+		DstIP:       "198.51.100.1",
+		DstPort:     53,
+		UserID:      1000,
+	}})
+	pressKey(m, "L")
+	pressKey(m, "down")
+	// Executable, Destination IP, Destination port, User ID, and around.
+	for range 5 {
+		pressKey(m, "t")
+	}
+	pressKey(m, "enter")
+
+	if len(ctrl.decisions) != 2 {
+		t.Fatalf("expected both prompts answered, got %+v", ctrl.decisions)
+	}
+	if got := ctrl.decisions[1]; got.PromptID != "raw" || got.Target != controller.PromptTargetDestinationIP {
+		t.Fatalf("expected raw's targets cycled back to its IP, got %+v", got)
+	}
+}
+
+func TestPromptWithoutControllerIsReadOnly(t *testing.T) {
+	_, store, _ := reviewModel(t, "", "curl", "wget")
+	var typedNil *failingPromptManager
+	m := New(store, theme.New(theme.Options{}), typedNil)
+	m.SetSize(120, 30)
+	want := controller.ErrNoController.Error()
+	if out := util.StripANSI(m.View()); !strings.Contains(out, want) {
+		t.Fatalf("expected the read-only line before any key, got:\n%s", out)
+	}
+	pressKey(m, "enter")
+	if got := util.StripANSI(m.status); got != want {
+		t.Fatalf("expected %q on confirm, got %q", want, got)
+	}
+	pressKey(m, "L")
+	if out := util.StripANSI(m.View()); !strings.Contains(out, want) {
+		t.Fatalf("expected the read-only line in the review, got:\n%s", out)
+	}
+	pressKey(m, "enter")
+	if got := util.StripANSI(m.review.status); got != want || len(store.Snapshot().Prompts) != 2 {
+		t.Fatalf("expected %q and both prompts pending, got %q", want, got)
+	}
+}
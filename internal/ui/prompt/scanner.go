@@ -0,0 +1,84 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/scanhook"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+type scanResultMsg struct {
+	promptID string
+	result   scanhook.Result
+}
+
+func scanHookCmd(promptID, command, path string, runner scanhook.Runner) tea.Cmd {
+	return func() tea.Msg {
+		hook := scanhook.Hook{Command: command, Runner: runner}
+		return scanResultMsg{promptID: promptID, result: hook.Scan(context.Background(), path)}
+	}
+}
+
+// startScanner runs the configured inspect hook against the prompting
+// binary. The hook only makes sense on the machine running the process, so
+// remote nodes get a note instead.
+func (m *Model) startScanner(prompt state.Prompt, settings state.Settings, local bool) tea.Cmd {
+	if !settings.InspectHookEnabled || settings.InspectHook == "" {
+		return nil
+	}
+	path := prompt.Connection.RawProcessPath()
+	switch {
+	case !local:
+		m.setScannerSection(m.theme.Subtle.Render("Scanner: not run for remote nodes"))
+		return nil
+	case path == "":
+		m.setScannerSection(m.theme.Subtle.Render("Scanner: process path unknown"))
+		return nil
+	}
+	name := "hook"
+	if argv, err := scanhook.Command(settings.InspectHook, path); err == nil {
+		name = filepath.Base(argv[0])
+	}
+	m.setScannerSection(m.theme.Warning.Render(fmt.Sprintf("Scanner: running %s", name)))
+	return scanHookCmd(prompt.ID, settings.InspectHook, path, m.scanner)
+}
+
+func (m *Model) showScanResult(res scanhook.Result) {
+	var head string
+	switch {
+	case res.Err != nil:
+		head = m.theme.Danger.Render("Scanner: error: " + util.Sanitize(res.Err.Error()))
+	case res.Verdict == scanhook.VerdictClean:
+		head = m.theme.Success.Render("Scanner: clean")
+	default:
+		head = m.theme.Danger.Render(fmt.Sprintf("Scanner: %s (exit %d)", res.Verdict, res.ExitCode))
+	}
+	lines := []string{head}
+	for _, line := range res.Lines {
+		lines = append(lines, "  "+sanitizeOutput(line))
+	}
+	m.setScannerSection(lines...)
+}
+
+// setScannerSection replaces the scanner lines of the YARA section.
+func (m *Model) setScannerSection(lines ...string) {
+	m.scannerLines = lines
+	m.refreshSection("yara")
+}
+
+// sanitizeOutput drops escape sequences and control characters so scanner
+// output cannot restyle or move around the terminal.
+func sanitizeOutput(line string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, util.StripANSI(line))
+}
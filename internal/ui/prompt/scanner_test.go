@@ -0,0 +1,131 @@
+package prompt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+type fakeScanRunner struct {
+	calls  int
+	argv   []string
+	output string
+	code   int
+}
+
+func (f *fakeScanRunner) Run(_ context.Context, argv, _ []string) ([]byte, int, error) {
+	f.calls++
+	f.argv = argv
+	return []byte(f.output), f.code, nil
+}
+
+func newScannerModel(t *testing.T, prompt state.Prompt, nodes []state.Node) (*Model, *fakeScanRunner) {
+	t.Helper()
+	store := state.NewStore()
+	store.SetNodes(nodes)
+	store.AddPrompt(prompt)
+	settings := store.Snapshot().Settings
+	settings.YaraEnabled = false
+	settings.InspectHook = "clamscan --no-summary {path}"
+	settings.InspectHookEnabled = true
+	store.SetSettings(settings)
+
+	runner := &fakeScanRunner{}
+	m := New(store, theme.New(theme.Options{}), nil)
+	m.scanner = runner
+	m.SetSize(100, 30)
+	return m, runner
+}
+
+// scanMsg runs cmd and returns the scanner result among its messages,
+// descending into batches.
+func scanMsg(t *testing.T, cmd tea.Cmd) scanResultMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatalf("expected a scanner command")
+	}
+	var msgs []tea.Msg
+	var run func(tea.Cmd)
+	run = func(c tea.Cmd) {
+		msg := c()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, inner := range batch {
+				run(inner)
+			}
+			return
+		}
+		msgs = append(msgs, msg)
+	}
+	run(cmd)
+	for _, msg := range msgs {
+		if res, ok := msg.(scanResultMsg); ok {
+			return res
+		}
+	}
+	t.Fatalf("no scanner result in %v", msgs)
+	return scanResultMsg{}
+}
+
+func inspectText(m *Model) string {
+	return util.StripANSI(strings.Join(m.inspectInfo.Lines, "\n"))
+}
+
+func TestInspectRunsScannerHook(t *testing.T) {
+	m, runner := newScannerModel(t, state.Prompt{ID: "p1", Connection: state.Connection{ProcessPath: "/tmp/dropper"}}, nil)
+	runner.output = "/tmp/dropper: Eicar-Signature FOUND\x1b[2J\n"
+	runner.code = 1
+
+	cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	if !strings.Contains(inspectText(m), "Scanner: running clamscan") {
+		t.Fatalf("expected running note, got:\n%s", inspectText(m))
+	}
+	msg := scanMsg(t, cmd)
+	if got := strings.Join(runner.argv, " "); got != "clamscan --no-summary /tmp/dropper" {
+		t.Fatalf("unexpected argv %q", got)
+	}
+	if _, handled := m.Update(msg); !handled {
+		t.Fatalf("expected scanner result to be handled")
+	}
+	text := inspectText(m)
+	if strings.Contains(text, "running") || !strings.Contains(text, "Scanner: suspicious (exit 1)") {
+		t.Fatalf("expected verdict to replace running note, got:\n%s", text)
+	}
+	if !strings.Contains(text, "  /tmp/dropper: Eicar-Signature FOUND") || strings.Contains(text, "\x1b") {
+		t.Fatalf("expected sanitized output lines, got %q", text)
+	}
+}
+
+func TestInspectSkipsScannerForRemoteNodes(t *testing.T) {
+	nodes := []state.Node{{ID: "node-1", Address: "10.0.0.5:50051"}}
+	m, runner := newScannerModel(t, state.Prompt{ID: "p1", NodeID: "node-1", Connection: state.Connection{ProcessPath: "/usr/bin/curl"}}, nodes)
+
+	cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	if cmd != nil {
+		t.Fatalf("expected no command for a remote node")
+	}
+	if runner.calls != 0 {
+		t.Fatalf("scanner must not run for remote nodes")
+	}
+	if !strings.Contains(inspectText(m), "Scanner: not run for remote nodes") {
+		t.Fatalf("expected remote note, got:\n%s", inspectText(m))
+	}
+}
+
+func TestInspectScannerGetsTheRawPath(t *testing.T) {
+	raw := "/tmp/drop\nper\x1b[2J"
+	conn := state.Connection{ProcessPath: util.Sanitize(raw)}
+	conn.Raw = &state.Connection{ProcessPath: raw}
+	m, runner := newScannerModel(t, state.Prompt{ID: "p1", Connection: conn}, nil)
+
+	cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	scanMsg(t, cmd)
+	if len(runner.argv) != 3 || runner.argv[2] != raw {
+		t.Fatalf("expected the hook to scan %q, got %q", raw, runner.argv)
+	}
+}
@@ -0,0 +1,137 @@
+package prompt
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+)
+
+// inspectSection is one collapsible part of the inspect panel. Its body is
+// only computed while the section is expanded, so a collapsed section never
+// reads /proc or starts a scan.
+type inspectSection struct {
+	// id is the name the inspect_sections setting uses.
+	id    string
+	key   string
+	title string
+	// lines renders the body; the result is kept until invalidated.
+	lines func() []string
+	// open, when set, runs the first time the section is expanded on a
+	// panel, to start work whose result arrives later.
+	open func() tea.Cmd
+}
+
+// sectionPanel lays out the sections of one inspected prompt. The expanded
+// set is shared with the model, so toggles carry over to the next prompt.
+type sectionPanel struct {
+	sections []inspectSection
+	expanded map[string]bool
+	cache    map[string][]string
+	opened   map[string]bool
+}
+
+// expandedSections seeds the session's expanded set from the configured
+// section names; nil selects config.DefaultInspectSections.
+func expandedSections(names []string) map[string]bool {
+	if names == nil {
+		names = config.DefaultInspectSections
+	}
+	expanded := make(map[string]bool, len(names))
+	for _, name := range names {
+		expanded[name] = true
+	}
+	return expanded
+}
+
+func newSectionPanel(sections []inspectSection, expanded map[string]bool) *sectionPanel {
+	return &sectionPanel{
+		sections: sections,
+		expanded: expanded,
+		cache:    make(map[string][]string),
+		opened:   make(map[string]bool),
+	}
+}
+
+// start opens the sections that are already expanded.
+func (p *sectionPanel) start() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, s := range p.sections {
+		if p.expanded[s.id] {
+			cmds = append(cmds, p.open(s))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (p *sectionPanel) open(s inspectSection) tea.Cmd {
+	if p.opened[s.id] || s.open == nil {
+		return nil
+	}
+	p.opened[s.id] = true
+	return s.open()
+}
+
+// toggle expands or collapses the section bound to key, reporting false
+// when no section uses it.
+func (p *sectionPanel) toggle(key string) (tea.Cmd, bool) {
+	for _, s := range p.sections {
+		if s.key != key {
+			continue
+		}
+		p.expanded[s.id] = !p.expanded[s.id]
+		if p.expanded[s.id] {
+			return p.open(s), true
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// invalidate drops the body of id so the next render recomputes it.
+func (p *sectionPanel) invalidate(id string) {
+	delete(p.cache, id)
+}
+
+// render lists a heading per section, styled by heading, followed by the
+// body of the expanded ones.
+func (p *sectionPanel) render(heading lipgloss.Style) []string {
+	var out []string
+	for i, s := range p.sections {
+		if !p.expanded[s.id] {
+			out = append(out, heading.Render(glyphs.Current().Collapsed+" "+s.key+" "+s.title))
+			continue
+		}
+		if i > 0 {
+			out = append(out, "")
+		}
+		out = append(out, heading.Render(glyphs.Current().Expanded+" "+s.key+" "+s.title))
+		body, ok := p.cache[s.id]
+		if !ok {
+			body = s.lines()
+			p.cache[s.id] = body
+		}
+		if len(body) == 0 {
+			body = []string{"Nothing to show"}
+		}
+		out = append(out, body...)
+	}
+	return out
+}
+
+// legend names the toggle keys, styling expanded sections with on and
+// collapsed ones with off.
+func (p *sectionPanel) legend(on, off lipgloss.Style) string {
+	parts := make([]string, 0, len(p.sections))
+	for _, s := range p.sections {
+		style := off
+		if p.expanded[s.id] {
+			style = on
+		}
+		parts = append(parts, style.Render(s.key+" "+s.title))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,152 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// countingSections returns two sections whose providers count their calls.
+func countingSections(renders, opens map[string]int) []inspectSection {
+	section := func(id, key string) inspectSection {
+		return inspectSection{
+			id: id, key: key, title: strings.ToUpper(id),
+			lines: func() []string {
+				renders[id]++
+				return []string{id + " body"}
+			},
+			open: func() tea.Cmd {
+				opens[id]++
+				return nil
+			},
+		}
+	}
+	return []inspectSection{section("alpha", "1"), section("beta", "2")}
+}
+
+func TestSectionPanelRendersOnlyExpandedSections(t *testing.T) {
+	renders, opens := map[string]int{}, map[string]int{}
+	panel := newSectionPanel(countingSections(renders, opens), expandedSections([]string{"alpha"}))
+	panel.start()
+
+	got := strings.Join(panel.render(lipgloss.NewStyle()), "\n")
+	if got != "▾ 1 ALPHA\nalpha body\n▸ 2 BETA" {
+		t.Fatalf("unexpected panel:\n%s", got)
+	}
+	if renders["beta"] != 0 || opens["beta"] != 0 {
+		t.Fatalf("collapsed section must not be computed, got renders=%v opens=%v", renders, opens)
+	}
+
+	// Bodies are cached until invalidated.
+	panel.render(lipgloss.NewStyle())
+	if renders["alpha"] != 1 {
+		t.Fatalf("expected one alpha render, got %d", renders["alpha"])
+	}
+	panel.invalidate("alpha")
+	panel.render(lipgloss.NewStyle())
+	if renders["alpha"] != 2 {
+		t.Fatalf("expected alpha recomputed after invalidate, got %d", renders["alpha"])
+	}
+
+	// Expanding opens once; collapsing and expanding again reuses it.
+	if _, ok := panel.toggle("2"); !ok {
+		t.Fatalf("expected key 2 to toggle beta")
+	}
+	panel.toggle("2")
+	panel.toggle("2")
+	panel.render(lipgloss.NewStyle())
+	if opens["beta"] != 1 || renders["beta"] != 1 {
+		t.Fatalf("expected beta opened and rendered once, got opens=%v renders=%v", opens, renders)
+	}
+	if _, ok := panel.toggle("9"); ok {
+		t.Fatalf("expected unbound key to be ignored")
+	}
+}
+
+func TestSectionPanelSharesExpandedSet(t *testing.T) {
+	renders, opens := map[string]int{}, map[string]int{}
+	expanded := expandedSections([]string{})
+	first := newSectionPanel(countingSections(renders, opens), expanded)
+	first.toggle("2")
+
+	// A later panel built from the same set starts with beta open.
+	second := newSectionPanel(countingSections(renders, opens), expanded)
+	second.start()
+	if opens["beta"] != 2 || opens["alpha"] != 0 {
+		t.Fatalf("expected the toggle remembered, got opens=%v", opens)
+	}
+	if legend := second.legend(lipgloss.NewStyle(), lipgloss.NewStyle()); legend != "1 ALPHA · 2 BETA" {
+		t.Fatalf("unexpected legend %q", legend)
+	}
+
+	if got := expandedSections(nil); !got["identity"] || !got["yara"] || !got["tree"] || got["env"] {
+		t.Fatalf("expected the config defaults for nil, got %v", got)
+	}
+}
+
+func TestInspectRemembersSectionsAcrossPrompts(t *testing.T) {
+	store := state.NewStore()
+	store.AddPrompt(state.Prompt{ID: "p1", Connection: state.Connection{ProcessPath: "/usr/bin/curl"}})
+	store.AddPrompt(state.Prompt{ID: "p2", Connection: state.Connection{ProcessPath: "/usr/bin/wget"}})
+	settings := store.Snapshot().Settings
+	settings.InspectSections = []string{"identity"}
+	settings.InspectHook = "clamscan {path}"
+	settings.InspectHookEnabled = true
+	store.SetSettings(settings)
+	m := New(store, theme.New(theme.Options{}), nil)
+	runner := &fakeScanRunner{}
+	m.scanner = runner
+	m.SetSize(100, 30)
+
+	// The YARA section is collapsed, so nothing is scanned on inspect.
+	if cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}}); cmd != nil {
+		t.Fatalf("expected no scan while the YARA section is collapsed")
+	}
+	if text := inspectText(m); strings.Contains(text, "Scanner") || !strings.Contains(text, "▸ 2 YARA") {
+		t.Fatalf("expected a collapsed YARA section, got:\n%s", text)
+	}
+	cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	scanMsg(t, cmd)
+	if !strings.Contains(inspectText(m), "Scanner: running clamscan") {
+		t.Fatalf("expected the scan started on expand, got:\n%s", inspectText(m))
+	}
+
+	// Leave, move to the next prompt and inspect again: YARA stays open.
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}})
+	if cmd, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}}); cmd == nil {
+		t.Fatalf("expected the remembered YARA section to start its scan")
+	}
+	if !strings.Contains(inspectText(m), "▾ 2 YARA") {
+		t.Fatalf("expected the YARA section expanded, got:\n%s", inspectText(m))
+	}
+}
+
+func TestParseProcNet(t *testing.T) {
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0035 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1111 1 0
+   1: 0F02000A:C350 2218D85D:01BB 01 00000000:00000000 00:00000000 00000000  1000        0 2222 1 0
+   2: 0F02000A:C351 2218D85D:01BB 01 00000000:00000000 00:00000000 00000000  1000        0 3333 1 0
+`
+	inodes := map[string]bool{"1111": true, "2222": true, "9999": true}
+	got := parseProcNet("tcp", table, inodes)
+	want := []string{
+		"tcp  127.0.0.1:53 LISTEN",
+		"tcp  10.0.2.15:50000 → 93.216.24.34:443 ESTABLISHED",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected sockets %q", got)
+	}
+	if len(inodes) != 1 || !inodes["9999"] {
+		t.Fatalf("expected matched inodes removed, got %v", inodes)
+	}
+
+	if addr, ok := decodeProcAddr("00000000000000000000000001000000:0016"); !ok || addr != "[::1]:22" {
+		t.Fatalf("unexpected IPv6 address %q", addr)
+	}
+}
@@ -0,0 +1,107 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/yara"
+)
+
+// Labels of the two images scanned when the binary on disk is not the one
+// running.
+const (
+	imageOnDisk   = "on-disk"
+	imageInMemory = "in-memory image"
+)
+
+// yaraImage is one file handed to the YARA scanner. label is empty when
+// only one image is scanned.
+type yaraImage struct {
+	label string
+	path  string
+}
+
+// yaraScan is the outcome of scanning one image.
+type yaraScan struct {
+	label  string
+	result yara.Result
+	err    error
+}
+
+// yaraImages picks what to scan for a local process. The path on disk can
+// be replaced after exec, so /proc/<pid>/exe, which refers to the running
+// inode even once the file is gone, is preferred; when both exist and are
+// different files both are scanned and replaced reports the mismatch.
+func yaraImages(pid uint32, path string) (images []yaraImage, replaced bool) {
+	if pid == 0 {
+		return []yaraImage{{path: path}}, false
+	}
+	exe := filepath.Join(procRoot, strconv.FormatUint(uint64(pid), 10), "exe")
+	running, err := os.Stat(exe)
+	if err != nil {
+		// Gone, or not ours to read: all there is left is the path.
+		return []yaraImage{{path: path}}, false
+	}
+	onDisk, err := os.Stat(path)
+	if err != nil || os.SameFile(running, onDisk) {
+		return []yaraImage{{path: exe}}, false
+	}
+	return []yaraImage{{label: imageOnDisk, path: path}, {label: imageInMemory, path: exe}}, true
+}
+
+// scanSummary is the status of one labelled scan, e.g. "on-disk: clean".
+func scanSummary(scan yaraScan) string {
+	switch n := len(scan.result.Matches); {
+	case scan.err != nil:
+		return scan.label + ": error: " + util.Sanitize(scan.err.Error())
+	case n == 0:
+		return scan.label + ": clean"
+	case n == 1:
+		return scan.label + ": 1 match"
+	default:
+		return fmt.Sprintf("%s: %d matches", scan.label, n)
+	}
+}
+
+// showYaraScans fills the YARA section from the scans of one prompt.
+func (m *Model) showYaraScans(scans []yaraScan, replaced bool, path string) {
+	m.yaraMatches = m.yaraMatches[:0]
+	m.yaraWarning = ""
+	if replaced {
+		m.yaraWarning = fmt.Sprintf("Running binary differs from %s on disk: it was replaced after exec", path)
+	}
+	if len(scans) == 1 {
+		scan := scans[0]
+		switch {
+		case scan.err != nil:
+			m.setYaraStatus("YARA: error: "+util.Sanitize(scan.err.Error()), yaraStatusError)
+		case len(scan.result.Matches) == 0:
+			m.setYaraStatus("YARA: no matches", yaraStatusNoMatches)
+		default:
+			for _, match := range scan.result.Matches {
+				m.yaraMatches = append(m.yaraMatches, match.Rule)
+			}
+			m.setYaraStatus(fmt.Sprintf("YARA: matches (%d)", len(scan.result.Matches)), yaraStatusMatches)
+		}
+		return
+	}
+	kind := yaraStatusNoMatches
+	summaries := make([]string, 0, len(scans))
+	for _, scan := range scans {
+		summaries = append(summaries, scanSummary(scan))
+		switch {
+		case scan.err != nil && kind == yaraStatusNoMatches:
+			kind = yaraStatusError
+		case len(scan.result.Matches) > 0:
+			kind = yaraStatusMatches
+		}
+		for _, match := range scan.result.Matches {
+			m.yaraMatches = append(m.yaraMatches, scan.label+": "+match.Rule)
+		}
+	}
+	m.setYaraStatus("YARA: "+strings.Join(summaries, " / "), kind)
+}
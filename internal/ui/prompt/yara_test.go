@@ -0,0 +1,185 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/yara"
+)
+
+// fakeExe builds a proc tree whose 4242/exe points at running, and returns
+// the proc root.
+func fakeExe(t *testing.T, running string) string {
+	t.Helper()
+	root := fakeProc(t, true, "", map[string]string{})
+	if err := os.MkdirAll(filepath.Join(root, "4242"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(running, filepath.Join(root, "4242", "exe")); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func writeBinary(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestYaraImages(t *testing.T) {
+	dir := t.TempDir()
+	onDisk := writeBinary(t, dir, "curl", "v1")
+
+	t.Run("unknown pid", func(t *testing.T) {
+		images, replaced := yaraImages(0, onDisk)
+		if replaced || len(images) != 1 || images[0].path != onDisk {
+			t.Fatalf("expected the path alone, got %+v", images)
+		}
+	})
+	t.Run("exited", func(t *testing.T) {
+		fakeProc(t, true, "", map[string]string{})
+		images, replaced := yaraImages(4242, onDisk)
+		if replaced || len(images) != 1 || images[0].path != onDisk {
+			t.Fatalf("expected the path alone, got %+v", images)
+		}
+	})
+	t.Run("same inode", func(t *testing.T) {
+		root := fakeExe(t, onDisk)
+		images, replaced := yaraImages(4242, onDisk)
+		if replaced || len(images) != 1 || images[0].path != filepath.Join(root, "4242", "exe") {
+			t.Fatalf("expected the running image alone, got %+v", images)
+		}
+	})
+	t.Run("deleted on disk", func(t *testing.T) {
+		root := fakeExe(t, onDisk)
+		images, replaced := yaraImages(4242, filepath.Join(dir, "gone"))
+		if replaced || len(images) != 1 || images[0].path != filepath.Join(root, "4242", "exe") {
+			t.Fatalf("expected the running image alone, got %+v", images)
+		}
+	})
+	t.Run("replaced after exec", func(t *testing.T) {
+		running := writeBinary(t, dir, "curl.old", "v0")
+		root := fakeExe(t, running)
+		images, replaced := yaraImages(4242, onDisk)
+		want := []yaraImage{{imageOnDisk, onDisk}, {imageInMemory, filepath.Join(root, "4242", "exe")}}
+		if !replaced || len(images) != 2 || images[0] != want[0] || images[1] != want[1] {
+			t.Fatalf("expected both images, got %+v", images)
+		}
+	})
+}
+
+func TestYaraScansReplacedBinaryTwice(t *testing.T) {
+	dir := t.TempDir()
+	onDisk := writeBinary(t, dir, "curl", "clean")
+	running := writeBinary(t, dir, "curl.old", "evil")
+	fakeExe(t, running)
+
+	store := state.NewStore()
+	prompt := state.Prompt{ID: "p1", Connection: state.Connection{ProcessID: 4242, ProcessPath: onDisk}}
+	store.AddPrompt(prompt)
+	settings := store.Snapshot().Settings
+	settings.AlertsInterrupt = true
+	settings.YaraEnabled = true
+	settings.YaraRuleDir = dir
+	store.SetSettings(settings)
+
+	m := New(store, theme.New(theme.Options{}), nil)
+	m.SetSize(100, 40)
+	var scanned []string
+	m.yaraAvailable = func() bool { return true }
+	m.yaraScanFile = func(path, _ string) (yara.Result, error) {
+		scanned = append(scanned, path)
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != "evil" {
+			return yara.Result{}, err
+		}
+		return yara.Result{Matches: []yara.Match{{Rule: "dropper"}, {Rule: "packed"}}}, nil
+	}
+	m.View()
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+
+	cmd := m.startYara(prompt, store.Snapshot().Settings)
+	if got := util.StripANSI(strings.Join(m.yaraLines(), "\n")); !strings.Contains(got, "scanning "+onDisk+" and the running image") {
+		t.Fatalf("expected the scanning status to name both images, got %q", got)
+	}
+	if _, handled := m.Update(cmd()); !handled {
+		t.Fatal("expected the scan result to be handled")
+	}
+	if len(scanned) != 2 {
+		t.Fatalf("expected two scans, got %v", scanned)
+	}
+	got := util.StripANSI(strings.Join(m.yaraLines(), "\n"))
+	for _, want := range []string{
+		"YARA: on-disk: clean / in-memory image: 2 matches",
+		"in-memory image: dropper",
+		"Running binary differs from " + onDisk + " on disk",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in the YARA section, got:\n%s", want, got)
+		}
+	}
+	if m.yaraKind != yaraStatusMatches {
+		t.Fatalf("expected the matches to set the status kind, got %v", m.yaraKind)
+	}
+}
+
+func TestYaraScansRunningImageOfUnchangedBinary(t *testing.T) {
+	dir := t.TempDir()
+	onDisk := writeBinary(t, dir, "curl", "clean")
+	root := fakeExe(t, onDisk)
+
+	m := New(state.NewStore(), theme.New(theme.Options{}), nil)
+	var scanned []string
+	m.yaraScanFile = func(path, _ string) (yara.Result, error) {
+		scanned = append(scanned, path)
+		return yara.Result{}, nil
+	}
+	images, replaced := yaraImages(4242, onDisk)
+	msg := scanYaraCmd("p1", onDisk, images, replaced, dir, m.yaraScanFile)().(yaraResultMsg)
+	m.showYaraScans(msg.scans, msg.replaced, msg.path)
+
+	if len(scanned) != 1 || scanned[0] != filepath.Join(root, "4242", "exe") {
+		t.Fatalf("expected only the running image to be scanned, got %v", scanned)
+	}
+	got := util.StripANSI(strings.Join(m.yaraLines(), "\n"))
+	if got != "YARA: no matches" {
+		t.Fatalf("expected a plain clean status, got %q", got)
+	}
+}
+
+func TestYaraScansTheRawPath(t *testing.T) {
+	dir := t.TempDir()
+	raw := writeBinary(t, dir, "cu\x1brl\n", "evil")
+	store := state.NewStore()
+	conn := state.Connection{ProcessPath: util.Sanitize(raw)}
+	conn.Raw = &state.Connection{ProcessPath: raw}
+	prompt := state.Prompt{ID: "p1", Connection: conn}
+	store.AddPrompt(prompt)
+	settings := store.Snapshot().Settings
+	settings.YaraEnabled = true
+	settings.YaraRuleDir = dir
+	store.SetSettings(settings)
+
+	m := New(store, theme.New(theme.Options{}), nil)
+	var scanned []string
+	m.yaraAvailable = func() bool { return true }
+	m.yaraScanFile = func(path, _ string) (yara.Result, error) {
+		scanned = append(scanned, path)
+		return yara.Result{}, nil
+	}
+	m.startYara(prompt, store.Snapshot().Settings)()
+	if len(scanned) != 1 || scanned[0] != raw {
+		t.Fatalf("expected %q scanned, got %q", raw, scanned)
+	}
+}
@@ -0,0 +1,79 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/yara"
+)
+
+// About is the runtime information shown in the about overlay next to the
+// build's.
+type About struct {
+	ConfigPath string
+	ListenAddr string
+	// ControlSocket is empty when the control socket is disabled.
+	ControlSocket string
+	// Demo is set when no daemons are listened for.
+	Demo bool
+}
+
+// toggleAbout opens or closes the about overlay.
+func (m *Model) toggleAbout() {
+	m.aboutOpen = !m.aboutOpen
+}
+
+func (m *Model) renderAbout() string {
+	info := m.build
+	rows := [][2]string{
+		{"Version", info.Version},
+		{"Commit", orUnknown(info.Commit, info.Modified)},
+		{"Built", orUnknown(info.Date, false)},
+		{"Go", orUnknown(info.GoVersion, false)},
+		{"Build tags", buildTags(info.Tags)},
+		{"Config", orUnknown(m.about.ConfigPath, false)},
+	}
+	switch {
+	case m.about.Demo:
+		rows = append(rows, [2]string{"Listening", "nothing (demo mode)"})
+	default:
+		rows = append(rows, [2]string{"Listening", orUnknown(m.about.ListenAddr, false)})
+		control := m.about.ControlSocket
+		if control == "" {
+			control = "disabled"
+		}
+		rows = append(rows, [2]string{"Control socket", control})
+	}
+
+	lines := []string{m.theme.Header.Render("About OpenSnitch TUI"), ""}
+	for _, row := range rows {
+		lines = append(lines, fmt.Sprintf("%s %s", m.theme.Subtle.Render(fmt.Sprintf("%-15s", row[0])), row[1]))
+	}
+	lines = append(lines, "", m.theme.Subtle.Render("f1/esc close"))
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func orUnknown(value string, modified bool) string {
+	if value == "" {
+		return "unknown"
+	}
+	if modified {
+		return value + " (uncommitted changes)"
+	}
+	return value
+}
+
+// buildTags lists the -tags of the build and whether YARA made it in,
+// which also depends on cgo.
+func buildTags(tags []string) string {
+	yaraState := "yara: not built in"
+	if yara.IsAvailable() {
+		yaraState = "yara: built in"
+	}
+	if len(tags) == 0 {
+		return "none · " + yaraState
+	}
+	return strings.Join(tags, ",") + " · " + yaraState
+}
@@ -0,0 +1,47 @@
+package root
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/version"
+)
+
+func TestAboutOverlayTogglesWithF1(t *testing.T) {
+	model := New(state.NewStore(), Options{
+		Theme: theme.New(theme.Options{}),
+		About: About{ConfigPath: "/home/me/.config/opensnitch-tui/config.yaml", ListenAddr: "127.0.0.1:50051"},
+	})
+	model.build = version.Info{Version: version.Dev}
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyF1})
+	out := model.View()
+	for _, want := range []string{"About OpenSnitch TUI", "dev", "Commit", "unknown", "config.yaml", "127.0.0.1:50051", "Control socket", "disabled", "yara:"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the about overlay, got %q", want, out)
+		}
+	}
+
+	// Keys go to the overlay while it is open, not to the view below.
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if model.active != state.ViewDashboard {
+		t.Fatalf("expected tab swallowed by the overlay, got view %s", model.active)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if strings.Contains(model.View(), "About OpenSnitch TUI") {
+		t.Fatal("expected esc to close the overlay")
+	}
+}
+
+func TestAboutOverlayInDemoMode(t *testing.T) {
+	model := New(state.NewStore(), Options{Theme: theme.New(theme.Options{}), About: About{Demo: true, ListenAddr: "127.0.0.1:50051"}})
+	model.toggleAbout()
+	if out := model.renderAbout(); !strings.Contains(out, "nothing (demo mode)") || strings.Contains(out, "127.0.0.1:50051") {
+		t.Fatalf("expected demo mode listed instead of the address, got %q", out)
+	}
+}
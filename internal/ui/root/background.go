@@ -0,0 +1,68 @@
+package root
+
+import (
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// backgroundInterval limits how often resizes re-query the terminal.
+const backgroundInterval = time.Minute
+
+// backgroundTimeout bounds a query the terminal never answers.
+var backgroundTimeout = 2 * time.Second
+
+var errBackgroundTimeout = errors.New("background detection timed out")
+
+// backgroundMsg carries the result of a terminal background query.
+type backgroundMsg struct {
+	light bool
+	err   error
+}
+
+// detectBackground runs detect in the background, giving up after
+// backgroundTimeout so a silent terminal cannot hold up anything.
+func detectBackground(detect func() (bool, error)) tea.Cmd {
+	return func() tea.Msg {
+		result := make(chan backgroundMsg, 1)
+		go func() {
+			light, err := detect()
+			result <- backgroundMsg{light: light, err: err}
+		}()
+		select {
+		case msg := <-result:
+			return msg
+		case <-time.After(backgroundTimeout):
+			return backgroundMsg{err: errBackgroundTimeout}
+		}
+	}
+}
+
+// redetectBackground re-queries the terminal background when the auto theme
+// is active, at most once per backgroundInterval unless forced.
+func (m *Model) redetectBackground(now time.Time, force bool) tea.Cmd {
+	if m.detect == nil || m.themeName != config.ThemeAuto {
+		return nil
+	}
+	if !force && !m.lastDetect.IsZero() && now.Sub(m.lastDetect) < backgroundInterval {
+		return nil
+	}
+	m.lastDetect = now
+	return detectBackground(m.detect)
+}
+
+// onBackground switches between Dawn and Midnight when the auto theme is
+// active and the terminal background changed.
+func (m *Model) onBackground(msg backgroundMsg) {
+	if msg.err != nil || msg.light == m.light {
+		return
+	}
+	m.light = msg.light
+	if m.themeName == config.ThemeAuto {
+		m.applyTheme(theme.New(theme.Options{Name: config.ThemeAuto, Light: m.light}))
+	}
+}
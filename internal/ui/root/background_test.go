@@ -0,0 +1,131 @@
+package root
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// themeRecorder is a view that remembers the last theme it was given.
+type themeRecorder struct {
+	theme theme.Theme
+}
+
+func (r *themeRecorder) Init() tea.Cmd                       { return nil }
+func (r *themeRecorder) Update(tea.Msg) (tea.Model, tea.Cmd) { return r, nil }
+func (r *themeRecorder) View() string                        { return "" }
+func (r *themeRecorder) SetSize(int, int)                    {}
+func (r *themeRecorder) SetTheme(th theme.Theme)             { r.theme = th }
+func (r *themeRecorder) Title() string                       { return "Recorder" }
+
+// runCmd runs cmd, flattening batches, and returns the messages it produced.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, runCmd(c)...)
+	}
+	return msgs
+}
+
+func newAutoThemeModel(t *testing.T, themeName string, detect func() (bool, error)) (*Model, *themeRecorder) {
+	t.Helper()
+	store := state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.ThemeName = themeName
+	store.SetSettings(settings)
+	model := New(store, Options{Theme: theme.New(theme.Options{Name: themeName}), DetectBackground: detect})
+	recorder := &themeRecorder{}
+	model.views[state.ViewDashboard] = recorder
+	return model, recorder
+}
+
+func TestAutoThemeSwitchesOnRefreshKey(t *testing.T) {
+	light := true
+	model, recorder := newAutoThemeModel(t, config.ThemeAuto, func() (bool, error) { return light, nil })
+	if model.theme.Name != config.ThemeMidnight {
+		t.Fatalf("expected the auto theme to start dark, got %q", model.theme.Name)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if cmd == nil {
+		t.Fatal("expected ctrl+r to start a background query")
+	}
+	model.Update(cmd())
+	if model.theme.Name != config.ThemeDawn || recorder.theme.Name != config.ThemeDawn {
+		t.Fatalf("expected Dawn propagated to views, got root %q view %q", model.theme.Name, recorder.theme.Name)
+	}
+	if model.themeName != config.ThemeAuto {
+		t.Fatalf("expected the configured theme to stay auto, got %q", model.themeName)
+	}
+
+	light = false
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	model.Update(cmd())
+	if recorder.theme.Name != config.ThemeMidnight {
+		t.Fatalf("expected Midnight after the background turned dark, got %q", recorder.theme.Name)
+	}
+}
+
+func TestAutoThemeResizeQueriesAtMostOncePerInterval(t *testing.T) {
+	calls := 0
+	model, _ := newAutoThemeModel(t, config.ThemeAuto, func() (bool, error) { calls++; return true, nil })
+
+	_, cmd := model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	runCmd(cmd)
+	if calls != 0 {
+		t.Fatalf("expected no query right after startup detection, got %d", calls)
+	}
+
+	model.lastDetect = time.Now().Add(-2 * backgroundInterval)
+	_, cmd = model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	for _, msg := range runCmd(cmd) {
+		model.Update(msg)
+	}
+	if calls != 1 || model.theme.Name != config.ThemeDawn {
+		t.Fatalf("expected one query switching to Dawn, got %d calls and %q", calls, model.theme.Name)
+	}
+
+	_, cmd = model.Update(tea.WindowSizeMsg{Width: 90, Height: 30})
+	runCmd(cmd)
+	if calls != 1 {
+		t.Fatalf("expected the next resize to be rate limited, got %d calls", calls)
+	}
+}
+
+func TestFixedThemeIgnoresBackground(t *testing.T) {
+	model, recorder := newAutoThemeModel(t, config.ThemeCanopy, func() (bool, error) { return true, nil })
+
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlR}); cmd != nil {
+		t.Fatal("expected no background query for a fixed theme")
+	}
+	model.Update(backgroundMsg{light: true})
+	if recorder.theme.Name != "" || model.theme.Name != config.ThemeCanopy {
+		t.Fatalf("expected Canopy to stay, got %q", model.theme.Name)
+	}
+}
+
+func TestDetectBackgroundTimesOut(t *testing.T) {
+	prev := backgroundTimeout
+	backgroundTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { backgroundTimeout = prev })
+
+	block := make(chan struct{})
+	defer close(block)
+	msg := detectBackground(func() (bool, error) { <-block; return true, nil })()
+	if got, ok := msg.(backgroundMsg); !ok || got.err == nil {
+		t.Fatalf("expected a timeout error, got %#v", msg)
+	}
+}
@@ -0,0 +1,72 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
+)
+
+// helpSection lists the keys of one view as key, action pairs.
+type helpSection struct {
+	title string
+	keys  [][2]string
+}
+
+// promptHelp lists the keys of the prompt card, which handles them itself
+// rather than through a binding table.
+var promptHelp = helpSection{"Prompt", [][2]string{
+	{"j/k or ↓/↑", "next/previous field"},
+	{"n/p or →/←", "next/previous option"},
+	{"1-9", "pick an option of the focused field"},
+	{"a/d/r", "allow, deny, reject"},
+	{"j/k h/l", "scroll inspect (or arrows)"},
+}}
+
+// toggleHelp opens or closes the key help overlay.
+func (m *Model) toggleHelp() {
+	m.helpOpen = !m.helpOpen
+}
+
+// renderHelp lists the global and prompt keys next to those of the active
+// view, taken from its binding tables.
+func (m *Model) renderHelp() string {
+	global := helpSection{title: "Global"}
+	for _, binding := range []key.Binding{
+		m.keymap.NextView, m.keymap.PrevView, m.keymap.Help, m.keymap.About,
+		m.keymap.DND, m.keymap.Log, m.keymap.Profile, m.keymap.SaveProfile,
+		m.keymap.RefreshTheme, m.keymap.AckWarning, m.keymap.Quit,
+	} {
+		help := binding.Help()
+		global.keys = append(global.keys, [2]string{help.Key, help.Desc})
+	}
+	columns := []string{m.renderHelpSections(global, promptHelp)}
+	if active, ok := m.activeView().(view.KeyHelp); ok {
+		section := helpSection{title: m.activeView().Title()}
+		for _, help := range active.KeyHelp() {
+			section.keys = append(section.keys, [2]string{help.KeyString(), help.Desc})
+		}
+		columns = append(columns, "    ", m.renderHelpSections(section))
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	lines := []string{m.theme.Header.Render("Keys"), "", body, "", m.theme.Subtle.Render("?/esc close")}
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func (m *Model) renderHelpSections(sections ...helpSection) string {
+	var lines []string
+	for i, section := range sections {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, m.theme.Header.Render(section.title))
+		for _, row := range section.keys {
+			lines = append(lines, fmt.Sprintf("  %s %s", m.theme.Subtle.Render(fmt.Sprintf("%-22s", row[0])), row[1]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
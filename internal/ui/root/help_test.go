@@ -0,0 +1,69 @@
+package root
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func TestHelpOverlayListsAlternativeKeys(t *testing.T) {
+	model := New(state.NewStore(), Options{Theme: theme.New(theme.Options{})})
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	out := model.View()
+	for _, want := range []string{"Keys", "next view", "Prompt", "1-9", "Dashboard", "review frequent denials"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the help overlay, got %q", want, out)
+		}
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if model.active != state.ViewDashboard {
+		t.Fatalf("expected tab swallowed by the overlay, got view %s", model.active)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if strings.Contains(model.View(), "1-9") {
+		t.Fatal("expected ? to close the overlay")
+	}
+}
+
+func TestTabStaysInOpenForm(t *testing.T) {
+	model := New(state.NewStore(), Options{Theme: theme.New(theme.Options{})})
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	model.active = state.ViewSettings
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}}) // settings filter
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if model.active != state.ViewSettings || model.helpOpen {
+		t.Fatalf("expected tab and ? to stay in the filter, got view %s, help %v", model.active, model.helpOpen)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if model.active == state.ViewSettings {
+		t.Fatal("expected tab to switch views once the filter is closed")
+	}
+}
+
+func TestHelpOverlayListsTheActiveViewsBindings(t *testing.T) {
+	model := New(state.NewStore(), Options{Theme: theme.New(theme.Options{})})
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 60})
+	model.active = state.ViewRules
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	out := model.View()
+	for _, want := range []string{"Rules", "x/delete", "delete the rule", "up/k", "hide disabled rules"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q from the rules table in the help overlay, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "review frequent denials") {
+		t.Fatalf("expected only the active view's keys, got %q", out)
+	}
+}
@@ -0,0 +1,123 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// logPaneRows is how many log entries the pane shows at once; the pane adds
+// a heading line above them.
+const logPaneRows = 10
+
+// logPaneMode is whether the log pane is hidden, shown under the view, or
+// shown and taking keys.
+type logPaneMode int
+
+const (
+	logPaneHidden logPaneMode = iota
+	logPaneShown
+	logPaneFocused
+	logPaneModeCount
+)
+
+// toggleLog steps the pane from hidden to shown to focused and back.
+func (m *Model) toggleLog() {
+	m.logMode = (m.logMode + 1) % logPaneModeCount
+	if m.logMode == logPaneHidden {
+		m.logOffset = 0
+	}
+	m.resizeViews()
+}
+
+func (m *Model) closeLog() {
+	m.logMode = logPaneHidden
+	m.logOffset = 0
+	m.resizeViews()
+}
+
+// updateLog scrolls the focused pane. Entries are newest first, so down
+// moves to older ones.
+func (m *Model) updateLog(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "esc":
+		m.closeLog()
+	case "down", "j":
+		m.logOffset++
+	case "up", "k":
+		m.logOffset--
+	case "pgdown":
+		m.logOffset += logPaneRows
+	case "pgup":
+		m.logOffset -= logPaneRows
+	case "home", "g":
+		m.logOffset = 0
+	case "end", "G":
+		m.logOffset = len(m.store.Snapshot().Log)
+	}
+	m.logOffset = clampLogOffset(m.logOffset, len(m.store.Snapshot().Log))
+}
+
+func clampLogOffset(offset, total int) int {
+	return max(0, min(offset, total-logPaneRows))
+}
+
+// bodyHeight is the height left for the active view under the header and
+// security banner and above the footer and log pane.
+func (m *Model) bodyHeight() int {
+	height := m.height - 2
+	if m.warningShown {
+		height--
+	}
+	if m.logMode != logPaneHidden {
+		height -= logPaneRows + 1
+	}
+	return max(1, height)
+}
+
+func (m *Model) resizeViews() {
+	for _, v := range m.views {
+		v.SetSize(m.width, m.bodyHeight())
+	}
+	if m.prompt != nil {
+		m.prompt.SetSize(m.width, m.bodyHeight())
+	}
+}
+
+func (m *Model) renderLog(entries []state.LogEntry) string {
+	help := "ctrl+l focus"
+	heading := m.theme.Subtle
+	if m.logMode == logPaneFocused {
+		help = "↑↓ pgup/pgdn scroll · esc/ctrl+l close"
+		heading = m.theme.Title
+	}
+	offset := clampLogOffset(m.logOffset, len(entries))
+	lines := []string{heading.Render(fmt.Sprintf("Log · %d entries · %s", len(entries), help))}
+	if len(entries) == 0 {
+		lines = append(lines, m.theme.Subtle.Render("No errors or warnings this session"))
+	}
+	width := max(20, m.width-2)
+	for _, entry := range entries[offset:min(offset+logPaneRows, len(entries))] {
+		source := ""
+		if entry.Subsystem != "" {
+			source = "[" + entry.Subsystem + "] "
+		}
+		line := util.TruncateString(fmt.Sprintf("%s %-5s %s%s", entry.At.Format("15:04:05"), entry.Severity, source, entry.Text), width)
+		switch entry.Severity {
+		case state.LogError:
+			line = m.theme.Danger.Render(line)
+		case state.LogWarning:
+			line = m.theme.Warning.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < logPaneRows+1 {
+		lines = append(lines, "")
+	}
+	return lipgloss.NewStyle().Width(max(20, m.width)).Render(strings.Join(lines, "\n"))
+}
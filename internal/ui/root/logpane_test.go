@@ -0,0 +1,75 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// keyRecorder is a view that counts the keys it receives and remembers its
+// height.
+type keyRecorder struct {
+	themeRecorder
+	keys   int
+	height int
+}
+
+func (r *keyRecorder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		r.keys++
+	}
+	return r, nil
+}
+func (r *keyRecorder) SetSize(_, height int) { r.height = height }
+
+func TestLogPaneShowsFocusesAndScrolls(t *testing.T) {
+	store := state.NewStore()
+	for i := range 15 {
+		store.ReportError(state.SubsystemDaemon, fmt.Sprintf("failure %02d", i))
+	}
+	model := New(store, Options{Theme: theme.New(theme.Options{})})
+	defer model.closeSubscription()
+	recorder := &keyRecorder{}
+	model.views[state.ViewDashboard] = recorder
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	ctrlL := tea.KeyMsg{Type: tea.KeyCtrlL}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	model.Update(ctrlL)
+	if recorder.height != 40-2-(logPaneRows+1) {
+		t.Fatalf("expected the view to shrink for the pane, got height %d", recorder.height)
+	}
+	out := model.View()
+	if !strings.Contains(out, "ERROR [daemon] failure 14") || strings.Contains(out, "failure 04") {
+		t.Fatalf("expected the newest %d entries, got: %s", logPaneRows, out)
+	}
+	model.Update(down)
+	if recorder.keys != 1 || model.logOffset != 0 {
+		t.Fatalf("expected keys to reach the view while the pane is only shown, got %d keys, offset %d", recorder.keys, model.logOffset)
+	}
+
+	model.Update(ctrlL)
+	for range 20 {
+		model.Update(down)
+	}
+	if recorder.keys != 1 {
+		t.Fatalf("expected the focused pane to keep keys from the view, got %d", recorder.keys)
+	}
+	out = model.View()
+	if !strings.Contains(out, "failure 00") || strings.Contains(out, "[daemon] failure 14") {
+		t.Fatalf("expected scrolling to stop at the oldest entries, got: %s", out)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.logMode != logPaneHidden || recorder.height != 40-2 {
+		t.Fatalf("expected esc to hide the pane and restore the view height, got mode %d height %d", model.logMode, recorder.height)
+	}
+	if strings.Contains(model.View(), "failure 00") {
+		t.Fatal("expected the pane gone from the view")
+	}
+}
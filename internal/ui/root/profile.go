@@ -0,0 +1,106 @@
+package root
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
+)
+
+// cycleProfile applies the profile after the active one, wrapping around.
+func (m *Model) cycleProfile() {
+	profiles := m.store.Snapshot().Settings.Profiles
+	if len(profiles) == 0 {
+		m.notice = m.theme.Warning.Render("No profiles yet; ctrl+s saves the current layout as one")
+		return
+	}
+	next := 0
+	for idx, p := range profiles {
+		if p.Name == m.profile {
+			next = (idx + 1) % len(profiles)
+			break
+		}
+	}
+	m.applyProfile(profiles[next])
+}
+
+// applyProfile hands the profile to every view and switches to its view.
+func (m *Model) applyProfile(profile state.Profile) {
+	for _, v := range m.views {
+		if layout, ok := v.(view.Layout); ok {
+			layout.ApplyProfile(profile)
+		}
+	}
+	if _, ok := m.views[profile.View]; ok {
+		m.active = profile.View
+		m.store.SetActiveView(m.active)
+	}
+	m.profile = profile.Name
+	m.notice = ""
+}
+
+// startSaveProfile asks for the name to save the current layout under,
+// suggesting the active profile's.
+func (m *Model) startSaveProfile() {
+	input := textinput.New()
+	input.Prompt = "Save profile as: "
+	input.Placeholder = "name"
+	input.CharLimit = 40
+	input.Width = 24
+	input.SetValue(m.profile)
+	input.Focus()
+	m.profileInput = &input
+	m.notice = ""
+}
+
+func (m *Model) updateProfileInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.profileInput = nil
+		return nil
+	case "enter":
+		name := strings.TrimSpace(m.profileInput.Value())
+		m.profileInput = nil
+		if name != "" {
+			m.saveProfile(name)
+		}
+		return nil
+	}
+	input, cmd := m.profileInput.Update(msg)
+	m.profileInput = &input
+	return cmd
+}
+
+// saveProfile captures every view's layout and the active view under name.
+// A profile that could not be written to disk still applies for the session.
+func (m *Model) saveProfile(name string) {
+	profile := state.Profile{Name: name, View: m.active}
+	for _, v := range m.views {
+		if layout, ok := v.(view.Layout); ok {
+			layout.CaptureProfile(&profile)
+		}
+	}
+	m.notice = m.theme.Success.Render(fmt.Sprintf("Saved profile %s", name))
+	if m.settings != nil {
+		saved, err := m.settings.SaveProfile(profile)
+		switch {
+		case errors.Is(err, controller.ErrNotPersisted):
+			m.notice = m.theme.Warning.Render(fmt.Sprintf("Profile %s kept for this session only: %v", name, err))
+		case err != nil:
+			m.notice = m.theme.Danger.Render(fmt.Sprintf("Failed to save profile: %v", err))
+			return
+		default:
+			profile = saved
+		}
+	}
+	settings := m.store.Snapshot().Settings
+	settings.Profiles = state.WithProfile(settings.Profiles, profile)
+	m.store.SetSettings(settings)
+	m.profile = profile.Name
+}
@@ -0,0 +1,88 @@
+package root
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/settings"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func newProfileModel(t *testing.T, profiles []state.Profile) (*Model, *settings.Manager) {
+	t.Helper()
+	store := state.NewStore()
+	s := store.Snapshot().Settings
+	s.Profiles = profiles
+	store.SetSettings(s)
+	mgr := settings.NewManager(filepath.Join(t.TempDir(), "config.yaml"), config.Default())
+	model := New(store, Options{Theme: theme.New(theme.Options{}), Settings: mgr})
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return model, mgr
+}
+
+func captured(m *Model) state.Profile {
+	var p state.Profile
+	for _, v := range m.views {
+		if layout, ok := v.(view.Layout); ok {
+			layout.CaptureProfile(&p)
+		}
+	}
+	return p
+}
+
+func TestProfileKeyCyclesProfiles(t *testing.T) {
+	model, _ := newProfileModel(t, []state.Profile{
+		{Name: "triage", View: state.ViewEvents, Events: state.EventsLayout{Filter: "deny", Sort: "process"}},
+		{Name: "audit", View: state.ViewRules, Rules: state.RulesLayout{HideDisabled: true, Sort: "name"}},
+	})
+	ctrlW := tea.KeyMsg{Type: tea.KeyCtrlW}
+
+	model.Update(ctrlW)
+	if model.active != state.ViewEvents || captured(model).Events.Filter != "deny" {
+		t.Fatalf("expected triage applied, got view %s and %+v", model.active, captured(model).Events)
+	}
+	if footer := util.StripANSI(model.View()); !strings.Contains(footer, "Profile triage") {
+		t.Fatalf("expected the footer to name the profile, got:\n%s", footer)
+	}
+
+	model.Update(ctrlW)
+	if got := captured(model); model.active != state.ViewRules || !got.Rules.HideDisabled || got.Events.Filter != "" {
+		t.Fatalf("expected audit applied, got view %s and %+v", model.active, got)
+	}
+	model.Update(ctrlW)
+	if model.profile != "triage" {
+		t.Fatalf("expected cycling to wrap around, got %q", model.profile)
+	}
+}
+
+func TestSaveProfileCapturesViews(t *testing.T) {
+	model, mgr := newProfileModel(t, nil)
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if line := util.StripANSI(model.footerLine(model.store.Snapshot())); !strings.Contains(line, "No profiles yet") {
+		t.Fatalf("expected a hint without profiles, got %q", line)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyTab}) // events
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("mine")})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	profiles := model.store.Snapshot().Settings.Profiles
+	if len(profiles) != 1 || profiles[0].Name != "mine" || profiles[0].View != state.ViewEvents || profiles[0].Events.Filter != "allow" {
+		t.Fatalf("unexpected profiles %+v", profiles)
+	}
+	if saved := mgr.Config().Profiles; len(saved) != 1 || saved[0].View != "events" || saved[0].Rules.Sort != "daemon" {
+		t.Fatalf("expected the profile persisted, got %+v", saved)
+	}
+	if !strings.Contains(model.footerLine(model.store.Snapshot()), "Profile mine") {
+		t.Fatalf("expected the saved profile active")
+	}
+}
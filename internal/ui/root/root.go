@@ -2,24 +2,33 @@ package root
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/container"
 	"github.com/adamkadaban/opensnitch-tui/internal/controller"
 	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/prompt"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/views/alerts"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/views/connections"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/views/dashboard"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/views/events"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/views/nodes"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/views/rules"
 	settingsview "github.com/adamkadaban/opensnitch-tui/internal/ui/views/settings"
+	"github.com/adamkadaban/opensnitch-tui/internal/version"
 )
 
 // Options controls how the root model is assembled.
@@ -29,16 +38,60 @@ type Options struct {
 	Rules    controller.RuleManager
 	Prompts  controller.PromptManager
 	Settings controller.SettingsManager
+	Firewall controller.FirewallManager
+	// Baselines marks node counter baselines from the dashboard; nil
+	// disables it.
+	Baselines controller.BaselineManager
+	// Wire renders events as protobuf text for debugging; nil disables it.
+	Wire controller.WireInspector
+	// DetectBackground reports whether the terminal background is light;
+	// the auto theme re-runs it on resize and ctrl+r. Nil disables it. Theme
+	// is expected to reflect a detection made just before New.
+	DetectBackground func() (bool, error)
+	// StartView is the view shown first; empty or unknown views fall back
+	// to the dashboard.
+	StartView state.ViewKind
+	// About is shown in the about overlay.
+	About About
+	// Output is the terminal the program renders to; clipboard copies are
+	// written there. Nil drops them.
+	Output io.Writer
 }
 
 // Model orchestrates routed Bubble Tea views and global UI chrome.
 type Model struct {
-	store     *state.Store
-	sub       *state.Subscription
-	keymap    keymap.Global
-	theme     theme.Theme
-	themeName string
-	prompt    *prompt.Model
+	store  *state.Store
+	sub    *state.Subscription
+	keymap keymap.Global
+	theme  theme.Theme
+	// themeName is the configured theme, possibly "auto"; light is the
+	// last detected terminal background.
+	themeName  string
+	light      bool
+	detect     func() (bool, error)
+	lastDetect time.Time
+	prompt     *prompt.Model
+	// logMode and logOffset are the session log pane's state; the offset
+	// counts entries scrolled past from the newest.
+	logMode   logPaneMode
+	logOffset int
+	// settings saves workspace profiles; profile is the one last applied
+	// or saved, and profileInput the open "save as" prompt.
+	settings     controller.SettingsManager
+	profile      string
+	profileInput *textinput.Model
+	// notice is a one-off message shown in the footer until the next key.
+	notice string
+	// aboutOpen shows the about overlay over the active view.
+	aboutOpen bool
+	about     About
+	build     version.Info
+	// helpOpen shows the key help overlay over the active view.
+	helpOpen bool
+	// warningShown is set while the security warning banner is drawn.
+	warningShown bool
+	// output receives clipboard copies; see clipboard.Msg.
+	output io.Writer
 
 	views  map[state.ViewKind]view.Model
 	order  []state.ViewKind
@@ -56,12 +109,13 @@ func New(store *state.Store, opts Options) *Model {
 	}
 
 	views := map[state.ViewKind]view.Model{
-		state.ViewDashboard: dashboard.New(store, opts.Theme),
-		state.ViewAlerts:    alerts.New(store, opts.Theme),
-		state.ViewEvents:    events.New(store, opts.Theme),
-		state.ViewRules:     rules.New(store, opts.Theme, opts.Rules),
-		state.ViewNodes:     nodes.New(store, opts.Theme),
-		state.ViewSettings:  settingsview.New(store, opts.Theme, opts.Settings),
+		state.ViewDashboard:   dashboard.New(store, opts.Theme, opts.Baselines, opts.Rules),
+		state.ViewAlerts:      alerts.New(store, opts.Theme),
+		state.ViewEvents:      events.New(store, opts.Theme, opts.Wire, opts.Rules),
+		state.ViewConnections: connections.New(store, opts.Theme),
+		state.ViewRules:       rules.New(store, opts.Theme, opts.Rules),
+		state.ViewNodes:       nodes.New(store, opts.Theme, opts.Firewall, opts.Settings),
+		state.ViewSettings:    settingsview.New(store, opts.Theme, opts.Settings),
 	}
 
 	promptModel := prompt.New(store, opts.Theme, opts.Prompts)
@@ -71,20 +125,51 @@ func New(store *state.Store, opts Options) *Model {
 		keymap:    keyMap,
 		theme:     opts.Theme,
 		themeName: theme.Normalize(opts.Theme.Name),
+		light:     opts.Theme.IsLight,
+		detect:    opts.DetectBackground,
 		prompt:    promptModel,
+		settings:  opts.Settings,
+		about:     opts.About,
+		output:    opts.Output,
+		build:     version.Current(),
 		views:     views,
 		order:     append([]state.ViewKind{}, state.DefaultViewOrder...),
 		active:    state.ViewDashboard,
 	}
+	if _, ok := views[opts.StartView]; ok {
+		model.active = opts.StartView
+	}
 	if store != nil {
+		store.SetActiveView(model.active)
 		model.sub = store.Subscribe()
-		model.applyTheme(theme.New(theme.Options{Name: store.Snapshot().Settings.ThemeName}))
+		model.setTheme(theme.Normalize(store.Snapshot().Settings.ThemeName))
+		model.warningShown = store.Snapshot().SecurityWarning != ""
+	}
+	if model.themeName == config.ThemeAuto {
+		model.lastDetect = time.Now()
 	}
 	return model
 }
 
 type storeChangeMsg struct{}
 
+// dndExpiredMsg asks for a redraw once a timed do-not-disturb window ends.
+type dndExpiredMsg struct{}
+
+// staleCheckInterval is how often views are redrawn while nothing else
+// changes, so a node that went quiet is flagged as stale.
+const staleCheckInterval = 10 * time.Second
+
+type staleTickMsg struct{}
+
+// containerNamedMsg asks for a redraw once a container name resolved in the
+// background.
+type containerNamedMsg struct{}
+
+func staleTick() tea.Cmd {
+	return tea.Tick(staleCheckInterval, func(time.Time) tea.Msg { return staleTickMsg{} })
+}
+
 func (m *Model) Init() tea.Cmd {
 	cmds := make([]tea.Cmd, 0, len(m.views))
 	for _, v := range m.views {
@@ -93,39 +178,94 @@ func (m *Model) Init() tea.Cmd {
 	if m.prompt != nil {
 		cmds = append(cmds, m.prompt.Init())
 	}
-	cmds = append(cmds, waitForStoreChanges(m.sub))
+	cmds = append(cmds, waitForStoreChanges(m.sub), staleTick(), waitForContainerNames())
 	return tea.Batch(cmds...)
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(clipboard.Msg); ok {
+		if m.output != nil {
+			clipboard.Write(m.output, msg)
+		}
+		return m, nil
+	}
 	if m.prompt != nil {
 		if cmd, handled := m.prompt.Update(msg); handled {
 			return m, cmd
 		}
 	}
 
+	var redetect tea.Cmd
 	switch msg := msg.(type) {
 	case storeChangeMsg:
 		m.onStoreChanged()
 		return m, waitForStoreChanges(m.sub)
+	case dndExpiredMsg:
+		return m, nil
+	case staleTickMsg:
+		return m, staleTick()
+	case containerNamedMsg:
+		return m, waitForContainerNames()
+	case backgroundMsg:
+		m.onBackground(msg)
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		for _, v := range m.views {
-			v.SetSize(msg.Width, max(1, msg.Height-2))
-		}
-		if m.prompt != nil {
-			m.prompt.SetSize(msg.Width, max(1, msg.Height-2))
-		}
+		m.resizeViews()
+		redetect = m.redetectBackground(time.Now(), false)
 
 	case tea.KeyMsg:
+		m.notice = ""
+		if m.profileInput != nil && !key.Matches(msg, m.keymap.Quit) {
+			return m, m.updateProfileInput(msg)
+		}
+		if m.aboutOpen && !key.Matches(msg, m.keymap.Quit) {
+			if key.Matches(msg, m.keymap.About) || msg.String() == "esc" {
+				m.aboutOpen = false
+			}
+			return m, nil
+		}
+		if m.helpOpen && !key.Matches(msg, m.keymap.Quit) {
+			if key.Matches(msg, m.keymap.Help) || msg.String() == "esc" {
+				m.helpOpen = false
+			}
+			return m, nil
+		}
+		typing := m.enteringText()
 		switch {
 		case key.Matches(msg, m.keymap.Quit):
 			return m, tea.Quit
-		case key.Matches(msg, m.keymap.NextView):
+		case !typing && key.Matches(msg, m.keymap.NextView):
 			m.cycle(1)
-		case key.Matches(msg, m.keymap.PrevView):
+		case !typing && key.Matches(msg, m.keymap.PrevView):
 			m.cycle(-1)
+		case !typing && key.Matches(msg, m.keymap.Help):
+			m.toggleHelp()
+			return m, nil
+		case key.Matches(msg, m.keymap.DND):
+			return m, m.toggleDND(time.Now())
+		case key.Matches(msg, m.keymap.RefreshTheme):
+			return m, m.redetectBackground(time.Now(), true)
+		case key.Matches(msg, m.keymap.Log):
+			m.toggleLog()
+			return m, nil
+		case key.Matches(msg, m.keymap.Profile):
+			m.cycleProfile()
+			return m, nil
+		case key.Matches(msg, m.keymap.SaveProfile):
+			m.startSaveProfile()
+			return m, nil
+		case key.Matches(msg, m.keymap.About):
+			m.toggleAbout()
+			return m, nil
+		case m.warningShown && key.Matches(msg, m.keymap.AckWarning):
+			m.acknowledgeWarning()
+			return m, nil
+		}
+		if m.logMode == logPaneFocused {
+			m.updateLog(msg)
+			return m, nil
 		}
 
 	case tea.QuitMsg:
@@ -138,7 +278,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.views[m.active] = nextView
 	}
 
-	return m, cmd
+	return m, tea.Batch(cmd, redetect)
 }
 
 func (m *Model) View() string {
@@ -147,10 +287,18 @@ func (m *Model) View() string {
 		return ""
 	}
 
-	headline := lipgloss.JoinHorizontal(lipgloss.Top,
+	snapshot := m.store.Snapshot()
+	headerParts := []string{
 		m.theme.Title.Render("OpenSnitch TUI"),
 		lipgloss.NewStyle().Padding(0, 1).Render(m.renderTabs()),
-	)
+	}
+	if badge := m.dndBadge(snapshot.Settings, time.Now()); badge != "" {
+		headerParts = append(headerParts, badge)
+	}
+	headline := lipgloss.JoinHorizontal(lipgloss.Top, headerParts...)
+	if snapshot.SecurityWarning != "" {
+		headline = lipgloss.JoinVertical(lipgloss.Left, headline, m.renderWarningBanner(snapshot))
+	}
 
 	body := activeView.View()
 	if m.prompt != nil {
@@ -158,16 +306,31 @@ func (m *Model) View() string {
 			body = overlay
 		}
 	}
-	snapshot := m.store.Snapshot()
+	if m.aboutOpen {
+		body = m.renderAbout()
+	}
+	if m.helpOpen {
+		body = m.renderHelp()
+	}
+	if m.logMode != logPaneHidden {
+		body = lipgloss.JoinVertical(lipgloss.Left, body, m.renderLog(snapshot.Log))
+	}
 	footer := m.theme.Footer.Render(m.footerLine(snapshot))
 
-	return lipgloss.JoinVertical(lipgloss.Left, headline, body, footer)
+	return glyphs.Text(lipgloss.JoinVertical(lipgloss.Left, headline, body, footer))
 }
 
 func (m *Model) activeView() view.Model {
 	return m.views[m.active]
 }
 
+// enteringText reports whether the active view holds the keyboard for a
+// form or text field.
+func (m *Model) enteringText() bool {
+	entry, ok := m.activeView().(view.TextEntry)
+	return ok && entry.EnteringText()
+}
+
 func (m *Model) cycle(delta int) {
 	if len(m.order) == 0 {
 		return
@@ -205,6 +368,7 @@ func (m *Model) onStoreChanged() {
 		return
 	}
 	snapshot := m.store.Snapshot()
+	m.syncWarningBanner(snapshot)
 	desired := theme.Normalize(snapshot.Settings.ThemeName)
 	if desired == "" {
 		desired = m.themeName
@@ -212,7 +376,14 @@ func (m *Model) onStoreChanged() {
 	if desired == m.themeName {
 		return
 	}
-	m.applyTheme(theme.New(theme.Options{Name: desired}))
+	m.setTheme(desired)
+}
+
+// setTheme switches to the named theme; "auto" uses the last detected
+// background.
+func (m *Model) setTheme(name string) {
+	m.themeName = name
+	m.applyTheme(theme.New(theme.Options{Name: name, Light: m.light}))
 }
 
 func (m *Model) applyTheme(th theme.Theme) {
@@ -220,7 +391,6 @@ func (m *Model) applyTheme(th theme.Theme) {
 		return
 	}
 	m.theme = th
-	m.themeName = th.Name
 	for _, v := range m.views {
 		v.SetTheme(th)
 	}
@@ -229,14 +399,63 @@ func (m *Model) applyTheme(th theme.Theme) {
 	}
 }
 
+// toggleDND switches do-not-disturb using the configured preset length and
+// schedules a redraw for when a timed window runs out.
+func (m *Model) toggleDND(now time.Time) tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	settings := m.store.Snapshot().Settings
+	if settings.DNDActive(now) {
+		settings.DNDEnabled = false
+		settings.DNDUntil = time.Time{}
+		m.store.SetSettings(settings)
+		return nil
+	}
+	settings.DNDEnabled = true
+	settings.DNDUntil = time.Time{}
+	if settings.DNDMinutes > 0 {
+		settings.DNDUntil = now.Add(time.Duration(settings.DNDMinutes) * time.Minute)
+	}
+	m.store.SetSettings(settings)
+	if settings.DNDUntil.IsZero() {
+		return nil
+	}
+	return tea.Tick(settings.DNDUntil.Sub(now), func(time.Time) tea.Msg { return dndExpiredMsg{} })
+}
+
+func (m *Model) dndBadge(settings state.Settings, now time.Time) string {
+	if !settings.DNDActive(now) {
+		return ""
+	}
+	label := "DND"
+	if !settings.DNDUntil.IsZero() {
+		label = fmt.Sprintf("DND until %s", settings.DNDUntil.Format("15:04"))
+	}
+	return m.theme.Warning.Render(label)
+}
+
 func (m *Model) footerLine(snapshot state.Snapshot) string {
+	if m.profileInput != nil {
+		return m.profileInput.View() + " · enter save · esc cancel"
+	}
 	nodes := len(snapshot.Nodes)
-	line := fmt.Sprintf("View %s · Nodes %d · %s", titleCase(string(snapshot.ActiveView)), nodes, m.keymap.ShortHelp())
+	line := fmt.Sprintf("View %s · Nodes %d", titleCase(string(snapshot.ActiveView)), nodes)
+	if m.profile != "" {
+		line = fmt.Sprintf("%s · Profile %s", line, m.profile)
+	}
+	line = fmt.Sprintf("%s · %s", line, m.keymap.ShortHelp())
+	if m.notice != "" {
+		line = fmt.Sprintf("%s · %s", line, m.notice)
+	}
 	if snapshot.LastError != "" {
 		line = fmt.Sprintf("%s · %s", line, m.theme.Danger.Render(snapshot.LastError))
 	}
+	if snapshot.ConfigWarning != "" {
+		line = fmt.Sprintf("%s · %s", line, m.theme.Warning.Render(snapshot.ConfigWarning))
+	}
 	if !snapshot.Settings.AlertsInterrupt && len(snapshot.Prompts) > 0 && snapshot.ActiveView != state.ViewAlerts {
-		indicator := m.theme.Danger.Render("● alerts pending")
+		indicator := m.theme.Danger.Render(glyphs.Current().Dot + " alerts pending")
 		line = fmt.Sprintf("%s · %s", line, indicator)
 	}
 	return line
@@ -269,3 +488,10 @@ func waitForStoreChanges(sub *state.Subscription) tea.Cmd {
 		return storeChangeMsg{}
 	}
 }
+
+func waitForContainerNames() tea.Cmd {
+	return func() tea.Msg {
+		<-container.Local.Updates()
+		return containerNamedMsg{}
+	}
+}
@@ -1,12 +1,21 @@
 package root
 
 import (
+	"bytes"
+	"encoding/base64"
 	"strings"
 	"testing"
+	"time"
+	"unicode"
 
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
 	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
 func TestFooterLineIncludesError(t *testing.T) {
@@ -34,3 +43,143 @@ func TestFooterLineWithoutError(t *testing.T) {
 		t.Fatalf("did not expect footer to include error text, got %q", line)
 	}
 }
+
+func TestToggleDNDUsesPresetAndShowsBadge(t *testing.T) {
+	store := state.NewStore()
+	model := New(store, Options{Theme: theme.New(theme.Options{})})
+	now := time.Date(2024, time.March, 1, 14, 2, 0, 0, time.Local)
+
+	if cmd := model.toggleDND(now); cmd == nil {
+		t.Fatalf("expected expiry tick for timed DND")
+	}
+	settings := store.Snapshot().Settings
+	if !settings.DNDActive(now) || !settings.DNDUntil.Equal(now.Add(30*time.Minute)) {
+		t.Fatalf("expected 30 minute DND window, got %+v", settings)
+	}
+	if badge := model.dndBadge(settings, now); !strings.Contains(badge, "DND until 14:32") {
+		t.Fatalf("expected DND badge, got %q", badge)
+	}
+	if badge := model.dndBadge(settings, now.Add(31*time.Minute)); badge != "" {
+		t.Fatalf("expected badge to disappear after expiry, got %q", badge)
+	}
+
+	model.toggleDND(now.Add(time.Minute))
+	if store.Snapshot().Settings.DNDActive(now.Add(time.Minute)) {
+		t.Fatalf("expected second toggle to switch DND off")
+	}
+}
+
+func TestNewOpensOnStartView(t *testing.T) {
+	store := state.NewStore()
+	model := New(store, Options{Theme: theme.New(theme.Options{}), StartView: state.ViewEvents})
+	defer model.closeSubscription()
+	if model.active != state.ViewEvents {
+		t.Fatalf("active = %q, want events", model.active)
+	}
+	if got := store.ActiveView(); got != state.ViewEvents {
+		t.Fatalf("store ActiveView = %q, want events", got)
+	}
+
+	fallback := New(state.NewStore(), Options{Theme: theme.New(theme.Options{}), StartView: "firewall"})
+	defer fallback.closeSubscription()
+	if fallback.active != state.ViewDashboard {
+		t.Fatalf("unknown start view: active = %q, want dashboard", fallback.active)
+	}
+}
+
+func TestClipboardCopiesAreWrittenToTheProgramOutput(t *testing.T) {
+	var out bytes.Buffer
+	model := New(state.NewStore(), Options{Theme: theme.New(theme.Options{}), Output: &out})
+	defer model.closeSubscription()
+
+	if _, cmd := model.Update(clipboard.Copy("ssh-ed25519")()); cmd != nil {
+		t.Fatalf("expected no follow-up command, got %v", cmd)
+	}
+	if want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("ssh-ed25519")); !strings.Contains(out.String(), want) {
+		t.Fatalf("expected OSC 52 sequence %q, got %q", want, out.String())
+	}
+}
+
+func TestFooterLineShowsConfigWarning(t *testing.T) {
+	model := &Model{keymap: keymap.DefaultGlobal(), theme: theme.New(theme.Options{})}
+	snapshot := state.Snapshot{ActiveView: state.ViewSettings, ConfigWarning: "settings not saved: save /ro/config.yaml: permission denied"}
+
+	if line := model.footerLine(snapshot); !strings.Contains(line, "/ro/config.yaml") {
+		t.Fatalf("expected footer to include the config warning, got %q", line)
+	}
+}
+
+func TestASCIIModeRendersEveryViewInASCII(t *testing.T) {
+	glyphs.SetASCII(true)
+	t.Cleanup(func() { glyphs.SetASCII(false) })
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", Status: state.NodeStatusReady, FirewallEnabled: true, FirewallKnown: true}})
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: 1, Connection: state.Connection{DstHost: "example.com", DstPort: 443, ProcessPath: "/usr/bin/curl"}}})
+	model := New(store, Options{Theme: theme.New(theme.Options{})})
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	for _, kind := range state.DefaultViewOrder {
+		model.active = kind
+		out := model.View()
+		for _, r := range out {
+			if r > unicode.MaxASCII {
+				t.Fatalf("%s: expected only ASCII, found %q in:\n%s", kind, r, out)
+			}
+		}
+	}
+}
+
+func TestSecurityWarningBannerUntilAcknowledged(t *testing.T) {
+	store := state.NewStore()
+	model := New(store, Options{Theme: theme.New(theme.Options{})})
+	defer model.closeSubscription()
+	recorder := &keyRecorder{}
+	model.views[state.ViewDashboard] = recorder
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+
+	store.SetSecurityWarning("listening on 0.0.0.0:50051 without TLS — any host on the network can manage rules")
+	model.Update(storeChangeMsg{})
+	if out := util.StripANSI(model.View()); !strings.Contains(out, "listening on 0.0.0.0:50051 without TLS") || !strings.Contains(out, "ctrl+g acknowledge warning") {
+		t.Fatalf("expected the warning banner, got: %s", out)
+	}
+	if recorder.height != 40-3 {
+		t.Fatalf("expected the banner to take a line from the view, got height %d", recorder.height)
+	}
+
+	// Other keys leave it up.
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if store.Snapshot().SecurityWarning == "" {
+		t.Fatal("expected the banner to stay until acknowledged")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	model.Update(storeChangeMsg{})
+	if out := util.StripANSI(model.View()); strings.Contains(out, "without TLS") || recorder.height != 40-2 {
+		t.Fatalf("expected ctrl+g to dismiss the banner, got height %d: %s", recorder.height, out)
+	}
+	if log := store.Snapshot().Log; len(log) == 0 || !strings.Contains(log[0].Text, "without TLS") {
+		t.Fatalf("expected the warning kept in the session log, got %+v", log)
+	}
+}
+
+func TestDenialReviewResumesAfterTabbingAway(t *testing.T) {
+	store := state.NewStore()
+	var events []state.Event
+	for i, host := range []string{"a.example", "b.example", "c.example"} {
+		events = append(events, state.Event{NodeID: "node-1", UnixNano: int64(i + 1), Connection: state.Connection{ProcessPath: "/usr/bin/curl", DstHost: host}, Rule: state.Rule{Action: "deny"}})
+	}
+	store.MergeEvents(events)
+	model := New(store, Options{Theme: theme.New(theme.Options{})})
+	defer model.closeSubscription()
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if model.active == state.ViewDashboard {
+		t.Fatalf("expected tab to leave the dashboard mid-review")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if out := util.StripANSI(model.View()); !strings.Contains(out, "Review denials · 2 of 3") {
+		t.Fatalf("expected the review where it was left, got:\n%s", out)
+	}
+}
@@ -0,0 +1,30 @@
+package root
+
+import (
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// syncWarningBanner resizes the views when the security banner appears or
+// goes, since it takes a line from them.
+func (m *Model) syncWarningBanner(snapshot state.Snapshot) {
+	shown := snapshot.SecurityWarning != ""
+	if shown == m.warningShown {
+		return
+	}
+	m.warningShown = shown
+	m.resizeViews()
+}
+
+// acknowledgeWarning dismisses the security banner; the warning stays in
+// the session log.
+func (m *Model) acknowledgeWarning() {
+	m.store.SetSecurityWarning("")
+}
+
+func (m *Model) renderWarningBanner(snapshot state.Snapshot) string {
+	help := m.keymap.AckWarning.Help()
+	line := glyphs.Current().Warning + " " + snapshot.SecurityWarning + " · " + help.Key + " " + help.Desc
+	return m.theme.Danger.Bold(true).Render(util.TruncateString(line, max(20, m.width)))
+}
@@ -1,6 +1,8 @@
 package view
 
 import (
+	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -12,3 +14,24 @@ type Model interface {
 	SetTheme(theme theme.Theme)
 	Title() string
 }
+
+// Layout is implemented by views whose layout workspace profiles store.
+// ApplyProfile switches the view to a profile's layout; CaptureProfile
+// records the current layout into one.
+type Layout interface {
+	ApplyProfile(profile state.Profile)
+	CaptureProfile(profile *state.Profile)
+}
+
+// KeyHelp is implemented by views whose keys come from binding tables; the
+// key help overlay lists them under the view's title.
+type KeyHelp interface {
+	KeyHelp() []keymap.Help
+}
+
+// TextEntry is implemented by views that open forms or text fields. While
+// EnteringText reports true the root leaves tab, shift+tab and ? to the
+// view, so they move between fields or get typed instead of switching views.
+type TextEntry interface {
+	EnteringText() bool
+}
@@ -50,7 +50,7 @@ func (m *Model) View() string {
 		}
 	}
 	for idx := 0; idx < maxRows; idx++ {
-		rows = append(rows, m.renderAlert(snapshot.Alerts[idx]))
+		rows = append(rows, m.renderAlert(snapshot.Nodes, snapshot.Alerts[idx]))
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
@@ -68,7 +68,7 @@ func (m *Model) SetTheme(th theme.Theme) {
 	m.theme = th
 }
 
-func (m *Model) renderAlert(alert state.Alert) string {
+func (m *Model) renderAlert(nodes []state.Node, alert state.Alert) string {
 	left := fmt.Sprintf("[%s][%s] %s", strings.ToUpper(alert.Priority), strings.ToUpper(alert.Type), alert.Text)
 	meta := []string{}
 	if alert.NodeID != "" {
@@ -82,9 +82,19 @@ func (m *Model) renderAlert(alert state.Alert) string {
 	if alert.Action != "" {
 		meta = append(meta, fmt.Sprintf("action %s", strings.ToLower(alert.Action)))
 	}
+	title := m.theme.Title
+	if alert.Suppressed {
+		// Kept for the record, but dimmed so it does not read as new.
+		title = m.theme.Subtle
+		meta = append(meta, "suppressed during maintenance")
+	}
+	details := m.theme.Subtle.Render(strings.Join(meta, " · "))
+	if alert.NodeID != "" {
+		details = m.theme.AccentMark(state.NodeKeyOf(nodes, alert.NodeID)) + " " + details
+	}
 	line := lipgloss.JoinVertical(lipgloss.Left,
-		m.theme.Title.Width(max(1, m.width-4)).Render(left),
-		m.theme.Subtle.Render(strings.Join(meta, " · ")),
+		title.Width(max(1, m.width-4)).Render(left),
+		details,
 	)
 	return m.theme.Card.Width(max(20, m.width-4)).Render(line)
 }
@@ -45,3 +45,15 @@ func TestAlertsViewRendersAlert(t *testing.T) {
 		t.Fatalf("expected priority label in view, got %q", out)
 	}
 }
+
+func TestAlertsViewMarksSuppressed(t *testing.T) {
+	store := state.NewStore()
+	store.AddAlert(state.Alert{ID: "1", NodeID: "node-1", Text: "disk full", Priority: "high", Type: "warning", Suppressed: true})
+
+	m := New(store, theme.New(theme.Options{}))
+	m.SetSize(80, 10)
+
+	if out := m.View(); !strings.Contains(out, "suppressed during maintenance") {
+		t.Fatalf("expected suppressed marker, got %q", out)
+	}
+}
@@ -0,0 +1,305 @@
+// Package connections renders the live connection feed: every connection a
+// daemon asked about or reported, in arrival order.
+package connections
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/components/table"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/util/netnames"
+)
+
+// Model shows the store's connection feed, newest first, with the detail
+// of the selected connection below the table.
+type Model struct {
+	store *state.Store
+	theme theme.Theme
+
+	width  int
+	height int
+
+	rowIdx        int
+	tableOffset   int
+	tableXOffset  int
+	tableMaxWidth int
+	// pinned is the Seq of the selected connection, so the selection stays
+	// on it while newer connections push it down; zero follows the newest.
+	pinned uint64
+}
+
+const (
+	tableChrome  = 14
+	minTableRows = 3
+	maxTableRows = 10
+	columnGap    = 1
+	timeLayout   = "15:04:05"
+)
+
+// columns are the table's column widths; process takes what is left.
+type columns struct {
+	cursor, seq, time, source, action, node, dst, proto, process int
+}
+
+func (c columns) widths() []int {
+	return []int{c.cursor, c.seq, c.time, c.source, c.action, c.node, c.dst, c.proto, c.process}
+}
+
+func New(store *state.Store, th theme.Theme) view.Model {
+	return &Model{store: store, theme: th}
+}
+
+func (m *Model) Init() tea.Cmd { return nil }
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	snapshot := m.store.Snapshot()
+	m.follow(snapshot.Connections)
+	cmd, _ := keyTable.Dispatch(key.String(), &keyContext{m: m, snapshot: snapshot})
+	m.pin(snapshot.Connections)
+	return m, cmd
+}
+
+// Title carries the number of connections seen this session, so the feed
+// can be watched from the other views.
+func (m *Model) Title() string {
+	return fmt.Sprintf("Connections (%d)", m.store.ConnectionsSeen())
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *Model) SetTheme(th theme.Theme) {
+	m.theme = th
+}
+
+func (m *Model) View() string {
+	snapshot := m.store.Snapshot()
+	conns := snapshot.Connections
+	m.follow(conns)
+	if len(conns) == 0 {
+		return m.wrap(m.theme.Subtle.Render("No connections yet; they appear here as daemons report them."))
+	}
+	sections := []string{
+		m.renderTable(conns, snapshot.Nodes),
+		m.renderDetail(snapshot, conns[m.rowIdx]),
+		m.theme.Subtle.Render("↑↓ pgup/pgdn move · home/end first/last · ←/→ scroll"),
+	}
+	return m.wrap(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// follow moves the selection back onto the pinned connection, or keeps it
+// on the newest one while nothing is pinned, and scrolls it into view.
+func (m *Model) follow(conns []state.ConnectionEvent) {
+	m.rowIdx = 0
+	if m.pinned != 0 {
+		if len(conns) > 0 && conns[len(conns)-1].Seq > m.pinned {
+			// The pinned connection was evicted; stay on the oldest.
+			m.rowIdx = len(conns) - 1
+		} else {
+			for i, conn := range conns {
+				if conn.Seq == m.pinned {
+					m.rowIdx = i
+					break
+				}
+			}
+		}
+	}
+	capacity := m.tableCapacity()
+	m.tableOffset = min(m.tableOffset, max(0, len(conns)-capacity))
+	if m.rowIdx < m.tableOffset {
+		m.tableOffset = m.rowIdx
+	}
+	if m.rowIdx >= m.tableOffset+capacity {
+		m.tableOffset = m.rowIdx - capacity + 1
+	}
+}
+
+// pin records the selected connection after a move; the newest row unpins
+// so the selection follows arrivals again.
+func (m *Model) pin(conns []state.ConnectionEvent) {
+	m.pinned = 0
+	if m.rowIdx > 0 && m.rowIdx < len(conns) {
+		m.pinned = conns[m.rowIdx].Seq
+	}
+	m.follow(conns)
+}
+
+func (m *Model) move(delta, count int) {
+	m.rowIdx = max(0, min(count-1, m.rowIdx+delta))
+}
+
+func (m *Model) renderTable(conns []state.ConnectionEvent, nodes []state.Node) string {
+	layout := m.columns()
+	capacity := m.tableCapacity()
+	end := min(len(conns), m.tableOffset+capacity)
+	gap := strings.Repeat(" ", columnGap)
+
+	header := m.theme.Header.Bold(true).Padding(0)
+	labels := []string{"", "#", "TIME", "SOURCE", "ACTION", "NODE", "DESTINATION", "PROTO", "PROCESS"}
+	cells := make([]string, len(labels))
+	for i, width := range layout.widths() {
+		cells[i] = table.PadAndStyle(header, labels[i], width, true)
+	}
+	rows := []string{strings.Join(cells, gap)}
+	for idx := m.tableOffset; idx < end; idx++ {
+		rows = append(rows, m.renderRow(layout, conns[idx], nodes, idx, gap))
+	}
+	if end < len(conns) {
+		rows = append(rows, table.RenderCaretRow(table.ComputeMaxWidth(rows[:1]), m.theme.Subtle))
+	}
+	m.tableMaxWidth = table.ComputeMaxWidth(rows)
+	m.tableXOffset = min(m.tableXOffset, max(0, m.tableMaxWidth-m.contentWidth()))
+	return lipgloss.JoinVertical(lipgloss.Left, table.ClipRows(rows, m.tableXOffset, max(1, m.contentWidth()))...)
+}
+
+func (m *Model) renderRow(layout columns, conn state.ConnectionEvent, nodes []state.Node, idx int, gap string) string {
+	bg := m.theme.TableRowEven
+	if idx%2 == 1 {
+		bg = m.theme.TableRowOdd
+	}
+	cursor := " "
+	if idx == m.rowIdx {
+		bg, cursor = m.theme.TableRowSelect, ">"
+	}
+	body := m.theme.Body.UnsetBackground().Background(bg).Padding(0)
+	subtle := m.theme.Subtle.UnsetBackground().Background(bg).Padding(0)
+	values := []string{
+		cursor,
+		fmt.Sprintf("%d", conn.Seq),
+		conn.At.UTC().Format(timeLayout),
+		string(conn.Source),
+		formatAction(conn),
+		nodeLabel(nodes, conn.NodeID),
+		formatDestination(conn.Connection),
+		util.Fallback(netnames.Protocol(conn.Connection.Protocol), "-"),
+		util.Fallback(conn.Connection.ProcessPath, "-"),
+	}
+	cells := make([]string, len(values))
+	for i, width := range layout.widths() {
+		style := body
+		if i == 1 || i == 2 {
+			style = subtle
+		}
+		cells[i] = table.PadAndStyle(style, values[i], width, true)
+	}
+	return strings.Join(cells, lipgloss.NewStyle().Background(bg).Render(gap))
+}
+
+func (m *Model) renderDetail(snapshot state.Snapshot, conn state.ConnectionEvent) string {
+	inner := max(20, m.contentWidth())
+	line := func(label, value string) string {
+		return util.TruncateString(fmt.Sprintf("%s: %s", label, value), inner)
+	}
+	c := conn.Connection
+	lines := []string{
+		line("Time", conn.At.UTC().Format(time.RFC3339)),
+		"Node: " + m.theme.AccentMark(state.NodeKeyOf(snapshot.Nodes, conn.NodeID)) + " " + util.TruncateString(nodeLabel(snapshot.Nodes, conn.NodeID), max(1, inner-8)),
+		line("Source", sourceLabel(conn.Source)),
+		line("Action", formatAction(conn)),
+		line("Protocol", util.Fallback(netnames.Protocol(c.Protocol), "-")),
+		line("Src", util.Fallback(util.FormatEndpoint(c.SrcIP, c.SrcPort), "-")),
+		line("Dst", util.Fallback(util.FormatEndpoint(c.DstIP, c.DstPort), "-")+serviceNote(c)),
+		line("DstHost", util.Fallback(c.DstHost, "-")),
+		line("Process", util.Fallback(c.ProcessPath, "-")),
+		line("PID/UID", fmt.Sprintf("%d/%d", c.ProcessID, c.UserID)),
+		line("Args", util.Fallback(strings.Join(c.ProcessArgs, " "), "-")),
+		line("Rule", util.Fallback(conn.Rule.Name, "-")),
+	}
+	return m.theme.Body.Render(strings.Join(lines, "\n"))
+}
+
+// columns fits the table to the width, giving the process path the rest.
+func (m *Model) columns() columns {
+	layout := columns{cursor: 1, seq: 6, time: len(timeLayout), source: 6, action: 6, node: 12, dst: 26, proto: 5}
+	used := 0
+	for _, width := range layout.widths() {
+		used += width
+	}
+	gaps := columnGap * (len(layout.widths()) - 1)
+	layout.process = max(12, m.contentWidth()-used-gaps)
+	return layout
+}
+
+func (m *Model) tableCapacity() int {
+	return max(minTableRows, min(maxTableRows, m.height-tableChrome))
+}
+
+func (m *Model) adjustTableX(delta int) {
+	m.tableXOffset = max(0, min(m.tableXOffset+delta, m.tableMaxWidth-m.contentWidth()))
+}
+
+func (m *Model) contentWidth() int {
+	if m.width <= 0 {
+		return 80
+	}
+	if m.width <= 4 {
+		return m.width
+	}
+	return m.width - 4
+}
+
+func (m *Model) wrap(body string) string {
+	return m.theme.Body.Width(max(1, m.width)).Height(max(5, m.height)).Render(body)
+}
+
+func formatAction(conn state.ConnectionEvent) string {
+	if conn.Source == state.ConnectionFromPrompt {
+		return "ask"
+	}
+	return util.Fallback(conn.Rule.Action, "-")
+}
+
+// formatDestination prefers the host name over the address, with the port.
+func formatDestination(c state.Connection) string {
+	host := util.Fallback(c.DstHost, util.CompactIP(c.DstIP))
+	if host == "" {
+		return "-"
+	}
+	if c.DstPort == 0 {
+		return host
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return fmt.Sprintf("%s:%d", host, c.DstPort)
+}
+
+func serviceNote(c state.Connection) string {
+	if name, ok := netnames.Service(c.DstPort, c.Protocol); ok {
+		return " (" + name + ")"
+	}
+	return ""
+}
+
+func sourceLabel(source state.ConnectionSource) string {
+	switch source {
+	case state.ConnectionFromPrompt:
+		return "prompt (the daemon asked before connecting)"
+	case state.ConnectionFromEvent:
+		return "event (reported by the daemon)"
+	}
+	return util.Fallback(string(source), "-")
+}
+
+func nodeLabel(nodes []state.Node, id string) string {
+	for _, node := range nodes {
+		if node.ID == id {
+			return util.DisplayName(node)
+		}
+	}
+	return util.Fallback(id, "-")
+}
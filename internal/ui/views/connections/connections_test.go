@@ -0,0 +1,130 @@
+package connections
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/view/viewtest"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func feedStore(hosts ...string) *state.Store {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	at := time.Unix(1700000000, 0)
+	for i, host := range hosts {
+		source, rule := state.ConnectionFromEvent, state.Rule{Name: "allow-" + host, Action: "allow"}
+		if i%2 == 1 {
+			source, rule = state.ConnectionFromPrompt, state.Rule{}
+		}
+		store.AddConnectionEvent(state.ConnectionEvent{
+			At:     at.Add(time.Duration(i) * time.Second),
+			NodeID: "node-1",
+			Source: source,
+			Connection: state.Connection{
+				Protocol:    "tcp",
+				DstIP:       "203.0.113.7",
+				DstHost:     host,
+				DstPort:     443,
+				ProcessPath: "/usr/bin/curl",
+				ProcessID:   42,
+				UserID:      1000,
+			},
+			Rule: rule,
+		})
+	}
+	return store
+}
+
+func press(m *Model, key string) {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	switch key {
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "pgdown":
+		msg = tea.KeyMsg{Type: tea.KeyPgDown}
+	case "pgup":
+		msg = tea.KeyMsg{Type: tea.KeyPgUp}
+	}
+	m.Update(msg)
+}
+
+func TestConnectionsSnapshot(t *testing.T) {
+	m := New(feedStore("example.com", "example.org"), theme.New(theme.Options{}))
+	m.SetSize(100, 24)
+
+	viewtest.AssertSnapshot(t, m.View(), filepath.Join("testdata", "connections.snap"))
+}
+
+func TestConnectionsEmpty(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}))
+	m.SetSize(80, 20)
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "No connections yet") {
+		t.Fatalf("expected the empty message, got %q", out)
+	}
+}
+
+func TestTitleCountsConnectionsSeen(t *testing.T) {
+	store := feedStore("a.example", "b.example", "c.example")
+	store.SetConnectionCap(2)
+	m := New(store, theme.New(theme.Options{}))
+	if got := m.Title(); got != "Connections (3)" {
+		t.Fatalf("expected evicted connections still counted, got %q", got)
+	}
+}
+
+func TestSelectionStaysOnConnectionAsNewOnesArrive(t *testing.T) {
+	store := feedStore("a.example", "b.example", "c.example")
+	m := New(store, theme.New(theme.Options{})).(*Model)
+	m.SetSize(100, 24)
+	m.View()
+
+	press(m, "down")
+	if !strings.Contains(util.StripANSI(m.View()), "DstHost: b.example") {
+		t.Fatal("expected the second newest connection selected")
+	}
+	store.AddConnectionEvent(state.ConnectionEvent{Source: state.ConnectionFromPrompt, Connection: state.Connection{DstHost: "d.example"}})
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "DstHost: b.example") {
+		t.Fatalf("expected the selection to stay on b.example, got %q", out)
+	}
+
+	press(m, "up")
+	press(m, "up")
+	store.AddConnectionEvent(state.ConnectionEvent{Source: state.ConnectionFromPrompt, Connection: state.Connection{DstHost: "e.example"}})
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "DstHost: e.example") {
+		t.Fatalf("expected the newest row to follow arrivals, got %q", out)
+	}
+}
+
+func TestPageKeysMoveByTable(t *testing.T) {
+	hosts := make([]string, 30)
+	for i := range hosts {
+		hosts[i] = fmt.Sprintf("host-%02d.example", i)
+	}
+	m := New(feedStore(hosts...), theme.New(theme.Options{})).(*Model)
+	m.SetSize(100, 24)
+	m.View()
+
+	page := m.tableCapacity()
+	press(m, "pgdown")
+	if m.rowIdx != page {
+		t.Fatalf("expected pgdown to move %d rows, got %d", page, m.rowIdx)
+	}
+	press(m, "G")
+	if m.rowIdx != len(hosts)-1 || !strings.Contains(util.StripANSI(m.View()), "DstHost: host-00.example") {
+		t.Fatalf("expected G to select the oldest connection, got row %d", m.rowIdx)
+	}
+	press(m, "pgup")
+	if m.rowIdx != len(hosts)-1-page {
+		t.Fatalf("expected pgup to move back %d rows, got %d", page, m.rowIdx)
+	}
+}
@@ -0,0 +1,41 @@
+package connections
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// keyContext is what the connections actions work on.
+type keyContext struct {
+	m        *Model
+	snapshot state.Snapshot
+}
+
+// action is one entry of the connections key table.
+type action = keymap.Action[*keyContext]
+
+// moveBy returns an action body moving the selection by delta rows, or a
+// table page per unit of pages.
+func moveBy(delta, pages int) func(*keyContext) tea.Cmd {
+	return keymap.Do(func(c *keyContext) {
+		c.m.move(delta+pages*c.m.tableCapacity(), len(c.snapshot.Connections))
+	})
+}
+
+var keyTable = keymap.NewTable(
+	action{ID: "connections.up", Keys: []string{"up", "k"}, Help: "newer connection", Run: moveBy(-1, 0)},
+	action{ID: "connections.down", Keys: []string{"down", "j"}, Help: "older connection", Run: moveBy(1, 0)},
+	action{ID: "connections.page-up", Keys: []string{"pgup"}, Help: "page up", Run: moveBy(0, -1)},
+	action{ID: "connections.page-down", Keys: []string{"pgdown"}, Help: "page down", Run: moveBy(0, 1)},
+	action{ID: "connections.first", Keys: []string{"home", "g"}, Help: "newest connection", Run: keymap.Do(func(c *keyContext) { c.m.rowIdx = 0 })},
+	action{ID: "connections.last", Keys: []string{"end", "G"}, Help: "oldest connection", Run: keymap.Do(func(c *keyContext) {
+		c.m.rowIdx = max(0, len(c.snapshot.Connections)-1)
+	})},
+	action{ID: "connections.scroll-left", Keys: []string{"left", "h"}, Help: "scroll left", Run: keymap.Do(func(c *keyContext) { c.m.adjustTableX(-4) })},
+	action{ID: "connections.scroll-right", Keys: []string{"right", "l"}, Help: "scroll right", Run: keymap.Do(func(c *keyContext) { c.m.adjustTableX(4) })},
+)
+
+// KeyHelp lists the connections keys for the help overlay.
+func (m *Model) KeyHelp() []keymap.Help { return keyTable.Help() }
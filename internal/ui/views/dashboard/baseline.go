@@ -0,0 +1,69 @@
+package dashboard
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// sinceBaseline returns a formatter for the counters gained since the shown
+// node's baseline, and whether the node has one.
+func (m *Model) sinceBaseline(snapshot state.Snapshot) (func(func(state.Counters) uint64) string, bool) {
+	baseline, ok := state.BaselineOf(snapshot.Baselines, statsNode(snapshot))
+	if !ok || snapshot.Stats.UpdatedAt.IsZero() {
+		return func(func(state.Counters) uint64) string { return "" }, false
+	}
+	delta, ok := state.CountersOf(snapshot.Stats).Since(baseline.Counters)
+	return func(field func(state.Counters) uint64) string {
+		if !ok {
+			// The daemon restarted; the baseline moves with its next ping.
+			return "since baseline: ?"
+		}
+		return fmt.Sprintf("+%d since baseline", field(delta))
+	}, true
+}
+
+// baselineLine describes the shown node's baseline for the meta line.
+func (m *Model) baselineLine(snapshot state.Snapshot) string {
+	baseline, ok := state.BaselineOf(snapshot.Baselines, statsNode(snapshot))
+	if !ok {
+		return ""
+	}
+	when := fmt.Sprintf("%s (%s)", baseline.MarkedAt.Local().Format("2006-01-02 15:04"), util.RelativeTimeAt(baseline.MarkedAt, m.now()))
+	if baseline.Restarted {
+		return "Baseline reset by daemon restart " + when
+	}
+	return "Baseline " + when
+}
+
+func (m *Model) markBaseline() {
+	snapshot := m.store.Snapshot()
+	if snapshot.Stats.NodeID == "" || !widget.Guard(m.theme, m.baselines != nil, &m.status) {
+		return
+	}
+	node := statsNode(snapshot)
+	if _, err := m.baselines.MarkBaseline(node.ID); err != nil {
+		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to mark a baseline on %s: %v", util.DisplayName(node), err))
+		return
+	}
+	m.status = m.theme.Success.Render(fmt.Sprintf("Baseline marked on %s; B clears it", util.DisplayName(node)))
+}
+
+func (m *Model) clearBaseline() {
+	snapshot := m.store.Snapshot()
+	if snapshot.Stats.NodeID == "" || !widget.Guard(m.theme, m.baselines != nil, &m.status) {
+		return
+	}
+	node := statsNode(snapshot)
+	cleared, err := m.baselines.ClearBaseline(node.ID)
+	switch {
+	case err != nil:
+		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to clear the baseline of %s: %v", util.DisplayName(node), err))
+	case !cleared:
+		m.status = m.theme.Warning.Render(fmt.Sprintf("%s has no baseline", util.DisplayName(node)))
+	default:
+		m.status = m.theme.Success.Render(fmt.Sprintf("Baseline of %s cleared", util.DisplayName(node)))
+	}
+}
@@ -0,0 +1,55 @@
+package dashboard
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// storeBaselines marks baselines straight in the store, like the demo.
+type storeBaselines struct {
+	store *state.Store
+	now   time.Time
+}
+
+func (s *storeBaselines) MarkBaseline(nodeID string) (state.Baseline, error) {
+	return s.store.MarkBaseline(nodeID, s.now)
+}
+
+func (s *storeBaselines) ClearBaseline(nodeID string) (bool, error) {
+	return s.store.ClearBaseline(state.NodeKey(state.Node{ID: nodeID, Name: "alpha"})), nil
+}
+
+func TestDashboardShowsCountersSinceBaseline(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", LastSeen: now}})
+	store.SetStats(state.Stats{NodeID: "node-1", NodeName: "alpha", Connections: 100, Accepted: 90, Dropped: 10, UpdatedAt: now})
+
+	m := New(store, theme.New(theme.Options{}), &storeBaselines{store: store, now: now.Add(-5 * time.Minute)}, nil).(*Model)
+	m.now = func() time.Time { return now }
+	m.SetSize(120, 30)
+	if out := util.StripANSI(m.View()); strings.Contains(out, "since baseline") || strings.Contains(out, "Baseline") {
+		t.Fatalf("expected no baseline figures before marking, got:\n%s", out)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	store.SetStats(state.Stats{NodeID: "node-1", NodeName: "alpha", Connections: 140, Accepted: 120, Dropped: 20, UpdatedAt: now})
+	out := util.StripANSI(m.View())
+	for _, want := range []string{"140", "+40 since baseline", "+30 since baseline", "+10 since baseline", "Baseline marked on alpha", "Baseline " + now.Add(-5*time.Minute).Local().Format("2006-01-02 15:04") + " ("} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q, got:\n%s", want, out)
+		}
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'B'}})
+	if out := util.StripANSI(m.View()); strings.Contains(out, "since baseline") || !strings.Contains(out, "Baseline of alpha cleared") {
+		t.Fatalf("expected the baseline cleared, got:\n%s", out)
+	}
+}
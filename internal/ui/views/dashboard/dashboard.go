@@ -3,14 +3,20 @@ package dashboard
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/util/netnames"
 )
 
 // Model renders the high-level telemetry summary.
@@ -19,18 +25,57 @@ type Model struct {
 	theme  theme.Theme
 	width  int
 	height int
+	// stale is set while rendering data from a node that stopped pinging.
+	stale bool
+	now   func() time.Time
+	// baselines marks and clears the shown node's counter baseline; nil
+	// disables b and B.
+	baselines controller.BaselineManager
+	// rules adds the rules answered in a denial review; nil makes every
+	// answer but skip fail.
+	rules  controller.RuleManager
+	review *denialReview
+	status string
 }
 
 // New creates a dashboard view backed by the provided store.
-func New(store *state.Store, th theme.Theme) view.Model {
-	return &Model{store: store, theme: th}
+func New(store *state.Store, th theme.Theme, baselines controller.BaselineManager, rules controller.RuleManager) view.Model {
+	if !controller.Attached(baselines) {
+		baselines = nil
+	}
+	if !controller.Attached(rules) {
+		rules = nil
+	}
+	return &Model{store: store, theme: th, now: time.Now, baselines: baselines, rules: rules}
 }
 
 // Init satisfies tea.Model.
 func (m *Model) Init() tea.Cmd { return nil }
 
-// Update satisfies tea.Model. The dashboard currently reacts only to store updates.
-func (m *Model) Update(_ tea.Msg) (tea.Model, tea.Cmd) { return m, nil }
+// Update satisfies tea.Model. Besides store updates the dashboard reacts
+// to b and B, which mark and clear the shown node's baseline, and to R and
+// the keys of the denial review it opens.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	cmd, _ := keyTable.Dispatch(key.String(), m)
+	return m, cmd
+}
+
+var keyTable = keymap.NewTable(
+	keymap.Action[*Model]{ID: "dashboard.review-allow", Keys: []string{"a"}, Help: "allow the reviewed connection", Enabled: choosing, Run: keymap.Do(func(m *Model) { m.answerReview(choiceAllow) })},
+	keymap.Action[*Model]{ID: "dashboard.review-deny", Keys: []string{"d"}, Help: "keep denying the reviewed connection", Enabled: choosing, Run: keymap.Do(func(m *Model) { m.answerReview(choiceDeny) })},
+	keymap.Action[*Model]{ID: "dashboard.review-skip", Keys: []string{"s"}, Help: "skip the reviewed connection", Enabled: choosing, Run: keymap.Do(func(m *Model) { m.answerReview(choiceSkip) })},
+	keymap.Action[*Model]{ID: "dashboard.review-pause", Keys: []string{"esc"}, Help: "pause or close the denial review", Enabled: reviewing, Run: keymap.Do((*Model).pauseReview)},
+	keymap.Action[*Model]{ID: "dashboard.review", Keys: []string{"R"}, Help: "review frequent denials", Run: keymap.Do((*Model).startReview)},
+	keymap.Action[*Model]{ID: "dashboard.baseline", Keys: []string{"b"}, Help: "mark a counter baseline", Run: keymap.Do((*Model).markBaseline)},
+	keymap.Action[*Model]{ID: "dashboard.clear-baseline", Keys: []string{"B"}, Help: "clear the counter baseline", Run: keymap.Do((*Model).clearBaseline)},
+)
+
+// KeyHelp lists the dashboard keys for the help overlay.
+func (m *Model) KeyHelp() []keymap.Help { return keyTable.Help() }
 
 // View renders the dashboard contents.
 func (m *Model) View() string {
@@ -40,12 +85,23 @@ func (m *Model) View() string {
 
 	snapshot := m.store.Snapshot()
 	stats := snapshot.Stats
+	freshness := statsStaleness(snapshot, m.now())
+	m.stale = freshness.Stale
 
+	since, marked := m.sinceBaseline(snapshot)
 	cards := []string{
-		m.renderStat("Rules", stats.Rules),
-		m.renderStat("Connections", stats.Connections),
-		m.renderStat("Accepted", stats.Accepted),
-		m.renderStat("Dropped", stats.Dropped),
+		m.renderStat("Rules", stats.Rules, marked, ""),
+		m.renderStat("Connections", stats.Connections, marked, since(func(c state.Counters) uint64 { return c.Connections })),
+		m.renderStat("Accepted", stats.Accepted, marked, since(func(c state.Counters) uint64 { return c.Accepted })),
+		m.renderStat("Dropped", stats.Dropped, marked, since(func(c state.Counters) uint64 { return c.Dropped })),
+	}
+
+	if reviewing(m) {
+		body := []string{m.card().Width(max(20, m.width-8)).Render(m.renderReview(snapshot))}
+		if m.status != "" {
+			body = append(body, m.status)
+		}
+		return m.theme.Body.Width(max(1, m.width)).Height(max(3, m.height)).Render(lipgloss.JoinVertical(lipgloss.Left, body...))
 	}
 
 	row := lipgloss.JoinHorizontal(lipgloss.Top, cards...)
@@ -54,12 +110,29 @@ func (m *Model) View() string {
 	colWidth := max(20, m.width/4)
 	secondary := lipgloss.JoinHorizontal(lipgloss.Top,
 		m.renderTopList("Top destinations", stats.TopDestHosts, colWidth),
-		m.renderTopList("Top ports", stats.TopDestPorts, colWidth),
+		m.renderTopList("Top ports", portBuckets(stats.TopDestPorts), colWidth),
 		m.renderTopList("Top executables", stats.TopExecutables, colWidth),
 		m.renderTopList("Top users", stats.TopUsers, colWidth),
 	)
+	sections := []string{row, insights, secondary}
 	meta := m.theme.Subtle.Render(m.metaLine(stats))
-	body := lipgloss.JoinVertical(lipgloss.Left, row, insights, secondary, meta)
+	if freshness.Stale {
+		meta += " " + m.theme.Warning.Render("stale")
+	}
+	if line := m.baselineLine(snapshot); line != "" {
+		meta += m.theme.Subtle.Render(" · " + line)
+	}
+	if line := m.reviewLine(); line != "" {
+		meta += m.theme.Subtle.Render(" · " + line)
+	}
+	sections = append(sections, meta)
+	switch {
+	case m.status != "":
+		sections = append(sections, m.status)
+	case m.baselines == nil || m.rules == nil:
+		sections = append(sections, widget.RenderReadOnly(m.theme))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
 	return m.theme.Body.Width(max(1, m.width)).Height(max(3, m.height)).Render(body)
 }
@@ -78,11 +151,45 @@ func (m *Model) SetTheme(th theme.Theme) {
 	m.theme = th
 }
 
-func (m *Model) renderStat(label string, value uint64) string {
+// card is the card style, dimmed while the data is stale.
+func (m *Model) card() lipgloss.Style {
+	if !m.stale {
+		return m.theme.Card
+	}
+	subtle := m.theme.Subtle.GetForeground()
+	return m.theme.Card.Foreground(subtle).BorderForeground(subtle)
+}
+
+// statsStaleness reports how current the dashboard stats are, judged by
+// the node that sent them.
+func statsStaleness(snapshot state.Snapshot, now time.Time) state.Staleness {
+	if snapshot.Stats.UpdatedAt.IsZero() {
+		return state.Staleness{}
+	}
+	return state.NodeStaleness(statsNode(snapshot), snapshot.Stats, now)
+}
+
+// statsNode returns the node the dashboard stats came from.
+func statsNode(snapshot state.Snapshot) state.Node {
+	node := state.Node{ID: snapshot.Stats.NodeID, Name: snapshot.Stats.NodeName}
+	for _, candidate := range snapshot.Nodes {
+		if candidate.ID == node.ID {
+			return candidate
+		}
+	}
+	return node
+}
+
+// renderStat draws a counter card. With a baseline marked every card gets
+// a third line, so the row keeps one height even where since is empty.
+func (m *Model) renderStat(label string, value uint64, marked bool, since string) string {
 	const cardOverhead = 8 // border (2) + padding (4) + margin (2)
 	cardWidth := max(16, m.width/4-cardOverhead)
 	content := fmt.Sprintf("%d\n%s", value, label)
-	return m.theme.Card.Width(cardWidth).Render(content)
+	if marked {
+		content += "\n" + m.theme.Subtle.Render(since)
+	}
+	return m.card().Width(cardWidth).Render(content)
 }
 
 func (m *Model) renderTraffic(stats state.Stats, cardWidth int) string {
@@ -107,14 +214,14 @@ func (m *Model) renderTraffic(stats state.Stats, cardWidth int) string {
 	if total == 0 {
 		body = append(body, m.theme.Subtle.Render("No traffic yet"))
 	}
-	return m.theme.Card.Width(cardWidth).Render(strings.Join(body, "\n"))
+	return m.card().Width(cardWidth).Render(strings.Join(body, "\n"))
 }
 
 func (m *Model) renderTopList(title string, buckets []state.StatBucket, width int) string {
 	cardWidth := max(20, width-4)
 	head := m.theme.Title.Render(title)
 	if len(buckets) == 0 {
-		return m.theme.Card.Width(cardWidth).Render(head + "\n" + m.theme.Subtle.Render("Waiting for data"))
+		return m.card().Width(cardWidth).Render(head + "\n" + m.theme.Subtle.Render("Waiting for data"))
 	}
 	lines := make([]string, 0, len(buckets)+1)
 	lines = append(lines, head)
@@ -128,7 +235,17 @@ func (m *Model) renderTopList(title string, buckets []state.StatBucket, width in
 		lines = append(lines, trimToWidth(bucket.Label, cardWidth-2))
 		lines = append(lines, fmt.Sprintf("%-*s %6d", barWidth+1, bar, bucket.Value))
 	}
-	return m.theme.Card.Width(cardWidth).Render(strings.Join(lines, "\n"))
+	return m.card().Width(cardWidth).Render(strings.Join(lines, "\n"))
+}
+
+// portBuckets names the service of each port, e.g. "443 (https)".
+func portBuckets(buckets []state.StatBucket) []state.StatBucket {
+	out := make([]state.StatBucket, len(buckets))
+	for i, bucket := range buckets {
+		bucket.Label = netnames.PortLabel(bucket.Label)
+		out[i] = bucket
+	}
+	return out
 }
 
 func (m *Model) renderBreakdownLine(label string, value, total uint64, style lipgloss.Style, width int) string {
@@ -145,7 +262,7 @@ func (m *Model) renderRelativeBar(value, total uint64, width int) string {
 		return ""
 	}
 	filled := filledWidth(value, total, width)
-	return strings.Repeat("█", filled) + strings.Repeat(" ", width-filled)
+	return strings.Repeat(glyphs.Current().Bar, filled) + strings.Repeat(" ", width-filled)
 }
 
 func filledWidth(value, total uint64, width int) int {
@@ -177,5 +294,5 @@ func (m *Model) metaLine(stats state.Stats) string {
 		return "Waiting for daemon telemetry"
 	}
 	node := util.Fallback(stats.NodeName, util.Fallback(stats.NodeID, "unknown node"))
-	return fmt.Sprintf("Node %s · Daemon %s · Updated %s", node, util.Fallback(stats.DaemonVersion, "unknown"), util.RelativeTime(stats.UpdatedAt))
+	return fmt.Sprintf("Node %s · Daemon %s · Updated %s", node, util.Fallback(stats.DaemonVersion, "unknown"), util.RelativeTimeAt(stats.UpdatedAt, m.now()))
 }
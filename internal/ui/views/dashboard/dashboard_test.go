@@ -2,10 +2,13 @@ package dashboard
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view/viewtest"
 )
 
@@ -14,7 +17,7 @@ func TestDashboardViewWaitingSnapshot(t *testing.T) {
 	store.SetStats(state.Stats{})
 
 	th := theme.New(theme.Options{})
-	m := New(store, th)
+	m := New(store, th, nil, nil)
 	m.SetSize(120, 18)
 
 	viewtest.AssertSnapshot(t, m.View(), filepath.Join("testdata", "dashboard_waiting.snap"))
@@ -45,3 +48,46 @@ func TestTrimToWidth(t *testing.T) {
 		}
 	}
 }
+
+func TestRelativeBarGlyphs(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), nil, nil).(*Model)
+	if bar := m.renderRelativeBar(1, 2, 4); bar != "██  " {
+		t.Fatalf("expected a half-filled Unicode bar, got %q", bar)
+	}
+	glyphs.SetASCII(true)
+	t.Cleanup(func() { glyphs.SetASCII(false) })
+	if bar := m.renderRelativeBar(1, 2, 4); bar != "##  " {
+		t.Fatalf("expected a half-filled ASCII bar, got %q", bar)
+	}
+}
+
+func TestDashboardTopPortsNameServices(t *testing.T) {
+	store := state.NewStore()
+	store.SetStats(state.Stats{TopDestPorts: []state.StatBucket{{Label: "443", Value: 10}, {Label: "40123", Value: 2}}})
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(160, 40)
+
+	out := m.View()
+	if !strings.Contains(out, "443 (https)") || !strings.Contains(out, "40123") || strings.Contains(out, "40123 (") {
+		t.Fatalf("expected named well-known ports only, got:\n%s", out)
+	}
+}
+
+func TestDashboardFlagsStaleStats(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", LastSeen: now.Add(-30 * time.Second)}})
+	store.SetStats(state.Stats{NodeID: "node-1", NodeName: "alpha", Rules: 3, UpdatedAt: now.Add(-30 * time.Second)})
+
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.now = func() time.Time { return now }
+	m.SetSize(120, 30)
+	if out := m.View(); strings.Contains(out, "stale") || !strings.Contains(out, "Updated 30s ago") {
+		t.Fatalf("expected fresh stats, got:\n%s", out)
+	}
+
+	m.now = func() time.Time { return now.Add(3 * time.Minute) }
+	if out := m.View(); !strings.Contains(out, "stale") {
+		t.Fatalf("expected stale tag, got:\n%s", out)
+	}
+}
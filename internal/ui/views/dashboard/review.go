@@ -0,0 +1,267 @@
+package dashboard
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// reviewLimit is how many of the most denied connections a review walks
+// through.
+const reviewLimit = 10
+
+// denialGroup is the denied events of one process to one destination on
+// one node; rules are per node, so the node is part of the key.
+type denialGroup struct {
+	NodeID      string
+	Process     string
+	Destination string
+	Count       int
+	// Last is the newest of the events; rules are built from its
+	// connection, as a prompt for it would have been.
+	Last state.Event
+}
+
+// summarizeDenials groups the denied and rejected events by node, process
+// and destination host (or IP when the host is unknown), most denied
+// first and, among equals, most recently denied first.
+func summarizeDenials(events []state.Event) []denialGroup {
+	type key struct{ node, process, destination string }
+	index := make(map[key]int)
+	var groups []denialGroup
+	for _, ev := range events {
+		if ev.Rule.Action != "deny" && ev.Rule.Action != "reject" {
+			continue
+		}
+		conn := ev.Connection
+		k := key{ev.NodeID, conn.ProcessPath, util.Fallback(conn.DstHost, conn.DstIP)}
+		idx, ok := index[k]
+		if !ok {
+			idx = len(groups)
+			index[k] = idx
+			groups = append(groups, denialGroup{NodeID: k.node, Process: k.process, Destination: k.destination})
+		}
+		group := &groups[idx]
+		group.Count++
+		if ev.UnixNano >= group.Last.UnixNano {
+			group.Last = ev
+		}
+	}
+	slices.SortStableFunc(groups, func(a, b denialGroup) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Last.UnixNano, a.Last.UnixNano)
+	})
+	return groups
+}
+
+// reviewChoice is the answer given for one group.
+type reviewChoice int
+
+const (
+	choiceAllow reviewChoice = iota
+	choiceDeny
+	choiceSkip
+)
+
+// denialReview steps through the groups one at a time. It only moves on
+// once a choice is recorded, so leaving the review and coming back picks
+// up at the same group.
+type denialReview struct {
+	groups []denialGroup
+	step   int
+	// tally counts the recorded choices.
+	tally [3]int
+	// paused hides the review until it is resumed.
+	paused bool
+}
+
+func newDenialReview(groups []denialGroup, limit int) *denialReview {
+	return &denialReview{groups: slices.Clone(groups[:min(limit, len(groups))])}
+}
+
+// current returns the group awaiting a choice; false once every group has
+// one.
+func (r *denialReview) current() (denialGroup, bool) {
+	if r.step >= len(r.groups) {
+		return denialGroup{}, false
+	}
+	return r.groups[r.step], true
+}
+
+// record notes choice for the current group and moves to the next.
+func (r *denialReview) record(choice reviewChoice) {
+	if r.done() {
+		return
+	}
+	r.tally[choice]++
+	r.step++
+}
+
+func (r *denialReview) done() bool { return r.step >= len(r.groups) }
+
+// reviewRule builds the permanent rule for answering group with action,
+// on the target and with the name and description a prompt for the
+// group's newest connection would have used.
+func reviewRule(group denialGroup, action controller.PromptAction, settings state.Settings, existing []state.Rule, now time.Time) (state.Rule, error) {
+	conn := group.Last.Connection
+	target := controller.DefaultTarget(conn, controller.PromptTarget(settings.DefaultPromptTarget), settings.UIDZeroUnknown)
+	operator, err := target.Operator(conn, settings.UIDZeroUnknown)
+	if err != nil {
+		return state.Rule{}, err
+	}
+	limits := ruleset.LimitsFor(settings)
+	names := make([]string, 0, len(existing))
+	for _, rule := range existing {
+		names = append(names, rule.Name)
+	}
+	name := ruleset.GenerateName(settings.RuleNameTemplate, ruleset.NameParts{
+		Action:   string(action),
+		Duration: string(controller.PromptDurationAlways),
+		Target:   string(target),
+		Type:     operator.Type,
+		Data:     operator.Data,
+	}, names, limits.Text)
+	prompt := state.Prompt{NodeID: group.NodeID, Connection: conn}
+	return state.Rule{
+		NodeID:      group.NodeID,
+		Name:        name,
+		Description: ruleset.RenderDescription(settings.RuleDescriptionTemplate, ruleset.DescriptionFieldsFor(prompt, settings.UIDZeroUnknown, now), limits.Text),
+		Enabled:     true,
+		Action:      string(action),
+		Duration:    string(controller.PromptDurationAlways),
+		Operator:    operator,
+		CreatedAt:   now,
+	}, nil
+}
+
+// startReview resumes a paused review, or starts one over the denied
+// events in the history.
+func (m *Model) startReview() {
+	if m.review != nil && !m.review.done() {
+		m.review.paused = false
+		m.status = ""
+		return
+	}
+	groups := summarizeDenials(m.store.Snapshot().Events)
+	if len(groups) == 0 {
+		m.review = nil
+		m.status = m.theme.Subtle.Render("No denied connections in the event history to review")
+		return
+	}
+	m.review = newDenialReview(groups, reviewLimit)
+	m.status = ""
+}
+
+// answerReview records choice for the current group, first adding the
+// rule it asks for. A failed rule keeps the group current so it can be
+// tried again or skipped.
+func (m *Model) answerReview(choice reviewChoice) {
+	group, ok := m.review.current()
+	if !ok {
+		return
+	}
+	if choice != choiceSkip && !widget.Guard(m.theme, m.rules != nil, &m.status) {
+		return
+	}
+	if choice != choiceSkip {
+		action := controller.PromptActionAllow
+		if choice == choiceDeny {
+			action = controller.PromptActionDeny
+		}
+		if err := m.addReviewRule(group, action); err != nil {
+			m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to %s %s: %v", action, group.label(), err))
+			return
+		}
+		m.status = m.theme.Success.Render(fmt.Sprintf("Added a rule to %s %s", action, group.label()))
+	} else {
+		m.status = ""
+	}
+	m.review.record(choice)
+}
+
+func (m *Model) addReviewRule(group denialGroup, action controller.PromptAction) error {
+	if m.rules == nil {
+		return controller.ErrNoController
+	}
+	snapshot := m.store.Snapshot()
+	rule, err := reviewRule(group, action, snapshot.Settings, snapshot.Rules[group.NodeID], m.now())
+	if err != nil {
+		return err
+	}
+	return m.rules.AddRule(group.NodeID, rule)
+}
+
+// pauseReview hides an unfinished review, or closes a finished one.
+func (m *Model) pauseReview() {
+	if m.review.done() {
+		m.review = nil
+		m.status = ""
+		return
+	}
+	m.review.paused = true
+}
+
+func (g denialGroup) label() string {
+	return util.Fallback(g.Process, "unknown process") + " → " + util.Fallback(g.Destination, "unknown destination")
+}
+
+// renderReview draws the group awaiting a choice, or the tally once the
+// review is through.
+func (m *Model) renderReview(snapshot state.Snapshot) string {
+	r := m.review
+	title := m.theme.Title.Render(fmt.Sprintf("Review denials · %d of %d", min(r.step+1, len(r.groups)), len(r.groups)))
+	group, ok := r.current()
+	if !ok {
+		summary := fmt.Sprintf("Review done: %d allowed, %d kept denied, %d skipped", r.tally[choiceAllow], r.tally[choiceDeny], r.tally[choiceSkip])
+		return strings.Join([]string{title, summary, m.theme.Subtle.Render("esc close · R review again")}, "\n")
+	}
+	seen := fmt.Sprintf("%d denied on %s", group.Count, m.nodeLabel(snapshot, group.NodeID))
+	if group.Last.UnixNano != 0 {
+		seen += ", last " + util.RelativeTimeAt(time.Unix(0, group.Last.UnixNano), m.now())
+	}
+	lines := []string{title, group.label(), m.theme.Subtle.Render(seen)}
+	if rule, err := reviewRule(group, controller.PromptActionAllow, snapshot.Settings, snapshot.Rules[group.NodeID], m.now()); err == nil {
+		lines = append(lines, "Rule: "+rule.Operator.Operand+" is "+rule.Operator.Data+" · always")
+	} else {
+		lines = append(lines, m.theme.Warning.Render("No rule can be made: "+err.Error()))
+	}
+	var disabled []string
+	if m.rules == nil {
+		disabled = []string{"a allow", "d keep denying"}
+	}
+	lines = append(lines, widget.RenderHelp(m.theme, "a allow · d keep denying · s skip · esc pause (R resumes)", disabled))
+	return strings.Join(lines, "\n")
+}
+
+// reviewLine is the meta line note for a paused review.
+func (m *Model) reviewLine() string {
+	if m.review == nil || !m.review.paused {
+		return ""
+	}
+	return fmt.Sprintf("Denial review paused at %d of %d; R resumes", m.review.step+1, len(m.review.groups))
+}
+
+func (m *Model) nodeLabel(snapshot state.Snapshot, nodeID string) string {
+	for _, node := range snapshot.Nodes {
+		if node.ID == nodeID {
+			return util.DisplayName(node)
+		}
+	}
+	return util.Fallback(nodeID, "unknown node")
+}
+
+// reviewing enables the review's choices while it is shown.
+func reviewing(m *Model) bool { return m.review != nil && !m.review.paused }
+
+// choosing enables the choices while a group awaits one.
+func choosing(m *Model) bool { return reviewing(m) && !m.review.done() }
@@ -0,0 +1,226 @@
+package dashboard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func denied(node, process, host, ip string, at int64) state.Event {
+	return state.Event{
+		NodeID:     node,
+		UnixNano:   at,
+		Connection: state.Connection{ProcessPath: process, DstHost: host, DstIP: ip, DstPort: 443, ProcessID: 4242, UserID: 1000},
+		Rule:       state.Rule{Name: "deny-all", Action: "deny"},
+	}
+}
+
+func reviewEvents() []state.Event {
+	events := []state.Event{
+		denied("node-1", "/usr/bin/curl", "telemetry.example", "10.0.0.1", 1),
+		denied("node-1", "/usr/bin/curl", "telemetry.example", "10.0.0.2", 5),
+		denied("node-1", "/usr/bin/curl", "telemetry.example", "10.0.0.1", 3),
+		denied("node-1", "/usr/bin/dig", "", "9.9.9.9", 2),
+		denied("node-1", "/usr/bin/dig", "", "9.9.9.9", 6),
+		denied("node-2", "/usr/bin/curl", "telemetry.example", "10.0.0.1", 4),
+		{NodeID: "node-1", UnixNano: 7, Connection: state.Connection{ProcessPath: "/usr/bin/curl", DstHost: "telemetry.example"}, Rule: state.Rule{Action: "allow"}},
+	}
+	rejected := denied("node-1", "/usr/bin/ssh", "git.example", "10.0.0.9", 8)
+	rejected.Rule.Action = "reject"
+	return append(events, rejected)
+}
+
+func TestSummarizeDenials(t *testing.T) {
+	groups := summarizeDenials(reviewEvents())
+
+	want := []struct {
+		node, process, destination string
+		count                      int
+		last                       int64
+	}{
+		{"node-1", "/usr/bin/curl", "telemetry.example", 3, 5},
+		{"node-1", "/usr/bin/dig", "9.9.9.9", 2, 6},
+		{"node-1", "/usr/bin/ssh", "git.example", 1, 8},
+		{"node-2", "/usr/bin/curl", "telemetry.example", 1, 4},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("expected %d groups, got %+v", len(want), groups)
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.NodeID != w.node || g.Process != w.process || g.Destination != w.destination || g.Count != w.count || g.Last.UnixNano != w.last {
+			t.Fatalf("group %d: expected %+v, got %s %s %s %d %d", i, w, g.NodeID, g.Process, g.Destination, g.Count, g.Last.UnixNano)
+		}
+	}
+}
+
+func TestDenialReviewSteps(t *testing.T) {
+	r := newDenialReview(summarizeDenials(reviewEvents()), 3)
+	if len(r.groups) != 3 {
+		t.Fatalf("expected the review limited to 3 groups, got %d", len(r.groups))
+	}
+	for i, choice := range []reviewChoice{choiceAllow, choiceSkip, choiceDeny} {
+		group, ok := r.current()
+		if !ok || group.Process != r.groups[i].Process || r.done() {
+			t.Fatalf("step %d: expected group %d current", i, i)
+		}
+		r.record(choice)
+	}
+	if _, ok := r.current(); ok || !r.done() {
+		t.Fatalf("expected the review done after three choices")
+	}
+	r.record(choiceAllow)
+	if r.tally != [3]int{1, 1, 1} || r.step != 3 {
+		t.Fatalf("expected one of each choice and no more, got %v at step %d", r.tally, r.step)
+	}
+}
+
+func TestReviewRuleUsesPromptTargets(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	group := summarizeDenials(reviewEvents())[0]
+
+	rule, err := reviewRule(group, controller.PromptActionAllow, state.Settings{DefaultPromptTarget: "process.path"}, nil, now)
+	if err != nil {
+		t.Fatalf("reviewRule: %v", err)
+	}
+	if rule.NodeID != "node-1" || rule.Action != "allow" || rule.Duration != "always" || !rule.Enabled || rule.Operator.Type != "simple" || rule.Operator.Operand != "process.path" || rule.Operator.Data != "/usr/bin/curl" {
+		t.Fatalf("unexpected rule %+v", rule)
+	}
+	if rule.Name == "" || rule.Description == "" {
+		t.Fatalf("expected a generated name and description, got %+v", rule)
+	}
+
+	// The configured target wins when the connection has it; the newest
+	// event's destination IP is the one used.
+	rule, err = reviewRule(group, controller.PromptActionDeny, state.Settings{DefaultPromptTarget: "dest.ip"}, []state.Rule{{Name: rule.Name}}, now)
+	if err != nil || rule.Action != "deny" || rule.Operator.Operand != "dest.ip" || rule.Operator.Data != "10.0.0.2" {
+		t.Fatalf("expected a deny rule on the newest destination IP, got %+v, %v", rule, err)
+	}
+
+	// Without a value for the configured target the best available one is
+	// used, as for prompts.
+	group.Last.Connection.DstHost = ""
+	if rule, err = reviewRule(group, controller.PromptActionDeny, state.Settings{DefaultPromptTarget: "dest.host"}, nil, now); err != nil || rule.Operator.Operand != "process.path" {
+		t.Fatalf("expected the process path without a host, got %+v, %v", rule, err)
+	}
+}
+
+// addedRules records the rules added, failing for nodes in refuse.
+type addedRules struct {
+	noopRules
+	refuse map[string]bool
+	added  []state.Rule
+}
+
+func (a *addedRules) AddRule(nodeID string, rule state.Rule) error {
+	if a.refuse[nodeID] {
+		return errors.New("node disconnected")
+	}
+	a.added = append(a.added, rule)
+	return nil
+}
+
+type noopRules struct{}
+
+func (noopRules) EnableRule(string, string, string) error     { return nil }
+func (noopRules) DisableRule(string, string, string) error    { return nil }
+func (noopRules) DeleteRule(string, string, string) error     { return nil }
+func (noopRules) ChangeRule(string, state.Rule, string) error { return nil }
+func (noopRules) AddRule(string, state.Rule) error            { return nil }
+
+func TestDashboardDenialReviewFlow(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}, {ID: "node-2", Name: "beta"}})
+	store.MergeEvents(reviewEvents())
+	ctrl := &addedRules{refuse: map[string]bool{"node-2": true}}
+	m := New(store, theme.New(theme.Options{}), nil, ctrl).(*Model)
+	m.now = func() time.Time { return time.Unix(0, 10) }
+	m.SetSize(120, 30)
+	press := func(r rune) { m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}) }
+	view := func() string { return util.StripANSI(m.View()) }
+
+	press('a')
+	if len(ctrl.added) != 0 {
+		t.Fatalf("expected a to do nothing outside a review")
+	}
+	press('R')
+	if out := view(); !strings.Contains(out, "Review denials · 1 of 4") || !strings.Contains(out, "/usr/bin/curl → telemetry.example") || !strings.Contains(out, "3 denied on alpha") {
+		t.Fatalf("expected the most denied connection first, got:\n%s", out)
+	}
+	press('a')
+	if len(ctrl.added) != 1 || ctrl.added[0].Action != "allow" {
+		t.Fatalf("expected an allow rule, got %+v", ctrl.added)
+	}
+
+	// Pausing and resuming stays on the same connection.
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if out := view(); strings.Contains(out, "Review denials") || !strings.Contains(out, "Denial review paused at 2 of 4; R resumes") {
+		t.Fatalf("expected the dashboard with a paused review, got:\n%s", out)
+	}
+	press('d')
+	press('R')
+	if out := view(); !strings.Contains(out, "2 of 4") || !strings.Contains(out, "/usr/bin/dig → 9.9.9.9") {
+		t.Fatalf("expected to resume at the second connection, got:\n%s", out)
+	}
+	press('d')
+	press('s')
+
+	// A rule that cannot be added keeps the connection current.
+	press('d')
+	if out := view(); !strings.Contains(out, "4 of 4") || !strings.Contains(out, "Failed to deny /usr/bin/curl → telemetry.example: node disconnected") {
+		t.Fatalf("expected the failure on the last connection, got:\n%s", out)
+	}
+	press('s')
+	if out := view(); !strings.Contains(out, "Review done: 1 allowed, 1 kept denied, 2 skipped") {
+		t.Fatalf("expected the tally, got:\n%s", out)
+	}
+	if len(ctrl.added) != 2 || ctrl.added[1].Action != "deny" || ctrl.added[1].Operator.Data != "/usr/bin/dig" {
+		t.Fatalf("expected an allow and a deny rule, got %+v", ctrl.added)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.review != nil {
+		t.Fatalf("expected esc to close the finished review")
+	}
+}
+
+func TestDashboardWithoutControllersIsReadOnly(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetStats(state.Stats{NodeID: "node-1", NodeName: "alpha", Connections: 10})
+	store.MergeEvents(reviewEvents())
+	var typedNil *addedRules
+	m := New(store, theme.New(theme.Options{}), nil, typedNil).(*Model)
+	m.SetSize(120, 30)
+	press := func(r rune) { m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}) }
+	want := controller.ErrNoController.Error()
+	if out := util.StripANSI(m.View()); !strings.Contains(out, want) {
+		t.Fatalf("expected the read-only line before any key, got:\n%s", out)
+	}
+	for _, r := range "bB" {
+		m.status = ""
+		press(r)
+		if got := util.StripANSI(m.status); got != want {
+			t.Fatalf("%c: expected %q, got %q", r, want, got)
+		}
+	}
+	press('R')
+	for _, r := range "ad" {
+		m.status = ""
+		press(r)
+		if got := util.StripANSI(m.status); got != want || m.review.step != 0 {
+			t.Fatalf("%c: expected %q with the review not moving, got %q", r, want, got)
+		}
+	}
+	press('s')
+	if m.review.step != 1 {
+		t.Fatalf("expected skipping to work without a controller")
+	}
+}
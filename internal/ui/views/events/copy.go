@@ -0,0 +1,86 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/util/netnames"
+)
+
+// copyEvent copies the selected event's details, or saves them to a file
+// when the terminal is not expected to take OSC 52.
+func (m *Model) copyEvent(snapshot state.Snapshot) tea.Cmd {
+	if len(snapshot.Events) == 0 {
+		return nil
+	}
+	ev := eventAt(snapshot.Events, m.rowIdx)
+	var containerLabel string
+	if info, ok := m.eventContainer(snapshot.Nodes, ev); ok {
+		containerLabel = info.Label()
+	}
+	text := formatEventText(snapshot, ev, containerLabel, m.footprintLine(snapshot, ev))
+	if !clipboard.Supported() {
+		path, err := clipboard.Save(text)
+		if err != nil {
+			m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to save event: %v", err))
+			return nil
+		}
+		m.statusLine = m.theme.Success.Render("Saved event to " + path + " (no OSC 52 in this terminal)")
+		return nil
+	}
+	m.statusLine = m.theme.Success.Render("Copied event to clipboard")
+	return clipboard.Copy(text)
+}
+
+// formatEventText is the detail pane of ev as plain text, one field per
+// line and nothing truncated. containerLabel and footprint are the
+// pane's lookups for the event and are left out when empty.
+func formatEventText(snapshot state.Snapshot, ev state.Event, containerLabel, footprint string) string {
+	var b strings.Builder
+	field := func(label, value string) { fmt.Fprintf(&b, "%s: %s\n", label, value) }
+	conn := ev.Connection
+
+	field("Time", formatEventTime(ev)+localTimeNote(snapshot, ev))
+	node := findNodeLabel(snapshot.Nodes, ev.NodeID)
+	if node != ev.NodeID && ev.NodeID != "" {
+		node += " (" + ev.NodeID + ")"
+	}
+	field("Node", node)
+	field("Action", formatEventAction(ev))
+	field("Protocol", util.Fallback(netnames.Protocol(conn.Protocol), "-"))
+	field("Src", formatSource(conn))
+	if conn.Inbound() {
+		field("Direction", "inbound ← Src is the remote peer")
+	}
+	field("Dst", formatDestination(conn))
+	field("DstHost", util.Fallback(conn.DstHost, "-"))
+	field("Process", util.Fallback(conn.ProcessPath, "-"))
+	if containerLabel != "" {
+		field("Container", containerLabel)
+	}
+	field("PID/UID", formatPIDUID(conn.ProcessID, conn.UserID))
+	field("Args", formatCmdline(ev))
+	field("CWD", util.Fallback(conn.ProcessCWD, "-"))
+	field("Rule", util.Fallback(ev.Rule.Name, "-"))
+	if ev.Rule.Name != "" {
+		b.WriteString("  ↳ " + matchedRuleSummary(snapshot.Rules, ev) + "\n")
+	}
+	if ev.Prompt.PromptID != "" {
+		field("Prompt", formatPromptLink(ev.Prompt))
+	}
+	if checksums := util.ChecksumLines(util.SortedChecksums(conn.ProcessChecksums)); len(checksums) > 0 {
+		b.WriteString("Checksums:\n")
+		for _, line := range checksums {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if footprint != "" {
+		b.WriteString(footprint + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func copyEventFixture() (state.Snapshot, state.Event) {
+	ev := state.Event{
+		NodeID:   "unix:/run/opensnitch.sock",
+		UnixNano: 1700000000 * 1e9,
+		Connection: state.Connection{
+			Protocol:    "tcp",
+			SrcIP:       "192.168.1.20",
+			SrcPort:     51000,
+			DstIP:       "93.184.216.34",
+			DstPort:     443,
+			DstHost:     "example.org",
+			ProcessID:   4242,
+			UserID:      1000,
+			ProcessPath: "/usr/bin/curl",
+			ProcessArgs: []string{"curl", "-s", "https://example.org/a-very-long-path-that-the-detail-pane-would-truncate"},
+			ProcessCWD:  "/home/user",
+			ProcessChecksums: map[string]string{
+				"md5":    "d41d8cd98f00b204e9800998ecf8427e",
+				"sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+		},
+		Rule: state.Rule{Name: "allow-curl", Action: "allow"},
+	}
+	snapshot := state.Snapshot{
+		Nodes: []state.Node{{ID: ev.NodeID, Name: "laptop"}},
+		Rules: map[string][]state.Rule{ev.NodeID: {{Name: "allow-curl", Action: "allow", Duration: "always", Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}}}},
+	}
+	return snapshot, ev
+}
+
+func TestFormatEventText(t *testing.T) {
+	snapshot, ev := copyEventFixture()
+	got := formatEventText(snapshot, ev, "web (docker)", "This process: 1 event")
+
+	want := []string{
+		"Time: 2023-11-14T22:13:20Z",
+		"Node: laptop (unix:/run/opensnitch.sock)",
+		"Action: allow",
+		"Protocol: tcp",
+		"Src: 192.168.1.20:51000",
+		"Dst: 93.184.216.34:443 (https)",
+		"DstHost: example.org",
+		"Process: /usr/bin/curl",
+		"Container: web (docker)",
+		"PID/UID: 4242/1000",
+		"Args: curl -s https://example.org/a-very-long-path-that-the-detail-pane-would-truncate",
+		"CWD: /home/user",
+		"Rule: allow-curl",
+		"Checksums:",
+		"  md5     d41d8cd98f00b204e9800998ecf8427e",
+		"  sha256  e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"This process: 1 event",
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	idx := 0
+	for _, line := range lines {
+		if idx < len(want) && line == want[idx] {
+			idx++
+		}
+	}
+	if idx != len(want) {
+		t.Fatalf("expected %q in order, got:\n%s", want[idx], got)
+	}
+	if strings.Contains(got, "\x1b") || strings.Contains(got, "…") {
+		t.Fatalf("expected plain untruncated text, got:\n%s", got)
+	}
+}
+
+func TestCopyEventSavesWithoutOSC52(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	snapshot, ev := copyEventFixture()
+	store := state.NewStore()
+	store.SetNodes(snapshot.Nodes)
+	store.MergeEvents([]state.Event{ev})
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(160, 40)
+	press := func() tea.Cmd {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+		return cmd
+	}
+
+	t.Setenv("TERM", "xterm-256color")
+	if press() == nil || !strings.Contains(util.StripANSI(m.View()), "Copied event to clipboard") {
+		t.Fatalf("expected the event copied over OSC 52")
+	}
+
+	t.Setenv("TERM", "linux")
+	if cmd := press(); cmd != nil {
+		t.Fatalf("expected no clipboard command on the Linux console")
+	}
+	path := filepath.Join(dir, "opensnitch-tui", "clipboard.txt")
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "Saved event to "+path) {
+		t.Fatalf("expected the fallback path in the status line, got:\n%s", out)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "DstHost: example.org") {
+		t.Fatalf("expected the event saved to %s, got %q (%v)", path, data, err)
+	}
+}
@@ -2,24 +2,40 @@ package events
 
 import (
 	"fmt"
-	"sort"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
+	"github.com/adamkadaban/opensnitch-tui/internal/container"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/skew"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/components/table"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/util/netnames"
 )
 
 // Model renders recent events in a table styled like the Rules view.
 type Model struct {
 	store *state.Store
 	theme theme.Theme
+	// inspector renders connections as prototext; wire is the open wire
+	// view, if any.
+	inspector controller.WireInspector
+	wire      *widget.Pager
+	// rules enables and disables the rule an event hit; toggle is the
+	// change awaiting confirmation.
+	rules  controller.RuleManager
+	toggle *ruleToggle
 
 	width  int
 	height int
@@ -28,16 +44,50 @@ type Model struct {
 	tableOffset   int
 	tableXOffset  int
 	tableMaxWidth int
+
+	statusLine string
+	// checksumIdx is the checksum last copied from the selected event, or -1.
+	checksumIdx int
+	// showContainer adds the CONTAINER column, filled for local nodes only.
+	showContainer bool
+	containers    *container.Resolver
+	// timeMode picks how the TIME column renders; its width follows.
+	timeMode timeMode
+	// follow narrows the table to one process path while set.
+	follow *followState
+	// tail keeps the newest event of the table selected as events arrive,
+	// until a movement key is pressed.
+	tail bool
+	// filter keeps events with this rule action; empty shows all. sort
+	// orders the rows.
+	filter string
+	sort   eventSort
+	// query narrows the table to events whose process, command line,
+	// destination or rule contain its text; querying is set while it is
+	// typed. anchor is the event selected while a query is applied.
+	query    textinput.Model
+	querying bool
+	anchor   *eventKey
+	// totalEvents is the size of the history the last snapshot was
+	// narrowed from.
+	totalEvents int
+	// tableShare is the fraction of the height given to the table; zero
+	// sizes it automatically.
+	tableShare float64
+	// footprint caches the selected process's aggregate over the history.
+	footprint *footprintCache
+
+	now func() time.Time
 }
 
 const (
 	defaultTableRows = 5
 	minTableRows     = 3
 	maxTableRows     = 8
-	tableChrome      = 12
+	tableChrome      = 13
 	columnGap        = 1
 	minCursorWidth   = 2
-	minTimeWidth     = 20
+	dirWidth         = 3
 	minActionWidth   = 6
 	minDstIPWidth    = 12
 	minDstHostWidth  = 14
@@ -45,11 +95,46 @@ const (
 	minProcessWidth  = 12
 	minCmdlineWidth  = 12
 	minRuleWidth     = 10
+	containerWidth   = 16
 )
 
+// timeMode is how event times are shown in the table.
+type timeMode int
+
+const (
+	timeUTC timeMode = iota
+	timeLocal
+	timeRelative
+	timeModeCount
+)
+
+const localTimeLayout = "2006-01-02 15:04:05"
+
+func (t timeMode) String() string {
+	switch t {
+	case timeLocal:
+		return "local"
+	case timeRelative:
+		return "relative"
+	}
+	return "utc"
+}
+
+// width is the TIME column width that fits every time in this mode.
+func (t timeMode) width() int {
+	switch t {
+	case timeLocal:
+		return len(localTimeLayout)
+	case timeRelative:
+		return 10
+	}
+	return len("2006-01-02T15:04:05Z")
+}
+
 type tableLayout struct {
 	cursor  int
 	time    int
+	dir     int
 	action  int
 	dstIP   int
 	dstHost int
@@ -57,75 +142,171 @@ type tableLayout struct {
 	process int
 	cmdline int
 	rule    int
+	// container is zero while its column is hidden.
+	container int
 }
 
 func (tl tableLayout) total() int {
-	return tl.cursor + tl.time + tl.action + tl.dstIP + tl.dstHost + tl.proto + tl.process + tl.cmdline + tl.rule
+	return tl.cursor + tl.time + tl.dir + tl.action + tl.dstIP + tl.dstHost + tl.proto + tl.process + tl.cmdline + tl.rule + tl.container
 }
 
-func (tl tableLayout) count() int { return 9 }
+func (tl tableLayout) count() int {
+	n := 10
+	if tl.container > 0 {
+		n++
+	}
+	return n
+}
 
-func New(store *state.Store, th theme.Theme) view.Model {
-	return &Model{store: store, theme: th}
+func New(store *state.Store, th theme.Theme, inspector controller.WireInspector, rules controller.RuleManager) view.Model {
+	if !controller.Attached(inspector) {
+		inspector = nil
+	}
+	if !controller.Attached(rules) {
+		rules = nil
+	}
+	return &Model{store: store, theme: th, inspector: inspector, rules: rules, checksumIdx: -1, containers: container.Local, query: newQueryInput(), now: time.Now}
 }
 
 func (m *Model) Init() tea.Cmd { return nil }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	snapshot := m.store.Snapshot()
+	snapshot := m.viewSnapshot()
 	m.clampSelection(snapshot)
+	prevRow := m.rowIdx
 
+	var cmd tea.Cmd
 	switch key := msg.(type) {
 	case tea.KeyMsg:
-		switch key.String() {
-		case "left":
-			m.adjustTableX(-4)
-		case "right":
-			m.adjustTableX(4)
-		case "up":
-			if m.rowIdx > 0 {
-				m.rowIdx--
-			}
-		case "down":
-			if m.rowIdx < len(snapshot.Stats.Events)-1 {
-				m.rowIdx++
-			}
-		case "pgup":
-			m.rowIdx -= m.tableCapacity()
-			if m.rowIdx < 0 {
-				m.rowIdx = 0
-			}
-		case "pgdown":
-			m.rowIdx += m.tableCapacity()
-			if m.rowIdx >= len(snapshot.Stats.Events) {
-				m.rowIdx = max(0, len(snapshot.Stats.Events)-1)
-			}
-		case "home", "g":
-			m.rowIdx = 0
-		case "end", "G":
-			if n := len(snapshot.Stats.Events); n > 0 {
-				m.rowIdx = n - 1
-			}
+		if m.wire != nil {
+			return m, m.updateWire(key)
+		}
+		if m.toggle != nil {
+			m.updateRuleToggle(key)
+			return m, nil
+		}
+		if m.querying {
+			cmd = m.updateQuery(key, snapshot)
+			m.anchorSelection(m.shownEvents(m.store.Snapshot().Events))
+			return m, cmd
 		}
+		ctx := &keyContext{m: m, snapshot: snapshot}
+		cmd, _ = keyTable.Dispatch(key.String(), ctx)
+		m.anchorSelection(m.shownEvents(m.store.Snapshot().Events))
+		if ctx.keepStatus {
+			return m, cmd
+		}
+	}
+	if m.rowIdx != prevRow {
+		m.checksumIdx = -1
+		m.statusLine = ""
+	}
+
+	return m, cmd
+}
+
+// EnteringText reports whether the search query is being typed.
+func (m *Model) EnteringText() bool { return m.querying }
+
+// copyNextChecksum copies the selected event's checksums one per press,
+// cycling through the algorithms in display order.
+func (m *Model) copyNextChecksum(snapshot state.Snapshot) tea.Cmd {
+	if len(snapshot.Events) == 0 {
+		return nil
+	}
+	checksums := util.SortedChecksums(eventAt(snapshot.Events, m.rowIdx).Connection.ProcessChecksums)
+	if len(checksums) == 0 {
+		m.statusLine = m.theme.Warning.Render("No checksums for this event")
+		return nil
+	}
+	m.checksumIdx = util.WrapIndex(m.checksumIdx, 1, len(checksums))
+	cs := checksums[m.checksumIdx]
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Copied %s checksum to clipboard", cs.Algorithm))
+	return clipboard.Copy(cs.Value)
+}
+
+// copyVirusTotalURL shows and copies the VirusTotal report URL for the
+// selected event's sha256. Nothing is fetched; opening it is up to the user.
+func (m *Model) copyVirusTotalURL(snapshot state.Snapshot) tea.Cmd {
+	if len(snapshot.Events) == 0 {
+		return nil
+	}
+	url, ok := util.VirusTotalURL(eventAt(snapshot.Events, m.rowIdx).Connection.ProcessChecksums)
+	if !ok {
+		m.statusLine = m.theme.Warning.Render("No sha256 checksum for this event")
+		return nil
+	}
+	m.statusLine = m.theme.Success.Render("Copied " + url)
+	return clipboard.Copy(url)
+}
+
+// openWire shows the selected event's connection as the pb.Connection the
+// daemon sent.
+func (m *Model) openWire(snapshot state.Snapshot) {
+	if len(snapshot.Events) == 0 {
+		return
+	}
+	if !widget.Guard(m.theme, m.inspector != nil, &m.statusLine) {
+		return
 	}
+	conn := eventAt(snapshot.Events, m.rowIdx).Connection
+	m.wire = widget.NewPager("pb.Connection", m.inspector.ConnectionWire(conn))
+	m.statusLine = ""
+}
 
-	return m, nil
+func (m *Model) updateWire(key tea.KeyMsg) tea.Cmd {
+	switch key.String() {
+	case "esc", "ctrl+x":
+		m.wire = nil
+	case "y":
+		m.statusLine = m.theme.Success.Render("Copied prototext to clipboard")
+		return clipboard.Copy(m.wire.Text())
+	default:
+		m.wire.HandleKey(key.String(), max(6, m.height-m.tableCapacity()-tableChrome))
+	}
+	return nil
 }
 
 func (m *Model) View() string {
-	snapshot := m.store.Snapshot()
+	snapshot := m.viewSnapshot()
 	m.clampSelection(snapshot)
 
-	events := snapshot.Stats.Events
+	events := snapshot.Events
 	if len(events) == 0 {
 		msg := m.theme.Subtle.Render("No events yet.")
+		if m.follow != nil {
+			msg = m.renderFollowHeader() + "\n" + m.theme.Subtle.Render("No events from this process in the history.")
+		} else if m.queryText() != "" || m.querying {
+			msg = m.theme.Subtle.Render("No events match the search; esc clears it.")
+			if header := m.arrangeHeader(); header != "" {
+				msg = header + "\n" + msg
+			}
+			msg += "\n" + m.renderQuery(0, m.totalEvents)
+		} else if header := m.arrangeHeader(); header != "" && m.filter != "" {
+			msg = header + "\n" + m.theme.Subtle.Render("No events match the filter; f or a/d change it.")
+		}
 		return m.wrap(msg)
 	}
 
-	table := m.renderEventsTable(events)
-	detail := m.renderEventDetail(snapshot)
-	status := m.renderStatus()
-	body := lipgloss.JoinVertical(lipgloss.Left, table, detail, status)
+	table := m.renderEventsTable(events, snapshot)
+	var detail string
+	if m.wire != nil {
+		detail = m.wire.View(m.theme, max(6, m.height-m.tableCapacity()-tableChrome))
+	} else {
+		detail = m.renderEventDetail(snapshot)
+	}
+	status := m.renderStatus(len(events))
+	sections := []string{table, detail, status}
+	if header := m.renderFollowHeader(); header != "" {
+		sections = append([]string{header}, sections...)
+	}
+	if header := m.arrangeHeader(); header != "" {
+		sections = append([]string{header}, sections...)
+	}
+	if banner := m.staleBanner(snapshot); banner != "" {
+		sections = append([]string{banner}, sections...)
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, sections...)
 	return m.wrap(body)
 }
 
@@ -140,7 +321,7 @@ func (m *Model) SetTheme(th theme.Theme) {
 	m.theme = th
 }
 
-func (m *Model) renderEventsTable(events []state.Event) string {
+func (m *Model) renderEventsTable(events []state.Event, snapshot state.Snapshot) string {
 	layout := m.tableColumns()
 	start := min(m.tableOffset, max(0, len(events)-1))
 	capacity := m.tableCapacity()
@@ -155,7 +336,7 @@ func (m *Model) renderEventsTable(events []state.Event) string {
 	rows = append(rows, m.renderTableHeader(layout, gap))
 	for idx := start; idx < end; idx++ {
 		ev := eventAt(events, idx)
-		rows = append(rows, m.renderEventRow(layout, ev, idx, idx == m.rowIdx, gap))
+		rows = append(rows, m.renderEventRow(layout, ev, snapshot, idx, idx == m.rowIdx, gap))
 	}
 	if moreBelow {
 		tableWidth := layout.total() + columnGap*(layout.count()-1)
@@ -170,7 +351,7 @@ func (m *Model) renderEventsTable(events []state.Event) string {
 }
 
 func (m *Model) renderEventDetail(snapshot state.Snapshot) string {
-	events := snapshot.Stats.Events
+	events := snapshot.Events
 	if len(events) == 0 {
 		return ""
 	}
@@ -183,29 +364,84 @@ func (m *Model) renderEventDetail(snapshot state.Snapshot) string {
 
 	nodeLabel := findNodeLabel(snapshot.Nodes, ev.NodeID)
 	lines := []string{
-		fmtLine("Time", formatEventTime(ev)),
-		fmtLine("Node", nodeLabel),
+		fmtLine("Time", formatEventTime(ev)+localTimeNote(snapshot, ev)),
+		"Node: " + m.theme.AccentMark(state.NodeKeyOf(snapshot.Nodes, ev.NodeID)) + " " + util.TruncateString(nodeLabel, max(1, inner-8)),
 		fmtLine("Action", formatEventAction(ev)),
-		fmtLine("Protocol", util.Fallback(ev.Connection.Protocol, "-")),
-		fmtLine("Src", formatEndpoint(ev.Connection.SrcIP, ev.Connection.SrcPort)),
-		fmtLine("Dst", formatEndpoint(ev.Connection.DstIP, ev.Connection.DstPort)),
+		fmtLine("Protocol", util.Fallback(netnames.Protocol(ev.Connection.Protocol), "-")),
+		fmtLine("Src", formatSource(ev.Connection)),
+	}
+	if ev.Connection.Inbound() {
+		lines = append(lines, fmtLine("Direction", "inbound ← Src is the remote peer"))
+	}
+	lines = append(lines,
+		fmtLine("Dst", formatDestination(ev.Connection)),
 		fmtLine("DstHost", util.Fallback(ev.Connection.DstHost, "-")),
 		fmtLine("Process", util.Fallback(ev.Connection.ProcessPath, "-")),
+	)
+	if info, ok := m.eventContainer(snapshot.Nodes, ev); ok {
+		lines = append(lines, fmtLine("Container", info.Label()))
+	}
+	lines = append(lines,
 		fmtLine("PID/UID", formatPIDUID(ev.Connection.ProcessID, ev.Connection.UserID)),
 		fmtLine("Args", formatCmdline(ev)),
 		fmtLine("CWD", util.Fallback(ev.Connection.ProcessCWD, "-")),
 		fmtLine("Rule", util.Fallback(ev.Rule.Name, "-")),
+	)
+	if ev.Rule.Name != "" {
+		lines = append(lines, util.TruncateString("  ↳ "+matchedRuleSummary(snapshot.Rules, ev), inner))
+	}
+	if ev.Prompt.PromptID != "" {
+		lines = append(lines, fmtLine("Prompt", formatPromptLink(ev.Prompt)))
+	}
+	if checksums := util.ChecksumLines(util.SortedChecksums(ev.Connection.ProcessChecksums)); len(checksums) > 0 {
+		lines = append(lines, "Checksums:")
+		for idx, line := range checksums {
+			marker := "  "
+			if idx == m.checksumIdx {
+				marker = "› "
+			}
+			lines = append(lines, util.TruncateString(marker+line, inner))
+		}
 	}
-	if cs := formatChecksums(ev.Connection.ProcessChecksums); cs != "-" {
-		lines = append(lines, fmtLine("Checksums", cs))
+	if footprint := m.footprintLine(snapshot, ev); footprint != "" {
+		lines = append(lines, util.TruncateString(footprint, inner))
 	}
 	return m.theme.Body.Render(strings.Join(lines, "\n"))
 }
 
+// localTimeNote shows the skew-corrected local time when the event's node
+// clock is noticeably off and correction is enabled.
+func localTimeNote(snapshot state.Snapshot, ev state.Event) string {
+	if ev.UnixNano == 0 || !snapshot.Settings.ClockSkewCorrection {
+		return ""
+	}
+	for _, node := range snapshot.Nodes {
+		if node.ID == ev.NodeID && node.ClockSkewKnown && skew.Significant(node.ClockSkew) {
+			local := snapshot.EventLocalTime(ev).UTC().Format(time.RFC3339)
+			return fmt.Sprintf(" (≈ %s local, clock skew %s)", local, skew.Format(node.ClockSkew))
+		}
+	}
+	return ""
+}
+
+// matchedRuleSummary describes the rule that matched ev as it currently exists
+// on the event's node.
+func matchedRuleSummary(rules map[string][]state.Rule, ev state.Event) string {
+	rule, ok := ruleset.Lookup(rules, ev.NodeID, ev.Rule.Name)
+	if !ok {
+		return "(rule no longer exists)"
+	}
+	return ruleset.Summary(rule)
+}
+
 func (m *Model) renderTableHeader(layout tableLayout, gap string) string {
 	headerStyle := m.theme.Header.Bold(true).Padding(0)
-	labels := []string{"", "TIME", "ACTION", "DSTIP", "DSTHOST", "PROTO", "PROCESS", "CMDLINE", "RULE"}
-	widths := []int{layout.cursor, layout.time, layout.action, layout.dstIP, layout.dstHost, layout.proto, layout.process, layout.cmdline, layout.rule}
+	labels := []string{"", "TIME", "DIR", "ACTION", "DSTIP", "DSTHOST", "PROTO", "PROCESS", "CMDLINE", "RULE"}
+	widths := []int{layout.cursor, layout.time, layout.dir, layout.action, layout.dstIP, layout.dstHost, layout.proto, layout.process, layout.cmdline, layout.rule}
+	if layout.container > 0 {
+		labels = append(labels, "CONTAINER")
+		widths = append(widths, layout.container)
+	}
 	cells := make([]string, len(labels))
 	for i := range labels {
 		cells[i] = table.PadAndStyle(headerStyle, labels[i], widths[i], true)
@@ -213,7 +449,7 @@ func (m *Model) renderTableHeader(layout tableLayout, gap string) string {
 	return strings.Join(cells, gap)
 }
 
-func (m *Model) renderEventRow(layout tableLayout, ev state.Event, rowIdx int, selected bool, gap string) string {
+func (m *Model) renderEventRow(layout tableLayout, ev state.Event, snapshot state.Snapshot, rowIdx int, selected bool, gap string) string {
 	bg := m.rowStripeColor(rowIdx)
 	if selected {
 		bg = m.selectedRowColor()
@@ -225,6 +461,7 @@ func (m *Model) renderEventRow(layout tableLayout, ev state.Event, rowIdx int, s
 
 	cursorStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
 	timeStyle := stripBackground(m.theme.Title).Background(bg).Padding(0)
+	dirStyle := stripBackground(m.theme.Subtle).Background(bg).Padding(0)
 	actionStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
 	dstIPStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
 	dstHostStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
@@ -232,18 +469,27 @@ func (m *Model) renderEventRow(layout tableLayout, ev state.Event, rowIdx int, s
 	processStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
 	cmdlineStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
 	ruleStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
+	containerStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
 
 	columns := []string{
 		table.PadAndStyle(cursorStyle, cursor, layout.cursor, true),
-		table.PadAndStyle(timeStyle, formatEventTime(ev), layout.time, true),
+		table.PadAndStyle(timeStyle, m.formatTableTime(ev, snapshot.EventLocalTime(ev)), layout.time, true),
+		table.PadAndStyle(dirStyle, directionGlyph(ev.Connection), layout.dir, true),
 		table.PadAndStyle(actionStyle, formatEventAction(ev), layout.action, true),
-		table.PadAndStyle(dstIPStyle, util.Fallback(ev.Connection.DstIP, "-"), layout.dstIP, true),
+		table.PadAndStyle(dstIPStyle, util.Fallback(util.CompactIP(ev.Connection.DstIP), "-"), layout.dstIP, true),
 		table.PadAndStyle(dstHostStyle, util.Fallback(ev.Connection.DstHost, "-"), layout.dstHost, true),
-		table.PadAndStyle(protoStyle, util.Fallback(ev.Connection.Protocol, "-"), layout.proto, true),
+		table.PadAndStyle(protoStyle, util.Fallback(netnames.Protocol(ev.Connection.Protocol), "-"), layout.proto, true),
 		table.PadAndStyle(processStyle, formatProcess(ev), layout.process, true),
 		table.PadAndStyle(cmdlineStyle, formatCmdline(ev), layout.cmdline, true),
 		table.PadAndStyle(ruleStyle, util.Fallback(ev.Rule.Name, "-"), layout.rule, true),
 	}
+	if layout.container > 0 {
+		cell := "-"
+		if info, ok := m.eventContainer(snapshot.Nodes, ev); ok {
+			cell = util.Fallback(info.Name, info.ShortID())
+		}
+		columns = append(columns, table.PadAndStyle(containerStyle, cell, layout.container, true))
+	}
 
 	gapStyle := lipgloss.NewStyle().Background(bg)
 	rowGap := gapStyle.Render(gap)
@@ -271,6 +517,31 @@ func formatEventTime(ev state.Event) string {
 	return "unknown"
 }
 
+// formatTableTime renders ev's time for the TIME column in the current mode.
+// UTC shows the daemon's stamp; the local and relative modes use local, the
+// stamp corrected for the node's clock skew. Events without a timestamp
+// fall back to formatEventTime.
+func (m *Model) formatTableTime(ev state.Event, local time.Time) string {
+	if ev.UnixNano == 0 {
+		return formatEventTime(ev)
+	}
+	switch m.timeMode {
+	case timeLocal:
+		return local.Local().Format(localTimeLayout)
+	case timeRelative:
+		return util.RelativeTimeAt(local, m.now())
+	}
+	return time.Unix(0, ev.UnixNano).UTC().Format(time.RFC3339)
+}
+
+// directionGlyph marks inbound connections with ← and the rest with →.
+func directionGlyph(conn state.Connection) string {
+	if conn.Inbound() {
+		return " ←"
+	}
+	return " →"
+}
+
 func formatEventAction(ev state.Event) string {
 	if ev.Rule.Action != "" {
 		return ev.Rule.Action
@@ -293,16 +564,41 @@ func formatCmdline(ev state.Event) string {
 }
 
 func formatEndpoint(ip string, port uint32) string {
-	if ip == "" && port == 0 {
-		return "-"
+	return util.Fallback(util.FormatEndpoint(ip, port), "-")
+}
+
+// formatDestination is the destination endpoint with the service usually
+// found on its port, e.g. "1.1.1.1:53 (dns)".
+func formatDestination(conn state.Connection) string {
+	dst := formatEndpoint(conn.DstIP, conn.DstPort)
+	if name, ok := netnames.Service(conn.DstPort, conn.Protocol); ok {
+		dst += " (" + name + ")"
 	}
-	if port > 0 && ip != "" {
-		return fmt.Sprintf("%s:%d", ip, port)
+	return dst
+}
+
+// formatSource is the source endpoint.
+func formatSource(conn state.Connection) string {
+	return formatEndpoint(conn.SrcIP, conn.SrcPort)
+}
+
+// formatPromptLink summarises the prompt answer an event resulted from.
+func formatPromptLink(link state.PromptLink) string {
+	at := link.PromptedAt
+	if at.IsZero() {
+		at = link.ResolvedAt
 	}
-	if port > 0 {
-		return fmt.Sprintf(":%d", port)
+	return fmt.Sprintf("prompted at %s — answered %s/%s by %s",
+		at.Local().Format("15:04:05"), util.Fallback(link.Action, "-"), util.Fallback(link.Duration, "-"), util.Fallback(link.Source, "user"))
+}
+
+// eventContainer finds the container of ev's process. Only processes of
+// local nodes can be looked up.
+func (m *Model) eventContainer(nodes []state.Node, ev state.Event) (container.Info, bool) {
+	if !util.IsLocalNode(nodes, ev.NodeID) {
+		return container.Info{}, false
 	}
-	return ip
+	return m.containers.Lookup(ev.Connection.ProcessID, ev.Connection.ProcessPath)
 }
 
 func formatPIDUID(pid, uid uint32) string {
@@ -312,22 +608,6 @@ func formatPIDUID(pid, uid uint32) string {
 	return fmt.Sprintf("%d/%d", pid, uid)
 }
 
-func formatChecksums(checksums map[string]string) string {
-	if len(checksums) == 0 {
-		return "-"
-	}
-	keys := make([]string, 0, len(checksums))
-	for k := range checksums {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	parts := make([]string, len(keys))
-	for i, k := range keys {
-		parts[i] = fmt.Sprintf("%s=%s", k, checksums[k])
-	}
-	return strings.Join(parts, " ")
-}
-
 func findNodeLabel(nodes []state.Node, nodeID string) string {
 	for _, n := range nodes {
 		if n.ID == nodeID {
@@ -343,8 +623,55 @@ func findNodeLabel(nodes []state.Node, nodeID string) string {
 	return nodeID
 }
 
-func (m *Model) renderStatus() string {
-	return m.theme.Subtle.Render("←/→ scroll · ↑/↓ events · pgup/pgdn · home/end")
+func (m *Model) renderStatus(shown int) string {
+	text := "↑↓ pgup/pgdn · y copy · c checksum · v VirusTotal · t time · C container · D/E rule · F follow · ctrl+x wire\n/ search · a/d allowed/denied only · f filter · s sort · T tail · +/- table size"
+	if m.follow != nil {
+		text = "↑↓ pgup/pgdn move · c checksum · v VirusTotal · F/esc stop following"
+	}
+	if m.querying {
+		text = queryHelp
+	}
+	if m.wire != nil {
+		text = "↑/↓ scroll · y copy · esc close"
+	}
+	var disabled []string
+	if m.rules == nil {
+		disabled = append(disabled, "D/E rule")
+	}
+	if m.inspector == nil {
+		disabled = append(disabled, "ctrl+x wire")
+	}
+	help := widget.RenderHelp(m.theme, text, disabled)
+	if m.rules == nil && m.statusLine == "" {
+		help = widget.RenderReadOnly(m.theme) + "\n" + help
+	}
+	if m.tail && m.wire == nil {
+		help = m.theme.Success.Render("FOLLOW") + " " + help
+	}
+	if query := m.renderQuery(shown, m.totalEvents); query != "" && m.wire == nil {
+		help = query + "\n" + help
+	}
+	if m.statusLine == "" {
+		return help
+	}
+	return fmt.Sprintf("%s\n%s", m.statusLine, help)
+}
+
+// staleBanner names the nodes that stopped pinging; their events may still
+// be happening but are not reaching the table.
+func (m *Model) staleBanner(snapshot state.Snapshot) string {
+	now := m.now()
+	var notes []string
+	for _, node := range snapshot.Nodes {
+		freshness := state.NodeStaleness(node, snapshot.Stats, now)
+		if freshness.Stale {
+			notes = append(notes, fmt.Sprintf("%s (last ping %s)", util.DisplayName(node), util.RelativeTimeAt(freshness.LastSync, now)))
+		}
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return m.theme.Warning.Render("No new events arriving from " + strings.Join(notes, ", "))
 }
 
 func (m *Model) wrap(body string) string {
@@ -355,6 +682,10 @@ func (m *Model) tableCapacity() int {
 	if m.height <= 0 {
 		return defaultTableRows
 	}
+	if m.tableShare > 0 {
+		rows := int(math.Round(m.tableShare * float64(m.height)))
+		return max(minTableRows, min(rows, m.height-tableChrome))
+	}
 	capacity := m.height - tableChrome
 	if capacity < minTableRows {
 		capacity = minTableRows
@@ -368,7 +699,8 @@ func (m *Model) tableCapacity() int {
 func (m *Model) tableColumns() tableLayout {
 	layout := tableLayout{
 		cursor:  minCursorWidth,
-		time:    minTimeWidth,
+		time:    m.timeMode.width(),
+		dir:     dirWidth,
 		action:  minActionWidth,
 		dstIP:   minDstIPWidth,
 		dstHost: minDstHostWidth,
@@ -377,6 +709,9 @@ func (m *Model) tableColumns() tableLayout {
 		cmdline: minCmdlineWidth,
 		rule:    minRuleWidth,
 	}
+	if m.showContainer {
+		layout.container = containerWidth
+	}
 	inner := max(40, m.contentWidth())
 	gapWidth := columnGap * (layout.count() - 1)
 	usable := inner - gapWidth
@@ -419,7 +754,8 @@ func (m *Model) tableColumns() tableLayout {
 		}
 	} else if usable > base {
 		extra := usable - base
-		expanders := []*int{&layout.time, &layout.cmdline, &layout.process, &layout.dstHost}
+		// TIME keeps the width of its format; the rest goes to the free text.
+		expanders := []*int{&layout.cmdline, &layout.process, &layout.dstHost}
 		for extra > 0 {
 			for _, field := range expanders {
 				if extra == 0 {
@@ -443,7 +779,7 @@ func (m *Model) tableColumns() tableLayout {
 }
 
 func (m *Model) clampSelection(snapshot state.Snapshot) {
-	events := snapshot.Stats.Events
+	events := snapshot.Events
 	if len(events) == 0 {
 		m.rowIdx = 0
 		m.tableOffset = 0
@@ -2,12 +2,17 @@ package events
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/container"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view/viewtest"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
 func TestEventsSnapshot(t *testing.T) {
@@ -43,12 +48,320 @@ func TestEventsSnapshot(t *testing.T) {
 		},
 	}
 
-	stats := state.Stats{Events: events}
-	store.SetStats(stats)
+	store.MergeEvents(events)
 
 	th := theme.New(theme.Options{})
-	m := New(store, th)
+	m := New(store, th, nil, nil)
 	m.SetSize(100, 20)
+	m.(*Model).now = func() time.Time { return now }
 
 	viewtest.AssertSnapshot(t, m.View(), filepath.Join("testdata", "events.snap"))
 }
+
+func TestEventDetailMatchedRuleSummary(t *testing.T) {
+	curl := state.Rule{
+		Name:     "allow-curl",
+		Action:   "allow",
+		Duration: "always",
+		Enabled:  true,
+		Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"},
+	}
+	other := state.Rule{Name: "allow-curl", Action: "deny", Duration: "once"}
+
+	cases := []struct {
+		name  string
+		rules map[string][]state.Rule
+		want  string
+		avoid string
+	}{
+		{
+			name:  "resolved",
+			rules: map[string][]state.Rule{"node-1": {curl}},
+			want:  "↳ allow · always · enabled · simple process.path /usr/bin/curl",
+		},
+		{
+			name:  "missing",
+			rules: map[string][]state.Rule{"node-1": {{Name: "something-else"}}},
+			want:  "↳ (rule no longer exists)",
+		},
+		{
+			name:  "other node only",
+			rules: map[string][]state.Rule{"node-2": {other}},
+			want:  "↳ (rule no longer exists)",
+			avoid: "deny · once",
+		},
+		{
+			name:  "same name on several nodes",
+			rules: map[string][]state.Rule{"node-1": {curl}, "node-2": {other}},
+			want:  "↳ allow · always · enabled",
+			avoid: "deny · once",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := state.NewStore()
+			for nodeID, rules := range tc.rules {
+				store.SetRules(nodeID, rules)
+			}
+			store.MergeEvents([]state.Event{{
+				NodeID:     "node-1",
+				UnixNano:   1,
+				Connection: state.Connection{DstIP: "1.2.3.4", ProcessPath: "/usr/bin/curl"},
+				Rule:       state.Rule{Name: "allow-curl", Action: "allow"},
+			}})
+			m := New(store, theme.New(theme.Options{}), nil, nil)
+			m.SetSize(160, 30)
+
+			out := util.StripANSI(m.View())
+			if !strings.Contains(out, tc.want) {
+				t.Fatalf("expected %q in detail, got:\n%s", tc.want, out)
+			}
+			if tc.avoid != "" && strings.Contains(out, tc.avoid) {
+				t.Fatalf("did not expect %q in detail, got:\n%s", tc.avoid, out)
+			}
+		})
+	}
+}
+
+func TestEventDetailChecksumsCopy(t *testing.T) {
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{{
+		NodeID:   "node-1",
+		UnixNano: 1,
+		Connection: state.Connection{ProcessPath: "/usr/bin/curl", ProcessChecksums: map[string]string{
+			"sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			"md5":    "d41d8cd98f00b204e9800998ecf8427e",
+		}},
+	}})
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(160, 40)
+
+	out := util.StripANSI(m.View())
+	for _, want := range []string{
+		"Checksums:",
+		"  md5     d41d8cd98f00b204e9800998ecf8427e",
+		"  sha256  e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in detail, got:\n%s", want, out)
+		}
+	}
+
+	for _, want := range []string{"Copied md5 checksum", "Copied sha256 checksum", "Copied md5 checksum"} {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+		if cmd == nil {
+			t.Fatalf("expected a clipboard command")
+		}
+		if out := util.StripANSI(m.View()); !strings.Contains(out, want) {
+			t.Fatalf("expected %q, got:\n%s", want, out)
+		}
+	}
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "› md5") {
+		t.Fatalf("expected the copied checksum to be marked, got:\n%s", out)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")}); cmd == nil {
+		t.Fatalf("expected a clipboard command for the VirusTotal URL")
+	}
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "https://www.virustotal.com/gui/file/e3b0c442") {
+		t.Fatalf("expected VirusTotal URL in status, got:\n%s", out)
+	}
+}
+
+func TestEventDetailWithoutChecksumsHasNoSection(t *testing.T) {
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: 1, Connection: state.Connection{
+		ProcessPath:      "/usr/bin/curl",
+		ProcessChecksums: map[string]string{"md5": ""},
+	}}})
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(160, 40)
+	if out := util.StripANSI(m.View()); strings.Contains(out, "Checksums") {
+		t.Fatalf("expected no checksum section, got:\n%s", out)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")}); cmd != nil {
+		t.Fatalf("expected no clipboard command without a sha256")
+	}
+}
+
+func TestEventDetailShowsPromptLink(t *testing.T) {
+	resolved := time.Date(2024, time.March, 1, 14, 2, 11, 0, time.Local)
+	conn := state.Connection{ProcessPath: "/usr/bin/curl", DstIP: "93.184.216.34", DstPort: 443}
+	store := state.NewStore()
+	store.AddDecision(state.Decision{
+		PromptID:   "prompt-1",
+		NodeID:     "node-1",
+		Connection: conn,
+		Action:     "allow",
+		Duration:   "once",
+		Source:     state.DecisionSourceUser,
+		PromptedAt: resolved,
+		ResolvedAt: resolved.Add(3 * time.Second),
+	})
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: resolved.Add(4 * time.Second).UnixNano(), Connection: conn}})
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(160, 40)
+
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "Prompt: prompted at 14:02:11 — answered allow/once by user") {
+		t.Fatalf("expected prompt link in detail, got:\n%s", out)
+	}
+}
+
+func TestEventIPv6Endpoints(t *testing.T) {
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: 1, Connection: state.Connection{
+		SrcIP:       "fe80:0:0:0:0:0:0:1%eth0",
+		SrcPort:     5353,
+		DstIP:       "2001:0db8:0000:0000:0000:0000:0000:0001",
+		DstPort:     443,
+		ProcessPath: "/usr/bin/curl",
+	}}})
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(200, 40)
+
+	out := util.StripANSI(m.View())
+	for _, want := range []string{"Src: [fe80::1%eth0]:5353", "Dst: [2001:db8::1]:443"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestEventDetailNamesProtocolAndService(t *testing.T) {
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: 1, Connection: state.Connection{
+		Protocol:    "17",
+		DstIP:       "9.9.9.9",
+		DstPort:     53,
+		ProcessPath: "/usr/lib/systemd/systemd-resolved",
+	}}})
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(200, 40)
+
+	out := util.StripANSI(m.View())
+	for _, want := range []string{"Protocol: udp", "Dst: 9.9.9.9:53 (dns)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestEventsBannerNamesStaleNodes(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := state.NewStore()
+	store.SetNodes([]state.Node{
+		{ID: "node-1", Name: "alpha", LastSeen: now.Add(-2 * time.Minute)},
+		{ID: "node-2", Name: "beta", LastSeen: now.Add(-5 * time.Second)},
+	})
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: now.UnixNano(), Connection: state.Connection{ProcessPath: "/usr/bin/curl"}}})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.now = func() time.Time { return now }
+	m.SetSize(120, 24)
+
+	out := m.View()
+	if !strings.Contains(out, "No new events arriving from alpha (last ping 2m0s ago)") {
+		t.Fatalf("expected stale banner for alpha, got:\n%s", out)
+	}
+	if strings.Contains(out, "beta (last ping") {
+		t.Fatalf("fresh node should not be listed, got:\n%s", out)
+	}
+}
+
+func TestEventTimeColumnFollowsTimeMode(t *testing.T) {
+	for _, width := range []int{100, 160, 240} {
+		m := New(state.NewStore(), theme.New(theme.Options{}), nil, nil).(*Model)
+		m.SetSize(width, 40)
+		want := max(40, m.contentWidth()) - columnGap*(m.tableColumns().count()-1)
+		for _, tc := range []struct {
+			mode timeMode
+			time int
+		}{{timeUTC, 20}, {timeLocal, 19}, {timeRelative, 10}} {
+			m.timeMode = tc.mode
+			layout := m.tableColumns()
+			if layout.time != tc.time {
+				t.Errorf("width %d, %s: TIME is %d wide, want %d", width, tc.mode, layout.time, tc.time)
+			}
+			if layout.total() != want {
+				t.Errorf("width %d, %s: columns total %d, want %d", width, tc.mode, layout.total(), want)
+			}
+		}
+	}
+}
+
+func TestEventTimeModeToggle(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: now.Add(-42 * time.Second).UnixNano()}})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.now = func() time.Time { return now }
+	m.SetSize(160, 40)
+
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "2024-05-01T11:59:18Z") {
+		t.Fatalf("expected UTC time by default, got:\n%s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "42s ago") {
+		t.Fatalf("expected relative time, got:\n%s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.timeMode != timeUTC {
+		t.Fatalf("expected the mode to wrap to utc, got %s", m.timeMode)
+	}
+}
+
+func TestEventTimeCorrectsClockSkew(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := state.NewStore()
+	// The node's clock runs two minutes fast.
+	store.SetNodes([]state.Node{{ID: "node-1", ClockSkew: 2 * time.Minute, ClockSkewKnown: true}})
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: now.Add(2*time.Minute - 42*time.Second).UnixNano()}})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.now = func() time.Time { return now }
+	m.SetSize(160, 40)
+
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "2024-05-01T12:01:18Z") {
+		t.Fatalf("expected the daemon's stamp in UTC mode, got:\n%s", out)
+	}
+	m.timeMode = timeLocal
+	if out := util.StripANSI(m.View()); !strings.Contains(out, now.Add(-42*time.Second).Local().Format(localTimeLayout)) {
+		t.Fatalf("expected the corrected local time, got:\n%s", out)
+	}
+	m.timeMode = timeRelative
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "42s ago") {
+		t.Fatalf("expected the relative time from the corrected stamp, got:\n%s", out)
+	}
+
+	settings := store.Snapshot().Settings
+	settings.ClockSkewCorrection = false
+	store.SetSettings(settings)
+	if out := util.StripANSI(m.View()); strings.Contains(out, "42s ago") {
+		t.Fatalf("expected the raw stamp with correction off, got:\n%s", out)
+	}
+}
+
+func TestEventContainerColumnAndDetail(t *testing.T) {
+	store := state.NewStore()
+	store.UpsertNode(state.Node{ID: "local", Address: "unix:///tmp/osui.sock"})
+	store.MergeEvents([]state.Event{{NodeID: "local", UnixNano: 1, Connection: state.Connection{
+		ProcessPath: "/usr/bin/curl",
+		ProcessID:   106,
+	}}})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.containers = container.NewResolver(filepath.Join("..", "..", "..", "container", "testdata", "proc"))
+	m.SetSize(200, 40)
+
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "Container: e8f1a3c5d7b9 (containerd)") {
+		t.Fatalf("expected container detail line, got:\n%s", out)
+	}
+	if strings.Contains(out, "CONTAINER") {
+		t.Fatalf("expected container column hidden by default, got:\n%s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	out = util.StripANSI(m.View())
+	if !strings.Contains(out, "CONTAINER") || !strings.Contains(out, "e8f1a3c5d7b9") {
+		t.Fatalf("expected container column after toggle, got:\n%s", out)
+	}
+}
@@ -0,0 +1,185 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// maxFollowDestinations caps the destinations listed in the follow header.
+const maxFollowDestinations = 4
+
+// followState narrows the view to one process path, like tail -f for a
+// single binary.
+type followState struct {
+	path string
+	// prev is the view context restored when following stops.
+	prev viewContext
+	// shown is how many matching events were listed last time, so new ones
+	// can be scrolled to.
+	shown int
+	stats followStats
+}
+
+// viewContext is the part of the view that follow mode overrides.
+type viewContext struct {
+	rowIdx       int
+	tableOffset  int
+	tableXOffset int
+}
+
+// followStats aggregates the matching events seen while following. Unlike
+// the table it is not limited by the store's bounded event history.
+type followStats struct {
+	seen         map[string]struct{}
+	total        int
+	allowed      int
+	denied       int
+	destinations map[string]struct{}
+}
+
+func newFollowStats() followStats {
+	return followStats{seen: make(map[string]struct{}), destinations: make(map[string]struct{})}
+}
+
+// observe counts the events not seen before.
+func (s *followStats) observe(events []state.Event) {
+	for _, ev := range events {
+		key := fmt.Sprintf("%s|%d|%s|%s|%d|%s", ev.NodeID, ev.UnixNano, ev.Connection.DstIP, ev.Connection.DstHost, ev.Connection.DstPort, ev.Rule.Name)
+		if _, ok := s.seen[key]; ok {
+			continue
+		}
+		s.seen[key] = struct{}{}
+		s.total++
+		switch ev.Rule.Action {
+		case "allow":
+			s.allowed++
+		case "deny", "reject":
+			s.denied++
+		}
+		if dest := followDestination(ev.Connection); dest != "" {
+			s.destinations[dest] = struct{}{}
+		}
+	}
+}
+
+// sortedDestinations lists the distinct destinations in name order.
+func (s followStats) sortedDestinations() []string {
+	list := make([]string, 0, len(s.destinations))
+	for dest := range s.destinations {
+		list = append(list, dest)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func followDestination(conn state.Connection) string {
+	host := util.Fallback(conn.DstHost, util.CompactIP(conn.DstIP))
+	if host == "" {
+		return ""
+	}
+	if conn.DstPort == 0 {
+		return host
+	}
+	return util.FormatEndpoint(host, conn.DstPort)
+}
+
+func filterByPath(events []state.Event, path string) []state.Event {
+	matched := make([]state.Event, 0, len(events))
+	for _, ev := range events {
+		if ev.Connection.ProcessPath == path {
+			matched = append(matched, ev)
+		}
+	}
+	return matched
+}
+
+// viewSnapshot is the store snapshot as this view shows it: while following,
+// Events holds only the followed process, and new matches are counted and
+// scrolled to. While a query is applied the selected event stays selected
+// as events arrive. In tail mode the newest shown event is selected.
+func (m *Model) viewSnapshot() state.Snapshot {
+	snapshot := m.store.Snapshot()
+	m.totalEvents = len(snapshot.Events)
+	snapshot.Events = m.shownEvents(snapshot.Events)
+	if m.anchor != nil {
+		if row := rowOf(snapshot.Events, *m.anchor); row >= 0 {
+			m.rowIdx = row
+		}
+	}
+	if m.follow != nil {
+		m.follow.stats.observe(snapshot.Events)
+		if n := len(snapshot.Events); n > m.follow.shown {
+			m.rowIdx = n - 1
+		}
+		m.follow.shown = len(snapshot.Events)
+	}
+	if m.tail {
+		m.rowIdx = newestRow(snapshot.Events)
+	}
+	return snapshot
+}
+
+// shownEvents narrows events, in store order, to the rows of the table.
+func (m *Model) shownEvents(events []state.Event) []state.Event {
+	events = m.arrange(events)
+	if m.follow != nil {
+		events = filterByPath(events, m.follow.path)
+	}
+	return events
+}
+
+// startFollow follows the selected event's process, remembering the view
+// context to come back to.
+func (m *Model) startFollow(snapshot state.Snapshot) {
+	if len(snapshot.Events) == 0 {
+		return
+	}
+	path := eventAt(snapshot.Events, m.rowIdx).Connection.ProcessPath
+	if path == "" {
+		m.statusLine = m.theme.Warning.Render("No process path to follow")
+		return
+	}
+	m.follow = &followState{
+		path:  path,
+		prev:  viewContext{rowIdx: m.rowIdx, tableOffset: m.tableOffset, tableXOffset: m.tableXOffset},
+		stats: newFollowStats(),
+	}
+	m.tableOffset, m.tableXOffset = 0, 0
+	m.statusLine = ""
+	m.store.SetFollowedPath(path)
+	m.viewSnapshot()
+}
+
+// stopFollow returns to all events where the view was before following.
+func (m *Model) stopFollow() {
+	prev := m.follow.prev
+	m.follow = nil
+	m.rowIdx, m.tableOffset, m.tableXOffset = prev.rowIdx, prev.tableOffset, prev.tableXOffset
+	m.statusLine = ""
+	m.store.SetFollowedPath("")
+}
+
+// renderFollowHeader is the sticky header naming the followed path with
+// its counters and destinations.
+func (m *Model) renderFollowHeader() string {
+	if m.follow == nil {
+		return ""
+	}
+	width := max(20, m.contentWidth())
+	stats := m.follow.stats
+	title := m.theme.Title.Render(util.TruncateString("Following "+m.follow.path, width))
+	counts := fmt.Sprintf("%d events · %d allowed · %d denied · %d destinations", stats.total, stats.allowed, stats.denied, len(stats.destinations))
+	dests := stats.sortedDestinations()
+	if len(dests) > 0 {
+		shown := dests[:min(len(dests), maxFollowDestinations)]
+		counts += ": " + strings.Join(shown, ", ")
+		if extra := len(dests) - len(shown); extra > 0 {
+			counts += fmt.Sprintf(" +%d", extra)
+		}
+	}
+	return title + "\n" + m.theme.Subtle.Render(util.TruncateString(counts, width))
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func followEvent(at time.Time, path, host string, port uint32, action string) state.Event {
+	return state.Event{
+		NodeID:     "node-1",
+		UnixNano:   at.UnixNano(),
+		Connection: state.Connection{ProcessPath: path, DstHost: host, DstPort: port, Protocol: "tcp"},
+		Rule:       state.Rule{Name: action + "-rule", Action: action},
+	}
+}
+
+var followKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}}
+
+func TestFollowStatsCountEachEventOnce(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	events := []state.Event{
+		followEvent(now, "/usr/bin/curl", "example.com", 443, "allow"),
+		followEvent(now.Add(time.Second), "/usr/bin/curl", "example.org", 443, "deny"),
+		followEvent(now.Add(2*time.Second), "/usr/bin/curl", "example.com", 443, "allow"),
+	}
+	stats := newFollowStats()
+	stats.observe(events[:2])
+	stats.observe(events) // the first two again, plus one new
+	if stats.total != 3 || stats.allowed != 2 || stats.denied != 1 {
+		t.Fatalf("unexpected counters %+v", stats)
+	}
+	if got := strings.Join(stats.sortedDestinations(), ","); got != "example.com:443,example.org:443" {
+		t.Fatalf("unexpected destinations %q", got)
+	}
+}
+
+func TestFollowModeFiltersAndRestoresContext(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{
+		followEvent(now, "/usr/bin/curl", "example.com", 443, "allow"),
+		followEvent(now.Add(time.Second), "/usr/bin/dig", "dns.example", 53, "allow"),
+		followEvent(now.Add(2*time.Second), "/usr/bin/curl", "example.org", 443, "deny"),
+		followEvent(now.Add(3*time.Second), "/usr/bin/dig", "dns.example", 53, "allow"),
+	})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.SetSize(120, 30)
+	m.rowIdx, m.tableXOffset = 0, 4 // oldest event: curl
+
+	m.Update(followKey)
+	if m.follow == nil || m.follow.path != "/usr/bin/curl" {
+		t.Fatalf("expected to follow curl, got %+v", m.follow)
+	}
+	if store.Snapshot().FollowedPath != "/usr/bin/curl" {
+		t.Fatalf("expected the followed path in the store")
+	}
+	out := util.StripANSI(m.View())
+	if strings.Contains(out, "dns.example") {
+		t.Fatalf("expected only curl events, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Following /usr/bin/curl") || !strings.Contains(out, "2 events · 1 allowed · 1 denied · 2 destinations") {
+		t.Fatalf("expected sticky header with counters, got:\n%s", out)
+	}
+	if m.rowIdx != 1 {
+		t.Fatalf("expected newest matching event selected, got row %d", m.rowIdx)
+	}
+
+	// New matching events scroll into view; others stay out.
+	store.MergeEvents([]state.Event{
+		followEvent(now.Add(4*time.Second), "/usr/bin/curl", "example.net", 80, "allow"),
+		followEvent(now.Add(5*time.Second), "/usr/bin/dig", "dns.example", 53, "allow"),
+	})
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m.View()
+	if m.rowIdx != 1 {
+		t.Fatalf("expected the manual move to hold without new events, got row %d", m.rowIdx)
+	}
+	store.MergeEvents([]state.Event{followEvent(now.Add(6*time.Second), "/usr/bin/curl", "example.net", 80, "allow")})
+	m.View()
+	if m.rowIdx != 3 || m.follow.stats.total != 4 {
+		t.Fatalf("expected to jump to the new event, got row %d stats %+v", m.rowIdx, m.follow.stats)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.follow != nil || store.Snapshot().FollowedPath != "" {
+		t.Fatalf("expected esc to stop following")
+	}
+	if m.rowIdx != 0 || m.tableXOffset != 4 {
+		t.Fatalf("expected previous position restored, got row %d x %d", m.rowIdx, m.tableXOffset)
+	}
+	if !strings.Contains(m.View(), "dns.example") {
+		t.Fatalf("expected all events after following stops")
+	}
+}
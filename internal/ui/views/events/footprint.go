@@ -0,0 +1,60 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// footprintCache is the footprint of one process, valid while the event
+// history stays at rev.
+type footprintCache struct {
+	path string
+	rev  uint64
+	fp   state.Footprint
+}
+
+// processFootprint aggregates path across the whole history, ignoring the
+// filter and follow. It is only recomputed when the selection moves to
+// another process or the history changes.
+func (m *Model) processFootprint(path string, rev uint64) state.Footprint {
+	if c := m.footprint; c != nil && c.path == path && c.rev == rev {
+		return c.fp
+	}
+	snapshot := m.store.Snapshot()
+	m.footprint = &footprintCache{
+		path: path,
+		rev:  snapshot.EventsRevision,
+		fp:   state.ProcessFootprint(snapshot.Events, path),
+	}
+	return m.footprint.fp
+}
+
+// footprintLine summarizes the selected event's process, or is empty when
+// the event has none.
+func (m *Model) footprintLine(snapshot state.Snapshot, ev state.Event) string {
+	path := ev.Connection.ProcessPath
+	if path == "" {
+		return ""
+	}
+	fp := m.processFootprint(path, snapshot.EventsRevision)
+	parts := []string{fmt.Sprintf("%d %s, %d allowed, %d denied, %d %s",
+		fp.Events, plural(fp.Events, "event"), fp.Allowed, fp.Denied, fp.Destinations, plural(fp.Destinations, "destination"))}
+	switch {
+	case fp.First.IsZero():
+	case fp.First.Equal(fp.Last):
+		parts = append(parts, "seen "+util.RelativeTimeAt(fp.Last, m.now()))
+	default:
+		parts = append(parts, "first "+util.RelativeTimeAt(fp.First, m.now()), "last "+util.RelativeTimeAt(fp.Last, m.now()))
+	}
+	return "This process: " + strings.Join(parts, " · ")
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
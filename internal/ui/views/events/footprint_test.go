@@ -0,0 +1,51 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func TestEventsFootprintCachedUntilHistoryChanges(t *testing.T) {
+	base := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	event := func(offset time.Duration, path, host, action string) state.Event {
+		return state.Event{
+			NodeID:     "node-1",
+			UnixNano:   base.Add(offset).UnixNano(),
+			Connection: state.Connection{ProcessPath: path, DstHost: host, DstIP: "1.2.3.4"},
+			Rule:       state.Rule{Name: "r", Action: action},
+		}
+	}
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{
+		event(0, "/usr/bin/curl", "a.example", "allow"),
+		event(time.Minute, "/usr/bin/curl", "b.example", "deny"),
+		event(2*time.Minute, "/usr/bin/dig", "c.example", "allow"),
+	})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.now = func() time.Time { return base.Add(5 * time.Minute) }
+	m.SetSize(140, 40)
+	m.filter = "deny" // the footprint ignores the filter
+
+	out := m.View()
+	want := "This process: 2 events, 1 allowed, 1 denied, 2 destinations · first 5m0s ago · last 4m0s ago"
+	if !strings.Contains(out, want) {
+		t.Fatalf("expected %q, got:\n%s", want, out)
+	}
+	cached := m.footprint
+	m.View()
+	if m.footprint != cached {
+		t.Fatalf("expected the footprint to be reused between frames")
+	}
+
+	store.MergeEvents([]state.Event{event(3*time.Minute, "/usr/bin/curl", "a.example", "deny")})
+	if out := m.View(); !strings.Contains(out, "This process: 3 events, 1 allowed, 2 denied, 2 destinations") {
+		t.Fatalf("expected the footprint to follow the history, got:\n%s", out)
+	}
+	if m.footprint == cached {
+		t.Fatalf("expected a new footprint after the history changed")
+	}
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// keyContext is what the events actions work on.
+type keyContext struct {
+	m        *Model
+	snapshot state.Snapshot
+	// keepStatus skips the reset of the copied checksum and status line
+	// that follows a change of the selected row.
+	keepStatus bool
+}
+
+// action is one entry of the events key table.
+type action = keymap.Action[*keyContext]
+
+var keyTable = keymap.NewTable(
+	action{ID: "events.follow", Keys: []string{"F"}, Help: "follow the selected process", Run: keymap.Do(func(c *keyContext) {
+		if c.m.follow != nil {
+			c.m.stopFollow()
+		} else {
+			c.m.startFollow(c.snapshot)
+		}
+		c.keepStatus = true
+	})},
+	action{ID: "events.unfollow", Keys: []string{"esc"}, Help: "stop following", Enabled: following, Run: keymap.Do(func(c *keyContext) {
+		c.m.stopFollow()
+		c.keepStatus = true
+	})},
+	action{ID: "events.clear-search", Keys: []string{"esc"}, Help: "clear the search", Enabled: searched, Run: keymap.Do(func(c *keyContext) { c.m.clearQuery(c.snapshot) })},
+	action{ID: "events.search", Keys: []string{"/"}, Help: "search the events", Run: keymap.Do(func(c *keyContext) { c.m.startQuery() })},
+	action{ID: "events.allowed", Keys: []string{"a"}, Help: "show only allowed events", Run: keymap.Do(func(c *keyContext) { c.m.toggleAction(c.snapshot, "allow") })},
+	action{ID: "events.denied", Keys: []string{"d"}, Help: "show only denied events", Run: keymap.Do(func(c *keyContext) { c.m.toggleAction(c.snapshot, "deny") })},
+	action{ID: "events.wire", Keys: []string{"ctrl+x"}, Help: "show the connection on the wire", Run: keymap.Do(func(c *keyContext) { c.m.openWire(c.snapshot) })},
+	action{ID: "events.checksum", Keys: []string{"c"}, Help: "copy the next checksum", Run: func(c *keyContext) tea.Cmd { return c.m.copyNextChecksum(c.snapshot) }},
+	action{ID: "events.copy", Keys: []string{"y"}, Help: "copy the event details", Run: func(c *keyContext) tea.Cmd { return c.m.copyEvent(c.snapshot) }},
+	action{ID: "events.virustotal", Keys: []string{"v"}, Help: "copy the VirusTotal URL", Run: func(c *keyContext) tea.Cmd { return c.m.copyVirusTotalURL(c.snapshot) }},
+	action{ID: "events.container", Keys: []string{"C"}, Help: "toggle the CONTAINER column", Run: keymap.Do(func(c *keyContext) { c.m.showContainer = !c.m.showContainer })},
+	action{ID: "events.disable-rule", Keys: []string{"D"}, Help: "disable the rule hit", Run: keymap.Do(func(c *keyContext) { c.m.askRuleToggle(c.snapshot, false) })},
+	action{ID: "events.enable-rule", Keys: []string{"E"}, Help: "enable the rule hit", Run: keymap.Do(func(c *keyContext) { c.m.askRuleToggle(c.snapshot, true) })},
+	action{ID: "events.time", Keys: []string{"t"}, Help: "cycle the TIME format", Run: keymap.Do(func(c *keyContext) { c.m.timeMode = (c.m.timeMode + 1) % timeModeCount })},
+	action{ID: "events.filter", Keys: []string{"f"}, Help: "cycle the action filter", Run: keymap.Do(func(c *keyContext) { c.m.cycleFilter() })},
+	action{ID: "events.tail", Keys: []string{"T"}, Help: "keep the newest event selected", Run: keymap.Do(func(c *keyContext) { c.m.toggleTail(c.snapshot) })},
+	action{ID: "events.sort", Keys: []string{"s"}, Help: "cycle the sort order", Run: keymap.Do(func(c *keyContext) { c.m.cycleSort() })},
+	action{ID: "events.grow", Keys: []string{"+"}, Help: "grow the table", Run: keymap.Do(func(c *keyContext) { c.m.resizeTable(0.1) })},
+	action{ID: "events.shrink", Keys: []string{"-"}, Help: "shrink the table", Run: keymap.Do(func(c *keyContext) { c.m.resizeTable(-0.1) })},
+	action{ID: "events.scroll-left", Keys: []string{"left", "h"}, Help: "scroll left", Run: keymap.Do(func(c *keyContext) { c.m.adjustTableX(-4) })},
+	action{ID: "events.scroll-right", Keys: []string{"right", "l"}, Help: "scroll right", Run: keymap.Do(func(c *keyContext) { c.m.adjustTableX(4) })},
+	action{ID: "events.up", Keys: []string{"up", "k"}, Help: "previous event", Run: keymap.Do(navigate(func(c *keyContext) {
+		if c.m.rowIdx > 0 {
+			c.m.rowIdx--
+		}
+	}))},
+	action{ID: "events.down", Keys: []string{"down", "j"}, Help: "next event", Run: keymap.Do(navigate(func(c *keyContext) {
+		if c.m.rowIdx < len(c.snapshot.Events)-1 {
+			c.m.rowIdx++
+		}
+	}))},
+	action{ID: "events.page-up", Keys: []string{"pgup"}, Help: "page up", Run: keymap.Do(navigate(func(c *keyContext) {
+		c.m.rowIdx = max(0, c.m.rowIdx-c.m.tableCapacity())
+	}))},
+	action{ID: "events.page-down", Keys: []string{"pgdown"}, Help: "page down", Run: keymap.Do(navigate(func(c *keyContext) {
+		c.m.rowIdx += c.m.tableCapacity()
+		if c.m.rowIdx >= len(c.snapshot.Events) {
+			c.m.rowIdx = max(0, len(c.snapshot.Events)-1)
+		}
+	}))},
+	action{ID: "events.first", Keys: []string{"home", "g"}, Help: "first event", Run: keymap.Do(navigate(func(c *keyContext) { c.m.rowIdx = 0 }))},
+	action{ID: "events.last", Keys: []string{"end", "G"}, Help: "last event", Run: keymap.Do(navigate(func(c *keyContext) {
+		if n := len(c.snapshot.Events); n > 0 {
+			c.m.rowIdx = n - 1
+		}
+	}))},
+)
+
+// following enables stopping a follow.
+func following(c *keyContext) bool { return c.m.follow != nil }
+
+// searched enables clearing an applied search.
+func searched(c *keyContext) bool { return c.m.queryText() != "" }
+
+// KeyHelp lists the events keys for the help overlay.
+func (m *Model) KeyHelp() []keymap.Help { return keyTable.Help() }
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// eventSort orders the table. Rows are shown oldest first within a key, as
+// they are when sorted by time.
+type eventSort int
+
+const (
+	sortTime eventSort = iota
+	sortProcess
+	sortDestination
+	sortCount
+)
+
+func (s eventSort) String() string {
+	switch s {
+	case sortProcess:
+		return "process"
+	case sortDestination:
+		return "destination"
+	}
+	return "time"
+}
+
+// key is the value events are compared by; sortTime keeps store order.
+func (s eventSort) key(ev state.Event) string {
+	switch s {
+	case sortProcess:
+		return ev.Connection.ProcessPath
+	case sortDestination:
+		return cmp.Or(ev.Connection.DstHost, ev.Connection.DstIP)
+	}
+	return ""
+}
+
+// actionFilters are the values f cycles through; empty shows every event.
+var actionFilters = []string{"", "allow", "deny", "reject"}
+
+// arrange applies the action filter, query and sort to events, which are in
+// store order (newest first). The input is not modified.
+func (m *Model) arrange(events []state.Event) []state.Event {
+	query := m.queryText()
+	if m.filter == "" && query == "" && m.sort == sortTime {
+		return events
+	}
+	out := make([]state.Event, 0, len(events))
+	for _, ev := range events {
+		if (m.filter == "" || strings.EqualFold(ev.Rule.Action, m.filter)) && (query == "" || matchesQuery(ev, query)) {
+			out = append(out, ev)
+		}
+	}
+	if m.sort != sortTime {
+		// Display order is the reverse of store order, so sort descending.
+		slices.SortStableFunc(out, func(a, b state.Event) int {
+			return cmp.Compare(m.sort.key(b), m.sort.key(a))
+		})
+	}
+	return out
+}
+
+// cycleFilter moves to the next action filter.
+func (m *Model) cycleFilter() {
+	idx := slices.Index(actionFilters, m.filter)
+	m.filter = actionFilters[(idx+1)%len(actionFilters)]
+	m.resetSelection()
+}
+
+// cycleSort moves to the next sort order.
+func (m *Model) cycleSort() {
+	m.sort = (m.sort + 1) % sortCount
+	m.resetSelection()
+}
+
+func (m *Model) resetSelection() {
+	m.rowIdx = 0
+	m.tableOffset = 0
+	m.checksumIdx = -1
+	m.statusLine = ""
+}
+
+// resizeTable grows or shrinks the table's share of the view height by
+// delta, starting from the automatic size when no share is set.
+func (m *Model) resizeTable(delta float64) {
+	share := m.tableShare
+	if share == 0 {
+		share = math.Round(float64(m.tableCapacity())/float64(max(1, m.height))*10) / 10
+	}
+	m.tableShare = min(config.MaxTableShare, max(config.MinTableShare, share+delta))
+	m.tableShare = math.Round(m.tableShare*10) / 10
+}
+
+// arrangeHeader describes a filter or sort that differs from the default.
+func (m *Model) arrangeHeader() string {
+	var parts []string
+	if m.filter != "" {
+		parts = append(parts, fmt.Sprintf("Showing %s events", m.filter))
+	}
+	if m.sort != sortTime {
+		parts = append(parts, "sorted by "+m.sort.String())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return m.theme.Subtle.Render(strings.Join(parts, " · "))
+}
+
+// ApplyProfile switches to the profile's filter, sort, columns, time format
+// and table size. Values the view does not know fall back to the defaults.
+func (m *Model) ApplyProfile(profile state.Profile) {
+	layout := profile.Events
+	m.filter = ""
+	if slices.Contains(actionFilters, layout.Filter) {
+		m.filter = layout.Filter
+	}
+	m.sort = sortTime
+	for s := range sortCount {
+		if s.String() == layout.Sort {
+			m.sort = s
+		}
+	}
+	m.timeMode = timeUTC
+	for t := range timeModeCount {
+		if t.String() == layout.Time {
+			m.timeMode = t
+		}
+	}
+	m.showContainer = slices.Contains(layout.Columns, "container")
+	m.tableShare = layout.TableShare
+	m.resetSelection()
+}
+
+// CaptureProfile records the current layout into profile.
+func (m *Model) CaptureProfile(profile *state.Profile) {
+	var columns []string
+	for _, c := range []struct {
+		name  string
+		shown bool
+	}{{"container", m.showContainer}} {
+		if c.shown {
+			columns = append(columns, c.name)
+		}
+	}
+	profile.Events = state.EventsLayout{
+		Filter:     m.filter,
+		Sort:       m.sort.String(),
+		Columns:    columns,
+		Time:       m.timeMode.String(),
+		TableShare: m.tableShare,
+	}
+}
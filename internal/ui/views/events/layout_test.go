@@ -0,0 +1,110 @@
+package events
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func layoutFixture() *Model {
+	now := time.Unix(1700000000, 0)
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{
+		followEvent(now, "/usr/bin/wget", "b.example", 443, "allow"),
+		followEvent(now.Add(time.Second), "/usr/bin/curl", "c.example", 443, "deny"),
+		followEvent(now.Add(2*time.Second), "/usr/bin/dig", "a.example", 53, "deny"),
+		followEvent(now.Add(3*time.Second), "/usr/bin/curl", "d.example", 443, "allow"),
+	})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.SetSize(120, 30)
+	return m
+}
+
+// shownPaths lists the process of each row in display order.
+func shownPaths(m *Model) []string {
+	events := m.viewSnapshot().Events
+	paths := make([]string, len(events))
+	for i := range events {
+		paths[i] = eventAt(events, i).Connection.ProcessPath
+	}
+	return paths
+}
+
+func TestEventsFilterAndSort(t *testing.T) {
+	m := layoutFixture()
+	press(m, "f") // allow
+	press(m, "f") // deny
+	if got := shownPaths(m); !reflect.DeepEqual(got, []string{"/usr/bin/curl", "/usr/bin/dig"}) {
+		t.Fatalf("expected only denied events, got %v", got)
+	}
+	if out := press(m, "s"); !strings.Contains(out, "Showing deny events · sorted by process") {
+		t.Fatalf("expected the filter and sort described, got:\n%s", out)
+	}
+
+	press(m, "f")
+	press(m, "f") // all again
+	if got := shownPaths(m); !reflect.DeepEqual(got, []string{"/usr/bin/curl", "/usr/bin/curl", "/usr/bin/dig", "/usr/bin/wget"}) {
+		t.Fatalf("expected events sorted by process, got %v", got)
+	}
+	// Ties keep time order: the older curl event comes first.
+	if host := eventAt(m.viewSnapshot().Events, 0).Connection.DstHost; host != "c.example" {
+		t.Fatalf("expected the older curl event first, got %s", host)
+	}
+	press(m, "s")
+	if got := eventAt(m.viewSnapshot().Events, 0).Connection.DstHost; got != "a.example" {
+		t.Fatalf("expected events sorted by destination, got %s first", got)
+	}
+}
+
+func TestEventsTableShare(t *testing.T) {
+	m := layoutFixture()
+	if got := m.tableCapacity(); got != maxTableRows {
+		t.Fatalf("expected the automatic size, got %d", got)
+	}
+	press(m, "+")
+	if m.tableShare != 0.4 || m.tableCapacity() != 12 {
+		t.Fatalf("expected a 0.4 share of 12 rows, got %v and %d", m.tableShare, m.tableCapacity())
+	}
+	for range 10 {
+		press(m, "+")
+	}
+	if m.tableShare != 0.8 || m.tableCapacity() != 30-tableChrome {
+		t.Fatalf("expected the share capped and rows left for the detail, got %v and %d", m.tableShare, m.tableCapacity())
+	}
+}
+
+func TestEventsProfileRoundTrip(t *testing.T) {
+	m := layoutFixture()
+	for _, key := range []string{"f", "f", "s", "C", "t", "t", "+"} {
+		press(m, key)
+	}
+	var profile state.Profile
+	m.CaptureProfile(&profile)
+	want := state.EventsLayout{Filter: "deny", Sort: "process", Columns: []string{"container"}, Time: "relative", TableShare: 0.4}
+	if !reflect.DeepEqual(profile.Events, want) {
+		t.Fatalf("unexpected capture %+v", profile.Events)
+	}
+
+	other := layoutFixture()
+	other.ApplyProfile(profile)
+	var again state.Profile
+	other.CaptureProfile(&again)
+	if !reflect.DeepEqual(again.Events, want) {
+		t.Fatalf("expected the applied layout captured back, got %+v", again.Events)
+	}
+	if got := shownPaths(other); !reflect.DeepEqual(got, shownPaths(m)) {
+		t.Fatalf("expected the same rows, got %v and %v", got, shownPaths(m))
+	}
+
+	// An empty profile restores the defaults.
+	other.ApplyProfile(state.Profile{})
+	if other.filter != "" || other.sort != sortTime || other.showContainer || other.timeMode != timeUTC || other.tableShare != 0 {
+		var got state.Profile
+		other.CaptureProfile(&got)
+		t.Fatalf("expected the default layout, got %+v", got.Events)
+	}
+}
@@ -0,0 +1,151 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+const queryHelp = "type to search process, command line, destination or rule · ↑/↓ move · enter keep · esc clear"
+
+// eventKey identifies an event across snapshots, so the selection can stay
+// on it while new events shift the rows.
+type eventKey struct {
+	nodeID   string
+	unixNano int64
+	pid      uint32
+	srcPort  uint32
+	dstIP    string
+	dstPort  uint32
+}
+
+func keyOf(ev state.Event) eventKey {
+	conn := ev.Connection
+	return eventKey{nodeID: ev.NodeID, unixNano: ev.UnixNano, pid: conn.ProcessID, srcPort: conn.SrcPort, dstIP: conn.DstIP, dstPort: conn.DstPort}
+}
+
+// rowOf returns the display row of the event with key, or -1.
+func rowOf(events []state.Event, key eventKey) int {
+	for idx := range events {
+		if keyOf(events[idx]) == key {
+			return len(events) - 1 - idx
+		}
+	}
+	return -1
+}
+
+func newQueryInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "/ "
+	input.Placeholder = "process, command line, host, IP or rule"
+	input.CharLimit = 128
+	input.Width = 40
+	return input
+}
+
+func (m *Model) startQuery() {
+	m.query.Focus()
+	m.querying = true
+}
+
+// updateQuery narrows the table as the query is typed; the arrows keep
+// moving through the events left.
+func (m *Model) updateQuery(key tea.KeyMsg, snapshot state.Snapshot) tea.Cmd {
+	switch key.Type {
+	case tea.KeyEsc:
+		m.querying = false
+		m.clearQuery(snapshot)
+		return nil
+	case tea.KeyEnter:
+		m.querying = false
+		m.query.Blur()
+		return nil
+	case tea.KeyUp, tea.KeyDown:
+		cmd, _ := keyTable.Dispatch(key.String(), &keyContext{m: m, snapshot: snapshot})
+		return cmd
+	}
+	var cmd tea.Cmd
+	m.keepSelection(snapshot, func() { m.query, cmd = m.query.Update(key) })
+	return cmd
+}
+
+func (m *Model) clearQuery(snapshot state.Snapshot) {
+	m.query.Blur()
+	m.keepSelection(snapshot, func() { m.query.SetValue("") })
+}
+
+func (m *Model) queryText() string {
+	return strings.ToLower(strings.TrimSpace(m.query.Value()))
+}
+
+// toggleAction shows only events with the given rule action, or every
+// event again when that filter is already on.
+func (m *Model) toggleAction(snapshot state.Snapshot, action string) {
+	m.keepSelection(snapshot, func() {
+		if m.filter == action {
+			m.filter = ""
+		} else {
+			m.filter = action
+		}
+	})
+}
+
+// keepSelection applies a change to the filters, keeping the selected
+// event selected when it is still shown and going to the top otherwise.
+func (m *Model) keepSelection(snapshot state.Snapshot, change func()) {
+	var selected *eventKey
+	if len(snapshot.Events) > 0 {
+		key := keyOf(eventAt(snapshot.Events, m.rowIdx))
+		selected = &key
+	}
+	change()
+	events := m.shownEvents(m.store.Snapshot().Events)
+	m.rowIdx, m.tableOffset = 0, 0
+	if selected != nil {
+		if row := rowOf(events, *selected); row >= 0 {
+			m.rowIdx = row
+		}
+	}
+	m.checksumIdx = -1
+	m.clampSelection(state.Snapshot{Events: events})
+	m.anchorSelection(events)
+}
+
+// anchorSelection remembers the selected event while a query is applied,
+// so the next snapshot finds it again however many events arrived.
+func (m *Model) anchorSelection(events []state.Event) {
+	m.anchor = nil
+	if m.queryText() == "" || len(events) == 0 {
+		return
+	}
+	key := keyOf(eventAt(events, m.rowIdx))
+	m.anchor = &key
+}
+
+// matchesQuery reports whether the process path, command line,
+// destination or rule name of ev contain query, which is lower case.
+func matchesQuery(ev state.Event, query string) bool {
+	conn := ev.Connection
+	for _, field := range []string{conn.ProcessPath, strings.Join(conn.ProcessArgs, " "), conn.DstHost, conn.DstIP, ev.Rule.Name} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderQuery shows the query being typed, or the applied one with how
+// many events it leaves.
+func (m *Model) renderQuery(shown, total int) string {
+	if m.querying {
+		return fmt.Sprintf("%s  %s", m.query.View(), m.theme.Subtle.Render(fmt.Sprintf("matches: %d/%d", shown, total)))
+	}
+	if query := m.query.Value(); query != "" {
+		return m.theme.Subtle.Render(fmt.Sprintf("matches: %d/%d · %q (/ to change, esc to clear)", shown, total, query))
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// syntheticEvents returns n events a second apart starting at first: every
+// third is curl, the rest dig, alternately allowed and denied.
+func syntheticEvents(first, n int) []state.Event {
+	base := time.Unix(1700000000, 0)
+	events := make([]state.Event, 0, n)
+	for i := first; i < first+n; i++ {
+		process, action := "/usr/bin/dig", "deny"
+		if i%3 == 0 {
+			process = "/usr/bin/curl"
+		}
+		if i%2 == 0 {
+			action = "allow"
+		}
+		at := base.Add(time.Duration(i) * time.Second)
+		events = append(events, state.Event{
+			NodeID:   "node-1",
+			Time:     at.Format(time.RFC3339),
+			UnixNano: at.UnixNano(),
+			Connection: state.Connection{
+				DstIP:       fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+				DstHost:     fmt.Sprintf("host%d.example", i),
+				DstPort:     443,
+				ProcessID:   uint32(1000 + i),
+				ProcessPath: process,
+			},
+			Rule: state.Rule{Name: action + "-" + process[len("/usr/bin/"):], Action: action},
+		})
+	}
+	return events
+}
+
+func typeText(m *Model, text string) {
+	for _, r := range text {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func selectedHost(m *Model) string {
+	snapshot := m.viewSnapshot()
+	m.clampSelection(snapshot)
+	return eventAt(snapshot.Events, m.rowIdx).Connection.DstHost
+}
+
+func newQueryModel(t *testing.T) (*Model, *state.Store) {
+	t.Helper()
+	store := state.NewStore()
+	store.MergeEvents(syntheticEvents(0, 50))
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.SetSize(160, 20)
+	m.now = func() time.Time { return time.Unix(1700000100, 0) }
+	return m, store
+}
+
+func TestEventsQueryPaginatesMatches(t *testing.T) {
+	m, _ := newQueryModel(t)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if !m.EnteringText() {
+		t.Fatalf("expected / to open the search")
+	}
+	typeText(m, "CURL")
+	if out := m.View(); !strings.Contains(out, "matches: 17/50") {
+		t.Fatalf("expected the match count while typing, got %q", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Rows are oldest first; paging moves through the matches only.
+	if got := selectedHost(m); got != "host0.example" {
+		t.Fatalf("expected the oldest match selected, got %s", got)
+	}
+	page := m.tableCapacity()
+	m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if got, want := selectedHost(m), fmt.Sprintf("host%d.example", 3*page); got != want {
+		t.Fatalf("expected pgdown to select %s, got %s", want, got)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if got := selectedHost(m); got != "host48.example" {
+		t.Fatalf("expected the last match selected, got %s", got)
+	}
+	if out := m.View(); !strings.Contains(out, `matches: 17/50 · "CURL"`) || strings.Contains(out, "/usr/bin/dig") {
+		t.Fatalf("expected only curl rows and the applied query, got %q", out)
+	}
+
+	// Searching by destination, then for something no event has.
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	m.query.SetValue("")
+	typeText(m, "host4")
+	if out := m.View(); !strings.Contains(out, "matches: 11/50") {
+		t.Fatalf("expected host4 and host40-49 to match, got %q", out)
+	}
+	m.query.SetValue("")
+	typeText(m, "zzz")
+	if out := m.View(); !strings.Contains(out, "No events match the search") || !strings.Contains(out, "matches: 0/50") {
+		t.Fatalf("expected the empty search message, got %q", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.EnteringText() || m.queryText() != "" || len(m.viewSnapshot().Events) != 50 {
+		t.Fatalf("expected esc to clear the search")
+	}
+}
+
+func TestEventsQueryKeepsSelectionAsEventsArrive(t *testing.T) {
+	m, store := newQueryModel(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	typeText(m, "curl")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for range 6 {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if got := selectedHost(m); got != "host18.example" {
+		t.Fatalf("expected host18 selected, got %s", got)
+	}
+
+	// 160 more events push the 10 oldest out of the history, four of them
+	// curl, so the selected event moves up four rows.
+	for first := 50; first < 210; first += 20 {
+		store.MergeEvents(syntheticEvents(first, 20))
+		m.View()
+	}
+	if got := selectedHost(m); got != "host18.example" {
+		t.Fatalf("expected the selection to stay on host18, got %s", got)
+	}
+	if out := m.View(); !strings.Contains(out, "matches: 66/200") {
+		t.Fatalf("expected the count over the new history, got %q", out)
+	}
+}
+
+func TestEventsActionToggles(t *testing.T) {
+	m, _ := newQueryModel(t)
+	for range 3 {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	press := func(r rune) { m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}) }
+
+	press('d')
+	if n := len(m.viewSnapshot().Events); n != 25 || selectedHost(m) != "host3.example" {
+		t.Fatalf("expected 25 denied events with host3 still selected, got %d and %s", n, selectedHost(m))
+	}
+	press('a')
+	if n := len(m.viewSnapshot().Events); n != 25 || m.filter != "allow" || selectedHost(m) != "host0.example" {
+		t.Fatalf("expected 25 allowed events from the top, got %d, %q and %s", n, m.filter, selectedHost(m))
+	}
+	// The toggles combine with the search.
+	press('/')
+	typeText(m, "curl")
+	if out := m.View(); !strings.Contains(out, "matches: 9/50") || !strings.Contains(out, "Showing allow events") {
+		t.Fatalf("expected allowed curl events only, got %q", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	press('a')
+	if n := len(m.viewSnapshot().Events); n != 17 || m.filter != "" {
+		t.Fatalf("expected a to turn the action filter off, got %d events", n)
+	}
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+)
+
+// ruleToggle is an enable or disable of the rule an event hit, waiting for
+// the user to confirm it.
+type ruleToggle struct {
+	nodeID string
+	node   string
+	rule   string
+	hash   string
+	enable bool
+}
+
+func (t ruleToggle) verb() string {
+	if t.enable {
+		return "enable"
+	}
+	return "disable"
+}
+
+// askRuleToggle resolves the selected event's rule on its node and asks
+// for confirmation before enabling or disabling it.
+func (m *Model) askRuleToggle(snapshot state.Snapshot, enable bool) {
+	if len(snapshot.Events) == 0 {
+		return
+	}
+	ev := eventAt(snapshot.Events, m.rowIdx)
+	if ev.Rule.Name == "" {
+		m.statusLine = m.theme.Warning.Render("This event matched no rule")
+		return
+	}
+	if !widget.Guard(m.theme, m.rules != nil, &m.statusLine) {
+		return
+	}
+	nodeLabel := findNodeLabel(snapshot.Nodes, ev.NodeID)
+	node, known := findNode(snapshot.Nodes, ev.NodeID)
+	if !known || node.Status != state.NodeStatusReady {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("%s is not connected; rule %s cannot be changed", nodeLabel, ev.Rule.Name))
+		return
+	}
+	rule, ok := ruleset.Lookup(snapshot.Rules, ev.NodeID, ev.Rule.Name)
+	if !ok {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Rule %s no longer exists on %s", ev.Rule.Name, nodeLabel))
+		return
+	}
+	toggle := ruleToggle{nodeID: ev.NodeID, node: nodeLabel, rule: rule.Name, hash: ruleset.Hash(rule), enable: enable}
+	if rule.Enabled == enable {
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("Rule %s is already %sd on %s", rule.Name, toggle.verb(), nodeLabel))
+		return
+	}
+	m.toggle = &toggle
+	question := "Disable"
+	if enable {
+		question = "Enable"
+	}
+	m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s rule %s on %s? y confirm · any other key cancels", question, rule.Name, nodeLabel))
+}
+
+// updateRuleToggle applies the pending toggle on y or enter and drops it on
+// any other key.
+func (m *Model) updateRuleToggle(key tea.KeyMsg) {
+	toggle := *m.toggle
+	m.toggle = nil
+	switch key.String() {
+	case "y", "enter":
+	default:
+		m.statusLine = m.theme.Subtle.Render(fmt.Sprintf("Left rule %s unchanged", toggle.rule))
+		return
+	}
+	var err error
+	if toggle.enable {
+		err = m.rules.EnableRule(toggle.nodeID, toggle.rule, toggle.hash)
+	} else {
+		err = m.rules.DisableRule(toggle.nodeID, toggle.rule, toggle.hash)
+	}
+	switch {
+	case errors.Is(err, controller.ErrRuleConflict):
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s: rule changed since displayed — review and retry", toggle.rule))
+	case err != nil:
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to %s %s on %s: %v", toggle.verb(), toggle.rule, toggle.node, err))
+	default:
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("Requested %s %s on %s · new events will show the effect", toggle.verb(), toggle.rule, toggle.node))
+	}
+}
+
+func findNode(nodes []state.Node, id string) (state.Node, bool) {
+	for _, node := range nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+	return state.Node{}, false
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+type fakeRuleController struct {
+	calls []string
+	err   error
+}
+
+func (f *fakeRuleController) EnableRule(nodeID, ruleName, hash string) error {
+	f.calls = append(f.calls, "enable "+ruleName+" "+nodeID)
+	return f.err
+}
+
+func (f *fakeRuleController) DisableRule(nodeID, ruleName, hash string) error {
+	f.calls = append(f.calls, "disable "+ruleName+" "+nodeID)
+	return f.err
+}
+
+func (f *fakeRuleController) DeleteRule(string, string, string) error     { return nil }
+func (f *fakeRuleController) ChangeRule(string, state.Rule, string) error { return nil }
+func (f *fakeRuleController) AddRule(string, state.Rule) error            { return nil }
+
+func ruleToggleFixture(ctrl *fakeRuleController) (*state.Store, *Model) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", Status: state.NodeStatusReady}})
+	store.SetRules("node-1", []state.Rule{{Name: "block-telemetry", Action: "deny", Enabled: true}})
+	store.MergeEvents([]state.Event{{NodeID: "node-1", UnixNano: 1,
+		Connection: state.Connection{ProcessPath: "/usr/bin/app", DstHost: "telemetry.example"},
+		Rule:       state.Rule{Name: "block-telemetry", Action: "deny"},
+	}})
+	m := New(store, theme.New(theme.Options{}), nil, ctrl).(*Model)
+	m.SetSize(200, 40)
+	return store, m
+}
+
+func press(m *Model, key string) string {
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return util.StripANSI(m.View())
+}
+
+func TestEventRuleToggleDisablesAfterConfirm(t *testing.T) {
+	ctrl := &fakeRuleController{}
+	_, m := ruleToggleFixture(ctrl)
+
+	if out := press(m, "D"); !strings.Contains(out, "Disable rule block-telemetry on alpha? y confirm") {
+		t.Fatalf("expected a confirmation, got:\n%s", out)
+	}
+	if len(ctrl.calls) != 0 {
+		t.Fatalf("expected nothing sent before confirming, got %v", ctrl.calls)
+	}
+	out := press(m, "y")
+	if len(ctrl.calls) != 1 || ctrl.calls[0] != "disable block-telemetry node-1" {
+		t.Fatalf("expected the rule disabled on its node, got %v", ctrl.calls)
+	}
+	if !strings.Contains(out, "Requested disable block-telemetry on alpha") {
+		t.Fatalf("expected the result in the status line, got:\n%s", out)
+	}
+
+	// Already enabled: nothing to confirm. Any other key cancels.
+	if out := press(m, "E"); !strings.Contains(out, "already enabled") {
+		t.Fatalf("expected the rule reported as already enabled, got:\n%s", out)
+	}
+	press(m, "D")
+	if out := press(m, "n"); !strings.Contains(out, "Left rule block-telemetry unchanged") || len(ctrl.calls) != 1 {
+		t.Fatalf("expected the toggle cancelled, got %v:\n%s", ctrl.calls, out)
+	}
+}
+
+func TestEventRuleToggleMissingRule(t *testing.T) {
+	ctrl := &fakeRuleController{}
+	store, m := ruleToggleFixture(ctrl)
+	store.RemoveRule("node-1", "block-telemetry")
+
+	if out := press(m, "D"); !strings.Contains(out, "Rule block-telemetry no longer exists on alpha") {
+		t.Fatalf("expected a missing-rule error, got:\n%s", out)
+	}
+	press(m, "y")
+	if len(ctrl.calls) != 0 {
+		t.Fatalf("expected no controller call, got %v", ctrl.calls)
+	}
+
+	store.SetRules("node-1", []state.Rule{{Name: "block-telemetry", Enabled: true}})
+	store.UpdateNodeStatus("node-1", state.NodeStatusDisconnected, "notifications closed", store.Snapshot().Nodes[0].LastSeen)
+	if out := press(m, "D"); !strings.Contains(out, "alpha is not connected; rule block-telemetry cannot be changed") {
+		t.Fatalf("expected a disconnected-node error, got:\n%s", out)
+	}
+}
+
+func TestEventRuleToggleControllerError(t *testing.T) {
+	ctrl := &fakeRuleController{err: errors.New("notification buffer full for node-1")}
+	_, m := ruleToggleFixture(ctrl)
+
+	press(m, "D")
+	if out := press(m, "y"); !strings.Contains(out, "Failed to disable block-telemetry on alpha: notification buffer full") {
+		t.Fatalf("expected the controller error, got:\n%s", out)
+	}
+}
+
+func TestEventsWithoutControllersAreReadOnly(t *testing.T) {
+	_, m := ruleToggleFixture(nil)
+	want := controller.ErrNoController.Error()
+	if out := util.StripANSI(m.View()); !strings.Contains(out, want) {
+		t.Fatalf("expected the read-only line before any key, got:\n%s", out)
+	}
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("D")}, {Type: tea.KeyRunes, Runes: []rune("E")}, {Type: tea.KeyCtrlX}} {
+		m.statusLine = ""
+		m.Update(key)
+		if got := util.StripANSI(m.statusLine); got != want || m.toggle != nil || m.wire != nil {
+			t.Fatalf("%s: expected %q and nothing opened, got %q", key, want, got)
+		}
+	}
+}
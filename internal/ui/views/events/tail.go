@@ -0,0 +1,34 @@
+package events
+
+import "github.com/adamkadaban/opensnitch-tui/internal/state"
+
+// newestRow is the display row of the most recent event. Sorted by time
+// that is the last row; other sorts can put it anywhere.
+func newestRow(events []state.Event) int {
+	if len(events) == 0 {
+		return 0
+	}
+	newest := 0
+	for i, ev := range events {
+		if ev.UnixNano > events[newest].UnixNano {
+			newest = i
+		}
+	}
+	return len(events) - 1 - newest
+}
+
+// toggleTail glues the selection to the newest event, or lets it go.
+func (m *Model) toggleTail(snapshot state.Snapshot) {
+	m.tail = !m.tail
+	if m.tail {
+		m.rowIdx = newestRow(snapshot.Events)
+	}
+}
+
+// navigate wraps a movement key: moving by hand ends tail mode.
+func navigate(f func(c *keyContext)) func(*keyContext) {
+	return func(c *keyContext) {
+		c.m.tail = false
+		f(c)
+	}
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+var tailKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}}
+
+func newTailModel(t *testing.T) (*Model, *state.Store, time.Time) {
+	t.Helper()
+	now := time.Unix(1700000000, 0)
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{
+		followEvent(now, "/usr/bin/curl", "example.com", 443, "allow"),
+		followEvent(now.Add(time.Second), "/usr/bin/dig", "dns.example", 53, "deny"),
+		followEvent(now.Add(2*time.Second), "/usr/bin/wget", "example.org", 443, "allow"),
+	})
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.SetSize(120, 30)
+	return m, store, now
+}
+
+// selected is the process path of the selected row.
+func selected(m *Model) string {
+	return eventAt(m.viewSnapshot().Events, m.rowIdx).Connection.ProcessPath
+}
+
+func TestTailFollowsArrivingEvents(t *testing.T) {
+	for _, tail := range []bool{false, true} {
+		m, store, now := newTailModel(t)
+		m.rowIdx = 0 // oldest: curl
+		if tail {
+			m.Update(tailKey)
+			if got := selected(m); got != "/usr/bin/wget" {
+				t.Fatalf("expected tail mode to snap to the newest event, got %s", got)
+			}
+		}
+		store.MergeEvents([]state.Event{followEvent(now.Add(3*time.Second), "/usr/bin/ssh", "host.example", 22, "allow")})
+		m.View()
+		want := "/usr/bin/curl"
+		if tail {
+			want = "/usr/bin/ssh"
+		}
+		if got := selected(m); got != want {
+			t.Fatalf("tail=%v: expected %s selected after an event arrived, got %s", tail, want, got)
+		}
+	}
+}
+
+func TestTailIndicatorAndNavigationBreaksIt(t *testing.T) {
+	m, store, now := newTailModel(t)
+	m.Update(tailKey)
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "FOLLOW") {
+		t.Fatalf("expected the FOLLOW indicator in tail mode:\n%s", out)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if m.tail {
+		t.Fatal("expected a movement key to end tail mode")
+	}
+	if out := util.StripANSI(m.View()); strings.Contains(out, "FOLLOW") {
+		t.Fatal("expected the indicator to go with tail mode")
+	}
+	store.MergeEvents([]state.Event{followEvent(now.Add(3*time.Second), "/usr/bin/ssh", "host.example", 22, "allow")})
+	if got := selected(m); got != "/usr/bin/dig" {
+		t.Fatalf("expected the selection to stay put once tail mode ended, got %s", got)
+	}
+
+	m.Update(tailKey)
+	if got := selected(m); got != "/usr/bin/ssh" {
+		t.Fatalf("expected re-enabling to snap to the newest event, got %s", got)
+	}
+}
+
+func TestTailSurvivesFilterAndSort(t *testing.T) {
+	m, store, now := newTailModel(t)
+	m.Update(tailKey)
+
+	// Allowed events only: the tail is the newest allowed one.
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	store.MergeEvents([]state.Event{followEvent(now.Add(3*time.Second), "/usr/bin/nc", "evil.example", 4444, "deny")})
+	if got := selected(m); got != "/usr/bin/wget" {
+		t.Fatalf("expected the newest allowed event, got %s", got)
+	}
+	store.MergeEvents([]state.Event{followEvent(now.Add(4*time.Second), "/usr/bin/apt", "deb.example", 443, "allow")})
+	if got := selected(m); got != "/usr/bin/apt" {
+		t.Fatalf("expected the arriving allowed event, got %s", got)
+	}
+
+	// Sorted by process the newest event is not the last row.
+	m.filter = ""
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if !m.tail {
+		t.Fatal("expected tail mode to survive a sort change")
+	}
+	if got := selected(m); got != "/usr/bin/apt" {
+		t.Fatalf("expected the newest event under another sort, got %s", got)
+	}
+}
+
+func TestTailComposesWithProcessFollow(t *testing.T) {
+	m, store, now := newTailModel(t)
+	m.rowIdx = 0
+	m.Update(followKey) // follow curl
+	m.Update(tailKey)
+	store.MergeEvents([]state.Event{
+		followEvent(now.Add(3*time.Second), "/usr/bin/curl", "example.net", 443, "allow"),
+		followEvent(now.Add(4*time.Second), "/usr/bin/dig", "dns.example", 53, "allow"),
+	})
+	snapshot := m.viewSnapshot()
+	if got := eventAt(snapshot.Events, m.rowIdx).Connection.DstHost; got != "example.net" {
+		t.Fatalf("expected the newest event of the followed process, got %s", got)
+	}
+}
@@ -0,0 +1,44 @@
+package nodes
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// markBaseline records the selected node's counters as its baseline, or
+// with clear set drops it.
+func (m *Model) markBaseline(snapshot state.Snapshot, clear bool) {
+	node, ok := m.selectedNode(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.controller != nil, &m.statusLine) {
+		return
+	}
+	mgr, ok := m.controller.(controller.BaselineManager)
+	if !ok {
+		m.statusLine = m.theme.Danger.Render("Counter baselines unavailable")
+		return
+	}
+	if !clear {
+		if _, err := mgr.MarkBaseline(node.ID); err != nil {
+			m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to mark a baseline on %s: %v", util.DisplayName(node), err))
+			return
+		}
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("Baseline marked on %s; the dashboard shows traffic since now", util.DisplayName(node)))
+		return
+	}
+	cleared, err := mgr.ClearBaseline(node.ID)
+	switch {
+	case err != nil:
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to clear the baseline of %s: %v", util.DisplayName(node), err))
+	case !cleared:
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s has no baseline", util.DisplayName(node)))
+	default:
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("Baseline of %s cleared", util.DisplayName(node)))
+	}
+}
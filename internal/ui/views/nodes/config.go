@@ -0,0 +1,92 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// nodeConfig returns the settings manager as a NodeConfigManager, or
+// reports on the status line that nodes cannot be saved.
+func (m *Model) nodeConfig() (controller.NodeConfigManager, bool) {
+	mgr, ok := m.settings.(controller.NodeConfigManager)
+	if !ok {
+		m.statusLine = m.theme.Danger.Render("Saving nodes to the config is unavailable")
+	}
+	return mgr, ok
+}
+
+// saveNode lists the selected node in the config file, so it is shown
+// after a restart.
+func (m *Model) saveNode(snapshot state.Snapshot) {
+	node, ok := m.selectedNode(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.settings != nil, &m.statusLine) {
+		return
+	}
+	if configured, ok := state.MatchConfigured(snapshot.Nodes, node); ok {
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s is already in the config as %s", util.DisplayName(node), util.Fallback(configured.Address, configured.ID)))
+		return
+	}
+	mgr, ok := m.nodeConfig()
+	if !ok {
+		return
+	}
+	err := mgr.AddNode(node)
+	if err != nil && !errors.Is(err, controller.ErrNotPersisted) {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to save %s: %v", util.DisplayName(node), err))
+		return
+	}
+	m.store.UpdateNode(node.ID, func(n *state.Node) { n.Configured = true })
+	if err != nil {
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s is configured for this session but not saved: %v", util.DisplayName(node), err))
+		return
+	}
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Saved %s to the config", util.DisplayName(node)))
+}
+
+// removeNode drops the selected configured node from the config file. A
+// row that only came from the config goes with it; a connected one stays
+// until it disconnects.
+func (m *Model) removeNode(snapshot state.Snapshot) {
+	node, ok := m.selectedNode(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.settings != nil, &m.statusLine) {
+		return
+	}
+	if !node.Configured {
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s is not in the config", util.DisplayName(node)))
+		return
+	}
+	mgr, ok := m.nodeConfig()
+	if !ok {
+		return
+	}
+	removed, err := mgr.RemoveNode(node.Address)
+	switch {
+	case err != nil && !errors.Is(err, controller.ErrNotPersisted):
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to remove %s: %v", util.DisplayName(node), err))
+		return
+	case !removed:
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s is not in the config", util.DisplayName(node)))
+		return
+	}
+	if node.Status == state.NodeStatusDisconnected {
+		m.store.RemoveNode(node.ID)
+	} else {
+		m.store.UpdateNode(node.ID, func(n *state.Node) { n.Configured = false })
+	}
+	if err != nil {
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s is removed for this session but the config was not saved: %v", util.DisplayName(node), err))
+		return
+	}
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Removed %s from the config", util.DisplayName(node)))
+}
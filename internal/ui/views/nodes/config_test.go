@@ -0,0 +1,67 @@
+package nodes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// fakeNodeConfig records the nodes saved to and removed from the config.
+type fakeNodeConfig struct {
+	controller.SettingsManager
+	added   []state.Node
+	removed []string
+}
+
+func (f *fakeNodeConfig) AddNode(node state.Node) error {
+	f.added = append(f.added, node)
+	return nil
+}
+
+func (f *fakeNodeConfig) RemoveNode(address string) (bool, error) {
+	f.removed = append(f.removed, address)
+	return true, nil
+}
+
+func TestSaveAndRemoveNodeInConfig(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{
+		{ID: "node-1", Name: "alpha", Address: "10.0.0.1:50051", Status: state.NodeStatusDisconnected, Configured: true},
+		{ID: "tcp://10.0.0.2:41000", Name: "beta", Address: "10.0.0.2:41000", Status: state.NodeStatusReady},
+	})
+	cfg := &fakeNodeConfig{}
+	m := New(store, theme.New(theme.Options{}), nil, cfg).(*Model)
+	m.SetSize(160, 12)
+
+	pressKey(m, "s")
+	if len(cfg.added) != 0 || !strings.Contains(util.StripANSI(m.View()), "alpha is already in the config") {
+		t.Fatalf("expected a configured node not saved again, got %+v", cfg.added)
+	}
+
+	pressKey(m, "down")
+	pressKey(m, "s")
+	if len(cfg.added) != 1 || cfg.added[0].ID != "tcp://10.0.0.2:41000" {
+		t.Fatalf("expected beta saved, got %+v", cfg.added)
+	}
+	if node, _ := m.selectedNode(store.Snapshot()); !node.Configured {
+		t.Fatalf("expected beta marked configured")
+	}
+
+	pressKey(m, "x")
+	if node, _ := m.selectedNode(store.Snapshot()); node.Configured || len(store.Snapshot().Nodes) != 2 {
+		t.Fatalf("expected the connected node kept but no longer configured, got %+v", store.Snapshot().Nodes)
+	}
+
+	pressKey(m, "k")
+	pressKey(m, "x")
+	if len(cfg.removed) != 2 || cfg.removed[1] != "10.0.0.1:50051" {
+		t.Fatalf("unexpected removals %v", cfg.removed)
+	}
+	if nodes := store.Snapshot().Nodes; len(nodes) != 1 || nodes[0].Name != "beta" {
+		t.Fatalf("expected the disconnected configured row dropped, got %+v", nodes)
+	}
+}
@@ -0,0 +1,42 @@
+package nodes
+
+import (
+	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// keyContext is what the nodes actions work on.
+type keyContext struct {
+	m        *Model
+	snapshot state.Snapshot
+}
+
+// action is one entry of the nodes key table.
+type action = keymap.Action[keyContext]
+
+var keyTable = keymap.NewTable(
+	action{ID: "nodes.pause", Keys: []string{"t"}, Help: "pause or resume interception", Run: keymap.Do(func(c keyContext) { c.m.togglePause(c.snapshot) })},
+	action{ID: "nodes.policy", Keys: []string{"p"}, Help: "toggle the default action", Run: keymap.Do(func(c keyContext) { c.m.togglePolicy(c.snapshot) })},
+	action{ID: "nodes.maintenance", Keys: []string{"m"}, Help: "start or end maintenance", Run: keymap.Do(func(c keyContext) { c.m.toggleMaintenance(c.snapshot) })},
+	action{ID: "nodes.baseline", Keys: []string{"b"}, Help: "mark a counter baseline", Run: keymap.Do(func(c keyContext) { c.m.markBaseline(c.snapshot, false) })},
+	action{ID: "nodes.clear-baseline", Keys: []string{"B"}, Help: "clear the counter baseline", Run: keymap.Do(func(c keyContext) { c.m.markBaseline(c.snapshot, true) })},
+	action{ID: "nodes.save", Keys: []string{"s"}, Help: "save the node to the config", Run: keymap.Do(func(c keyContext) { c.m.saveNode(c.snapshot) })},
+	action{ID: "nodes.remove", Keys: []string{"x"}, Help: "remove the node from the config", Run: keymap.Do(func(c keyContext) { c.m.removeNode(c.snapshot) })},
+	action{ID: "nodes.trace-record", Keys: []string{"T"}, Help: "start or stop recording the protocol trace", Run: keymap.Do(func(c keyContext) { c.m.toggleTrace() })},
+	action{ID: "nodes.trace", Keys: []string{"w"}, Help: "show the node's protocol trace", Run: keymap.Do(func(c keyContext) { c.m.openTrace(c.snapshot) })},
+	action{ID: "nodes.scroll-left", Keys: []string{"left", "h"}, Help: "scroll left", Run: keymap.Do(func(c keyContext) { c.m.adjustTableX(-4) })},
+	action{ID: "nodes.scroll-right", Keys: []string{"right", "l"}, Help: "scroll right", Run: keymap.Do(func(c keyContext) { c.m.adjustTableX(4) })},
+	action{ID: "nodes.up", Keys: []string{"up", "k"}, Help: "previous node", Run: keymap.Do(func(c keyContext) {
+		if c.m.rowIdx > 0 {
+			c.m.rowIdx--
+		}
+	})},
+	action{ID: "nodes.down", Keys: []string{"down", "j"}, Help: "next node", Run: keymap.Do(func(c keyContext) {
+		if c.m.rowIdx < len(c.snapshot.Nodes)-1 {
+			c.m.rowIdx++
+		}
+	})},
+)
+
+// KeyHelp lists the nodes keys for the help overlay.
+func (m *Model) KeyHelp() []keymap.Help { return keyTable.Help() }
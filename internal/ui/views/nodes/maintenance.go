@@ -0,0 +1,68 @@
+package nodes
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// maintenancePresets lists the maintenance picker choices; a zero duration
+// is the custom entry.
+var maintenancePresets = []pausePreset{
+	{label: "15m", duration: 15 * time.Minute},
+	{label: "30m", duration: 30 * time.Minute},
+	{label: "1h", duration: time.Hour},
+	{label: "2h", duration: 2 * time.Hour},
+	{label: "custom"},
+}
+
+// maintenance returns the controller's maintenance support, which only the
+// daemon server has.
+func (m *Model) maintenance() (controller.MaintenanceManager, bool) {
+	mgr, ok := m.controller.(controller.MaintenanceManager)
+	return mgr, ok
+}
+
+// toggleMaintenance opens the duration picker for the selected node, or ends
+// its maintenance window early.
+func (m *Model) toggleMaintenance(snapshot state.Snapshot) {
+	node, ok := m.selectedNode(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.controller != nil, &m.statusLine) {
+		return
+	}
+	mgr, ok := m.maintenance()
+	if !ok {
+		m.statusLine = m.theme.Danger.Render("Maintenance mode unavailable")
+		return
+	}
+	if _, active := snapshot.MaintenanceFor(node.ID, m.now()); active {
+		if err := mgr.EndMaintenance(node.ID); err != nil {
+			m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to end maintenance on %s: %v", util.DisplayName(node), err))
+			return
+		}
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("Maintenance ended on %s; see Alerts for the summary", util.DisplayName(node)))
+		return
+	}
+	m.openPicker(node.ID, true)
+}
+
+func (m *Model) startMaintenance(snapshot state.Snapshot, d time.Duration) tea.Cmd {
+	label := m.pickedNodeLabel(snapshot)
+	mgr, _ := m.maintenance()
+	if err := mgr.StartMaintenance(m.pauseNodeID, d); err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to start maintenance on %s: %v", label, err))
+		return nil
+	}
+	action := snapshot.Settings.MaintenanceAction
+	m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s in maintenance for %s: prompts answered %s, alerts suppressed", label, formatCountdown(d), action))
+	return m.startTicking()
+}
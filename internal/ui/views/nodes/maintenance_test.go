@@ -0,0 +1,81 @@
+package nodes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// fakeMaintenance mimics the daemon server's maintenance windows.
+type fakeMaintenance struct {
+	*fakeFirewall
+	ended []string
+}
+
+func (f *fakeMaintenance) StartMaintenance(nodeID string, d time.Duration) error {
+	f.store.StartMaintenance(nodeID, "allow", f.now, f.now.Add(d))
+	return nil
+}
+
+func (f *fakeMaintenance) EndMaintenance(nodeID string) error {
+	f.ended = append(f.ended, nodeID)
+	f.store.EndMaintenance(nodeID)
+	return nil
+}
+
+func newMaintenanceModel(t *testing.T) (*Model, *fakeMaintenance) {
+	t.Helper()
+	m, fw := newPauseModel(t)
+	mm := &fakeMaintenance{fakeFirewall: fw}
+	m.controller = mm
+	return m, mm
+}
+
+func TestNodesMaintenanceShowsWrenchCountdown(t *testing.T) {
+	m, fake := newMaintenanceModel(t)
+	pressKey(m, "m")
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "Maintenance for: [15m]") {
+		t.Fatalf("expected maintenance picker, got:\n%s", out)
+	}
+	pressKey(m, "right")
+	pressKey(m, "right")
+	if cmd := pressKey(m, "enter"); cmd == nil {
+		t.Fatalf("expected a countdown tick to be scheduled")
+	}
+	window, ok := m.store.Snapshot().MaintenanceFor("tcp://10.0.0.1:50051", fake.now)
+	if !ok || window.Until.Sub(window.Since) != time.Hour {
+		t.Fatalf("expected an hour of maintenance on the selected node, got %+v", window)
+	}
+
+	m.now = func() time.Time { return fake.now.Add(20 * time.Minute) }
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "🔧 40:00 left") || strings.Count(out, "🔧") != 1 {
+		t.Fatalf("expected one wrench badge, got:\n%s", out)
+	}
+	if _, cmd := m.Update(pauseTickMsg{gen: m.tickGen}); cmd == nil {
+		t.Fatalf("expected ticks to continue during maintenance")
+	}
+
+	pressKey(m, "m")
+	if len(fake.ended) != 1 || m.picking {
+		t.Fatalf("expected a repeat press to end maintenance, got %v", fake.ended)
+	}
+	if out := util.StripANSI(m.View()); strings.Contains(out, "🔧") || !strings.Contains(out, "Maintenance ended") {
+		t.Fatalf("expected the badge gone and a notice, got:\n%s", out)
+	}
+}
+
+func TestNodesMaintenanceUnavailable(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(1))
+	m := New(store, theme.New(theme.Options{}), &fakeFirewall{store: store}, nil).(*Model)
+	m.SetSize(160, 12)
+	pressKey(m, "m")
+	if out := util.StripANSI(m.View()); m.picking || !strings.Contains(out, "Maintenance mode unavailable") {
+		t.Fatalf("expected maintenance to be unavailable, got:\n%s", out)
+	}
+}
@@ -6,41 +6,175 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/skew"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/components/table"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
 // Model renders configured daemon nodes and their connection status.
 type Model struct {
-	store  *state.Store
-	theme  theme.Theme
+	store      *state.Store
+	theme      theme.Theme
+	controller controller.FirewallManager
+	// settings saves nodes to the config file when it supports it.
+	settings controller.SettingsManager
+	now      func() time.Time
+
 	width  int
 	height int
+
+	rowIdx        int
+	tableOffset   int
+	tableXOffset  int
+	tableMaxWidth int
+
+	statusLine string
+	picking    bool
+	// pickMaintenance makes the picker start a maintenance window rather
+	// than pause the firewall.
+	pickMaintenance bool
+	pauseIdx        int
+	pauseNodeID     string
+	pauseInput      textinput.Model
+	tickGen         int
+
+	// trace is the open protocol trace of the node traceNodeID, if any.
+	trace       *widget.Pager
+	traceNodeID string
+}
+
+const (
+	defaultTableRows = 5
+	minTableRows     = 3
+	tableChrome      = 5
+	columnGap        = 1
+	minCursorWidth   = 2
+	minIndexWidth    = 3
+	minNameWidth     = 12
+	minAddressWidth  = 16
+	minStatusWidth   = 12
+	minVersionWidth  = 8
+	minRulesWidth    = 5
+	minLastSeenWidth = 10
+	minMessageWidth  = 14
+)
+
+type tableLayout struct {
+	cursor   int
+	index    int
+	name     int
+	address  int
+	status   int
+	version  int
+	rules    int
+	lastSeen int
+	message  int
+}
+
+func (tl tableLayout) total() int {
+	return tl.cursor + tl.index + tl.name + tl.address + tl.status + tl.version + tl.rules + tl.lastSeen + tl.message
 }
 
+func (tl tableLayout) count() int { return 9 }
+
 // New constructs the nodes view.
-func New(store *state.Store, th theme.Theme) view.Model {
-	return &Model{store: store, theme: th}
+func New(store *state.Store, th theme.Theme, ctrl controller.FirewallManager, settings controller.SettingsManager) view.Model {
+	if !controller.Attached(ctrl) {
+		ctrl = nil
+	}
+	if !controller.Attached(settings) {
+		settings = nil
+	}
+	return &Model{store: store, theme: th, controller: ctrl, settings: settings, now: time.Now}
 }
 
 func (m *Model) Init() tea.Cmd { return nil }
 
-func (m *Model) Update(_ tea.Msg) (tea.Model, tea.Cmd) { return m, nil }
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	snapshot := m.store.Snapshot()
+	m.clampSelection(snapshot)
+
+	var cmd tea.Cmd
+	switch key := msg.(type) {
+	case pauseTickMsg:
+		if key.gen == m.tickGen && anyCountdown(snapshot, m.now()) {
+			return m, pauseTick(key.gen)
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if m.picking {
+			return m, m.updatePicker(key, snapshot)
+		}
+		if m.trace != nil {
+			m.updateTrace(key, snapshot)
+			return m, nil
+		}
+		if anyCountdown(snapshot, m.now()) {
+			// Ticks only reach the active view; restart the chain when
+			// the user comes back.
+			cmd = m.startTicking()
+		}
+		if run, _ := keyTable.Dispatch(key.String(), keyContext{m: m, snapshot: snapshot}); run != nil {
+			cmd = tea.Batch(cmd, run)
+		}
+		m.clampSelection(snapshot)
+	}
+
+	return m, cmd
+}
+
+func (m *Model) selectedNode(snapshot state.Snapshot) (state.Node, bool) {
+	nodes := sortedNodes(snapshot.Nodes)
+	if len(nodes) == 0 {
+		return state.Node{}, false
+	}
+	return nodes[min(m.rowIdx, len(nodes)-1)], true
+}
 
 func (m *Model) View() string {
 	snapshot := m.store.Snapshot()
+	m.clampSelection(snapshot)
 
+	if m.trace != nil {
+		return m.wrap(lipgloss.JoinVertical(lipgloss.Left, m.trace.View(m.theme, m.traceHeight()), m.renderStatus()))
+	}
 	if len(snapshot.Nodes) == 0 {
 		msg := m.theme.Subtle.Render("No nodes configured. Add entries under nodes[] in config.yaml.")
-		return m.theme.Body.Width(max(1, m.width)).Height(max(3, m.height)).Render(msg)
+		return m.wrap(msg)
 	}
 
-	nodes := append([]state.Node(nil), snapshot.Nodes...)
+	nodes := sortedNodes(snapshot.Nodes)
+	body := lipgloss.JoinVertical(lipgloss.Left, m.renderNodesTable(nodes, snapshot), m.renderStatus())
+	return m.wrap(body)
+}
+
+func (m *Model) Title() string { return "Nodes" }
+
+// EnteringText reports whether the duration picker is open.
+func (m *Model) EnteringText() bool { return m.picking }
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m *Model) SetTheme(th theme.Theme) {
+	m.theme = th
+}
+
+// sortedNodes orders named nodes before unnamed ones, then by display name.
+func sortedNodes(in []state.Node) []state.Node {
+	nodes := append([]state.Node(nil), in...)
 	sort.SliceStable(nodes, func(i, j int) bool {
 		ni, nj := nodes[i], nodes[j]
 		nameI, nameJ := ni.Name != "", nj.Name != ""
@@ -54,34 +188,109 @@ func (m *Model) View() string {
 		}
 		return di < dj
 	})
+	return nodes
+}
 
-	rows := make([]string, 0, len(nodes))
-	for idx, node := range nodes {
-		label := fmt.Sprintf("%02d · %s", idx+1, labelForNode(node))
-		status := m.statusStyle(node.Status).Render(strings.ToUpper(string(node.Status)))
-		meta := nodeDetails(node)
+func (m *Model) renderNodesTable(nodes []state.Node, snapshot state.Snapshot) string {
+	layout := m.tableColumns()
+	start := min(m.tableOffset, max(0, len(nodes)-1))
+	capacity := m.tableCapacity()
+	if start > len(nodes)-capacity {
+		start = max(0, len(nodes)-capacity)
+	}
+	end := min(len(nodes), start+capacity)
+	moreBelow := end < len(nodes)
+	gap := strings.Repeat(" ", columnGap)
 
-		row := lipgloss.JoinHorizontal(lipgloss.Top,
-			m.theme.Title.Width(max(20, m.width/3)).Render(label),
-			m.theme.Subtle.Width(max(14, m.width/6)).Render(status),
-			m.theme.Body.Width(max(20, m.width/3)).Render(meta),
-		)
-		rows = append(rows, row)
+	rows := make([]string, 0, (end-start)+1)
+	rows = append(rows, m.renderTableHeader(layout, gap))
+	for idx := start; idx < end; idx++ {
+		node := nodes[idx]
+		message := formatMessage(node, state.PromptModeFor(nodes, node.ID), snapshot.Maintenance[node.ID], m.now())
+		rows = append(rows, m.renderNodeRow(layout, node, message, len(snapshot.Rules[node.ID]), idx, idx == m.rowIdx, gap))
+	}
+	if moreBelow {
+		tableWidth := layout.total() + columnGap*(layout.count()-1)
+		rows = append(rows, table.RenderCaretRow(tableWidth, m.theme.Subtle))
 	}
 
-	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
-	return m.theme.Body.Width(max(1, m.width)).Height(max(3, m.height)).Render(content)
+	m.tableMaxWidth = table.ComputeMaxWidth(rows)
+	visibleWidth := max(1, m.contentWidth())
+	clipped := table.ClipRows(rows, m.tableXOffset, visibleWidth)
+	return lipgloss.JoinVertical(lipgloss.Left, clipped...)
 }
 
-func (m *Model) Title() string { return "Nodes" }
+func (m *Model) renderTableHeader(layout tableLayout, gap string) string {
+	headerStyle := m.theme.Header.Bold(true).Padding(0)
+	labels := []string{"", "#", "NAME", "ADDRESS", "STATUS", "VERSION", "RULES", "LAST SEEN", "MESSAGE"}
+	widths := []int{layout.cursor, layout.index, layout.name, layout.address, layout.status, layout.version, layout.rules, layout.lastSeen, layout.message}
+	cells := make([]string, len(labels))
+	for i := range labels {
+		cells[i] = table.PadAndStyle(headerStyle, labels[i], widths[i], true)
+	}
+	return strings.Join(cells, gap)
+}
 
-func (m *Model) SetSize(width, height int) {
-	m.width = width
-	m.height = height
+func (m *Model) renderNodeRow(layout tableLayout, node state.Node, message string, ruleCount, rowIdx int, selected bool, gap string) string {
+	bg := m.rowStripeColor(rowIdx)
+	if selected {
+		bg = m.selectedRowColor()
+	}
+	// The marker carries the node's accent, the same as in the other views.
+	cursor := glyphs.Current().Dot
+	if selected {
+		cursor = ">"
+	}
+
+	bodyStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
+	nameStyle := stripBackground(m.theme.Title).Background(bg).Padding(0)
+	subtleStyle := stripBackground(m.theme.Subtle).Background(bg).Padding(0)
+	statusStyle := stripBackground(m.statusStyle(node.Status)).Background(bg).Padding(0)
+
+	columns := []string{
+		table.PadAndStyle(bodyStyle.Foreground(m.theme.Accent(state.NodeKey(node))).Bold(true), cursor, layout.cursor, true),
+		table.PadAndStyle(subtleStyle, fmt.Sprintf("%02d", rowIdx+1), layout.index, true),
+		table.PadAndStyle(nameStyle, util.Fallback(node.Name, "-"), layout.name, true),
+		table.PadAndStyle(bodyStyle, util.Fallback(node.Address, "-"), layout.address, true),
+		table.PadAndStyle(statusStyle, strings.ToUpper(string(node.Status)), layout.status, true),
+		table.PadAndStyle(bodyStyle, formatVersion(node.Version), layout.version, true),
+		table.PadAndStyle(bodyStyle, fmt.Sprintf("%d", ruleCount), layout.rules, true),
+		table.PadAndStyle(subtleStyle, formatLastSeen(node.LastSeen), layout.lastSeen, true),
+		table.PadAndStyle(bodyStyle, message, layout.message, true),
+	}
+
+	gapStyle := lipgloss.NewStyle().Background(bg)
+	rowGap := gapStyle.Render(gap)
+	return strings.Join(columns, rowGap)
 }
 
-func (m *Model) SetTheme(th theme.Theme) {
-	m.theme = th
+func (m *Model) renderStatus() string {
+	help := "←/→ scroll · ↑/↓ nodes · t pause firewall · p policy-only · m maintenance · b/B mark/clear baseline · s/x save/remove in config · T record trace · w protocol trace"
+	lines := []string{}
+	if m.trace != nil {
+		help = traceHelp
+	}
+	if m.picking {
+		help = "←/→ or n/p choose · enter pause · esc cancel"
+		if m.pickMaintenance {
+			help = "←/→ or n/p choose · enter start maintenance · esc cancel"
+		}
+		lines = append(lines, m.renderPicker())
+	}
+	var disabled []string
+	if m.controller == nil {
+		disabled = append(disabled, "t pause", "m maintenance", "b/B", "T record", "w protocol")
+	}
+	if m.settings == nil {
+		disabled = append(disabled, "s/x")
+	}
+	switch {
+	case m.statusLine != "":
+		lines = append(lines, m.statusLine)
+	case len(disabled) > 0:
+		lines = append(lines, widget.RenderReadOnly(m.theme))
+	}
+	return strings.Join(append(lines, widget.RenderHelp(m.theme, help, disabled)), "\n")
 }
 
 func (m *Model) statusStyle(status state.NodeStatus) lipgloss.Style {
@@ -97,19 +306,48 @@ func (m *Model) statusStyle(status state.NodeStatus) lipgloss.Style {
 	}
 }
 
-func nodeDetails(node state.Node) string {
+func formatVersion(version string) string {
+	if version == "" {
+		return "-"
+	}
+	return "v" + version
+}
+
+func formatLastSeen(ts time.Time) string {
+	if ts.IsZero() {
+		return "-"
+	}
+	return util.RelativeTime(ts)
+}
+
+func formatMessage(node state.Node, prompts state.PromptMode, maintenance state.Maintenance, now time.Time) string {
 	parts := []string{}
-	if node.Version != "" {
-		parts = append(parts, fmt.Sprintf("v%s", node.Version))
+	if maintenance.Active(now) {
+		parts = append(parts, "🔧 "+formatCountdown(maintenance.Until.Sub(now))+" left")
+	}
+	if prompts == state.PromptsPolicy {
+		parts = append(parts, "policy-only")
+	}
+	if left, ok := pausedUntil(node, now); ok {
+		parts = append(parts, "re-enabling in "+formatCountdown(left))
 	}
 	if node.Message != "" {
 		parts = append(parts, node.Message)
 	}
-	if !node.LastSeen.IsZero() {
-		parts = append(parts, fmt.Sprintf("seen %s ago", time.Since(node.LastSeen).Truncate(time.Second)))
-	}
 	if node.FirewallEnabled {
 		parts = append(parts, "firewall: on")
+	} else if node.FirewallKnown {
+		parts = append(parts, "firewall: off")
+	}
+	if node.ClockSkewKnown && skew.Significant(node.ClockSkew) {
+		parts = append(parts, "clock skew ≈ "+skew.Format(node.ClockSkew))
+	}
+	if node.AckCount > 0 {
+		ack := "ack avg " + util.HumanizeLatency(node.AckAverage)
+		if node.SlowAcks > 0 {
+			ack += fmt.Sprintf(" (%d slow)", node.SlowAcks)
+		}
+		parts = append(parts, ack)
 	}
 	if len(parts) == 0 {
 		return "awaiting connection"
@@ -117,12 +355,156 @@ func nodeDetails(node state.Node) string {
 	return strings.Join(parts, " · ")
 }
 
-func labelForNode(node state.Node) string {
-	if node.Name != "" {
-		if node.Address != "" {
-			return fmt.Sprintf("%s (%s)", node.Name, node.Address)
+func (m *Model) wrap(body string) string {
+	return m.theme.Body.Width(max(1, m.width)).Height(max(3, m.height)).Render(body)
+}
+
+func (m *Model) tableCapacity() int {
+	if m.height <= 0 {
+		return defaultTableRows
+	}
+	return max(minTableRows, m.height-tableChrome)
+}
+
+func (m *Model) tableColumns() tableLayout {
+	layout := tableLayout{
+		cursor:   minCursorWidth,
+		index:    minIndexWidth,
+		name:     minNameWidth,
+		address:  minAddressWidth,
+		status:   minStatusWidth,
+		version:  minVersionWidth,
+		rules:    minRulesWidth,
+		lastSeen: minLastSeenWidth,
+		message:  minMessageWidth,
+	}
+	inner := max(40, m.contentWidth())
+	gapWidth := columnGap * (layout.count() - 1)
+	usable := inner - gapWidth
+	if usable <= 0 {
+		usable = layout.total()
+	}
+	base := layout.total()
+	if usable < base {
+		deficit := base - usable
+		reducers := []struct {
+			field *int
+			min   int
+		}{
+			{&layout.message, 6},
+			{&layout.address, 8},
+			{&layout.name, 8},
+			{&layout.lastSeen, 6},
+			{&layout.version, 4},
+			{&layout.status, 6},
+		}
+		for deficit > 0 {
+			progressed := false
+			for i := range reducers {
+				if deficit == 0 {
+					break
+				}
+				curr := *reducers[i].field
+				if curr <= reducers[i].min {
+					continue
+				}
+				d := min(deficit, curr-reducers[i].min)
+				*reducers[i].field -= d
+				deficit -= d
+				progressed = true
+			}
+			if !progressed {
+				break
+			}
 		}
-		return node.Name
+	} else if usable > base {
+		extra := usable - base
+		expanders := []*int{&layout.name, &layout.message, &layout.address}
+		for extra > 0 {
+			for _, field := range expanders {
+				if extra == 0 {
+					break
+				}
+				(*field)++
+				extra--
+			}
+		}
+	}
+	layout.cursor = max(1, layout.cursor)
+	layout.index = max(2, layout.index)
+	layout.name = max(6, layout.name)
+	layout.address = max(6, layout.address)
+	layout.status = max(4, layout.status)
+	layout.version = max(4, layout.version)
+	layout.rules = max(3, layout.rules)
+	layout.lastSeen = max(4, layout.lastSeen)
+	layout.message = max(4, layout.message)
+	return layout
+}
+
+func (m *Model) clampSelection(snapshot state.Snapshot) {
+	nodes := snapshot.Nodes
+	if len(nodes) == 0 {
+		m.rowIdx = 0
+		m.tableOffset = 0
+		return
+	}
+	if m.rowIdx >= len(nodes) {
+		m.rowIdx = len(nodes) - 1
+	}
+	capacity := m.tableCapacity()
+	if len(nodes) <= capacity {
+		m.tableOffset = 0
+		return
+	}
+	if m.rowIdx < m.tableOffset {
+		m.tableOffset = m.rowIdx
+	}
+	if m.rowIdx >= m.tableOffset+capacity {
+		m.tableOffset = m.rowIdx - capacity + 1
+	}
+}
+
+func (m *Model) adjustTableX(delta int) {
+	if delta == 0 {
+		return
+	}
+	maxOffset := 0
+	visible := m.contentWidth()
+	if m.tableMaxWidth > visible {
+		maxOffset = m.tableMaxWidth - visible
 	}
-	return node.Address
+	newOffset := m.tableXOffset + delta
+	if newOffset < 0 {
+		newOffset = 0
+	}
+	if newOffset > maxOffset {
+		newOffset = maxOffset
+	}
+	m.tableXOffset = newOffset
+}
+
+func (m *Model) contentWidth() int {
+	if m.width <= 0 {
+		return 80
+	}
+	if m.width <= 4 {
+		return m.width
+	}
+	return m.width - 4
+}
+
+func stripBackground(style lipgloss.Style) lipgloss.Style {
+	return style.UnsetBackground()
+}
+
+func (m *Model) rowStripeColor(rowIdx int) lipgloss.Color {
+	if rowIdx%2 == 0 {
+		return m.theme.TableRowEven
+	}
+	return m.theme.TableRowOdd
+}
+
+func (m *Model) selectedRowColor() lipgloss.Color {
+	return m.theme.TableRowSelect
 }
@@ -1,19 +1,25 @@
 package nodes
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view/viewtest"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
 )
 
 func TestNodesViewEmptySnapshot(t *testing.T) {
 	store := state.NewStore()
 	th := theme.New(theme.Options{})
-	m := New(store, th)
+	m := New(store, th, nil, nil)
 	m.SetSize(90, 12)
 
 	viewtest.AssertSnapshot(t, m.View(), filepath.Join("testdata", "nodes_empty.snap"))
@@ -42,8 +48,180 @@ func TestNodesViewPopulatedSnapshot(t *testing.T) {
 	})
 
 	th := theme.New(theme.Options{})
-	m := New(store, th)
+	m := New(store, th, nil, nil)
 	m.SetSize(90, 14)
 
 	viewtest.AssertSnapshot(t, m.View(), filepath.Join("testdata", "nodes_populated.snap"))
 }
+
+func makeTestNodes(count int) []state.Node {
+	nodes := make([]state.Node, count)
+	for i := range nodes {
+		nodes[i] = state.Node{
+			ID:      fmt.Sprintf("tcp://10.0.0.%d:50051", i+1),
+			Name:    fmt.Sprintf("node-%02d", i),
+			Address: fmt.Sprintf("10.0.0.%d:50051", i+1),
+			Status:  state.NodeStatusReady,
+		}
+	}
+	return nodes
+}
+
+func TestNodesTableSelectionAndWindowing(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(10))
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.SetSize(120, 9)
+
+	out := m.View()
+	if !strings.Contains(out, "node-00") || strings.Contains(out, "node-06") {
+		t.Fatalf("expected first window of nodes, got %q", out)
+	}
+	for i := 0; i < 7; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	out = m.View()
+	if strings.Contains(out, "node-00") || !strings.Contains(out, ">  08") {
+		t.Fatalf("expected selection on node-07 to be visible, got %q", out)
+	}
+
+	store.SetNodes(makeTestNodes(3))
+	m.View()
+	if m.rowIdx != 2 || m.tableOffset != 0 {
+		t.Fatalf("expected selection clamped to last node, got row %d offset %d", m.rowIdx, m.tableOffset)
+	}
+	store.SetNodes(nil)
+	m.View()
+	if m.rowIdx != 0 {
+		t.Fatalf("expected selection reset when nodes vanish, got %d", m.rowIdx)
+	}
+}
+
+func TestNodesTableLongNamesStayAligned(t *testing.T) {
+	store := state.NewStore()
+	nodes := makeTestNodes(2)
+	nodes[0].Name = strings.Repeat("very-long-node-name-", 5)
+	store.SetNodes(nodes)
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.SetSize(90, 12)
+
+	lines := strings.Split(m.View(), "\n")
+	row := 0
+	for row < len(lines) && !strings.Contains(lines[row], "ADDRESS") {
+		row++
+	}
+	if row+2 >= len(lines) {
+		t.Fatalf("expected header and two rows, got %q", lines)
+	}
+	// Columns are compared in cells; the row markers are wider in bytes.
+	column := func(line, sub string) int { return lipgloss.Width(line[:strings.Index(line, sub)]) }
+	header := column(lines[row], "ADDRESS")
+	for _, line := range lines[row+1 : row+3] {
+		if idx := column(line, "10.0.0."); idx != header {
+			t.Fatalf("expected address column at %d, got %d in %q", header, idx, line)
+		}
+	}
+}
+
+func TestNodesTableColumnsReduceToFit(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), nil, nil).(*Model)
+	for _, width := range []int{60, 80, 100, 140} {
+		m.SetSize(width, 10)
+		layout := m.tableColumns()
+		total := layout.total() + columnGap*(layout.count()-1)
+		if width >= 100 && total != width-4 {
+			t.Fatalf("width %d: expected layout to fill %d, got %d", width, width-4, total)
+		}
+		if layout.name < 6 || layout.address < 6 || layout.message < 4 {
+			t.Fatalf("width %d: columns shrank below minimums: %+v", width, layout)
+		}
+	}
+}
+
+func TestNodesTableHorizontalScroll(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(2))
+	m := New(store, theme.New(theme.Options{}), nil, nil).(*Model)
+	m.SetSize(30, 10)
+	m.View()
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if m.tableXOffset != 4 {
+		t.Fatalf("expected horizontal offset 4, got %d", m.tableXOffset)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if m.tableXOffset != 0 {
+		t.Fatalf("expected offset clamped at 0, got %d", m.tableXOffset)
+	}
+}
+
+func TestNodesTableShowsClockSkew(t *testing.T) {
+	store := state.NewStore()
+	nodes := makeTestNodes(2)
+	nodes[0].ClockSkew, nodes[0].ClockSkewKnown = 42*time.Second, true
+	nodes[1].ClockSkew, nodes[1].ClockSkewKnown = 500*time.Millisecond, true
+	store.SetNodes(nodes)
+
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(200, 12)
+	out := util.StripANSI(m.View())
+	if strings.Count(out, "clock skew") != 1 || !strings.Contains(out, "clock skew ≈ +42s") {
+		t.Fatalf("expected skew only on the drifting node, got:\n%s", out)
+	}
+}
+
+func TestNodesTableShowsReportedFirewallState(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(3))
+	ids := []string{store.Snapshot().Nodes[0].ID, store.Snapshot().Nodes[1].ID}
+	store.UpsertNode(state.Node{ID: ids[0], FirewallEnabled: true, FirewallKnown: true})
+	store.UpsertNode(state.Node{ID: ids[1], FirewallKnown: true})
+
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(200, 12)
+	out := util.StripANSI(m.View())
+	if strings.Count(out, "firewall: on") != 1 || strings.Count(out, "firewall: off") != 1 {
+		t.Fatalf("expected on and off for the reporting nodes only, got:\n%s", out)
+	}
+
+	store.UpsertNode(state.Node{ID: ids[0], FirewallKnown: true})
+	if out := util.StripANSI(m.View()); strings.Contains(out, "firewall: on") || strings.Count(out, "firewall: off") != 2 {
+		t.Fatalf("expected the disabled firewall shown at once, got:\n%s", out)
+	}
+}
+
+func TestNodesTableShowsAckAverage(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(2))
+	id := store.Snapshot().Nodes[0].ID
+	store.RecordAck(state.ActionAck{NodeID: id, Action: "enable", Latency: 80 * time.Millisecond})
+	store.RecordAck(state.ActionAck{NodeID: id, Action: "disable", Latency: 4 * time.Second, Slow: true})
+	store.RecordAck(state.ActionAck{NodeID: id, Action: "delete", Err: "no such rule"})
+
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(200, 12)
+	out := util.StripANSI(m.View())
+	if strings.Count(out, "ack avg") != 1 || !strings.Contains(out, "ack avg 2s (1 slow)") {
+		t.Fatalf("expected the rolling ack average on the first node only, got:\n%s", out)
+	}
+}
+
+func TestNodesPolicyToggle(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(1))
+	id := store.Snapshot().Nodes[0].ID
+
+	m := New(store, theme.New(theme.Options{}), nil, nil)
+	m.SetSize(200, 12)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if got := state.PromptModeFor(store.Snapshot().Nodes, id); got != state.PromptsPolicy {
+		t.Fatalf("expected policy-only after p, got %q", got)
+	}
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "policy-only") {
+		t.Fatalf("expected the policy-only badge, got:\n%s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if got := state.PromptModeFor(store.Snapshot().Nodes, id); got != state.PromptsInteractive {
+		t.Fatalf("expected interactive after a second p, got %q", got)
+	}
+}
@@ -0,0 +1,222 @@
+package nodes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// maxPause keeps a forgotten custom pause from outliving the working day.
+const maxPause = 24 * time.Hour
+
+type pausePreset struct {
+	label    string
+	duration time.Duration
+}
+
+// pausePresets lists the picker choices; a zero duration is the custom entry.
+var pausePresets = []pausePreset{
+	{label: "5m", duration: 5 * time.Minute},
+	{label: "10m", duration: 10 * time.Minute},
+	{label: "30m", duration: 30 * time.Minute},
+	{label: "custom"},
+}
+
+// pauseTickMsg redraws pause countdowns; gen drops ticks from older chains.
+type pauseTickMsg struct{ gen int }
+
+// parsePauseDuration accepts whole minutes ("15") or a Go duration ("1h30m").
+func parsePauseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("enter minutes or a duration such as 1h30m")
+	}
+	d, err := time.ParseDuration(value)
+	if n, convErr := strconv.Atoi(value); convErr == nil {
+		d, err = time.Duration(n)*time.Minute, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	if d <= 0 || d > maxPause {
+		return 0, fmt.Errorf("pause must be between 1s and %s", maxPause)
+	}
+	return d, nil
+}
+
+// formatCountdown renders the time left as m:ss, or h:mm:ss past an hour.
+func formatCountdown(d time.Duration) string {
+	d = max(0, d.Round(time.Second))
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+func pausedUntil(node state.Node, now time.Time) (time.Duration, bool) {
+	if node.FirewallResumeAt.IsZero() || !node.FirewallResumeAt.After(now) {
+		return 0, false
+	}
+	return node.FirewallResumeAt.Sub(now), true
+}
+
+// anyCountdown reports whether a pause or maintenance countdown is shown.
+func anyCountdown(snapshot state.Snapshot, now time.Time) bool {
+	for _, node := range snapshot.Nodes {
+		if _, ok := pausedUntil(node, now); ok {
+			return true
+		}
+		if _, ok := snapshot.MaintenanceFor(node.ID, now); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// togglePause opens the duration picker for the selected node, or cancels a
+// running pause and re-enables the firewall right away.
+func (m *Model) togglePause(snapshot state.Snapshot) {
+	node, ok := m.selectedNode(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.controller != nil, &m.statusLine) {
+		return
+	}
+	if _, paused := pausedUntil(node, m.now()); paused {
+		if err := m.controller.EnableFirewall(node.ID); err != nil {
+			m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to re-enable firewall on %s: %v", util.DisplayName(node), err))
+			return
+		}
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("Pause cancelled; firewall re-enabled on %s", util.DisplayName(node)))
+		return
+	}
+	m.openPicker(node.ID, false)
+}
+
+// openPicker shows the duration picker for nodeID, for a maintenance
+// window or a firewall pause.
+func (m *Model) openPicker(nodeID string, maintenance bool) {
+	input := textinput.New()
+	input.Prompt = "minutes: "
+	input.Placeholder = "15 or 1h30m"
+	input.CharLimit = 16
+	input.Width = 16
+	m.pauseInput = input
+	m.pauseNodeID = nodeID
+	m.pauseIdx = 0
+	m.pickMaintenance = maintenance
+	m.picking = true
+}
+
+// presets returns the choices of the open picker.
+func (m *Model) presets() []pausePreset {
+	if m.pickMaintenance {
+		return maintenancePresets
+	}
+	return pausePresets
+}
+
+func (m *Model) updatePicker(msg tea.KeyMsg, snapshot state.Snapshot) tea.Cmd {
+	presets := m.presets()
+	custom := presets[m.pauseIdx].duration == 0
+	switch msg.String() {
+	case "esc":
+		m.picking = false
+		return nil
+	case "left", "right", "p", "n", "shift+tab", "tab":
+		delta := 1
+		switch msg.String() {
+		case "left", "p", "shift+tab":
+			delta = -1
+		}
+		m.pauseIdx = util.WrapIndex(m.pauseIdx, delta, len(presets))
+		if presets[m.pauseIdx].duration == 0 {
+			m.pauseInput.Focus()
+		} else {
+			m.pauseInput.Blur()
+		}
+		return nil
+	case "enter":
+		d := presets[m.pauseIdx].duration
+		if custom {
+			parsed, err := parsePauseDuration(m.pauseInput.Value())
+			if err != nil {
+				m.statusLine = m.theme.Danger.Render(err.Error())
+				return nil
+			}
+			d = parsed
+		}
+		m.picking = false
+		if m.pickMaintenance {
+			return m.startMaintenance(snapshot, d)
+		}
+		return m.pause(snapshot, d)
+	}
+	if !custom {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.pauseInput, cmd = m.pauseInput.Update(msg)
+	return cmd
+}
+
+// pickedNodeLabel names the node the picker was opened for.
+func (m *Model) pickedNodeLabel(snapshot state.Snapshot) string {
+	for _, node := range snapshot.Nodes {
+		if node.ID == m.pauseNodeID {
+			return util.DisplayName(node)
+		}
+	}
+	return m.pauseNodeID
+}
+
+func (m *Model) pause(snapshot state.Snapshot, d time.Duration) tea.Cmd {
+	label := m.pickedNodeLabel(snapshot)
+	if err := m.controller.PauseFirewall(m.pauseNodeID, d); err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to pause firewall on %s: %v", label, err))
+		return nil
+	}
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Firewall paused on %s for %s", label, formatCountdown(d)))
+	return m.startTicking()
+}
+
+// startTicking begins a fresh once-a-second redraw chain for countdowns.
+func (m *Model) startTicking() tea.Cmd {
+	m.tickGen++
+	return pauseTick(m.tickGen)
+}
+
+func pauseTick(gen int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return pauseTickMsg{gen: gen} })
+}
+
+func (m *Model) renderPicker() string {
+	presets := m.presets()
+	labels := make([]string, len(presets))
+	for idx, preset := range presets {
+		if idx == m.pauseIdx {
+			labels[idx] = m.theme.Title.Render("[" + preset.label + "]")
+		} else {
+			labels[idx] = m.theme.Subtle.Render(" " + preset.label + " ")
+		}
+	}
+	title := "Pause firewall for: "
+	if m.pickMaintenance {
+		title = "Maintenance for: "
+	}
+	line := title + strings.Join(labels, " ")
+	if presets[m.pauseIdx].duration == 0 {
+		line += "  " + m.pauseInput.View()
+	}
+	return line
+}
@@ -0,0 +1,193 @@
+package nodes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// fakeFirewall mimics the daemon server by updating the store.
+type fakeFirewall struct {
+	store   *state.Store
+	now     time.Time
+	paused  map[string]time.Duration
+	enabled []string
+}
+
+func (f *fakeFirewall) EnableFirewall(nodeID string) error {
+	f.enabled = append(f.enabled, nodeID)
+	f.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallEnabled, n.FirewallResumeAt = true, time.Time{} })
+	return nil
+}
+
+func (f *fakeFirewall) DisableFirewall(string) error { return nil }
+
+func (f *fakeFirewall) PauseFirewall(nodeID string, d time.Duration) error {
+	f.paused[nodeID] = d
+	f.store.UpdateNode(nodeID, func(n *state.Node) { n.FirewallEnabled, n.FirewallResumeAt = false, f.now.Add(d) })
+	return nil
+}
+
+func newPauseModel(t *testing.T) (*Model, *fakeFirewall) {
+	t.Helper()
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(2))
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	fw := &fakeFirewall{store: store, now: now, paused: map[string]time.Duration{}}
+	m := New(store, theme.New(theme.Options{}), fw, nil).(*Model)
+	m.now = func() time.Time { return now }
+	m.SetSize(160, 12)
+	return m, fw
+}
+
+func pressKey(m *Model, key string) tea.Cmd {
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "right":
+		msg = tea.KeyMsg{Type: tea.KeyRight}
+	case "left":
+		msg = tea.KeyMsg{Type: tea.KeyLeft}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "tab":
+		msg = tea.KeyMsg{Type: tea.KeyTab}
+	case "shift+tab":
+		msg = tea.KeyMsg{Type: tea.KeyShiftTab}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	_, cmd := m.Update(msg)
+	return cmd
+}
+
+func TestNodesPausePresetShowsCountdown(t *testing.T) {
+	m, fw := newPauseModel(t)
+	pressKey(m, "t")
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "Pause firewall for: [5m]") {
+		t.Fatalf("expected duration picker, got:\n%s", out)
+	}
+	pressKey(m, "right")
+	if cmd := pressKey(m, "enter"); cmd == nil {
+		t.Fatalf("expected a countdown tick to be scheduled")
+	}
+	if fw.paused["tcp://10.0.0.1:50051"] != 10*time.Minute {
+		t.Fatalf("expected 10m pause on the selected node, got %v", fw.paused)
+	}
+
+	m.now = func() time.Time { return fw.now.Add(2*time.Minute + 28*time.Second) }
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "re-enabling in 7:32") {
+		t.Fatalf("expected countdown badge, got:\n%s", out)
+	}
+	if strings.Count(out, "re-enabling") != 1 {
+		t.Fatalf("expected only the paused node to show a badge")
+	}
+
+	_, cmd := m.Update(pauseTickMsg{gen: m.tickGen})
+	if cmd == nil {
+		t.Fatalf("expected ticks to continue while paused")
+	}
+	if _, cmd := m.Update(pauseTickMsg{gen: m.tickGen - 1}); cmd != nil {
+		t.Fatalf("expected stale tick chains to stop")
+	}
+}
+
+func TestNodesPauseRepeatPressCancels(t *testing.T) {
+	m, fw := newPauseModel(t)
+	pressKey(m, "t")
+	pressKey(m, "enter")
+	pressKey(m, "t")
+	if len(fw.enabled) != 1 || fw.enabled[0] != "tcp://10.0.0.1:50051" {
+		t.Fatalf("expected repeat press to re-enable the firewall, got %v", fw.enabled)
+	}
+	if m.picking {
+		t.Fatalf("expected no picker when cancelling a pause")
+	}
+	if out := util.StripANSI(m.View()); strings.Contains(out, "re-enabling") {
+		t.Fatalf("expected badge to disappear, got:\n%s", out)
+	}
+	if _, cmd := m.Update(pauseTickMsg{gen: m.tickGen}); cmd != nil {
+		t.Fatalf("expected ticks to stop once nothing is paused")
+	}
+}
+
+func TestNodesPauseCustomDuration(t *testing.T) {
+	m, fw := newPauseModel(t)
+	pressKey(m, "t")
+	pressKey(m, "left") // wraps to custom
+	for _, r := range "abc" {
+		pressKey(m, string(r))
+	}
+	pressKey(m, "enter")
+	if len(fw.paused) != 0 || !m.picking {
+		t.Fatalf("expected invalid input to keep the picker open")
+	}
+	if out := util.StripANSI(m.View()); !strings.Contains(out, `invalid duration "abc"`) {
+		t.Fatalf("expected validation error, got:\n%s", out)
+	}
+	m.pauseInput.SetValue("90")
+	pressKey(m, "enter")
+	if fw.paused["tcp://10.0.0.1:50051"] != 90*time.Minute {
+		t.Fatalf("expected 90 minute pause, got %v", fw.paused)
+	}
+
+	pressKey(m, "down")
+	pressKey(m, "t")
+	pressKey(m, "esc")
+	if m.picking || len(fw.paused) != 1 {
+		t.Fatalf("expected esc to close the picker without pausing")
+	}
+}
+
+func TestParsePauseDuration(t *testing.T) {
+	cases := map[string]time.Duration{"15": 15 * time.Minute, "1h30m": 90 * time.Minute, " 45s ": 45 * time.Second}
+	for in, want := range cases {
+		if got, err := parsePauseDuration(in); err != nil || got != want {
+			t.Fatalf("parsePauseDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0", "-5", "25h", "soon"} {
+		if _, err := parsePauseDuration(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+	if got := formatCountdown(time.Hour + 2*time.Minute + 3*time.Second); got != "1:02:03" {
+		t.Fatalf("unexpected countdown %q", got)
+	}
+}
+
+func TestNodesPausePickerWithoutArrowKeys(t *testing.T) {
+	m, fw := newPauseModel(t)
+	pressKey(m, "j")
+	pressKey(m, "t")
+	if !m.EnteringText() {
+		t.Fatalf("expected the picker to hold the keyboard")
+	}
+	pressKey(m, "n")   // 10m
+	pressKey(m, "tab") // 30m
+	pressKey(m, "p")   // 10m
+	pressKey(m, "tab") // 30m
+	pressKey(m, "shift+tab")
+	pressKey(m, "enter")
+	if m.EnteringText() {
+		t.Fatalf("expected the picker to close")
+	}
+	if len(fw.paused) != 1 {
+		t.Fatalf("expected one pause, got %v", fw.paused)
+	}
+	for id, d := range fw.paused {
+		if id != "tcp://10.0.0.2:50051" || d != 10*time.Minute {
+			t.Fatalf("expected 10m pause on the second node, got %s %v", id, d)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package nodes
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// togglePolicy switches the selected node between interactive prompts and
+// answering them with the defaults, for this session. interactive_prompts
+// in the config file makes it stick.
+func (m *Model) togglePolicy(snapshot state.Snapshot) {
+	node, ok := m.selectedNode(snapshot)
+	if !ok {
+		return
+	}
+	mode := state.PromptsPolicy
+	if state.PromptModeFor(snapshot.Nodes, node.ID) == state.PromptsPolicy {
+		mode = state.PromptsInteractive
+	}
+	m.store.UpdateNode(node.ID, func(n *state.Node) { n.Prompts = mode })
+	if mode == state.PromptsPolicy {
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("Prompts from %s are answered with the defaults (policy-only) until p again", util.DisplayName(node)))
+		return
+	}
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Prompts from %s are interactive again", util.DisplayName(node)))
+}
@@ -0,0 +1,29 @@
+package nodes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestNodesWithoutControllersAreReadOnly(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(1))
+	var typedNil *fakeFirewall
+	m := New(store, theme.New(theme.Options{}), typedNil, nil).(*Model)
+	m.SetSize(160, 12)
+	if out := util.StripANSI(m.View()); !strings.Contains(out, controller.ErrNoController.Error()) {
+		t.Fatalf("expected the read-only line before any key, got:\n%s", out)
+	}
+	for _, key := range []string{"t", "m", "b", "B", "s", "x", "T", "w"} {
+		m.statusLine = ""
+		pressKey(m, key)
+		if m.picking || util.StripANSI(m.statusLine) != controller.ErrNoController.Error() {
+			t.Fatalf("%s: expected the shared error, got %q", key, util.StripANSI(m.statusLine))
+		}
+	}
+}
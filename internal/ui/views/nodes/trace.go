@@ -0,0 +1,119 @@
+package nodes
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const traceHelp = "↑/↓ scroll · r refresh · d dump to file · T start/stop recording · esc close"
+
+// tracer returns the controller's protocol trace, reporting in the status
+// line when there is none.
+func (m *Model) tracer() (controller.ProtocolTracer, bool) {
+	if !widget.Guard(m.theme, m.controller != nil, &m.statusLine) {
+		return nil, false
+	}
+	tracer, ok := m.controller.(controller.ProtocolTracer)
+	if !ok {
+		m.statusLine = m.theme.Warning.Render("Protocol trace unavailable")
+	}
+	return tracer, ok
+}
+
+// toggleTrace turns the recording of notification traffic on or off for
+// every node.
+func (m *Model) toggleTrace() {
+	tracer, ok := m.tracer()
+	if !ok {
+		return
+	}
+	if tracer.ProtocolTraceEnabled() {
+		tracer.SetProtocolTrace(false)
+		m.statusLine = m.theme.Subtle.Render("Protocol trace stopped; what was recorded is kept")
+		return
+	}
+	tracer.SetProtocolTrace(true)
+	m.statusLine = m.theme.Success.Render("Recording the notifications sent to each node and their replies")
+}
+
+// openTrace shows the selected node's recorded notification traffic.
+func (m *Model) openTrace(snapshot state.Snapshot) {
+	node, ok := m.selectedNode(snapshot)
+	if !ok {
+		return
+	}
+	tracer, ok := m.tracer()
+	if !ok {
+		return
+	}
+	m.traceNodeID = node.ID
+	m.statusLine = ""
+	m.loadTrace(tracer, node)
+}
+
+// loadTrace refills the open pager, keeping it scrolled to the newest
+// messages.
+func (m *Model) loadTrace(tracer controller.ProtocolTracer, node state.Node) {
+	entries := tracer.ProtocolTrace(node.ID)
+	lines := make([]string, 0, len(entries)+1)
+	for _, entry := range entries {
+		lines = append(lines, entry.String())
+	}
+	if len(lines) == 0 {
+		lines = append(lines, traceText(tracer))
+	}
+	m.trace = widget.NewPager(fmt.Sprintf("Protocol trace · %s · %d messages", util.DisplayName(node), len(entries)), strings.Join(lines, "\n"))
+	m.trace.HandleKey("end", m.traceHeight())
+}
+
+// traceText is what an empty trace shows.
+func traceText(tracer controller.ProtocolTracer) string {
+	if tracer.ProtocolTraceEnabled() {
+		return "No notifications exchanged since recording started."
+	}
+	return "Protocol trace is off; press T to record, or start with -trace-protocol."
+}
+
+func (m *Model) updateTrace(key tea.KeyMsg, snapshot state.Snapshot) {
+	tracer, _ := m.controller.(controller.ProtocolTracer)
+	switch key.String() {
+	case "esc", "w":
+		m.trace = nil
+		m.traceNodeID = ""
+	case "r":
+		m.loadTrace(tracer, m.traceNode(snapshot))
+	case "d":
+		path, err := tracer.DumpProtocolTrace(m.traceNodeID)
+		if err != nil {
+			m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to dump the protocol trace: %v", err))
+			return
+		}
+		m.statusLine = m.theme.Success.Render("Protocol trace written to " + path)
+	case "T":
+		m.toggleTrace()
+	default:
+		m.trace.HandleKey(key.String(), m.traceHeight())
+	}
+}
+
+// traceNode finds the node whose trace is open; a node that has gone
+// keeps its ID, so its trace can still be refreshed.
+func (m *Model) traceNode(snapshot state.Snapshot) state.Node {
+	for _, node := range snapshot.Nodes {
+		if node.ID == m.traceNodeID {
+			return node
+		}
+	}
+	return state.Node{ID: m.traceNodeID}
+}
+
+func (m *Model) traceHeight() int {
+	return max(6, m.height-tableChrome)
+}
@@ -0,0 +1,93 @@
+package nodes
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// tracingFirewall keeps a fixed protocol trace per node.
+type tracingFirewall struct {
+	fakeFirewall
+	enabled bool
+	traces  map[string][]state.TraceEntry
+	dumped  []string
+}
+
+func (f *tracingFirewall) SetProtocolTrace(enabled bool) { f.enabled = enabled }
+func (f *tracingFirewall) ProtocolTraceEnabled() bool    { return f.enabled }
+func (f *tracingFirewall) ProtocolTrace(nodeID string) []state.TraceEntry {
+	return f.traces[nodeID]
+}
+
+func (f *tracingFirewall) DumpProtocolTrace(nodeID string) (string, error) {
+	if len(f.traces[nodeID]) == 0 {
+		return "", errors.New("no protocol trace recorded for " + nodeID)
+	}
+	f.dumped = append(f.dumped, nodeID)
+	return "/state/trace.txt", nil
+}
+
+func TestNodesProtocolTrace(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(2))
+	at := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := &tracingFirewall{traces: map[string][]state.TraceEntry{
+		"tcp://10.0.0.1:50051": {
+			{Direction: state.TraceSent, At: at, ID: 4, Action: "ENABLE_RULE", Summary: `rules:{name:"ssh"}`},
+			{Direction: state.TraceReceived, At: at.Add(30 * time.Millisecond), ID: 4, Action: "OK"},
+		},
+	}}
+	m := New(store, theme.New(theme.Options{}), ctrl, nil).(*Model)
+	m.SetSize(160, 14)
+
+	pressKey(m, "T")
+	if !ctrl.enabled || !strings.Contains(util.StripANSI(m.statusLine), "Recording the notifications") {
+		t.Fatalf("expected T to start recording, got %q", util.StripANSI(m.statusLine))
+	}
+	pressKey(m, "w")
+	out := util.StripANSI(m.View())
+	for _, want := range []string{"Protocol trace · node-00 · 2 messages", `12:00:00.000 → #4 ENABLE_RULE rules:{name:"ssh"}`, "12:00:00.030 ← #4 OK", "d dump to file"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the trace, got:\n%s", want, out)
+		}
+	}
+	pressKey(m, "d")
+	if len(ctrl.dumped) != 1 || util.StripANSI(m.statusLine) != "Protocol trace written to /state/trace.txt" {
+		t.Fatalf("expected the trace dumped, got %v and %q", ctrl.dumped, util.StripANSI(m.statusLine))
+	}
+	pressKey(m, "esc")
+	if m.trace != nil {
+		t.Fatalf("expected esc to close the trace")
+	}
+
+	// The second node has nothing recorded yet.
+	pressKey(m, "j")
+	pressKey(m, "w")
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "No notifications exchanged since recording started") {
+		t.Fatalf("expected the empty trace note, got:\n%s", out)
+	}
+	pressKey(m, "d")
+	if !strings.Contains(util.StripANSI(m.statusLine), "Failed to dump the protocol trace") {
+		t.Fatalf("expected the dump to fail, got %q", util.StripANSI(m.statusLine))
+	}
+	pressKey(m, "T")
+	if ctrl.enabled {
+		t.Fatalf("expected T to stop recording from the trace too")
+	}
+}
+
+func TestNodesProtocolTraceUnavailable(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes(makeTestNodes(1))
+	m := New(store, theme.New(theme.Options{}), &fakeFirewall{store: store}, nil).(*Model)
+	pressKey(m, "w")
+	if m.trace != nil || util.StripANSI(m.statusLine) != "Protocol trace unavailable" {
+		t.Fatalf("expected the trace unavailable, got %q", util.StripANSI(m.statusLine))
+	}
+}
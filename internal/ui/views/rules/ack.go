@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// ackWait is a rule action sent to a daemon whose reply is still expected.
+type ackWait struct {
+	nodeID string
+	node   string
+	action string
+	rule   string
+	sent   time.Time
+	// flagged is set once the wait outlasted the slow-ack threshold.
+	flagged bool
+}
+
+// checkAck replaces the "Requested" status once the daemon replied, and
+// warns when the reply is overdue. It runs on every render.
+func (m *Model) checkAck(snapshot state.Snapshot) {
+	wait := m.awaiting
+	if wait == nil {
+		return
+	}
+	threshold := snapshot.Settings.SlowAckThreshold()
+	ack, ok := state.FindAck(snapshot.Acks, wait.nodeID, ackAction(wait.action), wait.rule, wait.sent)
+	if !ok {
+		if !wait.flagged && m.now().Sub(wait.sent) > threshold {
+			wait.flagged = true
+			m.statusLine = m.theme.Warning.Render(fmt.Sprintf("Requested %s %s on %s · no ack after %s — daemon may be overloaded", wait.action, wait.rule, wait.node, util.HumanizeLatency(threshold)))
+		}
+		return
+	}
+	m.awaiting = nil
+	latency := util.HumanizeLatency(ack.Latency)
+	switch {
+	case ack.Err != "":
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("%s %s on %s failed after %s: %s", wait.action, wait.rule, wait.node, latency, ack.Err))
+	case ack.Slow:
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%sd %s on %s (ack %s) · slow — daemon may be overloaded", wait.action, wait.rule, wait.node, latency))
+	default:
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("%sd %s on %s (ack %s)", wait.action, wait.rule, wait.node, latency))
+	}
+}
+
+// ackAction is the daemon action whose reply answers a requested action;
+// new rules go out as changes, which the daemon creates when unknown.
+func ackAction(action string) string {
+	if action == "create" {
+		return "change"
+	}
+	return action
+}
@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/tasks"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// copyAckTimeout is how long copying a rule waits for a node to
+// acknowledge it before counting the node as failed.
+const copyAckTimeout = 30 * time.Second
+
+// bulkRun is the bulk action started from this view; its progress lives
+// in the store under id.
+type bulkRun struct {
+	id     string
+	cancel context.CancelFunc
+}
+
+// copyToNodes sends the selected rule to every other connected node, a few
+// nodes at a time, each waiting for the node's ack. Progress is shown in a
+// panel until dismissed.
+func (m *Model) copyToNodes(snapshot state.Snapshot) tea.Cmd {
+	node, rules, ok := m.current(snapshot)
+	if !ok || len(rules) == 0 {
+		return nil
+	}
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return nil
+	}
+	if task, running := m.bulkTask(snapshot); running && !task.Finished {
+		m.statusLine = m.theme.Warning.Render("A copy is still running; press esc to stop it")
+		return nil
+	}
+	m.dismissBulk()
+
+	rule := rules[min(m.ruleIdx, len(rules)-1)]
+	rule.Cached, rule.Session = false, false
+	var targets []state.Node
+	for _, target := range snapshot.Nodes {
+		if target.ID != node.ID && target.Status == state.NodeStatusReady {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		m.statusLine = m.theme.Warning.Render("No other connected nodes to copy to")
+		return nil
+	}
+
+	m.bulkSeq++
+	id := fmt.Sprintf("rules.copy.%d", m.bulkSeq)
+	title := fmt.Sprintf("Copy %s to %d nodes", rule.Name, len(targets))
+	ctx, cancel := context.WithCancel(context.Background())
+	m.bulk = &bulkRun{id: id, cancel: cancel}
+	m.store.SetTask(state.TaskProgress{ID: id, Title: title, Total: len(targets)})
+	m.statusLine = ""
+
+	store, ctrl, now := m.store, m.controller, m.now
+	copyRule := func(ctx context.Context, target state.Node) error {
+		sent := now()
+		if err := ctrl.AddRule(target.ID, rule); err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(ctx, copyAckTimeout)
+		defer cancel()
+		ack, err := store.WaitAck(ctx, target.ID, "change", rule.Name, sent)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return fmt.Errorf("no ack within %s", copyAckTimeout)
+		case err != nil:
+			return err
+		case ack.Err != "":
+			return errors.New(ack.Err)
+		}
+		return nil
+	}
+	opts := tasks.Options{Parallelism: snapshot.Settings.BulkParallelism}
+	return func() tea.Msg {
+		defer cancel()
+		tasks.Track(ctx, store, id, title, targets, util.DisplayName, copyRule, opts)
+		return nil
+	}
+}
+
+// stopBulk stops the running bulk action from starting further tasks, or
+// dismisses its panel once it has finished.
+func (m *Model) stopBulk(snapshot state.Snapshot) {
+	task, ok := m.bulkTask(snapshot)
+	if !ok || task.Finished {
+		m.dismissBulk()
+		return
+	}
+	m.bulk.cancel()
+	m.store.CancelTask(m.bulk.id)
+}
+
+func (m *Model) dismissBulk() {
+	if m.bulk == nil {
+		return
+	}
+	m.bulk.cancel()
+	m.store.RemoveTask(m.bulk.id)
+	m.bulk = nil
+}
+
+// bulkTask returns the progress of the bulk action started here, if any.
+func (m *Model) bulkTask(snapshot state.Snapshot) (state.TaskProgress, bool) {
+	if m.bulk == nil {
+		return state.TaskProgress{}, false
+	}
+	return state.TaskByID(snapshot.Tasks, m.bulk.id)
+}
+
+func (m *Model) renderBulk(snapshot state.Snapshot) string {
+	task, ok := m.bulkTask(snapshot)
+	if !ok {
+		return ""
+	}
+	return widget.RenderTaskPanel(m.theme, task, m.width)
+}
+
+// bulkShown enables stopping or dismissing a bulk action.
+func bulkShown(c keyContext) bool { return c.m.bulk != nil }
@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// copyController acks every added rule, with the daemon's error for nodes
+// in refuse. With release set, each call reports on entered and waits.
+type copyController struct {
+	noopRuleManager
+	store   *state.Store
+	refuse  map[string]string
+	entered chan struct{}
+	release chan struct{}
+
+	mu    sync.Mutex
+	added []string
+}
+
+func (c *copyController) AddRule(nodeID string, rule state.Rule) error {
+	if c.release != nil {
+		c.entered <- struct{}{}
+		<-c.release
+	}
+	c.mu.Lock()
+	c.added = append(c.added, nodeID)
+	c.mu.Unlock()
+	c.store.RecordAck(state.ActionAck{NodeID: nodeID, Action: "change", Rule: rule.Name, SentAt: time.Now(), Err: c.refuse[nodeID]})
+	return nil
+}
+
+func newCopyModel(t *testing.T, ctrl *copyController) *Model {
+	t.Helper()
+	ctrl.store = state.NewStore()
+	ctrl.store.SetNodes([]state.Node{
+		{ID: "node-1", Name: "alpha", Status: state.NodeStatusReady},
+		{ID: "node-2", Name: "beta", Status: state.NodeStatusReady},
+		{ID: "node-3", Name: "gamma", Status: state.NodeStatusReady},
+		{ID: "node-4", Name: "delta", Status: state.NodeStatusDisconnected},
+		{ID: "node-5", Name: "epsilon", Status: state.NodeStatusReady},
+	})
+	ctrl.store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow", Duration: "always", Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"}}})
+	m := New(ctrl.store, theme.New(theme.Options{}), ctrl).(*Model)
+	m.SetSize(160, 30)
+	return m
+}
+
+func TestCopyToNodesReportsFailures(t *testing.T) {
+	ctrl := &copyController{refuse: map[string]string{"node-3": "rule exists"}}
+	m := newCopyModel(t, ctrl)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	if cmd == nil {
+		t.Fatalf("expected the copy to start")
+	}
+	if out := m.View(); !strings.Contains(out, "Copy ssh to 3 nodes: 0 of 3 complete") {
+		t.Fatalf("expected the panel before any node answered, got %q", out)
+	}
+	cmd()
+
+	if len(ctrl.added) != 3 {
+		t.Fatalf("expected the rule sent to the 3 other connected nodes, got %v", ctrl.added)
+	}
+	out := m.View()
+	for _, want := range []string{"3 of 3 complete, 1 failed", "gamma: rule exists", "esc dismiss"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the panel, got %q", want, out)
+		}
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.bulk != nil || len(ctrl.store.Snapshot().Tasks) != 0 {
+		t.Fatalf("expected esc to dismiss the finished copy")
+	}
+}
+
+func TestCopyToNodesStopsDispatching(t *testing.T) {
+	ctrl := &copyController{entered: make(chan struct{}, 3), release: make(chan struct{})}
+	m := newCopyModel(t, ctrl)
+	m.store.SetSettings(state.Settings{BulkParallelism: 1})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	done := make(chan struct{})
+	go func() {
+		cmd()
+		close(done)
+	}()
+	// The first node's task is running; stop before releasing it.
+	<-ctrl.entered
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if out := m.View(); !strings.Contains(out, "canceling") {
+		t.Fatalf("expected the panel to show the copy stopping, got %q", out)
+	}
+	close(ctrl.release)
+	<-done
+
+	task, ok := state.TaskByID(m.store.Snapshot().Tasks, m.bulk.id)
+	if !ok || !task.Finished || task.Done != 1 || task.Skipped != 2 || len(task.Failures) != 0 {
+		t.Fatalf("expected one node copied and two skipped, got %+v", task)
+	}
+	if out := m.View(); !strings.Contains(out, "canceled with 2 not started") {
+		t.Fatalf("expected the skipped nodes in the panel, got %q", out)
+	}
+	if len(ctrl.added) != 1 {
+		t.Fatalf("expected only one node to be sent the rule, got %v", ctrl.added)
+	}
+}
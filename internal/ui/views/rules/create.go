@@ -0,0 +1,230 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const createHelp = "esc cancel · enter create · tab/shift+tab · ←/→ or n/p change"
+
+const (
+	createFieldName = iota
+	createFieldDescription
+	createFieldAction
+	createFieldDuration
+	createFieldType
+	createFieldOperand
+	createFieldData
+	createFieldCount
+)
+
+// ruleTypeOptions are the operator types a rule can be written with here;
+// list operators nest several operators and are left to prompts.
+var ruleTypeOptions = []widget.Option{
+	{Label: "Simple", Value: ruleset.OperatorSimple},
+	{Label: "Regexp", Value: ruleset.OperatorRegexp},
+	{Label: "Network", Value: ruleset.OperatorNetwork},
+}
+
+var ruleOperandOptions = []widget.Option{
+	{Label: "Executable", Value: "process.path"},
+	{Label: "Command", Value: "process.command"},
+	{Label: "Process ID", Value: "process.id"},
+	{Label: "User ID", Value: "user.id"},
+	{Label: "Destination host", Value: "dest.host"},
+	{Label: "Destination IP", Value: "dest.ip"},
+	{Label: "Destination port", Value: "dest.port"},
+	{Label: "Destination network", Value: "dest.network"},
+	{Label: "Source IP", Value: "source.ip"},
+	{Label: "Protocol", Value: "protocol"},
+}
+
+// createState is the open new-rule form for one node.
+type createState struct {
+	nodeID string
+	focus  int
+	// inputs holds the name, description and data fields, in that order.
+	inputs     [3]textinput.Model
+	actionIdx  int
+	durIdx     int
+	typeIdx    int
+	operandIdx int
+}
+
+// input returns the text field focus is on, or nil on an option row.
+func (st *createState) input() *textinput.Model { return st.inputFor(st.focus) }
+
+func (st *createState) inputFor(field int) *textinput.Model {
+	switch field {
+	case createFieldName:
+		return &st.inputs[0]
+	case createFieldDescription:
+		return &st.inputs[1]
+	case createFieldData:
+		return &st.inputs[2]
+	}
+	return nil
+}
+
+// openCreate starts a blank rule for the selected node, denying always by
+// default like the daemon's own default action.
+func (m *Model) openCreate(snapshot state.Snapshot) {
+	node, _, ok := m.current(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return
+	}
+	st := &createState{
+		nodeID:    node.ID,
+		actionIdx: widget.IndexOf(ruleActionOptions, "deny"),
+		durIdx:    widget.IndexOf(ruleDurationOptions, "always"),
+	}
+	for i, placeholder := range []string{"rule name", "optional", "/usr/bin/curl"} {
+		ti := textinput.New()
+		ti.Placeholder = placeholder
+		ti.CharLimit = 0
+		ti.Width = 40
+		st.inputs[i] = ti
+	}
+	st.inputs[0].Focus()
+	m.create = st
+	m.statusLine = ""
+}
+
+// updateCreate passes the keys the form's table leaves to the focused text
+// field.
+func (m *Model) updateCreate(key tea.KeyMsg, snapshot state.Snapshot) tea.Cmd {
+	if cmd, ok := createKeys.Dispatch(key.String(), keyContext{m: m, snapshot: snapshot}); ok {
+		return cmd
+	}
+	input := m.create.input()
+	if input == nil {
+		return nil
+	}
+	var cmd tea.Cmd
+	*input, cmd = input.Update(key)
+	return cmd
+}
+
+func (m *Model) cycleCreateFocus(delta int) {
+	st := m.create
+	if input := st.input(); input != nil {
+		input.Blur()
+	}
+	st.focus = util.WrapIndex(st.focus, delta, createFieldCount)
+	if input := st.input(); input != nil {
+		input.Focus()
+	}
+}
+
+func (m *Model) adjustCreateSelection(delta int) {
+	st := m.create
+	switch st.focus {
+	case createFieldAction:
+		st.actionIdx = util.WrapIndex(st.actionIdx, delta, len(ruleActionOptions))
+	case createFieldDuration:
+		st.durIdx = util.WrapIndex(st.durIdx, delta, len(ruleDurationOptions))
+	case createFieldType:
+		st.typeIdx = util.WrapIndex(st.typeIdx, delta, len(ruleTypeOptions))
+	case createFieldOperand:
+		st.operandIdx = util.WrapIndex(st.operandIdx, delta, len(ruleOperandOptions))
+	}
+}
+
+// rule assembles the form into a rule for nodeID.
+func (st *createState) rule() state.Rule {
+	return state.Rule{
+		NodeID:      st.nodeID,
+		Name:        strings.TrimSpace(st.inputs[0].Value()),
+		Description: strings.TrimSpace(st.inputs[1].Value()),
+		Action:      ruleActionOptions[st.actionIdx].Value,
+		Duration:    ruleDurationOptions[st.durIdx].Value,
+		Enabled:     true,
+		Operator: state.RuleOperator{
+			Type:    ruleTypeOptions[st.typeIdx].Value,
+			Operand: ruleOperandOptions[st.operandIdx].Value,
+			Data:    strings.TrimSpace(st.inputs[2].Value()),
+		},
+	}
+}
+
+// submitCreate sends the new rule. The form stays open on errors so they
+// can be fixed in place.
+func (m *Model) submitCreate(snapshot state.Snapshot) {
+	st := m.create
+	node, ok := nodeByID(snapshot.Nodes, st.nodeID)
+	if !ok {
+		m.create = nil
+		m.statusLine = m.theme.Danger.Render("Node disconnected")
+		return
+	}
+	rule := st.rule()
+	switch {
+	case rule.Name == "":
+		m.statusLine = m.theme.Danger.Render("Rule name required")
+		return
+	case rule.Operator.Data == "":
+		m.statusLine = m.theme.Danger.Render("Rule data required")
+		return
+	}
+	if _, exists := ruleset.Lookup(snapshot.Rules, node.ID, rule.Name); exists {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Rule %s already exists on %s", rule.Name, util.DisplayName(node)))
+		return
+	}
+	if err := ruleset.LimitsFor(snapshot.Settings).Validate(rule); err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Invalid rule: %v", err))
+		return
+	}
+	sent := m.now()
+	err := m.controller.AddRule(node.ID, rule)
+	m.renderActionResult(err, "create", node, rule, sent)
+	if err == nil {
+		m.create = nil
+	}
+}
+
+func (m *Model) renderCreate(snapshot state.Snapshot) string {
+	st := m.create
+	label := st.nodeID
+	if node, ok := nodeByID(snapshot.Nodes, st.nodeID); ok {
+		label = util.DisplayName(node)
+	}
+	input := func(name string, field int) string {
+		ti := *st.inputFor(field)
+		ti.Prompt = "  "
+		if st.focus == field {
+			ti.Prompt = m.theme.Warning.Render("> ")
+		}
+		return fmt.Sprintf("%s: %s", name, ti.View())
+	}
+	rows := []string{
+		m.theme.Header.Render(fmt.Sprintf("New rule on %s", label)),
+		input("Name", createFieldName),
+		input("Description", createFieldDescription),
+		widget.RenderOptionRow(m.theme, "Action", ruleActionOptions, st.actionIdx, st.focus == createFieldAction),
+		widget.RenderOptionRow(m.theme, "Duration", ruleDurationOptions, st.durIdx, st.focus == createFieldDuration),
+		widget.RenderOptionRow(m.theme, "Type", ruleTypeOptions, st.typeIdx, st.focus == createFieldType),
+		widget.RenderOptionRow(m.theme, "Operand", ruleOperandOptions, st.operandIdx, st.focus == createFieldOperand),
+		input("Data", createFieldData),
+	}
+	return m.theme.Body.Render(strings.Join(rows, "\n"))
+}
+
+func nodeByID(nodes []state.Node, id string) (state.Node, bool) {
+	for _, node := range nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+	return state.Node{}, false
+}
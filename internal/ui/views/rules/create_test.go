@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func createStore() *state.Store {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+	return store
+}
+
+func typeText(m *Model, text string) {
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+}
+
+func TestCreateFormCyclesFocus(t *testing.T) {
+	view := New(createStore(), theme.New(theme.Options{}), &fakeRuleController{}).(*Model)
+	view.SetSize(120, 30)
+
+	typeText(view, "n")
+	if view.create == nil || !view.EnteringText() {
+		t.Fatal("expected n to open the new-rule form")
+	}
+	// n and p are text on the name field, not option keys.
+	typeText(view, "np")
+	if got := view.create.inputs[0].Value(); got != "np" {
+		t.Fatalf("expected n/p typed into the name, got %q", got)
+	}
+	for want := 1; want <= createFieldCount; want++ {
+		view.Update(tea.KeyMsg{Type: tea.KeyTab})
+		if view.create.focus != want%createFieldCount {
+			t.Fatalf("tab %d: expected focus %d, got %d", want, want%createFieldCount, view.create.focus)
+		}
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if view.create.focus != createFieldData || !view.create.inputs[2].Focused() || view.create.inputs[0].Focused() {
+		t.Fatalf("expected shift+tab to wrap onto the data field, got focus %d", view.create.focus)
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	typeText(view, "n")
+	if view.create.operandIdx != 1 {
+		t.Fatalf("expected n to pick the next operand, got %d", view.create.operandIdx)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view.create != nil || view.EnteringText() {
+		t.Fatal("expected esc to close the form")
+	}
+}
+
+func TestCreateFormValidatesName(t *testing.T) {
+	ctrl := &fakeRuleController{}
+	view := New(createStore(), theme.New(theme.Options{}), ctrl).(*Model)
+	view.SetSize(120, 30)
+
+	typeText(view, "n")
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if out := view.View(); !strings.Contains(out, "Rule name required") {
+		t.Fatalf("expected an empty name rejected, got %q", out)
+	}
+	typeText(view, "ssh")
+	view.create.inputs[2].SetValue("/usr/bin/ssh")
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if out := view.View(); !strings.Contains(out, "Rule ssh already exists on alpha") {
+		t.Fatalf("expected a duplicate name rejected, got %q", out)
+	}
+	if ctrl.action != "" || view.create == nil {
+		t.Fatalf("expected the form kept open with nothing sent, got %+v", ctrl)
+	}
+}
+
+func TestCreateFormAddsRule(t *testing.T) {
+	store := createStore()
+	clock := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := &fakeRuleController{}
+	view := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	view.now = func() time.Time { return clock }
+	view.SetSize(160, 30)
+
+	typeText(view, "n")
+	typeText(view, "block-curl")
+	for range createFieldOperand - createFieldName {
+		view.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	view.Update(tea.KeyMsg{Type: tea.KeyRight})
+	view.Update(tea.KeyMsg{Type: tea.KeyTab})
+	view.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeText(view, "^/usr/bin/curl$")
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	op := ctrl.rule.Operator
+	if ctrl.action != "add" || ctrl.nodeID != "node-1" || ctrl.rule.Name != "block-curl" || op.Type != "regexp" || op.Operand != "process.path" || op.Data != "^/usr/bin/curl$" {
+		t.Fatalf("expected block-curl added to node-1, got %+v", ctrl)
+	}
+	if ctrl.rule.Action != "deny" || ctrl.rule.Duration != "always" || !ctrl.rule.Enabled {
+		t.Fatalf("expected an enabled deny always rule, got %+v", ctrl.rule)
+	}
+	if view.create != nil {
+		t.Fatal("expected the form closed after creating")
+	}
+	store.RecordAck(state.ActionAck{NodeID: "node-1", Action: "change", Rule: "block-curl", SentAt: clock, Latency: 12 * time.Millisecond})
+	if out := view.View(); !strings.Contains(out, "created block-curl on alpha (ack 12ms)") {
+		t.Fatalf("expected the create acked by the daemon's change reply, got %q", out)
+	}
+}
@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestRulesHideDisabledToggle(t *testing.T) {
+	m := newJumpModel(t, 6)
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown}) // rule-02, enabled
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	out := m.View()
+	if strings.Contains(out, "rule-01") || strings.Contains(out, "rule-03") {
+		t.Fatalf("expected disabled rules to be hidden, got %q", out)
+	}
+	if !strings.Contains(out, "3 hidden") {
+		t.Fatalf("expected hidden count in status line, got %q", out)
+	}
+	if m.ruleIdx != 1 {
+		t.Fatalf("expected cursor to stay on rule-02 (index 1), got %d", m.ruleIdx)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	out = m.View()
+	if !strings.Contains(out, "rule-01") || strings.Contains(out, "hidden)") {
+		t.Fatalf("expected disabled rules to be reachable again, got %q", out)
+	}
+	if m.ruleIdx != 2 {
+		t.Fatalf("expected cursor to stay on rule-02 (index 2), got %d", m.ruleIdx)
+	}
+}
+
+func TestRulesHideDisabledActsOnVisibleRows(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", makeTestRules(4))
+	ctrl := &fakeRuleController{}
+	m := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	m.SetSize(100, 25)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if ctrl.ruleName != "rule-02" {
+		t.Fatalf("expected disable to target visible rule-02, got %q", ctrl.ruleName)
+	}
+}
+
+func TestRulesDisabledRowsDimmedPerTheme(t *testing.T) {
+	prev := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(prev) })
+
+	for _, preset := range theme.Presets() {
+		t.Run(preset.Name, func(t *testing.T) {
+			th := theme.New(theme.Options{Name: preset.Name})
+			m := New(state.NewStore(), th, nil).(*Model)
+			m.SetSize(120, 20)
+			layout := m.tableColumns()
+			gap := strings.Repeat(" ", columnGap)
+			subtle := termenv.TrueColor.Color(string(th.Subtle.GetForeground().(lipgloss.Color))).Sequence(false)
+			disabled := state.Rule{Name: "off", Action: "allow", Duration: "always"}
+			enabled := state.Rule{Name: "on", Action: "allow", Duration: "always", Enabled: true}
+
+			dimRow := m.renderRuleRow(layout, disabled, 1, false, gap)
+			if got, want := strings.Count(dimRow, subtle), layout.count(); got < want {
+				t.Fatalf("expected every cell of a disabled row to use the subtle colour, got %d of %d", got, want)
+			}
+			// The duration column is subtle by design; the name must not be.
+			if normal := m.renderRuleRow(layout, enabled, 1, false, gap); strings.Count(normal, subtle) >= layout.count() {
+				t.Fatalf("expected enabled row not to be dimmed: %q", normal)
+			}
+			selected := m.renderRuleRow(layout, disabled, 1, true, gap)
+			if strings.Count(selected, subtle) >= layout.count() {
+				t.Fatalf("expected selected disabled row to keep full-contrast foregrounds: %q", selected)
+			}
+			bg := termenv.TrueColor.Color(string(th.TableRowSelect)).Sequence(true)
+			if !strings.Contains(selected, bg) {
+				t.Fatalf("expected selected row background %q in %q", bg, selected)
+			}
+		})
+	}
+}
+
+func TestRulesSessionBadgeAndFilter(t *testing.T) {
+	m := newJumpModel(t, 4)
+	m.store.AddRule("node-1", state.Rule{Name: "allow-curl", Action: "allow", Duration: "always", Enabled: true})
+	m.store.AddRule("node-1", state.Rule{Name: "deny-nc", Action: "deny", Duration: "always", Enabled: true})
+	// A daemon refresh drops the in-memory mark from the rules it sends.
+	m.store.SetRules("node-1", append(makeTestRules(4),
+		state.Rule{NodeID: "node-1", Name: "allow-curl", Action: "allow", Enabled: true},
+		state.Rule{NodeID: "node-1", Name: "deny-nc", Action: "deny", Enabled: true},
+	))
+
+	out := util.StripANSI(m.View())
+	if !strings.Contains(out, "created this session: 2") {
+		t.Fatalf("expected the session count in the header, got:\n%s", out)
+	}
+	if !strings.Contains(out, "allow-curl new") || !strings.Contains(out, "deny-nc new") || strings.Contains(out, "rule-00 new") {
+		t.Fatalf("expected badges on the two session rules only, got:\n%s", out)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	out = util.StripANSI(m.View())
+	if strings.Contains(out, "rule-01") || !strings.Contains(out, "allow-curl") || !strings.Contains(out, "4 hidden") {
+		t.Fatalf("expected only session rules listed, got:\n%s", out)
+	}
+
+	m.store.SetRules("node-1", makeTestRules(4))
+	out = util.StripANSI(m.View())
+	if !strings.Contains(out, "No rules on this node were created this session") {
+		t.Fatalf("expected the empty session filter message, got:\n%s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if out := util.StripANSI(m.View()); !strings.Contains(out, "rule-01") || strings.Contains(out, "created this session") {
+		t.Fatalf("expected all rules back without a session count, got:\n%s", out)
+	}
+}
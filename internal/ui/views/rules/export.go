@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const exportHelp = "enter export · esc cancel"
+
+// startExport asks for the directory to write the node's rule files to,
+// starting from the last one used or the daemon's own.
+func (m *Model) startExport(snapshot state.Snapshot) {
+	if _, _, ok := m.current(snapshot); !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return
+	}
+	if _, ok := m.controller.(controller.RuleExporter); !ok {
+		m.statusLine = m.theme.Warning.Render("Rule export unavailable")
+		return
+	}
+	input := textinput.New()
+	input.Prompt = "Export rules to: "
+	input.Placeholder = ruleset.DaemonRulesDir
+	input.CharLimit = 4096
+	input.Width = 48
+	input.SetValue(util.Fallback(m.exportDir, ruleset.DaemonRulesDir))
+	input.Focus()
+	m.exportInput = input
+	m.exporting = true
+}
+
+func (m *Model) updateExport(msg tea.KeyMsg, snapshot state.Snapshot) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.exporting = false
+		return nil
+	case tea.KeyEnter:
+		m.exporting = false
+		m.exportRules(snapshot, strings.TrimSpace(m.exportInput.Value()))
+		return nil
+	}
+	var cmd tea.Cmd
+	m.exportInput, cmd = m.exportInput.Update(msg)
+	return cmd
+}
+
+// exportRules writes every rule of the selected node to dir, whatever the
+// table's filters hide.
+func (m *Model) exportRules(snapshot state.Snapshot, dir string) {
+	node, _, ok := m.current(snapshot)
+	if !ok || dir == "" {
+		return
+	}
+	if len(snapshot.Rules[node.ID]) == 0 {
+		m.statusLine = m.theme.Warning.Render("No rules to export")
+		return
+	}
+	m.exportDir = dir
+	written, err := m.controller.(controller.RuleExporter).ExportRules(node.ID, dir)
+	if err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Export failed after %d rules: %v", written, err))
+		return
+	}
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Exported %d rules of %s to %s", written, util.DisplayName(node), dir))
+}
@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+type fakeExportController struct {
+	fakeRuleController
+	nodeID, dir string
+}
+
+func (f *fakeExportController) ExportRules(nodeID, dir string) (int, error) {
+	f.nodeID, f.dir = nodeID, dir
+	return 2, f.err
+}
+
+func TestExportPromptsForDirectory(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}, {Name: "curl", Action: "deny"}})
+	ctrl := &fakeExportController{}
+	view := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	view.SetSize(140, 30)
+
+	typeText(view, "E")
+	if !view.EnteringText() || view.exportInput.Value() != ruleset.DaemonRulesDir {
+		t.Fatalf("expected E to ask for a directory starting at the daemon's, got %q", view.exportInput.Value())
+	}
+	view.exportInput.SetValue("/tmp/rules-backup")
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if ctrl.nodeID != "node-1" || ctrl.dir != "/tmp/rules-backup" {
+		t.Fatalf("expected node-1 exported to /tmp/rules-backup, got %q to %q", ctrl.nodeID, ctrl.dir)
+	}
+	if out := util.StripANSI(view.View()); !strings.Contains(out, "Exported 2 rules of alpha to /tmp/rules-backup") {
+		t.Fatalf("expected the count in the status line, got %q", out)
+	}
+
+	// The next export starts from the directory used last; esc leaves it.
+	typeText(view, "E")
+	if view.exportInput.Value() != "/tmp/rules-backup" {
+		t.Fatalf("expected the last directory offered again, got %q", view.exportInput.Value())
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view.EnteringText() {
+		t.Fatal("expected esc to close the export prompt")
+	}
+}
+
+func TestExportUnavailableWithoutExporter(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+	view := New(store, theme.New(theme.Options{}), &fakeRuleController{}).(*Model)
+	view.SetSize(140, 30)
+
+	typeText(view, "E")
+	if view.EnteringText() || !strings.Contains(util.StripANSI(view.View()), "Rule export unavailable") {
+		t.Fatal("expected export refused without an exporter")
+	}
+}
+
+type fakeImportController struct {
+	fakeRuleController
+	nodeID, path string
+	result       controller.ImportResult
+}
+
+func (f *fakeImportController) ImportRules(nodeID, path string) (controller.ImportResult, error) {
+	f.nodeID, f.path = nodeID, path
+	return f.result, f.err
+}
+
+func TestImportSummarizesSkippedFiles(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	ctrl := &fakeImportController{result: controller.ImportResult{
+		Imported: 4,
+		Skipped:  []error{errors.New("broken.json: unexpected end of JSON input"), errors.New("nameless.json: rule name required")},
+	}}
+	view := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	view.SetSize(160, 30)
+
+	typeText(view, "I")
+	if !view.EnteringText() {
+		t.Fatal("expected I to ask for a path")
+	}
+	view.importInput.SetValue("/tmp/rules-backup")
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if ctrl.nodeID != "node-1" || ctrl.path != "/tmp/rules-backup" {
+		t.Fatalf("expected /tmp/rules-backup imported to node-1, got %q to %q", ctrl.path, ctrl.nodeID)
+	}
+	out := util.StripANSI(view.View())
+	if !strings.Contains(out, "Imported 4, skipped 2 on alpha") || !strings.Contains(out, "broken.json") {
+		t.Fatalf("expected the counts and first skipped file in the status line, got %q", out)
+	}
+
+	typeText(view, "I")
+	if view.importInput.Value() != "/tmp/rules-backup" {
+		t.Fatalf("expected the last path offered again, got %q", view.importInput.Value())
+	}
+}
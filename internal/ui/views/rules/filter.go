@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+const filterHelp = "type to filter by name, action, data or description · ↑/↓ move · enter keep · esc clear"
+
+func newFilterInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "/ "
+	input.Placeholder = "rule name, action, data or description"
+	input.CharLimit = 128
+	input.Width = 40
+	return input
+}
+
+func (m *Model) startFilter() {
+	m.filter.Focus()
+	m.filtering = true
+}
+
+// updateFilter narrows the table as the query is typed; the arrows keep
+// moving through the rules left.
+func (m *Model) updateFilter(key tea.KeyMsg, snapshot state.Snapshot) tea.Cmd {
+	switch key.Type {
+	case tea.KeyEsc:
+		m.filtering = false
+		m.clearFilter(snapshot)
+		return nil
+	case tea.KeyEnter:
+		m.filtering = false
+		m.filter.Blur()
+		return nil
+	case tea.KeyUp, tea.KeyDown:
+		cmd, _ := tableKeys.Dispatch(key.String(), keyContext{m: m, snapshot: snapshot})
+		return cmd
+	}
+	var cmd tea.Cmd
+	m.keepSelection(snapshot, func() { m.filter, cmd = m.filter.Update(key) })
+	return cmd
+}
+
+func (m *Model) clearFilter(snapshot state.Snapshot) {
+	m.filter.Blur()
+	m.keepSelection(snapshot, func() { m.filter.SetValue("") })
+}
+
+func (m *Model) filterQuery() string {
+	return strings.ToLower(strings.TrimSpace(m.filter.Value()))
+}
+
+// filterRules keeps the rules whose name, action, description or operator
+// data contain query, which is already lower case.
+func filterRules(rules []state.Rule, query string) []state.Rule {
+	if query == "" {
+		return rules
+	}
+	out := make([]state.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if ruleMatches(rule, query) {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+func ruleMatches(rule state.Rule, query string) bool {
+	for _, field := range []string{rule.Name, rule.Action, rule.Description} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return operatorMatches(rule.Operator, query)
+}
+
+// operatorMatches searches the data of op and, for list operators, of
+// every operand in it.
+func operatorMatches(op state.RuleOperator, query string) bool {
+	if strings.Contains(strings.ToLower(op.Data), query) {
+		return true
+	}
+	for _, child := range op.Children {
+		if operatorMatches(child, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderFilter shows the query being typed, or the applied one with how
+// many rules it leaves.
+func (m *Model) renderFilter(shown, total int) string {
+	if m.filtering {
+		return fmt.Sprintf("%s  %s", m.filter.View(), m.theme.Subtle.Render(fmt.Sprintf("filtered: %d/%d", shown, total)))
+	}
+	if query := m.filter.Value(); query != "" {
+		return m.theme.Subtle.Render(fmt.Sprintf("filtered: %d/%d · %q (/ to change, esc to clear)", shown, total, query))
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func filterStore() *state.Store {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{
+		{Name: "allow-ssh", Action: "allow", Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"}},
+		{Name: "deny-telemetry", Action: "deny", Description: "Vendor Telemetry", Operator: state.RuleOperator{Type: "simple", Operand: "dest.host", Data: "metrics.example.com"}},
+		{Name: "allow-curl", Action: "allow", Operator: state.RuleOperator{Type: "list", Children: []state.RuleOperator{
+			{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"},
+			{Type: "simple", Operand: "dest.port", Data: "443"},
+		}}},
+	})
+	return store
+}
+
+func TestFilterMatchesRuleFields(t *testing.T) {
+	rules := filterStore().Snapshot().Rules["node-1"]
+	for query, want := range map[string]string{
+		"ssh":       "allow-ssh",
+		"deny":      "deny-telemetry",
+		"telemetry": "deny-telemetry",
+		"metrics":   "deny-telemetry",
+		"bin/curl":  "allow-curl",
+	} {
+		got := filterRules(rules, query)
+		if len(got) != 1 || got[0].Name != want {
+			t.Fatalf("%q: expected only %s, got %+v", query, want, got)
+		}
+	}
+	if got := filterRules(rules, "allow"); len(got) != 2 {
+		t.Fatalf("expected both allow rules, got %+v", got)
+	}
+}
+
+func TestFilterThenDeleteTargetsMatchedRule(t *testing.T) {
+	ctrl := &fakeRuleController{}
+	view := New(filterStore(), theme.New(theme.Options{}), ctrl).(*Model)
+	view.SetSize(120, 30)
+
+	typeText(view, "/")
+	if !view.EnteringText() {
+		t.Fatal("expected / to open the filter")
+	}
+	typeText(view, "TELE")
+	if out := view.View(); !strings.Contains(out, "filtered: 1/3") || strings.Contains(out, "allow-ssh") {
+		t.Fatalf("expected the table narrowed to one rule, got %q", out)
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view.EnteringText() {
+		t.Fatal("expected enter to leave the filter applied")
+	}
+	typeText(view, "x")
+	if ctrl.action != "delete" || ctrl.ruleName != "deny-telemetry" {
+		t.Fatalf("expected the delete to target deny-telemetry, got %+v", ctrl)
+	}
+}
+
+func TestFilterSelectionMovesWithinMatches(t *testing.T) {
+	ctrl := &fakeRuleController{}
+	view := New(filterStore(), theme.New(theme.Options{}), ctrl).(*Model)
+	view.SetSize(120, 30)
+
+	typeText(view, "/")
+	typeText(view, "allow")
+	view.Update(tea.KeyMsg{Type: tea.KeyDown})
+	view.Update(tea.KeyMsg{Type: tea.KeyDown})
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	typeText(view, "d")
+	if ctrl.action != "disable" || ctrl.ruleName != "allow-curl" {
+		t.Fatalf("expected the disable to target the last match allow-curl, got %+v", ctrl)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if out := view.View(); strings.Contains(out, "filtered:") || !strings.Contains(out, "deny-telemetry") {
+		t.Fatalf("expected esc to clear the applied filter, got %q", out)
+	}
+	typeText(view, "/")
+	typeText(view, "nothing-matches")
+	if out := view.View(); !strings.Contains(out, "No rules match the filter") || !strings.Contains(out, "filtered: 0/3") {
+		t.Fatalf("expected the no-match message, got %q", out)
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if view.EnteringText() || view.filter.Value() != "" {
+		t.Fatal("expected esc to close and clear the filter")
+	}
+}
@@ -0,0 +1,49 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// footprintCache is how many events in the history one rule matched, valid
+// while the history stays at rev.
+type footprintCache struct {
+	nodeID string
+	name   string
+	rev    uint64
+	fp     state.Footprint
+}
+
+// ruleFootprint aggregates the events the rule matched, recomputed only
+// when the selection moves to another rule or the history changes.
+func (m *Model) ruleFootprint(snapshot state.Snapshot, rule state.Rule) state.Footprint {
+	if c := m.footprint; c != nil && c.nodeID == rule.NodeID && c.name == rule.Name && c.rev == snapshot.EventsRevision {
+		return c.fp
+	}
+	m.footprint = &footprintCache{
+		nodeID: rule.NodeID,
+		name:   rule.Name,
+		rev:    snapshot.EventsRevision,
+		fp:     state.RuleFootprint(snapshot.Events, rule.NodeID, rule.Name),
+	}
+	return m.footprint.fp
+}
+
+// footprintLine says how many recent events referenced rule.
+func (m *Model) footprintLine(snapshot state.Snapshot, rule state.Rule) string {
+	fp := m.ruleFootprint(snapshot, rule)
+	if fp.Events == 0 {
+		return "Recent events: none matched this rule"
+	}
+	events := "events"
+	if fp.Events == 1 {
+		events = "event"
+	}
+	line := fmt.Sprintf("Recent events: %d %s matched this rule", fp.Events, events)
+	if !fp.Last.IsZero() {
+		line += " · last " + util.RelativeTimeAt(fp.Last, m.now())
+	}
+	return line
+}
@@ -0,0 +1,44 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func TestRulesDetailCountsRecentEvents(t *testing.T) {
+	base := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{
+		{NodeID: "node-1", Name: "allow-curl", Action: "allow", Enabled: true},
+		{NodeID: "node-1", Name: "deny-dns", Action: "deny", Enabled: true},
+	})
+	store.MergeEvents([]state.Event{
+		{NodeID: "node-1", UnixNano: base.UnixNano(), Connection: state.Connection{DstIP: "1.1.1.1"}, Rule: state.Rule{Name: "allow-curl"}},
+		{NodeID: "node-1", UnixNano: base.Add(time.Minute).UnixNano(), Connection: state.Connection{DstIP: "1.1.1.2"}, Rule: state.Rule{Name: "allow-curl"}},
+		{NodeID: "node-2", UnixNano: base.Add(time.Minute).UnixNano(), Connection: state.Connection{DstIP: "1.1.1.3"}, Rule: state.Rule{Name: "deny-dns"}},
+	})
+	m := New(store, theme.New(theme.Options{}), nil).(*Model)
+	m.now = func() time.Time { return base.Add(2 * time.Minute) }
+	m.SetSize(140, 40)
+
+	if out := m.View(); !strings.Contains(out, "Recent events: 2 events matched this rule · last 1m0s ago") {
+		t.Fatalf("expected the rule's event count, got:\n%s", out)
+	}
+	cached := m.footprint
+	m.View()
+	if m.footprint != cached {
+		t.Fatalf("expected the count to be reused between frames")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if out := m.View(); !strings.Contains(out, "Recent events: none matched this rule") {
+		t.Fatalf("expected events on other nodes not to count, got:\n%s", out)
+	}
+}
@@ -0,0 +1,50 @@
+package rules
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// freshness grades how recently a rule matched an event, for the HIT dot.
+type freshness int
+
+const (
+	// freshStale covers rules last hit before the recent threshold and
+	// rules never hit this session.
+	freshStale freshness = iota
+	freshRecent
+	freshNow
+)
+
+func ruleFreshness(last time.Time, hit bool, now time.Time, settings state.Settings) freshness {
+	if !hit {
+		return freshStale
+	}
+	fresh, recent := settings.RuleHitThresholds()
+	switch age := now.Sub(last); {
+	case age <= fresh:
+		return freshNow
+	case age <= recent:
+		return freshRecent
+	}
+	return freshStale
+}
+
+// freshnessStyle colours the HIT dot: green for rules hit within the fresh
+// threshold, yellow within the recent one, gray otherwise.
+func (m *Model) freshnessStyle(rule state.Rule) lipgloss.Style {
+	if m.store == nil {
+		return m.theme.Subtle
+	}
+	last, hit := m.store.RuleLastHit(rule.NodeID, rule.Name)
+	switch ruleFreshness(last, hit, m.now(), m.store.Snapshot().Settings) {
+	case freshNow:
+		return m.theme.Success
+	case freshRecent:
+		return m.theme.Warning
+	}
+	return m.theme.Subtle
+}
@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+func TestRulesFreshnessDotFollowsClock(t *testing.T) {
+	m, store := newPrecedenceModel(t)
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store.AppendEvents("node-1", []state.Event{{NodeID: "node-1", UnixNano: t0.UnixNano(), Rule: state.Rule{Name: "002-allow-dns"}}})
+	rules := store.Snapshot().Rules["node-1"]
+	hit, never := rules[2], rules[0]
+
+	cases := []struct {
+		after time.Duration
+		want  freshness
+	}{
+		{0, freshNow},
+		{5 * time.Minute, freshNow},
+		{5*time.Minute + time.Second, freshRecent},
+		{time.Hour, freshRecent},
+		{time.Hour + time.Second, freshStale},
+	}
+	for _, tc := range cases {
+		now := t0.Add(tc.after)
+		m.now = func() time.Time { return now }
+		last, ok := store.RuleLastHit("node-1", hit.Name)
+		if got := ruleFreshness(last, ok, now, store.Snapshot().Settings); got != tc.want {
+			t.Fatalf("after %v: freshness %d, want %d", tc.after, got, tc.want)
+		}
+	}
+
+	m.now = func() time.Time { return t0 }
+	if got := m.freshnessStyle(hit).GetForeground(); got != m.theme.Success.GetForeground() {
+		t.Fatalf("expected a green dot for a fresh hit, got %v", got)
+	}
+	m.now = func() time.Time { return t0.Add(30 * time.Minute) }
+	if got := m.freshnessStyle(hit).GetForeground(); got != m.theme.Warning.GetForeground() {
+		t.Fatalf("expected a yellow dot within the hour, got %v", got)
+	}
+	if got := m.freshnessStyle(never).GetForeground(); got != m.theme.Subtle.GetForeground() {
+		t.Fatalf("expected a gray dot for a rule never hit, got %v", got)
+	}
+	if _, ok := store.RuleLastHit("node-1", never.Name); ok {
+		t.Fatalf("expected rendering not to index rules never hit")
+	}
+}
+
+func TestRulesFreshnessThresholdsConfigurable(t *testing.T) {
+	_, store := newPrecedenceModel(t)
+	settings := store.Snapshot().Settings
+	settings.RuleHitFresh = time.Minute
+	settings.RuleHitRecent = 10 * time.Minute
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := ruleFreshness(t0, true, t0.Add(2*time.Minute), settings); got != freshRecent {
+		t.Fatalf("expected recent past a 1m fresh threshold, got %d", got)
+	}
+	if got := ruleFreshness(t0, true, t0.Add(11*time.Minute), settings); got != freshStale {
+		t.Fatalf("expected stale past a 10m recent threshold, got %d", got)
+	}
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const historyHelp = "↑/↓ pgup/pgdn scroll · esc close"
+
+// openHistory shows the selected rule's timeline, newest first.
+func (m *Model) openHistory(snapshot state.Snapshot) {
+	node, rules, ok := m.current(snapshot)
+	if !ok || len(rules) == 0 {
+		return
+	}
+	rule := rules[min(m.ruleIdx, len(rules)-1)]
+	title := fmt.Sprintf("History of %s on %s", rule.Name, util.DisplayName(node))
+	m.history = widget.NewPager(title, formatHistory(m.store.RuleHistory(node.ID, rule.Name)))
+	m.statusLine = ""
+}
+
+func (m *Model) updateHistory(key tea.KeyMsg) {
+	switch key.String() {
+	case "esc", "H":
+		m.history = nil
+	default:
+		m.history.HandleKey(key.String(), m.wireHeight())
+	}
+}
+
+// formatHistory writes one line per change: when, what changed it, and
+// the fields it touched.
+func formatHistory(changes []state.RuleChange) string {
+	if len(changes) == 0 {
+		return "No changes recorded for this rule yet."
+	}
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		line := fmt.Sprintf("%s  %-6s  %-8s", change.At.Local().Format("2006-01-02 15:04:05"), change.Source, change.Kind)
+		if summary := change.Summary(); summary != "" {
+			line += "  " + summary
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
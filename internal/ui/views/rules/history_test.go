@@ -0,0 +1,49 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func TestHistoryPanelListsRuleChanges(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "deny"}})
+	store.UpdateRule("node-1", "ssh", func(r *state.Rule) { r.Enabled = true })
+	view := New(store, theme.New(theme.Options{}), &fakeRuleController{}).(*Model)
+	view.SetSize(140, 30)
+
+	typeText(view, "H")
+	out := view.View()
+	for _, want := range []string{"History of ssh on alpha", "user    enabled", "daemon  changed   action: allow → deny", historyHelp} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the history panel, got %q", want, out)
+		}
+	}
+	if strings.Index(out, "enabled ") > strings.Index(out, "changed ") {
+		t.Fatalf("expected the newest change first, got %q", out)
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if strings.Contains(view.View(), "History of ssh") {
+		t.Fatal("expected esc to close the history panel")
+	}
+}
+
+func TestHistoryPanelWithoutChanges(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+	view := New(store, theme.New(theme.Options{}), &fakeRuleController{}).(*Model)
+	view.SetSize(140, 30)
+
+	typeText(view, "H")
+	if out := view.View(); !strings.Contains(out, "No changes recorded for this rule yet.") {
+		t.Fatalf("expected the empty history message, got %q", out)
+	}
+}
@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const importHelp = "enter import · esc cancel"
+
+// startImport asks for the rule file, or directory of them, to push to
+// the selected node. It starts from the last path imported or exported.
+func (m *Model) startImport(snapshot state.Snapshot) {
+	if _, _, ok := m.current(snapshot); !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return
+	}
+	if _, ok := m.controller.(controller.RuleImporter); !ok {
+		m.statusLine = m.theme.Warning.Render("Rule import unavailable")
+		return
+	}
+	input := textinput.New()
+	input.Prompt = "Import rules from: "
+	input.Placeholder = ruleset.DaemonRulesDir
+	input.CharLimit = 4096
+	input.Width = 48
+	input.SetValue(util.Fallback(m.importPath, m.exportDir))
+	input.Focus()
+	m.importInput = input
+	m.importing = true
+}
+
+func (m *Model) updateImport(msg tea.KeyMsg, snapshot state.Snapshot) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.importing = false
+		return nil
+	case tea.KeyEnter:
+		m.importing = false
+		m.importRules(snapshot, strings.TrimSpace(m.importInput.Value()))
+		return nil
+	}
+	var cmd tea.Cmd
+	m.importInput, cmd = m.importInput.Update(msg)
+	return cmd
+}
+
+// importRules sends the rule files at path to the selected node. Files
+// that could not be used are counted, and the first one is named.
+func (m *Model) importRules(snapshot state.Snapshot, path string) {
+	node, _, ok := m.current(snapshot)
+	if !ok || path == "" {
+		return
+	}
+	m.importPath = path
+	result, err := m.controller.(controller.RuleImporter).ImportRules(node.ID, path)
+	if err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Import failed: %v", err))
+		return
+	}
+	summary := fmt.Sprintf("Imported %d, skipped %d on %s", result.Imported, len(result.Skipped), util.DisplayName(node))
+	switch {
+	case len(result.Skipped) > 0:
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s (%v)", summary, result.Skipped[0]))
+	case result.Imported == 0:
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("No rule files in %s", path))
+	default:
+		m.statusLine = m.theme.Success.Render(summary)
+	}
+}
@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// resolveJump maps a jump query to an index into visible. The query is either a
+// 1-based row number or a case-insensitive rule-name prefix; the first match
+// wins. all holds every rule of the node so prefixes that only match hidden
+// rules can be reported instead of silently ignored.
+func resolveJump(query string, visible, all []state.Rule) (int, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return 0, fmt.Errorf("enter a row number or rule name")
+	}
+	if len(visible) == 0 {
+		return 0, fmt.Errorf("no rules to jump to")
+	}
+	if n, err := strconv.Atoi(query); err == nil {
+		if n < 1 || n > len(visible) {
+			return 0, fmt.Errorf("row %d out of range (1–%d)", n, len(visible))
+		}
+		return n - 1, nil
+	}
+	if idx := ruleset.FindPrefix(visible, query); idx >= 0 {
+		return idx, nil
+	}
+	if idx := ruleset.FindPrefix(all, query); idx >= 0 {
+		return 0, fmt.Errorf("rule %s is hidden by the current filter", all[idx].Name)
+	}
+	return 0, fmt.Errorf("no rule starting with %q", query)
+}
+
+func (m *Model) startJump() {
+	input := textinput.New()
+	input.Prompt = ": "
+	input.Placeholder = "row number or rule name"
+	input.CharLimit = 128
+	input.Width = 32
+	input.Focus()
+	m.jumpInput = input
+	m.jumping = true
+}
+
+func (m *Model) updateJump(msg tea.KeyMsg, snapshot state.Snapshot) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.jumping = false
+		return nil
+	case tea.KeyEnter:
+		m.jumping = false
+		m.jumpTo(snapshot, m.jumpInput.Value())
+		return nil
+	}
+	var cmd tea.Cmd
+	m.jumpInput, cmd = m.jumpInput.Update(msg)
+	return cmd
+}
+
+func (m *Model) jumpTo(snapshot state.Snapshot, query string) {
+	node, rules, ok := m.current(snapshot)
+	if !ok {
+		return
+	}
+	idx, err := resolveJump(query, rules, snapshot.Rules[node.ID])
+	if err != nil {
+		m.statusLine = m.theme.Warning.Render(err.Error())
+		return
+	}
+	m.ruleIdx = idx
+	// Place the target at the top of the window when possible.
+	m.tableOffset = max(0, min(idx, len(rules)-m.tableCapacity()))
+	m.statusLine = ""
+}
+
+// renderPosition describes which slice of the table is visible.
+func (m *Model) renderPosition(start, end, total int) string {
+	return m.theme.Subtle.Render(fmt.Sprintf("rows %d–%d of %d", start+1, end, total))
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func newJumpModel(t *testing.T, count int) *Model {
+	t.Helper()
+	store := state.NewStore()
+	node := state.Node{ID: "node-1", Name: "alpha"}
+	store.SetNodes([]state.Node{node})
+	store.SetRules(node.ID, makeTestRules(count))
+	m := New(store, theme.New(theme.Options{}), nil).(*Model)
+	m.SetSize(100, 20)
+	return m
+}
+
+func typeJump(m *Model, query string) {
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(query)})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestRulesPositionIndicator(t *testing.T) {
+	m := newJumpModel(t, 30)
+	if out := m.View(); !strings.Contains(out, "rows 1–8 of 30") {
+		t.Fatalf("expected position indicator, got %q", out)
+	}
+	typeJump(m, "rule-2")
+	if m.ruleIdx != 20 {
+		t.Fatalf("expected prefix jump to rule-20, got index %d", m.ruleIdx)
+	}
+	if out := m.View(); !strings.Contains(out, "rows 21–28 of 30") {
+		t.Fatalf("expected window to start at jumped row, got %q", out)
+	}
+}
+
+func TestRulesJumpByNumber(t *testing.T) {
+	m := newJumpModel(t, 30)
+	typeJump(m, "29")
+	if m.ruleIdx != 28 {
+		t.Fatalf("expected row 29 selected, got index %d", m.ruleIdx)
+	}
+	if out := m.View(); !strings.Contains(out, "rows 23–30 of 30") || !strings.Contains(out, "Name: rule-28") {
+		t.Fatalf("expected window clamped to the end, got %q", out)
+	}
+}
+
+func TestRulesJumpPrefixIsCaseInsensitive(t *testing.T) {
+	m := newJumpModel(t, 12)
+	typeJump(m, "RULE-1")
+	if m.ruleIdx != 10 {
+		t.Fatalf("expected first case-insensitive match rule-10, got index %d", m.ruleIdx)
+	}
+}
+
+func TestRulesJumpBeyondBounds(t *testing.T) {
+	m := newJumpModel(t, 30)
+	typeJump(m, "5")
+	typeJump(m, "500")
+	if m.ruleIdx != 4 {
+		t.Fatalf("expected selection to stay on row 5, got index %d", m.ruleIdx)
+	}
+	if out := m.View(); !strings.Contains(out, "row 500 out of range (1–30)") {
+		t.Fatalf("expected out-of-range status, got %q", out)
+	}
+}
+
+func TestResolveJumpHiddenRule(t *testing.T) {
+	all := makeTestRules(4)
+	visible := []state.Rule{all[0], all[2]}
+	if _, err := resolveJump("rule-01", visible, all); err == nil || !strings.Contains(err.Error(), "hidden") {
+		t.Fatalf("expected hidden-rule error, got %v", err)
+	}
+	if _, err := resolveJump("nope", visible, all); err == nil || !strings.Contains(err.Error(), "no rule starting") {
+		t.Fatalf("expected no-match error, got %v", err)
+	}
+	if idx, err := resolveJump("rule-02", visible, all); err != nil || idx != 1 {
+		t.Fatalf("expected visible index 1, got %d (%v)", idx, err)
+	}
+}
+
+func TestRulesJumpEscCancels(t *testing.T) {
+	m := newJumpModel(t, 10)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("7")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.jumping || m.ruleIdx != 0 {
+		t.Fatalf("expected esc to cancel jump without moving, got jumping=%v idx=%d", m.jumping, m.ruleIdx)
+	}
+}
@@ -0,0 +1,181 @@
+package rules
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// keyContext is what the rules actions work on.
+type keyContext struct {
+	m        *Model
+	snapshot state.Snapshot
+}
+
+// action is one entry of a rules key table.
+type action = keymap.Action[keyContext]
+
+// tableKeys are the keys of the rules table.
+var tableKeys = keymap.NewTable(
+	action{ID: "rules.scroll-left", Keys: []string{"left", "h"}, Help: "scroll left", Run: keymap.Do(func(c keyContext) { c.m.adjustTableX(-4) })},
+	action{ID: "rules.scroll-right", Keys: []string{"right", "l"}, Help: "scroll right", Run: keymap.Do(func(c keyContext) { c.m.adjustTableX(4) })},
+	action{ID: "rules.prev-node", Keys: []string{"["}, Help: "previous node", Run: keymap.Do(func(c keyContext) {
+		if c.m.nodeIdx > 0 {
+			c.m.nodeIdx--
+			c.m.resetTable()
+		}
+	})},
+	action{ID: "rules.next-node", Keys: []string{"]"}, Help: "next node", Run: keymap.Do(func(c keyContext) {
+		if nodes := c.snapshot.Nodes; len(nodes) > 0 && c.m.nodeIdx < len(nodes)-1 {
+			c.m.nodeIdx++
+			c.m.resetTable()
+		}
+	})},
+	action{ID: "rules.up", Keys: []string{"up", "k"}, Help: "previous rule", Run: keymap.Do(func(c keyContext) {
+		if c.m.ruleIdx > 0 {
+			c.m.ruleIdx--
+		}
+	})},
+	action{ID: "rules.down", Keys: []string{"down", "j"}, Help: "next rule", Run: keymap.Do(func(c keyContext) {
+		if _, rules, ok := c.m.current(c.snapshot); ok && c.m.ruleIdx < len(rules)-1 {
+			c.m.ruleIdx++
+		}
+	})},
+	action{ID: "rules.enable", Keys: []string{"e"}, Help: "enable the rule", Run: keymap.Do(func(c keyContext) { c.m.requestToggle(c.snapshot, true) })},
+	action{ID: "rules.disable", Keys: []string{"d"}, Help: "disable the rule", Run: keymap.Do(func(c keyContext) { c.m.requestToggle(c.snapshot, false) })},
+	action{ID: "rules.delete", Keys: []string{"x", "delete"}, Help: "delete the rule", Run: keymap.Do(func(c keyContext) { c.m.requestDelete(c.snapshot) })},
+	action{ID: "rules.create", Keys: []string{"n"}, Help: "create a rule", Run: keymap.Do(func(c keyContext) { c.m.openCreate(c.snapshot) })},
+	action{ID: "rules.modify", Keys: []string{"m"}, Help: "modify the rule", Run: keymap.Do(func(c keyContext) { c.m.startEdit(c.snapshot) })},
+	action{ID: "rules.jump", Keys: []string{":"}, Help: "jump to a rule", Run: keymap.Do(func(c keyContext) { c.m.startJump() })},
+	action{ID: "rules.filter", Keys: []string{"/"}, Help: "filter the rules", Run: keymap.Do(func(c keyContext) { c.m.startFilter() })},
+	action{ID: "rules.stop-bulk", Keys: []string{"esc"}, Help: "stop or dismiss the copy", Enabled: bulkShown, Run: keymap.Do(func(c keyContext) { c.m.stopBulk(c.snapshot) })},
+	action{ID: "rules.clear-filter", Keys: []string{"esc"}, Help: "clear the filter", Enabled: filtered, Run: keymap.Do(func(c keyContext) { c.m.clearFilter(c.snapshot) })},
+	action{ID: "rules.hide-disabled", Keys: []string{"z"}, Help: "hide disabled rules", Run: keymap.Do(func(c keyContext) { c.m.toggleHideDisabled(c.snapshot) })},
+	action{ID: "rules.session-only", Keys: []string{"w"}, Help: "show session rules only", Run: keymap.Do(func(c keyContext) { c.m.toggleSessionOnly(c.snapshot) })},
+	action{ID: "rules.sort", Keys: []string{"s"}, Help: "cycle the sort order", Run: keymap.Do(func(c keyContext) {
+		c.m.keepSelection(c.snapshot, func() { c.m.sort = (c.m.sort + 1) % sortCount })
+	})},
+	action{ID: "rules.grow", Keys: []string{"+"}, Help: "grow the table", Run: keymap.Do(func(c keyContext) { c.m.resizeTable(0.1) })},
+	action{ID: "rules.shrink", Keys: []string{"-"}, Help: "shrink the table", Run: keymap.Do(func(c keyContext) { c.m.resizeTable(-0.1) })},
+	action{ID: "rules.export", Keys: []string{"P"}, Help: "export the table", Run: func(c keyContext) tea.Cmd { return c.m.exportTable(c.snapshot) }},
+	action{ID: "rules.export-files", Keys: []string{"E"}, Help: "export rule files", Run: keymap.Do(func(c keyContext) { c.m.startExport(c.snapshot) })},
+	action{ID: "rules.import-files", Keys: []string{"I"}, Help: "import rule files", Run: keymap.Do(func(c keyContext) { c.m.startImport(c.snapshot) })},
+	action{ID: "rules.copy-to-nodes", Keys: []string{"C"}, Help: "copy the rule to all nodes", Run: func(c keyContext) tea.Cmd { return c.m.copyToNodes(c.snapshot) }},
+	action{ID: "rules.starter", Keys: []string{"S"}, Help: "starter rules", Run: keymap.Do(func(c keyContext) { c.m.openStarter(c.snapshot) })},
+	action{ID: "rules.trash", Keys: []string{"Z"}, Help: "open the trash", Run: keymap.Do(func(c keyContext) { c.m.openTrash(c.snapshot) })},
+	action{ID: "rules.undo", Keys: []string{"u"}, Help: "undo the last delete", Run: keymap.Do(func(c keyContext) { c.m.undoDelete(c.snapshot) })},
+	action{ID: "rules.history", Keys: []string{"H"}, Help: "show the rule's change history", Run: keymap.Do(func(c keyContext) { c.m.openHistory(c.snapshot) })},
+	action{ID: "rules.wire", Keys: []string{"ctrl+x"}, Help: "show the rule on the wire", Run: keymap.Do(func(c keyContext) { c.m.openWire(c.snapshot) })},
+)
+
+// tableHints is the rules table's help line. Each hint names the actions
+// it stands for, so those needing a controller are found by ID.
+var tableHints = []struct {
+	text string
+	ids  []string
+}{
+	{"←/→ scroll", []string{"rules.scroll-left", "rules.scroll-right"}},
+	{"[/] nodes", []string{"rules.prev-node", "rules.next-node"}},
+	{"↑/↓ rules", []string{"rules.up", "rules.down"}},
+	{"e enable", []string{"rules.enable"}},
+	{"d disable", []string{"rules.disable"}},
+	{"x delete", []string{"rules.delete"}},
+	{"u undo delete", []string{"rules.undo"}},
+	{"n new rule", []string{"rules.create"}},
+	{"m modify", []string{"rules.modify"}},
+	{": jump", []string{"rules.jump"}},
+	{"/ filter", []string{"rules.filter"}},
+	{"z disabled", []string{"rules.hide-disabled"}},
+	{"w new", []string{"rules.session-only"}},
+	{"s sort", []string{"rules.sort"}},
+	{"+/- size", []string{"rules.grow", "rules.shrink"}},
+	{"P export", []string{"rules.export"}},
+	{"E export JSON", []string{"rules.export-files"}},
+	{"I import JSON", []string{"rules.import-files"}},
+	{"C copy to all nodes", []string{"rules.copy-to-nodes"}},
+	{"S starter", []string{"rules.starter"}},
+	{"Z trash", []string{"rules.trash"}},
+	{"H history", []string{"rules.history"}},
+	{"ctrl+x wire", []string{"rules.wire"}},
+}
+
+// controllerActions are the table actions that report
+// controller.ErrNoController when the view has no controller.
+var controllerActions = map[string]bool{
+	"rules.enable":        true,
+	"rules.disable":       true,
+	"rules.delete":        true,
+	"rules.undo":          true,
+	"rules.create":        true,
+	"rules.modify":        true,
+	"rules.export-files":  true,
+	"rules.import-files":  true,
+	"rules.copy-to-nodes": true,
+	"rules.starter":       true,
+	"rules.trash":         true,
+	"rules.wire":          true,
+}
+
+// tableHelp returns the table's help line and the hints to strike through
+// without a controller.
+func tableHelp() (string, []string) {
+	texts := make([]string, 0, len(tableHints))
+	var disabled []string
+	for _, hint := range tableHints {
+		texts = append(texts, hint.text)
+		if slices.ContainsFunc(hint.ids, func(id string) bool { return controllerActions[id] }) {
+			disabled = append(disabled, hint.text)
+		}
+	}
+	return strings.Join(texts, " · "), disabled
+}
+
+// filtered enables clearing an applied filter.
+func filtered(c keyContext) bool { return c.m.filterQuery() != "" }
+
+// offDescription enables n/p, which stand in for →/← off the description
+// field.
+func offDescription(c keyContext) bool { return c.m.editFocus != editFieldDescription }
+
+// editKeys are the keys of the edit form; keys it leaves go to the
+// description field.
+var editKeys = keymap.NewTable(
+	action{ID: "rules.edit.cancel", Keys: []string{"esc"}, Help: "cancel", Run: keymap.Do(func(c keyContext) { c.m.cancelEdit() })},
+	action{ID: "rules.edit.save", Keys: []string{"enter"}, Help: "save", Run: keymap.Do(func(c keyContext) { c.m.submitEdit(c.snapshot) })},
+	action{ID: "rules.edit.next-field", Keys: []string{"tab", "down"}, Help: "next field", Run: keymap.Do(func(c keyContext) { c.m.cycleEditFocus(1) })},
+	action{ID: "rules.edit.prev-field", Keys: []string{"shift+tab", "up"}, Help: "previous field", Run: keymap.Do(func(c keyContext) { c.m.cycleEditFocus(-1) })},
+	action{ID: "rules.edit.prev-option", Keys: []string{"left"}, Help: "previous option", Run: keymap.Do(func(c keyContext) { c.m.adjustEditSelection(-1) })},
+	action{ID: "rules.edit.next-option", Keys: []string{"right"}, Help: "next option", Run: keymap.Do(func(c keyContext) { c.m.adjustEditSelection(1) })},
+	action{ID: "rules.edit.next-option-letter", Keys: []string{"n"}, Help: "next option", Enabled: offDescription, Run: keymap.Do(func(c keyContext) { c.m.adjustEditSelection(1) })},
+	action{ID: "rules.edit.prev-option-letter", Keys: []string{"p"}, Help: "previous option", Enabled: offDescription, Run: keymap.Do(func(c keyContext) { c.m.adjustEditSelection(-1) })},
+)
+
+// onCreateOption enables ←/→ and n/p on the new-rule form's option rows;
+// on its text fields they edit the text.
+func onCreateOption(c keyContext) bool { return c.m.create.input() == nil }
+
+// createKeys are the keys of the new-rule form; keys it leaves go to the
+// focused text field.
+var createKeys = keymap.NewTable(
+	action{ID: "rules.create.cancel", Keys: []string{"esc"}, Help: "cancel", Run: keymap.Do(func(c keyContext) { c.m.create = nil })},
+	action{ID: "rules.create.submit", Keys: []string{"enter"}, Help: "create", Run: keymap.Do(func(c keyContext) { c.m.submitCreate(c.snapshot) })},
+	action{ID: "rules.create.next-field", Keys: []string{"tab", "down"}, Help: "next field", Run: keymap.Do(func(c keyContext) { c.m.cycleCreateFocus(1) })},
+	action{ID: "rules.create.prev-field", Keys: []string{"shift+tab", "up"}, Help: "previous field", Run: keymap.Do(func(c keyContext) { c.m.cycleCreateFocus(-1) })},
+	action{ID: "rules.create.prev-option", Keys: []string{"left", "p"}, Help: "previous option", Enabled: onCreateOption, Run: keymap.Do(func(c keyContext) { c.m.adjustCreateSelection(-1) })},
+	action{ID: "rules.create.next-option", Keys: []string{"right", "n"}, Help: "next option", Enabled: onCreateOption, Run: keymap.Do(func(c keyContext) { c.m.adjustCreateSelection(1) })},
+)
+
+// resetTable scrolls back to the first rule after switching nodes.
+func (m *Model) resetTable() {
+	m.ruleIdx = 0
+	m.tableOffset = 0
+	m.tableXOffset = 0
+}
+
+// KeyHelp lists the rules table keys for the help overlay, which does not
+// open over the forms.
+func (m *Model) KeyHelp() []keymap.Help { return tableKeys.Help() }
@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// ruleSort orders the table. sortDaemon keeps the order the daemon sent,
+// which is the order it evaluates priority rules in.
+type ruleSort int
+
+const (
+	sortDaemon ruleSort = iota
+	sortName
+	sortAction
+	sortCount
+)
+
+func (s ruleSort) String() string {
+	switch s {
+	case sortName:
+		return "name"
+	case sortAction:
+		return "action"
+	}
+	return "daemon"
+}
+
+// sorted returns rules in the order s asks for; ties keep daemon order.
+func (s ruleSort) sorted(rules []state.Rule) []state.Rule {
+	if s == sortDaemon {
+		return rules
+	}
+	out := slices.Clone(rules)
+	slices.SortStableFunc(out, func(a, b state.Rule) int {
+		if s == sortAction {
+			if c := cmp.Compare(a.Action, b.Action); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return out
+}
+
+// keepSelection runs change, which alters which rules are shown or their
+// order, and keeps the cursor on the same rule when it is still visible.
+func (m *Model) keepSelection(snapshot state.Snapshot, change func()) {
+	selected := ""
+	if _, rules, ok := m.current(snapshot); ok && len(rules) > 0 {
+		selected = rules[min(m.ruleIdx, len(rules)-1)].Name
+	}
+	change()
+	_, rules, _ := m.current(snapshot)
+	if idx := ruleset.Find(rules, selected); idx >= 0 {
+		m.ruleIdx = idx
+	} else {
+		m.ruleIdx = 0
+		m.tableOffset = 0
+	}
+	m.clampSelection(snapshot)
+}
+
+// resizeTable grows or shrinks the table's share of the view height by
+// delta, starting from the automatic size when no share is set.
+func (m *Model) resizeTable(delta float64) {
+	share := m.tableShare
+	if share == 0 {
+		share = math.Round(float64(m.tableCapacity())/float64(max(1, m.height))*10) / 10
+	}
+	m.tableShare = min(config.MaxTableShare, max(config.MinTableShare, share+delta))
+	m.tableShare = math.Round(m.tableShare*10) / 10
+}
+
+// ApplyProfile switches to the profile's disabled-rule filter, sort and
+// table size, keeping the selected rule when it stays visible.
+func (m *Model) ApplyProfile(profile state.Profile) {
+	layout := profile.Rules
+	m.keepSelection(m.store.Snapshot(), func() {
+		m.hideDisabled = layout.HideDisabled
+		m.sort = sortDaemon
+		for s := range sortCount {
+			if s.String() == layout.Sort {
+				m.sort = s
+			}
+		}
+	})
+	m.tableShare = layout.TableShare
+}
+
+// CaptureProfile records the current layout into profile.
+func (m *Model) CaptureProfile(profile *state.Profile) {
+	profile.Rules = state.RulesLayout{
+		HideDisabled: m.hideDisabled,
+		Sort:         m.sort.String(),
+		TableShare:   m.tableShare,
+	}
+}
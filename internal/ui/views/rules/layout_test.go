@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func layoutFixture() *Model {
+	store := state.NewStore()
+	node := state.Node{ID: "node-1", Name: "alpha"}
+	store.SetNodes([]state.Node{node})
+	store.SetRules(node.ID, []state.Rule{
+		{Name: "zoom", Action: "allow", Enabled: true},
+		{Name: "apt", Action: "deny", Enabled: true},
+		{Name: "old", Action: "allow"},
+		{Name: "curl", Action: "allow", Enabled: true},
+	})
+	m := New(store, theme.New(theme.Options{}), nil).(*Model)
+	m.SetSize(100, 20)
+	return m
+}
+
+func shownNames(m *Model) []string {
+	_, rules, _ := m.current(m.store.Snapshot())
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.Name
+	}
+	return names
+}
+
+func pressKey(m *Model, key string) {
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+}
+
+func TestRulesSortKeepsSelection(t *testing.T) {
+	m := layoutFixture()
+	m.Update(tea.KeyMsg{Type: tea.KeyDown}) // apt
+
+	pressKey(m, "s")
+	if got := shownNames(m); !reflect.DeepEqual(got, []string{"apt", "curl", "old", "zoom"}) {
+		t.Fatalf("expected rules sorted by name, got %v", got)
+	}
+	if m.ruleIdx != 0 {
+		t.Fatalf("expected the cursor to stay on apt, got %d", m.ruleIdx)
+	}
+	pressKey(m, "s")
+	if got := shownNames(m); !reflect.DeepEqual(got, []string{"curl", "old", "zoom", "apt"}) {
+		t.Fatalf("expected rules sorted by action then name, got %v", got)
+	}
+	pressKey(m, "s")
+	if got := shownNames(m); !reflect.DeepEqual(got, []string{"zoom", "apt", "old", "curl"}) {
+		t.Fatalf("expected daemon order back, got %v", got)
+	}
+}
+
+func TestRulesProfileRoundTrip(t *testing.T) {
+	m := layoutFixture()
+	pressKey(m, "z")
+	pressKey(m, "s")
+	pressKey(m, "-") // from the automatic 0.4
+	pressKey(m, "-")
+	var profile state.Profile
+	m.CaptureProfile(&profile)
+	want := state.RulesLayout{HideDisabled: true, Sort: "name", TableShare: 0.2}
+	if profile.Rules != want {
+		t.Fatalf("unexpected capture %+v", profile.Rules)
+	}
+
+	other := layoutFixture()
+	other.ApplyProfile(profile)
+	var again state.Profile
+	other.CaptureProfile(&again)
+	if again.Rules != want {
+		t.Fatalf("expected the applied layout captured back, got %+v", again.Rules)
+	}
+	if got := shownNames(other); !reflect.DeepEqual(got, []string{"apt", "curl", "zoom"}) {
+		t.Fatalf("expected enabled rules by name, got %v", got)
+	}
+	if other.tableCapacity() != 4 {
+		t.Fatalf("expected a fifth of the height for the table, got %d rows", other.tableCapacity())
+	}
+
+	other.ApplyProfile(state.Profile{})
+	if got := shownNames(other); !reflect.DeepEqual(got, []string{"zoom", "apt", "old", "curl"}) {
+		t.Fatalf("expected an empty profile to restore the defaults, got %v", got)
+	}
+}
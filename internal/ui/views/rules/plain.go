@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// DefaultPlainWidth is the layout width used when dumping tables outside the TUI.
+const DefaultPlainWidth = 160
+
+// RenderPlainTable renders every rule using the table's column layout for the
+// given width, without ANSI styling and without the viewport height cap.
+func RenderPlainTable(rules []state.Rule, width int) string {
+	m := &Model{theme: theme.New(theme.Options{}), width: width}
+	return m.plainTable(rules)
+}
+
+func (m *Model) plainTable(rules []state.Rule) string {
+	layout := m.tableColumns()
+	gap := strings.Repeat(" ", columnGap)
+	rows := make([]string, 0, len(rules)+1)
+	rows = append(rows, m.renderTableHeader(layout, gap))
+	for idx, rule := range rules {
+		rows = append(rows, m.renderRuleRow(layout, rule, idx, false, gap))
+	}
+	// The cursor and the last-hit dot carry no information outside the TUI.
+	skip := layout.cursor + layout.hit + 2*columnGap
+	var b strings.Builder
+	for _, row := range rows {
+		plain := util.StripANSI(row)
+		if runes := []rune(plain); len(runes) > skip {
+			plain = string(runes[skip:])
+		}
+		b.WriteString(strings.TrimRight(plain, " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// exportTable copies the current node's rules, with the active filters, to
+// the clipboard, or writes them to a file under the state directory when
+// the terminal is not expected to take OSC 52.
+func (m *Model) exportTable(snapshot state.Snapshot) tea.Cmd {
+	node, rules, ok := m.current(snapshot)
+	if !ok || len(rules) == 0 {
+		m.statusLine = m.theme.Warning.Render("No rules to export")
+		return nil
+	}
+	text := m.plainTable(rules)
+	if clipboard.Supported() {
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("Copied %d rules to clipboard", len(rules)))
+		return clipboard.Copy(text)
+	}
+	dir, err := persist.StateDir()
+	if err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Export failed: %v", err))
+		return nil
+	}
+	path := filepath.Join(dir, dumpFileName(node, m.now()))
+	if err := m.writeFile(path, []byte(text)); err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Export failed: %v", err))
+		return nil
+	}
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Wrote %d rules to %s (no OSC 52 in this terminal)", len(rules), path))
+	return nil
+}
+
+func dumpFileName(node state.Node, now time.Time) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, util.Fallback(util.DisplayName(node), node.ID))
+	return fmt.Sprintf("opensnitch-tui-rules-%s-%s.txt", name, now.Format("20060102-150405"))
+}
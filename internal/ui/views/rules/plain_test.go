@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/view/viewtest"
+)
+
+func TestRenderPlainTableGolden(t *testing.T) {
+	out := RenderPlainTable(makeTestRules(12), 120)
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("expected no ANSI sequences in plain output")
+	}
+	viewtest.AssertSnapshot(t, out, filepath.Join("testdata", "rules_plain.snap"))
+}
+
+func TestRulesExportKeyWritesVisibleRules(t *testing.T) {
+	t.Setenv("TERM", "linux")
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	m := newJumpModel(t, 12)
+	var gotPath string
+	var gotData []byte
+	m.writeFile = func(name string, data []byte) error {
+		gotPath, gotData = name, data
+		return nil
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+
+	if filepath.Dir(gotPath) != filepath.Join(dir, "opensnitch-tui") || !strings.HasPrefix(filepath.Base(gotPath), "opensnitch-tui-rules-alpha-") {
+		t.Fatalf("expected the export under the state directory, got %q", gotPath)
+	}
+	lines := strings.Split(strings.TrimRight(string(gotData), "\n"), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("expected header plus 6 enabled rules unwindowed, got %d lines:\n%s", len(lines), gotData)
+	}
+	if strings.Contains(string(gotData), "rule-01") {
+		t.Fatalf("expected hidden disabled rules to be left out:\n%s", gotData)
+	}
+	if out := m.View(); !strings.Contains(out, "Wrote 6 rules") {
+		t.Fatalf("expected export status line, got %q", out)
+	}
+}
+
+func TestRulesExportKeyCopiesOverOSC52(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	m := newJumpModel(t, 12)
+	m.writeFile = func(string, []byte) error {
+		t.Fatalf("expected no file written when the terminal takes OSC 52")
+		return nil
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	if cmd == nil {
+		t.Fatalf("expected a clipboard command")
+	}
+	msg, ok := cmd().(clipboard.Msg)
+	if !ok || !strings.Contains(msg.Text, "rule-01") {
+		t.Fatalf("expected the table on the clipboard, got %#v", msg)
+	}
+	if out := m.View(); !strings.Contains(out, "Copied 12 rules to clipboard") {
+		t.Fatalf("expected copy status line, got %q", out)
+	}
+}
@@ -0,0 +1,59 @@
+package rules
+
+import (
+	"fmt"
+
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// maxOverlapLines bounds the overlap list in the detail pane.
+const maxOverlapLines = 3
+
+// analysisFor returns the precedence analysis of a node's rules, recomputed
+// only when the rules revision moves.
+func (m *Model) analysisFor(snapshot state.Snapshot, nodeID string) ruleset.Analysis {
+	if m.analyses == nil || m.analysesRev != snapshot.RulesRevision {
+		m.analyses = make(map[string]ruleset.Analysis)
+		m.analysesRev = snapshot.RulesRevision
+	}
+	analysis, ok := m.analyses[nodeID]
+	if !ok {
+		analysis = ruleset.Analyze(snapshot.Rules[nodeID])
+		m.analyses[nodeID] = analysis
+	}
+	return analysis
+}
+
+// precedenceLines explains how the rule interacts with overlapping rules on
+// its node. Disabled rules take no part in evaluation and get no lines.
+func (m *Model) precedenceLines(rule state.Rule, width int) []string {
+	if !rule.Enabled {
+		return nil
+	}
+	analysis := m.analyses[rule.NodeID]
+	var lines []string
+	if by, shadowed := analysis.ShadowedBy(rule.Name); shadowed {
+		line := fmt.Sprintf("Shadowed: never applies; %s matches everything it does and wins", by)
+		lines = append(lines, m.theme.Warning.Render(util.TruncateString(line, width)))
+	}
+	overlaps := analysis.Overlaps(rule.Name)
+	if len(overlaps) == 0 {
+		return lines
+	}
+	lines = append(lines, "Overlaps:")
+	for idx, overlap := range overlaps {
+		if idx == maxOverlapLines {
+			lines = append(lines, m.theme.Subtle.Render(fmt.Sprintf("  …and %d more", len(overlaps)-idx)))
+			break
+		}
+		outcome := "this rule wins"
+		if overlap.Wins {
+			outcome = "wins over this rule"
+		}
+		line := fmt.Sprintf("  %s (%s): %s", overlap.Rule.Name, overlap.Rule.Action, outcome)
+		lines = append(lines, util.TruncateString(line, width))
+	}
+	return lines
+}
@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func newPrecedenceModel(t *testing.T) (*Model, *state.Store) {
+	t.Helper()
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{
+		{NodeID: "node-1", Name: "000-deny-curl", Action: "deny", Enabled: true,
+			Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"}},
+		{NodeID: "node-1", Name: "001-allow-curl-https", Action: "allow", Enabled: true,
+			Operator: state.RuleOperator{Type: "list", Operand: "list", Children: []state.RuleOperator{
+				{Type: "simple", Operand: "process.path", Data: "/usr/bin/curl"},
+				{Type: "simple", Operand: "dest.port", Data: "443"},
+			}}},
+		{NodeID: "node-1", Name: "002-allow-dns", Action: "allow", Enabled: true,
+			Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "53"}},
+	})
+	m := New(store, theme.New(theme.Options{}), nil).(*Model)
+	m.SetSize(140, 40)
+	return m, store
+}
+
+func TestRulesShadowedBadgeAndExplainer(t *testing.T) {
+	m, _ := newPrecedenceModel(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	out := m.View()
+	if !strings.Contains(out, "shadowed") {
+		t.Fatalf("expected shadowed badge, got %q", out)
+	}
+	if !strings.Contains(out, "Shadowed: never applies; 000-deny-curl") {
+		t.Fatalf("expected shadow explanation, got %q", out)
+	}
+	if !strings.Contains(out, "000-deny-curl (deny): wins over this rule") {
+		t.Fatalf("expected overlap entry, got %q", out)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	out = m.View()
+	if !strings.Contains(out, "001-allow-curl-https (allow): this rule wins") {
+		t.Fatalf("expected reverse overlap entry, got %q", out)
+	}
+	if strings.Contains(out, "Shadowed:") {
+		t.Fatalf("broader rule must not be marked shadowed, got %q", out)
+	}
+}
+
+func TestRulesPrecedenceCacheFollowsRuleChanges(t *testing.T) {
+	m, store := newPrecedenceModel(t)
+	m.View()
+	if _, ok := m.analyses["node-1"].ShadowedBy("001-allow-curl-https"); !ok {
+		t.Fatalf("expected cached analysis to report the shadowed rule")
+	}
+	if !store.RemoveRule("node-1", "000-deny-curl") {
+		t.Fatalf("RemoveRule reported no rule removed")
+	}
+	out := m.View()
+	if strings.Contains(out, "shadowed") {
+		t.Fatalf("expected badge to clear after the shadowing rule is removed, got %q", out)
+	}
+}
+
+func TestRulesDetailShowsListOperatorTree(t *testing.T) {
+	m, _ := newPrecedenceModel(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	out := m.View()
+	for _, want := range []string{
+		"(simple process.path /usr/bin/curl ∧ simple d",
+		"Operator: ∧ all of",
+		"├─ simple process.path /usr/bin/curl",
+		"└─ simple dest.port 443",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in view, got %q", want, out)
+		}
+	}
+}
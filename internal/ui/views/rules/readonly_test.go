@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestRulesWithoutControllerAreReadOnly(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{
+		{ID: "node-1", Name: "alpha", Status: state.NodeStatusReady},
+		{ID: "node-2", Name: "beta", Status: state.NodeStatusReady},
+	})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow", Enabled: true, Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"}}})
+	var typedNil *copyController
+	m := New(store, theme.New(theme.Options{}), typedNil).(*Model)
+	m.SetSize(160, 30)
+	want := controller.ErrNoController.Error()
+	if out := util.StripANSI(m.View()); !strings.Contains(out, want) {
+		t.Fatalf("expected the read-only line before any key, got %q", out)
+	}
+
+	keys := []tea.KeyMsg{{Type: tea.KeyCtrlX}}
+	for _, r := range "edxunmEICSZ" {
+		keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	for _, key := range keys {
+		m.statusLine = ""
+		if _, cmd := m.Update(key); cmd != nil {
+			t.Fatalf("%s: expected no command without a controller", key)
+		}
+		if got := util.StripANSI(m.statusLine); got != want {
+			t.Fatalf("%s: expected %q, got %q", key, want, got)
+		}
+		if m.editing || m.create != nil || m.exporting || m.importing || m.starter != nil || m.trash != nil || m.wire != nil || m.bulk != nil {
+			t.Fatalf("%s: expected no mode to open without a controller", key)
+		}
+	}
+}
+
+func TestTableHintsNameTableActions(t *testing.T) {
+	ids := map[string]bool{}
+	for _, help := range tableKeys.Help() {
+		ids[help.ID] = true
+	}
+	hinted := map[string]bool{}
+	for _, hint := range tableHints {
+		for _, id := range hint.ids {
+			if !ids[id] {
+				t.Fatalf("hint %q names unknown action %s", hint.text, id)
+			}
+			hinted[id] = true
+		}
+	}
+	for id := range controllerActions {
+		if !hinted[id] {
+			t.Fatalf("controller action %s has no hint to strike", id)
+		}
+	}
+	for id := range ids {
+		if !hinted[id] && id != "rules.stop-bulk" && id != "rules.clear-filter" {
+			t.Fatalf("action %s missing from the help line", id)
+		}
+	}
+
+	_, disabled := tableHelp()
+	want := []string{"e enable", "d disable", "x delete", "u undo delete", "n new rule", "m modify", "E export JSON", "I import JSON", "C copy to all nodes", "S starter", "Z trash", "ctrl+x wire"}
+	if strings.Join(disabled, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %q struck without a controller, got %q", want, disabled)
+	}
+}
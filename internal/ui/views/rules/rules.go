@@ -1,7 +1,9 @@
 package rules
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -10,9 +12,13 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/persist"
+	"github.com/adamkadaban/opensnitch-tui/internal/rulecache"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/components/table"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/glyphs"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
 	"github.com/adamkadaban/opensnitch-tui/internal/util"
@@ -38,19 +44,88 @@ type Model struct {
 	editFocus      int
 	editInputs     []textinput.Model
 	editRuleName   string
+	editRuleHash   string
 	editActionIdx  int
 	editDurIdx     int
 	editNoLog      bool
 	editPrecedence bool
+
+	jumping   bool
+	jumpInput textinput.Model
+
+	// exporting is set while the export directory is typed; exportDir is
+	// the last one used.
+	exporting   bool
+	exportInput textinput.Model
+	exportDir   string
+
+	// importing is set while the path to import is typed; importPath is
+	// the last one used.
+	importing   bool
+	importInput textinput.Model
+	importPath  string
+
+	// filter narrows the table to rules matching its text; filtering is
+	// set while it is being typed.
+	filter    textinput.Model
+	filtering bool
+
+	hideDisabled bool
+	// sessionOnly shows only the rules created this session.
+	sessionOnly bool
+	// sort orders the table; tableShare is the fraction of the height
+	// given to it, zero sizing it automatically.
+	sort       ruleSort
+	tableShare float64
+
+	// starter is the open starter-rules checklist, if any.
+	starter *starterState
+	// trash is the open trash of the selected node, if any.
+	trash *trashState
+	// create is the open new-rule form, if any.
+	create *createState
+
+	// shown is the selected rule as last rendered; actions send its hash so
+	// the controller can refuse them if a refresh replaced the rule since.
+	shown shownRule
+
+	// analyses caches precedence analysis per node for analysesRev.
+	analyses    map[string]ruleset.Analysis
+	analysesRev uint64
+	// footprint caches how many recent events the selected rule matched.
+	footprint *footprintCache
+
+	// attached is false when the view was built without a controller;
+	// actions then report controller.ErrNoController.
+	attached bool
+
+	// inspector is set when the controller can render rules as prototext;
+	// wire is the open wire view, if any.
+	inspector controller.WireInspector
+	wire      *widget.Pager
+	// history is the open timeline of the selected rule, if any.
+	history *widget.Pager
+
+	// awaiting is the last requested action until its daemon ack arrives.
+	awaiting *ackWait
+
+	// bulk is the copy to other nodes last started here, shown until
+	// dismissed; bulkSeq numbers them.
+	bulk    *bulkRun
+	bulkSeq int
+
+	writeFile func(path string, data []byte) error
+	now       func() time.Time
 }
 
 const (
 	defaultTableRows   = 5
 	minTableRows       = 3
 	maxTableRows       = 8
-	tableChrome        = 8
+	tableChrome        = 10
 	columnGap          = 1
 	minCursorWidth     = 2
+	minHitWidth        = 3
 	minNameWidth       = 8
 	minActionWidth     = 6
 	minDurationWidth   = 8
@@ -83,8 +158,13 @@ var ruleDurationOptions = []widget.Option{
 	{Label: "Always", Value: "always"},
 }
 
+type shownRule struct {
+	nodeID, name, hash string
+}
+
 type tableLayout struct {
 	cursor     int
+	hit        int
 	name       int
 	action     int
 	duration   int
@@ -95,13 +175,18 @@ type tableLayout struct {
 }
 
 func (tl tableLayout) total() int {
-	return tl.cursor + tl.name + tl.action + tl.duration + tl.status + tl.precedence + tl.noLog + tl.operator
+	return tl.cursor + tl.hit + tl.name + tl.action + tl.duration + tl.status + tl.precedence + tl.noLog + tl.operator
 }
 
-func (tl tableLayout) count() int { return 8 }
+func (tl tableLayout) count() int { return 9 }
 
 func New(store *state.Store, th theme.Theme, ctrl controller.RuleManager) view.Model {
-	return &Model{store: store, theme: th, controller: ctrl}
+	attached := controller.Attached(ctrl)
+	if !attached {
+		ctrl = nil
+	}
+	inspector, _ := ctrl.(controller.WireInspector)
+	return &Model{store: store, theme: th, controller: ctrl, attached: attached, inspector: inspector, filter: newFilterInput(), writeFile: persist.WriteFile, now: time.Now}
 }
 
 func (m *Model) Init() tea.Cmd { return nil }
@@ -112,34 +197,40 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch key := msg.(type) {
 	case tea.KeyMsg:
+		if m.jumping {
+			return m, m.updateJump(key, snapshot)
+		}
+		if m.filtering {
+			return m, m.updateFilter(key, snapshot)
+		}
+		if m.exporting {
+			return m, m.updateExport(key, snapshot)
+		}
+		if m.importing {
+			return m, m.updateImport(key, snapshot)
+		}
+		if m.wire != nil {
+			return m, m.updateWire(key)
+		}
+		if m.history != nil {
+			m.updateHistory(key)
+			return m, nil
+		}
+		if m.starter != nil {
+			m.updateStarter(key, snapshot)
+			return m, nil
+		}
+		if m.trash != nil {
+			m.updateTrash(key, snapshot)
+			return m, nil
+		}
+		if m.create != nil {
+			return m, m.updateCreate(key, snapshot)
+		}
 		if m.editing {
-			switch key.Type {
-			case tea.KeyEsc:
-				m.cancelEdit()
-				return m, nil
-			case tea.KeyEnter:
-				m.submitEdit(snapshot)
-				return m, nil
-			case tea.KeyTab:
-				m.cycleEditFocus(1)
-				return m, nil
-			case tea.KeyShiftTab:
-				m.cycleEditFocus(-1)
-				return m, nil
-			}
-			switch key.String() {
-			case "up":
-				m.cycleEditFocus(-1)
-				return m, nil
-			case "down":
-				m.cycleEditFocus(1)
-				return m, nil
-			case "left":
-				m.adjustEditSelection(-1)
-				return m, nil
-			case "right":
-				m.adjustEditSelection(1)
-				return m, nil
+			ctx := keyContext{m: m, snapshot: snapshot}
+			if cmd, ok := editKeys.Dispatch(key.String(), ctx); ok {
+				return m, cmd
 			}
 			var cmd tea.Cmd
 			if m.editFocus == editFieldDescription && len(m.editInputs) > 0 {
@@ -147,43 +238,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, cmd
 		}
-		switch key.String() {
-		case "left":
-			m.adjustTableX(-4)
-		case "right":
-			m.adjustTableX(4)
-		case "[":
-			if m.nodeIdx > 0 {
-				m.nodeIdx--
-				m.ruleIdx = 0
-				m.tableOffset = 0
-				m.tableXOffset = 0
-			}
-		case "]":
-			nodes := snapshot.Nodes
-			if len(nodes) > 0 && m.nodeIdx < len(nodes)-1 {
-				m.nodeIdx++
-				m.ruleIdx = 0
-				m.tableOffset = 0
-				m.tableXOffset = 0
-			}
-		case "up":
-			if m.ruleIdx > 0 {
-				m.ruleIdx--
-			}
-		case "down":
-			if _, rules, ok := m.current(snapshot); ok && m.ruleIdx < len(rules)-1 {
-				m.ruleIdx++
-			}
-		case "e":
-			m.requestToggle(snapshot, true)
-		case "d":
-			m.requestToggle(snapshot, false)
-		case "x", "delete":
-			m.requestDelete(snapshot)
-		case "m":
-			m.startEdit(snapshot)
-		}
+		cmd, _ := tableKeys.Dispatch(key.String(), keyContext{m: m, snapshot: snapshot})
+		return m, cmd
 	}
 
 	return m, nil
@@ -199,21 +255,41 @@ func (m *Model) View() string {
 		return m.wrap(msg)
 	}
 
-	_, rules, ok := m.current(snapshot)
+	node, rules, ok := m.current(snapshot)
 	if !ok {
 		msg := m.theme.Subtle.Render("Select a node to view its rules.")
 		return m.wrap(msg)
 	}
 
+	if len(rules) > 0 {
+		rule := rules[min(m.ruleIdx, len(rules)-1)]
+		m.shown = shownRule{nodeID: node.ID, name: rule.Name, hash: ruleset.Hash(rule)}
+	}
+	m.checkAck(snapshot)
 	header := m.renderNodes(snapshot)
-	table := m.renderRulesTable(rules)
+	m.analysisFor(snapshot, node.ID)
+	table := m.renderRulesTable(rules, len(snapshot.Rules[node.ID]))
 	var content string
-	if m.editing {
+	switch {
+	case m.wire != nil:
+		content = m.wire.View(m.theme, m.wireHeight())
+	case m.history != nil:
+		content = m.history.View(m.theme, m.wireHeight())
+	case m.starter != nil:
+		content = m.renderStarter()
+	case m.trash != nil:
+		content = m.renderTrash(snapshot, node)
+	case m.create != nil:
+		content = m.renderCreate(snapshot)
+	case m.editing:
 		content = m.renderEditModal(rules)
-	} else {
-		content = m.renderRuleDetail(rules)
+	default:
+		content = m.renderRuleDetail(snapshot, rules)
+	}
+	status := m.renderStatus(len(rules), len(snapshot.Rules[node.ID]))
+	if panel := m.renderBulk(snapshot); panel != "" {
+		status = panel + "\n" + status
 	}
-	status := m.renderStatus()
 
 	body := lipgloss.JoinVertical(lipgloss.Left, header, table, content, status)
 	return m.wrap(body)
@@ -221,6 +297,12 @@ func (m *Model) View() string {
 
 func (m *Model) Title() string { return "Rules" }
 
+// EnteringText reports whether a rule form, the filter, or the jump,
+// export or import prompt is open.
+func (m *Model) EnteringText() bool {
+	return m.editing || m.create != nil || m.jumping || m.filtering || m.exporting || m.importing
+}
+
 func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -235,14 +317,35 @@ func (m *Model) renderNodes(snapshot state.Snapshot) string {
 	items := make([]string, 0, len(nodes))
 	for idx, node := range nodes {
 		label := fmt.Sprintf("%s (%d)", util.DisplayName(node), len(snapshot.Rules[node.ID]))
-		items = append(items, m.theme.RenderTab(label, idx == m.nodeIdx))
+		items = append(items, m.theme.AccentUnderline(state.NodeKey(node), m.theme.RenderTab(label, idx == m.nodeIdx)))
+	}
+	// Rules only change on push from the daemon, so a silent node may be
+	// showing an outdated list.
+	if m.nodeIdx < len(nodes) {
+		if count := state.SessionRuleCount(snapshot.Rules[nodes[m.nodeIdx].ID]); count > 0 {
+			items = append(items, " "+m.theme.Subtle.Render(fmt.Sprintf("created this session: %d", count)))
+		}
+		if cachedOnly(snapshot.Rules[nodes[m.nodeIdx].ID]) {
+			items = append(items, " "+m.theme.Warning.Render("("+rulecache.AwaitingSync+")"))
+		} else if freshness := state.NodeStaleness(nodes[m.nodeIdx], snapshot.Stats, m.now()); freshness.Stale {
+			items = append(items, " "+m.theme.Warning.Render(fmt.Sprintf("(last sync %s)", util.RelativeTimeAt(freshness.LastSync, m.now()))))
+		}
 	}
 	return lipgloss.JoinHorizontal(lipgloss.Top, items...)
 }
 
-func (m *Model) renderRulesTable(rules []state.Rule) string {
+func (m *Model) renderRulesTable(rules []state.Rule, total int) string {
 	if len(rules) == 0 {
-		return m.theme.Subtle.Render("No rules reported for this node.")
+		if total > 0 && m.filterQuery() != "" {
+			return m.theme.Subtle.Render("No rules match the filter. Press esc to clear it.")
+		}
+		if total > 0 && m.sessionOnly {
+			return m.theme.Subtle.Render("No rules on this node were created this session. Press w to show all rules.")
+		}
+		if total > 0 {
+			return m.theme.Subtle.Render("All rules on this node are disabled. Press z to show them.")
+		}
+		return m.theme.Subtle.Render("No rules reported for this node. Press S to add starter rules for local network traffic.")
 	}
 	layout := m.tableColumns()
 	start := min(m.tableOffset, max(0, len(rules)-1))
@@ -267,13 +370,16 @@ func (m *Model) renderRulesTable(rules []state.Rule) string {
 	m.tableMaxWidth = table.ComputeMaxWidth(rows)
 	visibleWidth := max(1, m.contentWidth())
 	clipped := table.ClipRows(rows, m.tableXOffset, visibleWidth)
+	if len(rules) > capacity {
+		clipped = append(clipped, m.renderPosition(start, end, len(rules)))
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, clipped...)
 }
 
 func (m *Model) renderTableHeader(layout tableLayout, gap string) string {
 	headerStyle := m.theme.Header.Bold(true).Padding(0)
-	labels := []string{"", "NAME", "ACTION", "DURATION", "STATUS", "PRECEDENCE", "NOLOG", "OPERATOR"}
-	widths := []int{layout.cursor, layout.name, layout.action, layout.duration, layout.status, layout.precedence, layout.noLog, layout.operator}
+	labels := []string{"", "HIT", "NAME", "ACTION", "DURATION", "STATUS", "PRECEDENCE", "NOLOG", "OPERATOR"}
+	widths := []int{layout.cursor, layout.hit, layout.name, layout.action, layout.duration, layout.status, layout.precedence, layout.noLog, layout.operator}
 	cells := make([]string, len(labels))
 	for i := range labels {
 		cells[i] = table.PadAndStyle(headerStyle, labels[i], widths[i], true)
@@ -290,36 +396,70 @@ func (m *Model) renderRuleRow(layout tableLayout, rule state.Rule, rowIdx int, s
 	if selected {
 		cursor = ">"
 	}
-	cursorStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
-	nameStyle := stripBackground(m.theme.Title).Background(bg).Padding(0)
-	actionStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
-	durationStyle := stripBackground(m.theme.Subtle).Background(bg).Padding(0)
-	statusEnabled := stripBackground(m.theme.Success).Background(bg).Padding(0)
-	statusDisabled := stripBackground(m.theme.Warning).Background(bg).Padding(0)
-	flagStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
-	operatorStyle := stripBackground(m.theme.Body).Background(bg).Padding(0)
+	// Disabled rows are dimmed, except under the cursor where the regular
+	// foregrounds keep the selection readable on light selection colours.
+	dimmed := !rule.Enabled && !selected
+	cell := func(base lipgloss.Style) lipgloss.Style {
+		style := stripBackground(base).Background(bg).Padding(0)
+		if dimmed {
+			style = style.Foreground(m.theme.Subtle.GetForeground()).Bold(false)
+		}
+		return style
+	}
+	cursorStyle := cell(m.theme.Body)
+	nameStyle := cell(m.theme.Title)
+	badgeStyle := cell(m.theme.Success)
+	actionStyle := cell(m.theme.Body)
+	durationStyle := cell(m.theme.Subtle)
+	statusEnabled := cell(m.theme.Success)
+	statusDisabled := cell(m.theme.Warning)
+	flagStyle := cell(m.theme.Body)
+	operatorStyle := cell(m.theme.Body)
 	statusLabel := "disabled"
 	statusStyle := statusDisabled
-	if rule.Enabled {
+	switch {
+	case rule.Cached:
+		statusLabel = "cached"
+	case rule.Enabled:
 		statusLabel = "enabled"
 		statusStyle = statusEnabled
+		if _, shadowed := m.analyses[rule.NodeID].ShadowedBy(rule.Name); shadowed {
+			statusLabel = "shadowed"
+			statusStyle = statusDisabled
+		}
 	}
 	cells := []string{
 		table.PadAndStyle(cursorStyle, cursor, layout.cursor, true),
-		table.PadAndStyle(nameStyle, rule.Name, layout.name, true),
+		table.PadAndStyle(cell(m.freshnessStyle(rule)), glyphs.Current().Dot, layout.hit, true),
+		m.renderNameCell(nameStyle, badgeStyle, rule, layout.name),
 		table.PadAndStyle(actionStyle, rule.Action, layout.action, true),
 		table.PadAndStyle(durationStyle, rule.Duration, layout.duration, true),
 		table.PadAndStyle(statusStyle, statusLabel, layout.status, true),
 		table.PadAndStyle(flagStyle, boolLabel(rule.Precedence), layout.precedence, true),
 		table.PadAndStyle(flagStyle, boolLabel(rule.NoLog), layout.noLog, true),
-		table.PadAndStyle(operatorStyle, describeOperator(rule.Operator), layout.operator, false),
+		table.PadAndStyle(operatorStyle, ruleset.DescribeOperator(rule.Operator), layout.operator, false),
 	}
 	gapStyle := lipgloss.NewStyle().Background(bg)
 	rowGap := gapStyle.Render(gap)
 	return strings.Join(cells, rowGap)
 }
 
-func (m *Model) renderRuleDetail(rules []state.Rule) string {
+// sessionBadge follows the name of rules created this session.
+const sessionBadge = " new"
+
+// renderNameCell renders the NAME cell, keeping the session badge visible
+// by truncating the name instead.
+func (m *Model) renderNameCell(nameStyle, badgeStyle lipgloss.Style, rule state.Rule, width int) string {
+	badge := util.RuneWidth(sessionBadge)
+	if !rule.Session || width <= badge+1 {
+		return table.PadAndStyle(nameStyle, rule.Name, width, true)
+	}
+	name := util.TruncateString(rule.Name, width-badge)
+	pad := width - badge - util.RuneWidth(name)
+	return nameStyle.Render(name) + badgeStyle.Render(sessionBadge) + nameStyle.Render(strings.Repeat(" ", pad))
+}
+
+func (m *Model) renderRuleDetail(snapshot state.Snapshot, rules []state.Rule) string {
 	if len(rules) == 0 {
 		return ""
 	}
@@ -341,11 +481,18 @@ func (m *Model) renderRuleDetail(rules []state.Rule) string {
 		fmtLine("Action", colorRuleAction(m.theme, rule.Action)),
 		fmtLine("Duration", colorDuration(m.theme, rule.Duration)),
 		fmtLine("Enabled", colorBool(m.theme, rule.Enabled)),
+	}
+	if rule.Cached {
+		lines = append(lines, fmtLine("Status", m.theme.Warning.Render(rulecache.AwaitingSync)))
+	}
+	lines = append(lines,
 		fmtLine("Precedence", colorBool(m.theme, rule.Precedence)),
 		fmtLine("NoLog", colorBool(m.theme, rule.NoLog)),
 		fmtLine("Created", created),
-		fmtLine("Operator", describeOperator(rule.Operator)),
-	}
+	)
+	lines = append(lines, operatorLines(rule.Operator, fmtLine, inner)...)
+	lines = append(lines, util.TruncateString(m.footprintLine(snapshot, rule), inner))
+	lines = append(lines, m.precedenceLines(rule, inner)...)
 	return m.theme.Body.Render(strings.Join(lines, "\n"))
 }
 
@@ -388,15 +535,17 @@ func (m *Model) renderEditRow(label string, opts []widget.Option, selected int,
 }
 
 func (m *Model) startEdit(snapshot state.Snapshot) {
-	_, rules, ok := m.current(snapshot)
+	node, rules, ok := m.current(snapshot)
 	if !ok || len(rules) == 0 {
 		return
 	}
-	if m.controller == nil {
-		m.statusLine = m.theme.Danger.Render("Rules controller unavailable")
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
 		return
 	}
 	rule := rules[min(m.ruleIdx, len(rules)-1)]
+	if m.blockCached(node, rule) {
+		return
+	}
 	inputs := make([]textinput.Model, 1)
 	desc := textinput.New()
 	desc.Placeholder = editPlaceholders[editFieldDescription]
@@ -408,6 +557,7 @@ func (m *Model) startEdit(snapshot state.Snapshot) {
 	m.editInputs = inputs
 	m.editFocus = editFieldDescription
 	m.editRuleName = rule.Name
+	m.editRuleHash = m.shownHash(node, rule)
 	m.editActionIdx = widget.IndexOf(ruleActionOptions, strings.ToLower(rule.Action))
 	m.editDurIdx = widget.IndexOf(ruleDurationOptions, strings.ToLower(rule.Duration))
 	m.editNoLog = rule.NoLog
@@ -419,6 +569,7 @@ func (m *Model) cancelEdit() {
 	m.editing = false
 	m.editInputs = nil
 	m.editRuleName = ""
+	m.editRuleHash = ""
 	m.editActionIdx = 0
 	m.editDurIdx = 0
 	m.editNoLog = false
@@ -462,8 +613,7 @@ func (m *Model) submitEdit(snapshot state.Snapshot) {
 	if !ok || len(rules) == 0 {
 		return
 	}
-	if m.controller == nil {
-		m.statusLine = m.theme.Danger.Render("Rules controller unavailable")
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
 		return
 	}
 	if len(ruleActionOptions) == 0 {
@@ -474,17 +624,12 @@ func (m *Model) submitEdit(snapshot state.Snapshot) {
 		m.statusLine = m.theme.Danger.Render("No duration options configured")
 		return
 	}
-	var rule state.Rule
-	for _, r := range rules {
-		if r.Name == m.editRuleName {
-			rule = r
-			break
-		}
-	}
-	if rule.Name == "" {
+	found := ruleset.Find(rules, m.editRuleName)
+	if found < 0 {
 		m.statusLine = m.theme.Danger.Render("Rule not found")
 		return
 	}
+	rule := rules[found]
 	desc := ""
 	if len(m.editInputs) > 0 {
 		desc = strings.TrimSpace(m.editInputs[0].Value())
@@ -499,21 +644,70 @@ func (m *Model) submitEdit(snapshot state.Snapshot) {
 	if rule.NodeID == "" {
 		rule.NodeID = node.ID
 	}
-	err := m.controller.ChangeRule(rule.NodeID, rule)
-	m.renderActionResult(err, "change", node, rule)
-	if err == nil {
+	if err := ruleset.LimitsFor(snapshot.Settings).Validate(rule); err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Invalid rule: %v", err))
+		return
+	}
+	sent := m.now()
+	err := m.controller.ChangeRule(rule.NodeID, rule, m.editRuleHash)
+	m.renderActionResult(err, "change", node, rule, sent)
+	if err == nil || errors.Is(err, controller.ErrRuleConflict) {
 		m.cancelEdit()
 	}
 }
 
-func (m *Model) renderStatus() string {
+func (m *Model) renderStatus(shown, total int) string {
 	var help string
-	if m.editing {
-		help = "esc cancel · enter save · tab/shift+tab · ←/→ change"
-	} else {
-		help = "←/→ scroll · [/] nodes · ↑/↓ rules · e enable · d disable · x delete · m modify"
+	var disabled []string
+	switch {
+	case m.jumping:
+		help = "enter jump · esc cancel"
+	case m.filtering:
+		help = filterHelp
+	case m.exporting:
+		help = exportHelp
+	case m.importing:
+		help = importHelp
+	case m.wire != nil:
+		help = wireHelp
+	case m.history != nil:
+		help = historyHelp
+	case m.starter != nil:
+		help = starterHelp
+	case m.trash != nil && m.trash.confirmEmpty:
+		help = trashConfirmHelp
+	case m.trash != nil:
+		help = trashHelp
+	case m.create != nil:
+		help = createHelp
+	case m.editing:
+		help = "esc cancel · enter save · tab/shift+tab · ←/→ or n/p change"
+	default:
+		help, disabled = tableHelp()
+		if m.hideDisabled || m.sessionOnly {
+			help += fmt.Sprintf(" (%d hidden)", total-shown)
+		}
 	}
+	help = widget.WrapHelp(help, m.width-m.theme.Body.GetHorizontalFrameSize())
 	helpRendered := m.theme.Subtle.Render(help)
+	if !m.attached {
+		helpRendered = widget.RenderHelp(m.theme, help, disabled)
+		if m.statusLine == "" {
+			helpRendered = fmt.Sprintf("%s\n%s", widget.RenderReadOnly(m.theme), helpRendered)
+		}
+	}
+	if m.jumping {
+		helpRendered = fmt.Sprintf("%s\n%s", m.jumpInput.View(), helpRendered)
+	}
+	if m.exporting {
+		helpRendered = fmt.Sprintf("%s\n%s", m.exportInput.View(), helpRendered)
+	}
+	if m.importing {
+		helpRendered = fmt.Sprintf("%s\n%s", m.importInput.View(), helpRendered)
+	}
+	if filter := m.renderFilter(shown, total); filter != "" {
+		helpRendered = fmt.Sprintf("%s\n%s", filter, helpRendered)
+	}
 	if m.statusLine == "" {
 		return helpRendered
 	}
@@ -528,6 +722,10 @@ func (m *Model) tableCapacity() int {
 	if m.height <= 0 {
 		return defaultTableRows
 	}
+	if m.tableShare > 0 {
+		rows := int(math.Round(m.tableShare * float64(m.height)))
+		return max(minTableRows, min(rows, m.height-tableChrome))
+	}
 	capacity := m.height - tableChrome
 	if capacity < minTableRows {
 		capacity = minTableRows
@@ -541,6 +739,7 @@ func (m *Model) tableCapacity() int {
 func (m *Model) tableColumns() tableLayout {
 	layout := tableLayout{
 		cursor:     minCursorWidth,
+		hit:        minHitWidth,
 		name:       minNameWidth,
 		action:     minActionWidth,
 		duration:   minDurationWidth,
@@ -621,6 +820,7 @@ func (m *Model) tableColumns() tableLayout {
 		}
 	}
 	layout.cursor = max(1, layout.cursor)
+	layout.hit = max(1, layout.hit)
 	layout.name = max(6, layout.name)
 	layout.action = max(4, layout.action)
 	layout.duration = max(4, layout.duration)
@@ -673,7 +873,7 @@ func (m *Model) clampSelection(snapshot state.Snapshot) {
 		m.ruleIdx = 0
 		m.tableOffset = 0
 	}
-	rules := snapshot.Rules[nodes[m.nodeIdx].ID]
+	_, rules, _ := m.current(snapshot)
 	if len(rules) == 0 {
 		m.ruleIdx = 0
 		m.tableOffset = 0
@@ -702,7 +902,56 @@ func (m *Model) current(snapshot state.Snapshot) (state.Node, []state.Rule, bool
 	}
 	node := nodes[min(m.nodeIdx, len(nodes)-1)]
 	rules := snapshot.Rules[node.ID]
-	return node, rules, true
+	if m.hideDisabled {
+		rules = enabledOnly(rules)
+	}
+	if m.sessionOnly {
+		rules = sessionOnly(rules)
+	}
+	rules = filterRules(rules, m.filterQuery())
+	return node, m.sort.sorted(rules), true
+}
+
+// cachedOnly reports whether a node's rules all come from the rule cache.
+func cachedOnly(rules []state.Rule) bool {
+	for _, rule := range rules {
+		if !rule.Cached {
+			return false
+		}
+	}
+	return len(rules) > 0
+}
+
+func enabledOnly(rules []state.Rule) []state.Rule {
+	out := make([]state.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Enabled {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+func sessionOnly(rules []state.Rule) []state.Rule {
+	out := make([]state.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Session {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+// toggleSessionOnly flips the filter showing only rules created this
+// session, for reviewing them before quitting.
+func (m *Model) toggleSessionOnly(snapshot state.Snapshot) {
+	m.keepSelection(snapshot, func() { m.sessionOnly = !m.sessionOnly })
+}
+
+// toggleHideDisabled flips the disabled-rule filter, keeping the cursor on
+// the same rule when it stays visible.
+func (m *Model) toggleHideDisabled(snapshot state.Snapshot) {
+	m.keepSelection(snapshot, func() { m.hideDisabled = !m.hideDisabled })
 }
 
 func (m *Model) requestToggle(snapshot state.Snapshot, enable bool) {
@@ -711,20 +960,23 @@ func (m *Model) requestToggle(snapshot state.Snapshot, enable bool) {
 		return
 	}
 	rule := rules[min(m.ruleIdx, len(rules)-1)]
-	if m.controller == nil {
-		m.statusLine = m.theme.Danger.Render("Rules controller unavailable")
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return
+	}
+	if m.blockCached(node, rule) {
 		return
 	}
 	var err error
 	var verb string
+	sent := m.now()
 	if enable {
 		verb = "enable"
-		err = m.controller.EnableRule(node.ID, rule.Name)
+		err = m.controller.EnableRule(node.ID, rule.Name, m.shownHash(node, rule))
 	} else {
 		verb = "disable"
-		err = m.controller.DisableRule(node.ID, rule.Name)
+		err = m.controller.DisableRule(node.ID, rule.Name, m.shownHash(node, rule))
 	}
-	m.renderActionResult(err, verb, node, rule)
+	m.renderActionResult(err, verb, node, rule, sent)
 }
 
 func (m *Model) requestDelete(snapshot state.Snapshot) {
@@ -732,45 +984,58 @@ func (m *Model) requestDelete(snapshot state.Snapshot) {
 	if !ok || len(rules) == 0 {
 		return
 	}
-	if m.controller == nil {
-		m.statusLine = m.theme.Danger.Render("Rules controller unavailable")
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
 		return
 	}
 	rule := rules[min(m.ruleIdx, len(rules)-1)]
-	err := m.controller.DeleteRule(node.ID, rule.Name)
+	if m.blockCached(node, rule) {
+		return
+	}
+	sent := m.now()
+	err := m.controller.DeleteRule(node.ID, rule.Name, m.shownHash(node, rule))
 	if err == nil && m.ruleIdx >= len(rules)-1 {
 		m.ruleIdx = max(0, m.ruleIdx-1)
 	}
-	m.renderActionResult(err, "delete", node, rule)
+	m.renderActionResult(err, "delete", node, rule, sent)
 }
 
-func (m *Model) renderActionResult(err error, action string, node state.Node, rule state.Rule) {
-	if err != nil {
-		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to %s %s on %s: %v", action, rule.Name, util.DisplayName(node), err))
-		return
-	}
-	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Requested %s %s on %s", action, rule.Name, util.DisplayName(node)))
+// blockCached refuses changes to rules only known from the rule cache; the
+// daemon holding them is not connected to receive the change.
+func (m *Model) blockCached(node state.Node, rule state.Rule) bool {
+	if !rule.Cached {
+		return false
+	}
+	m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s is cached from a previous session; %s must reconnect before its rules can be changed", rule.Name, util.DisplayName(node)))
+	return true
 }
 
-func describeOperator(op state.RuleOperator) string {
-	if op.Type == "" && op.Operand == "" && op.Data == "" && len(op.Children) == 0 {
-		return "-"
+// shownHash returns the hash of rule as it was last rendered, or of rule
+// itself when the selection moved without a render in between.
+func (m *Model) shownHash(node state.Node, rule state.Rule) string {
+	if m.shown.nodeID == node.ID && m.shown.name == rule.Name {
+		return m.shown.hash
 	}
-	parts := []string{op.Type}
-	if op.Operand != "" {
-		parts = append(parts, op.Operand)
-	}
-	if op.Data != "" {
-		parts = append(parts, op.Data)
+	return ruleset.Hash(rule)
+}
+
+// renderActionResult reports a requested action; a successful request then
+// awaits the daemon's ack, sent at sent or later.
+func (m *Model) renderActionResult(err error, action string, node state.Node, rule state.Rule, sent time.Time) {
+	m.awaiting = nil
+	if errors.Is(err, controller.ErrRuleConflict) {
+		// The detail pane renders the current rule; forget the stale hash so
+		// a retry acts on what the user now sees.
+		m.shown = shownRule{}
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("%s: rule changed since displayed — review and retry", rule.Name))
+		return
 	}
-	if len(op.Children) > 0 {
-		childParts := make([]string, len(op.Children))
-		for i, child := range op.Children {
-			childParts[i] = describeOperator(child)
-		}
-		parts = append(parts, fmt.Sprintf("[%s]", strings.Join(childParts, ", ")))
+	if err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to %s %s on %s: %v", action, rule.Name, util.DisplayName(node), err))
+		return
 	}
-	return strings.TrimSpace(strings.Join(parts, " "))
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Requested %s %s on %s", action, rule.Name, util.DisplayName(node)))
+	m.awaiting = &ackWait{nodeID: node.ID, node: util.DisplayName(node), action: action, rule: rule.Name, sent: sent}
+	m.checkAck(m.store.Snapshot())
 }
 
 func stripBackground(style lipgloss.Style) lipgloss.Style {
@@ -811,6 +1076,17 @@ func colorRuleAction(th theme.Theme, action string) string {
 	}
 }
 
+// operatorLines shows a single condition inline and a list operator as a
+// tree under the label, so it is clear that every branch must match.
+func operatorLines(op state.RuleOperator, fmtLine func(label, value string) string, width int) []string {
+	tree := ruleset.OperatorTree(op)
+	lines := []string{fmtLine("Operator", tree[0])}
+	for _, line := range tree[1:] {
+		lines = append(lines, util.TruncateString("  "+line, width))
+	}
+	return lines
+}
+
 func colorBool(th theme.Theme, v bool) string {
 	if v {
 		return th.Success.Render("true")
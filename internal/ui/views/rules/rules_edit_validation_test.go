@@ -85,11 +85,39 @@ type recordingRuleManager struct {
 	last *state.Rule
 }
 
-func (r *recordingRuleManager) EnableRule(string, string) error  { return nil }
-func (r *recordingRuleManager) DisableRule(string, string) error { return nil }
-func (r *recordingRuleManager) DeleteRule(string, string) error  { return nil }
-func (r *recordingRuleManager) ChangeRule(_ string, rule state.Rule) error {
+func (r *recordingRuleManager) EnableRule(string, string, string) error  { return nil }
+func (r *recordingRuleManager) DisableRule(string, string, string) error { return nil }
+func (r *recordingRuleManager) DeleteRule(string, string, string) error  { return nil }
+func (r *recordingRuleManager) AddRule(string, state.Rule) error         { return nil }
+func (r *recordingRuleManager) ChangeRule(_ string, rule state.Rule, _ string) error {
 	ruleCopy := rule
 	r.last = &ruleCopy
 	return nil
 }
+
+func TestSubmitEditRejectsOverlongDescription(t *testing.T) {
+	store := state.NewStore()
+	node := state.Node{ID: "node-1", Name: "alpha"}
+	store.SetNodes([]state.Node{node})
+	store.SetRules(node.ID, []state.Rule{{Name: "r1", Action: "allow", Duration: "once", Enabled: true}})
+
+	rec := &recordingRuleManager{}
+	m := New(store, theme.New(theme.Options{}), rec).(*Model)
+	m.editing = true
+	m.editRuleName = "r1"
+	m.editInputs = []textinput.Model{textinput.New()}
+	m.editInputs[0].CharLimit = 0
+	m.editInputs[0].SetValue(strings.Repeat("é", 300))
+
+	m.submitEdit(store.Snapshot())
+
+	if rec.last != nil {
+		t.Fatalf("expected no change to be sent, got %+v", rec.last)
+	}
+	if !strings.Contains(m.statusLine, "description is 300 characters long; the limit is 256") {
+		t.Fatalf("expected length error, got %q", m.statusLine)
+	}
+	if !m.editing {
+		t.Fatalf("expected the edit form to stay open")
+	}
+}
@@ -27,9 +27,10 @@ func TestRulesSnapshot(t *testing.T) {
 
 type noopRuleManager struct{}
 
-func (noopRuleManager) EnableRule(string, string) error  { return nil }
-func (noopRuleManager) DisableRule(string, string) error { return nil }
-func (noopRuleManager) DeleteRule(string, string) error  { return nil }
-func (noopRuleManager) ChangeRule(string, state.Rule) error {
+func (noopRuleManager) EnableRule(string, string, string) error  { return nil }
+func (noopRuleManager) DisableRule(string, string, string) error { return nil }
+func (noopRuleManager) DeleteRule(string, string, string) error  { return nil }
+func (noopRuleManager) AddRule(string, state.Rule) error         { return nil }
+func (noopRuleManager) ChangeRule(string, state.Rule, string) error {
 	return nil
 }
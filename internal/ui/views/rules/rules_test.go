@@ -9,6 +9,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
 )
@@ -18,35 +19,50 @@ type fakeRuleController struct {
 	nodeID   string
 	ruleName string
 	rule     state.Rule
+	hash     string
+	added    []string
 	err      error
 }
 
-func (f *fakeRuleController) EnableRule(nodeID, ruleName string) error {
+func (f *fakeRuleController) EnableRule(nodeID, ruleName, hash string) error {
 	f.action = "enable"
 	f.nodeID = nodeID
 	f.ruleName = ruleName
+	f.hash = hash
 	return f.err
 }
 
-func (f *fakeRuleController) DisableRule(nodeID, ruleName string) error {
+func (f *fakeRuleController) DisableRule(nodeID, ruleName, hash string) error {
 	f.action = "disable"
 	f.nodeID = nodeID
 	f.ruleName = ruleName
+	f.hash = hash
 	return f.err
 }
 
-func (f *fakeRuleController) DeleteRule(nodeID, ruleName string) error {
+func (f *fakeRuleController) DeleteRule(nodeID, ruleName, hash string) error {
 	f.action = "delete"
 	f.nodeID = nodeID
 	f.ruleName = ruleName
+	f.hash = hash
 	return f.err
 }
 
-func (f *fakeRuleController) ChangeRule(nodeID string, rule state.Rule) error {
+func (f *fakeRuleController) ChangeRule(nodeID string, rule state.Rule, hash string) error {
 	f.action = "change"
 	f.nodeID = nodeID
 	f.ruleName = rule.Name
 	f.rule = rule
+	f.hash = hash
+	return f.err
+}
+
+func (f *fakeRuleController) AddRule(nodeID string, rule state.Rule) error {
+	f.action = "add"
+	f.nodeID = nodeID
+	f.ruleName = rule.Name
+	f.rule = rule
+	f.added = append(f.added, rule.Name)
 	return f.err
 }
 
@@ -80,6 +96,33 @@ func TestRulesEnableAction(t *testing.T) {
 	}
 }
 
+func TestRulesActionReportsAck(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", Address: "10.0.0.2"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow", Duration: "once", Operator: state.RuleOperator{Type: "process"}}})
+	clock := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	view := New(store, theme.New(theme.Options{}), &fakeRuleController{}).(*Model)
+	view.now = func() time.Time { return clock }
+	view.SetSize(160, 25)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	store.RecordAck(state.ActionAck{NodeID: "node-1", Action: "enable", Rule: "ssh", SentAt: clock, Latency: 84 * time.Millisecond})
+	if out := view.View(); !strings.Contains(out, "enabled ssh on alpha (ack 84ms)") {
+		t.Fatalf("expected the ack time in the status, got %q", out)
+	}
+
+	// No ack past the threshold flags the daemon as slow.
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	clock = clock.Add(state.DefaultSlowAck + time.Second)
+	if out := view.View(); !strings.Contains(out, "no ack after 3s — daemon may be overloaded") {
+		t.Fatalf("expected an overdue ack warning, got %q", out)
+	}
+	store.RecordAck(state.ActionAck{NodeID: "node-1", Action: "disable", Rule: "ssh", SentAt: clock.Add(-4 * time.Second), Latency: 4 * time.Second, Slow: true})
+	if out := view.View(); !strings.Contains(out, "disabled ssh on alpha (ack 4s) · slow — daemon may be overloaded") {
+		t.Fatalf("expected the slow ack in the status, got %q", out)
+	}
+}
+
 func TestRulesDeleteAction(t *testing.T) {
 	store := state.NewStore()
 	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", Address: "10.0.0.2"}})
@@ -142,6 +185,69 @@ func TestRulesModifyNavigateFieldsWithArrows(t *testing.T) {
 	}
 }
 
+func TestRulesModifyWithoutArrowKeys(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", Address: "10.0.0.2"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow", Description: "orig"}})
+	ctrl := &fakeRuleController{}
+	m := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	m.SetSize(80, 25)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if !m.EnteringText() {
+		t.Fatalf("expected the edit form to hold the keyboard")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.editActionIdx != 0 || m.editInputs[0].Value() != "orign" {
+		t.Fatalf("expected n to be typed into the description, got action %d, %q", m.editActionIdx, m.editInputs[0].Value())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.editFocus != editFieldAction {
+		t.Fatalf("expected tab to focus the action, got %d", m.editFocus)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.editActionIdx != 1 {
+		t.Fatalf("expected n to pick the next action, got %d", m.editActionIdx)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.editFocus != editFieldCount-1 {
+		t.Fatalf("expected shift+tab to wrap to the last field, got %d", m.editFocus)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if !m.editPrecedence {
+		t.Fatalf("expected p to flip the precedence toggle")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if ctrl.action != "change" || m.EnteringText() {
+		t.Fatalf("expected the change to be submitted, got %+v", ctrl)
+	}
+}
+
+func TestRulesTableWithoutArrowKeys(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", makeTestRules(3))
+	m := New(store, theme.New(theme.Options{}), &fakeRuleController{}).(*Model)
+	m.SetSize(60, 25)
+	m.View()
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if m.ruleIdx != 1 {
+		t.Fatalf("expected j/k to move the selection, got %d", m.ruleIdx)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	if m.tableXOffset == 0 {
+		t.Fatalf("expected l to scroll the table")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	if m.tableXOffset != 0 {
+		t.Fatalf("expected h to scroll back, got %d", m.tableXOffset)
+	}
+}
+
 func TestRulesTableWindowing(t *testing.T) {
 	store := state.NewStore()
 	node := state.Node{ID: "node-1", Name: "alpha"}
@@ -326,3 +432,176 @@ func makeTestRules(count int) []state.Rule {
 	}
 	return rules
 }
+
+type fakeWireController struct {
+	fakeRuleController
+}
+
+func (f *fakeWireController) RuleWire(rule state.Rule) string {
+	return "name: \"" + rule.Name + "\"\noperator: {\n  type: \"simple\"\n}"
+}
+
+func (f *fakeWireController) ConnectionWire(state.Connection) string { return "" }
+
+func TestRulesWireModal(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+	view := New(store, theme.New(theme.Options{}), &fakeWireController{})
+	view.SetSize(100, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	out := view.View()
+	if !strings.Contains(out, "pb.Rule ssh") || !strings.Contains(out, `name: "ssh"`) {
+		t.Fatalf("expected wire view of the selected rule, got %q", out)
+	}
+	if _, cmd := view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}); cmd == nil {
+		t.Fatalf("expected copy command")
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if out := view.View(); strings.Contains(out, "pb.Rule") {
+		t.Fatalf("expected esc to close the wire view, got %q", out)
+	}
+}
+
+func TestRulesWireUnavailable(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh"}})
+	view := New(store, theme.New(theme.Options{}), &fakeRuleController{})
+	view.SetSize(100, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if out := view.View(); !strings.Contains(out, "Wire view unavailable") {
+		t.Fatalf("expected unavailable notice, got %q", out)
+	}
+}
+
+func TestRulesHeaderShowsLastSyncWhenStale(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", LastSeen: now}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+	m := New(store, theme.New(theme.Options{}), nil).(*Model)
+	m.SetSize(100, 25)
+
+	m.now = func() time.Time { return now.Add(10 * time.Second) }
+	if out := m.View(); strings.Contains(out, "last sync") {
+		t.Fatalf("expected no sync note for a live node, got:\n%s", out)
+	}
+	m.now = func() time.Time { return now.Add(3 * time.Minute) }
+	if out := m.View(); !strings.Contains(out, "(last sync 3m0s ago)") {
+		t.Fatalf("expected last sync note, got:\n%s", out)
+	}
+}
+
+func TestRulesCachedRulesAwaitSync(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha", Status: state.NodeStatusDisconnected}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow", Enabled: true, Cached: true}})
+	ctrl := &fakeRuleController{}
+	view := New(store, theme.New(theme.Options{}), ctrl)
+	view.SetSize(120, 25)
+
+	out := view.View()
+	if !strings.Contains(out, "(cached — awaiting sync)") || !strings.Contains(out, "Status: cached — awaiting sync") {
+		t.Fatalf("expected cached markers, got:\n%s", out)
+	}
+	for _, key := range []rune{'d', 'x', 'm'} {
+		view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		if ctrl.action != "" {
+			t.Fatalf("%c reached the controller for a cached rule: %+v", key, ctrl)
+		}
+		if out := view.View(); !strings.Contains(out, "must reconnect") {
+			t.Fatalf("%c: expected a blocked message, got:\n%s", key, out)
+		}
+	}
+}
+
+func TestRulesActionCarriesDisplayedHash(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	shown := state.Rule{Name: "ssh", Action: "allow", Operator: state.RuleOperator{Type: "simple", Operand: "process.path", Data: "/usr/bin/ssh"}}
+	store.SetRules("node-1", []state.Rule{shown})
+	ctrl := &fakeRuleController{}
+	view := New(store, theme.New(theme.Options{}), ctrl)
+	view.SetSize(120, 25)
+	view.View()
+
+	// A refresh swaps the operator before the key press.
+	replaced := shown
+	replaced.Operator.Data = "/usr/bin/scp"
+	store.SetRules("node-1", []state.Rule{replaced})
+	ctrl.err = &controller.RuleConflictError{NodeID: "node-1", Rule: "ssh"}
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+
+	if ctrl.action != "delete" || ctrl.hash != ruleset.Hash(shown) {
+		t.Fatalf("expected delete with the displayed rule's hash, got %+v", ctrl)
+	}
+	out := view.View()
+	if !strings.Contains(out, "ssh: rule changed since displayed — review and retry") {
+		t.Fatalf("expected a conflict message, got:\n%s", out)
+	}
+	if !strings.Contains(out, "/usr/bin/scp") {
+		t.Fatalf("expected the detail pane to show the current rule, got:\n%s", out)
+	}
+
+	ctrl.err = nil
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if ctrl.hash != ruleset.Hash(replaced) {
+		t.Fatal("expected the retry to carry the refreshed rule's hash")
+	}
+}
+
+func TestRulesStarterPackAddsCheckedRules(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	ctrl := &fakeRuleController{}
+	view := New(store, theme.New(theme.Options{}), ctrl)
+	view.SetSize(120, 30)
+
+	if out := view.View(); !strings.Contains(out, "Press S to add starter rules") {
+		t.Fatalf("expected the empty state to offer starter rules, got:\n%s", out)
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	if out := view.View(); !strings.Contains(out, "[x] mDNS multicast (avahi)") {
+		t.Fatalf("expected the checklist, got:\n%s", out)
+	}
+	// Uncheck the second item, then add the rest.
+	view.Update(tea.KeyMsg{Type: tea.KeyDown})
+	view.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	want := []string{"starter-allow-mdns", "starter-allow-ntp", "starter-allow-dhcp"}
+	if strings.Join(ctrl.added, ",") != strings.Join(want, ",") || ctrl.nodeID != "node-1" {
+		t.Fatalf("expected %v added on node-1, got %v on %s", want, ctrl.added, ctrl.nodeID)
+	}
+	if out := view.View(); !strings.Contains(out, "Added 3 starter rules to alpha") {
+		t.Fatalf("expected a summary, got:\n%s", out)
+	}
+}
+
+func TestRulesStarterPackSkipsPresentRules(t *testing.T) {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	present := ruleset.StarterPack()[0].Rule
+	store.SetRules("node-1", []state.Rule{present})
+	ctrl := &fakeRuleController{}
+	view := New(store, theme.New(theme.Options{}), ctrl)
+	view.SetSize(120, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	if out := view.View(); !strings.Contains(out, "[-] mDNS multicast (avahi) (starter-allow-mdns) already present") {
+		t.Fatalf("expected the present rule marked, got:\n%s", out)
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	view.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for _, name := range ctrl.added {
+		if name == present.Name {
+			t.Fatalf("re-added %s", name)
+		}
+	}
+	if len(ctrl.added) != 3 {
+		t.Fatalf("expected the other 3 rules added, got %v", ctrl.added)
+	}
+}
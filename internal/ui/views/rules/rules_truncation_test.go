@@ -24,19 +24,19 @@ func TestRulesTableTruncatesNameColumn(t *testing.T) {
 	model.SetSize(80, 10)
 
 	layout := model.tableColumns()
-	table := model.renderRulesTable(store.Snapshot().Rules[node.ID])
+	table := model.renderRulesTable(store.Snapshot().Rules[node.ID], len(store.Snapshot().Rules[node.ID]))
 	lines := strings.Split(table, "\n")
 	if len(lines) < 2 {
 		t.Fatalf("expected header + at least one row, got: %v", lines)
 	}
-	row := util.StripANSI(lines[1])
-	// name starts after cursor+gap
-	nameStart := layout.cursor + columnGap
+	row := []rune(util.StripANSI(lines[1]))
+	// name starts after the cursor and HIT columns
+	nameStart := layout.cursor + layout.hit + 2*columnGap
 	nameEnd := nameStart + layout.name
 	if nameEnd > len(row) {
 		t.Fatalf("row too short: %q", row)
 	}
-	nameCell := strings.TrimSpace(row[nameStart:nameEnd])
+	nameCell := strings.TrimSpace(string(row[nameStart:nameEnd]))
 	if !strings.Contains(nameCell, "...") {
 		t.Fatalf("expected name column to be truncated with ellipsis, got: %q", nameCell)
 	}
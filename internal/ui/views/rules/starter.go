@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const starterHelp = "↑/↓ move · space toggle · enter add selected · esc cancel"
+
+// starterState is the open starter-rules checklist for one node.
+type starterState struct {
+	nodeID  string
+	items   []ruleset.Starter
+	checked []bool
+	present []bool
+	cursor  int
+}
+
+// openStarter offers the starter pack for the selected node. Rules the node
+// already has are listed but left unchecked.
+func (m *Model) openStarter(snapshot state.Snapshot) {
+	node, _, ok := m.current(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return
+	}
+	items := ruleset.StarterPack()
+	st := &starterState{
+		nodeID:  node.ID,
+		items:   items,
+		checked: make([]bool, len(items)),
+		present: make([]bool, len(items)),
+	}
+	for i, item := range items {
+		_, st.present[i] = ruleset.Lookup(snapshot.Rules, node.ID, item.Rule.Name)
+		st.checked[i] = !st.present[i]
+	}
+	m.starter = st
+	m.statusLine = ""
+}
+
+func (m *Model) updateStarter(key tea.KeyMsg, snapshot state.Snapshot) {
+	st := m.starter
+	switch key.String() {
+	case "esc":
+		m.starter = nil
+	case "up", "k":
+		st.cursor = max(0, st.cursor-1)
+	case "down", "j":
+		st.cursor = min(len(st.items)-1, st.cursor+1)
+	case " ", "space":
+		if !st.present[st.cursor] {
+			st.checked[st.cursor] = !st.checked[st.cursor]
+		}
+	case "enter":
+		m.starter = nil
+		m.addStarterRules(snapshot, st)
+	}
+}
+
+// addStarterRules pushes the checked rules one by one and reports what
+// happened to each.
+func (m *Model) addStarterRules(snapshot state.Snapshot, st *starterState) {
+	label := st.nodeID
+	for _, node := range snapshot.Nodes {
+		if node.ID == st.nodeID {
+			label = util.DisplayName(node)
+		}
+	}
+	var added, failed []string
+	for i, item := range st.items {
+		if !st.checked[i] || st.present[i] {
+			continue
+		}
+		if err := m.controller.AddRule(st.nodeID, item.Rule); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", item.Rule.Name, err))
+			continue
+		}
+		added = append(added, item.Rule.Name)
+	}
+	switch {
+	case len(failed) > 0:
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Added %d starter rules to %s; failed %s", len(added), label, strings.Join(failed, "; ")))
+	case len(added) == 0:
+		m.statusLine = m.theme.Warning.Render("No starter rules selected")
+	default:
+		m.statusLine = m.theme.Success.Render(fmt.Sprintf("Added %d starter rules to %s: %s", len(added), label, strings.Join(added, ", ")))
+	}
+}
+
+func (m *Model) renderStarter() string {
+	st := m.starter
+	inner := max(20, m.contentWidth())
+	lines := []string{m.theme.Header.Render("Starter rules: allow common local-network traffic")}
+	for i, item := range st.items {
+		box := "[ ]"
+		switch {
+		case st.present[i]:
+			box = "[-]"
+		case st.checked[i]:
+			box = "[x]"
+		}
+		cursor := "  "
+		if i == st.cursor {
+			cursor = m.theme.Warning.Render("> ")
+		}
+		line := fmt.Sprintf("%s %s (%s)", box, item.Label, item.Rule.Name)
+		if st.present[i] {
+			line = m.theme.Subtle.Render(line + " already present")
+		}
+		lines = append(lines, cursor+line)
+	}
+	item := st.items[st.cursor]
+	lines = append(lines,
+		util.TruncateString(item.Rule.Description, inner),
+		util.TruncateString(ruleset.DescribeOperator(item.Rule.Operator), inner),
+	)
+	return m.theme.Body.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,153 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const (
+	trashHelp        = "↑/↓ move · r restore · E empty trash · Z/esc back"
+	trashConfirmHelp = "y empty trash · any other key cancels"
+)
+
+// trashState is the open trash of the selected node.
+type trashState struct {
+	cursor int
+	// confirmEmpty is set after E until the next key.
+	confirmEmpty bool
+}
+
+// ruleTrash returns the controller's trash, reporting when it has none.
+func (m *Model) ruleTrash() (controller.RuleTrash, bool) {
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return nil, false
+	}
+	trash, ok := m.controller.(controller.RuleTrash)
+	if !ok {
+		m.statusLine = m.theme.Danger.Render("Trash unavailable")
+	}
+	return trash, ok
+}
+
+func (m *Model) openTrash(snapshot state.Snapshot) {
+	if _, _, ok := m.current(snapshot); !ok {
+		return
+	}
+	if _, ok := m.ruleTrash(); !ok {
+		return
+	}
+	m.trash = &trashState{}
+	m.statusLine = ""
+}
+
+func (m *Model) updateTrash(key tea.KeyMsg, snapshot state.Snapshot) {
+	st := m.trash
+	node, _, ok := m.current(snapshot)
+	if !ok {
+		m.trash = nil
+		return
+	}
+	entries := state.TrashOf(snapshot.Trash, node)
+	if st.confirmEmpty {
+		st.confirmEmpty = false
+		if key.String() == "y" {
+			m.emptyTrash(node)
+		} else {
+			m.statusLine = ""
+		}
+		return
+	}
+	switch key.String() {
+	case "esc", "Z":
+		m.trash = nil
+	case "up", "k":
+		st.cursor = max(0, st.cursor-1)
+	case "down", "j":
+		st.cursor = min(max(0, len(entries)-1), st.cursor+1)
+	case "r":
+		if len(entries) > 0 {
+			m.restoreTrashed(node, entries[min(st.cursor, len(entries)-1)])
+		}
+	case "E":
+		if len(entries) > 0 {
+			st.confirmEmpty = true
+			m.statusLine = m.theme.Warning.Render(fmt.Sprintf("Permanently forget %d deleted rules of %s?", len(entries), util.DisplayName(node)))
+		}
+	}
+}
+
+// undoDelete restores the rule most recently deleted from the selected
+// node; undo is a restore of the newest trash entry.
+func (m *Model) undoDelete(snapshot state.Snapshot) {
+	node, _, ok := m.current(snapshot)
+	if !ok {
+		return
+	}
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return
+	}
+	entries := state.TrashOf(snapshot.Trash, node)
+	if len(entries) == 0 {
+		m.statusLine = m.theme.Warning.Render(fmt.Sprintf("Nothing to undo: the trash of %s is empty", util.DisplayName(node)))
+		return
+	}
+	m.restoreTrashed(node, entries[0])
+}
+
+func (m *Model) restoreTrashed(node state.Node, entry state.TrashedRule) {
+	trash, ok := m.ruleTrash()
+	if !ok {
+		return
+	}
+	if err := trash.RestoreRule(node.ID, entry.Rule.Name); err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to restore %s on %s: %v", entry.Rule.Name, util.DisplayName(node), err))
+		return
+	}
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Restored %s on %s", entry.Rule.Name, util.DisplayName(node)))
+}
+
+func (m *Model) emptyTrash(node state.Node) {
+	trash, ok := m.ruleTrash()
+	if !ok {
+		return
+	}
+	removed, err := trash.EmptyTrash(node.ID)
+	if err != nil {
+		m.statusLine = m.theme.Danger.Render(fmt.Sprintf("Failed to empty the trash of %s: %v", util.DisplayName(node), err))
+		return
+	}
+	m.trash.cursor = 0
+	m.statusLine = m.theme.Success.Render(fmt.Sprintf("Emptied the trash of %s (%d rules)", util.DisplayName(node), removed))
+}
+
+func (m *Model) renderTrash(snapshot state.Snapshot, node state.Node) string {
+	entries := state.TrashOf(snapshot.Trash, node)
+	inner := max(20, m.contentWidth())
+	lines := []string{m.theme.Header.Render(fmt.Sprintf("Trash of %s: deleted rules kept for 7 days", util.DisplayName(node)))}
+	if len(entries) == 0 {
+		lines = append(lines, m.theme.Subtle.Render("No deleted rules."))
+		return m.theme.Body.Render(strings.Join(lines, "\n"))
+	}
+	m.trash.cursor = min(m.trash.cursor, len(entries)-1)
+	now := m.now()
+	for i, entry := range entries {
+		cursor := "  "
+		if i == m.trash.cursor {
+			cursor = m.theme.Warning.Render("> ")
+		}
+		deleted := fmt.Sprintf("deleted %s (%s)", util.RelativeTimeAt(entry.DeletedAt, now), entry.DeletedAt.Local().Format("2006-01-02 15:04"))
+		line := fmt.Sprintf("%s %s · %s", entry.Rule.Name, entry.Rule.Action, m.theme.Subtle.Render(deleted))
+		lines = append(lines, cursor+util.TruncateString(line, inner-2))
+	}
+	entry := entries[m.trash.cursor]
+	lines = append(lines, util.TruncateString(ruleset.DescribeOperator(entry.Rule.Operator), inner))
+	return m.theme.Body.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,113 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+type fakeTrashController struct {
+	fakeRuleController
+	restored []string
+	emptied  string
+}
+
+func (f *fakeTrashController) RestoreRule(nodeID, ruleName string) error {
+	f.restored = append(f.restored, nodeID+"/"+ruleName)
+	return f.err
+}
+
+func (f *fakeTrashController) EmptyTrash(nodeID string) (int, error) {
+	f.emptied = nodeID
+	return 2, f.err
+}
+
+func trashStore(now time.Time) *state.Store {
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	store.SetRules("node-1", []state.Rule{{Name: "ssh", Action: "allow"}})
+	store.SetTrash([]state.TrashedRule{
+		{NodeKey: "name:alpha", Rule: state.Rule{Name: "curl", Action: "deny"}, DeletedAt: now.Add(-time.Hour)},
+		{NodeKey: "name:beta", Rule: state.Rule{Name: "wget", Action: "deny"}, DeletedAt: now.Add(-time.Hour)},
+		{NodeKey: "name:alpha", Rule: state.Rule{Name: "dig", Action: "allow"}, DeletedAt: now.Add(-2 * time.Hour)},
+	})
+	return store
+}
+
+func TestRulesTrashListsAndRestores(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := &fakeTrashController{}
+	view := New(trashStore(now), theme.New(theme.Options{}), ctrl).(*Model)
+	view.now = func() time.Time { return now }
+	view.SetSize(120, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'Z'}})
+	out := view.View()
+	if !strings.Contains(out, "Trash of alpha") || !strings.Contains(out, "curl deny") || !strings.Contains(out, "deleted 1h0m0s ago") {
+		t.Fatalf("expected the trash of alpha, got %q", out)
+	}
+	if strings.Contains(out, "wget") {
+		t.Fatalf("expected only alpha's entries, got %q", out)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyDown})
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if len(ctrl.restored) != 1 || ctrl.restored[0] != "node-1/dig" {
+		t.Fatalf("expected dig restored, got %v", ctrl.restored)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	if !strings.Contains(view.View(), "Permanently forget 2 deleted rules") {
+		t.Fatalf("expected a confirmation, got %q", view.View())
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if ctrl.emptied != "" {
+		t.Fatal("expected any key but y to cancel")
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if ctrl.emptied != "node-1" {
+		t.Fatalf("expected the trash of node-1 emptied, got %q", ctrl.emptied)
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if strings.Contains(view.View(), "Trash of alpha") {
+		t.Fatal("expected esc to close the trash")
+	}
+}
+
+func TestRulesUndoRestoresNewestTrashed(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ctrl := &fakeTrashController{}
+	view := New(trashStore(now), theme.New(theme.Options{}), ctrl)
+	view.SetSize(120, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if len(ctrl.restored) != 1 || ctrl.restored[0] != "node-1/curl" {
+		t.Fatalf("expected the newest entry restored, got %v", ctrl.restored)
+	}
+
+	store := state.NewStore()
+	store.SetNodes([]state.Node{{ID: "node-1", Name: "alpha"}})
+	view = New(store, theme.New(theme.Options{}), ctrl)
+	view.SetSize(120, 30)
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if !strings.Contains(view.View(), "Nothing to undo") {
+		t.Fatalf("expected a nothing-to-undo notice, got %q", view.View())
+	}
+}
+
+func TestRulesTrashUnavailable(t *testing.T) {
+	view := New(trashStore(time.Now()), theme.New(theme.Options{}), &fakeRuleController{})
+	view.SetSize(120, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'Z'}})
+	if out := view.View(); !strings.Contains(out, "Trash unavailable") || strings.Contains(out, "Trash of") {
+		t.Fatalf("expected unavailable notice, got %q", out)
+	}
+}
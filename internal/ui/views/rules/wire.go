@@ -0,0 +1,47 @@
+package rules
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/clipboard"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+)
+
+const wireHelp = "↑/↓ scroll · y copy · esc close"
+
+// openWire shows the selected rule as the pb.Rule sent to the daemon, for
+// pasting into upstream bug reports.
+func (m *Model) openWire(snapshot state.Snapshot) {
+	_, rules, ok := m.current(snapshot)
+	if !ok || len(rules) == 0 {
+		return
+	}
+	if !widget.Guard(m.theme, m.attached, &m.statusLine) {
+		return
+	}
+	if m.inspector == nil {
+		m.statusLine = m.theme.Warning.Render("Wire view unavailable")
+		return
+	}
+	rule := rules[min(m.ruleIdx, len(rules)-1)]
+	m.wire = widget.NewPager("pb.Rule "+rule.Name, m.inspector.RuleWire(rule))
+	m.statusLine = ""
+}
+
+func (m *Model) updateWire(key tea.KeyMsg) tea.Cmd {
+	switch key.String() {
+	case "esc", "ctrl+x":
+		m.wire = nil
+	case "y":
+		m.statusLine = m.theme.Success.Render("Copied prototext to clipboard")
+		return clipboard.Copy(m.wire.Text())
+	default:
+		m.wire.HandleKey(key.String(), m.wireHeight())
+	}
+	return nil
+}
+
+func (m *Model) wireHeight() int {
+	return max(6, m.height-m.tableCapacity()-tableChrome)
+}
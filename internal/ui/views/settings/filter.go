@@ -0,0 +1,140 @@
+package settings
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+const filterHelp = "type to filter by label · ↑/↓ or tab/shift+tab move · enter keep · esc clear"
+
+func newFilterInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "/ "
+	input.Placeholder = "setting label"
+	input.CharLimit = 64
+	input.Width = 32
+	return input
+}
+
+func (m *Model) startFilter() {
+	m.filter.Focus()
+	m.filtering = true
+	m.blurText()
+}
+
+func (m *Model) updateFilter(key tea.KeyMsg) tea.Cmd {
+	switch key.Type {
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter.Blur()
+		m.filter.SetValue("")
+		return nil
+	case tea.KeyEnter:
+		m.filtering = false
+		m.filter.Blur()
+		return nil
+	case tea.KeyUp, tea.KeyShiftTab:
+		m.moveFocus(-1)
+		return nil
+	case tea.KeyDown, tea.KeyTab:
+		m.moveFocus(1)
+		return nil
+	}
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(key)
+	m.keepFocusVisible()
+	return cmd
+}
+
+// matches reports whether r passes the label filter.
+func (m *Model) matches(r row) bool {
+	query := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	return query == "" || strings.Contains(strings.ToLower(r.label), query)
+}
+
+func (m *Model) visibleFields() []field {
+	var out []field
+	for _, r := range rows {
+		if m.matches(r) {
+			out = append(out, r.field)
+		}
+	}
+	return out
+}
+
+// moveFocus steps through the rows the filter leaves visible.
+func (m *Model) moveFocus(delta int) {
+	visible := m.visibleFields()
+	if len(visible) == 0 {
+		return
+	}
+	if pos := slices.Index(visible, m.focus); pos >= 0 {
+		m.focus = visible[util.WrapIndex(pos, delta, len(visible))]
+	} else {
+		m.focus = visible[0]
+	}
+	m.blurText()
+}
+
+// keepFocusVisible leaves focus alone while its row still matches and
+// otherwise moves it to the first match.
+func (m *Model) keepFocusVisible() {
+	visible := m.visibleFields()
+	if len(visible) == 0 || slices.Contains(visible, m.focus) {
+		return
+	}
+	m.focus = visible[0]
+}
+
+// hiddenChanges lists the labels of rows the filter hides whose pending
+// value differs from the saved one.
+func (m *Model) hiddenChanges() []string {
+	settings := m.store.Snapshot().Settings
+	var out []string
+	for _, r := range rows {
+		if !m.matches(r) && r.pending(m) != r.stored(settings) {
+			out = append(out, r.label)
+		}
+	}
+	return out
+}
+
+// saveAll saves every row, visible or not. When the filter hides changed
+// rows the first enter only says so and the second one saves.
+func (m *Model) saveAll() tea.Cmd {
+	if !widget.Guard(m.theme, m.controller != nil, &m.status) {
+		return nil
+	}
+	if hidden := m.hiddenChanges(); len(hidden) > 0 && !m.confirmHidden {
+		m.confirmHidden = true
+		m.status = m.theme.Warning.Render(fmt.Sprintf("%d hidden settings changed (%s) · enter again to save all", len(hidden), strings.Join(hidden, ", ")))
+		return nil
+	}
+	m.confirmHidden = false
+	if m.enablingYara() && m.controller != nil {
+		if !m.validateAll() {
+			return nil
+		}
+		return m.startYaraCheck(false)
+	}
+	m.persistAll()
+	return nil
+}
+
+func (m *Model) renderFilter() string {
+	if m.filtering {
+		return m.filter.View()
+	}
+	if query := m.filter.Value(); query != "" {
+		return m.theme.Subtle.Render(fmt.Sprintf("Filter: %q (/ to change)", query))
+	}
+	return ""
+}
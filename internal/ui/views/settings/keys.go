@@ -0,0 +1,46 @@
+package settings
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/keymap"
+)
+
+// action is one entry of a settings key table.
+type action = keymap.Action[*Model]
+
+// change shifts or adjusts the focused setting and validates the result.
+func change(f func(m *Model)) func(*Model) tea.Cmd {
+	return keymap.Do(func(m *Model) {
+		f(m)
+		m.validateChanged()
+	})
+}
+
+// fieldKeys are the keys of the settings list.
+var fieldKeys = keymap.NewTable(
+	action{ID: "settings.next", Keys: []string{"tab", "down", "j"}, Help: "next setting", Run: keymap.Do(func(m *Model) { m.moveFocus(1) })},
+	action{ID: "settings.prev", Keys: []string{"shift+tab", "up", "k"}, Help: "previous setting", Run: keymap.Do(func(m *Model) { m.moveFocus(-1) })},
+	action{ID: "settings.prev-option", Keys: []string{"left", "p"}, Help: "previous option", Run: change(func(m *Model) { m.shiftSelection(-1) })},
+	action{ID: "settings.next-option", Keys: []string{"right", "n"}, Help: "next option", Run: change(func(m *Model) { m.shiftSelection(1) })},
+	action{ID: "settings.raise", Keys: []string{"+"}, Help: "raise a timeout or duration", Run: change(func(m *Model) { m.adjustSelection(1) })},
+	action{ID: "settings.lower", Keys: []string{"-"}, Help: "lower a timeout or duration", Run: change(func(m *Model) { m.adjustSelection(-1) })},
+	action{ID: "settings.save", Keys: []string{"enter"}, Help: "save all", Run: func(m *Model) tea.Cmd { return m.saveAll() }},
+	action{ID: "settings.save-field", Keys: []string{"s"}, Help: "save the focused setting", Run: func(m *Model) tea.Cmd { return m.saveField() }},
+	action{ID: "settings.clear-filter", Keys: []string{"esc"}, Help: "clear the filter", Run: keymap.Do(func(m *Model) { m.filter.SetValue("") })},
+	action{ID: "settings.filter", Keys: []string{"/"}, Help: "filter settings", Run: keymap.Do(func(m *Model) { m.startFilter() })},
+	action{ID: "settings.retry", Keys: []string{"r"}, Help: "retry a failed save", Run: keymap.Do(func(m *Model) { m.retrySave() })},
+)
+
+// textKeys are the keys of a text row; keys it leaves are typed into the
+// field.
+var textKeys = keymap.NewTable(
+	action{ID: "settings.text.next", Keys: []string{"tab", "down"}, Help: "next setting", Run: keymap.Do(func(m *Model) { m.moveFocus(1) })},
+	action{ID: "settings.text.prev", Keys: []string{"shift+tab", "up"}, Help: "previous setting", Run: keymap.Do(func(m *Model) { m.moveFocus(-1) })},
+	action{ID: "settings.text.save", Keys: []string{"enter"}, Help: "save the field", Run: keymap.Do(func(m *Model) { m.persistField(m.focus) })},
+	action{ID: "settings.text.leave", Keys: []string{"esc"}, Help: "leave the field", Run: keymap.Do(func(m *Model) { rows[m.focus].text(m).Blur() })},
+)
+
+// KeyHelp lists the settings list keys for the help overlay, which does
+// not open over a text row.
+func (m *Model) KeyHelp() []keymap.Help { return fieldKeys.Help() }
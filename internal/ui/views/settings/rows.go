@@ -0,0 +1,218 @@
+package settings
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// row describes one settings row. The control is given by exactly one of
+// index (an option list), toggle or text.
+type row struct {
+	field   field
+	section string
+	label   string
+	// what names the setting in save messages.
+	what    string
+	options []widget.Option
+	index   func(*Model) *int
+	toggle  func(*Model) *bool
+	text    func(*Model) *textinput.Model
+	// stored is the saved value, in the same form as pending.
+	stored func(state.Settings) string
+	save   func(*Model) error
+	// note, when set, is shown dimmed after the control.
+	note func(*Model) string
+	// numeric marks option lists ordered from least to most, which +/-
+	// step through without wrapping.
+	numeric bool
+}
+
+var sections = []string{"General", "Alerts", "Security"}
+
+// rows lists every setting in display order; rows[f] describes field f.
+var rows = []row{
+	{
+		field: fieldTheme, section: "General", label: "Theme", what: "theme",
+		options: themeOptions,
+		index:   func(m *Model) *int { return &m.themeIdx },
+		stored:  func(s state.Settings) string { return s.ThemeName },
+		save:    func(m *Model) error { _, err := m.saveTheme(); return err },
+	},
+	{
+		field: fieldAction, section: "General", label: "Default action", what: "default action",
+		options: promptActions,
+		index:   func(m *Model) *int { return &m.actionIdx },
+		stored:  func(s state.Settings) string { return s.DefaultPromptAction },
+		save:    func(m *Model) error { _, err := m.saveAction(); return err },
+	},
+	{
+		field: fieldDuration, section: "General", label: "Default duration", what: "default duration",
+		options: promptDurations,
+		index:   func(m *Model) *int { return &m.durationIdx },
+		stored:  func(s state.Settings) string { return s.DefaultPromptDuration },
+		save:    func(m *Model) error { _, err := m.saveDuration(); return err },
+	},
+	{
+		field: fieldTarget, section: "General", label: "Default target", what: "default target",
+		options: promptTargets,
+		index:   func(m *Model) *int { return &m.targetIdx },
+		stored:  func(s state.Settings) string { return s.DefaultPromptTarget },
+		save:    func(m *Model) error { _, err := m.saveTarget(); return err },
+	},
+	{
+		field: fieldPromptTimeout, section: "General", label: "Prompt timeout", what: "prompt timeout",
+		options: promptTimeouts,
+		index:   func(m *Model) *int { return &m.timeoutIdx },
+		stored:  func(s state.Settings) string { return strconv.Itoa(timeoutSeconds(s)) },
+		save:    func(m *Model) error { _, err := m.savePromptTimeout(); return err },
+		numeric: true,
+	},
+	{
+		field: fieldPromptFocus, section: "General", label: "Prompt focus", what: "prompt focus",
+		options: promptFocuses,
+		index:   func(m *Model) *int { return &m.focusIdx },
+		stored:  func(s state.Settings) string { return s.PromptInitialFocus },
+		save:    func(m *Model) error { _, err := m.savePromptFocus(); return err },
+	},
+	{
+		field: fieldStartView, section: "General", label: "Start view", what: "start view",
+		options: startViewOptions,
+		index:   func(m *Model) *int { return &m.startViewIdx },
+		stored:  func(s state.Settings) string { return string(s.StartView) },
+		save:    func(m *Model) error { _, err := m.saveStartView(); return err },
+	},
+	{
+		field: fieldDescriptionTemplate, section: "General", label: "Rule description", what: "rule description template",
+		text:   func(m *Model) *textinput.Model { return &m.descTemplate },
+		stored: func(s state.Settings) string { return s.RuleDescriptionTemplate },
+		save:   func(m *Model) error { _, err := m.saveDescriptionTemplate(m.descTemplate.Value()); return err },
+	},
+	{
+		field: fieldAlertsInterrupt, section: "Alerts", label: "Alerts interrupt", what: "alerts interrupt",
+		toggle: func(m *Model) *bool { return &m.alertsInterrupt },
+		stored: func(s state.Settings) string { return strconv.FormatBool(s.AlertsInterrupt) },
+		save:   func(m *Model) error { _, err := m.saveAlertsInterrupt(m.alertsInterrupt); return err },
+	},
+	{
+		field: fieldPauseOnInspect, section: "Alerts", label: "Pause alert timeout on inspect", what: "pause on inspect",
+		toggle: func(m *Model) *bool { return &m.pauseOnInspect },
+		stored: func(s state.Settings) string { return strconv.FormatBool(s.PausePromptOnInspect) },
+		save:   func(m *Model) error { _, err := m.savePauseOnInspect(m.pauseOnInspect); return err },
+	},
+	{
+		field: fieldDND, section: "Alerts", label: "Do not disturb (ctrl+n)", what: "do-not-disturb preset",
+		options: dndPresets,
+		index:   func(m *Model) *int { return &m.dndIdx },
+		stored:  func(s state.Settings) string { return strconv.Itoa(s.DNDMinutes) },
+		save:    func(m *Model) error { _, err := m.saveDNDMinutes(); return err },
+		numeric: true,
+	},
+	{
+		field: fieldYaraEnabled, section: "Security", label: "YARA scanning enabled", what: "YARA scanning",
+		toggle: func(m *Model) *bool { return &m.yaraEnabled },
+		stored: func(s state.Settings) string { return strconv.FormatBool(s.YaraEnabled) },
+		save:   func(m *Model) error { _, err := m.saveYaraEnabled(m.yaraEnabled); return err },
+	},
+	{
+		field: fieldYaraRuleDir, section: "Security", label: "YARA rule directory", what: "YARA rule dir",
+		text:   func(m *Model) *textinput.Model { return &m.yaraRuleDir },
+		stored: func(s state.Settings) string { return s.YaraRuleDir },
+		save:   func(m *Model) error { _, err := m.saveYaraRuleDir(m.yaraRuleDir.Value()); return err },
+	},
+	{
+		field: fieldInspectHook, section: "Security", label: "External scanner hook", what: "scanner hook",
+		toggle: func(m *Model) *bool { return &m.hookEnabled },
+		stored: func(s state.Settings) string { return strconv.FormatBool(s.InspectHookEnabled) },
+		save:   func(m *Model) error { _, err := m.saveInspectHookEnabled(m.hookEnabled); return err },
+		note:   hookNote,
+	},
+}
+
+// pending is the value the row would save.
+func (r row) pending(m *Model) string {
+	switch {
+	case r.index != nil:
+		return r.options[*r.index(m)].Value
+	case r.toggle != nil:
+		return strconv.FormatBool(*r.toggle(m))
+	default:
+		return strings.TrimSpace(r.text(m).Value())
+	}
+}
+
+// display is the pending value as shown in messages.
+func (r row) display(m *Model) string {
+	switch {
+	case r.index != nil:
+		return r.options[*r.index(m)].Label
+	case r.toggle != nil:
+		if *r.toggle(m) {
+			return "on"
+		}
+		return "off"
+	default:
+		return strings.TrimSpace(r.text(m).Value())
+	}
+}
+
+// load sets the row's control to the stored value.
+func (r row) load(m *Model, settings state.Settings) {
+	value := r.stored(settings)
+	switch {
+	case r.index != nil:
+		*r.index(m) = widget.IndexOf(r.options, value)
+	case r.toggle != nil:
+		*r.toggle(m) = value == "true"
+	default:
+		r.text(m).SetValue(value)
+	}
+}
+
+// shift moves an option row by delta or flips a toggle; text rows ignore it.
+func (r row) shift(m *Model, delta int) {
+	switch {
+	case r.index != nil:
+		idx := r.index(m)
+		*idx = util.WrapIndex(*idx, delta, len(r.options))
+	case r.toggle != nil:
+		if delta%2 != 0 {
+			flag := r.toggle(m)
+			*flag = !*flag
+		}
+	}
+}
+
+// adjust steps a numeric row by delta, stopping at either end of its
+// options.
+func (r row) adjust(m *Model, delta int) {
+	if !r.numeric {
+		return
+	}
+	idx := r.index(m)
+	*idx = min(max(*idx+delta, 0), len(r.options)-1)
+}
+
+// hookNote shows the configured scanner command, which can only be changed
+// in the config file.
+func hookNote(m *Model) string {
+	command := m.store.Snapshot().Settings.InspectHook
+	if command == "" {
+		return "(set inspect_hook in the config file)"
+	}
+	return util.TruncateString(command, 48)
+}
+
+func timeoutSeconds(settings state.Settings) int {
+	seconds := int(settings.PromptTimeout / time.Second)
+	if seconds <= 0 {
+		return 30
+	}
+	return seconds
+}
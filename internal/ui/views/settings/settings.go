@@ -1,8 +1,8 @@
 package settings
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -12,12 +12,14 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
 	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/view"
 	"github.com/adamkadaban/opensnitch-tui/internal/ui/widget"
-	"github.com/adamkadaban/opensnitch-tui/internal/util"
+	"github.com/adamkadaban/opensnitch-tui/internal/yara"
 )
 
 // Model renders the settings view for global preferences.
@@ -35,11 +37,32 @@ type Model struct {
 	durationIdx     int
 	targetIdx       int
 	timeoutIdx      int
+	focusIdx        int
+	startViewIdx    int
+	descTemplate    textinput.Model
 	alertsInterrupt bool
 	pauseOnInspect  bool
+	dndIdx          int
 	yaraEnabled     bool
 	yaraRuleDir     textinput.Model
-	status          string
+	hookEnabled     bool
+	// filter narrows the rows to labels containing its text; filtering is
+	// set while it is being typed.
+	filter    textinput.Model
+	filtering bool
+	// confirmHidden is set after enter warned about changes the filter
+	// hides; the next enter saves.
+	confirmHidden bool
+	status        string
+	// fieldErrs holds inline validation messages shown under their rows.
+	fieldErrs map[field]string
+	// unsaved is the last persistence failure of a save in progress; the
+	// values were still applied for the session.
+	unsaved error
+	// yaraCheck is the pending dry run before YARA is switched on;
+	// checkYara runs it.
+	yaraCheck *yaraCheck
+	checkYara func(dir string) yara.Report
 }
 
 type field int
@@ -50,14 +73,17 @@ const (
 	fieldDuration
 	fieldTarget
 	fieldPromptTimeout
+	fieldPromptFocus
+	fieldStartView
+	fieldDescriptionTemplate
 	fieldAlertsInterrupt
 	fieldPauseOnInspect
+	fieldDND
 	fieldYaraEnabled
 	fieldYaraRuleDir
+	fieldInspectHook
 )
 
-const settingsFieldCount = 9
-
 var promptActions = []widget.Option{
 	{Label: "Allow", Value: "allow"},
 	{Label: "Deny", Value: "deny"},
@@ -88,15 +114,39 @@ var promptTimeouts = []widget.Option{
 	{Label: "300s", Value: "300"},
 }
 
+var promptFocuses = []widget.Option{
+	{Label: "Action", Value: "action"},
+	{Label: "Duration", Value: "duration"},
+	{Label: "Target", Value: "target"},
+	{Label: "Confirm", Value: "confirm"},
+}
+
+var dndPresets = []widget.Option{
+	{Label: "15m", Value: "15"},
+	{Label: "30m", Value: "30"},
+	{Label: "1h", Value: "60"},
+	{Label: "Until toggled off", Value: "0"},
+}
+
 var themeOptions = buildThemeOptions()
 
+var startViewOptions = buildStartViewOptions()
+
 // New constructs a settings view model.
 func New(store *state.Store, th theme.Theme, ctrl controller.SettingsManager) view.Model {
-	m := &Model{store: store, theme: th, controller: ctrl}
+	if !controller.Attached(ctrl) {
+		ctrl = nil
+	}
+	m := &Model{store: store, theme: th, controller: ctrl, checkYara: yara.Check}
 	m.yaraRuleDir = textinput.New()
 	m.yaraRuleDir.Placeholder = "/path/to/yara_rules"
 	m.yaraRuleDir.CharLimit = 0
 	m.yaraRuleDir.Width = 40
+	m.descTemplate = textinput.New()
+	m.descTemplate.Placeholder = ruleset.DefaultDescriptionTemplate
+	m.descTemplate.CharLimit = 0
+	m.descTemplate.Width = 60
+	m.filter = newFilterInput()
 	m.syncSelection()
 	return m
 }
@@ -117,102 +167,68 @@ func (m *Model) SetTheme(th theme.Theme) {
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch key := msg.(type) {
+	case yaraCheckedMsg:
+		m.onYaraChecked(key)
 	case tea.KeyMsg:
-		// Special handling for text input field
-		if m.focus == fieldYaraRuleDir {
-			// Manage input focus
-			m.yaraRuleDir.Focus()
-			switch key.Type {
-			case tea.KeyTab:
-				m.yaraRuleDir.Blur()
-				m.focus = (m.focus + 1) % settingsFieldCount
-				return m, nil
-			case tea.KeyShiftTab:
-				m.yaraRuleDir.Blur()
-				m.focus--
-				if m.focus < 0 {
-					m.focus = settingsFieldCount - 1
-				}
-				return m, nil
-			case tea.KeyEnter:
-				m.persistYaraRuleDir()
-				return m, nil
-			case tea.KeyUp:
-				m.yaraRuleDir.Blur()
-				m.focus--
-				if m.focus < 0 {
-					m.focus = settingsFieldCount - 1
-				}
-				return m, nil
-			case tea.KeyDown:
-				m.yaraRuleDir.Blur()
-				m.focus = (m.focus + 1) % settingsFieldCount
-				return m, nil
-			case tea.KeyEsc:
-				m.yaraRuleDir.Blur()
-				return m, nil
-			}
-			m.yaraRuleDir, cmd = m.yaraRuleDir.Update(msg)
-			return m, cmd
+		if m.yaraCheck != nil {
+			m.updateYaraCheck(key)
+			return m, nil
 		}
-		// General navigation (non-text fields): only arrows/tab/enter
-		switch key.Type {
-		case tea.KeyTab:
-			m.focus = (m.focus + 1) % settingsFieldCount
-		case tea.KeyShiftTab:
-			m.focus--
-			if m.focus < 0 {
-				m.focus = settingsFieldCount - 1
-			}
-		case tea.KeyDown:
-			m.focus = (m.focus + 1) % settingsFieldCount
-		case tea.KeyUp:
-			m.focus--
-			if m.focus < 0 {
-				m.focus = settingsFieldCount - 1
-			}
-		case tea.KeyLeft:
-			m.shiftSelection(-1)
-		case tea.KeyRight:
-			m.shiftSelection(1)
-		case tea.KeyEnter:
-			m.persistAll()
+		if key.Type != tea.KeyEnter {
+			m.confirmHidden = false
 		}
-
-		// Blur text input when leaving it
-		if m.focus != fieldYaraRuleDir {
-			m.yaraRuleDir.Blur()
+		if m.filtering {
+			return m, m.updateFilter(key)
 		}
+		// Text rows take the keys their table leaves.
+		if r := rows[m.focus]; r.text != nil {
+			input := r.text(m)
+			input.Focus()
+			if cmd, ok := textKeys.Dispatch(key.String(), m); ok {
+				return m, cmd
+			}
+			*input, cmd = input.Update(msg)
+			m.validateField(m.focus)
+			return m, cmd
+		}
+		// General navigation (non-text fields)
+		cmd, _ = fieldKeys.Dispatch(key.String(), m)
 	}
 
-	return m, nil
+	return m, cmd
 }
 
 func (m *Model) View() string {
-	general := []string{
-		m.renderRow("Theme", themeOptions, m.themeIdx, m.focus == fieldTheme),
-		m.renderRow("Default action", promptActions, m.actionIdx, m.focus == fieldAction),
-		m.renderRow("Default duration", promptDurations, m.durationIdx, m.focus == fieldDuration),
-		m.renderRow("Default target", promptTargets, m.targetIdx, m.focus == fieldTarget),
-		m.renderRow("Prompt timeout", promptTimeouts, m.timeoutIdx, m.focus == fieldPromptTimeout),
+	var body []string
+	for _, section := range sections {
+		var lines []string
+		for _, r := range rows {
+			if r.section == section && m.matches(r) {
+				lines = append(lines, m.renderField(r))
+			}
+		}
+		body = append(body, m.renderSection(section, lines))
 	}
-	alerts := []string{
-		m.renderToggle("Alerts interrupt", m.alertsInterrupt, m.focus == fieldAlertsInterrupt),
-		m.renderToggle("Pause alert timeout on inspect", m.pauseOnInspect, m.focus == fieldPauseOnInspect),
+	if filter := m.renderFilter(); filter != "" {
+		body = append(body, filter)
 	}
-	security := []string{
-		m.renderToggle("YARA scanning enabled", m.yaraEnabled, m.focus == fieldYaraEnabled),
-		m.renderInput("YARA rule directory", m.yaraRuleDir, m.focus == fieldYaraRuleDir),
+	help := "↑/↓ move · ←/→ change · / filter · enter save all · s save field"
+	if m.filtering {
+		help = filterHelp
 	}
-
-	body := []string{
-		m.renderSection("General", general),
-		m.renderSection("Alerts", alerts),
-		m.renderSection("Security", security),
-		m.theme.Subtle.Render("↑/↓ move · ←/→ change · enter save all"),
+	var disabled []string
+	if m.controller == nil {
+		disabled = []string{"enter save all", "s save field"}
+	}
+	body = append(body, widget.RenderHelp(m.theme, help, disabled))
+	if pending := m.renderPendingSave(); pending != "" {
+		body = append(body, pending)
 	}
-	if m.status != "" {
+	switch {
+	case m.status != "":
 		body = append(body, m.status)
+	case m.controller == nil:
+		body = append(body, widget.RenderReadOnly(m.theme))
 	}
 
 	content := strings.Join(body, "\n")
@@ -220,61 +236,86 @@ func (m *Model) View() string {
 }
 
 func (m *Model) syncSelection() {
-	snapshot := m.store.Snapshot()
-	m.themeIdx = widget.IndexOf(themeOptions, snapshot.Settings.ThemeName)
-	m.actionIdx = widget.IndexOf(promptActions, snapshot.Settings.DefaultPromptAction)
-	m.durationIdx = widget.IndexOf(promptDurations, snapshot.Settings.DefaultPromptDuration)
-	m.targetIdx = widget.IndexOf(promptTargets, snapshot.Settings.DefaultPromptTarget)
-	timeoutSeconds := int(snapshot.Settings.PromptTimeout / time.Second)
-	if timeoutSeconds <= 0 {
-		timeoutSeconds = 30
-	}
-	m.timeoutIdx = widget.IndexOf(promptTimeouts, fmt.Sprintf("%d", timeoutSeconds))
-	m.alertsInterrupt = snapshot.Settings.AlertsInterrupt
-	m.pauseOnInspect = snapshot.Settings.PausePromptOnInspect
-	m.yaraEnabled = snapshot.Settings.YaraEnabled
-	m.yaraRuleDir.SetValue(snapshot.Settings.YaraRuleDir)
+	settings := m.store.Snapshot().Settings
+	for _, r := range rows {
+		r.load(m, settings)
+	}
 }
 
 func (m *Model) persistAll() {
-	if m.controller == nil {
-		m.status = m.theme.Danger.Render("Settings controller unavailable")
+	if !widget.Guard(m.theme, m.controller != nil, &m.status) {
 		return
 	}
-	if _, err := m.saveTheme(); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save theme: %v", err))
+	m.unsaved = nil
+	if !m.validateAll() {
 		return
 	}
-	if _, err := m.saveAction(); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save action: %v", err))
-		return
+	for _, r := range rows {
+		if err := r.save(m); err != nil {
+			m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save %s: %v", r.what, err))
+			return
+		}
 	}
-	if _, err := m.saveDuration(); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save duration: %v", err))
+	if m.reportUnsaved() {
 		return
 	}
-	if _, err := m.saveTarget(); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save target: %v", err))
-		return
+	m.status = m.theme.Success.Render("Settings saved")
+}
+
+// accept lets persistence failures through as applied-for-this-session and
+// remembers them for reportUnsaved; other errors are returned.
+func (m *Model) accept(err error) error {
+	if errors.Is(err, controller.ErrNotPersisted) {
+		m.unsaved = err
+		return nil
 	}
-	if _, err := m.savePromptTimeout(); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save timeout: %v", err))
-		return
+	return err
+}
+
+// reportUnsaved warns that the last save only applied in memory and records
+// the reason for the footer the first time it happens. Changes are only
+// promised a retry while one is scheduled.
+func (m *Model) reportUnsaved() bool {
+	if m.unsaved == nil {
+		return false
 	}
-	if _, err := m.saveAlertsInterrupt(m.alertsInterrupt); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save alerts setting: %v", err))
-		return
+	if save := m.store.Snapshot().ConfigSave; save.Dirty && !save.NextRetry.IsZero() {
+		m.status = m.theme.Warning.Render("Applied; the config could not be written and will be retried")
+		return true
 	}
-	if _, err := m.savePauseOnInspect(m.pauseOnInspect); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save pause-on-inspect: %v", err))
-		return
+	m.status = m.theme.Warning.Render("Applied for this session only (config not writable)")
+	if m.store.Snapshot().ConfigWarning == "" {
+		m.store.SetConfigWarning(fmt.Sprintf("settings not saved: %v", m.unsaved))
+	}
+	return true
+}
+
+// renderPendingSave describes settings changes still waiting for a retried
+// save, empty when everything is on disk.
+func (m *Model) renderPendingSave() string {
+	save := m.store.Snapshot().ConfigSave
+	if !save.Dirty {
+		return ""
+	}
+	line := fmt.Sprintf("Unsaved changes — retrying (%d failed", save.Attempts)
+	if !save.NextRetry.IsZero() {
+		line += ", next at " + save.NextRetry.Format("15:04:05")
+	}
+	line += ")"
+	if _, ok := m.controller.(controller.SaveRetrier); ok {
+		line += " · r retry now"
 	}
-	if _, err := m.saveYaraEnabled(m.yaraEnabled); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save YARA enabled: %v", err))
+	return m.theme.Warning.Render(line)
+}
+
+// retrySave asks the settings manager to write pending changes now.
+func (m *Model) retrySave() {
+	retrier, ok := m.controller.(controller.SaveRetrier)
+	if !ok || !m.store.Snapshot().ConfigSave.Dirty {
 		return
 	}
-	if _, err := m.saveYaraRuleDir(m.yaraRuleDir.Value()); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save YARA rule dir: %v", err))
+	if err := retrier.RetrySave(); err != nil {
+		m.status = m.theme.Danger.Render(fmt.Sprintf("Retry failed: %v", err))
 		return
 	}
 	m.status = m.theme.Success.Render("Settings saved")
@@ -290,53 +331,81 @@ func (m *Model) contentWidth() int {
 // (wrap and max replaced by util.WrapIndex and Go built-in max)
 
 func (m *Model) shiftSelection(delta int) {
-	switch m.focus {
-	case fieldTheme:
-		m.themeIdx = util.WrapIndex(m.themeIdx, delta, len(themeOptions))
-	case fieldAction:
-		m.actionIdx = util.WrapIndex(m.actionIdx, delta, len(promptActions))
-	case fieldDuration:
-		m.durationIdx = util.WrapIndex(m.durationIdx, delta, len(promptDurations))
-	case fieldTarget:
-		m.targetIdx = util.WrapIndex(m.targetIdx, delta, len(promptTargets))
-	case fieldPromptTimeout:
-		m.timeoutIdx = util.WrapIndex(m.timeoutIdx, delta, len(promptTimeouts))
-	case fieldAlertsInterrupt:
-		current := 0
-		if m.alertsInterrupt {
-			current = 1
-		}
-		current = util.WrapIndex(current, delta, 2)
-		m.alertsInterrupt = current == 1
-	case fieldPauseOnInspect:
-		current := 0
-		if m.pauseOnInspect {
-			current = 1
+	rows[m.focus].shift(m, delta)
+}
+
+// adjustSelection steps a numeric row; other rows ignore it.
+func (m *Model) adjustSelection(delta int) {
+	rows[m.focus].adjust(m, delta)
+}
+
+// EnteringText reports whether the filter or a text row has the keyboard.
+func (m *Model) EnteringText() bool {
+	return m.filtering || rows[m.focus].text != nil
+}
+
+// blurText takes the cursor out of every text row but the focused one.
+func (m *Model) blurText() {
+	for _, r := range rows {
+		if r.text != nil && (r.field != m.focus || m.filtering) {
+			r.text(m).Blur()
 		}
-		current = util.WrapIndex(current, delta, 2)
-		m.pauseOnInspect = current == 1
-	case fieldYaraEnabled:
-		current := 0
-		if m.yaraEnabled {
-			current = 1
+	}
+}
+
+// saveField saves only the focused setting; switching YARA on still goes
+// through its check first.
+func (m *Model) saveField() tea.Cmd {
+	if !widget.Guard(m.theme, m.controller != nil, &m.status) {
+		return nil
+	}
+	if m.focus == fieldYaraEnabled && m.enablingYara() && m.controller != nil {
+		if !m.checkYaraField() {
+			return nil
 		}
-		current = util.WrapIndex(current, delta, 2)
-		m.yaraEnabled = current == 1
+		return m.startYaraCheck(true)
 	}
+	m.persistField(m.focus)
+	return nil
 }
 
-func (m *Model) persistYaraRuleDir() {
-	if value, err := m.saveYaraRuleDir(m.yaraRuleDir.Value()); err != nil {
-		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save YARA rule dir: %v", err))
-	} else {
-		m.status = m.theme.Success.Render(fmt.Sprintf("YARA rule dir set to %s", value))
+// persistField saves f alone, leaving the other settings as stored.
+func (m *Model) persistField(f field) {
+	if !widget.Guard(m.theme, m.controller != nil, &m.status) {
+		return
+	}
+	m.unsaved = nil
+	r := rows[f]
+	if m.validateField(f) != "" {
+		m.status = m.theme.Danger.Render("Fix the highlighted field before saving")
+		return
+	}
+	if f == fieldYaraEnabled && !m.checkYaraField() {
+		return
+	}
+	if err := r.save(m); err != nil {
+		m.status = m.theme.Danger.Render(fmt.Sprintf("Failed to save %s: %v", r.what, err))
+		return
+	}
+	if !m.reportUnsaved() {
+		m.status = m.theme.Success.Render(fmt.Sprintf("%s set to %s", strings.ToUpper(r.what[:1])+r.what[1:], r.display(m)))
 	}
 }
 
+// checkYaraField refuses to switch YARA on by itself while the saved rule
+// directory would not allow it.
+func (m *Model) checkYaraField() bool {
+	if err := config.ValidateYara(m.yaraEnabled, m.store.Snapshot().Settings.YaraRuleDir); err != nil {
+		m.status = m.theme.Danger.Render("Save the YARA rule directory first: " + err.Error())
+		return false
+	}
+	return true
+}
+
 func (m *Model) saveAction() (string, error) {
 	choice := promptActions[m.actionIdx].Value
 	value, err := m.controller.SetDefaultPromptAction(choice)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return "", err
 	}
 	m.actionIdx = widget.IndexOf(promptActions, value)
@@ -349,7 +418,7 @@ func (m *Model) saveAction() (string, error) {
 func (m *Model) saveDuration() (string, error) {
 	choice := promptDurations[m.durationIdx].Value
 	value, err := m.controller.SetDefaultPromptDuration(choice)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return "", err
 	}
 	m.durationIdx = widget.IndexOf(promptDurations, value)
@@ -362,7 +431,7 @@ func (m *Model) saveDuration() (string, error) {
 func (m *Model) saveTarget() (string, error) {
 	choice := promptTargets[m.targetIdx].Value
 	value, err := m.controller.SetDefaultPromptTarget(choice)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return "", err
 	}
 	m.targetIdx = widget.IndexOf(promptTargets, value)
@@ -375,7 +444,7 @@ func (m *Model) saveTarget() (string, error) {
 func (m *Model) saveTheme() (string, error) {
 	choice := themeOptions[m.themeIdx].Value
 	value, err := m.controller.SetTheme(choice)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return "", err
 	}
 	m.themeIdx = widget.IndexOf(themeOptions, value)
@@ -388,7 +457,7 @@ func (m *Model) saveTheme() (string, error) {
 func (m *Model) savePromptTimeout() (int, error) {
 	seconds := optionSeconds(promptTimeouts[m.timeoutIdx])
 	value, err := m.controller.SetPromptTimeout(seconds)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return 0, err
 	}
 	m.timeoutIdx = widget.IndexOf(promptTimeouts, fmt.Sprintf("%d", value))
@@ -398,9 +467,34 @@ func (m *Model) savePromptTimeout() (int, error) {
 	return value, nil
 }
 
+func (m *Model) savePromptFocus() (string, error) {
+	value, err := m.controller.SetPromptInitialFocus(promptFocuses[m.focusIdx].Value)
+	if err = m.accept(err); err != nil {
+		return "", err
+	}
+	m.focusIdx = widget.IndexOf(promptFocuses, value)
+	m.updateSettings(func(settings *state.Settings) {
+		settings.PromptInitialFocus = value
+	})
+	return value, nil
+}
+
+func (m *Model) saveStartView() (string, error) {
+	choice := startViewOptions[m.startViewIdx].Value
+	value, err := m.controller.SetStartView(choice)
+	if err = m.accept(err); err != nil {
+		return "", err
+	}
+	m.startViewIdx = widget.IndexOf(startViewOptions, value)
+	m.updateSettings(func(settings *state.Settings) {
+		settings.StartView = state.ViewKind(value)
+	})
+	return value, nil
+}
+
 func (m *Model) saveAlertsInterrupt(enabled bool) (bool, error) {
 	value, err := m.controller.SetAlertsInterrupt(enabled)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return false, err
 	}
 	m.alertsInterrupt = value
@@ -412,7 +506,7 @@ func (m *Model) saveAlertsInterrupt(enabled bool) (bool, error) {
 
 func (m *Model) savePauseOnInspect(enabled bool) (bool, error) {
 	value, err := m.controller.SetPausePromptOnInspect(enabled)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return false, err
 	}
 	m.pauseOnInspect = value
@@ -422,9 +516,25 @@ func (m *Model) savePauseOnInspect(enabled bool) (bool, error) {
 	return value, nil
 }
 
+func (m *Model) saveDNDMinutes() (int, error) {
+	minutes, err := strconv.Atoi(dndPresets[m.dndIdx].Value)
+	if err != nil {
+		return 0, err
+	}
+	value, err := m.controller.SetDNDMinutes(minutes)
+	if err = m.accept(err); err != nil {
+		return 0, err
+	}
+	m.dndIdx = widget.IndexOf(dndPresets, strconv.Itoa(value))
+	m.updateSettings(func(settings *state.Settings) {
+		settings.DNDMinutes = value
+	})
+	return value, nil
+}
+
 func (m *Model) saveYaraEnabled(enabled bool) (bool, error) {
 	value, err := m.controller.SetYaraEnabled(enabled)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return false, err
 	}
 	m.yaraEnabled = value
@@ -436,20 +546,11 @@ func (m *Model) saveYaraEnabled(enabled bool) (bool, error) {
 
 func (m *Model) saveYaraRuleDir(path string) (string, error) {
 	path = strings.TrimSpace(path)
-	if path != "" {
-		info, err := os.Stat(path)
-		if err != nil {
-			return "", fmt.Errorf("%s: %w", path, err)
-		}
-		if !info.IsDir() {
-			return "", fmt.Errorf("%s is not a directory", path)
-		}
-	}
-	if m.yaraEnabled && path == "" {
-		return "", fmt.Errorf("YARA rule directory required when YARA scanning is enabled")
+	if err := config.ValidateYara(m.yaraEnabled, path); err != nil {
+		return "", err
 	}
 	value, err := m.controller.SetYaraRuleDir(path)
-	if err != nil {
+	if err = m.accept(err); err != nil {
 		return "", err
 	}
 	m.yaraRuleDir.SetValue(value)
@@ -459,6 +560,48 @@ func (m *Model) saveYaraRuleDir(path string) (string, error) {
 	return value, nil
 }
 
+func (m *Model) saveDescriptionTemplate(text string) (string, error) {
+	value, err := m.controller.SetRuleDescriptionTemplate(strings.TrimSpace(text))
+	if err = m.accept(err); err != nil {
+		return "", err
+	}
+	m.descTemplate.SetValue(value)
+	m.updateSettings(func(settings *state.Settings) {
+		settings.RuleDescriptionTemplate = value
+	})
+	return value, nil
+}
+
+func (m *Model) saveInspectHookEnabled(enabled bool) (bool, error) {
+	value, err := m.controller.SetInspectHookEnabled(enabled)
+	if err = m.accept(err); err != nil {
+		return false, err
+	}
+	m.hookEnabled = value
+	m.updateSettings(func(settings *state.Settings) {
+		settings.InspectHookEnabled = value
+	})
+	return value, nil
+}
+
+// renderField renders r with its control, note and inline error.
+func (m *Model) renderField(r row) string {
+	focused := m.focus == r.field
+	var out string
+	switch {
+	case r.index != nil:
+		out = m.renderRow(r.label, r.options, *r.index(m), focused)
+	case r.toggle != nil:
+		out = m.renderToggle(r.label, *r.toggle(m), focused)
+	default:
+		out = m.renderInput(r.label, *r.text(m), focused)
+	}
+	if r.note != nil {
+		out += "  " + m.theme.Subtle.Render(r.note(m))
+	}
+	return m.withError(r.field, out)
+}
+
 func (m *Model) updateSettings(mut func(*state.Settings)) {
 	if mut == nil {
 		return
@@ -468,10 +611,13 @@ func (m *Model) updateSettings(mut func(*state.Settings)) {
 	m.store.SetSettings(settings)
 }
 
-func (m *Model) renderSection(title string, rows []string) string {
-	content := strings.Join(rows, "\n")
+// renderSection dims the heading of a section the filter left empty.
+func (m *Model) renderSection(title string, lines []string) string {
+	if len(lines) == 0 {
+		return m.theme.Subtle.Render(title)
+	}
 	head := m.theme.Title.Render(title)
-	return fmt.Sprintf("%s\n%s", head, content)
+	return fmt.Sprintf("%s\n%s", head, strings.Join(lines, "\n"))
 }
 
 func (m *Model) renderToggle(label string, enabled bool, focused bool) string {
@@ -506,5 +652,14 @@ func buildThemeOptions() []widget.Option {
 	for _, preset := range presets {
 		opts = append(opts, widget.Option{Label: preset.Label, Value: preset.Name})
 	}
+	return append(opts, widget.Option{Label: theme.Label(config.ThemeAuto), Value: config.ThemeAuto})
+}
+
+func buildStartViewOptions() []widget.Option {
+	opts := make([]widget.Option, 0, len(state.DefaultViewOrder))
+	for _, kind := range state.DefaultViewOrder {
+		name := string(kind)
+		opts = append(opts, widget.Option{Label: strings.ToUpper(name[:1]) + name[1:], Value: name})
+	}
 	return opts
 }
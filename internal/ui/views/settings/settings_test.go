@@ -1,13 +1,25 @@
 package settings
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
 
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	appsettings "github.com/adamkadaban/opensnitch-tui/internal/settings"
 	"github.com/adamkadaban/opensnitch-tui/internal/state"
 	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/yara"
 )
 
 type fakeSettingsController struct {
@@ -31,11 +43,25 @@ func (f *fakeSettingsController) SetDefaultPromptTarget(target string) (string,
 }
 func (f *fakeSettingsController) SetAlertsInterrupt(enabled bool) (bool, error) { return enabled, nil }
 func (f *fakeSettingsController) SetPromptTimeout(seconds int) (int, error)     { return seconds, nil }
+func (f *fakeSettingsController) SetPromptInitialFocus(focus string) (string, error) {
+	return focus, nil
+}
 func (f *fakeSettingsController) SetPausePromptOnInspect(enabled bool) (bool, error) {
 	return enabled, nil
 }
 func (f *fakeSettingsController) SetYaraRuleDir(path string) (string, error) { return path, nil }
 func (f *fakeSettingsController) SetYaraEnabled(enabled bool) (bool, error)  { return enabled, nil }
+func (f *fakeSettingsController) SetInspectHookEnabled(enabled bool) (bool, error) {
+	return enabled, nil
+}
+func (f *fakeSettingsController) SetDNDMinutes(minutes int) (int, error)   { return minutes, nil }
+func (f *fakeSettingsController) SetStartView(name string) (string, error) { return name, nil }
+func (f *fakeSettingsController) SetRuleDescriptionTemplate(text string) (string, error) {
+	return text, nil
+}
+func (f *fakeSettingsController) SaveProfile(profile state.Profile) (state.Profile, error) {
+	return profile, nil
+}
 
 func TestSettingsViewRenderContainsFields(t *testing.T) {
 	store := state.NewStore()
@@ -44,7 +70,7 @@ func TestSettingsViewRenderContainsFields(t *testing.T) {
 	m.SetSize(80, 20)
 
 	out := m.View()
-	checks := []string{"Theme", "Default action", "Default duration", "Default target", "Prompt timeout", "Alerts interrupt", "Pause alert timeout on inspect", "YARA scanning enabled", "YARA rule directory"}
+	checks := []string{"Theme", "Default action", "Default duration", "Default target", "Prompt timeout", "Prompt focus", "Start view", "Alerts interrupt", "Pause alert timeout on inspect", "YARA scanning enabled", "YARA rule directory"}
 	for _, c := range checks {
 		if !strings.Contains(out, c) {
 			t.Fatalf("expected view to contain %q, got: %s", c, out)
@@ -69,3 +95,608 @@ func TestSettingsViewPersistThemeOnSaveFocused(t *testing.T) {
 		t.Fatalf("expected lastTheme to be set")
 	}
 }
+
+// unwritableSettings applies values but reports that saving failed.
+type unwritableSettings struct {
+	fakeSettingsController
+}
+
+func (u *unwritableSettings) SetTheme(name string) (string, error) {
+	return name, &controller.NotPersistedError{Path: "/ro/config.yaml", Err: errors.New("permission denied")}
+}
+
+func TestSettingsViewWarnsWhenNotPersisted(t *testing.T) {
+	store := state.NewStore()
+	m := New(store, theme.New(theme.Options{}), &unwritableSettings{}).(*Model)
+	m.SetSize(100, 30)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRight}) // next theme
+	want := themeOptions[1].Value
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	out := m.View()
+	if !strings.Contains(out, "Applied for this session only (config not writable)") {
+		t.Fatalf("expected session-only warning, got: %s", out)
+	}
+	snapshot := store.Snapshot()
+	if snapshot.Settings.ThemeName != want {
+		t.Fatalf("expected theme %q applied to the store, got %q", want, snapshot.Settings.ThemeName)
+	}
+	if !strings.Contains(snapshot.ConfigWarning, "/ro/config.yaml") || !strings.Contains(snapshot.ConfigWarning, "permission denied") {
+		t.Fatalf("expected footer warning with path and cause, got %q", snapshot.ConfigWarning)
+	}
+}
+
+// stoppedSettings fails to save after its retries have been stopped, so the
+// change stays unsaved with nothing scheduled.
+type stoppedSettings struct {
+	fakeSettingsController
+	store *state.Store
+}
+
+func (s *stoppedSettings) SetTheme(name string) (string, error) {
+	err := &controller.NotPersistedError{Path: "/ro/config.yaml", Err: errors.New("permission denied")}
+	s.store.SetConfigSave(state.ConfigSave{Dirty: true, Attempts: 1, Err: err.Error()})
+	return name, err
+}
+
+func TestSettingsViewWarnsSessionOnlyWithoutScheduledRetry(t *testing.T) {
+	store := state.NewStore()
+	m := New(store, theme.New(theme.Options{}), &stoppedSettings{store: store}).(*Model)
+	m.SetSize(100, 30)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if out := m.View(); !strings.Contains(out, "Applied for this session only (config not writable)") {
+		t.Fatalf("expected session-only warning, got: %s", out)
+	}
+	if got := store.Snapshot().ConfigWarning; got != "settings not saved: save /ro/config.yaml: permission denied" {
+		t.Fatalf("expected footer warning with path and cause, got %q", got)
+	}
+}
+
+func TestSettingsViewSaveJumpsToInvalidField(t *testing.T) {
+	store := state.NewStore()
+	ctrl := &fakeSettingsController{}
+	m := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	m.SetSize(100, 40)
+
+	m.focus = fieldYaraEnabled
+	m.yaraEnabled = false
+	m.Update(tea.KeyMsg{Type: tea.KeyRight}) // enable YARA with no rule directory
+	if !m.yaraEnabled {
+		t.Fatalf("expected YARA to be enabled")
+	}
+	if _, ok := m.fieldErrs[fieldYaraRuleDir]; !ok {
+		t.Fatalf("expected inline error on the rule directory after enabling YARA")
+	}
+
+	m.focus = fieldTheme
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if ctrl.setThemeCalls != 0 {
+		t.Fatalf("expected save to be refused while a field is invalid")
+	}
+	if m.focus != fieldYaraRuleDir {
+		t.Fatalf("expected focus on the invalid field, got %v", m.focus)
+	}
+	if !strings.Contains(m.View(), "↳ YARA rule directory required") {
+		t.Fatalf("expected inline error under the row, got: %s", m.View())
+	}
+}
+
+func TestSettingsViewInlineErrorClearsOnceCorrected(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	m.focus = fieldYaraRuleDir
+	m.yaraRuleDir.Focus()
+	m.yaraRuleDir.SetValue("")
+	for _, r := range "/nonexistent-yara-dir" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if _, ok := m.fieldErrs[fieldYaraRuleDir]; !ok {
+		t.Fatalf("expected inline error for a missing directory")
+	}
+
+	dir := t.TempDir()
+	m.yaraRuleDir.SetValue("")
+	for _, r := range dir {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if msg, ok := m.fieldErrs[fieldYaraRuleDir]; ok {
+		t.Fatalf("expected error to clear once corrected, got %q", msg)
+	}
+	if strings.Contains(m.View(), "↳") {
+		t.Fatalf("expected no inline errors rendered")
+	}
+}
+
+func TestSettingsViewScannerHookNeedsConfiguredCommand(t *testing.T) {
+	store := state.NewStore()
+	m := New(store, theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	m.focus = fieldInspectHook
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if !m.hookEnabled {
+		t.Fatalf("expected the scanner hook toggle to flip")
+	}
+	if !strings.Contains(m.View(), "↳ set inspect_hook in the config file first") {
+		t.Fatalf("expected inline error without a configured command, got: %s", m.View())
+	}
+
+	settings := store.Snapshot().Settings
+	settings.InspectHook = "clamscan {path}"
+	store.SetSettings(settings)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !store.Snapshot().Settings.InspectHookEnabled {
+		t.Fatalf("expected the hook to be enabled once a command is configured")
+	}
+	if !strings.Contains(m.View(), "clamscan {path}") {
+		t.Fatalf("expected the configured command to be shown, got: %s", m.View())
+	}
+}
+
+func TestSettingsRowsFollowFieldOrder(t *testing.T) {
+	for i, r := range rows {
+		if r.field != field(i) {
+			t.Fatalf("row %d (%s) describes field %d", i, r.label, r.field)
+		}
+	}
+}
+
+func typeFilter(m *Model, query string) {
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	for _, r := range query {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestSettingsFilterPreservesFocus(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	m.focus = fieldTarget
+	typeFilter(m, "default")
+	if m.focus != fieldTarget {
+		t.Fatalf("expected focus to stay on a still-visible row, got %v", m.focus)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc}) // clear
+	typeFilter(m, "yara")
+	if m.focus != fieldYaraEnabled {
+		t.Fatalf("expected focus on the first match when the focused row is hidden, got %v", m.focus)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // keep the filter
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.focus != fieldYaraRuleDir {
+		t.Fatalf("expected down to reach the next match, got %v", m.focus)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.focus != fieldYaraEnabled {
+		t.Fatalf("expected navigation to wrap within the matches, got %v", m.focus)
+	}
+
+	out := m.View()
+	if strings.Contains(out, "Default action") || !strings.Contains(out, "YARA rule directory") {
+		t.Fatalf("expected only matching rows, got: %s", out)
+	}
+	if !strings.Contains(out, "General") || !strings.Contains(out, `Filter: "yara"`) {
+		t.Fatalf("expected empty sections and the filter to stay listed, got: %s", out)
+	}
+}
+
+func TestSettingsSaveAllConfirmsHiddenChanges(t *testing.T) {
+	store := state.NewStore()
+	ctrl := &fakeSettingsController{}
+	m := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	m.SetSize(100, 40)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRight}) // change the theme
+	want := themeOptions[m.themeIdx].Value
+	typeFilter(m, "alerts")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter}) // keep the filter
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if ctrl.setThemeCalls != 0 {
+		t.Fatalf("expected the first enter to warn instead of saving")
+	}
+	if !strings.Contains(m.View(), "1 hidden settings changed (Theme)") {
+		t.Fatalf("expected the hidden change to be named, got: %s", m.View())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if ctrl.setThemeCalls != 1 || store.Snapshot().Settings.ThemeName != want {
+		t.Fatalf("expected the second enter to save hidden rows too, got %d calls, theme %q", ctrl.setThemeCalls, store.Snapshot().Settings.ThemeName)
+	}
+}
+
+// enableYaraWith switches YARA on with a temp rule directory and presses
+// enter, returning the check command.
+func enableYaraWith(t *testing.T, m *Model, report yara.Report) tea.Cmd {
+	t.Helper()
+	var checked string
+	m.checkYara = func(dir string) yara.Report {
+		checked = dir
+		return report
+	}
+	dir := t.TempDir()
+	m.yaraRuleDir.SetValue(dir)
+	m.focus = fieldYaraEnabled
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("expected enabling YARA to start a check")
+	}
+	m.Update(cmd())
+	if checked != dir {
+		t.Fatalf("expected the rule dir checked, got %q", checked)
+	}
+	return cmd
+}
+
+func TestSettingsEnablingYaraConfirmsAfterCheck(t *testing.T) {
+	store := state.NewStore()
+	m := New(store, theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	enableYaraWith(t, m, yara.Report{Available: true, Files: 142, Sampled: 20, Compile: 1800 * time.Millisecond})
+	if store.Snapshot().Settings.YaraEnabled {
+		t.Fatalf("expected nothing saved before the confirmation")
+	}
+	if out := m.View(); !strings.Contains(out, "142 rule files, support: built-in, sample compile 1.8s (20 of 142 files) — enable? y/n") {
+		t.Fatalf("expected the check findings, got: %s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if !store.Snapshot().Settings.YaraEnabled {
+		t.Fatalf("expected YARA saved after confirming")
+	}
+
+	// Saving again with YARA already on does not re-check.
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatalf("expected no check once YARA is enabled")
+	}
+}
+
+func TestSettingsEnablingYaraDeclined(t *testing.T) {
+	store := state.NewStore()
+	m := New(store, theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	enableYaraWith(t, m, yara.Report{Files: 3})
+	if out := m.View(); !strings.Contains(out, "3 rule files, support: missing") {
+		t.Fatalf("expected the stub build reported, got: %s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.yaraEnabled || store.Snapshot().Settings.YaraEnabled {
+		t.Fatalf("expected YARA left off after declining")
+	}
+	if !strings.Contains(m.View(), "YARA scanning left off") {
+		t.Fatalf("expected the decline acknowledged, got: %s", m.View())
+	}
+}
+
+// retryingSettings fails to save until retried.
+type retryingSettings struct {
+	unwritableSettings
+	store   *state.Store
+	retries int
+}
+
+func (r *retryingSettings) RetrySave() error {
+	r.retries++
+	r.store.SetConfigSave(state.ConfigSave{})
+	return nil
+}
+
+func TestSettingsViewRetriesPendingSave(t *testing.T) {
+	store := state.NewStore()
+	ctrl := &retryingSettings{store: store}
+	m := New(store, theme.New(theme.Options{}), ctrl).(*Model)
+	m.SetSize(100, 40)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if ctrl.retries != 0 {
+		t.Fatalf("expected no retry without a pending save")
+	}
+
+	store.SetConfigSave(state.ConfigSave{Dirty: true, Attempts: 2, NextRetry: time.Date(2026, 5, 1, 12, 0, 10, 0, time.Local), Err: "save /ro/config.yaml: permission denied"})
+	out := m.View()
+	if !strings.Contains(out, "Unsaved changes — retrying (2 failed, next at 12:00:10) · r retry now") {
+		t.Fatalf("expected the pending save shown, got: %s", out)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if ctrl.retries != 1 {
+		t.Fatalf("expected r to retry the save, got %d retries", ctrl.retries)
+	}
+	out = m.View()
+	if strings.Contains(out, "Unsaved changes") || !strings.Contains(out, "Settings saved") {
+		t.Fatalf("expected the pending save cleared, got: %s", out)
+	}
+}
+
+func pressRune(m *Model, r rune) {
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+}
+
+func TestSettingsNavigateWithoutArrowKeys(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	m.focus = fieldTheme
+	pressRune(m, 'j')
+	if m.focus != fieldAction {
+		t.Fatalf("expected j to move to the next row, got %v", m.focus)
+	}
+	pressRune(m, 'k')
+	if m.focus != fieldTheme {
+		t.Fatalf("expected k to move to the previous row, got %v", m.focus)
+	}
+
+	m.themeIdx = 0
+	pressRune(m, 'n')
+	if m.themeIdx != 1 {
+		t.Fatalf("expected n to pick the next theme, got %d", m.themeIdx)
+	}
+	pressRune(m, 'p')
+	pressRune(m, 'p')
+	if m.themeIdx != len(themeOptions)-1 {
+		t.Fatalf("expected p to wrap to the last theme, got %d", m.themeIdx)
+	}
+	pressRune(m, '+')
+	if m.themeIdx != len(themeOptions)-1 {
+		t.Fatalf("expected + to leave a non-numeric row alone, got %d", m.themeIdx)
+	}
+
+	m.focus = fieldAlertsInterrupt
+	m.alertsInterrupt = false
+	pressRune(m, 'n')
+	if !m.alertsInterrupt {
+		t.Fatalf("expected n to flip a toggle")
+	}
+}
+
+func TestSettingsPlusMinusStepNumericRows(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	m.focus = fieldPromptTimeout
+	m.timeoutIdx = 0
+	pressRune(m, '-')
+	if m.timeoutIdx != 0 {
+		t.Fatalf("expected - to stop at the shortest timeout, got %d", m.timeoutIdx)
+	}
+	pressRune(m, '+')
+	if m.timeoutIdx != 1 {
+		t.Fatalf("expected + to raise the timeout, got %d", m.timeoutIdx)
+	}
+	m.focus = fieldDND
+	m.dndIdx = len(dndPresets) - 1
+	pressRune(m, '+')
+	if m.dndIdx != len(dndPresets)-1 {
+		t.Fatalf("expected + to stop at the last preset, got %d", m.dndIdx)
+	}
+	pressRune(m, '-')
+	if m.dndIdx != len(dndPresets)-2 {
+		t.Fatalf("expected - to lower the preset, got %d", m.dndIdx)
+	}
+}
+
+func TestSettingsTextEntryKeepsTab(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+
+	m.focus = fieldTheme
+	if m.EnteringText() {
+		t.Fatalf("expected option rows to leave tab to view switching")
+	}
+	typeFilter(m, "yara")
+	if !m.EnteringText() {
+		t.Fatalf("expected the filter to hold the keyboard")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.focus != fieldYaraRuleDir {
+		t.Fatalf("expected tab to reach the next match, got %v", m.focus)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.focus != fieldYaraEnabled {
+		t.Fatalf("expected shift+tab to go back, got %v", m.focus)
+	}
+}
+
+// persistedView wires the view to a settings manager writing a config file
+// with a saved YARA rule directory and scanner hook, so every row can be
+// saved on its own. read returns the YAML as stored.
+func persistedView(t *testing.T) (m *Model, store *state.Store, read func() map[string]any) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	cfg := config.Default()
+	cfg.YaraRuleDir = t.TempDir()
+	cfg.InspectHook = "clamscan {path}"
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	store = state.NewStore()
+	settings := store.Snapshot().Settings
+	settings.YaraRuleDir = cfg.YaraRuleDir
+	settings.InspectHook = cfg.InspectHook
+	store.SetSettings(settings)
+	m = New(store, theme.New(theme.Options{}), appsettings.NewManager(path, cfg)).(*Model)
+	m.SetSize(100, 40)
+	m.checkYara = func(string) yara.Report { return yara.Report{Available: true, Files: 1} }
+	read = func() map[string]any {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := map[string]any{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	return m, store, read
+}
+
+// changedKeys lists the YAML keys whose values differ between before and
+// after.
+func changedKeys(before, after map[string]any) []string {
+	var keys []string
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestSettingsSaveFieldWritesOnlyTheFocusedSetting(t *testing.T) {
+	keys := map[field]string{
+		fieldTheme:           "theme",
+		fieldAction:          "default_prompt_action",
+		fieldDuration:        "default_prompt_duration",
+		fieldTarget:          "default_prompt_target",
+		fieldPromptTimeout:   "prompt_timeout_seconds",
+		fieldPromptFocus:     "prompt_initial_focus",
+		fieldStartView:       "start_view",
+		fieldAlertsInterrupt: "alerts_interrupt",
+		fieldPauseOnInspect:  "pause_prompt_on_inspect",
+		fieldDND:             "dnd_minutes",
+		fieldYaraEnabled:     "yara_enabled",
+		fieldInspectHook:     "inspect_hook_enabled",
+	}
+	for f, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			m, _, read := persistedView(t)
+			before := read()
+
+			// Change the neighbouring row too; s must leave it unsaved.
+			other := fieldTheme
+			if f == fieldTheme {
+				other = fieldAction
+			}
+			rows[other].shift(m, 1)
+			m.focus = f
+			m.Update(tea.KeyMsg{Type: tea.KeyRight})
+			_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+			if f == fieldYaraEnabled {
+				if cmd == nil {
+					t.Fatal("expected switching YARA on to be checked first")
+				}
+				m.Update(cmd())
+				m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+			}
+
+			if got := changedKeys(before, read()); len(got) != 1 || got[0] != key {
+				t.Fatalf("expected only %s written, got changes to %v", key, got)
+			}
+			want := fmt.Sprintf("%s set to %s", strings.ToUpper(rows[f].what[:1])+rows[f].what[1:], rows[f].display(m))
+			if out := m.View(); !strings.Contains(out, want) {
+				t.Fatalf("expected %q, got: %s", want, out)
+			}
+		})
+	}
+}
+
+func TestSettingsSaveFieldOnRuleDirTypesAndEnterSaves(t *testing.T) {
+	m, store, read := persistedView(t)
+	before := read()
+	rows[fieldTheme].shift(m, 1)
+	m.focus = fieldYaraRuleDir
+	dir := t.TempDir()
+	m.yaraRuleDir.SetValue(dir)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if got := changedKeys(before, read()); len(got) != 0 {
+		t.Fatalf("expected s to be typed into the field, got changes to %v", got)
+	}
+	m.yaraRuleDir.SetValue(dir)
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := changedKeys(before, read()); len(got) != 1 || got[0] != "yara_rule_dir" {
+		t.Fatalf("expected only yara_rule_dir written, got changes to %v", got)
+	}
+	if store.Snapshot().Settings.YaraRuleDir != dir {
+		t.Fatalf("expected the directory applied, got %q", store.Snapshot().Settings.YaraRuleDir)
+	}
+	if out := m.View(); !strings.Contains(out, "YARA rule dir set to "+dir) {
+		t.Fatalf("expected the rule dir message, got: %s", out)
+	}
+}
+
+func TestSettingsSaveFieldRefusesYaraWithoutSavedRuleDir(t *testing.T) {
+	store := state.NewStore()
+	m := New(store, theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(100, 40)
+	m.yaraRuleDir.SetValue(t.TempDir())
+	m.focus = fieldYaraEnabled
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}}); cmd != nil {
+		t.Fatal("expected no check while the rule dir is unsaved")
+	}
+	if store.Snapshot().Settings.YaraEnabled {
+		t.Fatal("expected YARA left off")
+	}
+	if out := m.View(); !strings.Contains(out, "Save the YARA rule directory first") {
+		t.Fatalf("expected the reason, got: %s", out)
+	}
+}
+
+func TestSettingsHelpMentionsSaveField(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(120, 40)
+	if out := m.View(); !strings.Contains(out, "enter save all · s save field") {
+		t.Fatalf("expected the save keys in the help line, got: %s", out)
+	}
+}
+
+func TestSettingsDescriptionTemplateField(t *testing.T) {
+	store := state.NewStore()
+	m := New(store, theme.New(theme.Options{}), &fakeSettingsController{}).(*Model)
+	m.SetSize(120, 40)
+
+	typeFilter(m, "description")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.focus != fieldDescriptionTemplate || !m.EnteringText() {
+		t.Fatalf("expected the description template field focused, got %v", m.focus)
+	}
+	for _, r := range `{{printf "%s" .Process}}` {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if msg := m.fieldErrs[fieldDescriptionTemplate]; !strings.Contains(msg, "not allowed") {
+		t.Fatalf("expected printf refused inline, got %q", msg)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if store.Snapshot().Settings.RuleDescriptionTemplate != "" {
+		t.Fatal("expected an invalid template left unsaved")
+	}
+
+	m.descTemplate.SetValue("")
+	for _, r := range "{{.Node}}: {{base .Process}}" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := store.Snapshot().Settings.RuleDescriptionTemplate; got != "{{.Node}}: {{base .Process}}" {
+		t.Fatalf("expected the template saved, got %q", got)
+	}
+}
+
+func TestSettingsWithoutControllerIsReadOnly(t *testing.T) {
+	m := New(state.NewStore(), theme.New(theme.Options{}), nil).(*Model)
+	m.SetSize(120, 40)
+	want := controller.ErrNoController.Error()
+	if out := m.View(); !strings.Contains(out, want) {
+		t.Fatalf("expected the read-only line before any key, got: %s", out)
+	}
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyEnter}, {Type: tea.KeyRunes, Runes: []rune{'s'}}} {
+		m.status = ""
+		m.Update(key)
+		if !strings.Contains(m.status, want) {
+			t.Fatalf("%s: expected the shared error, got %q", key, m.status)
+		}
+	}
+}
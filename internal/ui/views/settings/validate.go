@@ -0,0 +1,97 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/config"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// fieldError checks the pending value of f with the same rules the settings
+// manager applies, returning "" when it is valid.
+func (m *Model) fieldError(f field) string {
+	var err error
+	switch f {
+	case fieldPromptTimeout:
+		err = config.ValidatePromptTimeoutSeconds(optionSeconds(promptTimeouts[m.timeoutIdx]))
+	case fieldStartView:
+		if name := startViewOptions[m.startViewIdx].Value; !isViewName(name) {
+			err = fmt.Errorf("unknown view %q", name)
+		}
+	case fieldDescriptionTemplate:
+		_, err = ruleset.ParseDescriptionTemplate(m.descTemplate.Value())
+	case fieldYaraRuleDir:
+		err = config.ValidateYara(m.yaraEnabled, m.yaraRuleDir.Value())
+	case fieldInspectHook:
+		if m.hookEnabled && m.store.Snapshot().Settings.InspectHook == "" {
+			err = errors.New("set inspect_hook in the config file first")
+		}
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func isViewName(name string) bool {
+	_, ok := state.ParseViewKind(name)
+	return ok
+}
+
+// validateField refreshes the inline error of f and returns it.
+func (m *Model) validateField(f field) string {
+	msg := m.fieldError(f)
+	if msg == "" {
+		delete(m.fieldErrs, f)
+		return ""
+	}
+	if m.fieldErrs == nil {
+		m.fieldErrs = make(map[field]string)
+	}
+	m.fieldErrs[f] = msg
+	return msg
+}
+
+// validateChanged revalidates the focused field after a change, plus the
+// rule directory when toggling YARA changes whether one is required.
+func (m *Model) validateChanged() {
+	m.validateField(m.focus)
+	if m.focus == fieldYaraEnabled {
+		m.validateField(fieldYaraRuleDir)
+	}
+}
+
+// validateAll checks every field and moves focus to the first invalid one,
+// clearing the filter if it hides that row.
+func (m *Model) validateAll() bool {
+	first := field(-1)
+	for _, r := range rows {
+		if m.validateField(r.field) != "" && first < 0 {
+			first = r.field
+		}
+	}
+	if first < 0 {
+		return true
+	}
+	m.focus = first
+	if !m.matches(rows[first]) {
+		m.filter.SetValue("")
+	}
+	if r := rows[first]; r.text != nil {
+		r.text(m).Focus()
+	}
+	m.blurText()
+	m.status = m.theme.Danger.Render("Fix the highlighted field before saving")
+	return false
+}
+
+// withError appends the inline error of f under its rendered row.
+func (m *Model) withError(f field, row string) string {
+	msg, ok := m.fieldErrs[f]
+	if !ok {
+		return row
+	}
+	return row + "\n" + m.theme.Danger.Render("  ↳ "+msg)
+}
@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/yara"
+)
+
+// yaraCheck is the dry run that stands between switching YARA scanning on
+// and saving: large rule sets are slow to compile and scanning needs a cgo
+// build, so the user confirms after seeing what they are enabling.
+type yaraCheck struct {
+	dir string
+	// single is set when s started the check: only the toggle is saved.
+	single bool
+	// report is nil while the check runs.
+	report *yara.Report
+}
+
+// yaraCheckedMsg carries a finished check back to the check it belongs to.
+type yaraCheckedMsg struct {
+	check  *yaraCheck
+	report yara.Report
+}
+
+// enablingYara reports whether saving would switch YARA scanning on.
+func (m *Model) enablingYara() bool {
+	return m.yaraEnabled && !m.store.Snapshot().Settings.YaraEnabled
+}
+
+func (m *Model) startYaraCheck(single bool) tea.Cmd {
+	check := &yaraCheck{dir: strings.TrimSpace(m.yaraRuleDir.Value()), single: single}
+	m.yaraCheck = check
+	m.status = m.theme.Subtle.Render(fmt.Sprintf("Checking YARA rules in %s… (esc cancels)", check.dir))
+	run := m.checkYara
+	return func() tea.Msg { return yaraCheckedMsg{check: check, report: run(check.dir)} }
+}
+
+func (m *Model) onYaraChecked(msg yaraCheckedMsg) {
+	if msg.check != m.yaraCheck {
+		return
+	}
+	m.yaraCheck.report = &msg.report
+	m.status = m.theme.Warning.Render(yaraSummary(msg.report) + " — enable? y/n")
+}
+
+// updateYaraCheck answers the confirmation: y saves everything with YARA
+// on (or just the toggle, after s), n switches it back off without saving.
+func (m *Model) updateYaraCheck(key tea.KeyMsg) {
+	switch key.String() {
+	case "y", "enter":
+		if m.yaraCheck.report == nil {
+			return
+		}
+		single := m.yaraCheck.single
+		m.yaraCheck = nil
+		if single {
+			m.persistField(fieldYaraEnabled)
+			return
+		}
+		m.persistAll()
+	case "n", "esc":
+		m.yaraCheck = nil
+		m.yaraEnabled = false
+		m.validateField(fieldYaraRuleDir)
+		m.status = m.theme.Subtle.Render("YARA scanning left off; nothing saved")
+	}
+}
+
+func yaraSummary(r yara.Report) string {
+	if r.Err != nil {
+		return fmt.Sprintf("YARA check failed: %v", r.Err)
+	}
+	parts := []string{fmt.Sprintf("%d rule files", r.Files)}
+	if !r.Available {
+		parts = append(parts, "support: missing (rebuild with cgo and libyara)")
+		return strings.Join(parts, ", ")
+	}
+	parts = append(parts, "support: built-in")
+	if r.Sampled > 0 {
+		compile := fmt.Sprintf("sample compile %.1fs", r.Compile.Seconds())
+		if r.Sampled < r.Files {
+			compile += fmt.Sprintf(" (%d of %d files)", r.Sampled, r.Files)
+		}
+		parts = append(parts, compile)
+	}
+	return strings.Join(parts, ", ")
+}
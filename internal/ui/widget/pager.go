@@ -0,0 +1,66 @@
+package widget
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// Pager is a scrollable block of preformatted text, shown by views in place
+// of their detail pane.
+type Pager struct {
+	title  string
+	text   string
+	lines  []string
+	offset int
+}
+
+// NewPager returns a pager over text, scrolled to the top.
+func NewPager(title, text string) *Pager {
+	text = strings.TrimRight(text, "\n")
+	return &Pager{title: title, text: text, lines: strings.Split(text, "\n")}
+}
+
+// Text returns the full, unscrolled text.
+func (p *Pager) Text() string { return p.text }
+
+// HandleKey scrolls for navigation keys and reports whether key was one.
+func (p *Pager) HandleKey(key string, height int) bool {
+	page := max(1, height-1)
+	switch key {
+	case "up", "k":
+		p.offset--
+	case "down", "j":
+		p.offset++
+	case "pgup":
+		p.offset -= page
+	case "pgdown":
+		p.offset += page
+	case "home", "g":
+		p.offset = 0
+	case "end", "G":
+		p.offset = len(p.lines)
+	default:
+		return false
+	}
+	p.clamp(height)
+	return true
+}
+
+func (p *Pager) clamp(height int) {
+	p.offset = min(p.offset, len(p.lines)-max(1, height-1))
+	p.offset = max(p.offset, 0)
+}
+
+// View renders the title and as many lines as fit in height rows.
+func (p *Pager) View(th theme.Theme, height int) string {
+	p.clamp(height)
+	visible := max(1, height-1)
+	end := min(len(p.lines), p.offset+visible)
+	header := th.Header.Render(p.title)
+	if len(p.lines) > visible {
+		header += th.Subtle.Render(fmt.Sprintf("  (%d-%d of %d)", p.offset+1, end, len(p.lines)))
+	}
+	return header + "\n" + strings.Join(p.lines[p.offset:end], "\n")
+}
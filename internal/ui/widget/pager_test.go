@@ -0,0 +1,45 @@
+package widget
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+func TestPagerScrollsAndClamps(t *testing.T) {
+	p := NewPager("Wire", "a\nb\nc\nd\ne\n")
+	th := theme.New(theme.Options{})
+
+	if out := p.View(th, 3); !strings.Contains(out, "a\nb") || strings.Contains(out, "c") {
+		t.Fatalf("expected first two lines, got %q", out)
+	}
+	p.HandleKey("end", 3)
+	if out := p.View(th, 3); !strings.HasSuffix(out, "d\ne") {
+		t.Fatalf("expected last two lines, got %q", out)
+	}
+	p.HandleKey("down", 3)
+	if out := p.View(th, 3); !strings.HasSuffix(out, "d\ne") {
+		t.Fatalf("expected scrolling past the end to clamp, got %q", out)
+	}
+	if p.HandleKey("y", 3) {
+		t.Fatalf("expected non-navigation keys to be left to the caller")
+	}
+	if p.Text() != "a\nb\nc\nd\ne" {
+		t.Fatalf("unexpected text %q", p.Text())
+	}
+}
+
+func TestPagerScrollsWithJK(t *testing.T) {
+	p := NewPager("Wire", "a\nb\nc\nd\ne\n")
+	th := theme.New(theme.Options{})
+
+	p.HandleKey("j", 3)
+	if out := p.View(th, 3); !strings.Contains(out, "b\nc") {
+		t.Fatalf("expected j to scroll down, got %q", out)
+	}
+	p.HandleKey("k", 3)
+	if out := p.View(th, 3); !strings.Contains(out, "a\nb") {
+		t.Fatalf("expected k to scroll up, got %q", out)
+	}
+}
@@ -0,0 +1,63 @@
+package widget
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+// maxPanelFailures is how many failures the progress panel lists before
+// summarizing the rest.
+const maxPanelFailures = 5
+
+// RenderTaskPanel renders a bulk action as a few lines: its title and how
+// many of its tasks are complete, the failures so far, and the key that
+// cancels it or, once finished, dismisses the panel.
+func RenderTaskPanel(th theme.Theme, task state.TaskProgress, width int) string {
+	summary := fmt.Sprintf("%s: %d of %d complete", task.Title, task.Done, task.Total)
+	if n := len(task.Failures); n > 0 {
+		summary += fmt.Sprintf(", %d failed", n)
+	}
+	var hint string
+	switch {
+	case task.Finished && task.Skipped > 0:
+		summary += fmt.Sprintf(", canceled with %d not started", task.Skipped)
+		hint = "esc dismiss"
+	case task.Finished:
+		hint = "esc dismiss"
+	case task.Canceling:
+		summary += ", canceling; waiting for running tasks"
+	default:
+		hint = "esc stop"
+	}
+
+	style := th.Body
+	switch {
+	case len(task.Failures) > 0:
+		style = th.Danger
+	case task.Finished && task.Skipped == 0:
+		style = th.Success
+	case task.Canceling || task.Skipped > 0:
+		style = th.Warning
+	}
+	line := style.Render(summary)
+	if hint != "" {
+		line += "  " + th.Subtle.Render(hint)
+	}
+	lines := []string{line}
+	for i, failure := range task.Failures {
+		if i == maxPanelFailures {
+			lines = append(lines, th.Subtle.Render(fmt.Sprintf("  …and %d more", len(task.Failures)-i)))
+			break
+		}
+		text := fmt.Sprintf("  %s: %s", failure.Name, failure.Err)
+		if width > 0 {
+			text = util.TruncateString(text, width)
+		}
+		lines = append(lines, th.Danger.Render(text))
+	}
+	return strings.Join(lines, "\n")
+}
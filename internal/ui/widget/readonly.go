@@ -0,0 +1,74 @@
+package widget
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+)
+
+// RenderReadOnly is the line a view built without a controller keeps in
+// its status area, so missing wiring shows before any key is pressed.
+func RenderReadOnly(th theme.Theme) string {
+	return th.Warning.Render(controller.ErrNoController.Error())
+}
+
+// Guard reports whether an action needing a controller may go ahead. When
+// attached is false it sets *status to controller.ErrNoController, the
+// same for every action of every view.
+func Guard(th theme.Theme, attached bool, status *string) bool {
+	if !attached {
+		*status = th.Danger.Render(controller.ErrNoController.Error())
+	}
+	return attached
+}
+
+// RenderHelp renders a help line of " · " separated hints. Hints starting
+// with one of disabled, such as "e enable", are struck through to show
+// the key does nothing in this view.
+func RenderHelp(th theme.Theme, help string, disabled []string) string {
+	if len(disabled) == 0 {
+		return th.Subtle.Render(help)
+	}
+	struck := th.Subtle.Strikethrough(true)
+	lines := strings.Split(help, "\n")
+	for i, line := range lines {
+		hints := strings.Split(line, " · ")
+		for j, hint := range hints {
+			style := th.Subtle
+			for _, prefix := range disabled {
+				if strings.HasPrefix(hint, prefix) {
+					style = struck
+					break
+				}
+			}
+			hints[j] = style.Render(hint)
+		}
+		lines[i] = strings.Join(hints, th.Subtle.Render(" · "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WrapHelp breaks a help line of " · " separated hints into lines no wider
+// than width, only ever between hints, so no hint is split from its key.
+func WrapHelp(help string, width int) string {
+	if width <= 0 {
+		return help
+	}
+	var lines []string
+	line := ""
+	for _, hint := range strings.Split(help, " · ") {
+		switch {
+		case line == "":
+			line = hint
+		case lipgloss.Width(line+" · "+hint) <= width:
+			line += " · " + hint
+		default:
+			lines = append(lines, line)
+			line = hint
+		}
+	}
+	return strings.Join(append(lines, line), "\n")
+}
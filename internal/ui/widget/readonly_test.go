@@ -0,0 +1,68 @@
+package widget
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/controller"
+	"github.com/adamkadaban/opensnitch-tui/internal/theme"
+	"github.com/adamkadaban/opensnitch-tui/internal/util"
+)
+
+func TestGuardReportsTheSharedError(t *testing.T) {
+	th := theme.New(theme.Options{})
+	status := "previous"
+	if !Guard(th, true, &status) || status != "previous" {
+		t.Fatalf("expected an attached controller to pass untouched, got %q", status)
+	}
+	if Guard(th, false, &status) || util.StripANSI(status) != controller.ErrNoController.Error() {
+		t.Fatalf("expected the shared error, got %q", status)
+	}
+}
+
+func TestRenderHelpStrikesDisabledHints(t *testing.T) {
+	th := theme.New(theme.Options{})
+	th.Subtle = lipgloss.NewStyle()
+	help := "e enable · d disable · / filter\nenter save"
+	if got := RenderHelp(th, help, nil); got != help {
+		t.Fatalf("expected the help unchanged, got %q", got)
+	}
+	got := RenderHelp(th, help, []string{"e enable", "enter"})
+	if util.StripANSI(got) != help {
+		t.Fatalf("expected the same text, got %q", util.StripANSI(got))
+	}
+	want := []string{th.Subtle.Strikethrough(true).Render("e enable"), "d disable", th.Subtle.Strikethrough(true).Render("enter save")}
+	for _, part := range want {
+		if !strings.Contains(got, part) {
+			t.Fatalf("expected %q in %q", part, got)
+		}
+	}
+}
+
+func TestWrapHelpBreaksBetweenHints(t *testing.T) {
+	help := "e enable · d disable · x delete rule · / filter"
+	if got := WrapHelp(help, 0); got != help {
+		t.Fatalf("expected no width to leave the help alone, got %q", got)
+	}
+	if got, want := WrapHelp(help, 24), "e enable · d disable\nx delete rule · / filter"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got, want := WrapHelp(help, 5), "e enable\nd disable\nx delete rule\n/ filter"; got != want {
+		t.Fatalf("expected over-wide hints kept whole, got %q", got)
+	}
+}
+
+func TestAttachedSeesTypedNil(t *testing.T) {
+	var typedNil *Pager
+	var iface controller.RuleManager
+	for _, c := range []any{nil, typedNil, iface} {
+		if controller.Attached(c) {
+			t.Fatalf("expected %#v to count as no controller", c)
+		}
+	}
+	if !controller.Attached(&Pager{}) {
+		t.Fatalf("expected a pointer to count as a controller")
+	}
+}
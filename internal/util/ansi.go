@@ -5,19 +5,14 @@ import (
 	"unicode/utf8"
 )
 
-// StripANSI removes ANSI escape sequences from s.
+// StripANSI removes ANSI escape sequences from s: CSI sequences such as SGR
+// colours, and OSC sequences such as OSC 8 hyperlinks.
 func StripANSI(s string) string {
 	var b strings.Builder
 	for i := 0; i < len(s); {
-		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
-			j := i + 2
-			for j < len(s) && !((s[j] >= 'A' && s[j] <= 'Z') || (s[j] >= 'a' && s[j] <= 'z')) {
-				j++
-			}
-			if j < len(s) {
-				i = j + 1
-				continue
-			}
+		if end, ok := escapeEnd(s, i); ok {
+			i = end
+			continue
 		}
 		r, size := utf8.DecodeRuneInString(s[i:])
 		b.WriteRune(r)
@@ -27,32 +22,36 @@ func StripANSI(s string) string {
 }
 
 // AnsiSlice returns the substring of s corresponding to visible runes [offset, offset+width), preserving ANSI codes.
+// The SGR attributes and the hyperlink in effect at offset are reopened at
+// the start of the slice, and closed again at its end.
 func AnsiSlice(s string, offset, width int) string {
 	var b strings.Builder
 	visible := 0
 	started := false
 	activeSGR := ""
+	activeLink := ""
 	for i := 0; i < len(s); {
-		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
-			j := i + 2
-			for j < len(s) && !((s[j] >= 'A' && s[j] <= 'Z') || (s[j] >= 'a' && s[j] <= 'z')) {
-				j++
-			}
-			if j < len(s) {
-				esc := s[i : j+1]
-				if esc[len(esc)-1] == 'm' {
-					if isResetSGR(esc) {
-						activeSGR = ""
-					} else {
-						activeSGR = esc
-					}
+		if end, ok := escapeEnd(s, i); ok {
+			esc := s[i:end]
+			switch {
+			case esc[1] == '[' && esc[len(esc)-1] == 'm':
+				if isResetSGR(esc) {
+					activeSGR = ""
+				} else {
+					activeSGR = esc
 				}
-				if started {
-					b.WriteString(esc)
+			case isHyperlink(esc):
+				if hyperlinkURL(esc) == "" {
+					activeLink = ""
+				} else {
+					activeLink = esc
 				}
-				i = j + 1
-				continue
 			}
+			if started {
+				b.WriteString(esc)
+			}
+			i = end
+			continue
 		}
 		r, size := utf8.DecodeRuneInString(s[i:])
 		if size == 0 {
@@ -64,6 +63,9 @@ func AnsiSlice(s string, offset, width int) string {
 				if activeSGR != "" {
 					b.WriteString(activeSGR)
 				}
+				if activeLink != "" {
+					b.WriteString(activeLink)
+				}
 			}
 			b.WriteRune(r)
 		}
@@ -73,12 +75,46 @@ func AnsiSlice(s string, offset, width int) string {
 			break
 		}
 	}
+	if started && activeLink != "" {
+		b.WriteString(hyperlinkClose)
+	}
 	if started && activeSGR != "" {
 		b.WriteString("\x1b[0m")
 	}
 	return b.String()
 }
 
+// escapeEnd reports whether an escape sequence starts at s[i] and where it
+// ends. A CSI sequence ends at its final letter and is not one without
+// it. An OSC sequence ends at BEL or ST (ESC \); without either it runs to
+// the end of s, as a terminal would swallow it.
+func escapeEnd(s string, i int) (int, bool) {
+	if s[i] != '\x1b' || i+1 >= len(s) {
+		return 0, false
+	}
+	switch s[i+1] {
+	case '[':
+		j := i + 2
+		for j < len(s) && !((s[j] >= 'A' && s[j] <= 'Z') || (s[j] >= 'a' && s[j] <= 'z')) {
+			j++
+		}
+		if j < len(s) {
+			return j + 1, true
+		}
+	case ']':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return j + 1, true
+			}
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2, true
+			}
+		}
+		return len(s), true
+	}
+	return 0, false
+}
+
 // RuneWidth returns the number of runes in s excluding ANSI sequences.
 func RuneWidth(s string) int { return len([]rune(StripANSI(s))) }
 
@@ -32,3 +32,58 @@ func TestAnsiSliceWithResetInsideSlice(t *testing.T) {
 		t.Fatalf("expected %q, got %q", expected, out)
 	}
 }
+
+const (
+	linkOpen  = "\x1b]8;;https://www.virustotal.com/gui/file/abc\x1b\\"
+	linkClose = "\x1b]8;;\x1b\\"
+)
+
+func TestAnsiSliceInsideHyperlink(t *testing.T) {
+	s := "see " + linkOpen + "VirusTotal" + linkClose + " now"
+	// Cutting into the link reopens it and closes it at the end.
+	if out, want := AnsiSlice(s, 6, 3), linkOpen+"rus"+linkClose; out != want {
+		t.Fatalf("expected %q, got %q", want, out)
+	}
+	// A slice ending past the link keeps its own close and adds none.
+	if out, want := AnsiSlice(s, 10, 6), linkOpen+"otal"+linkClose+" n"; out != want {
+		t.Fatalf("expected %q, got %q", want, out)
+	}
+	// A slice after the link carries nothing of it.
+	if out := AnsiSlice(s, 15, 3); out != "now" {
+		t.Fatalf("expected plain text after the link, got %q", out)
+	}
+	// BEL terminates an OSC the same as ST.
+	bel := "\x1b]8;;file:///usr/bin/curl\a/usr/bin/curl\x1b]8;;\a"
+	if out, want := AnsiSlice(bel, 5, 3), "\x1b]8;;file:///usr/bin/curl\abin"+linkClose; out != want {
+		t.Fatalf("expected %q, got %q", want, out)
+	}
+}
+
+func TestAnsiSliceSGRInsideHyperlink(t *testing.T) {
+	red := "\x1b[31m"
+	reset := "\x1b[0m"
+	s := linkOpen + "ab" + red + "cde" + reset + "f" + linkClose + "g"
+	if out, want := AnsiSlice(s, 3, 2), red+linkOpen+"de"+linkClose+reset; out != want {
+		t.Fatalf("expected %q, got %q", want, out)
+	}
+	if out, want := AnsiSlice(s, 4, 3), red+linkOpen+"e"+reset+"f"+linkClose+"g"; out != want {
+		t.Fatalf("expected %q, got %q", want, out)
+	}
+	if got := RuneWidth(s); got != 7 {
+		t.Fatalf("expected the link and colour to be zero-width, got %d", got)
+	}
+}
+
+func TestStripANSIRemovesOSC(t *testing.T) {
+	cases := map[string]string{
+		"see " + linkOpen + "VirusTotal" + linkClose + " now": "see VirusTotal now",
+		"\x1b]8;id=1;file:///tmp\a/tmp\x1b]8;;\a":             "/tmp",
+		"\x1b]0;window title\x07\x1b[1mbold\x1b[0m":           "bold",
+		"text\x1b]8;;https://unterminated":                    "text",
+	}
+	for in, want := range cases {
+		if got := StripANSI(in); got != want {
+			t.Fatalf("StripANSI(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
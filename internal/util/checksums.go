@@ -0,0 +1,59 @@
+package util
+
+import (
+	"sort"
+	"strings"
+)
+
+// Checksum is a single process checksum.
+type Checksum struct {
+	Algorithm string
+	Value     string
+}
+
+// SortedChecksums returns the non-empty checksums ordered by algorithm name.
+func SortedChecksums(checksums map[string]string) []Checksum {
+	out := make([]Checksum, 0, len(checksums))
+	for algo, value := range checksums {
+		algo, value = strings.TrimSpace(algo), strings.TrimSpace(value)
+		if algo == "" || value == "" {
+			continue
+		}
+		out = append(out, Checksum{Algorithm: strings.ToLower(algo), Value: value})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Algorithm < out[j].Algorithm })
+	return out
+}
+
+// ChecksumLines renders one "algo  value" line per checksum with the values
+// aligned. It returns nil when there is nothing to show.
+func ChecksumLines(checksums []Checksum) []string {
+	if len(checksums) == 0 {
+		return nil
+	}
+	width := 0
+	for _, cs := range checksums {
+		width = max(width, RuneWidth(cs.Algorithm))
+	}
+	lines := make([]string, len(checksums))
+	for i, cs := range checksums {
+		lines[i] = PadString(cs.Algorithm, width) + "  " + cs.Value
+	}
+	return lines
+}
+
+// VirusTotalURL returns the VirusTotal file report URL for the sha256
+// checksum, when one is present and well formed.
+func VirusTotalURL(checksums map[string]string) (string, bool) {
+	for algo, value := range checksums {
+		if !strings.EqualFold(strings.TrimSpace(algo), "sha256") {
+			continue
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+		if len(value) != 64 || strings.Trim(value, "0123456789abcdef") != "" {
+			return "", false
+		}
+		return "https://www.virustotal.com/gui/file/" + value, true
+	}
+	return "", false
+}
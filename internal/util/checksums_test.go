@@ -0,0 +1,49 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChecksumLinesSortedAndAligned(t *testing.T) {
+	checksums := SortedChecksums(map[string]string{
+		"sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"MD5":    "d41d8cd98f00b204e9800998ecf8427e",
+		"sha1":   "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"crc":    "   ",
+	})
+	want := []string{
+		"md5     d41d8cd98f00b204e9800998ecf8427e",
+		"sha1    da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"sha256  e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	if got := ChecksumLines(checksums); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ChecksumLines = %q, want %q", got, want)
+	}
+}
+
+func TestChecksumLinesEmpty(t *testing.T) {
+	if got := ChecksumLines(SortedChecksums(nil)); got != nil {
+		t.Fatalf("expected nil for missing checksums, got %q", got)
+	}
+	if got := ChecksumLines(SortedChecksums(map[string]string{"md5": ""})); got != nil {
+		t.Fatalf("expected nil for empty values, got %q", got)
+	}
+}
+
+func TestVirusTotalURL(t *testing.T) {
+	sha := "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"
+	url, ok := VirusTotalURL(map[string]string{"SHA256": sha, "md5": "x"})
+	if !ok || url != "https://www.virustotal.com/gui/file/e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Fatalf("unexpected url %q ok=%v", url, ok)
+	}
+	for _, checksums := range []map[string]string{
+		nil,
+		{"md5": "d41d8cd98f00b204e9800998ecf8427e"},
+		{"sha256": "not-a-hash"},
+	} {
+		if url, ok := VirusTotalURL(checksums); ok {
+			t.Fatalf("expected no url for %v, got %q", checksums, url)
+		}
+	}
+}
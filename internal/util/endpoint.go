@@ -0,0 +1,34 @@
+package util
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// CompactIP renders ip in its shortest form: IPv6 is ::-collapsed and
+// IPv4-mapped addresses are shown as plain IPv4. Zone IDs are kept. Anything
+// that does not parse as an IP is returned unchanged.
+func CompactIP(ip string) string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return ip
+	}
+	if addr.Is4In6() {
+		addr = addr.Unmap()
+	}
+	return addr.String()
+}
+
+// FormatEndpoint joins host and port, bracketing IPv6 addresses only when a
+// port follows. It returns "" when both are empty.
+func FormatEndpoint(host string, port uint32) string {
+	host = CompactIP(host)
+	if port == 0 {
+		return host
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return host + ":" + strconv.FormatUint(uint64(port), 10)
+}
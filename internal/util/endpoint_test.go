@@ -0,0 +1,46 @@
+package util
+
+import "testing"
+
+func TestCompactIP(t *testing.T) {
+	cases := map[string]string{
+		"2001:0db8:0000:0000:0000:0000:0000:0001": "2001:db8::1",
+		"2001:db8::1":          "2001:db8::1",
+		"2001:DB8:0:0:1:0:0:1": "2001:db8::1:0:0:1",
+		"0000:0000:0000:0000:0000:0000:0000:0000":      "::",
+		"::ffff:192.0.2.10":                            "192.0.2.10",
+		"0:0:0:0:0:ffff:c000:020a":                     "192.0.2.10",
+		"fe80:0000:0000:0000:0000:0000:0000:0001%eth0": "fe80::1%eth0",
+		"fe80::1%wlp3s0":                               "fe80::1%wlp3s0",
+		"192.0.2.10":                                   "192.0.2.10",
+		"example.com":                                  "example.com",
+		"":                                             "",
+	}
+	for in, want := range cases {
+		if got := CompactIP(in); got != want {
+			t.Errorf("CompactIP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFormatEndpoint(t *testing.T) {
+	cases := []struct {
+		host string
+		port uint32
+		want string
+	}{
+		{"2001:0db8::0001", 443, "[2001:db8::1]:443"},
+		{"2001:db8::1", 0, "2001:db8::1"},
+		{"fe80::1%eth0", 5353, "[fe80::1%eth0]:5353"},
+		{"::ffff:192.0.2.10", 80, "192.0.2.10:80"},
+		{"192.0.2.10", 53, "192.0.2.10:53"},
+		{"example.com", 443, "example.com:443"},
+		{"", 22, ":22"},
+		{"", 0, ""},
+	}
+	for _, tc := range cases {
+		if got := FormatEndpoint(tc.host, tc.port); got != tc.want {
+			t.Errorf("FormatEndpoint(%q, %d) = %q, want %q", tc.host, tc.port, got, tc.want)
+		}
+	}
+}
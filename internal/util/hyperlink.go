@@ -0,0 +1,61 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+// hyperlinkClose ends an OSC 8 hyperlink.
+const hyperlinkClose = "\x1b]8;;\x1b\\"
+
+// Hyperlink returns text as an OSC 8 hyperlink to url when enabled, and
+// text alone otherwise or when url is empty or holds control characters,
+// which would end the sequence early.
+func Hyperlink(url, text string, enabled bool) string {
+	if !enabled || url == "" || strings.ContainsFunc(url, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return text
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + hyperlinkClose
+}
+
+// HyperlinksSupported reports whether the terminal described by getenv is
+// known to render OSC 8 hyperlinks. Terminals that do not are meant to
+// ignore them, but some print the escape bytes, so only those known to
+// work are trusted. FORCE_HYPERLINK=1 or 0 overrides the guess.
+func HyperlinksSupported(getenv func(string) string) bool {
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		on, err := strconv.ParseBool(force)
+		return err == nil && on
+	}
+	switch getenv("TERM") {
+	case "", "dumb", "linux":
+		return false
+	case "xterm-kitty", "alacritty", "foot", "xterm-ghostty", "wezterm":
+		return true
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty":
+		return true
+	}
+	if getenv("WT_SESSION") != "" || getenv("KONSOLE_VERSION") != "" {
+		return true
+	}
+	// VTE 0.50 (GNOME Terminal, Tilix, …) added hyperlinks.
+	vte, err := strconv.Atoi(getenv("VTE_VERSION"))
+	return err == nil && vte >= 5000
+}
+
+// isHyperlink reports whether the OSC sequence esc opens or closes an
+// OSC 8 hyperlink.
+func isHyperlink(esc string) bool {
+	return strings.HasPrefix(esc, "\x1b]8;")
+}
+
+// hyperlinkURL returns the target of the OSC 8 sequence esc; empty closes
+// the open link.
+func hyperlinkURL(esc string) string {
+	body := strings.TrimPrefix(esc, "\x1b]8;")
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "\a"), "\x1b\\")
+	_, url, _ := strings.Cut(body, ";")
+	return url
+}
@@ -0,0 +1,38 @@
+package util
+
+import "testing"
+
+func TestHyperlink(t *testing.T) {
+	if got, want := Hyperlink("https://example.com", "site", true), "\x1b]8;;https://example.com\x1b\\site\x1b]8;;\x1b\\"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for _, url := range []string{"", "https://example.com\x1b\\evil"} {
+		if got := Hyperlink(url, "site", true); got != "site" {
+			t.Fatalf("expected plain text for %q, got %q", url, got)
+		}
+	}
+	if got := Hyperlink("https://example.com", "site", false); got != "site" {
+		t.Fatalf("expected plain text when disabled, got %q", got)
+	}
+}
+
+func TestHyperlinksSupported(t *testing.T) {
+	cases := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, true},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, true},
+		{map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "6003"}, true},
+		{map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "4803"}, false},
+		{map[string]string{"TERM": "xterm-256color"}, false},
+		{map[string]string{"TERM": "linux", "WT_SESSION": "x"}, false},
+		{map[string]string{"TERM": "xterm-256color", "FORCE_HYPERLINK": "1"}, true},
+		{map[string]string{"TERM": "xterm-kitty", "FORCE_HYPERLINK": "0"}, false},
+	}
+	for _, tc := range cases {
+		if got := HyperlinksSupported(func(key string) string { return tc.env[key] }); got != tc.want {
+			t.Fatalf("%v: expected %v, got %v", tc.env, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"net"
+	"strings"
+
+	"github.com/adamkadaban/opensnitch-tui/internal/state"
+)
+
+// IsLocalNode reports whether nodeID runs on this machine, so its PIDs and
+// paths can be looked up locally. Unknown nodes are treated as remote.
+func IsLocalNode(nodes []state.Node, nodeID string) bool {
+	if nodeID == "" {
+		return true
+	}
+	// If the nodeID itself looks like an address (peerKey style), evaluate directly.
+	if IsLocalAddress(nodeID) {
+		return true
+	}
+	for _, n := range nodes {
+		if n.ID == nodeID {
+			return IsLocalAddress(n.Address)
+		}
+	}
+	return false
+}
+
+// IsLocalAddress reports whether a daemon address is a unix socket or a
+// loopback host.
+func IsLocalAddress(addr string) bool {
+	if addr == "" {
+		return true
+	}
+	if strings.HasPrefix(addr, "unix://") {
+		return true
+	}
+	host := addr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback()
+	}
+	host = strings.ToLower(host)
+	return host == "localhost"
+}
@@ -1,4 +1,4 @@
-package prompt
+package util
 
 import (
 	"testing"
@@ -22,8 +22,8 @@ func TestIsLocalAddress(t *testing.T) {
 		{"example.com:443", false},
 	}
 	for _, tt := range cases {
-		if got := isLocalAddress(tt.addr); got != tt.want {
-			t.Errorf("isLocalAddress(%q) = %v, want %v", tt.addr, got, tt.want)
+		if got := IsLocalAddress(tt.addr); got != tt.want {
+			t.Errorf("IsLocalAddress(%q) = %v, want %v", tt.addr, got, tt.want)
 		}
 	}
 }
@@ -33,19 +33,19 @@ func TestIsLocalNode(t *testing.T) {
 		{ID: "a", Address: "localhost:50051"},
 		{ID: "b", Address: "192.168.1.10:50051"},
 	}
-	if !isLocalNode(nodes, "a") {
+	if !IsLocalNode(nodes, "a") {
 		t.Fatalf("expected node a to be local")
 	}
-	if isLocalNode(nodes, "b") {
+	if IsLocalNode(nodes, "b") {
 		t.Fatalf("expected node b to be remote")
 	}
-	if !isLocalNode(nodes, "") {
+	if !IsLocalNode(nodes, "") {
 		t.Fatalf("expected empty node ID to be treated as local")
 	}
-	if !isLocalNode(nodes, "unix://@:1764271078117941612") {
+	if !IsLocalNode(nodes, "unix://@:1764271078117941612") {
 		t.Fatalf("expected peerKey-style unix node ID to be treated as local")
 	}
-	if isLocalNode(nodes, "missing") {
+	if IsLocalNode(nodes, "missing") {
 		t.Fatalf("expected missing node to be non-local")
 	}
 }
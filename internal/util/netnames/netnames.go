@@ -0,0 +1,156 @@
+// Package netnames turns protocol numbers and ports into the names people
+// know them by: "6" is tcp and 443 is https.
+package netnames
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// protocols maps IANA protocol numbers to the names the daemons use.
+var protocols = map[string]string{
+	"1":   "icmp",
+	"6":   "tcp",
+	"17":  "udp",
+	"58":  "icmp6",
+	"132": "sctp",
+	"136": "udplite",
+}
+
+// wellKnown names the ports most connections go to, whatever the
+// transport. It wins over /etc/services, whose names ("domain", "www") are
+// less familiar.
+var wellKnown = map[uint32]string{
+	20: "ftp-data", 21: "ftp", 22: "ssh", 23: "telnet", 25: "smtp",
+	53: "dns", 67: "dhcp", 68: "dhcp", 80: "http", 88: "kerberos",
+	110: "pop3", 123: "ntp", 143: "imap", 161: "snmp", 389: "ldap",
+	443: "https", 465: "smtps", 514: "syslog", 587: "submission", 636: "ldaps",
+	853: "dns-over-tls", 993: "imaps", 995: "pop3s", 1194: "openvpn", 1900: "ssdp",
+	3306: "mysql", 3389: "rdp", 5222: "xmpp", 5353: "mdns", 5432: "postgresql",
+	6379: "redis", 8080: "http-alt", 8443: "https-alt", 9418: "git", 51820: "wireguard",
+}
+
+// services holds the names read by LoadServices, keyed by transport and
+// port.
+var (
+	servicesMu sync.RWMutex
+	services   map[serviceKey]string
+)
+
+type serviceKey struct {
+	proto string
+	port  uint32
+}
+
+// Protocol returns the lowercase name of proto, which daemons send as a
+// name ("TCP6") or an IANA number ("17"). Unknown values are lowercased.
+func Protocol(proto string) string {
+	proto = strings.ToLower(strings.TrimSpace(proto))
+	if name, ok := protocols[proto]; ok {
+		return name
+	}
+	return proto
+}
+
+// transport is the /etc/services protocol of proto: tcp6 and udp6 are
+// tcp and udp, and an unknown or missing one is looked up as tcp.
+func transport(proto string) string {
+	switch p := strings.TrimSuffix(Protocol(proto), "6"); p {
+	case "tcp", "udp", "sctp":
+		return p
+	case "udplite":
+		return "udp"
+	default:
+		return "tcp"
+	}
+}
+
+// Service returns the service usually found on port over proto.
+func Service(port uint32, proto string) (string, bool) {
+	if port == 0 {
+		return "", false
+	}
+	if name, ok := wellKnown[port]; ok {
+		return name, true
+	}
+	servicesMu.RLock()
+	defer servicesMu.RUnlock()
+	name, ok := services[serviceKey{transport(proto), port}]
+	return name, ok
+}
+
+// Port renders port with its service when known, e.g. "443 (https)".
+func Port(port uint32, proto string) string {
+	num := strconv.FormatUint(uint64(port), 10)
+	if name, ok := Service(port, proto); ok {
+		return num + " (" + name + ")"
+	}
+	return num
+}
+
+// PortLabel is Port for a port already rendered as text, as in the
+// daemon's per-port statistics. Labels that are not a port come back as
+// they are.
+func PortLabel(label string) string {
+	port, err := strconv.ParseUint(strings.TrimSpace(label), 10, 16)
+	if err != nil || port == 0 {
+		return label
+	}
+	return Port(uint32(port), "")
+}
+
+// parseServices reads a services(5) file. Comments and malformed lines are
+// skipped.
+func parseServices(r io.Reader) (map[serviceKey]string, error) {
+	out := make(map[serviceKey]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		portText, proto, ok := strings.Cut(fields[1], "/")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseUint(portText, 10, 16)
+		if err != nil || port == 0 {
+			continue
+		}
+		key := serviceKey{strings.ToLower(proto), uint32(port)}
+		// The first name listed for a port is the canonical one.
+		if _, seen := out[key]; !seen {
+			out[key] = fields[0]
+		}
+	}
+	return out, scanner.Err()
+}
+
+// LoadServices adds the names in the services(5) file at path to those
+// Service knows.
+func LoadServices(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("load services: %w", err)
+	}
+	defer f.Close()
+	parsed, err := parseServices(f)
+	if err != nil {
+		return fmt.Errorf("load services: %w", err)
+	}
+	servicesMu.Lock()
+	defer servicesMu.Unlock()
+	if services == nil {
+		services = make(map[serviceKey]string, len(parsed))
+	}
+	for key, name := range parsed {
+		services[key] = name
+	}
+	return nil
+}
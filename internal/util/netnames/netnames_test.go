@@ -0,0 +1,103 @@
+package netnames
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProtocol(t *testing.T) {
+	cases := map[string]string{
+		"6":     "tcp",
+		"17":    "udp",
+		"1":     "icmp",
+		"58":    "icmp6",
+		"TCP6":  "tcp6",
+		" udp ": "udp",
+		"":      "",
+		"250":   "250",
+	}
+	for in, want := range cases {
+		if got := Protocol(in); got != want {
+			t.Errorf("Protocol(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPort(t *testing.T) {
+	cases := []struct {
+		port  uint32
+		proto string
+		want  string
+	}{
+		{443, "tcp", "443 (https)"},
+		{53, "udp6", "53 (dns)"},
+		{22, "6", "22 (ssh)"},
+		{40123, "tcp", "40123"},
+		{0, "tcp", "0"},
+	}
+	for _, tc := range cases {
+		if got := Port(tc.port, tc.proto); got != tc.want {
+			t.Errorf("Port(%d, %q) = %q, want %q", tc.port, tc.proto, got, tc.want)
+		}
+	}
+	if got := PortLabel("443"); got != "443 (https)" {
+		t.Errorf("PortLabel(443) = %q", got)
+	}
+	if got := PortLabel("other"); got != "other" {
+		t.Errorf("PortLabel(other) = %q", got)
+	}
+}
+
+func TestParseServices(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := parseServices(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[serviceKey]string{
+		{"tcp", 1}:    "tcpmux",
+		{"tcp", 53}:   "domain",
+		{"udp", 53}:   "domain",
+		{"tcp", 70}:   "gopher",
+		{"tcp", 79}:   "finger",
+		{"tcp", 4190}: "sieve",
+		{"udp", 750}:  "kerberos4",
+		{"tcp", 6514}: "syslog-tls",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d services, got %v", len(want), got)
+	}
+	for key, name := range want {
+		if got[key] != name {
+			t.Errorf("%s/%d = %q, want %q", key.proto, key.port, got[key], name)
+		}
+	}
+}
+
+func TestLoadServices(t *testing.T) {
+	t.Cleanup(func() { services = nil })
+	if _, ok := Service(4190, "tcp"); ok {
+		t.Fatal("expected no name for 4190 before loading services")
+	}
+	if err := LoadServices(filepath.Join("testdata", "services")); err != nil {
+		t.Fatal(err)
+	}
+	if got := Port(4190, "tcp6"); got != "4190 (sieve)" {
+		t.Fatalf("expected tcp6 to use the tcp entry, got %q", got)
+	}
+	if _, ok := Service(4190, "udp"); ok {
+		t.Fatal("expected the tcp-only service to be unknown over udp")
+	}
+	// The built-in names win over the services file.
+	if got := Port(53, "udp"); got != "53 (dns)" {
+		t.Fatalf("expected the built-in name, got %q", got)
+	}
+	if err := LoadServices(filepath.Join("testdata", "missing")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
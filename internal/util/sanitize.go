@@ -0,0 +1,108 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxSanitizedRunes caps strings passed through Sanitize. Legitimate paths
+// and arguments are far shorter; anything longer is cut with an ellipsis.
+const MaxSanitizedRunes = 4096
+
+// maxCombining is how many combining marks may follow one base character;
+// more only stack into unreadable glyphs that overflow their cell.
+const maxCombining = 2
+
+// Sanitize makes a string from a daemon safe to render in a terminal.
+// Control characters become their visible Control Pictures ("\n" is "␊",
+// ESC is "␛"), C1 controls and bidirectional overrides are shown as \u
+// escapes, invalid UTF-8 becomes U+FFFD, runs of combining marks are
+// trimmed and the result is capped at MaxSanitizedRunes.
+func Sanitize(s string) string {
+	if isPlainASCII(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	runes, combining := 0, 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if runes == MaxSanitizedRunes {
+			b.WriteRune('…')
+			break
+		}
+		if unicode.In(r, unicode.Mn, unicode.Me) {
+			if combining >= maxCombining {
+				continue
+			}
+			combining++
+		} else {
+			combining = 0
+		}
+		runes++
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			b.WriteRune(utf8.RuneError)
+		case r < 0x20:
+			b.WriteRune(0x2400 + r)
+		case r == 0x7f:
+			b.WriteRune('␡')
+		case r >= 0x80 && r < 0xa0, isBidiControl(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SanitizeAll sanitizes each string of values, returning values itself when
+// nothing changed.
+func SanitizeAll(values []string) []string {
+	var out []string
+	for i, value := range values {
+		clean := Sanitize(value)
+		if clean != value && out == nil {
+			out = make([]string, len(values))
+			copy(out, values[:i])
+		}
+		if out != nil {
+			out[i] = clean
+		}
+	}
+	if out == nil {
+		return values
+	}
+	return out
+}
+
+// Unsanitize returns original when shown is what Sanitize made of it, so
+// values sent back to a daemon keep their exact bytes unless they were
+// edited since.
+func Unsanitize(shown, original string) string {
+	if Sanitize(original) == shown {
+		return original
+	}
+	return shown
+}
+
+func isPlainASCII(s string) bool {
+	if len(s) > MaxSanitizedRunes {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// isBidiControl reports the embedding, override and isolate controls that
+// reorder how the surrounding text is displayed.
+func isBidiControl(r rune) bool {
+	return (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069) || r == 0x200e || r == 0x200f || r == 0x061c
+}
@@ -0,0 +1,94 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitize(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain ascii", "/usr/bin/curl -s https://example.com", "/usr/bin/curl -s https://example.com"},
+		{"empty", "", ""},
+		{"newline", "evil\nALLOWED", "evil␊ALLOWED"},
+		{"carriage return", "a\rb", "a␍b"},
+		{"tab", "a\tb", "a␉b"},
+		{"nul byte", "a\x00b", "a␀b"},
+		{"del", "a\x7fb", "a␡b"},
+		{"ansi colour", "\x1b[31mred\x1b[0m", "␛[31mred␛[0m"},
+		{"ansi clear screen", "\x1b[2J\x1b[H", "␛[2J␛[H"},
+		{"osc title", "\x1b]0;pwned\x07", "␛]0;pwned␇"},
+		{"osc 52 clipboard", "\x1b]52;c;ZWNobyBoaQ==\x1b\\", `␛]52;c;ZWNobyBoaQ==␛\`},
+		{"c1 csi", "a\u009b31mb", `a\u009b31mb`},
+		{"c1 nel", "a\u0085b", `a\u0085b`},
+		{"bidi override", "invoice\u202egpj.exe", `invoice\u202egpj.exe`},
+		{"bidi isolate", "a\u2066b\u2069", `a\u2066b\u2069`},
+		{"invalid utf-8", "a\xffb\xc3", "a�b�"},
+		{"multi-byte", "/home/jürgen/日本語/🙂", "/home/jürgen/日本語/🙂"},
+		{"combining accent kept", "e\u0301", "e\u0301"},
+		{"two combining marks kept", "a\u0301\u0308", "a\u0301\u0308"},
+		{"zalgo trimmed", "a\u0301\u0308\u0323\u0324\u0325b\u0301", "a\u0301\u0308b\u0301"},
+		{"leading combining mark", "\u0301x", "\u0301x"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Sanitize(tc.in); got != tc.want {
+				t.Fatalf("Sanitize(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeLeavesNoControlBytes(t *testing.T) {
+	var all strings.Builder
+	for b := 0; b < 256; b++ {
+		all.WriteByte(byte(b))
+	}
+	for r := rune(0x80); r < 0xa0; r++ {
+		all.WriteRune(r)
+	}
+	out := Sanitize(all.String())
+	if !utf8.ValidString(out) {
+		t.Fatalf("expected valid UTF-8, got %q", out)
+	}
+	for _, r := range out {
+		if r < 0x20 || (r >= 0x7f && r < 0xa0) {
+			t.Fatalf("control character %U survived in %q", r, out)
+		}
+	}
+}
+
+func TestSanitizeCapsLength(t *testing.T) {
+	long := strings.Repeat("a", MaxSanitizedRunes+100)
+	out := Sanitize(long)
+	if utf8.RuneCountInString(out) != MaxSanitizedRunes+1 || !strings.HasSuffix(out, "…") {
+		t.Fatalf("expected %d runes and an ellipsis, got %d", MaxSanitizedRunes+1, utf8.RuneCountInString(out))
+	}
+	exact := strings.Repeat("é", MaxSanitizedRunes)
+	if Sanitize(exact) != exact {
+		t.Fatalf("expected a string at the cap to be left whole")
+	}
+}
+
+func TestSanitizeAllAndUnsanitize(t *testing.T) {
+	clean := []string{"curl", "-s"}
+	if got := SanitizeAll(clean); &got[0] != &clean[0] {
+		t.Fatalf("expected clean slices to be returned as is")
+	}
+	dirty := []string{"curl", "a\nb"}
+	got := SanitizeAll(dirty)
+	if got[0] != "curl" || got[1] != "a␊b" || dirty[1] != "a\nb" {
+		t.Fatalf("unexpected SanitizeAll result %q (input %q)", got, dirty)
+	}
+
+	if got := Unsanitize("a␊b", "a\nb"); got != "a\nb" {
+		t.Fatalf("expected the original back, got %q", got)
+	}
+	if got := Unsanitize("edited", "a\nb"); got != "edited" {
+		t.Fatalf("expected an edited value kept, got %q", got)
+	}
+}
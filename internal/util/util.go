@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -17,7 +18,12 @@ func Fallback(value, def string) string {
 
 // RelativeTime renders a human-friendly duration ago value.
 func RelativeTime(ts time.Time) string {
-	delta := time.Since(ts)
+	return RelativeTimeAt(ts, time.Now())
+}
+
+// RelativeTimeAt renders how long before now ts was.
+func RelativeTimeAt(ts, now time.Time) string {
+	delta := now.Sub(ts)
 	if delta < time.Second {
 		delta = time.Second
 	}
@@ -59,6 +65,25 @@ func TruncateString(value string, width int) string {
 	return string(runes[:width-3]) + "..."
 }
 
+// TruncateMiddle shortens value to width runes by replacing its middle with an
+// ellipsis, keeping both the leading and trailing parts visible.
+func TruncateMiddle(value string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	keep := width - 1
+	head := (keep + 1) / 2
+	tail := keep - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
+
 // PadString pads value with spaces up to width runes.
 func PadString(value string, width int) string {
 	padding := width - len([]rune(value))
@@ -67,3 +92,32 @@ func PadString(value string, width int) string {
 	}
 	return value
 }
+
+var byteUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+
+// HumanizeBytes renders a byte count in binary units, e.g. "512 B",
+// "1.2 MiB" or "34 KiB"; one decimal is kept below 10 of a unit.
+func HumanizeBytes(n uint64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	if value < 9.95 {
+		return fmt.Sprintf("%.1f %s", value, byteUnits[unit])
+	}
+	return fmt.Sprintf("%.0f %s", value, byteUnits[unit])
+}
+
+// HumanizeLatency renders a round-trip time as whole milliseconds below a
+// second and tenths of a second above, e.g. "84ms" or "3.4s".
+func HumanizeLatency(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncateMiddle(t *testing.T) {
+	cases := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"abcdefghij", 5, "ab…ij"},
+		{"abcdefghij", 6, "abc…ij"},
+		{"abcdef", 3, "abc"},
+		{"abcdef", 0, ""},
+	}
+	for _, tt := range cases {
+		if got := TruncateMiddle(tt.in, tt.width); got != tt.want {
+			t.Errorf("TruncateMiddle(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizeLatency(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                       "0ms",
+		84 * time.Millisecond:   "84ms",
+		999 * time.Millisecond:  "999ms",
+		3420 * time.Millisecond: "3.4s",
+		time.Minute:             "1m0s",
+	}
+	for in, want := range cases {
+		if got := HumanizeLatency(in); got != want {
+			t.Errorf("HumanizeLatency(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHumanizeBytes(t *testing.T) {
+	cases := []struct {
+		in   uint64
+		want string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{34 * 1024, "34 KiB"},
+		{10239, "10 KiB"},
+		{1258291, "1.2 MiB"},
+		{5 << 30, "5.0 GiB"},
+	}
+	for _, tt := range cases {
+		if got := HumanizeBytes(tt.in); got != tt.want {
+			t.Errorf("HumanizeBytes(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
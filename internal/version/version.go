@@ -0,0 +1,106 @@
+// Package version describes the running build. Release builds set the
+// variables below with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/adamkadaban/opensnitch-tui/internal/version.Version=v1.2.0"
+//
+// and `go build` from a checkout falls back to the VCS stamp Go embeds.
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Set with -ldflags -X; empty values fall back to the embedded build info.
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Dev is the version of a build that was not given one.
+const Dev = "dev"
+
+// Info is what is known about the running build.
+type Info struct {
+	Version string
+	// Commit is the VCS revision, shortened; empty when unknown.
+	Commit string
+	// Date is the build or commit time as given; empty when unknown.
+	Date string
+	// Modified is set for builds of a checkout with uncommitted changes.
+	Modified bool
+	// Tags are the -tags the binary was built with.
+	Tags      []string
+	GoVersion string
+}
+
+// Current returns the running build's Info.
+func Current() Info {
+	bi, _ := debug.ReadBuildInfo()
+	return resolve(Version, Commit, Date, bi)
+}
+
+func resolve(version, commit, date string, bi *debug.BuildInfo) Info {
+	info := Info{Version: version, Commit: commit, Date: date}
+	if bi != nil {
+		info.GoVersion = bi.GoVersion
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			case "-tags":
+				info.Tags = splitTags(setting.Value)
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = Dev
+	}
+	if len(info.Commit) > 12 {
+		info.Commit = info.Commit[:12]
+	}
+	return info
+}
+
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// String renders info on one line, e.g. "v1.2.0 (commit 1a2b3c4d5e6f,
+// built 2024-05-01T12:00:00Z)".
+func (info Info) String() string {
+	var details []string
+	if info.Commit != "" {
+		commit := "commit " + info.Commit
+		if info.Modified {
+			commit += "+dirty"
+		}
+		details = append(details, commit)
+	}
+	if info.Date != "" {
+		details = append(details, "built "+info.Date)
+	}
+	if len(details) == 0 {
+		return info.Version
+	}
+	return fmt.Sprintf("%s (%s)", info.Version, strings.Join(details, ", "))
+}
@@ -0,0 +1,49 @@
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestResolveFallsBackToDev(t *testing.T) {
+	info := resolve("", "", "", nil)
+	if info.Version != Dev || info.Commit != "" || info.Date != "" {
+		t.Fatalf("expected a bare dev build, got %+v", info)
+	}
+	if got := info.String(); got != "dev" {
+		t.Fatalf("String() = %q, want %q", got, "dev")
+	}
+
+	// A checkout build reports (devel) and only its VCS stamp.
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.24.0",
+		Main:      debug.Module{Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "-tags", Value: "no_yara"},
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+			{Key: "vcs.time", Value: "2024-05-01T12:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	info = resolve("", "", "", bi)
+	if info.Version != Dev || info.GoVersion != "go1.24.0" || len(info.Tags) != 1 || info.Tags[0] != "no_yara" {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if got, want := info.String(), "dev (commit 0123456789ab+dirty, built 2024-05-01T12:00:00Z)"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestResolvePrefersLinkerValues(t *testing.T) {
+	bi := &debug.BuildInfo{
+		Main:     debug.Module{Version: "v0.9.0"},
+		Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "ffffffffffff"}},
+	}
+	info := resolve("v1.2.0", "1a2b3c4", "2024-06-01", bi)
+	if got, want := info.String(), "v1.2.0 (commit 1a2b3c4, built 2024-06-01)"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+	if info := resolve("", "", "", bi); info.Version != "v0.9.0" {
+		t.Fatalf("expected the module version of a go install build, got %+v", info)
+	}
+}
@@ -0,0 +1,70 @@
+package yara
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// checkSampleSize caps how many rule files Check compiles.
+const checkSampleSize = 20
+
+// Report describes what enabling scanning with a rule directory involves.
+type Report struct {
+	// Available is IsAvailable at the time of the check.
+	Available bool
+	// Files counts the .yar and .yara files under the directory.
+	Files int
+	// Sampled rule files compiled in Compile; zero when nothing was
+	// compiled.
+	Sampled int
+	Compile time.Duration
+	Err     error
+}
+
+// Check counts the rule files in dir and, when YARA support is built in,
+// times compiling a sample of them. It can take seconds on large rule sets
+// and is meant to run off the UI goroutine.
+func Check(dir string) Report {
+	report := Report{Available: IsAvailable()}
+	files, err := RuleFiles(dir)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	report.Files = len(files)
+	if !report.Available || len(files) == 0 {
+		return report
+	}
+	sample := files[:min(len(files), checkSampleSize)]
+	start := time.Now()
+	if err := compileSample(sample); err != nil {
+		report.Err = err
+		return report
+	}
+	report.Sampled = len(sample)
+	report.Compile = time.Since(start)
+	return report
+}
+
+// RuleFiles lists the .yar and .yara files under dir, in walk order.
+func RuleFiles(dir string) ([]string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, ErrNoRules
+	}
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".yar" || ext == ".yara" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
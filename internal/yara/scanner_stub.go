@@ -9,3 +9,5 @@ func IsAvailable() bool { return false }
 func ScanFile(_, _ string) (Result, error) {
 	return Result{}, ErrUnavailable
 }
+
+func compileSample([]string) error { return ErrUnavailable }
@@ -2,7 +2,11 @@
 
 package yara
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestStubIsAvailable(t *testing.T) {
 	if IsAvailable() {
@@ -12,3 +16,23 @@ func TestStubIsAvailable(t *testing.T) {
 		t.Fatalf("expected error from stub ScanFile")
 	}
 }
+
+func TestStubCheckCountsRuleFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.yar", "b.YARA", "nested/c.yar", "notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("rule r { condition: true }"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := Check(dir)
+	if report.Available || report.Files != 3 || report.Sampled != 0 || report.Err != nil {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report := Check(filepath.Join(dir, "missing")); report.Err == nil {
+		t.Fatalf("expected a missing directory reported")
+	}
+}
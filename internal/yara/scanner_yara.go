@@ -4,10 +4,7 @@ package yara
 
 import (
 	"fmt"
-	"io/fs"
 	"os"
-	"path/filepath"
-	"strings"
 	"sync"
 
 	gyara "github.com/hillu/go-yara/v4"
@@ -51,32 +48,11 @@ func getOrCompile(dir string) (*gyara.Rules, error) {
 	if r, ok := compiledDirs[dir]; ok {
 		return r, nil
 	}
-	compiler, err := gyara.NewCompiler()
+	files, err := RuleFiles(dir)
 	if err != nil {
 		return nil, err
 	}
-	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		ext := strings.ToLower(filepath.Ext(path))
-		if ext != ".yar" && ext != ".yara" {
-			return nil
-		}
-		f, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		return compiler.AddFile(f, "")
-	})
-	if err != nil {
-		return nil, err
-	}
-	rules, err := compiler.GetRules()
+	rules, err := compile(files)
 	if err != nil {
 		return nil, err
 	}
@@ -86,3 +62,31 @@ func getOrCompile(dir string) (*gyara.Rules, error) {
 	compiledDirs[dir] = rules
 	return rules, nil
 }
+
+// compileSample compiles files with a throwaway compiler, for Check.
+func compileSample(files []string) error {
+	_, err := compile(files)
+	return err
+}
+
+func compile(files []string) (*gyara.Rules, error) {
+	compiler, err := gyara.NewCompiler()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		if err := addFile(compiler, path); err != nil {
+			return nil, err
+		}
+	}
+	return compiler.GetRules()
+}
+
+func addFile(compiler *gyara.Compiler, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return compiler.AddFile(f, "")
+}
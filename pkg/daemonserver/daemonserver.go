@@ -0,0 +1,37 @@
+// Package daemonserver runs the gRPC service OpenSnitch daemons connect
+// to and keeps a state store current with what they report, for programs
+// that want the daemons' connections and rules without the terminal UI.
+//
+// Compatibility: New, Server's exported methods and the Options fields
+// below are kept across minor releases. Options fields typed from the
+// module's internal packages (rule cache, trash, baselines, history,
+// writer and blocklists) are used by the TUI; embedders leave them nil
+// and get a server that keeps everything in memory.
+package daemonserver
+
+import (
+	"github.com/adamkadaban/opensnitch-tui/internal/daemon"
+	"github.com/adamkadaban/opensnitch-tui/pkg/state"
+)
+
+// Server is the daemon-facing service. Start or Serve it, then act on
+// nodes through its methods, such as AddRule, ChangeRule, DeleteRule and
+// ResolvePrompt; their results arrive in the store as acknowledgements.
+type Server = daemon.Server
+
+// Options configure a Server. ListenAddr takes host:port or
+// unix:///path and defaults to 127.0.0.1:50051.
+type Options = daemon.Options
+
+// TLSOptions enable TLS, and with ClientCA client certificates, on the
+// listener.
+type TLSOptions = daemon.TLSOptions
+
+// ShutdownTimeout bounds how long a Server stops gracefully once the
+// context given to Start or Serve is canceled.
+const ShutdownTimeout = daemon.ShutdownTimeout
+
+// New returns a server that records what daemons report in store.
+func New(store *state.Store, opts Options) *Server {
+	return daemon.New(store, opts)
+}
@@ -0,0 +1,51 @@
+package daemonserver_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/adamkadaban/opensnitch-tui/pkg/daemonserver"
+	"github.com/adamkadaban/opensnitch-tui/pkg/state"
+)
+
+func ExampleServer_Serve() {
+	store := state.NewStore()
+	server := daemonserver.New(store, daemonserver.Options{})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, lis) }()
+
+	// Daemons pointed at lis.Addr() now show up in store.Snapshot().Nodes.
+	// Canceling the context answers their pending prompts and stops.
+	cancel()
+	fmt.Println("stopped:", <-done)
+	// Output: stopped: <nil>
+}
+
+func ExampleNew() {
+	store := state.NewStore()
+	server := daemonserver.New(store, daemonserver.Options{
+		ListenAddr: "unix:///run/user/1000/opensnitch-ui.sock",
+	})
+
+	sub := store.Subscribe()
+	defer sub.Close()
+	go func() {
+		for range sub.Events() {
+			for _, node := range store.Snapshot().Nodes {
+				fmt.Println(node.ID, node.Status)
+			}
+		}
+	}()
+
+	if err := server.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,56 @@
+package ruleconv_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/adamkadaban/opensnitch-tui/pkg/ruleconv"
+	"github.com/adamkadaban/opensnitch-tui/pkg/state"
+)
+
+func ExampleToProto() {
+	rule := state.Rule{
+		Name:     "allow-ssh",
+		Enabled:  true,
+		Action:   "allow",
+		Duration: "always",
+		Operator: state.RuleOperator{Type: "simple", Operand: "dest.port", Data: "22"},
+	}
+
+	msg := ruleconv.ToProto(rule)
+	fmt.Println(msg.GetName(), msg.GetOperator().GetOperand(), msg.GetOperator().GetData())
+
+	back := ruleconv.FromProto(msg, "node-1")
+	fmt.Println(back.NodeID, back.Action, back.Duration)
+	// Output:
+	// allow-ssh dest.port 22
+	// node-1 allow always
+}
+
+func ExampleNormalizeDuration() {
+	for _, raw := range []string{"until_restart", "Always", "30m"} {
+		fmt.Printf("%q\n", ruleconv.NormalizeDuration(raw))
+	}
+	// Output:
+	// "until restart"
+	// "always"
+	// "30m"
+}
+
+func ExampleToFile() {
+	rule := state.Rule{
+		Name:     "deny-telemetry",
+		Enabled:  true,
+		Action:   "deny",
+		Duration: "always",
+		Operator: state.RuleOperator{Type: "simple", Operand: "dest.host", Data: "telemetry.example"},
+	}
+
+	data, err := json.Marshal(ruleconv.ToFile(rule).Operator)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+	// Output: {"type":"simple","operand":"dest.host","sensitive":false,"data":"telemetry.example","list":null}
+}
@@ -0,0 +1,88 @@
+// Package ruleconv converts OpenSnitch rules between the daemon's protobuf
+// messages, the JSON rule files opensnitchd loads from /etc/opensnitchd/rules
+// and the store's Rule type.
+//
+// Compatibility: the functions and types here are kept across minor
+// releases. ProtoRule and ProtoOperator follow the daemon's protocol, so
+// they gain fields when OpenSnitch does.
+package ruleconv
+
+import (
+	"github.com/adamkadaban/opensnitch-tui/internal/pb/protocol"
+	"github.com/adamkadaban/opensnitch-tui/internal/ruleconv"
+	ruleset "github.com/adamkadaban/opensnitch-tui/internal/rules"
+	"github.com/adamkadaban/opensnitch-tui/pkg/state"
+)
+
+type (
+	// ProtoRule is a rule as daemons send and receive it.
+	ProtoRule = protocol.Rule
+	// ProtoOperator is a ProtoRule's operator.
+	ProtoOperator = protocol.Operator
+)
+
+// File is a rule in the JSON schema of opensnitchd's rule files; marshal
+// it with encoding/json. File.Rule converts it back for a node.
+type File = ruleset.File
+
+// FileOperator is a File's operator; List holds the operators of a list
+// operator.
+type FileOperator = ruleset.FileOperator
+
+// FromProto converts a rule reported by nodeID. Durations are given their
+// canonical spelling and control characters in strings are escaped for
+// display; ToProto undoes the escaping.
+func FromProto(rule *ProtoRule, nodeID string) state.Rule {
+	return ruleconv.FromProto(rule, nodeID)
+}
+
+// FromProtoList converts every rule reported by nodeID.
+func FromProtoList(list []*ProtoRule, nodeID string) []state.Rule {
+	return ruleconv.FromProtoList(list, nodeID)
+}
+
+// ToProto converts rule to send to a daemon.
+func ToProto(rule state.Rule) *ProtoRule {
+	return ruleconv.ToProto(rule)
+}
+
+// OperatorFromProto converts a daemon operator, expanding list operators.
+func OperatorFromProto(op *ProtoOperator) state.RuleOperator {
+	return ruleconv.OperatorFromProto(op)
+}
+
+// OperatorToProto converts op to send to a daemon.
+func OperatorToProto(op state.RuleOperator) *ProtoOperator {
+	return ruleconv.OperatorToProto(op)
+}
+
+// NormalizeDuration returns the canonical spelling of a rule duration,
+// such as "until restart" for "until_restart". Durations it does not
+// know, such as "30m", are returned unchanged.
+func NormalizeDuration(raw string) string {
+	return ruleconv.NormalizeDuration(raw)
+}
+
+// ToFile converts rule to its rule file.
+func ToFile(rule state.Rule) File {
+	return ruleset.ToFile(rule)
+}
+
+// ReadFiles reads the rule file at path, or every .json file directly in
+// the directory at path. Files that do not decode are reported in skipped
+// rather than failing the read.
+func ReadFiles(path string) (files []File, skipped []error, err error) {
+	return ruleset.ReadFiles(path)
+}
+
+// WriteFiles writes each rule to dir as a rule file and returns how many
+// were written before any error.
+func WriteFiles(dir string, rules []state.Rule) (int, error) {
+	return ruleset.WriteFiles(dir, rules)
+}
+
+// Hash returns a digest of rule's definition that ignores the order of
+// list operator children, for telling whether two rules are the same.
+func Hash(rule state.Rule) string {
+	return ruleset.Hash(rule)
+}
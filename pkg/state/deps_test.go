@@ -0,0 +1,27 @@
+package state_test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestPublicPackagesAvoidUI keeps the terminal UI libraries out of what an
+// embedder of pkg/ links.
+func TestPublicPackagesAvoidUI(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	out, err := exec.Command(goBin, "list", "-deps", "github.com/adamkadaban/opensnitch-tui/pkg/...").Output()
+	if err != nil {
+		t.Fatalf("go list: %v", err)
+	}
+	for _, dep := range strings.Fields(string(out)) {
+		for _, banned := range []string{"github.com/charmbracelet/bubbletea", "github.com/charmbracelet/lipgloss"} {
+			if strings.HasPrefix(dep, banned) {
+				t.Errorf("pkg/ depends on %s", dep)
+			}
+		}
+	}
+}
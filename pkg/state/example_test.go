@@ -0,0 +1,35 @@
+package state_test
+
+import (
+	"fmt"
+
+	"github.com/adamkadaban/opensnitch-tui/pkg/state"
+)
+
+func ExampleStore_Subscribe() {
+	store := state.NewStore()
+	sub := store.Subscribe()
+	defer sub.Close()
+
+	store.SetNodes([]state.Node{{ID: "unix:/run/opensnitch.sock", Name: "laptop", Status: state.NodeStatusReady}})
+
+	<-sub.Events()
+	for _, node := range store.Snapshot().Nodes {
+		fmt.Println(node.Name, node.Status)
+	}
+	// Output: laptop ready
+}
+
+func ExampleStore_MergeEvents() {
+	store := state.NewStore()
+	store.MergeEvents([]state.Event{{
+		NodeID:     "node-1",
+		Connection: state.Connection{ProcessPath: "/usr/bin/curl", DstHost: "example.org", DstPort: 443},
+		Rule:       state.Rule{Name: "allow-curl", Action: "allow"},
+	}})
+
+	for _, ev := range store.Snapshot().Events {
+		fmt.Printf("%s -> %s:%d (%s)\n", ev.Connection.ProcessPath, ev.Connection.DstHost, ev.Connection.DstPort, ev.Rule.Action)
+	}
+	// Output: /usr/bin/curl -> example.org:443 (allow)
+}
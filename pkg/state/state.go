@@ -0,0 +1,58 @@
+// Package state exposes the store behind opensnitch-tui so other programs
+// can follow OpenSnitch daemons without the terminal UI: subscribe to the
+// store, take a snapshot on every signal and read nodes, events, rules
+// and prompts from it.
+//
+// Compatibility: the names declared here are kept across minor releases.
+// They are aliases of the implementation in internal/state, so fields may
+// be added to the aliased structs and methods added to Store, but existing
+// fields and methods are only removed or changed in a major release.
+// Snapshot fields and Store methods that refer to types not re-exported
+// here are used by the TUI and carry no such promise.
+package state
+
+import "github.com/adamkadaban/opensnitch-tui/internal/state"
+
+// Store holds the state of every connected node. It is safe for
+// concurrent use; mutators signal subscribers after each change.
+type Store = state.Store
+
+// Subscription signals on Events after the store changes. Signals are
+// coalesced, so a subscriber that falls behind sees one signal for many
+// changes and should read a fresh Snapshot. Close stops delivery and
+// closes the channel.
+type Subscription = state.Subscription
+
+// Snapshot is a copy of the store taken by Store.Snapshot. Its slices and
+// maps are not shared with the store.
+type Snapshot = state.Snapshot
+
+type (
+	Node         = state.Node
+	NodeStatus   = state.NodeStatus
+	Stats        = state.Stats
+	Event        = state.Event
+	Connection   = state.Connection
+	Rule         = state.Rule
+	RuleOperator = state.RuleOperator
+	Prompt       = state.Prompt
+	Decision     = state.Decision
+	Alert        = state.Alert
+	Settings     = state.Settings
+	ActionAck    = state.ActionAck
+	LogEntry     = state.LogEntry
+	LogSeverity  = state.LogSeverity
+)
+
+const (
+	NodeStatusUnknown      = state.NodeStatusUnknown
+	NodeStatusDisconnected = state.NodeStatusDisconnected
+	NodeStatusConnecting   = state.NodeStatusConnecting
+	NodeStatusReady        = state.NodeStatusReady
+	NodeStatusError        = state.NodeStatusError
+)
+
+// NewStore returns an empty store with the default settings.
+func NewStore() *Store {
+	return state.NewStore()
+}